@@ -0,0 +1,108 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/benchmark"
+	"helm.sh/helm/v3/pkg/cli/output"
+)
+
+const benchDesc = `
+Measure the latency and memory cost of the render and apply pipeline.
+
+This command renders, installs, upgrades and uninstalls a synthetic chart of
+a configurable size, and reports the latency and the allocations of every
+phase. Every resource of the chart is a ConfigMap.
+
+By default the releases are applied to an in-memory fake, which measures Helm
+alone. With '--cluster' they are applied to the cluster of the current kube
+context instead, for instance a kind cluster. Every release is uninstalled at
+the end of its iteration.
+
+    $ helm bench --templates 100 --resources 10 --iterations 20
+`
+
+func newBenchCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	o := benchmark.Options{Chart: benchmark.DefaultChartOptions}
+	var cluster bool
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "bench",
+		Short:             "measure the performance of rendering and applying charts",
+		Long:              benchDesc,
+		Hidden:            true,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target := cfg
+			if !cluster {
+				target = benchmark.NewFakeConfiguration()
+				target.Log = debug
+			}
+			o.Namespace = settings.Namespace()
+
+			report, err := benchmark.Run(target, o)
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &benchReportWriter{report})
+		},
+	}
+
+	f := cmd.Flags()
+	f.IntVar(&o.Chart.Templates, "templates", o.Chart.Templates, "number of templates in the synthetic chart")
+	f.IntVar(&o.Chart.Resources, "resources", o.Chart.Resources, "number of resources rendered by each template")
+	f.IntVar(&o.Chart.ValueKeys, "value-keys", o.Chart.ValueKeys, "number of values set on each resource")
+	f.IntVar(&o.Iterations, "iterations", 10, "number of times every phase is measured")
+	f.StringVar(&o.ReleasePrefix, "release-prefix", "bench", "prefix of the names of the releases")
+	f.BoolVar(&cluster, "cluster", false, "apply the releases to the cluster of the current kube context instead of a fake")
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type benchReportWriter struct {
+	report *benchmark.Report
+}
+
+func (w *benchReportWriter) WriteTable(out io.Writer) error {
+	fmt.Fprintf(out, "CHART: %d templates, %d resources, %d value keys\n\n",
+		w.report.Chart.Templates, w.report.Resources, w.report.Chart.ValueKeys)
+
+	tbl := uitable.New()
+	tbl.AddRow("PHASE", "ITERATIONS", "MIN", "MEAN", "P50", "P95", "MAX", "BYTES/OP", "ALLOCS/OP")
+	for _, r := range w.report.Results {
+		tbl.AddRow(r.Phase, r.Iterations, r.Min, r.Mean, r.P50, r.P95, r.Max, r.BytesPerOp, r.AllocsPerOp)
+	}
+	return output.EncodeTable(out, tbl)
+}
+
+func (w *benchReportWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.report)
+}
+
+func (w *benchReportWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.report)
+}
@@ -0,0 +1,50 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+var capabilitiesHelp = `
+This command consists of multiple subcommands to work with snapshots of the
+capabilities of clusters.
+
+A snapshot records the Kubernetes version, the API resources and the
+CustomResourceDefinitions of a cluster, along with their schemas. Given to
+'helm template', 'helm lint' or 'helm install --dry-run' with
+'--capabilities-snapshot', charts render exactly as they would in that
+cluster, without access to it.
+`
+
+func newCapabilitiesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "capabilities",
+		Short: "export the capabilities of a cluster to render charts elsewhere",
+		Long:  capabilitiesHelp,
+		Args:  require.NoArgs,
+	}
+
+	cmd.AddCommand(newCapabilitiesExportCmd(cfg, out))
+
+	return cmd
+}
@@ -0,0 +1,68 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+var capabilitiesExportHelp = `
+This command takes a snapshot of the capabilities of the cluster of the current
+context and prints it as YAML.
+
+The snapshot holds the Kubernetes version, the API versions and resources the
+cluster serves, and the CustomResourceDefinitions installed in it with their
+schemas: everything templates can see through '.Capabilities'. Only the
+discovery API is queried and the CustomResourceDefinitions listed; nothing is
+changed.
+
+Save the snapshot to a file and pass it to '--capabilities-snapshot' to render
+charts as they would be in the cluster, for instance to reproduce an issue:
+
+    $ helm capabilities export > snapshot.yaml
+    $ helm template mychart --capabilities-snapshot snapshot.yaml
+`
+
+func newCapabilitiesExportCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewCapabilitiesExport(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "print a snapshot of the capabilities of the cluster",
+		Long:  capabilitiesExportHelp,
+		Args:  require.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshot, err := client.Run()
+			if err != nil {
+				return err
+			}
+			data, err := snapshot.Marshal()
+			if err != nil {
+				return err
+			}
+			_, err = out.Write(data)
+			return err
+		},
+	}
+
+	return cmd
+}
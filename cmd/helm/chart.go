@@ -44,6 +44,7 @@ func newChartCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		newChartPushCmd(cfg, out),
 		newChartRemoveCmd(cfg, out),
 		newChartSaveCmd(cfg, out),
+		newChartPushValuesCmd(cfg, out),
 	)
 	return cmd
 }
@@ -0,0 +1,52 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+const chartPushValuesDesc = `
+Upload a values file to a remote registry as a values overlay.
+
+Values overlays hold environment-specific configuration that is versioned
+independently of charts. Reference them at install or upgrade time with
+'--values-ref':
+
+    $ helm chart push-values prod.yaml oci://registry/overlays/prod:1.2.0
+    $ helm install web ./web --values-ref oci://registry/overlays/prod:1.2.0
+
+The digest of the overlay that was pulled is recorded in the release.
+`
+
+func newChartPushValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:    "push-values [file] [ref]",
+		Short:  "push a values overlay to remote",
+		Long:   chartPushValuesDesc,
+		Args:   require.ExactArgs(2),
+		Hidden: !FeatureGateOCI.IsEnabled(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return action.NewValuesPush(cfg).Run(out, args[0], args[1])
+		},
+	}
+}
@@ -17,7 +17,6 @@ limitations under the License.
 package main
 
 import (
-	"fmt"
 	"io"
 	"path/filepath"
 
@@ -26,6 +25,7 @@ import (
 	"helm.sh/helm/v3/cmd/helm/require"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli/messages"
 	"helm.sh/helm/v3/pkg/helmpath"
 )
 
@@ -85,7 +85,7 @@ func newCreateCmd(out io.Writer) *cobra.Command {
 }
 
 func (o *createOptions) run(out io.Writer) error {
-	fmt.Fprintf(out, "Creating %s\n", o.name)
+	info(out, messages.ChartCreating, o.name)
 
 	chartname := filepath.Base(o.name)
 	cfile := &chart.Metadata{
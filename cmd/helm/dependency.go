@@ -71,6 +71,32 @@ the dependency charts stored locally. The path should start with a prefix of
 If the dependency chart is retrieved locally, it is not required to have the
 repository added to helm by "helm add repo". Version matching is also supported
 for this case.
+
+The 'values-files' of a dependency are files of the chart holding defaults for
+the dependency, merged under its name or alias in the order they are listed.
+The values under that key in the 'values.yaml' of the chart take precedence:
+
+    # Chart.yaml
+    dependencies:
+    - name: redis
+      version: "10.5.7"
+      repository: "https://example.com/charts"
+      values-files:
+      - values/redis.yaml
+
+With HELM_EXPERIMENTAL_OCI=1, the repository can also be a registry, with the
+"oci://" prefix. The chart is pulled from the repository followed by its name.
+Registries cannot be searched for versions, so the version must be exact, or
+the digest of the chart to pin it whatever its tag now refers to:
+
+    # Chart.yaml
+    dependencies:
+    - name: nginx
+      version: "sha256:4c9b2f...e81a"
+      repository: "oci://registry.example.com/charts"
+
+Chart.lock records the digest of each chart pulled from a registry, and
+'helm dependency build' pulls the charts by these digests.
 `
 
 const dependencyListDesc = `
@@ -82,9 +108,9 @@ the contents of a chart.
 This will produce an error if the chart cannot be loaded.
 `
 
-func newDependencyCmd(out io.Writer) *cobra.Command {
+func newDependencyCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "dependency update|build|list",
+		Use:     "dependency update|build|list|vendor|outdated",
 		Aliases: []string{"dep", "dependencies"},
 		Short:   "manage a chart's dependencies",
 		Long:    dependencyDesc,
@@ -92,8 +118,10 @@ func newDependencyCmd(out io.Writer) *cobra.Command {
 	}
 
 	cmd.AddCommand(newDependencyListCmd(out))
-	cmd.AddCommand(newDependencyUpdateCmd(out))
-	cmd.AddCommand(newDependencyBuildCmd(out))
+	cmd.AddCommand(newDependencyUpdateCmd(cfg, out))
+	cmd.AddCommand(newDependencyBuildCmd(cfg, out))
+	cmd.AddCommand(newDependencyVendorCmd(cfg, out))
+	cmd.AddCommand(newDependencyOutdatedCmd(out))
 
 	return cmd
 }
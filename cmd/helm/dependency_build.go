@@ -39,9 +39,16 @@ does.
 
 If no lock file is found, 'helm dependency build' will mirror the behavior
 of 'helm dependency update'.
+
+Dependencies with file:// repositories are always rebuilt from their
+directories. The lock file records a digest of their content, so changes that
+do not bump their version are reported, and picked up by
+'helm install --dependency-update'. With '--symlink-local', they are linked
+into the charts/ directory instead of archived, so that changes are picked up
+without rebuilding during local development.
 `
 
-func newDependencyBuildCmd(out io.Writer) *cobra.Command {
+func newDependencyBuildCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewDependency()
 
 	cmd := &cobra.Command{
@@ -63,6 +70,8 @@ func newDependencyBuildCmd(out io.Writer) *cobra.Command {
 				RepositoryConfig: settings.RepositoryConfig,
 				RepositoryCache:  settings.RepositoryCache,
 				Debug:            settings.Debug,
+				RegistryClient:   ociRegistryClient(cfg),
+				SymlinkLocal:     client.SymlinkLocal,
 			}
 			if client.Verify {
 				man.Verify = downloader.VerifyIfPossible
@@ -79,6 +88,7 @@ func newDependencyBuildCmd(out io.Writer) *cobra.Command {
 	f.BoolVar(&client.Verify, "verify", false, "verify the packages against signatures")
 	f.StringVar(&client.Keyring, "keyring", defaultKeyring(), "keyring containing public keys")
 	f.BoolVar(&client.SkipRefresh, "skip-refresh", false, "do not refresh the local repository cache")
+	f.BoolVar(&client.SymlinkLocal, "symlink-local", false, "link dependencies with file:// repositories into the charts directory instead of archiving them")
 
 	return cmd
 }
@@ -0,0 +1,115 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+)
+
+const dependencyOutdatedDesc = `
+List the dependencies of a chart for which newer versions are available.
+
+The versions of Chart.lock are compared against the versions of the
+repositories of the dependencies. WANTED is the newest version satisfying the
+constraint of Chart.yaml, that 'helm dependency update' would lock. LATEST is
+the newest version, which may require changing the constraint. The repository
+indexes are refreshed first, unless --skip-refresh is set.
+
+Dependencies living in the charts/ directory or in local directories are left
+out. With --all, the dependencies that are up to date are listed too.
+`
+
+func newDependencyOutdatedCmd(out io.Writer) *cobra.Command {
+	client := action.NewDependency()
+	var outfmt output.Format
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "outdated CHART",
+		Short: "list the dependencies for which newer versions are available",
+		Long:  dependencyOutdatedDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			man := &downloader.Manager{
+				// Progress is kept apart from structured output
+				Out:              cmd.ErrOrStderr(),
+				ChartPath:        chartpath,
+				SkipUpdate:       client.SkipRefresh,
+				Getters:          getter.All(settings),
+				RepositoryConfig: settings.RepositoryConfig,
+				RepositoryCache:  settings.RepositoryCache,
+				Debug:            settings.Debug,
+			}
+			versions, err := man.Outdated()
+			if err != nil {
+				return err
+			}
+			res := dependencyVersions{}
+			for _, v := range versions {
+				if all || v.Outdated() {
+					res = append(res, v)
+				}
+			}
+			return outfmt.Write(out, res)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&all, "all", false, "list the dependencies that are up to date too")
+	f.BoolVar(&client.SkipRefresh, "skip-refresh", false, "do not refresh the local repository cache")
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type dependencyVersions []downloader.DependencyVersions
+
+func (d dependencyVersions) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, d)
+}
+
+func (d dependencyVersions) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, d)
+}
+
+func (d dependencyVersions) WriteTable(out io.Writer) error {
+	if len(d) == 0 {
+		_, err := fmt.Fprintln(out, "All dependencies are up to date.")
+		return err
+	}
+	tbl := uitable.New()
+	tbl.AddRow("NAME", "REPOSITORY", "CONSTRAINT", "LOCKED", "WANTED", "LATEST")
+	for _, v := range d {
+		tbl.AddRow(v.Name, v.Repository, v.Constraint, v.Locked, v.Wanted, v.Latest)
+	}
+	return output.EncodeTable(out, tbl)
+}
@@ -43,7 +43,7 @@ in the Chart.yaml file, but (b) at the wrong version.
 `
 
 // newDependencyUpdateCmd creates a new dependency update command.
-func newDependencyUpdateCmd(out io.Writer) *cobra.Command {
+func newDependencyUpdateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewDependency()
 
 	cmd := &cobra.Command{
@@ -66,6 +66,8 @@ func newDependencyUpdateCmd(out io.Writer) *cobra.Command {
 				RepositoryConfig: settings.RepositoryConfig,
 				RepositoryCache:  settings.RepositoryCache,
 				Debug:            settings.Debug,
+				RegistryClient:   ociRegistryClient(cfg),
+				SymlinkLocal:     client.SymlinkLocal,
 			}
 			if client.Verify {
 				man.Verify = downloader.VerifyAlways
@@ -78,6 +80,7 @@ func newDependencyUpdateCmd(out io.Writer) *cobra.Command {
 	f.BoolVar(&client.Verify, "verify", false, "verify the packages against signatures")
 	f.StringVar(&client.Keyring, "keyring", defaultKeyring(), "keyring containing public keys")
 	f.BoolVar(&client.SkipRefresh, "skip-refresh", false, "do not refresh the local repository cache")
+	f.BoolVar(&client.SymlinkLocal, "symlink-local", false, "link dependencies with file:// repositories into the charts directory instead of archiving them")
 
 	return cmd
 }
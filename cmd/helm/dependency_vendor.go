@@ -0,0 +1,89 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+)
+
+const dependencyVendorDesc = `
+Vendor the dependencies of a chart into the charts/ directory.
+
+Vendor builds the charts/ directory from the Chart.lock file, as
+'helm dependency build' does, then expands each dependency fetched from a
+repository into a 'charts/<name>/' directory instead of keeping it as an
+archive. Vendored dependencies can be reviewed and patched like the rest of
+the chart.
+
+The repository, version and digest of each vendored dependency are recorded
+in the 'vendored' section of Chart.lock. If --verify is set, nothing is
+downloaded: the vendored directories are checked against the digests of
+Chart.lock, and the command fails if any of them was modified or removed.
+`
+
+func newDependencyVendorCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewDependency()
+	var verify bool
+
+	cmd := &cobra.Command{
+		Use:   "vendor CHART",
+		Short: "expand the dependencies of the Chart.lock file into the charts/ directory",
+		Long:  dependencyVendorDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			man := &downloader.Manager{
+				Out:              out,
+				ChartPath:        chartpath,
+				Keyring:          client.Keyring,
+				SkipUpdate:       client.SkipRefresh,
+				Getters:          getter.All(settings),
+				RepositoryConfig: settings.RepositoryConfig,
+				RepositoryCache:  settings.RepositoryCache,
+				Debug:            settings.Debug,
+				RegistryClient:   ociRegistryClient(cfg),
+			}
+			if verify {
+				return man.VerifyVendored()
+			}
+			err := man.Vendor()
+			if e, ok := err.(downloader.ErrRepoNotFound); ok {
+				return fmt.Errorf("%s. Please add the missing repos via 'helm repo add'", e.Error())
+			}
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&verify, "verify", false, "check the vendored dependencies for local modifications instead of vendoring them")
+	f.StringVar(&client.Keyring, "keyring", defaultKeyring(), "keyring containing public keys")
+	f.BoolVar(&client.SkipRefresh, "skip-refresh", false, "do not refresh the local repository cache")
+
+	return cmd
+}
@@ -0,0 +1,97 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/repo/repotest"
+)
+
+func TestDependencyVendorCmd(t *testing.T) {
+	srv, err := repotest.NewTempServerWithCleanup(t, "testdata/testcharts/*.tgz")
+	defer srv.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootDir := srv.Root()
+	srv.LinkIndices()
+
+	chartname := "depvendor"
+	createTestingChart(t, rootDir, chartname, srv.URL())
+	repoFile := filepath.Join(rootDir, "repositories.yaml")
+	chartpath := filepath.Join(rootDir, chartname)
+
+	cmd := fmt.Sprintf("dependency vendor '%s' --repository-config %s --repository-cache %s", chartpath, repoFile, rootDir)
+	_, out, err := executeActionCommand(cmd)
+	if err != nil {
+		t.Logf("Output: %s", out)
+		t.Fatal(err)
+	}
+
+	// The dependency is expanded into a directory, and the archive removed.
+	if _, err := os.Stat(filepath.Join(chartpath, "charts/reqtest/Chart.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(chartpath, "charts/reqtest-0.1.0.tgz")); !os.IsNotExist(err) {
+		t.Errorf("expected the archive of the dependency to be removed, got %v", err)
+	}
+
+	c, err := loader.LoadDir(chartpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Lock.Vendored) != 2 {
+		t.Fatalf("expected 2 vendored dependencies in the lock file, got %d", len(c.Lock.Vendored))
+	}
+	v := c.Lock.Vendored[0]
+	if v.Name != "reqtest" || v.Version != "0.1.0" || v.Repository != srv.URL() {
+		t.Errorf("unexpected provenance of the vendored dependency: %+v", v)
+	}
+	if !strings.HasPrefix(v.ArchiveDigest, "sha256:") || !strings.HasPrefix(v.Digest, "sha256:") {
+		t.Errorf("unexpected digests of the vendored dependency: %+v", v)
+	}
+
+	verifyCmd := fmt.Sprintf("dependency vendor '%s' --verify", chartpath)
+	if _, out, err = executeActionCommand(verifyCmd); err != nil {
+		t.Logf("Output: %s", out)
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "reqtest: ok") {
+		t.Errorf("expected the vendored dependency to be verified, got\n%s", out)
+	}
+
+	// Patch the vendored dependency
+	values := filepath.Join(chartpath, "charts/reqtest/values.yaml")
+	if err := ioutil.WriteFile(values, []byte("patched: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, out, err = executeActionCommand(verifyCmd)
+	if err == nil {
+		t.Fatal("expected verification of a modified dependency to fail")
+	}
+	if !strings.Contains(out, "reqtest: modified") {
+		t.Errorf("expected the vendored dependency to be reported as modified, got\n%s", out)
+	}
+}
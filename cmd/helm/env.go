@@ -24,13 +24,17 @@ import (
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
 )
 
 var envHelp = `
 Env prints out all the environment information in use by Helm.
+
+Use 'helm env storage' to describe the storage of the releases and check its
+health.
 `
 
-func newEnvCmd(out io.Writer) *cobra.Command {
+func newEnvCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "env",
 		Short: "helm client environment information",
@@ -60,6 +64,7 @@ func newEnvCmd(out io.Writer) *cobra.Command {
 			}
 		},
 	}
+	cmd.AddCommand(newEnvStorageCmd(cfg, out))
 	return cmd
 }
 
@@ -0,0 +1,114 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+)
+
+var envStorageHelp = `
+This command describes the storage of the releases and checks its health.
+
+It reports the storage driver in use, selected with the HELM_DRIVER environment
+variable, and the namespace it reads, as a release stored with another driver
+or in another namespace is not found. It counts the releases and the records
+of their revisions, and lists the largest records.
+
+The health checks depend on the driver. The Secret and ConfigMap drivers
+report the records getting close to the size limit of Kubernetes objects,
+past which upgrades fail. The SQL driver checks that the database can be
+reached.
+`
+
+func newEnvStorageCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	var outfmt output.Format
+	client := action.NewStorageInfo(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "storage",
+		Short: "describe the release storage and check its health",
+		Long:  envStorageHelp,
+		Args:  require.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client.Namespace = settings.Namespace()
+			report, err := client.Run()
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &storageReportWriter{report})
+		},
+	}
+
+	f := cmd.Flags()
+	f.IntVar(&client.Top, "top", client.Top, "number of largest records to list")
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type storageReportWriter struct {
+	report *action.StorageReport
+}
+
+func (w *storageReportWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.report)
+}
+
+func (w *storageReportWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.report)
+}
+
+func (w *storageReportWriter) WriteTable(out io.Writer) error {
+	r := w.report
+	fmt.Fprintf(out, "DRIVER: %s\n", r.Driver)
+	fmt.Fprintf(out, "NAMESPACE: %s\n", r.Namespace)
+	fmt.Fprintf(out, "RELEASES: %d\n", r.Releases)
+	fmt.Fprintf(out, "REVISIONS: %d\n", r.Revisions)
+	if r.MaxRecordSize > 0 {
+		fmt.Fprintf(out, "MAX RECORD SIZE: %d\n", r.MaxRecordSize)
+	}
+
+	if len(r.Largest) > 0 {
+		fmt.Fprintln(out, "LARGEST RECORDS:")
+		table := uitable.New()
+		table.AddRow("NAME", "NAMESPACE", "REVISION", "STATUS", "SIZE")
+		for _, rec := range r.Largest {
+			table.AddRow(rec.Name, rec.Namespace, rec.Revision, rec.Status, rec.Size)
+		}
+		if err := output.EncodeTable(out, table); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(out, "CHECKS:")
+	table := uitable.New()
+	table.MaxColWidth = 100
+	table.Wrap = true
+	table.AddRow("CHECK", "STATUS", "MESSAGE")
+	for _, c := range r.Checks {
+		table.AddRow(c.Name, c.Status, c.Message)
+	}
+	return output.EncodeTable(out, table)
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package main
 
 import (
+	"strings"
 	"testing"
+
+	"helm.sh/helm/v3/pkg/release"
 )
 
 func TestEnv(t *testing.T) {
@@ -29,6 +32,25 @@ func TestEnv(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestEnvStorage(t *testing.T) {
+	defer resetEnv()()
+
+	store := storageFixture()
+	rel := release.Mock(&release.MockReleaseOptions{Name: "storage-test"})
+	if err := store.Create(rel); err != nil {
+		t.Fatal(err)
+	}
+	_, out, err := executeActionCommandC(store, "env storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{"DRIVER: Memory", "RELEASES: 1", "REVISIONS: 1", "storage-test", "the records can be listed and decoded"} {
+		if !strings.Contains(out, expect) {
+			t.Errorf("Expected %q in the output, got\n%s", expect, out)
+		}
+	}
+}
+
 func TestEnvFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "env", false)
 	checkFileCompletion(t, "env HELM_BIN", false)
@@ -21,32 +21,57 @@ import (
 	"fmt"
 	"log"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"k8s.io/klog/v2"
 
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli/output"
 	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/diff"
 	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/kube"
 	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/repo"
 )
 
 const outputFlag = "output"
 const postRenderFlag = "post-renderer"
+const waitForFlag = "wait-for"
+const diffRendererFlag = "diff-renderer"
+const featureFlag = "feature"
 
 func addValueOptionsFlags(f *pflag.FlagSet, v *values.Options) {
-	f.StringSliceVarP(&v.ValueFiles, "values", "f", []string{}, "specify values in a YAML file or a URL (can specify multiple)")
+	f.StringSliceVarP(&v.ValueFiles, "values", "f", []string{}, "specify values in a YAML, JSON, TOML or CUE file or a URL (can specify multiple)")
 	f.StringArrayVar(&v.Values, "set", []string{}, "set values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
 	f.StringArrayVar(&v.StringValues, "set-string", []string{}, "set STRING values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
 	f.StringArrayVar(&v.FileValues, "set-file", []string{}, "set values from respective files specified via the command line (can specify multiple or separate values with commas: key1=path1,key2=path2)")
+	f.StringArrayVar(&v.JSONValues, "set-json", []string{}, "set JSON values on the command line, deep merging objects into the values already set (can specify multiple or separate values with commas: key1=jsonval1,key2=jsonval2)")
+	f.StringArrayVar(&v.LiteralValues, "set-literal", []string{}, "set a literal STRING value on the command line, e.g. key.list[0]=value. Everything after the first = is the value (can specify multiple)")
+}
+
+// addValuesRefFlag adds the flag for values overlays stored in registries.
+func addValuesRefFlag(f *pflag.FlagSet, refs *[]string) {
+	f.StringArrayVar(refs, "values-ref", []string{}, "merge a values overlay stored in a registry beneath the other values, e.g. oci://registry/overlays/prod:1.2.0. Pin it with @sha256:... (can specify multiple)")
+}
+
+// addInstallValueOptionsFlags adds the flags for values that 'helm upgrade
+// --install' only applies when the release is installed.
+func addInstallValueOptionsFlags(f *pflag.FlagSet, v *values.Options) {
+	f.StringSliceVar(&v.ValueFiles, "install-values", []string{}, "if --install is set and the release is installed, also apply values from a YAML file or a URL. They are recorded apart from the release's values and not reused by later upgrades (can specify multiple)")
+	f.StringArrayVar(&v.Values, "install-set", []string{}, "like --set, but only applied when --install installs the release (can specify multiple or separate values with commas: key1=val1,key2=val2)")
+	f.StringArrayVar(&v.StringValues, "install-set-string", []string{}, "like --set-string, but only applied when --install installs the release (can specify multiple or separate values with commas: key1=val1,key2=val2)")
+	f.StringArrayVar(&v.FileValues, "install-set-file", []string{}, "like --set-file, but only applied when --install installs the release (can specify multiple or separate values with commas: key1=path1,key2=path2)")
 }
 
 func addChartPathOptionsFlags(f *pflag.FlagSet, c *action.ChartPathOptions) {
-	f.StringVar(&c.Version, "version", "", "specify the exact chart version to use. If this is not specified, the latest version is used")
+	f.StringVar(&c.Version, "version", "", "specify a version constraint for the chart version to use, such as an exact version or '>=1.2.0 <2.0.0'. The newest matching version is used. If this is not specified, the latest version is used. Charts in registries (oci://) take an exact version or a digest (sha256:...)")
 	f.BoolVar(&c.Verify, "verify", false, "verify the package before using it")
 	f.StringVar(&c.Keyring, "keyring", defaultKeyring(), "location of public keys used for verification")
 	f.StringVar(&c.RepoURL, "repo", "", "chart repository url where to locate the requested chart")
@@ -79,6 +104,27 @@ func bindOutputFlag(cmd *cobra.Command, varRef *output.Format) {
 	}
 }
 
+// bindDiffRendererFlag adds the flag naming the renderer of the changes of
+// the resources of a release to the given command.
+func bindDiffRendererFlag(cmd *cobra.Command, renderer *string) {
+	cmd.Flags().StringVar(renderer, diffRendererFlag, "unified",
+		fmt.Sprintf("render the changes with one of: %s, or %s followed by a command given the paths of the current and target manifests", strings.Join(diff.Names(), ", "), diff.ExternalPrefix))
+
+	err := cmd.RegisterFlagCompletionFunc(diffRendererFlag, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var names []string
+		for _, name := range append(diff.Names(), diff.ExternalPrefix) {
+			if strings.HasPrefix(name, toComplete) {
+				names = append(names, name)
+			}
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
 type outputValue output.Format
 
 func newOutputValue(defaultValue output.Format, p *output.Format) *outputValue {
@@ -106,6 +152,47 @@ func (o *outputValue) Set(s string) error {
 	return nil
 }
 
+// addDryRunFlag adds the '--dry-run' flag, which takes an optional mode:
+// 'client', the default, or 'server' to also send the resources to the API
+// server in dry-run mode. 'true' and 'false' are kept for compatibility.
+func addDryRunFlag(f *pflag.FlagSet, dryRun, server *bool, usage string) {
+	f.Var(&dryRunValue{dryRun, server}, "dry-run", usage)
+	f.Lookup("dry-run").NoOptDefVal = "client"
+}
+
+type dryRunValue struct {
+	dryRun *bool
+	server *bool
+}
+
+func (d *dryRunValue) String() string {
+	switch {
+	case d.dryRun == nil || !*d.dryRun:
+		return "none"
+	case *d.server:
+		return "server"
+	}
+	return "client"
+}
+
+func (d *dryRunValue) Type() string {
+	return "mode"
+}
+
+func (d *dryRunValue) Set(s string) error {
+	switch s {
+	case "client", "true":
+		*d.dryRun, *d.server = true, false
+	case "server":
+		*d.dryRun, *d.server = true, true
+	case "none", "false":
+		*d.dryRun, *d.server = false, false
+	default:
+		return fmt.Errorf("invalid dry-run mode %q: must be one of client, server or none", s)
+	}
+	return nil
+}
+
 func bindPostRenderFlag(cmd *cobra.Command, varRef *postrender.PostRenderer) {
 	cmd.Flags().Var(&postRenderer{varRef}, postRenderFlag, "the path to an executable to be used for post rendering. If it exists in $PATH, the binary will be used, otherwise it will try to look for the executable at the given path")
 }
@@ -134,6 +221,179 @@ func (p postRenderer) Set(s string) error {
 	return nil
 }
 
+func bindWaitForFlag(f *pflag.FlagSet, varRef *[]kube.WaitCondition) {
+	f.Var(&waitConditions{varRef}, waitForFlag, "wait until the release's resources meet a condition before marking the release as successful (can specify multiple). "+
+		"Accepts [KIND[/NAME]:]condition=TYPE[=STATUS] or [KIND[/NAME]:]jsonpath=EXPRESSION=VALUE, e.g. Deployment/web:condition=Available or Pod:jsonpath={.status.phase}=Running. "+
+		"Conditions without a kind apply to the resources that report the condition type or field. It will wait for as long as --timeout")
+}
+
+func bindFeatureFlag(f *pflag.FlagSet, varRef *chartutil.Features) {
+	f.Var(&featuresValue{varRef}, featureFlag, "enable a feature of the client for the templates, which they see in .Helm.Features (can specify multiple). "+
+		"Accepts NAME or NAME=true|false. Features are also enabled by $HELM_FEATURES, a comma-separated list of the same")
+}
+
+func bindCapabilitiesSnapshotFlag(f *pflag.FlagSet, varRef **chartutil.CapabilitiesSnapshot) {
+	f.Var(&capabilitiesSnapshotValue{snapshot: varRef}, "capabilities-snapshot", "render the templates with the capabilities recorded in the given snapshot, as taken by 'helm capabilities export'")
+}
+
+// applyEnvFeatures adds the features of $HELM_FEATURES that are not given by
+// flags.
+func applyEnvFeatures(varRef *chartutil.Features) error {
+	env, err := action.ParseFeatures(settings.Features)
+	if err != nil {
+		return errors.Wrap(err, "invalid $HELM_FEATURES")
+	}
+	if *varRef == nil {
+		*varRef = chartutil.Features{}
+	}
+	for name, enabled := range env {
+		if _, ok := (*varRef)[name]; !ok {
+			(*varRef)[name] = enabled
+		}
+	}
+	return nil
+}
+
+// applyReleaseDefaults sets the operation flags that were not given on the
+// command line to the defaults recorded with the last revision of the release,
+// if it has any. A nil atomic is left alone.
+func applyReleaseDefaults(cfg *action.Configuration, f *pflag.FlagSet, name string, wait, waitForJobs *bool, timeout *time.Duration, atomic *bool) {
+	rel, err := action.NewGet(cfg).Run(name)
+	if err != nil || rel.Defaults == nil {
+		// The operation itself reports the releases that cannot be read
+		return
+	}
+	d := rel.Defaults
+	if !f.Changed("wait") {
+		*wait = d.Wait
+	}
+	if !f.Changed("wait-for-jobs") {
+		*waitForJobs = d.WaitForJobs
+	}
+	if d.Timeout > 0 && !f.Changed("timeout") {
+		*timeout = d.Timeout
+	}
+	if atomic != nil && !f.Changed("atomic") {
+		*atomic = d.Atomic
+	}
+	debug("using the defaults recorded with release %s: wait=%t wait-for-jobs=%t timeout=%s atomic=%t", name, d.Wait, d.WaitForJobs, d.Timeout, d.Atomic)
+}
+
+// applyProfileDefaults sets the operation flags that were not given on the
+// command line to the defaults of the configuration profile, if one is in
+// use. Nil flags are left alone.
+func applyProfileDefaults(f *pflag.FlagSet, wait, waitForJobs *bool, timeout *time.Duration, renderer *postrender.PostRenderer) error {
+	if profile == nil {
+		return nil
+	}
+	p := profile
+	if wait != nil && p.Wait && !f.Changed("wait") {
+		*wait = true
+	}
+	if waitForJobs != nil && p.WaitForJobs && !f.Changed("wait-for-jobs") {
+		*waitForJobs = true
+	}
+	if timeout != nil && p.Timeout.Duration > 0 && !f.Changed("timeout") {
+		*timeout = p.Timeout.Duration
+	}
+	if renderer != nil && p.PostRenderer != "" && !f.Changed(postRenderFlag) {
+		pr, err := postrender.NewExec(p.PostRenderer)
+		if err != nil {
+			return errors.Wrapf(err, "invalid post-renderer in profile %s", p.Name)
+		}
+		*renderer = pr
+	}
+	debug("using the defaults of profile %s: wait=%t wait-for-jobs=%t timeout=%s post-renderer=%q", p.Name, p.Wait, p.WaitForJobs, p.Timeout.Duration, p.PostRenderer)
+	return nil
+}
+
+// redactValues returns the values with the paths redacted by the
+// configuration profile hidden, if one is in use.
+func redactValues(vals map[string]interface{}) (map[string]interface{}, error) {
+	if profile == nil {
+		return vals, nil
+	}
+	return profile.RedactValues(vals)
+}
+
+type waitConditions struct {
+	conditions *[]kube.WaitCondition
+}
+
+func (w waitConditions) String() string {
+	var s []string
+	for _, c := range *w.conditions {
+		s = append(s, c.String())
+	}
+	return "[" + strings.Join(s, ",") + "]"
+}
+
+func (w waitConditions) Type() string {
+	return "condition"
+}
+
+func (w waitConditions) Set(s string) error {
+	c, err := kube.ParseWaitCondition(s)
+	if err != nil {
+		return err
+	}
+	*w.conditions = append(*w.conditions, c)
+	return nil
+}
+
+type capabilitiesSnapshotValue struct {
+	snapshot **chartutil.CapabilitiesSnapshot
+	path     string
+}
+
+func (c *capabilitiesSnapshotValue) String() string {
+	return c.path
+}
+
+func (c *capabilitiesSnapshotValue) Type() string {
+	return "file"
+}
+
+func (c *capabilitiesSnapshotValue) Set(s string) error {
+	snapshot, err := chartutil.LoadCapabilitiesSnapshot(s)
+	if err != nil {
+		return err
+	}
+	c.path, *c.snapshot = s, snapshot
+	return nil
+}
+
+type featuresValue struct {
+	features *chartutil.Features
+}
+
+func (f featuresValue) String() string {
+	var s []string
+	for name, enabled := range *f.features {
+		s = append(s, fmt.Sprintf("%s=%t", name, enabled))
+	}
+	sort.Strings(s)
+	return "[" + strings.Join(s, ",") + "]"
+}
+
+func (f featuresValue) Type() string {
+	return "feature"
+}
+
+func (f featuresValue) Set(s string) error {
+	parsed, err := action.ParseFeatures([]string{s})
+	if err != nil {
+		return err
+	}
+	if *f.features == nil {
+		*f.features = chartutil.Features{}
+	}
+	for name, enabled := range parsed {
+		(*f.features)[name] = enabled
+	}
+	return nil
+}
+
 func compVersionFlag(chartRef string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	chartInfo := strings.Split(chartRef, "/")
 	if len(chartInfo) != 2 {
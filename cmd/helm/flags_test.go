@@ -18,9 +18,15 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"testing"
+	"time"
 
+	"github.com/spf13/pflag"
+
+	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
 	"helm.sh/helm/v3/pkg/release"
 	helmtime "helm.sh/helm/v3/pkg/time"
 )
@@ -86,3 +92,80 @@ func outputFlagCompletionTest(t *testing.T, cmdName string) {
 	}}
 	runTestCmd(t, tests)
 }
+
+func TestApplyReleaseDefaults(t *testing.T) {
+	store := storageFixture()
+	rel := release.Mock(&release.MockReleaseOptions{Name: "defaulted"})
+	rel.Defaults = &release.Defaults{Wait: true, WaitForJobs: true, Timeout: 10 * time.Minute, Atomic: true}
+	if err := store.Create(rel); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &action.Configuration{
+		Releases:   store,
+		KubeClient: &kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		Log:        func(format string, v ...interface{}) {},
+	}
+
+	var wait, waitForJobs, atomic bool
+	var timeout time.Duration
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	f.BoolVar(&wait, "wait", false, "")
+	f.BoolVar(&waitForJobs, "wait-for-jobs", false, "")
+	f.DurationVar(&timeout, "timeout", 300*time.Second, "")
+	f.BoolVar(&atomic, "atomic", false, "")
+	if err := f.Parse([]string{"--wait-for-jobs=false", "--timeout=1m"}); err != nil {
+		t.Fatal(err)
+	}
+
+	applyReleaseDefaults(cfg, f, "defaulted", &wait, &waitForJobs, &timeout, &atomic)
+	if !wait || !atomic {
+		t.Errorf("expected --wait and --atomic to default to the release's, got %t and %t", wait, atomic)
+	}
+	if waitForJobs || timeout != time.Minute {
+		t.Errorf("expected the flags given to be kept, got --wait-for-jobs=%t and --timeout=%s", waitForJobs, timeout)
+	}
+
+	// Releases without defaults leave the flags alone
+	wait = false
+	applyReleaseDefaults(cfg, f, "missing", &wait, &waitForJobs, &timeout, &atomic)
+	if wait {
+		t.Error("expected --wait to be left alone for a missing release")
+	}
+}
+
+func TestDryRunFlag(t *testing.T) {
+	tests := []struct {
+		args           []string
+		dryRun, server bool
+		mode           string
+	}{
+		{nil, false, false, "none"},
+		{[]string{"--dry-run"}, true, false, "client"},
+		{[]string{"--dry-run=client"}, true, false, "client"},
+		{[]string{"--dry-run=true"}, true, false, "client"},
+		{[]string{"--dry-run=server"}, true, true, "server"},
+		{[]string{"--dry-run=server", "--dry-run=false"}, false, false, "none"},
+	}
+	for _, tt := range tests {
+		var dryRun, server bool
+		f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		addDryRunFlag(f, &dryRun, &server, "simulate")
+		if err := f.Parse(tt.args); err != nil {
+			t.Fatalf("%v: unexpected error: %s", tt.args, err)
+		}
+		if dryRun != tt.dryRun || server != tt.server {
+			t.Errorf("%v: expected dry run %t and server %t, got %t and %t", tt.args, tt.dryRun, tt.server, dryRun, server)
+		}
+		if mode := f.Lookup("dry-run").Value.String(); mode != tt.mode {
+			t.Errorf("%v: expected mode %q, got %q", tt.args, tt.mode, mode)
+		}
+	}
+
+	var dryRun, server bool
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	addDryRunFlag(f, &dryRun, &server, "simulate")
+	if err := f.Parse([]string{"--dry-run=everything"}); err == nil {
+		t.Error("expected an invalid mode to fail")
+	}
+}
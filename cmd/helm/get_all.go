@@ -17,23 +17,46 @@ limitations under the License.
 package main
 
 import (
+	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/messages"
 	"helm.sh/helm/v3/pkg/cli/output"
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
 )
 
 var getAllHelp = `
 This command prints a human readable collection of information about the
 notes, hooks, supplied values, and generated manifest file of the given release.
+
+Use '--revision-range' to get several revisions at once. Either end of the
+range can be left out:
+
+    $ helm get all my-release --revision-range 3..7
+    $ helm get all my-release --revision-range 5..
+
+With '--output-dir', the information is written to files instead, one
+directory per revision, such as 'my-release/3/manifest.yaml'. This is handy
+to export the history of a release for later inspection:
+
+    $ helm get all my-release --revision-range 3..7 --output-dir ./history
 `
 
 func newGetAllCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
-	var template string
+	var template, revisionRange, outputDir string
 	client := action.NewGet(cfg)
 
 	cmd := &cobra.Command{
@@ -48,18 +71,52 @@ func newGetAllCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			return compListReleases(toComplete, cfg)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			res, err := client.Run(args[0])
-			if err != nil {
-				return err
-			}
-			if template != "" {
-				data := map[string]interface{}{
-					"Release": res,
+			var rels []*release.Release
+			if revisionRange != "" {
+				if client.Version != 0 {
+					return errors.New("--revision and --revision-range cannot be used together")
+				}
+				from, to, err := parseRevisionRange(revisionRange)
+				if err != nil {
+					return err
 				}
-				return tpl(template, data, out)
+				if rels, err = client.RunRange(args[0], from, to); err != nil {
+					return err
+				}
+			} else {
+				res, err := client.Run(args[0])
+				if err != nil {
+					return err
+				}
+				rels = append(rels, res)
 			}
 
-			return output.Table.Write(out, &statusPrinter{res, true, false})
+			for i, res := range rels {
+				if outputDir != "" {
+					dir, err := writeRevision(outputDir, res)
+					if err != nil {
+						return err
+					}
+					info(out, messages.RevisionWritten, res.Version, res.Name, dir)
+					continue
+				}
+				if i > 0 {
+					fmt.Fprintln(out)
+				}
+				if template != "" {
+					data := map[string]interface{}{
+						"Release": res,
+					}
+					if err := tpl(template, data, out); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := output.Table.Write(out, &statusPrinter{res, true, false, false}); err != nil {
+					return err
+				}
+			}
+			return nil
 		},
 	}
 
@@ -77,6 +134,107 @@ func newGetAllCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	}
 
 	f.StringVar(&template, "template", "", "go template for formatting the output, eg: {{.Release.Name}}")
+	f.StringVar(&revisionRange, "revision-range", "", "get the revisions of the named release in the range FROM..TO, eg: 3..7")
+	f.StringVar(&outputDir, "output-dir", "", "write the manifest, hooks, values and notes of each revision to files in a directory tree rooted at this path")
 
 	return cmd
 }
+
+// parseRevisionRange parses a range of revisions such as '3..7'. Either end
+// can be left out, in which case it is returned as 0.
+func parseRevisionRange(s string) (int, int, error) {
+	parts := strings.Split(s, "..")
+	if len(parts) != 2 {
+		return 0, 0, errors.Errorf("invalid revision range %q: must be of the form FROM..TO", s)
+	}
+	var bounds [2]int
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 1 {
+			return 0, 0, errors.Errorf("invalid revision range %q: %q is not a revision", s, p)
+		}
+		bounds[i] = v
+	}
+	return bounds[0], bounds[1], nil
+}
+
+// revisionInfo is the metadata of a revision written by writeRevision.
+type revisionInfo struct {
+	Name          string         `json:"name"`
+	Namespace     string         `json:"namespace"`
+	Revision      int            `json:"revision"`
+	Status        release.Status `json:"status"`
+	Chart         string         `json:"chart"`
+	AppVersion    string         `json:"app_version"`
+	Description   string         `json:"description"`
+	FirstDeployed helmtime.Time  `json:"first_deployed"`
+	LastDeployed  helmtime.Time  `json:"last_deployed"`
+}
+
+// writeRevision writes the information of a revision of a release to files in
+// the directory <dir>/<name>/<revision>, and returns that directory.
+func writeRevision(dir string, rel *release.Release) (string, error) {
+	dir = filepath.Join(dir, rel.Name, strconv.Itoa(rel.Version))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	info := revisionInfo{
+		Name:      rel.Name,
+		Namespace: rel.Namespace,
+		Revision:  rel.Version,
+	}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		info.Chart = fmt.Sprintf("%s-%s", rel.Chart.Metadata.Name, rel.Chart.Metadata.Version)
+		info.AppVersion = rel.Chart.Metadata.AppVersion
+	}
+	var notes string
+	if rel.Info != nil {
+		info.Status = rel.Info.Status
+		info.Description = rel.Info.Description
+		info.FirstDeployed = rel.Info.FirstDeployed
+		info.LastDeployed = rel.Info.LastDeployed
+		notes = rel.Info.Notes
+	}
+	infoData, err := yaml.Marshal(info)
+	if err != nil {
+		return "", err
+	}
+
+	values, err := redactValues(rel.Config)
+	if err != nil {
+		return "", err
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	valuesData, err := yaml.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	var hooks strings.Builder
+	for _, h := range rel.Hooks {
+		fmt.Fprintf(&hooks, "---\n# Source: %s\n%s\n", h.Path, h.Manifest)
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"release.yaml", infoData},
+		{"values.yaml", valuesData},
+		{"manifest.yaml", []byte(rel.Manifest)},
+		{"hooks.yaml", []byte(hooks.String())},
+		{"notes.txt", []byte(notes)},
+	}
+	for _, f := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, f.name), f.data, 0644); err != nil {
+			return "", errors.Wrapf(err, "failed to write revision %d of %s", rel.Version, rel.Name)
+		}
+	}
+	return dir, nil
+}
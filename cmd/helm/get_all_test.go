@@ -17,8 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
+	"helm.sh/helm/v3/internal/test/ensure"
 	"helm.sh/helm/v3/pkg/release"
 )
 
@@ -38,6 +41,21 @@ func TestGetCmd(t *testing.T) {
 		cmd:       "get all",
 		golden:    "output/get-all-no-args.txt",
 		wantError: true,
+	}, {
+		name:      "get all with an invalid revision range",
+		cmd:       "get all thomas-guide --revision-range 3",
+		rels:      []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+		wantError: true,
+	}, {
+		name:      "get all with a revision range without revisions",
+		cmd:       "get all thomas-guide --revision-range 2..3",
+		rels:      []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+		wantError: true,
+	}, {
+		name:      "get all with both a revision and a revision range",
+		cmd:       "get all thomas-guide --revision 1 --revision-range 1..",
+		rels:      []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+		wantError: true,
 	}}
 	runTestCmd(t, tests)
 }
@@ -50,3 +68,68 @@ func TestGetAllFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "get all", false)
 	checkFileCompletion(t, "get all myrelease", false)
 }
+
+func TestParseRevisionRange(t *testing.T) {
+	tests := []struct {
+		in       string
+		from, to int
+		wantErr  bool
+	}{
+		{"3..7", 3, 7, false},
+		{"3..", 3, 0, false},
+		{"..7", 0, 7, false},
+		{"..", 0, 0, false},
+		{"3", 0, 0, true},
+		{"a..7", 0, 0, true},
+		{"0..7", 0, 0, true},
+		{"1..2..3", 0, 0, true},
+	}
+	for _, tt := range tests {
+		from, to, err := parseRevisionRange(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: expected error %t, got %v", tt.in, tt.wantErr, err)
+			continue
+		}
+		if from != tt.from || to != tt.to {
+			t.Errorf("%q: expected %d..%d, got %d..%d", tt.in, tt.from, tt.to, from, to)
+		}
+	}
+}
+
+func TestGetAllOutputDir(t *testing.T) {
+	dir := ensure.TempDir(t)
+
+	store := storageFixture()
+	for v := 1; v <= 4; v++ {
+		rel := release.Mock(&release.MockReleaseOptions{Name: "thomas-guide", Version: v, Status: release.StatusSuperseded})
+		if err := store.Create(rel); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, _, err := executeActionCommandC(store, "get all thomas-guide --revision-range 2..3 --output-dir "+dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, revision := range []string{"2", "3"} {
+		for _, name := range []string{"release.yaml", "values.yaml", "manifest.yaml", "hooks.yaml", "notes.txt"} {
+			if _, err := ioutil.ReadFile(filepath.Join(dir, "thomas-guide", revision, name)); err != nil {
+				t.Errorf("expected %s of revision %s to be written: %s", name, revision, err)
+			}
+		}
+	}
+	for _, revision := range []string{"1", "4"} {
+		if _, err := ioutil.ReadDir(filepath.Join(dir, "thomas-guide", revision)); err == nil {
+			t.Errorf("expected revision %s not to be written", revision)
+		}
+	}
+
+	notes, err := ioutil.ReadFile(filepath.Join(dir, "thomas-guide", "2", "notes.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(notes) != "Some mock release notes!" {
+		t.Errorf("unexpected notes %q", notes)
+	}
+}
@@ -25,15 +25,23 @@ import (
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+	"helm.sh/helm/v3/pkg/release"
 )
 
 const getHooksHelp = `
 This command downloads hooks for a given release.
 
 Hooks are formatted in YAML and separated by the YAML '---\n' separator.
+
+With '--output json' or '--output yaml', the hooks are printed as objects
+instead, along with the history of their executions across the revisions of
+the release: when each attempt started and completed, its outcome, the
+resource it created and, for failures, why it failed.
 `
 
 func newGetHooksCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	var outfmt output.Format
 	client := action.NewGet(cfg)
 
 	cmd := &cobra.Command{
@@ -52,10 +60,7 @@ func newGetHooksCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			for _, hook := range res.Hooks {
-				fmt.Fprintf(out, "---\n# Source: %s\n%s\n", hook.Path, hook.Manifest)
-			}
-			return nil
+			return outfmt.Write(out, &hooksWriter{res.Hooks})
 		},
 	}
 
@@ -71,5 +76,34 @@ func newGetHooksCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		log.Fatal(err)
 	}
 
+	bindOutputFlag(cmd, &outfmt)
+
 	return cmd
 }
+
+type hooksWriter struct {
+	hooks []*release.Hook
+}
+
+func (w *hooksWriter) WriteTable(out io.Writer) error {
+	for _, hook := range w.hooks {
+		fmt.Fprintf(out, "---\n# Source: %s\n%s\n", hook.Path, hook.Manifest)
+	}
+	return nil
+}
+
+func (w *hooksWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.list())
+}
+
+func (w *hooksWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.list())
+}
+
+// list never encodes the hooks as null.
+func (w *hooksWriter) list() []*release.Hook {
+	if w.hooks == nil {
+		return []*release.Hook{}
+	}
+	return w.hooks
+}
@@ -18,6 +18,7 @@ package main
 
 import (
 	"testing"
+	"time"
 
 	"helm.sh/helm/v3/pkg/release"
 )
@@ -28,6 +29,11 @@ func TestGetHooks(t *testing.T) {
 		cmd:    "get hooks aeneas",
 		golden: "output/get-hooks.txt",
 		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "aeneas"})},
+	}, {
+		name:   "get hooks with their executions",
+		cmd:    "get hooks aeneas --output json",
+		golden: "output/get-hooks.json",
+		rels:   []*release.Release{failedHookRelease()},
 	}, {
 		name:      "get hooks without args",
 		cmd:       "get hooks",
@@ -37,6 +43,24 @@ func TestGetHooks(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+// failedHookRelease returns a release whose hook failed.
+func failedHookRelease() *release.Release {
+	rel := release.Mock(&release.MockReleaseOptions{Name: "aeneas"})
+	started := rel.Info.LastDeployed
+	h := rel.Hooks[0]
+	h.LastRun = release.HookExecution{
+		StartedAt:   started,
+		CompletedAt: started.Add(time.Minute),
+		Phase:       release.HookPhaseFailed,
+		Revision:    1,
+		Event:       release.HookPreInstall,
+		Message:     "job failed: BackoffLimitExceeded",
+		Resource:    &release.HookResource{Kind: "Job", Name: "pre-install-hook", Namespace: "default"},
+	}
+	h.RecordLastRun()
+	return rel
+}
+
 func TestGetHooksRevisionCompletion(t *testing.T) {
 	revisionFlagCompletionTest(t, "get hooks")
 }
@@ -29,9 +29,16 @@ import (
 
 var getNotesHelp = `
 This command shows notes provided by the chart of a named release.
+
+With '--compare-to', the changes to the notes since the given revision are
+shown instead, as a unified diff, so that instructions that changed between
+chart versions are not missed:
+
+    $ helm get notes my-release --compare-to 3
 `
 
 func newGetNotesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	var compareTo int
 	client := action.NewGet(cfg)
 
 	cmd := &cobra.Command{
@@ -50,6 +57,16 @@ func newGetNotesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if compareTo > 0 {
+				from := action.NewGet(cfg)
+				from.Version = compareTo
+				prev, err := from.Run(args[0])
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(out, action.NotesDiff(prev, res))
+				return nil
+			}
 			if len(res.Info.Notes) > 0 {
 				fmt.Fprintf(out, "NOTES:\n%s\n", res.Info.Notes)
 			}
@@ -59,15 +76,18 @@ func newGetNotesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	f.IntVar(&client.Version, "revision", 0, "get the named release with revision")
-	err := cmd.RegisterFlagCompletionFunc("revision", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		if len(args) == 1 {
-			return compListRevisions(toComplete, cfg, args[0])
-		}
-		return nil, cobra.ShellCompDirectiveNoFileComp
-	})
+	f.IntVar(&compareTo, "compare-to", 0, "show the changes to the notes since this revision")
+	for _, flag := range []string{"revision", "compare-to"} {
+		err := cmd.RegisterFlagCompletionFunc(flag, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return compListRevisions(toComplete, cfg, args[0])
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		})
 
-	if err != nil {
-		log.Fatal(err)
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
 
 	return cmd
@@ -23,6 +23,9 @@ import (
 )
 
 func TestGetNotesCmd(t *testing.T) {
+	upgraded := release.Mock(&release.MockReleaseOptions{Name: "the-limerick", Version: 2})
+	upgraded.Info.Notes = "Some updated mock release notes!"
+
 	tests := []cmdTestCase{{
 		name:   "get notes of a deployed release",
 		cmd:    "get notes the-limerick",
@@ -33,6 +36,14 @@ func TestGetNotesCmd(t *testing.T) {
 		cmd:       "get notes",
 		golden:    "output/get-notes-no-args.txt",
 		wantError: true,
+	}, {
+		name:   "get notes changes since a revision",
+		cmd:    "get notes the-limerick --compare-to 1",
+		golden: "output/get-notes-compare-to.txt",
+		rels: []*release.Release{
+			release.Mock(&release.MockReleaseOptions{Name: "the-limerick", Version: 1}),
+			upgraded,
+		},
 	}}
 	runTestCmd(t, tests)
 }
@@ -0,0 +1,100 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+	"log"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+)
+
+var getResourcesHelp = `
+This command lists the live Kubernetes objects belonging to a release.
+
+The objects are the resources of the release's manifest, its hooks that still
+exist, and the objects labeled with the name of the release through the
+'app.kubernetes.io/instance' label, such as the Pods of its Deployments. The
+SOURCE column tells how each object was found. The resources of the manifest
+that do not exist in the cluster are listed with the status 'Missing'.
+`
+
+func newGetResourcesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	var outfmt output.Format
+	client := action.NewGetResources(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "resources RELEASE_NAME",
+		Short: "list the live resources of a named release",
+		Long:  getResourcesHelp,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return compListReleases(toComplete, cfg)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			res, err := client.Run(args[0])
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, releaseResources(res))
+		},
+	}
+
+	f := cmd.Flags()
+	f.IntVar(&client.Version, "revision", 0, "get the named release with revision")
+	err := cmd.RegisterFlagCompletionFunc("revision", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 1 {
+			return compListRevisions(toComplete, cfg, args[0])
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type releaseResources []action.ReleaseResource
+
+func (r releaseResources) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, r)
+}
+
+func (r releaseResources) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, r)
+}
+
+func (r releaseResources) WriteTable(out io.Writer) error {
+	tbl := uitable.New()
+	tbl.AddRow("KIND", "NAME", "NAMESPACE", "STATUS", "SOURCE")
+	for _, item := range r {
+		tbl.AddRow(item.Kind, item.Name, item.Namespace, item.Status, item.Source)
+	}
+	return output.EncodeTable(out, tbl)
+}
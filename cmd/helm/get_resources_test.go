@@ -0,0 +1,42 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestGetResources(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "get resources with release",
+		cmd:    "get resources juno",
+		golden: "output/get-resources.txt",
+		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "juno"})},
+	}, {
+		name:      "get resources without args",
+		cmd:       "get resources",
+		golden:    "output/get-resources-no-args.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestGetResourcesRevisionCompletion(t *testing.T) {
+	revisionFlagCompletionTest(t, "get resources")
+}
@@ -57,6 +57,9 @@ func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if vals, err = redactValues(vals); err != nil {
+				return err
+			}
 			return outfmt.Write(out, &valuesWriter{vals, client.AllValues})
 		},
 	}
@@ -17,8 +17,10 @@ limitations under the License.
 package main
 
 import (
+	"os"
 	"testing"
 
+	"helm.sh/helm/v3/pkg/helmpath"
 	"helm.sh/helm/v3/pkg/release"
 )
 
@@ -53,6 +55,24 @@ func TestGetValuesCmd(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestGetValuesCmdProfile(t *testing.T) {
+	defer resetEnv()()
+	os.Setenv(helmpath.ConfigHomeEnvVar, "testdata/helmhome/helm")
+
+	tests := []cmdTestCase{{
+		name:   "get values with redacted values",
+		cmd:    "get values thomas-guide --profile redacted --output yaml",
+		golden: "output/values-redacted.yaml",
+		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+	}, {
+		name:      "get values with a missing profile",
+		cmd:       "get values thomas-guide --profile missing",
+		rels:      []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestGetValuesRevisionCompletion(t *testing.T) {
 	revisionFlagCompletionTest(t, "get values")
 }
@@ -18,21 +18,30 @@ package main // import "helm.sh/helm/v3/cmd/helm"
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/yaml"
 
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	// Import to initialize client auth plugins.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
 
+	"helm.sh/helm/v3/internal/experimental/registry"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/cli/messages"
 	"helm.sh/helm/v3/pkg/gates"
+	"helm.sh/helm/v3/pkg/helmpath"
 	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/notify"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage/driver"
 )
@@ -42,6 +51,13 @@ const FeatureGateOCI = gates.Gate("HELM_EXPERIMENTAL_OCI")
 
 var settings = cli.New()
 
+// profile is the configuration profile in use, if any.
+var profile *cli.Profile
+
+// msgs prints the messages that are not the output of commands. It is set up
+// by newRootCmd from the settings.
+var msgs = messages.NewPrinter(os.Stdout, os.Stderr, messages.English)
+
 func init() {
 	log.SetFlags(log.Lshortfile)
 }
@@ -54,8 +70,61 @@ func debug(format string, v ...interface{}) {
 }
 
 func warning(format string, v ...interface{}) {
-	format = fmt.Sprintf("WARNING: %s\n", format)
-	fmt.Fprintf(os.Stderr, format, v...)
+	msgs.Warning(messages.Warning, fmt.Sprintf(format, v...))
+}
+
+// info prints a message of the catalog reporting progress to out, unless
+// quiet.
+func info(out io.Writer, id messages.ID, v ...interface{}) {
+	msgs.Fprint(out, messages.LevelInfo, id, v...)
+}
+
+// verbose prints a detailed message of the catalog, if verbose.
+func verbose(id messages.ID, v ...interface{}) {
+	msgs.Verbose(id, v...)
+}
+
+// warningPrinter prints the warnings raised by actions, each once, apart from
+// the output of commands.
+type warningPrinter struct {
+	msgs    *messages.Printer
+	mu      sync.Mutex
+	printed map[string]bool
+}
+
+func newWarningPrinter(msgs *messages.Printer) *warningPrinter {
+	return &warningPrinter{msgs: msgs, printed: map[string]bool{}}
+}
+
+func (p *warningPrinter) HandleWarning(w action.Warning) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.printed[w.Message] {
+		return
+	}
+	p.printed[w.Message] = true
+	p.msgs.Warning(messages.ActionWarning, w.Reason, w.Message)
+}
+
+// initActionConfig initializes the configuration of the actions for the
+// given Kubernetes client getter and namespace.
+func initActionConfig(cfg *action.Configuration, getter genericclioptions.RESTClientGetter, namespace string) error {
+	helmDriver := os.Getenv("HELM_DRIVER")
+	if err := cfg.Init(getter, namespace, helmDriver, debug); err != nil {
+		return err
+	}
+	cfg.Warnings = newWarningPrinter(msgs)
+	cfg.Releases.DeltaValues, _ = strconv.ParseBool(os.Getenv("HELM_STORAGE_DELTA_VALUES"))
+	if err := initReleaseNaming(cfg); err != nil {
+		return err
+	}
+	if err := initNotifier(cfg); err != nil {
+		return err
+	}
+	if helmDriver == "memory" {
+		loadReleasesInMemory(cfg)
+	}
+	return nil
 }
 
 func main() {
@@ -68,13 +137,10 @@ func main() {
 
 	// run when each command's execute method is called
 	cobra.OnInitialize(func() {
-		helmDriver := os.Getenv("HELM_DRIVER")
-		if err := actionConfig.Init(settings.RESTClientGetter(), settings.Namespace(), helmDriver, debug); err != nil {
+		if err := initActionConfig(actionConfig, settings.RESTClientGetter(), settings.Namespace()); err != nil {
 			log.Fatal(err)
 		}
-		if helmDriver == "memory" {
-			loadReleasesInMemory(actionConfig)
-		}
+		rest.SetDefaultWarningHandler(actionConfig.KubeWarningHandler())
 	})
 
 	if err := cmd.Execute(); err != nil {
@@ -97,6 +163,71 @@ func checkOCIFeatureGate() func(_ *cobra.Command, _ []string) error {
 	}
 }
 
+// checkOCIChartRef returns an error for references to charts in registries
+// unless the OCI feature gate is enabled.
+func checkOCIChartRef(ref string) error {
+	if registry.IsOCI(ref) && !FeatureGateOCI.IsEnabled() {
+		return FeatureGateOCI.Error()
+	}
+	return nil
+}
+
+// ociRegistryClient returns the client to pull charts from registries with,
+// or nil unless the OCI feature gate is enabled.
+func ociRegistryClient(cfg *action.Configuration) *registry.Client {
+	if !FeatureGateOCI.IsEnabled() {
+		return nil
+	}
+	return cfg.RegistryClient
+}
+
+// initReleaseNaming sets the generator and the validator of release names
+// configured with the HELM_NAME_GENERATOR and HELM_NAME_PATTERN environment
+// variables.
+func initReleaseNaming(actionConfig *action.Configuration) error {
+	if spec := os.Getenv("HELM_NAME_GENERATOR"); spec != "" {
+		gen, err := action.NewNameGenerator(actionConfig, spec)
+		if err != nil {
+			return err
+		}
+		actionConfig.NameGenerator = gen
+	}
+	if pattern := os.Getenv("HELM_NAME_PATTERN"); pattern != "" {
+		v, err := action.NewNamePatternValidator(pattern)
+		if err != nil {
+			return err
+		}
+		actionConfig.NameValidator = v
+	}
+	return nil
+}
+
+// initNotifier sets the notifier posting the events of releases to the
+// webhooks of the file named by HELM_NOTIFIERS_CONFIG, notifiers.yaml in the
+// configuration directory by default. Nothing is posted in offline mode.
+func initNotifier(actionConfig *action.Configuration) error {
+	if settings.Offline {
+		return nil
+	}
+	path := os.Getenv("HELM_NOTIFIERS_CONFIG")
+	if path == "" {
+		path = helmpath.ConfigPath("notifiers.yaml")
+	}
+	c, err := notify.LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	if len(c.Webhooks) == 0 {
+		return nil
+	}
+	n, err := notify.New(c)
+	if err != nil {
+		return err
+	}
+	actionConfig.Notifier = n
+	return nil
+}
+
 // This function loads releases into the memory storage if the
 // environment variable is properly set.
 func loadReleasesInMemory(actionConfig *action.Configuration) {
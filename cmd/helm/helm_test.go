@@ -32,6 +32,7 @@ import (
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/cli/messages"
 	kubefake "helm.sh/helm/v3/pkg/kube/fake"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage"
@@ -168,6 +169,7 @@ func resetEnv() func() {
 			os.Setenv(kv[0], kv[1])
 		}
 		settings = cli.New()
+		msgs = messages.NewPrinter(os.Stdout, os.Stderr, messages.English)
 	}
 }
 
@@ -238,3 +240,16 @@ func TestPluginExitCode(t *testing.T) {
 		}
 	}
 }
+
+func TestWarningPrinter(t *testing.T) {
+	var out bytes.Buffer
+	p := newWarningPrinter(messages.NewPrinter(ioutil.Discard, &out, messages.English))
+	p.HandleWarning(action.Warning{Reason: action.WarningKubernetes, Message: "policy/v1beta1 PodDisruptionBudget is deprecated"})
+	p.HandleWarning(action.Warning{Reason: action.WarningDeprecatedChart, Message: "chart hello-0.1.0 is deprecated"})
+	p.HandleWarning(action.Warning{Reason: action.WarningKubernetes, Message: "policy/v1beta1 PodDisruptionBudget is deprecated"})
+
+	expect := "WARNING: policy/v1beta1 PodDisruptionBudget is deprecated\nWARNING: chart hello-0.1.0 is deprecated\n"
+	if out.String() != expect {
+		t.Errorf("Expected %q, got %q", expect, out.String())
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+	"helm.sh/helm/v3/pkg/cli/values"
+)
+
+const imagesDesc = `
+List the container images run by a chart or a release.
+
+The argument is a chart if it is a path that exists, a chart reference such as
+'example/mariadb', a URL, or if '--repo' is set. The chart is rendered locally
+with the values given, as with 'helm template', and the images of its
+resources and hooks are listed. Otherwise, the argument is the name of a
+release, and the images of its manifest and hooks are listed:
+
+    $ helm images example/mariadb --set image.tag=10.5
+    $ helm images myrelease
+
+With '--unique', each image is listed once, sorted, which suits pre-pulling,
+mirroring or scanning them:
+
+    $ helm images --unique ./mychart | xargs -n1 docker pull
+`
+
+func newImagesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewImages(cfg)
+	installClient := action.NewInstall(cfg)
+	valueOpts := &values.Options{}
+	var outfmt output.Format
+	var unique bool
+
+	cmd := &cobra.Command{
+		Use:   "images [CHART|RELEASE]",
+		Short: "list the container images of a chart or a release",
+		Long:  imagesDesc,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return compListReleases(toComplete, cfg)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var images []action.Image
+			if isChartArg(args[0], installClient.RepoURL) {
+				installClient.DryRun = true
+				installClient.Replace = true // Skip the name check
+				installClient.ClientOnly = true
+				rel, err := runInstall([]string{"RELEASE-NAME", args[0]}, installClient, valueOpts, cmd.ErrOrStderr())
+				if err != nil {
+					return err
+				}
+				if images, err = action.ReleaseImages(rel); err != nil {
+					return err
+				}
+			} else {
+				var err error
+				if images, err = client.Run(args[0]); err != nil {
+					return err
+				}
+			}
+
+			if unique {
+				return outfmt.Write(out, uniqueImages(action.UniqueImages(images)))
+			}
+			return outfmt.Write(out, imageList(images))
+		},
+	}
+
+	f := cmd.Flags()
+	f.IntVar(&client.Version, "revision", 0, "list the images of the named release with revision")
+	f.BoolVar(&unique, "unique", false, "list each image once, sorted")
+	addValueOptionsFlags(f, valueOpts)
+	addChartPathOptionsFlags(f, &installClient.ChartPathOptions)
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+// isChartArg returns true if the argument of 'helm images' is a chart rather
+// than the name of a release, which cannot contain slashes.
+func isChartArg(arg, repoURL string) bool {
+	if repoURL != "" || strings.Contains(arg, "/") {
+		return true
+	}
+	_, err := os.Stat(arg)
+	return err == nil
+}
+
+type imageList []action.Image
+
+func (l imageList) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, l)
+}
+
+func (l imageList) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, l)
+}
+
+func (l imageList) WriteTable(out io.Writer) error {
+	tbl := uitable.New()
+	tbl.AddRow("IMAGE", "KIND", "NAME", "CONTAINER", "HOOK")
+	for _, img := range l {
+		tbl.AddRow(img.Image, img.Kind, img.Name, img.Container, img.Hook)
+	}
+	return output.EncodeTable(out, tbl)
+}
+
+type uniqueImages []string
+
+func (l uniqueImages) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, l)
+}
+
+func (l uniqueImages) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, l)
+}
+
+func (l uniqueImages) WriteTable(out io.Writer) error {
+	for _, image := range l {
+		if _, err := fmt.Fprintln(out, image); err != nil {
+			return err
+		}
+	}
+	return nil
+}
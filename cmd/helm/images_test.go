@@ -0,0 +1,45 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestImages(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "images of a chart",
+		cmd:    "images testdata/testcharts/alpine",
+		golden: "output/images-chart.txt",
+	}, {
+		name:   "unique images of a chart",
+		cmd:    "images --unique testdata/testcharts/alpine --set Name=other",
+		golden: "output/images-unique.txt",
+	}, {
+		name:   "images of a release",
+		cmd:    "images juno",
+		golden: "output/images-release.txt",
+		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "juno"})},
+	}, {
+		name:      "images of a missing release",
+		cmd:       "images missing",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
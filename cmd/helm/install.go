@@ -17,10 +17,13 @@ limitations under the License.
 package main
 
 import (
+	"fmt"
 	"io"
 	"log"
+	"os"
 	"time"
 
+	"github.com/gosuri/uitable"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -29,11 +32,15 @@ import (
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli/messages"
 	"helm.sh/helm/v3/pkg/cli/output"
 	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/downloader"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/kube"
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/secrets"
 )
 
 const installDesc = `
@@ -62,6 +69,14 @@ or
 
     $ helm install --set-file my_script=dothings.sh myredis ./redis
 
+or
+
+    $ helm install --set-json 'master.resources={"limits": {"memory": "1Gi"}}' myredis ./redis
+
+or
+
+    $ helm install --set-literal 'master.extraFlags[0]=--maxmemory-policy=a,b' myredis ./redis
+
 You can specify the '--values'/'-f' flag multiple times. The priority will be given to the
 last (right-most) file specified. For example, if both myvalues.yaml and override.yaml
 contained a key called 'Test', the value set in override.yaml would take precedence:
@@ -81,6 +96,13 @@ the '--debug' and '--dry-run' flags can be combined.
 If --verify is set, the chart MUST have a provenance file, and the provenance
 file MUST pass all verification steps.
 
+With '--record-defaults', the '--wait', '--wait-for-jobs', '--timeout' and
+'--atomic' flags are recorded with the release. Later upgrades and rollbacks
+of the release use them unless they set these flags themselves, whoever runs
+them:
+
+    $ helm install --record-defaults --atomic --timeout 10m myredis ./redis
+
 There are five different ways you can express the chart you want to install:
 
 1. By chart reference: helm install mymaria example/mariadb
@@ -101,12 +123,96 @@ supply a version number with the '--version' flag.
 
 To see the list of chart repositories, use 'helm repo list'. To search for
 charts in a repository, use 'helm search'.
+
+TRACING VALUES
+
+To find out where a value comes from, pass its dotted path to '--trace-values',
+such as 'image.tag' or 'mysubchart.replicas'. The chart is not installed.
+Instead, the final value is printed with the source that supplied it, and the
+values of the other sources it overrides: the values.yaml of a chart, a parent
+chart's values.yaml, a values file, or one of the --set flags.
+
+    $ helm install -f prod.yaml --trace-values image.tag myredis ./redis
+
+SECRET REFERENCES
+
+Values can refer to secrets instead of holding them, with strings of the form
+'ref+<backend>://<path>#<key>'. The references are resolved when the chart is
+rendered, so secrets never need to be written to values files, and the values
+stored in the release keep the references:
+
+    $ helm install --set db.password=ref+vault://secret/data/db#password mydb ./db
+
+The 'env' backend reads environment variables, such as 'ref+env://DB_PASSWORD',
+and the 'file' backend reads a key of a YAML file, such as
+'ref+file://secrets.yaml#db.password'. Other backends, such as 'vault' or
+'awssm', are provided by plugins. Each resolution is recorded in the debug log.
+
+SERVER DRY RUNS
+
+With '--dry-run=server', the resources and the install hooks are also sent to
+the API server in dry-run mode, so that they go through validation, the
+ValidatingAdmissionPolicies and the admission webhooks of the cluster without
+being persisted. Whether each of them was admitted, and the policy or webhook
+that denied it, is reported after the release, and the command fails if any
+was denied. '--dry-run' alone is the same as '--dry-run=client'. Resources in
+a namespace that '--create-namespace' would create are denied, as the
+namespace does not exist yet:
+
+    $ helm install --dry-run=server myredis ./redis
+
+With '--dry-run', '--capabilities-snapshot' renders the chart with the
+capabilities recorded by 'helm capabilities export' in another cluster instead
+of those of the current one, to see what the release would be there.
+
+RESOURCE QUOTAS
+
+With '--check-quota', the CPU and memory requested and limited by the pods of
+the workloads of the release, and the storage requested by its persistent
+volume claims, are compared with the ResourceQuotas of the namespace before
+anything is installed. The install fails with a report of each quota that would
+be exceeded, rather than when the API server rejects a resource half-way
+through. Scoped quotas are not checked, and the pods of DaemonSets and CronJobs
+are counted once.
+
+MIGRATIONS
+
+Jobs annotated with 'helm.sh/hook: migration', such as database migrations, run
+after the pre-install hooks and before the resources of the release are
+created. The version of the chart they succeeded for is recorded in the
+release, so that upgrades only run them again for a new version of the chart.
+Their Pods are not retried unless the Job sets a 'backoffLimit'.
+
+CLIENT FEATURES
+
+Templates can check the features of the client in '.Helm.Features', a map of
+feature names to whether they are enabled. The built-in features 'dryRun',
+'serverDryRun' and 'clientOnly' tell how the chart is being rendered. Other
+features are enabled with '--feature', or with $HELM_FEATURES:
+
+    $ helm install --feature canary --feature legacyIngress=false myapp ./myapp
+
+Templates should check them with 'index', such as '{{ if index .Helm.Features
+"canary" }}', so that they render when the feature is not given at all.
+
+STANDARD LABELS
+
+With '--standard-labels', the recommended labels 'app.kubernetes.io/name',
+'app.kubernetes.io/instance' and 'app.kubernetes.io/version', and the
+'helm.sh/chart' label, are added to the resources of the release from the name
+of the release and the metadata of its chart, after any post-renderer. The
+labels that the chart already sets to other values are kept, unless
+'--label-conflicts' is 'overwrite', or 'error' to fail. The labels are added to
+the resources when they are sent to the cluster, not to the manifest of the
+release, and hooks are left alone.
 `
 
 func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewInstall(cfg)
+	client.RegistryClient = ociRegistryClient(cfg)
 	valueOpts := &values.Options{}
 	var outfmt output.Format
+	var traceValues []string
 
 	cmd := &cobra.Command{
 		Use:   "install [NAME] [CHART]",
@@ -116,31 +222,109 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			return compInstall(args, toComplete, client)
 		},
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(traceValues) > 0 {
+				return runTraceValues(args, client, valueOpts, traceValues, out)
+			}
+			if err := applyProfileDefaults(cmd.Flags(), &client.Wait, &client.WaitForJobs, &client.Timeout, &client.PostRenderer); err != nil {
+				return err
+			}
 			rel, err := runInstall(args, client, valueOpts, out)
 			if err != nil {
 				return err
 			}
 
-			return outfmt.Write(out, &statusPrinter{rel, settings.Debug, false})
+			if err := outfmt.Write(out, &statusPrinter{rel, settings.Debug, false, false}); err != nil {
+				return err
+			}
+			return reportAdmission(admissionOut(cmd, outfmt, out), client.ServerDryRun && client.DryRun, client.AdmissionResults)
 		},
 	}
 
 	addInstallFlags(cmd, cmd.Flags(), client, valueOpts)
+	cmd.Flags().StringArrayVar(&traceValues, "trace-values", []string{}, "print where the value at the given dotted path comes from instead of installing the chart (can specify multiple)")
 	bindOutputFlag(cmd, &outfmt)
 	bindPostRenderFlag(cmd, &client.PostRenderer)
 
 	return cmd
 }
 
+// admissionOut returns where to report the admission of the resources, apart
+// from structured output.
+func admissionOut(cmd *cobra.Command, outfmt output.Format, out io.Writer) io.Writer {
+	if outfmt != output.Table {
+		return cmd.ErrOrStderr()
+	}
+	return out
+}
+
+// reportAdmission writes the outcome of the server-side dry run of each
+// resource, if one was done, and returns an error if any of them would have
+// been rejected.
+func reportAdmission(out io.Writer, serverDryRun bool, results []kube.AdmissionResult) error {
+	if !serverDryRun {
+		return nil
+	}
+	fmt.Fprintln(out, "SERVER DRY RUN:")
+	table := uitable.New()
+	table.AddRow("KIND", "NAME", "NAMESPACE", "HOOK", "ADMITTED", "DENIED BY")
+	for _, r := range results {
+		table.AddRow(r.Kind, r.Name, r.Namespace, r.Hook, r.Allowed, r.DeniedBy())
+	}
+	if err := output.EncodeTable(out, table); err != nil {
+		return err
+	}
+
+	denied := action.DeniedAdmissions(results)
+	if len(denied) == 0 {
+		return nil
+	}
+	for _, r := range denied {
+		fmt.Fprintf(out, "%s/%s: %s\n", r.Kind, r.Name, r.Message)
+	}
+	return errors.Errorf("%d of %d resources would be rejected by the API server", len(denied), len(results))
+}
+
+// runTraceValues prints where the values at the given keys come from.
+func runTraceValues(args []string, client *action.Install, valueOpts *values.Options, keys []string, out io.Writer) error {
+	ch, vals, sources, err := loadChartWithValueSources(args, client, valueOpts)
+	if err != nil {
+		return err
+	}
+
+	for i, key := range keys {
+		trace, err := chartutil.TraceValue(ch, vals, sources, key)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprint(out, trace.String())
+	}
+	return nil
+}
+
 func addInstallFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.Install, valueOpts *values.Options) {
 	f.BoolVar(&client.CreateNamespace, "create-namespace", false, "create the release namespace if not present")
-	f.BoolVar(&client.DryRun, "dry-run", false, "simulate an install")
+	addDryRunFlag(f, &client.DryRun, &client.ServerDryRun, "simulate an install. With --dry-run=server, the resources and hooks are also sent to the API server in dry-run mode, to check them against its admission policies and webhooks")
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "prevent hooks from running during install")
 	f.BoolVar(&client.Replace, "replace", false, "re-use the given name, only if that name is a deleted release which remains in the history. This is unsafe in production")
+	f.BoolVar(&client.OverridePause, "override-pause", false, "with --replace, re-use the name even if the release is paused")
+	f.BoolVar(&client.NoDeprecated, "no-deprecated", false, "fail instead of warning if the chart or one of its subcharts is deprecated")
+	f.BoolVar(&client.StrictCompatibility, "strict-compatibility", false, "fail instead of warning if the compatibility matrix of the chart or one of its subcharts does not support the app or Kubernetes version")
+	f.BoolVar(&client.StrictValues, "strict-values", false, "fail instead of warning if values that the schema of the chart or of one of its subcharts deprecates are set")
+	bindCapabilitiesSnapshotFlag(f, &client.CapabilitiesSnapshot)
+	f.BoolVar(&client.CheckQuota, "check-quota", false, "fail before installing anything if the resources requested by the release exceed the resource quotas of the namespace")
+	f.BoolVar(&client.AllowOwnershipTransfer, "allow-ownership-transfer", false, "adopt existing resources owned by another release or by other field managers instead of failing")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
 	f.BoolVar(&client.Wait, "wait", false, "if set, will wait until all Pods, PVCs, Services, and minimum number of Pods of a Deployment, StatefulSet, or ReplicaSet are in a ready state before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
+	bindWaitForFlag(f, &client.WaitFor)
+	bindFeatureFlag(f, &client.Features)
+	f.BoolVar(&client.StandardLabels, "standard-labels", false, "add the recommended app.kubernetes.io labels and the helm.sh/chart label to the resources of the release")
+	f.StringVar((*string)(&client.LabelConflicts), "label-conflicts", string(action.LabelConflictKeep), "with --standard-labels, what to do with the labels that resources already set to other values: keep, overwrite or error")
+	f.IntVar(&client.WaitForEndpoints, "wait-for-endpoints", 0, "if set, will wait until each Service with a selector has at least this number of ready endpoints before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVarP(&client.GenerateName, "generate-name", "g", false, "generate the name (and omit the NAME parameter)")
 	f.StringVar(&client.NameTemplate, "name-template", "", "specify template used to name the release")
 	f.StringVar(&client.Description, "description", "", "add a custom description")
@@ -150,7 +334,11 @@ func addInstallFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.Instal
 	f.BoolVar(&client.Atomic, "atomic", false, "if set, the installation process deletes the installation on failure. The --wait flag will be set automatically if --atomic is used")
 	f.BoolVar(&client.SkipCRDs, "skip-crds", false, "if set, no CRDs will be installed. By default, CRDs are installed if not already present")
 	f.BoolVar(&client.SubNotes, "render-subchart-notes", false, "if set, render subchart notes along with the parent")
+	f.BoolVar(&client.RecordDefaults, "record-defaults", false, "record --wait, --wait-for-jobs, --timeout and --atomic with the release as the defaults of its upgrades and rollbacks")
+	f.StringSliceVar(&client.OnlySubcharts, "only-subchart", []string{}, "render and install only the given subcharts (can specify multiple or separate values with commas: redis,ingress). Nested subcharts are given as dotted paths, such as backend.redis")
+	f.StringSliceVar(&client.SkipSubcharts, "skip-subchart", []string{}, "do not render nor install the given subcharts (can specify multiple or separate values with commas: metrics,ingress)")
 	addValueOptionsFlags(f, valueOpts)
+	addValuesRefFlag(f, &client.ValuesRefs)
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
 
 	err := cmd.RegisterFlagCompletionFunc("version", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -171,6 +359,12 @@ func addInstallFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.Instal
 
 func runInstall(args []string, client *action.Install, valueOpts *values.Options, out io.Writer) (*release.Release, error) {
 	debug("Original chart version: %q", client.Version)
+	if len(client.ValuesRefs) > 0 && !FeatureGateOCI.IsEnabled() {
+		return nil, FeatureGateOCI.Error()
+	}
+	if err := applyEnvFeatures(&client.Features); err != nil {
+		return nil, err
+	}
 	if client.Version == "" && client.Devel {
 		debug("setting version to >0.0.0-0")
 		client.Version = ">0.0.0-0"
@@ -181,6 +375,9 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 		return nil, err
 	}
 	client.ReleaseName = name
+	if err := checkOCIChartRef(chart); err != nil {
+		return nil, err
+	}
 
 	cp, err := client.ChartPathOptions.LocateChart(chart, settings)
 	if err != nil {
@@ -189,6 +386,8 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 
 	debug("CHART PATH: %s\n", cp)
 
+	client.SecretResolver = newSecretResolver()
+
 	p := getter.All(settings)
 	vals, err := valueOpts.MergeValues(p)
 	if err != nil {
@@ -200,31 +399,29 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 	if err != nil {
 		return nil, err
 	}
+	verbose(messages.LoadedChart, chartRequested.Name(), chartRequested.Metadata.Version, cp)
 
 	if err := checkIfInstallable(chartRequested); err != nil {
 		return nil, err
 	}
 
-	if chartRequested.Metadata.Deprecated {
-		warning("This chart is deprecated")
-	}
-
 	if req := chartRequested.Metadata.Dependencies; req != nil {
+		man := &downloader.Manager{
+			Out:              out,
+			ChartPath:        cp,
+			Keyring:          client.ChartPathOptions.Keyring,
+			SkipUpdate:       false,
+			Getters:          p,
+			RepositoryConfig: settings.RepositoryConfig,
+			RepositoryCache:  settings.RepositoryCache,
+			Debug:            settings.Debug,
+			RegistryClient:   client.RegistryClient,
+		}
 		// If CheckDependencies returns an error, we have unfulfilled dependencies.
 		// As of Helm 2.4.0, this is treated as a stopping condition:
 		// https://github.com/helm/helm/issues/2209
 		if err := action.CheckDependencies(chartRequested, req); err != nil {
 			if client.DependencyUpdate {
-				man := &downloader.Manager{
-					Out:              out,
-					ChartPath:        cp,
-					Keyring:          client.ChartPathOptions.Keyring,
-					SkipUpdate:       false,
-					Getters:          p,
-					RepositoryConfig: settings.RepositoryConfig,
-					RepositoryCache:  settings.RepositoryCache,
-					Debug:            settings.Debug,
-				}
 				if err := man.Update(); err != nil {
 					return nil, err
 				}
@@ -235,11 +432,39 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 			} else {
 				return nil, err
 			}
+		} else if client.DependencyUpdate && isChartDir(cp) {
+			// Dependencies from local directories may have changed without
+			// a version bump
+			changed, err := man.LocalChanges()
+			if err != nil {
+				return nil, err
+			}
+			if len(changed) > 0 {
+				man.SkipUpdate = true
+				if err := man.Build(); err != nil {
+					return nil, err
+				}
+				if chartRequested, err = loader.Load(cp); err != nil {
+					return nil, errors.Wrap(err, "failed reloading chart after rebuilding local dependencies")
+				}
+			}
 		}
 	}
 
 	client.Namespace = settings.Namespace()
-	return client.Run(chartRequested, vals)
+	rel, err := client.Run(chartRequested, vals)
+	if !client.StrictCompatibility {
+		for _, c := range client.Incompatibilities {
+			warning("%s", c)
+		}
+	}
+	return rel, err
+}
+
+// isChartDir returns true if the chart is an unpacked chart directory.
+func isChartDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
 }
 
 // checkIfInstallable validates if a chart can be installed
@@ -264,3 +489,11 @@ func compInstall(args []string, toComplete string, client *action.Install) ([]st
 	}
 	return nil, cobra.ShellCompDirectiveNoFileComp
 }
+
+// newSecretResolver returns a resolver of the secret references of values,
+// recording the resolutions in the debug log.
+func newSecretResolver() *secrets.Resolver {
+	r := secrets.NewResolver(secrets.All(settings))
+	r.Log = debug
+	return r
+}
@@ -0,0 +1,155 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli/output"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+)
+
+const installSetDesc = `
+This command installs several charts together, as separate releases, from a
+manifest describing them:
+
+    apiVersion: v1
+    values:
+      global:
+        domain: example.com
+    releases:
+      - name: db
+        chart: bitnami/postgresql
+        version: 10.3.11
+      - name: app
+        chart: ./charts/app
+        dependsOn: [db]
+        values:
+          replicaCount: 2
+
+The values of the manifest are shared by every release. The values of a
+release are merged over them, and the values given with '--values' and
+'--set' are merged over both. Relative chart paths are relative to the
+manifest.
+
+The releases are installed one at a time, in the order of the manifest, except
+that a release is always installed after the releases it depends on. With
+'--wait', every release is ready before the releases depending on it are
+installed. With '--atomic', a failure uninstalls all the releases of the set
+installed so far, so that the set is installed completely or not at all.
+
+The version of a release in the manifest takes precedence over '--version'.
+`
+
+func newInstallSetCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewInstallSet(cfg)
+	chartOpts := action.ChartPathOptions{RegistryClient: ociRegistryClient(cfg)}
+	valueOpts := &values.Options{}
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:   "install-set MANIFEST",
+		Short: "install several charts together from a manifest",
+		Long:  installSetDesc,
+		Args:  require.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			data, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			manifest, err := action.ParseInstallSetManifest(data)
+			if err != nil {
+				return err
+			}
+
+			vals, err := valueOpts.MergeValues(getter.All(settings))
+			if err != nil {
+				return err
+			}
+
+			client.Namespace = settings.Namespace()
+			client.LoadChart = installSetChartLoader(chartOpts, filepath.Dir(args[0]))
+			rels, err := client.Run(manifest, vals)
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, newReleaseListWriter(rels, ""))
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&client.CreateNamespace, "create-namespace", false, "create the release namespace if not present")
+	f.BoolVar(&client.DryRun, "dry-run", false, "simulate the installation of the set")
+	f.BoolVar(&client.DisableHooks, "no-hooks", false, "prevent hooks from running during install")
+	f.BoolVar(&client.Wait, "wait", false, "if set, will wait until all Pods, PVCs, Services, and minimum number of Pods of a Deployment, StatefulSet, or ReplicaSet are in a ready state before installing the next release. It will wait for as long as --timeout")
+	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before installing the next release. It will wait for as long as --timeout")
+	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	f.BoolVar(&client.Atomic, "atomic", false, "if set, the releases of the set installed so far are uninstalled when a release fails. The --wait flag will be set automatically if --atomic is used")
+	addValueOptionsFlags(f, valueOpts)
+	addChartPathOptionsFlags(f, &chartOpts)
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+// installSetChartLoader returns a loader for the charts of an install set,
+// resolving relative chart paths against dir.
+func installSetChartLoader(opts action.ChartPathOptions, dir string) func(action.InstallSetRelease) (*chart.Chart, error) {
+	return func(r action.InstallSetRelease) (*chart.Chart, error) {
+		ref := r.Chart
+		if strings.HasPrefix(ref, "./") || strings.HasPrefix(ref, "../") {
+			ref = filepath.Join(dir, ref)
+		}
+		if err := checkOCIChartRef(ref); err != nil {
+			return nil, err
+		}
+
+		c := opts
+		if r.Version != "" {
+			c.Version = r.Version
+		}
+		cp, err := c.LocateChart(ref, settings)
+		if err != nil {
+			return nil, err
+		}
+		debug("CHART PATH for %s: %s\n", r.Name, cp)
+
+		chrt, err := loader.Load(cp)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkIfInstallable(chrt); err != nil {
+			return nil, err
+		}
+		if req := chrt.Metadata.Dependencies; req != nil {
+			if err := action.CheckDependencies(chrt, req); err != nil {
+				return nil, err
+			}
+		}
+		return chrt, nil
+	}
+}
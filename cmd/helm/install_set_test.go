@@ -0,0 +1,38 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+)
+
+func TestInstallSetCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "install a set of charts in dependency order",
+		cmd:    "install-set testdata/installset/set.yaml -o json",
+		golden: "output/install-set.json",
+	}, {
+		name:      "install a set of charts with a dependency cycle",
+		cmd:       "install-set testdata/installset/cycle.yaml",
+		wantError: true,
+	}, {
+		name:      "install a set of charts from a missing manifest",
+		cmd:       "install-set testdata/installset/missing.yaml",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
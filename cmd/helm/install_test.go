@@ -17,8 +17,12 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 	"testing"
+
+	"helm.sh/helm/v3/pkg/kube"
 )
 
 func TestInstall(t *testing.T) {
@@ -202,11 +206,28 @@ func TestInstall(t *testing.T) {
 			cmd:    "install aeneas testdata/testcharts/deprecated --namespace default",
 			golden: "output/deprecated-chart.txt",
 		},
+		{
+			name:      "install deprecated chart with --no-deprecated",
+			cmd:       "install aeneas testdata/testcharts/deprecated --namespace default --no-deprecated",
+			wantError: true,
+			golden:    "output/deprecated-chart-no-deprecated.txt",
+		},
 		// Install chart with only crds
 		{
 			name: "install chart with only crds",
 			cmd:  "install crd-test testdata/testcharts/chart-with-only-crds --namespace default",
 		},
+		// Trace where a value comes from instead of installing
+		{
+			name:   "install with --trace-values",
+			cmd:    "install virgil testdata/testcharts/alpine --set Name=bar --trace-values Name",
+			golden: "output/install-trace-values.txt",
+		},
+		{
+			name:      "install with --trace-values for a missing key",
+			cmd:       "install virgil testdata/testcharts/alpine --trace-values missing",
+			wantError: true,
+		},
 	}
 
 	runTestActionCmd(t, tests)
@@ -252,3 +273,24 @@ func TestInstallFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "install myname", true)
 	checkFileCompletion(t, "install myname mychart", false)
 }
+
+func TestReportAdmission(t *testing.T) {
+	var out bytes.Buffer
+	if err := reportAdmission(&out, false, nil); err != nil || out.Len() != 0 {
+		t.Errorf("expected no report without a server dry run, got %q, %v", out.String(), err)
+	}
+
+	results := []kube.AdmissionResult{
+		{Kind: "Deployment", Name: "web", Namespace: "default", Allowed: true},
+		{Kind: "Job", Name: "migrate", Namespace: "default", Hook: true, Policy: "signed-images", Message: "image must be signed"},
+	}
+	err := reportAdmission(&out, true, results)
+	if err == nil || err.Error() != "1 of 2 resources would be rejected by the API server" {
+		t.Errorf("expected the denial to fail the command, got %v", err)
+	}
+	for _, s := range []string{"SERVER DRY RUN:", "ValidatingAdmissionPolicy/signed-images", "Job/migrate: image must be signed"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected the report to contain %q, got:\n%s", s, out.String())
+		}
+	}
+}
@@ -38,6 +38,18 @@ the chart is well-formed.
 If the linter encounters things that will cause the chart to fail installation,
 it will emit [ERROR] messages. If it encounters issues that break with convention
 or recommendation, it will emit [WARNING] messages.
+
+With '--value-references', the values of the chart are cross-referenced with
+its templates: the values of values.yaml that no template references are
+reported as [INFO] messages, and the '.Values' paths the templates reference
+that neither values.yaml nor values.schema.json define are reported as
+[WARNING] messages, as they are likely typos. The global values and those of
+the chart's dependencies are not checked.
+
+With '--matrix', the chart is linted with every combination of the values of
+its values matrix, ci/values-matrix.yaml, on top of the values given by flags
+(see 'helm template --help'). The messages found with some combinations only
+are followed by the combinations they were found with.
 `
 
 func newLintCmd(out io.Writer) *cobra.Command {
@@ -120,6 +132,9 @@ func newLintCmd(out io.Writer) *cobra.Command {
 	f := cmd.Flags()
 	f.BoolVar(&client.Strict, "strict", false, "fail on lint warnings")
 	f.BoolVar(&client.WithSubcharts, "with-subcharts", false, "lint dependent charts")
+	f.BoolVar(&client.ValueReferences, "value-references", false, "report the values never referenced by the templates, and those referenced but never defined")
+	bindCapabilitiesSnapshotFlag(f, &client.CapabilitiesSnapshot)
+	f.BoolVar(&client.Matrix, "matrix", false, "lint the chart with every combination of the values of its values matrix, ci/values-matrix.yaml")
 	addValueOptionsFlags(f, valueOpts)
 
 	return cmd
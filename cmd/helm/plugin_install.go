@@ -16,13 +16,13 @@ limitations under the License.
 package main
 
 import (
-	"fmt"
 	"io"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/cli/messages"
 	"helm.sh/helm/v3/pkg/plugin"
 	"helm.sh/helm/v3/pkg/plugin/installer"
 )
@@ -71,7 +71,7 @@ func (o *pluginInstallOptions) complete(args []string) error {
 func (o *pluginInstallOptions) run(out io.Writer) error {
 	installer.Debug = settings.Debug
 
-	i, err := installer.NewForSource(o.source, o.version)
+	i, err := installer.NewForSource(o.source, o.version, installer.OptOffline(settings.Offline))
 	if err != nil {
 		return err
 	}
@@ -89,6 +89,6 @@ func (o *pluginInstallOptions) run(out io.Writer) error {
 		return err
 	}
 
-	fmt.Fprintf(out, "Installed plugin: %s\n", p.Metadata.Name)
+	info(out, messages.PluginInstalled, p.Metadata.Name)
 	return nil
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"helm.sh/helm/v3/pkg/cli/messages"
 	"helm.sh/helm/v3/pkg/plugin"
 )
 
@@ -74,7 +75,7 @@ func (o *pluginUninstallOptions) run(out io.Writer) error {
 			if err := uninstallPlugin(found); err != nil {
 				errorPlugins = append(errorPlugins, fmt.Sprintf("Failed to uninstall plugin %s, got error (%v)", name, err))
 			} else {
-				fmt.Fprintf(out, "Uninstalled plugin: %s\n", name)
+				info(out, messages.PluginUninstalled, name)
 			}
 		} else {
 			errorPlugins = append(errorPlugins, fmt.Sprintf("Plugin: %s not found", name))
@@ -24,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"helm.sh/helm/v3/pkg/cli/messages"
 	"helm.sh/helm/v3/pkg/plugin"
 	"helm.sh/helm/v3/pkg/plugin/installer"
 )
@@ -77,7 +78,7 @@ func (o *pluginUpdateOptions) run(out io.Writer) error {
 			if err := updatePlugin(found); err != nil {
 				errorPlugins = append(errorPlugins, fmt.Sprintf("Failed to update plugin %s, got error (%v)", name, err))
 			} else {
-				fmt.Fprintf(out, "Updated plugin: %s\n", name)
+				info(out, messages.PluginUpdated, name)
 			}
 		} else {
 			errorPlugins = append(errorPlugins, fmt.Sprintf("Plugin: %s not found", name))
@@ -103,7 +104,7 @@ func updatePlugin(p *plugin.Plugin) error {
 	if err != nil {
 		return err
 	}
-	if err := installer.Update(i); err != nil {
+	if err := installer.Update(i, installer.OptOffline(settings.Offline)); err != nil {
 		return err
 	}
 
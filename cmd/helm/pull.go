@@ -35,15 +35,28 @@ also be used to perform cryptographic verification of a chart without installing
 the chart.
 
 There are options for unpacking the chart after download. This will create a
-directory for the chart and uncompress into that directory.
+directory for the chart and uncompress into that directory. With '--expand-deps',
+the dependencies of the chart are expanded into its charts/ directory as well,
+the missing ones being downloaded first, giving a working directory layout that
+can be edited right away:
+
+    $ helm pull --untar --expand-deps example/mariadb
 
 If the --verify flag is specified, the requested chart MUST have a provenance
 file, and MUST pass the verification process. Failure in any part of this will
 result in an error, and the chart will not be saved locally.
+
+With HELM_EXPERIMENTAL_OCI=1, charts can be pulled from registries by tag or by
+digest, the latter being immune to the tag being moved. '--version' takes an
+exact version, which is the tag, or a digest:
+
+    $ helm pull oci://registry.example.com/charts/mariadb --version 9.3.1
+    $ helm pull oci://registry.example.com/charts/mariadb@sha256:4c9b2f...e81a
 `
 
-func newPullCmd(out io.Writer) *cobra.Command {
+func newPullCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewPull()
+	client.RegistryClient = ociRegistryClient(cfg)
 
 	cmd := &cobra.Command{
 		Use:     "pull [chart URL | repo/chartname] [...]",
@@ -65,6 +78,9 @@ func newPullCmd(out io.Writer) *cobra.Command {
 			}
 
 			for i := 0; i < len(args); i++ {
+				if err := checkOCIChartRef(args[i]); err != nil {
+					return err
+				}
 				output, err := client.Run(args[i])
 				if err != nil {
 					return err
@@ -79,6 +95,7 @@ func newPullCmd(out io.Writer) *cobra.Command {
 	f.BoolVar(&client.Devel, "devel", false, "use development versions, too. Equivalent to version '>0.0.0-0'. If --version is set, this is ignored.")
 	f.BoolVar(&client.Untar, "untar", false, "if set to true, will untar the chart after downloading it")
 	f.BoolVar(&client.VerifyLater, "prov", false, "fetch the provenance file, but don't perform verification")
+	f.BoolVar(&client.ExpandDeps, "expand-deps", false, "if untar is specified, expand the dependencies of the chart into its charts/ directory, downloading those it does not vendor")
 	f.StringVar(&client.UntarDir, "untardir", ".", "if untar is specified, this flag specifies the name of the directory into which the chart is expanded")
 	f.StringVarP(&client.DestDir, "destination", "d", ".", "location to write the chart. If this and tardir are specified, tardir is appended to this")
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
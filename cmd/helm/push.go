@@ -0,0 +1,88 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+const pushDesc = `
+Package a chart and upload it to a registry in one step.
+
+The chart can be a chart directory, which is packaged in memory, or a packaged
+chart. Unlike 'helm chart save' and 'helm chart push', the local registry cache
+is left alone. If the reference has no tag, the version of the chart is used:
+
+    $ helm push ./mychart oci://registry.example.com/charts/mychart
+
+With '--reproducible', the entries of the archive of a chart directory are
+stamped with the time given by the SOURCE_DATE_EPOCH environment variable, or
+with the Unix epoch if it is not set, rather than the current time. Pushing
+the same chart then gives the same digest.
+`
+
+func newPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewPush(cfg)
+	var reproducible bool
+
+	cmd := &cobra.Command{
+		Use:               "push [chart] [ref]",
+		Short:             "package a chart and push it to a registry",
+		Long:              pushDesc,
+		Args:              require.ExactArgs(2),
+		Hidden:            !FeatureGateOCI.IsEnabled(),
+		PersistentPreRunE: checkOCIFeatureGate(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if reproducible {
+				modTime, err := sourceDateEpoch()
+				if err != nil {
+					return err
+				}
+				client.ModTime = modTime
+			}
+			return client.Run(out, args[0], args[1])
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&reproducible, "reproducible", false, "stamp the packaged chart with $SOURCE_DATE_EPOCH, or the Unix epoch, so that its digest is reproducible")
+
+	return cmd
+}
+
+// sourceDateEpoch returns the time of reproducible builds, given in seconds by
+// the SOURCE_DATE_EPOCH environment variable, defaulting to the Unix epoch.
+func sourceDateEpoch() (time.Time, error) {
+	s := os.Getenv("SOURCE_DATE_EPOCH")
+	if s == "" {
+		return time.Unix(0, 0), nil
+	}
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid SOURCE_DATE_EPOCH %q: it must be a number of seconds", s)
+	}
+	return time.Unix(sec, 0), nil
+}
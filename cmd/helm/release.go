@@ -0,0 +1,50 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+var releaseHelp = `
+This command consists of multiple subcommands which can be used to
+manage the release records kept in the storage backend.
+`
+
+func newReleaseCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "manage stored release records",
+		Long:  releaseHelp,
+		Args:  require.NoArgs,
+	}
+
+	cmd.AddCommand(newReleaseBackupCmd(cfg, out))
+	cmd.AddCommand(newReleaseRestoreCmd(cfg, out))
+	cmd.AddCommand(newReleaseRenameCmd(cfg, out))
+	cmd.AddCommand(newReleaseRelabelCmd(cfg, out))
+	cmd.AddCommand(newReleasePauseCmd(cfg, out))
+	cmd.AddCommand(newReleaseResumeCmd(cfg, out))
+	cmd.AddCommand(newReleaseVerifyCmd(cfg, out))
+
+	return cmd
+}
@@ -0,0 +1,79 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/messages"
+)
+
+var releaseBackupHelp = `
+This command writes the complete stored history of one or more releases to a
+file, so that it can be restored into another cluster with
+'helm release restore'.
+
+If no release names are given, every release in the namespace is included.
+The archive is written to standard output unless '--file' is set.
+
+The archive contains the rendered manifests and supplied values of every
+revision. Treat it with the same care as the release records themselves.
+`
+
+func newReleaseBackupCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewReleaseBackup(cfg)
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "backup [RELEASE_NAME...]",
+		Short: "write the history of releases to an archive",
+		Long:  releaseBackupHelp,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return compListReleases(toComplete, cfg)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			archive, err := client.Run(args...)
+			if err != nil {
+				return err
+			}
+
+			if file == "" || file == "-" {
+				return archive.Write(out)
+			}
+
+			f, err := os.Create(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if err := archive.Write(f); err != nil {
+				return err
+			}
+			info(out, messages.ReleasesBackedUp, len(archive.Releases), file)
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&file, "file", "f", "", "write the archive to this file instead of standard output")
+
+	return cmd
+}
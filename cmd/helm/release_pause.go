@@ -0,0 +1,97 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/messages"
+)
+
+var releasePauseHelp = `
+This command pauses a release.
+
+While a release is paused, 'helm install --replace', 'helm upgrade' and
+'helm rollback' refuse to act on it unless '--override-pause' is given. The
+resources of the release are left untouched. The pause is recorded in the
+release record, so it applies to every client of the storage backend.
+
+Use 'helm release resume' to lift the pause.
+`
+
+var releaseResumeHelp = `
+This command resumes a release paused with 'helm release pause'.
+`
+
+func newReleasePauseCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewReleasePause(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "pause RELEASE_NAME",
+		Short: "pause a release to prevent changes to it",
+		Long:  releasePauseHelp,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return compListReleases(toComplete, cfg)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := client.Run(args[0]); err != nil {
+				return err
+			}
+			info(out, messages.ReleasePaused, args[0])
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&client.Reason, "reason", "", "record why the release is paused")
+
+	return cmd
+}
+
+func newReleaseResumeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewReleaseResume(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "resume RELEASE_NAME",
+		Short: "resume a paused release",
+		Long:  releaseResumeHelp,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return compListReleases(toComplete, cfg)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := client.Run(args[0]); err != nil {
+				return err
+			}
+			info(out, messages.ReleaseResumed, args[0])
+			return nil
+		},
+	}
+
+	return cmd
+}
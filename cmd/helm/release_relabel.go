@@ -0,0 +1,95 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+var releaseRelabelHelp = `
+This command rewrites the ownership metadata of the resources of a release.
+
+The 'app.kubernetes.io/managed-by' label and the 'meta.helm.sh/release-name'
+and 'meta.helm.sh/release-namespace' annotations of every resource of the last
+revision of the release are set to the values Helm expects. Use it after
+migrating resources from another tool, or after a change of conventions, so
+that the next upgrade can adopt the resources.
+
+Before rewriting a resource, the managed fields of server-side apply are
+checked: if its ownership metadata is managed by a field manager other than
+Helm and the ones given with '--allow-manager', or if the resource is annotated for
+another release, the resource is reported as a conflict and left untouched.
+Use '--force' to rewrite those as well.
+
+Each resource is reported as 'relabeled', 'unchanged', 'missing' from the
+cluster, 'conflict', or 'outdated' with '--dry-run'. The command fails if any
+conflict remains.
+`
+
+func newReleaseRelabelCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewReleaseRelabel(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "relabel RELEASE_NAME",
+		Short: "rewrite the ownership metadata of the resources of a release",
+		Long:  releaseRelabelHelp,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return compListReleases(toComplete, cfg)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := client.Run(args[0])
+			if err != nil {
+				return err
+			}
+
+			table := uitable.New()
+			table.AddRow("KIND", "NAME", "NAMESPACE", "RESULT", "DETAIL")
+			conflicts := 0
+			for _, r := range results {
+				table.AddRow(r.Kind, r.Name, r.Namespace, r.State, r.Detail)
+				if r.State == action.ResourceConflict {
+					conflicts++
+				}
+			}
+			fmt.Fprintln(out, table)
+
+			if conflicts > 0 {
+				return errors.Errorf("%d resource(s) of release %q are owned by something else, use --allow-manager or --force to relabel them", conflicts, args[0])
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&client.DryRun, "dry-run", false, "report the resources that would be relabeled without changing them")
+	f.StringSliceVar(&client.AllowedManagers, "allow-manager", []string{}, "field manager, besides Helm, whose ownership metadata may be rewritten (can specify multiple)")
+	f.BoolVar(&client.Force, "force", false, "also rewrite the resources owned by other field managers or releases")
+
+	return cmd
+}
@@ -0,0 +1,80 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/messages"
+)
+
+var releaseRenameHelp = `
+This command renames a release without uninstalling it.
+
+The 'meta.helm.sh/release-name' annotation of the resources belonging to the
+release is updated, along with their 'app.kubernetes.io/instance' label when it
+holds the release name, and every stored revision of the release is then
+rewritten under the new name. The running workloads are left untouched.
+
+If updating the resources or storing the revisions fails, the changes made so
+far are reverted. The revisions under the old name are removed last: if that
+fails, the release is stored under both names, and the revisions left under
+the old name must be removed from the storage backend by hand.
+
+Resource names, selectors and pod template labels are not changed. Templates
+that derive them from '.Release.Name' will render different values on the next
+upgrade.
+`
+
+func newReleaseRenameCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewReleaseRename(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "rename RELEASE_NAME NEW_NAME",
+		Short: "rename a release",
+		Long:  releaseRenameHelp,
+		Args:  require.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return compListReleases(toComplete, cfg)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rels, err := client.Run(args[0], args[1])
+			if err != nil {
+				return err
+			}
+			if client.DryRun {
+				fmt.Fprintf(out, "Release %q would be renamed to %q (%d revisions)\n", args[0], args[1], len(rels))
+				return nil
+			}
+			info(out, messages.ReleaseRenamed, args[0], args[1])
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&client.DryRun, "dry-run", false, "simulate a rename")
+
+	return cmd
+}
@@ -0,0 +1,80 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/messages"
+)
+
+var releaseRestoreHelp = `
+This command reads an archive written by 'helm release backup' and stores
+every release record it contains in the current storage backend, preserving
+revision numbers and history. Every record must belong to the namespace the
+command runs in; restore the records of other namespaces with --namespace.
+
+Only the release records are restored. The Kubernetes resources belonging to
+the releases are not created; restore the records alongside the workloads
+themselves when migrating a cluster.
+
+Use '-' as the file name to read the archive from standard input.
+`
+
+func newReleaseRestoreCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewReleaseRestore(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "restore FILE",
+		Short: "restore release records from an archive",
+		Long:  releaseRestoreHelp,
+		Args:  require.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in := cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				in = f
+			}
+
+			archive, err := action.LoadReleaseArchive(in)
+			if err != nil {
+				return err
+			}
+			client.Namespace = settings.Namespace()
+			rels, err := client.Run(archive)
+			if err != nil {
+				return err
+			}
+			info(out, messages.ReleasesRestored, len(rels))
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&client.Force, "force", false, "replace release revisions that already exist")
+
+	return cmd
+}
@@ -35,6 +35,11 @@ The test command runs the tests for a release.
 
 The argument this command takes is the name of a deployed release.
 The tests to be run are defined in the chart that was installed.
+
+With '--unit', the argument is the path to a chart instead, and the unit tests
+in the chart's 'tests/' directory are run locally, without a cluster. Each
+'*_test.yaml' file in that directory describes a suite of test cases: the
+values to render the chart with, and assertions on the rendered documents.
 `
 
 func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -42,6 +47,7 @@ func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 	var outfmt = output.Table
 	var outputLogs bool
 	var filter []string
+	var unit bool
 
 	cmd := &cobra.Command{
 		Use:   "test [RELEASE]",
@@ -55,6 +61,10 @@ func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 			return compListReleases(toComplete, cfg)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if unit {
+				return runUnitTests(out, args[0], filter)
+			}
+
 			client.Namespace = settings.Namespace()
 			notName := regexp.MustCompile(`^!\s?name=`)
 			for _, f := range filter {
@@ -72,7 +82,7 @@ func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 				return runErr
 			}
 
-			if err := outfmt.Write(out, &statusPrinter{rel, settings.Debug, false}); err != nil {
+			if err := outfmt.Write(out, &statusPrinter{rel, settings.Debug, false, false}); err != nil {
 				return err
 			}
 
@@ -92,6 +102,43 @@ func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
 	f.BoolVar(&outputLogs, "logs", false, "dump the logs from test pods (this runs after all tests are complete, but before any cleanup)")
 	f.StringSliceVar(&filter, "filter", []string{}, "specify tests by attribute (currently \"name\") using attribute=value syntax or '!attribute=value' to exclude a test (can specify multiple or separate values with commas: name=test1,name=test2)")
+	f.BoolVar(&unit, "unit", false, "run the unit tests of the chart at the given path locally instead of testing a release. Unit tests can be filtered with --filter suite=NAME or name=NAME")
 
 	return cmd
 }
+
+func runUnitTests(out io.Writer, chartPath string, filter []string) error {
+	client := action.NewUnitTest()
+	for _, f := range filter {
+		for _, attr := range []string{"suite", "name"} {
+			if strings.HasPrefix(f, attr+"=") {
+				client.Filters[attr] = append(client.Filters[attr], strings.TrimPrefix(f, attr+"="))
+			}
+		}
+	}
+
+	report, err := client.Run(chartPath)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range report.Suites {
+		for _, c := range s.Cases {
+			if c.Passed() {
+				fmt.Fprintf(out, "PASS  %s: %s\n", s.Name, c.Name)
+				continue
+			}
+			fmt.Fprintf(out, "FAIL  %s: %s\n", s.Name, c.Name)
+			for _, failure := range c.Failures {
+				fmt.Fprintf(out, "      %s\n", strings.ReplaceAll(failure.Error(), "\n", "\n      "))
+			}
+		}
+	}
+
+	passed, failed := report.Counts()
+	fmt.Fprintf(out, "\nChart: %s, Suites: %d, Tests: %d passed, %d failed\n", report.Chart, len(report.Suites), passed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d unit tests failed", failed)
+	}
+	return nil
+}
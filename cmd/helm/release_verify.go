@@ -0,0 +1,87 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+var releaseVerifyHelp = `
+This command verifies the integrity of the revisions of a release.
+
+Each revision stored by this version of Helm records a checksum of its rendered
+manifests and hooks, and a checksum of the chart's values coalesced with the
+values of the release. This command recomputes the checksums and reports each
+revision as 'intact', 'modified' if its record was altered in the storage
+backend, or 'unverified' if it has no checksums.
+
+The command fails if any revision is modified. Use '--revision' to verify a
+single revision.
+`
+
+func newReleaseVerifyCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewReleaseVerify(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "verify RELEASE_NAME",
+		Short: "verify the integrity of the revisions of a release",
+		Long:  releaseVerifyHelp,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return compListReleases(toComplete, cfg)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := client.Run(args[0])
+			if err != nil {
+				return err
+			}
+
+			table := uitable.New()
+			table.AddRow("REVISION", "STATE", "MODIFIED")
+			var modified []string
+			for _, r := range results {
+				table.AddRow(r.Revision, r.State, strings.Join(r.Modified, ", "))
+				if r.State == action.RevisionModified {
+					modified = append(modified, fmt.Sprint(r.Revision))
+				}
+			}
+			fmt.Fprintln(out, table)
+
+			if len(modified) > 0 {
+				return errors.Errorf("release %q has modified revisions: %s", args[0], strings.Join(modified, ", "))
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.IntVar(&client.Version, "revision", 0, "verify only this revision")
+
+	return cmd
+}
@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/cli/messages"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/repo"
 )
@@ -49,8 +50,14 @@ type repoAddOptions struct {
 	username             string
 	password             string
 	forceUpdate          bool
+	replaceIfSameURL     bool
 	allowDeprecatedRepos bool
 
+	validate   string
+	keyring    string
+	retries    int
+	retryDelay time.Duration
+
 	certFile              string
 	keyFile               string
 	caFile                string
@@ -63,12 +70,33 @@ type repoAddOptions struct {
 	deprecatedNoUpdate bool
 }
 
+const repoAddDesc = `
+Add a chart repository.
+
+The repository is validated before it is added, with the strictness set by
+'--validate':
+
+    none:      the repository is not checked
+    reachable: its index can be fetched
+    index:     its index can be fetched and is valid (default)
+    signed:    its index is also signed, in an index.yaml.prov file next to it,
+               by a key of the keyring
+
+An index that cannot be fetched is fetched again up to '--retries' times,
+waiting '--retry-delay' before the first retry and twice as long before each
+of the next ones.
+
+An existing repository is only replaced with '--force-update', or, with
+'--replace-if-same-url', if it has the same URL.
+`
+
 func newRepoAddCmd(out io.Writer) *cobra.Command {
 	o := &repoAddOptions{}
 
 	cmd := &cobra.Command{
 		Use:               "add [NAME] [URL]",
 		Short:             "add a chart repository",
+		Long:              repoAddDesc,
 		Args:              require.ExactArgs(2),
 		ValidArgsFunction: noCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -90,6 +118,11 @@ func newRepoAddCmd(out io.Writer) *cobra.Command {
 	f.StringVar(&o.keyFile, "key-file", "", "identify HTTPS client using this SSL key file")
 	f.StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
 	f.BoolVar(&o.insecureSkipTLSverify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the repository")
+	f.BoolVar(&o.replaceIfSameURL, "replace-if-same-url", false, "replace the repo if it already exists with the same URL, for instance to update its credentials")
+	f.StringVar(&o.validate, "validate", repo.ValidateIndex.String(), fmt.Sprintf("how strictly the repository is validated. One of: %s", strings.Join(repo.ValidationLevels(), ", ")))
+	f.StringVar(&o.keyring, "keyring", defaultKeyring(), "location of the public keys trusted to sign the repository index")
+	f.IntVar(&o.retries, "retries", 0, "number of times the repository index is fetched again if it cannot be reached")
+	f.DurationVar(&o.retryDelay, "retry-delay", time.Second, "time to wait before retrying to fetch the repository index")
 	f.BoolVar(&o.allowDeprecatedRepos, "allow-deprecated-repos", false, "by default, this command will not allow adding official repos that have been permanently deleted. This disables that behavior")
 
 	return cmd
@@ -105,6 +138,15 @@ func (o *repoAddOptions) run(out io.Writer) error {
 		}
 	}
 
+	level := repo.ValidateIndex
+	if o.validate != "" {
+		l, err := repo.ParseValidationLevel(o.validate)
+		if err != nil {
+			return err
+		}
+		level = l
+	}
+
 	// Ensure the file directory exists as it is required for file locking
 	err := os.MkdirAll(filepath.Dir(o.repoFile), os.ModePerm)
 	if err != nil && !os.IsExist(err) {
@@ -157,19 +199,21 @@ func (o *repoAddOptions) run(out io.Writer) error {
 
 	// If the repo exists do one of two things:
 	// 1. If the configuration for the name is the same continue without error
-	// 2. When the config is different require --force-update
+	// 2. When the config is different require --force-update, or
+	//    --replace-if-same-url if the URL is the same
 	if !o.forceUpdate && f.Has(o.name) {
 		existing := f.Get(o.name)
-		if c != *existing {
+		if c == *existing {
+			// The add is idempotent so do nothing
+			info(out, messages.RepoUnchanged, o.name)
+			return nil
+		}
 
+		if !o.replaceIfSameURL || c.URL != existing.URL {
 			// The input coming in for the name is different from what is already
 			// configured. Return an error.
 			return errors.Errorf("repository name (%s) already exists, please specify a different name", o.name)
 		}
-
-		// The add is idempotent so do nothing
-		fmt.Fprintf(out, "%q already exists with the same configuration, skipping\n", o.name)
-		return nil
 	}
 
 	r, err := repo.NewChartRepository(&c, getter.All(settings))
@@ -180,7 +224,13 @@ func (o *repoAddOptions) run(out io.Writer) error {
 	if o.repoCache != "" {
 		r.CachePath = o.repoCache
 	}
-	if _, err := r.DownloadIndexFile(); err != nil {
+	err = r.Validate(repo.ValidateOptions{
+		Level:      level,
+		Keyring:    o.keyring,
+		Retries:    o.retries,
+		RetryDelay: o.retryDelay,
+	})
+	if err != nil {
 		return errors.Wrapf(err, "looks like %q is not a valid chart repository or cannot be reached", o.url)
 	}
 
@@ -189,6 +239,6 @@ func (o *repoAddOptions) run(out io.Writer) error {
 	if err := f.WriteFile(o.repoFile, 0644); err != nil {
 		return err
 	}
-	fmt.Fprintf(out, "%q has been added to your repositories\n", o.name)
+	info(out, messages.RepoAdded, o.name)
 	return nil
 }
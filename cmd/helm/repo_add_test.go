@@ -71,6 +71,32 @@ func TestRepoAddCmd(t *testing.T) {
 			cmd:    fmt.Sprintf("repo add test-name %s --repository-config %s --repository-cache %s --force-update", srv2.URL(), repoFile, tmpdir),
 			golden: "output/repo-add.txt",
 		},
+		{
+			name:   "replace repository with the same url",
+			cmd:    fmt.Sprintf("repo add test-name %s --repository-config %s --repository-cache %s --username admin --password secret --replace-if-same-url", srv2.URL(), repoFile, tmpdir),
+			golden: "output/repo-add.txt",
+		},
+		{
+			name:      "replace repository with a different url",
+			cmd:       fmt.Sprintf("repo add test-name %s --repository-config %s --repository-cache %s --replace-if-same-url", srv.URL(), repoFile, tmpdir),
+			wantError: true,
+		},
+		{
+			name:      "add repository with an unknown validation level",
+			cmd:       fmt.Sprintf("repo add other-name %s --repository-config %s --repository-cache %s --validate paranoid", srv.URL(), repoFile, tmpdir),
+			golden:    "output/repo-add-invalid-validation.txt",
+			wantError: true,
+		},
+		{
+			name:   "add unreachable repository without validation",
+			cmd:    fmt.Sprintf("repo add other-name %s/missing --repository-config %s --repository-cache %s --validate none", srv.URL(), repoFile, tmpdir),
+			golden: "output/repo-add-other.txt",
+		},
+		{
+			name:      "add unsigned repository",
+			cmd:       fmt.Sprintf("repo add signed-name %s --repository-config %s --repository-cache %s --validate signed --keyring testdata/helm-test-key.pub", srv.URL(), repoFile, tmpdir),
+			wantError: true,
+		},
 	}
 
 	runTestCmd(t, tests)
@@ -17,7 +17,6 @@ limitations under the License.
 package main
 
 import (
-	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -26,6 +25,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/cli/messages"
 	"helm.sh/helm/v3/pkg/helmpath"
 	"helm.sh/helm/v3/pkg/repo"
 )
@@ -74,7 +74,7 @@ func (o *repoRemoveOptions) run(out io.Writer) error {
 		if err := removeRepoCache(o.repoCache, name); err != nil {
 			return err
 		}
-		fmt.Fprintf(out, "%q has been removed from your repositories\n", name)
+		info(out, messages.RepoRemoved, name)
 	}
 
 	return nil
@@ -17,14 +17,19 @@ limitations under the License.
 package main
 
 import (
-	"fmt"
+	"bytes"
 	"io"
+	"net"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/cli/messages"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/repo"
 )
@@ -32,14 +37,32 @@ import (
 const updateDesc = `
 Update gets the latest information about charts from the respective chart repositories.
 Information is cached locally, where it is used by commands like 'helm search'.
+
+The repositories are updated in parallel. A repository whose index is not
+downloaded within '--timeout' is reported as failed, without holding back the
+others. The timeout applies to HTTP and HTTPS repositories; repositories served
+by getter plugins are waited for as long as the plugin runs.
+
+By default, the command succeeds even if some repositories could not be
+updated. Use '--fail-on any' to exit with an error if any repository failed,
+or '--fail-on all' to exit with an error only if none could be updated.
 `
 
 var errNoRepositories = errors.New("no repositories found. You must add one before updating")
 
+// Values of the --fail-on flag
+const (
+	failOnNever = "never"
+	failOnAny   = "any"
+	failOnAll   = "all"
+)
+
 type repoUpdateOptions struct {
-	update    func([]*repo.ChartRepository, io.Writer)
+	update    func([]*repo.ChartRepository, io.Writer, time.Duration) []string
 	repoFile  string
 	repoCache string
+	timeout   time.Duration
+	failOn    string
 }
 
 func newRepoUpdateCmd(out io.Writer) *cobra.Command {
@@ -58,10 +81,20 @@ func newRepoUpdateCmd(out io.Writer) *cobra.Command {
 			return o.run(out)
 		},
 	}
+
+	f := cmd.Flags()
+	f.DurationVar(&o.timeout, "timeout", 120*time.Second, "time to wait for the index of each repository. Use 0 to wait indefinitely")
+	f.StringVar(&o.failOn, "fail-on", failOnNever, "when to exit with an error if repositories could not be updated: never, any or all")
 	return cmd
 }
 
 func (o *repoUpdateOptions) run(out io.Writer) error {
+	switch o.failOn {
+	case "", failOnNever, failOnAny, failOnAll:
+	default:
+		return errors.Errorf("invalid --fail-on value %q: must be %s, %s or %s", o.failOn, failOnNever, failOnAny, failOnAll)
+	}
+
 	f, err := repo.LoadFile(o.repoFile)
 	if isNotExist(err) || len(f.Repositories) == 0 {
 		return errNoRepositories
@@ -78,24 +111,73 @@ func (o *repoUpdateOptions) run(out io.Writer) error {
 		repos = append(repos, r)
 	}
 
-	o.update(repos, out)
+	failed := o.update(repos, out, o.timeout)
+	if len(failed) == 0 {
+		return nil
+	}
+	if o.failOn == failOnAny || (o.failOn == failOnAll && len(failed) == len(repos)) {
+		return errors.Errorf("failed to update %d of %d repositories: %s", len(failed), len(repos), strings.Join(failed, ", "))
+	}
 	return nil
 }
 
-func updateCharts(repos []*repo.ChartRepository, out io.Writer) {
-	fmt.Fprintln(out, "Hang tight while we grab the latest from your chart repositories...")
-	var wg sync.WaitGroup
+// updateCharts downloads the index of each repository in parallel, giving up
+// on a repository after the timeout. It returns the names of the repositories
+// that could not be updated.
+func updateCharts(repos []*repo.ChartRepository, out io.Writer, timeout time.Duration) []string {
+	info(out, messages.RepoUpdating)
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed []string
+	)
 	for _, re := range repos {
 		wg.Add(1)
 		go func(re *repo.ChartRepository) {
 			defer wg.Done()
-			if _, err := re.DownloadIndexFile(); err != nil {
-				fmt.Fprintf(out, "...Unable to get an update from the %q chart repository (%s):\n\t%s\n", re.Config.Name, re.Config.URL, err)
+			err := downloadIndexFile(re, timeout)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed = append(failed, re.Config.Name)
+				info(out, messages.RepoUpdateFailed, re.Config.Name, re.Config.URL, err)
 			} else {
-				fmt.Fprintf(out, "...Successfully got an update from the %q chart repository\n", re.Config.Name)
+				info(out, messages.RepoUpdated, re.Config.Name)
 			}
 		}(re)
 	}
 	wg.Wait()
-	fmt.Fprintln(out, "Update Complete. ⎈Happy Helming!⎈")
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		info(out, messages.ReposUpdateFailed, len(failed), len(repos), strings.Join(failed, ", "))
+		return failed
+	}
+	info(out, messages.ReposUpdated)
+	return nil
+}
+
+// downloadIndexFile downloads the index of a repository, giving up after the
+// timeout. A zero timeout waits indefinitely. The request is cancelled by the
+// getter on timeout, so nothing is written to the cache afterwards.
+func downloadIndexFile(re *repo.ChartRepository, timeout time.Duration) error {
+	if timeout > 0 {
+		re.Client = timeoutGetter{Getter: re.Client, timeout: timeout}
+	}
+	_, err := re.DownloadIndexFile()
+	var netErr net.Error
+	if timeout > 0 && errors.As(err, &netErr) && netErr.Timeout() {
+		return errors.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
+
+// timeoutGetter sets the timeout of every request of a getter.
+type timeoutGetter struct {
+	getter.Getter
+	timeout time.Duration
+}
+
+func (g timeoutGetter) Get(url string, options ...getter.Option) (*bytes.Buffer, error) {
+	return g.Getter.Get(url, append(options, getter.WithTimeout(g.timeout))...)
 }
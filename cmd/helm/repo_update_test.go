@@ -19,10 +19,14 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"helm.sh/helm/v3/internal/test/ensure"
 	"helm.sh/helm/v3/pkg/getter"
@@ -34,10 +38,11 @@ func TestUpdateCmd(t *testing.T) {
 	var out bytes.Buffer
 	// Instead of using the HTTP updater, we provide our own for this test.
 	// The TestUpdateCharts test verifies the HTTP behavior independently.
-	updater := func(repos []*repo.ChartRepository, out io.Writer) {
+	updater := func(repos []*repo.ChartRepository, out io.Writer, timeout time.Duration) []string {
 		for _, re := range repos {
 			fmt.Fprintln(out, re.Config.Name)
 		}
+		return nil
 	}
 	o := &repoUpdateOptions{
 		update:   updater,
@@ -90,7 +95,7 @@ func TestUpdateCharts(t *testing.T) {
 	}
 
 	b := bytes.NewBuffer(nil)
-	updateCharts([]*repo.ChartRepository{r}, b)
+	updateCharts([]*repo.ChartRepository{r}, b, 0)
 
 	got := b.String()
 	if strings.Contains(got, "Unable to get an update") {
@@ -101,6 +106,73 @@ func TestUpdateCharts(t *testing.T) {
 	}
 }
 
+func TestUpdateChartsTimeout(t *testing.T) {
+	defer resetEnv()()
+	defer ensure.HelmHome(t)()
+
+	ts, err := repotest.NewTempServerWithCleanup(t, "testdata/testserver/*.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Stop()
+
+	// A repository that never answers in time
+	stall := make(chan struct{})
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-stall
+	}))
+	defer dead.Close()
+	defer close(stall)
+
+	var repos []*repo.ChartRepository
+	for _, e := range []*repo.Entry{{Name: "charts", URL: ts.URL()}, {Name: "dead", URL: dead.URL}} {
+		r, err := repo.NewChartRepository(e, getter.All(settings))
+		if err != nil {
+			t.Fatal(err)
+		}
+		repos = append(repos, r)
+	}
+
+	b := bytes.NewBuffer(nil)
+	failed := updateCharts(repos, b, 100*time.Millisecond)
+
+	if len(failed) != 1 || failed[0] != "dead" {
+		t.Errorf("expected the dead repository to fail, got %v", failed)
+	}
+	got := b.String()
+	if !strings.Contains(got, `Successfully got an update from the "charts" chart repository`) {
+		t.Errorf("expected the charts repository to be updated, got %q", got)
+	}
+	if !strings.Contains(got, "timed out after 100ms") {
+		t.Errorf("expected the dead repository to time out, got %q", got)
+	}
+}
+
+func TestUpdateCmdFailOn(t *testing.T) {
+	updater := func(repos []*repo.ChartRepository, out io.Writer, timeout time.Duration) []string {
+		return []string{"charts"}
+	}
+	tests := []struct {
+		failOn  string
+		wantErr bool
+	}{
+		{failOnNever, false},
+		{failOnAny, true},
+		{failOnAll, true},
+		{"sometimes", true},
+	}
+	for _, tt := range tests {
+		o := &repoUpdateOptions{
+			update:   updater,
+			repoFile: "testdata/repositories.yaml",
+			failOn:   tt.failOn,
+		}
+		if err := o.run(ioutil.Discard); (err != nil) != tt.wantErr {
+			t.Errorf("--fail-on %s: expected error %v, got %v", tt.failOn, tt.wantErr, err)
+		}
+	}
+}
+
 func TestRepoUpdateFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "repo update", false)
 }
@@ -26,6 +26,8 @@ import (
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/messages"
+	"helm.sh/helm/v3/pkg/diff"
 )
 
 const rollbackDesc = `
@@ -36,10 +38,25 @@ second is a revision (version) number. If this argument is omitted, it will
 roll back to the previous release.
 
 To see revision numbers, run 'helm history RELEASE'.
+
+With '--force', the resources that cannot be replaced, such as for changes of
+immutable fields, are deleted and created again once their deletion completed,
+waiting up to '--deletion-timeout'.
+
+The '--wait', '--wait-for-jobs' and '--timeout' flags that are not set default
+to those recorded with the release by '--record-defaults', if any.
+
+With '--diff', the changes of the resources are printed before the rollback, so
+that '--dry-run --diff' previews it. See 'helm upgrade --help' for the
+renderers '--diff-renderer' accepts:
+
+    $ helm rollback --dry-run --diff --diff-renderer side-by-side myrelease 2
 `
 
 func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewRollback(cfg)
+	var showDiff bool
+	var diffRenderer string
 
 	cmd := &cobra.Command{
 		Use:   "rollback <RELEASE> [REVISION]",
@@ -66,11 +83,30 @@ func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				client.Version = ver
 			}
 
+			if err := applyProfileDefaults(cmd.Flags(), &client.Wait, &client.WaitForJobs, &client.Timeout, nil); err != nil {
+				return err
+			}
+			applyReleaseDefaults(cfg, cmd.Flags(), args[0], &client.Wait, &client.WaitForJobs, &client.Timeout, nil)
+
+			if showDiff {
+				renderer, err := diff.NewRenderer(diffRenderer)
+				if err != nil {
+					return err
+				}
+				diffs, err := client.Diff(args[0])
+				if err != nil {
+					return err
+				}
+				if err := renderer.Render(out, diffs); err != nil {
+					return err
+				}
+			}
+
 			if err := client.Run(args[0]); err != nil {
 				return err
 			}
 
-			fmt.Fprintf(out, "Rollback was a success! Happy Helming!\n")
+			info(out, messages.ReleaseRolledBack)
 			return nil
 		},
 	}
@@ -79,12 +115,16 @@ func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.DryRun, "dry-run", false, "simulate a rollback")
 	f.BoolVar(&client.Recreate, "recreate-pods", false, "performs pods restart for the resource if applicable")
 	f.BoolVar(&client.Force, "force", false, "force resource update through delete/recreate if needed")
+	f.DurationVar(&client.DeletionTimeout, "deletion-timeout", 2*time.Minute, "with --force, time to wait for the deletion of the resources that cannot be replaced before recreating them")
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "prevent hooks from running during rollback")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
 	f.BoolVar(&client.Wait, "wait", false, "if set, will wait until all Pods, PVCs, Services, and minimum number of Pods of a Deployment, StatefulSet, or ReplicaSet are in a ready state before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.CleanupOnFail, "cleanup-on-fail", false, "allow deletion of new resources created in this rollback when rollback fails")
 	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit")
+	f.BoolVar(&client.OverridePause, "override-pause", false, "roll back the release even if it is paused. The release stays paused")
+	f.BoolVar(&showDiff, "diff", false, "print the changes of the resources of the release before rolling it back")
+	bindDiffRendererFlag(cmd, &diffRenderer)
 
 	return cmd
 }
@@ -59,6 +59,16 @@ func TestRollbackCmd(t *testing.T) {
 		cmd:    "rollback funny-honey 1 --wait --wait-for-jobs",
 		golden: "output/rollback-wait-for-jobs.txt",
 		rels:   rels,
+	}, {
+		name:   "rollback a release quietly",
+		cmd:    "rollback funny-honey 1 --quiet",
+		golden: "output/rollback-quiet.txt",
+		rels:   rels,
+	}, {
+		name:      "rollback a release quietly and verbosely",
+		cmd:       "rollback funny-honey 1 --quiet --verbose",
+		rels:      rels,
+		wantError: true,
 	}, {
 		name:   "rollback a release without revision",
 		cmd:    "rollback funny-honey",
@@ -74,6 +84,49 @@ func TestRollbackCmd(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestRollbackDiff(t *testing.T) {
+	manifest := func(value string) string {
+		return "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\ndata:\n  key: " + value + "\n"
+	}
+	rels := []*release.Release{
+		{
+			Name:      "funny-honey",
+			Namespace: "default",
+			Info:      &release.Info{Status: release.StatusSuperseded},
+			Chart:     &chart.Chart{},
+			Version:   1,
+			Manifest:  manifest("old"),
+		},
+		{
+			Name:      "funny-honey",
+			Namespace: "default",
+			Info:      &release.Info{Status: release.StatusDeployed},
+			Chart:     &chart.Chart{},
+			Version:   2,
+			Manifest:  manifest("new"),
+		},
+	}
+
+	tests := []cmdTestCase{{
+		name:   "rollback a release with diff",
+		cmd:    "rollback funny-honey 1 --dry-run --diff",
+		golden: "output/rollback-diff.txt",
+		rels:   rels,
+	}, {
+		name:   "rollback a release with json-patch diff",
+		cmd:    "rollback funny-honey 1 --dry-run --diff --diff-renderer json-patch",
+		golden: "output/rollback-diff-json-patch.txt",
+		rels:   rels,
+	}, {
+		name:      "rollback a release with an unknown diff renderer",
+		cmd:       "rollback funny-honey 1 --dry-run --diff --diff-renderer nope",
+		golden:    "output/rollback-diff-unknown.txt",
+		rels:      rels,
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestRollbackRevisionCompletion(t *testing.T) {
 	mk := func(name string, vers int, status release.Status) *release.Release {
 		return release.Mock(&release.MockReleaseOptions{
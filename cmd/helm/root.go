@@ -24,6 +24,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,6 +32,9 @@ import (
 
 	"helm.sh/helm/v3/internal/experimental/registry"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/auth"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/cli/messages"
 	"helm.sh/helm/v3/pkg/repo"
 )
 
@@ -53,13 +57,22 @@ Environment variables:
 | $HELM_DEBUG                        | indicate whether or not Helm is running in Debug mode                             |
 | $HELM_DRIVER                       | set the backend storage driver. Values are: configmap, secret, memory, postgres   |
 | $HELM_DRIVER_SQL_CONNECTION_STRING | set the connection string the SQL storage driver should use.                      |
+| $HELM_FEATURES                     | set the features of the client enabled for templates, comma-separated.            |
+| $HELM_LANG                         | set the language of messages instead of $LC_ALL, $LC_MESSAGES or $LANG.           |
 | $HELM_MAX_HISTORY                  | set the maximum number of helm release history.                                   |
+| $HELM_MESSAGE_FORMAT               | set the format of messages that are not the output of commands: text or json.     |
+| $HELM_NAME_GENERATOR               | set the generator of release names: timestamp, sequence, random or external:CMD.  |
+| $HELM_NAME_PATTERN                 | set a regular expression the names of new releases must match.                   |
 | $HELM_NAMESPACE                    | set the namespace used for the helm operations.                                   |
 | $HELM_NO_PLUGINS                   | disable plugins. Set HELM_NO_PLUGINS=1 to disable plugins.                        |
+| $HELM_NOTIFIERS_CONFIG             | set the path to the file of the webhooks notified of the events of releases.      |
+| $HELM_OFFLINE                      | fail instead of reaching chart repositories or registries if set to true.         |
 | $HELM_PLUGINS                      | set the path to the plugins directory                                             |
+| $HELM_PROFILE                      | set the name of the configuration profile to use.                                 |
 | $HELM_REGISTRY_CONFIG              | set the path to the registry config file.                                         |
 | $HELM_REPOSITORY_CACHE             | set the path to the repository cache directory                                    |
 | $HELM_REPOSITORY_CONFIG            | set the path to the repositories file.                                            |
+| $HELM_STORAGE_DELTA_VALUES         | store large values as changes from the previous revision if set to true.         |
 | $KUBECONFIG                        | set an alternative Kubernetes configuration file (default "~/.kube/config")       |
 | $HELM_KUBEAPISERVER                | set the Kubernetes API Server Endpoint for authentication                         |
 | $HELM_KUBECAFILE                   | set the Kubernetes certificate authority file.                                    |
@@ -81,6 +94,38 @@ By default, the default directories depend on the Operating System. The defaults
 | Linux            | $HOME/.cache/helm         | $HOME/.config/helm             | $HOME/.local/share/helm |
 | macOS            | $HOME/Library/Caches/helm | $HOME/Library/Preferences/helm | $HOME/Library/helm      |
 | Windows          | %TEMP%\helm               | %APPDATA%\helm                 | %APPDATA%\helm          |
+
+The events of releases, such as failed installs, rollbacks or missing resources
+found by 'helm get resources', are posted as signed JSON to the webhooks listed
+in $HELM_NOTIFIERS_CONFIG, such as $HOME/.config/helm/notifiers.yaml on Linux:
+
+    webhooks:
+    - url: https://hooks.slack.com/services/T000/B000/XXXX
+      format: slack
+      releases: ["payments/*"]
+      events: ["install.failed", "upgrade.failed"]
+
+With '--offline' or $HELM_OFFLINE, Helm never reaches chart repositories, OCI
+registries, the hub or plugin sources, and fails with a clear error instead.
+Only the Kubernetes API is contacted, and commands such as 'helm template',
+'helm lint' and 'helm package' work without any network access.
+
+Configuration profiles bundle defaults for a cluster or a namespace. The profile
+named with '--profile' or $HELM_PROFILE is read from the 'profiles' directory of
+the configuration path, such as $HOME/.config/helm/profiles/prod.yaml on Linux:
+
+    kubeContext: prod
+    namespace: payments
+    wait: true
+    timeout: 10m
+    postRenderer: /usr/local/bin/kustomize-prod
+    redact:
+      - database.password
+
+The kube context and the namespace are used unless they are given by flags or
+environment variables. The release commands use the wait, timeout and
+post-renderer defaults unless their flags are set, and the values at the
+redacted paths are hidden by 'helm get values' and 'helm get all'.
 `
 
 func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string) (*cobra.Command, error) {
@@ -153,13 +198,49 @@ func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string
 	flags.ParseErrorsWhitelist.UnknownFlags = true
 	flags.Parse(args)
 
+	// The profile sets defaults of the settings, so it is applied before they
+	// are used
+	profile = nil
+	if settings.Profile != "" {
+		p, err := cli.LoadProfile(settings.Profile)
+		if err != nil {
+			return nil, err
+		}
+		settings.ApplyProfile(p)
+		profile = p
+	}
+
+	if err := setupMessages(out); err != nil {
+		return nil, err
+	}
+	if profile != nil {
+		verbose(messages.UsingProfile, profile.Name)
+	}
+
+	// The registry client is also used to pull charts from registries, so it
+	// is created before the commands
+	registryOpts := []registry.ClientOption{
+		registry.ClientOptDebug(settings.Debug),
+		registry.ClientOptWriter(out),
+		registry.ClientOptCredentialsFile(settings.RegistryConfig),
+		registry.ClientOptOffline(settings.Offline),
+	}
+	if authProvider, err := auth.NewPluginProvider(settings); err == nil && authProvider != nil {
+		registryOpts = append(registryOpts, registry.ClientOptCredentialsProvider(authProvider))
+	}
+	registryClient, err := registry.NewClient(registryOpts...)
+	if err != nil {
+		return nil, err
+	}
+	actionConfig.RegistryClient = registryClient
+
 	// Add subcommands
 	cmd.AddCommand(
 		// chart commands
 		newCreateCmd(out),
-		newDependencyCmd(out),
-		newPullCmd(out),
-		newShowCmd(out),
+		newDependencyCmd(actionConfig, out),
+		newPullCmd(actionConfig, out),
+		newShowCmd(actionConfig, out),
 		newLintCmd(out),
 		newPackageCmd(out),
 		newRepoCmd(out),
@@ -167,39 +248,40 @@ func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string
 		newVerifyCmd(out),
 
 		// release commands
+		newCapabilitiesCmd(actionConfig, out),
 		newGetCmd(actionConfig, out),
 		newHistoryCmd(actionConfig, out),
+		newImagesCmd(actionConfig, out),
 		newInstallCmd(actionConfig, out),
+		newInstallSetCmd(actionConfig, out),
 		newListCmd(actionConfig, out),
+		newReleaseCmd(actionConfig, out),
 		newReleaseTestCmd(actionConfig, out),
 		newRollbackCmd(actionConfig, out),
+		newServeAPICmd(out),
 		newStatusCmd(actionConfig, out),
+		newStorageCmd(actionConfig, out),
 		newTemplateCmd(actionConfig, out),
 		newUninstallCmd(actionConfig, out),
 		newUpgradeCmd(actionConfig, out),
 
 		newCompletionCmd(out),
-		newEnvCmd(out),
+		newEnvCmd(actionConfig, out),
 		newPluginCmd(out),
 		newVersionCmd(out),
 
 		// Hidden documentation generator command: 'helm docs'
 		newDocsCmd(out),
+
+		// Hidden performance harness: 'helm bench'
+		newBenchCmd(actionConfig, out),
 	)
 
 	// Add *experimental* subcommands
-	registryClient, err := registry.NewClient(
-		registry.ClientOptDebug(settings.Debug),
-		registry.ClientOptWriter(out),
-		registry.ClientOptCredentialsFile(settings.RegistryConfig),
-	)
-	if err != nil {
-		return nil, err
-	}
-	actionConfig.RegistryClient = registryClient
 	cmd.AddCommand(
 		newRegistryCmd(actionConfig, out),
 		newChartCmd(actionConfig, out),
+		newPushCmd(actionConfig, out),
 	)
 
 	// Find and add plugins
@@ -214,6 +296,26 @@ func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string
 	return cmd, nil
 }
 
+// setupMessages sets up the printer of messages from the settings.
+func setupMessages(out io.Writer) error {
+	if settings.Quiet && settings.Verbose {
+		return errors.New("--quiet and --verbose cannot be used together")
+	}
+	format, err := messages.ParseFormat(settings.MessageFormat)
+	if err != nil {
+		return err
+	}
+	msgs = messages.NewPrinter(out, os.Stderr, messages.Lookup(messages.Language()))
+	msgs.Format = format
+	switch {
+	case settings.Quiet:
+		msgs.Verbosity = messages.Quiet
+	case settings.Verbose:
+		msgs.Verbosity = messages.Verbose
+	}
+	return nil
+}
+
 func checkForExpiredRepos(repofile string) {
 
 	expiredRepos := []struct {
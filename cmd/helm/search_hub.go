@@ -27,6 +27,7 @@ import (
 
 	"helm.sh/helm/v3/internal/monocular"
 	"helm.sh/helm/v3/pkg/cli/output"
+	"helm.sh/helm/v3/pkg/getter"
 )
 
 const searchHubDesc = `
@@ -76,6 +77,9 @@ func newSearchHubCmd(out io.Writer) *cobra.Command {
 }
 
 func (o *searchHubOptions) run(out io.Writer, args []string) error {
+	if settings.Offline {
+		return errors.Wrapf(getter.ErrOffline, "unable to search %q", o.searchEndpoint)
+	}
 	c, err := monocular.New(o.searchEndpoint)
 	if err != nil {
 		return errors.Wrap(err, fmt.Sprintf("unable to create connection to %q", o.searchEndpoint))
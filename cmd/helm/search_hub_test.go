@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -51,6 +52,21 @@ func TestSearchHubCmd(t *testing.T) {
 	}
 }
 
+func TestSearchHubCmdOffline(t *testing.T) {
+	defer resetEnv()()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected no request to the search service in offline mode")
+	}))
+	defer ts.Close()
+
+	testcmd := "search hub --offline --endpoint " + ts.URL + " maria"
+	_, _, err := executeActionCommandC(storageFixture(), testcmd)
+	if err == nil || !strings.Contains(err.Error(), "offline mode") {
+		t.Errorf("expected an offline mode error, got %v", err)
+	}
+}
+
 func TestSearchHubOutputCompletion(t *testing.T) {
 	outputFlagCompletionTest(t, "search hub")
 }
@@ -0,0 +1,169 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/server"
+)
+
+var serveAPIHelp = `
+This command serves the operations of Helm on releases as an HTTP API, so that
+platforms can drive Helm remotely instead of running the CLI.
+
+The API takes and returns JSON:
+
+    GET    /v1/namespaces/NAMESPACE/releases                  list the releases
+    POST   /v1/namespaces/NAMESPACE/releases                  install a release
+    GET    /v1/namespaces/NAMESPACE/releases/NAME             get the status of a release
+    PUT    /v1/namespaces/NAMESPACE/releases/NAME             upgrade a release
+    POST   /v1/namespaces/NAMESPACE/releases/NAME/rollback    roll back a release
+
+Callers authenticate with a bearer token from the file given with
+'--token-file'. Each token names the Kubernetes user, and optionally the
+groups, that the operations it is used for impersonate:
+
+    tokens:
+      - token: 3f2b8c...
+        user: ci
+        groups: [platform]
+
+Callers thus only have the permissions RBAC grants to their user, while the
+identity of the server needs the permission to impersonate them.
+
+Charts are referenced as REPO/CHART, from the repositories configured for the
+server, or as oci:// references. Local charts may only be referenced by their
+path relative to the directory given with '--chart-root'. Other local paths
+and URLs are refused.
+
+Serve over TLS with '--tls-cert-file' and '--tls-key-file' unless the server
+is only reached through a trusted proxy, as tokens are sent in clear otherwise:
+
+    $ helm serve-api --token-file tokens.yaml --address :8443 \
+        --tls-cert-file tls.crt --tls-key-file tls.key
+`
+
+// serveAPIShutdownTimeout is how long the requests in flight are given to
+// complete when the server is stopped.
+const serveAPIShutdownTimeout = 30 * time.Second
+
+// Timeouts of the connections of the callers. No write timeout is set, as
+// operations wait for the resources of releases up to their own timeout.
+const (
+	serveAPIReadHeaderTimeout = 10 * time.Second
+	serveAPIReadTimeout       = time.Minute
+	serveAPIIdleTimeout       = 2 * time.Minute
+)
+
+type serveAPIOptions struct {
+	address     string
+	tokenFile   string
+	tlsCertFile string
+	tlsKeyFile  string
+	chartRoot   string
+}
+
+func newServeAPICmd(out io.Writer) *cobra.Command {
+	o := &serveAPIOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "serve-api",
+		Short: "serve the operations on releases as an HTTP API",
+		Long:  serveAPIHelp,
+		Args:  require.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(out)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&o.address, "address", "127.0.0.1:8080", "address to listen on")
+	f.StringVar(&o.tokenFile, "token-file", "", "file listing the bearer tokens of the callers and the users they impersonate")
+	f.StringVar(&o.tlsCertFile, "tls-cert-file", "", "certificate file to serve over TLS")
+	f.StringVar(&o.tlsKeyFile, "tls-key-file", "", "private key file to serve over TLS")
+	f.StringVar(&o.chartRoot, "chart-root", "", "directory of the local charts that requests may reference by their relative path")
+
+	return cmd
+}
+
+func (o *serveAPIOptions) run(out io.Writer) error {
+	if o.tokenFile == "" {
+		return errors.New("--token-file is required")
+	}
+	if (o.tlsCertFile == "") != (o.tlsKeyFile == "") {
+		return errors.New("--tls-cert-file and --tls-key-file must be given together")
+	}
+	tokens, err := server.LoadTokens(o.tokenFile)
+	if err != nil {
+		return err
+	}
+
+	s := &server.Server{
+		Settings:  settings,
+		Tokens:    tokens,
+		ChartRoot: o.chartRoot,
+		NewConfiguration: func(getter genericclioptions.RESTClientGetter, namespace string) (*action.Configuration, error) {
+			cfg := new(action.Configuration)
+			return cfg, initActionConfig(cfg, getter, namespace)
+		},
+		Log: debug,
+	}
+	srv := &http.Server{
+		Addr:              o.address,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: serveAPIReadHeaderTimeout,
+		ReadTimeout:       serveAPIReadTimeout,
+		IdleTimeout:       serveAPIIdleTimeout,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		if o.tlsCertFile != "" {
+			errs <- srv.ListenAndServeTLS(o.tlsCertFile, o.tlsKeyFile)
+		} else {
+			errs <- srv.ListenAndServe()
+		}
+	}()
+	fmt.Fprintf(out, "Serving the Helm API on %s\n", o.address)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+	select {
+	case err := <-errs:
+		return err
+	case <-stop:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), serveAPIShutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(ctx)
+}
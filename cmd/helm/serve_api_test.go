@@ -0,0 +1,45 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+)
+
+func TestServeAPICmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:      "serve without tokens",
+		cmd:       "serve-api",
+		golden:    "output/serve-api-no-tokens.txt",
+		wantError: true,
+	}, {
+		name:      "serve with a certificate without its key",
+		cmd:       "serve-api --token-file testdata/api-tokens.yaml --tls-cert-file tls.crt",
+		golden:    "output/serve-api-tls-key-missing.txt",
+		wantError: true,
+	}, {
+		name:      "serve with invalid tokens",
+		cmd:       "serve-api --token-file testdata/api-tokens-invalid.yaml",
+		golden:    "output/serve-api-invalid-tokens.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestServeAPIFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "serve-api", false)
+}
@@ -39,6 +39,13 @@ This command inspects a chart (directory, file, or URL) and displays all its con
 const showValuesDesc = `
 This command inspects a chart (directory, file, or URL) and displays the contents
 of the values.yaml file
+
+With '--docs', it displays the documentation of the values instead, as a markdown
+table or as JSON. Each value is documented with its type, its default and the
+comments directly above it in values.yaml. Values without comments are described
+by the descriptions of values.schema.json.
+
+    $ helm show values --docs ./mychart > VALUES.md
 `
 
 const showChartDesc = `
@@ -51,8 +58,11 @@ This command inspects a chart (directory, file, or URL) and displays the content
 of the README file
 `
 
-func newShowCmd(out io.Writer) *cobra.Command {
+func newShowCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewShow(action.ShowAll)
+	client.RegistryClient = ociRegistryClient(cfg)
+	var valuesDocs bool
+	var valuesDocsFormat string
 
 	showCommand := &cobra.Command{
 		Use:               "show",
@@ -96,6 +106,9 @@ func newShowCmd(out io.Writer) *cobra.Command {
 		ValidArgsFunction: validArgsFunc,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client.OutputFormat = action.ShowValues
+			if valuesDocs {
+				client.ValuesDocs = valuesDocsFormat
+			}
 			output, err := runShow(args, client)
 			if err != nil {
 				return err
@@ -145,6 +158,10 @@ func newShowCmd(out io.Writer) *cobra.Command {
 		showCommand.AddCommand(subCmd)
 	}
 
+	f := valuesSubCmd.Flags()
+	f.BoolVar(&valuesDocs, "docs", false, "show the documentation of the values instead of values.yaml")
+	f.StringVar(&valuesDocsFormat, "docs-format", "markdown", "format of the values documentation: markdown or json")
+
 	return showCommand
 }
 
@@ -175,6 +192,9 @@ func runShow(args []string, client *action.Show) (string, error) {
 		debug("setting version to >0.0.0-0")
 		client.Version = ">0.0.0-0"
 	}
+	if err := checkOCIChartRef(args[0]); err != nil {
+		return "", err
+	}
 
 	cp, err := client.ChartPathOptions.LocateChart(args[0], settings)
 	if err != nil {
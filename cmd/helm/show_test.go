@@ -145,3 +145,16 @@ func TestShowReadmeFileCompletion(t *testing.T) {
 func TestShowValuesFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "show values", true)
 }
+
+func TestShowValuesDocs(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "show values documentation",
+		cmd:    "show values --docs testdata/testcharts/chart-with-schema",
+		golden: "output/show-values-docs.txt",
+	}, {
+		name:      "show values documentation in an unknown format",
+		cmd:       "show values --docs --docs-format yaml testdata/testcharts/chart-with-schema",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
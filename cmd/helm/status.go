@@ -23,6 +23,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v3/cmd/helm/require"
@@ -43,7 +44,8 @@ The status consists of:
 - description of the release (can be completion message or error message, need to enable --show-desc)
 - list of resources that this release consists of, sorted by kind
 - details on last test suite run, if applicable
-- additional notes provided by the chart
+- details on the last run of each hook and what became of its resource, need to enable --show-hooks
+- additional notes provided by the chart (structured notes from the chart's NOTES.yaml are included in the JSON and YAML output)
 `
 
 func newStatusCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -70,7 +72,7 @@ func newStatusCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			// strip chart metadata from the output
 			rel.Chart = nil
 
-			return outfmt.Write(out, &statusPrinter{rel, false, client.ShowDescription})
+			return outfmt.Write(out, &statusPrinter{rel, false, client.ShowDescription, client.ShowHooks})
 		},
 	}
 
@@ -91,6 +93,7 @@ func newStatusCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	bindOutputFlag(cmd, &outfmt)
 	f.BoolVar(&client.ShowDescription, "show-desc", false, "if set, display the description message of the named release")
+	f.BoolVar(&client.ShowHooks, "show-hooks", false, "if set, display the last run of each hook and the state of its resource")
 
 	return cmd
 }
@@ -99,6 +102,7 @@ type statusPrinter struct {
 	release         *release.Release
 	debug           bool
 	showDescription bool
+	showHooks       bool
 }
 
 func (s statusPrinter) WriteJSON(out io.Writer) error {
@@ -120,6 +124,9 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 	fmt.Fprintf(out, "NAMESPACE: %s\n", s.release.Namespace)
 	fmt.Fprintf(out, "STATUS: %s\n", s.release.Info.Status.String())
 	fmt.Fprintf(out, "REVISION: %d\n", s.release.Version)
+	if s.release.IsPaused() {
+		fmt.Fprintf(out, "PAUSED: %s\n", pauseString(s.release.Info.Pause))
+	}
 	if s.showDescription {
 		fmt.Fprintf(out, "DESCRIPTION: %s\n", s.release.Info.Description)
 	}
@@ -142,9 +149,42 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 		}
 	}
 
+	if s.showHooks {
+		if err := writeHookExecutions(out, s.release.Hooks); err != nil {
+			return err
+		}
+	}
+
+	if len(s.release.Info.Warnings) > 0 {
+		fmt.Fprintln(out, "API WARNINGS:")
+		for _, w := range s.release.Info.Warnings {
+			fmt.Fprintf(out, "  %s %q: %s\n", w.Kind, w.Name, w.Message)
+		}
+	}
+
+	if len(s.release.Info.NotReady) > 0 {
+		fmt.Fprintln(out, "NOT READY:")
+		for _, r := range s.release.Info.NotReady {
+			fmt.Fprintf(out, "  %s %q: %s\n", r.Kind, r.Name, r.Status)
+			for _, e := range r.Events {
+				fmt.Fprintf(out, "    %s\n", e)
+			}
+			for _, p := range r.Pods {
+				fmt.Fprintf(out, "    Pod %q: %s\n", p.Name, p.Status)
+				for _, e := range p.Events {
+					fmt.Fprintf(out, "      %s\n", e)
+				}
+			}
+		}
+	}
+
 	if s.debug {
 		fmt.Fprintln(out, "USER-SUPPLIED VALUES:")
-		err := output.EncodeYAML(out, s.release.Config)
+		vals, err := redactValues(s.release.Config)
+		if err != nil {
+			return err
+		}
+		err = output.EncodeYAML(out, vals)
 		if err != nil {
 			return err
 		}
@@ -156,8 +196,13 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 			return err
 		}
 
+		computed, err := redactValues(cfg.AsMap())
+		if err != nil {
+			return err
+		}
+
 		fmt.Fprintln(out, "COMPUTED VALUES:")
-		err = output.EncodeYAML(out, cfg.AsMap())
+		err = output.EncodeYAML(out, computed)
 		if err != nil {
 			return err
 		}
@@ -165,6 +210,12 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 		fmt.Fprintln(out)
 	}
 
+	if strings.EqualFold(s.release.Info.Description, "Dry run complete") {
+		if err := writeHookPlan(out, action.HookPlan(s.release)); err != nil {
+			return err
+		}
+	}
+
 	if strings.EqualFold(s.release.Info.Description, "Dry run complete") || s.debug {
 		fmt.Fprintln(out, "HOOKS:")
 		for _, h := range s.release.Hooks {
@@ -176,6 +227,9 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 	if len(s.release.Info.Notes) > 0 {
 		fmt.Fprintf(out, "NOTES:\n%s\n", strings.TrimSpace(s.release.Info.Notes))
 	}
+	if len(s.release.Info.NotesDiff) > 0 {
+		fmt.Fprintf(out, "NOTES CHANGES:\n%s", s.release.Info.NotesDiff)
+	}
 	return nil
 }
 
@@ -192,3 +246,64 @@ func executionsByHookEvent(rel *release.Release) map[release.HookEvent][]*releas
 	}
 	return result
 }
+
+// writeHookExecutions prints a table of the last run of each hook
+func writeHookExecutions(out io.Writer, hooks []*release.Hook) error {
+	if len(hooks) == 0 {
+		fmt.Fprintln(out, "HOOKS: None")
+		return nil
+	}
+	fmt.Fprintln(out, "HOOKS:")
+	table := uitable.New()
+	table.AddRow("NAME", "KIND", "EVENTS", "WEIGHT", "PHASE", "RESOURCE", "COMPLETED")
+	for _, h := range hooks {
+		events := make([]string, 0, len(h.Events))
+		for _, e := range h.Events {
+			events = append(events, e.String())
+		}
+		phase, resource, completed := "Not run", "", ""
+		if !h.LastRun.StartedAt.IsZero() {
+			phase = h.LastRun.Phase.String()
+			resource = h.LastRun.ResourceState.String()
+			if h.LastRun.DeletedBy != "" {
+				resource = fmt.Sprintf("%s (%s)", resource, h.LastRun.DeletedBy)
+			}
+		}
+		if !h.LastRun.CompletedAt.IsZero() {
+			completed = h.LastRun.CompletedAt.Format(time.ANSIC)
+		}
+		table.AddRow(h.Name, h.Kind, strings.Join(events, ","), h.Weight, phase, resource, completed)
+	}
+	return output.EncodeTable(out, table)
+}
+
+// writeHookPlan writes the hooks a pending operation would run, in order.
+func writeHookPlan(out io.Writer, plan []action.PlannedHook) error {
+	if len(plan) == 0 {
+		fmt.Fprintln(out, "HOOK EXECUTION PLAN: None")
+		return nil
+	}
+	fmt.Fprintln(out, "HOOK EXECUTION PLAN:")
+	table := uitable.New()
+	table.AddRow("ORDER", "EVENT", "WEIGHT", "KIND", "NAME", "DELETE POLICIES", "TIMEOUT")
+	for i, h := range plan {
+		policies := make([]string, 0, len(h.DeletePolicies))
+		for _, p := range h.DeletePolicies {
+			policies = append(policies, p.String())
+		}
+		timeout := ""
+		if h.TimeoutSeconds > 0 {
+			timeout = (time.Duration(h.TimeoutSeconds) * time.Second).String()
+		}
+		table.AddRow(i+1, h.Event, h.Weight, h.Kind, h.Name, strings.Join(policies, ","), timeout)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func pauseString(p *release.Pause) string {
+	s := fmt.Sprintf("since %s", p.PausedAt.Format(time.ANSIC))
+	if p.Reason != "" {
+		s += " (" + p.Reason + ")"
+	}
+	return s
+}
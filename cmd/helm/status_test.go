@@ -60,6 +60,40 @@ func TestStatusCmd(t *testing.T) {
 			Status: release.StatusDeployed,
 			Notes:  "release notes",
 		}),
+	}, {
+		name:   "get status of a deployed release with API warnings",
+		cmd:    "status flummoxed-chickadee",
+		golden: "output/status-with-warnings.txt",
+		rels: releasesMockWithStatus(&release.Info{
+			Status: release.StatusDeployed,
+			Warnings: []release.ResourceWarning{{
+				Kind:      "PodDisruptionBudget",
+				Name:      "flummoxed-chickadee",
+				Namespace: "default",
+				Message:   "policy/v1beta1 PodDisruptionBudget is deprecated in v1.21+, unavailable in v1.25+; use policy/v1 PodDisruptionBudget",
+			}},
+		}),
+	}, {
+		name:   "get status of a release that failed waiting for its resources",
+		cmd:    "status flummoxed-chickadee",
+		golden: "output/status-with-not-ready.txt",
+		rels: releasesMockWithStatus(&release.Info{
+			Status: release.StatusFailed,
+			NotReady: []release.NotReadyResource{{
+				Kind:      "Deployment",
+				Name:      "web",
+				Namespace: "default",
+				Status:    "ready replicas: 0/1, updated replicas: 1",
+				Events:    []string{"Normal ScalingReplicaSet: Scaled up replica set web-5d4f8 to 1"},
+				Pods: []release.NotReadyResource{{
+					Kind:      "Pod",
+					Name:      "web-5d4f8-x2v9k",
+					Namespace: "default",
+					Status:    "phase: Pending, container web waiting: ImagePullBackOff",
+					Events:    []string{`Warning Failed: Failed to pull image "web:nope"`},
+				}},
+			}},
+		}),
 	}, {
 		name:   "get status of a deployed release with notes in json",
 		cmd:    "status flummoxed-chickadee -o json",
@@ -108,6 +142,44 @@ func TestStatusCmd(t *testing.T) {
 				},
 			},
 		),
+	}, {
+		name:   "get status of a deployed release with hooks",
+		cmd:    "status --show-hooks flummoxed-chickadee",
+		golden: "output/status-with-hooks.txt",
+		rels: releasesMockWithStatus(
+			&release.Info{
+				Status: release.StatusDeployed,
+			},
+			&release.Hook{
+				Name:   "db-migrate",
+				Kind:   "Job",
+				Weight: -5,
+				Events: []release.HookEvent{release.HookPreInstall, release.HookPreUpgrade},
+				LastRun: release.HookExecution{
+					StartedAt:     mustParseTime("2006-01-02T15:00:05Z"),
+					CompletedAt:   mustParseTime("2006-01-02T15:00:07Z"),
+					Phase:         release.HookPhaseSucceeded,
+					ResourceState: release.HookResourceDeleted,
+					DeletedBy:     release.HookSucceeded,
+				},
+			},
+			&release.Hook{
+				Name:   "notify",
+				Kind:   "Pod",
+				Events: []release.HookEvent{release.HookPostInstall},
+				LastRun: release.HookExecution{
+					StartedAt:     mustParseTime("2006-01-02T15:04:05Z"),
+					CompletedAt:   mustParseTime("2006-01-02T15:04:07Z"),
+					Phase:         release.HookPhaseFailed,
+					ResourceState: release.HookResourceKept,
+				},
+			},
+			&release.Hook{
+				Name:   "never-run-test",
+				Kind:   "Pod",
+				Events: []release.HookEvent{release.HookTest},
+			},
+		),
 	}}
 	runTestCmd(t, tests)
 }
@@ -0,0 +1,158 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/messages"
+	"helm.sh/helm/v3/pkg/cli/output"
+)
+
+var storageGCHelp = `
+This command deletes the release records that are no longer needed:
+
+- orphaned: all the records of the releases whose latest revision is deployed
+  or failed, but none of whose resources exist anymore, such as after the
+  namespace of the release was deleted.
+- superseded: the superseded revisions beyond the number of revisions kept per
+  release, set with '--max-history'.
+- stale-pending: the latest revision of the releases left pending-install,
+  pending-upgrade or pending-rollback for longer than '--pending-ttl', as left
+  behind by operations that crashed. Their resources are left alone.
+
+Only the records are deleted, never the resources of the releases. Use
+'--dry-run' to list the records without deleting them, or '--interactive' to
+confirm each deletion:
+
+    $ helm storage gc --dry-run
+    $ helm storage gc --max-history 10 --pending-ttl 2h --interactive
+`
+
+func newStorageGCCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	var outfmt output.Format
+	var dryRun, interactive bool
+	client := action.NewStorageGC(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "delete orphaned, superseded and stale release records",
+		Long:  storageGCHelp,
+		Args:  require.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interactive && (dryRun || outfmt != output.Table) {
+				return errors.New("--interactive cannot be used with --dry-run or --output")
+			}
+			garbage, err := client.Find()
+			if err != nil {
+				return err
+			}
+			if err := outfmt.Write(out, &garbageWriter{garbage}); err != nil {
+				return err
+			}
+			if dryRun || len(garbage) == 0 {
+				return nil
+			}
+			if interactive {
+				if garbage, err = confirmGarbage(cmd.InOrStdin(), out, garbage); err != nil {
+					return err
+				}
+			}
+			if err := client.Collect(garbage); err != nil {
+				return err
+			}
+			if outfmt == output.Table {
+				info(out, messages.RecordsDeleted, len(garbage))
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&dryRun, "dry-run", false, "list the records that would be deleted without deleting them")
+	f.BoolVarP(&interactive, "interactive", "i", false, "ask for confirmation before deleting each record")
+	f.BoolVar(&client.Orphaned, "orphaned", client.Orphaned, "delete the records of the releases none of whose resources exist anymore")
+	f.IntVar(&client.MaxHistory, "max-history", 0, "number of revisions kept per release, beyond which the superseded revisions are deleted. 0 keeps them all")
+	f.DurationVar(&client.PendingTTL, "pending-ttl", client.PendingTTL, "how long a revision can be pending before it is deleted as left behind by a crashed operation. 0 keeps them")
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+// confirmGarbage asks whether to delete each record, and returns the ones
+// confirmed.
+func confirmGarbage(in io.Reader, out io.Writer, garbage []action.GarbageRecord) ([]action.GarbageRecord, error) {
+	r := bufio.NewReader(in)
+	var confirmed []action.GarbageRecord
+	for _, g := range garbage {
+		fmt.Fprintf(out, "Delete revision %d of %q (%s)? [y/N]: ", g.Revision, g.Name, g.Reason)
+		answer, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, errors.Wrap(err, "unable to read the confirmation")
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			confirmed = append(confirmed, g)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	return confirmed, nil
+}
+
+type garbageWriter struct {
+	garbage []action.GarbageRecord
+}
+
+func (w *garbageWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.records())
+}
+
+func (w *garbageWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.records())
+}
+
+func (w *garbageWriter) WriteTable(out io.Writer) error {
+	if len(w.garbage) == 0 {
+		_, err := fmt.Fprintln(out, "no release records to delete")
+		return err
+	}
+	table := uitable.New()
+	table.AddRow("NAME", "NAMESPACE", "REVISION", "STATUS", "REASON", "MESSAGE")
+	for _, g := range w.garbage {
+		table.AddRow(g.Name, g.Namespace, g.Revision, g.Status, g.Reason, g.Message)
+	}
+	return output.EncodeTable(out, table)
+}
+
+// records never encodes the records as null.
+func (w *garbageWriter) records() []action.GarbageRecord {
+	if w.garbage == nil {
+		return []action.GarbageRecord{}
+	}
+	return w.garbage
+}
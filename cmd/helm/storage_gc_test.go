@@ -0,0 +1,58 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestStorageGC(t *testing.T) {
+	stuck := release.Mock(&release.MockReleaseOptions{Name: "stuck", Status: release.StatusPendingInstall})
+	stuck.Info.LastDeployed = stuck.Info.LastDeployed.Add(-48 * time.Hour)
+	rels := []*release.Release{
+		release.Mock(&release.MockReleaseOptions{Name: "aeneas", Version: 1, Status: release.StatusSuperseded}),
+		release.Mock(&release.MockReleaseOptions{Name: "aeneas", Version: 2, Status: release.StatusSuperseded}),
+		release.Mock(&release.MockReleaseOptions{Name: "aeneas", Version: 3, Status: release.StatusDeployed}),
+		stuck,
+	}
+
+	tests := []cmdTestCase{{
+		name:   "list the garbage",
+		cmd:    "storage gc --dry-run --max-history 2",
+		golden: "output/storage-gc-dry-run.txt",
+		rels:   rels,
+	}, {
+		name:   "delete the garbage",
+		cmd:    "storage gc --max-history 2 --output json",
+		golden: "output/storage-gc.json",
+		rels:   rels,
+	}, {
+		name:   "nothing to delete",
+		cmd:    "storage gc --pending-ttl 0",
+		golden: "output/storage-gc-none.txt",
+		rels:   rels,
+	}, {
+		name:      "interactive dry run",
+		cmd:       "storage gc --dry-run --interactive",
+		rels:      rels,
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
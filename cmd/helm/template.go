@@ -27,12 +27,16 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/releaseutil"
 )
 
@@ -42,15 +46,68 @@ Render chart templates locally and display the output.
 Any values that would normally be looked up or retrieved in-cluster will be
 faked locally. Additionally, none of the server-side testing of chart validity
 (e.g. whether an API is supported) is done.
+
+With '--from-cluster', the API versions and the Kubernetes version given to the
+templates as '.Capabilities' are read from the cluster of the current context,
+instead of being faked, so that the output matches what an install would
+render. Only the discovery API of the cluster is queried; nothing is changed.
+Additional API versions can still be given with '--api-versions'.
+
+With '--capabilities-snapshot', they are read from a snapshot taken by 'helm
+capabilities export' instead, along with the CustomResourceDefinitions, so
+that the output matches what an install would render in the cluster the
+snapshot was taken of.
+
+With '--matrix', the chart is rendered with every combination of the values of
+its values matrix, the ci/values-matrix.yaml file of the chart, on top of the
+values given by flags. The output of each combination is preceded by a
+'# Values matrix' comment naming it, and the combinations that fail to render
+are reported together. The values matrix maps axes to named choices of values:
+
+    axes:
+      ingress:
+        enabled:
+          ingress:
+            enabled: true
+        disabled: {}
+      persistence:
+        emptyDir:
+          persistence:
+            enabled: false
+        volume:
+          persistence:
+            enabled: true
+    exclude:
+    - ingress: disabled
+      persistence: emptyDir
+
+To debug the values a subchart receives, pass the path of the subchart to
+'--debug-values', such as 'mysubchart' or 'mysubchart/charts/nested'. Instead
+of the rendered templates, the values scope, globals and exports of the
+subchart are printed after coalescing, each annotated with the source that
+supplied it: the values.yaml of a chart, a values file, or one of the --set
+flags. Use '.' for the chart itself.
+
+With '--output-dir', the rendered templates are written to files instead of
+stdout. By default there is one file per template of the chart. With
+'--output-layout resources', there is one file per resource instead, named
+after its kind and name, along with an index.yaml mapping each file to its
+resource and source template. '--output-layout kustomize' writes the same
+files, with hooks in a hooks directory, and a kustomization.yaml listing the
+other resources, so the output can be consumed by kustomize directly.
 `
 
 func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	var validate bool
 	var includeCrds bool
 	client := action.NewInstall(cfg)
+	client.RegistryClient = ociRegistryClient(cfg)
 	valueOpts := &values.Options{}
 	var extraAPIs []string
 	var showFiles []string
+	var debugValues string
+	var fromCluster bool
+	var matrix bool
 
 	cmd := &cobra.Command{
 		Use:   "template [NAME] [CHART]",
@@ -60,13 +117,29 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			return compInstall(args, toComplete, client)
 		},
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyProfileDefaults(cmd.Flags(), nil, nil, nil, &client.PostRenderer); err != nil {
+				return err
+			}
 			client.DryRun = true
 			client.ReleaseName = "RELEASE-NAME"
 			client.Replace = true // Skip the name check
 			client.ClientOnly = !validate
 			client.APIVersions = chartutil.VersionSet(extraAPIs)
+			client.CapabilitiesFromCluster = fromCluster
+			if fromCluster && client.CapabilitiesSnapshot != nil {
+				return errors.New("--from-cluster and --capabilities-snapshot cannot be used together")
+			}
 			client.IncludeCRDs = includeCrds
+			if debugValues != "" {
+				return runDebugValues(args, client, valueOpts, debugValues, out)
+			}
+			if matrix {
+				if client.OutputDir != "" || len(showFiles) > 0 {
+					return errors.New("--matrix cannot be used with --output-dir or --show-only")
+				}
+				return runTemplateMatrix(args, client, valueOpts, out)
+			}
 			rel, err := runInstall(args, client, valueOpts, out)
 
 			if err != nil && !settings.Debug {
@@ -76,6 +149,14 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				return err
 			}
 
+			// The resources were written to the output directory by the action
+			if client.OutputDir != "" && client.OutputLayout != action.OutputLayoutTemplates {
+				for _, f := range client.OutputFiles {
+					fmt.Fprintf(out, "wrote %s\n", f)
+				}
+				return err
+			}
+
 			// We ignore a potential error here because, when the --debug flag was specified,
 			// we always want to print the YAML, even if it is not valid. The error is still returned afterwards.
 			if rel != nil {
@@ -161,16 +242,141 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	addInstallFlags(cmd, f, client, valueOpts)
 	f.StringArrayVarP(&showFiles, "show-only", "s", []string{}, "only show manifests rendered from the given templates")
 	f.StringVar(&client.OutputDir, "output-dir", "", "writes the executed templates to files in output-dir instead of stdout")
+	f.StringVar(&client.OutputLayout, "output-layout", action.OutputLayoutTemplates, "layout of the files written to output-dir: templates (one file per template), resources (one file per resource) or kustomize (one file per resource and a kustomization.yaml)")
 	f.BoolVar(&validate, "validate", false, "validate your manifests against the Kubernetes cluster you are currently pointing at. This is the same validation performed on an install")
 	f.BoolVar(&includeCrds, "include-crds", false, "include CRDs in the templated output")
 	f.BoolVar(&client.IsUpgrade, "is-upgrade", false, "set .Release.IsUpgrade instead of .Release.IsInstall")
 	f.StringArrayVarP(&extraAPIs, "api-versions", "a", []string{}, "Kubernetes api versions used for Capabilities.APIVersions")
+	f.BoolVar(&fromCluster, "from-cluster", false, "take Capabilities.APIVersions and Capabilities.KubeVersion from the cluster you are currently pointing at. The cluster is only queried, nothing is changed")
 	f.BoolVar(&client.UseReleaseName, "release-name", false, "use release name in the output-dir path.")
+	f.BoolVar(&matrix, "matrix", false, "render the chart with every combination of the values of its values matrix, ci/values-matrix.yaml, on top of the given values, and report the combinations that fail")
+	f.StringVar(&debugValues, "debug-values", "", "print the values the subchart at the given path receives, annotated with their sources, instead of rendering the templates")
 	bindPostRenderFlag(cmd, &client.PostRenderer)
 
 	return cmd
 }
 
+// runDebugValues prints the values the subchart at the given path receives.
+func runDebugValues(args []string, client *action.Install, valueOpts *values.Options, path string, out io.Writer) error {
+	ch, vals, sources, err := loadChartWithValueSources(args, client, valueOpts)
+	if err != nil {
+		return err
+	}
+
+	debug, err := chartutil.DebugValues(ch, vals, sources, path)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(out, debug.String())
+	return nil
+}
+
+// runTemplateMatrix renders the chart with each combination of the values of
+// its values matrix, and fails if any of them fails to render.
+func runTemplateMatrix(args []string, client *action.Install, valueOpts *values.Options, out io.Writer) error {
+	if err := applyEnvFeatures(&client.Features); err != nil {
+		return err
+	}
+	cp, err := locateChart(args, client)
+	if err != nil {
+		return err
+	}
+	vals, err := valueOpts.MergeValues(getter.All(settings))
+	if err != nil {
+		return err
+	}
+	ch, err := loadInstallableChart(cp)
+	if err != nil {
+		return err
+	}
+	matrix, err := chartutil.LoadValuesMatrix(ch)
+	if err != nil {
+		return err
+	}
+	combinations, err := matrix.Combinations()
+	if err != nil {
+		return err
+	}
+
+	client.Namespace = settings.Namespace()
+	client.SecretResolver = newSecretResolver()
+	var failures []string
+	for i, c := range combinations {
+		// Rendering drops the disabled subcharts from the chart, so each
+		// combination renders a chart of its own
+		if i > 0 {
+			if ch, err = loadInstallableChart(cp); err != nil {
+				return err
+			}
+		}
+		cvals, err := c.Merge(vals)
+		if err != nil {
+			return err
+		}
+		rel, err := client.Run(ch, cvals)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", c.Name, err))
+			continue
+		}
+		fmt.Fprintf(out, "# Values matrix: %s\n%s\n", c.Name, strings.TrimSpace(rel.Manifest))
+		if !client.DisableHooks {
+			for _, m := range rel.Hooks {
+				fmt.Fprintf(out, "---\n# Source: %s\n%s\n", m.Path, m.Manifest)
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return errors.Errorf("%d of %d combinations of the values matrix failed to render:\n%s", len(failures), len(combinations), strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// locateChart returns the path of the chart to install.
+func locateChart(args []string, client *action.Install) (string, error) {
+	_, chartRef, err := client.NameAndChart(args)
+	if err != nil {
+		return "", err
+	}
+	if err := checkOCIChartRef(chartRef); err != nil {
+		return "", err
+	}
+	return client.ChartPathOptions.LocateChart(chartRef, settings)
+}
+
+// loadInstallableChart loads the chart at the given path, checking that its
+// dependencies are present.
+func loadInstallableChart(cp string) (*chart.Chart, error) {
+	ch, err := loader.Load(cp)
+	if err != nil {
+		return nil, err
+	}
+	if req := ch.Metadata.Dependencies; req != nil {
+		if err := action.CheckDependencies(ch, req); err != nil {
+			return nil, err
+		}
+	}
+	return ch, nil
+}
+
+// loadChartWithValueSources loads the chart to install and merges the user
+// supplied values, keeping track of the source of each of them.
+func loadChartWithValueSources(args []string, client *action.Install, valueOpts *values.Options) (*chart.Chart, map[string]interface{}, []chartutil.ValuesSource, error) {
+	cp, err := locateChart(args, client)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	vals, sources, err := valueOpts.MergeValuesWithSources(getter.All(settings))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ch, err := loadInstallableChart(cp)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return ch, vals, sources, nil
+}
+
 // The following functions (writeToFile, createOrOpenFile, and ensureDirectoryForFile)
 // are coppied from the actions package. This is part of a change to correct a
 // bug introduced by #8156. As part of the todo to refactor renderResources
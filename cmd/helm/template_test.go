@@ -41,6 +41,17 @@ func TestTemplateCmd(t *testing.T) {
 			cmd:    fmt.Sprintf("template '%s' --values '%s'", chartPath, filepath.Join(chartPath, "/charts/subchartA/values.yaml")),
 			golden: "output/template-values-files.txt",
 		},
+		{
+			name:   "check debug values",
+			cmd:    fmt.Sprintf("template '%s' --debug-values subcharta --set subcharta.service.name=httpd", chartPath),
+			golden: "output/template-debug-values.txt",
+		},
+		{
+			name:      "check debug values of a missing subchart",
+			cmd:       fmt.Sprintf("template '%s' --debug-values missing", chartPath),
+			wantError: true,
+			golden:    "output/template-debug-values-missing.txt",
+		},
 		{
 			name:   "check name template",
 			cmd:    fmt.Sprintf(`template '%s' --name-template='foobar-{{ b64enc "abc" }}-baz'`, chartPath),
@@ -79,6 +90,27 @@ func TestTemplateCmd(t *testing.T) {
 			cmd:    fmt.Sprintf("template --api-versions helm.k8s.io/test '%s'", chartPath),
 			golden: "output/template-with-api-version.txt",
 		},
+		{
+			name:   "check values matrix",
+			cmd:    "template testdata/testcharts/chart-with-values-matrix --matrix --set persistence.size=1Gi",
+			golden: "output/template-matrix.txt",
+		},
+		{
+			name:      "check values matrix with a failing combination",
+			cmd:       "template testdata/testcharts/chart-with-values-matrix --matrix",
+			wantError: true,
+		},
+		{
+			name:   "check capabilities snapshot",
+			cmd:    fmt.Sprintf("template '%s' --capabilities-snapshot testdata/capabilities-snapshot.yaml", chartPath),
+			golden: "output/template-with-capabilities-snapshot.txt",
+		},
+		{
+			name:      "check capabilities snapshot with from-cluster",
+			cmd:       fmt.Sprintf("template '%s' --capabilities-snapshot testdata/capabilities-snapshot.yaml --from-cluster", chartPath),
+			golden:    "output/template-capabilities-snapshot-from-cluster.txt",
+			wantError: true,
+		},
 		{
 			name:   "template with CRDs",
 			cmd:    fmt.Sprintf("template '%s' --include-crds", chartPath),
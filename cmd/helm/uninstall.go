@@ -19,12 +19,17 @@ package main
 import (
 	"fmt"
 	"io"
+	"sync"
 	"time"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/messages"
+	"helm.sh/helm/v3/pkg/cli/output"
+	"helm.sh/helm/v3/pkg/release"
 )
 
 const uninstallDesc = `
@@ -35,10 +40,28 @@ as well as the release history, freeing it up for future use.
 
 Use the '--dry-run' flag to see which releases will be uninstalled without actually
 uninstalling them.
+
+If some resources cannot be deleted, the release is left in the 'uninstalling'
+state instead of being removed, and the command fails. Once the cause is fixed,
+use the '--resume' flag to delete the remaining resources and finish the
+uninstall. The pre-delete hooks, which already ran, are skipped.
+
+With '--output json' or '--output yaml', a report of the deletion of each
+resource is printed: deleted, kept-by-policy, not-found or failed.
+
+Instead of release names, the '--selector' flag selects the releases to
+uninstall by their labels, as in 'helm list':
+
+    $ helm uninstall --selector team=payments
+
+The releases are uninstalled concurrently, up to '--max-workers' at a time. The
+command fails if any of them cannot be uninstalled, after trying all of them.
 `
 
 func newUninstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewUninstall(cfg)
+	batch := action.NewBatch(cfg)
+	var outfmt output.Format
 
 	cmd := &cobra.Command{
 		Use:        "uninstall RELEASE_NAME [...]",
@@ -46,7 +69,12 @@ func newUninstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		SuggestFor: []string{"remove", "rm"},
 		Short:      "uninstall a release",
 		Long:       uninstallDesc,
-		Args:       require.MinimumNArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if batch.Selector != "" {
+				return require.NoArgs(cmd, args)
+			}
+			return require.MinimumNArgs(1)(cmd, args)
+		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			if len(args) != 0 {
 				return nil, cobra.ShellCompDirectiveNoFileComp
@@ -54,17 +82,17 @@ func newUninstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			return compListReleases(toComplete, cfg)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if batch.Selector != "" {
+				return runBatchUninstall(out, client, batch, outfmt)
+			}
 			for i := 0; i < len(args); i++ {
-
 				res, err := client.Run(args[i])
-				if err != nil {
+				if err := writeUninstallResult(out, args[i], res, err, outfmt); err != nil {
 					return err
 				}
-				if res != nil && res.Info != "" {
-					fmt.Fprintln(out, res.Info)
+				if err != nil {
+					return err
 				}
-
-				fmt.Fprintf(out, "release \"%s\" uninstalled\n", args[i])
 			}
 			return nil
 		},
@@ -76,6 +104,84 @@ func newUninstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.KeepHistory, "keep-history", false, "remove all associated resources and mark the release as deleted, but retain the release history")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
 	f.StringVar(&client.Description, "description", "", "add a custom description")
+	f.BoolVar(&client.Resume, "resume", false, "resume a partially failed uninstall, deleting the remaining resources of a release that is still uninstalling")
+	f.StringVarP(&batch.Selector, "selector", "l", "", "uninstall the releases matching this selector (label query) instead of the named ones, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	f.IntVar(&batch.Workers, "max-workers", 4, "maximum number of releases uninstalled concurrently with --selector")
+	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
 }
+
+// runBatchUninstall uninstalls the releases selected by batch, and writes the
+// result of each of them in the order they were listed.
+func runBatchUninstall(out io.Writer, client *action.Uninstall, batch *action.Batch, outfmt output.Format) error {
+	if client.Resume {
+		// Only the releases left uninstalling can be resumed.
+		batch.StateMask = action.ListUninstalling
+	}
+	var mu sync.Mutex
+	responses := map[string]*release.UninstallReleaseResponse{}
+	report, err := batch.Run(func(name string) error {
+		res, err := client.Run(name)
+		mu.Lock()
+		responses[name] = res
+		mu.Unlock()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(report.Results) == 0 {
+		fmt.Fprintf(out, "no releases match the selector %q\n", batch.Selector)
+		return nil
+	}
+	for _, r := range report.Results {
+		if err := writeUninstallResult(out, r.Name, responses[r.Name], r.Error, outfmt); err != nil {
+			return err
+		}
+		if r.Error != nil && outfmt == output.Table {
+			fmt.Fprintf(out, "release \"%s\" failed to uninstall: %s\n", r.Name, r.Error)
+		}
+	}
+	return report.Err()
+}
+
+// writeUninstallResult writes the report of the uninstall of a release with a
+// structured output format, or a line saying it was uninstalled with a table.
+func writeUninstallResult(out io.Writer, name string, res *release.UninstallReleaseResponse, uninstallErr error, outfmt output.Format) error {
+	if outfmt != output.Table {
+		if res != nil && res.Report != nil {
+			return outfmt.Write(out, &uninstallReport{res.Report})
+		}
+		return nil
+	}
+	if uninstallErr != nil {
+		return nil
+	}
+	if res != nil && res.Info != "" {
+		fmt.Fprintln(out, res.Info)
+	}
+	info(out, messages.ReleaseUninstalled, name)
+	return nil
+}
+
+type uninstallReport struct {
+	*release.UninstallReport
+}
+
+func (r *uninstallReport) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, r.UninstallReport)
+}
+
+func (r *uninstallReport) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, r.UninstallReport)
+}
+
+func (r *uninstallReport) WriteTable(out io.Writer) error {
+	tbl := uitable.New()
+	tbl.AddRow("KIND", "NAME", "NAMESPACE", "OUTCOME", "ERROR")
+	for _, res := range r.Resources {
+		tbl.AddRow(res.Kind, res.Name, res.Namespace, res.Outcome, res.Error)
+	}
+	return output.EncodeTable(out, tbl)
+}
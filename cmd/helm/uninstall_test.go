@@ -57,6 +57,34 @@ func TestUninstall(t *testing.T) {
 			golden: "output/uninstall-keep-history.txt",
 			rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "aeneas"})},
 		},
+		{
+			name:      "resume an uninstall of a deployed release",
+			cmd:       "uninstall aeneas --resume",
+			rels:      []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "aeneas"})},
+			wantError: true,
+		},
+		{
+			name:   "uninstall by selector",
+			cmd:    "uninstall --selector team=payments",
+			golden: "output/uninstall-selector.txt",
+			rels: []*release.Release{
+				labeledRelease("aeneas", "payments"),
+				labeledRelease("aeneas2", "payments"),
+				labeledRelease("dido", "discovery"),
+			},
+		},
+		{
+			name:   "uninstall by selector without matches",
+			cmd:    "uninstall --selector team=search",
+			golden: "output/uninstall-selector-no-match.txt",
+			rels:   []*release.Release{labeledRelease("aeneas", "payments")},
+		},
+		{
+			name:      "uninstall by selector with a release name",
+			cmd:       "uninstall aeneas --selector team=payments",
+			rels:      []*release.Release{labeledRelease("aeneas", "payments")},
+			wantError: true,
+		},
 		{
 			name:      "uninstall without release",
 			cmd:       "uninstall",
@@ -67,6 +95,12 @@ func TestUninstall(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func labeledRelease(name, team string) *release.Release {
+	rel := release.Mock(&release.MockReleaseOptions{Name: name})
+	rel.Labels = map[string]string{"team": team}
+	return rel
+}
+
 func TestUninstallFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "uninstall", false)
 	checkFileCompletion(t, "uninstall myrelease", false)
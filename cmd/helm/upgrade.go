@@ -17,19 +17,25 @@ limitations under the License.
 package main
 
 import (
+	"bufio"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
+	"strings"
 	"time"
 
+	"github.com/gosuri/uitable"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli/messages"
 	"helm.sh/helm/v3/pkg/cli/output"
 	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/diff"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/storage/driver"
 )
@@ -59,13 +65,75 @@ last (right-most) set specified. For example, if both 'bar' and 'newbar' values
 set for a key called 'foo', the 'newbar' value would take precedence:
 
     $ helm upgrade --set foo=bar --set foo=newbar redis ./redis
+
+With '--install', values that must only be sent when the release is first
+installed, such as an initial admin password, can be given with the
+'--install-values', '--install-set', '--install-set-string' and
+'--install-set-file' flags. They are recorded apart from the release's values,
+so later upgrades, including those using '--reuse-values', do not send them
+again:
+
+    $ helm upgrade --install --install-set auth.adminPassword=$PASSWORD redis ./redis
+
+With '--skip-if-unchanged', the upgrade is skipped and no revision is created
+if the chart, the values and the rendered manifests are identical to those of
+the deployed revision. This keeps the history meaningful when upgrades are run
+on a schedule.
+
+With '--confirm', the plan of the upgrade is printed first: the resources it
+would create, update and delete, and the hooks it would run. The upgrade then
+proceeds only once the name of the release is typed to confirm it. For changes
+approved ahead of time, the token printed with the plan can be written to a
+file given with '--confirm-file' instead: the upgrade proceeds only if the plan
+computed when it runs has the same token, so that nothing changed since it was
+reviewed. With '--dry-run', '--confirm' prints the plan and its token without
+asking for confirmation:
+
+    $ helm upgrade --dry-run --confirm redis ./redis
+    $ helm upgrade --confirm-file approved-token redis ./redis
+
+With '--diff', the changes of the resources are printed before the upgrade, so
+that '--dry-run --diff' previews it. They are rendered as a unified diff, or
+with the renderer named by '--diff-renderer': 'unified', 'side-by-side',
+'json-patch', or 'external:' followed by a command, such as dyff, that is given
+the paths of the current and upgraded manifests:
+
+    $ helm upgrade --dry-run --diff --diff-renderer 'external:dyff between' redis ./redis
+
+With '--force', resources are replaced rather than patched. Those that cannot
+be replaced, such as for changes of immutable fields, are deleted and created
+again once their deletion completed, waiting up to '--deletion-timeout' for the
+finalizers of the old objects to run.
+
+The '--wait', '--wait-for-jobs', '--timeout' and '--atomic' flags that are not
+set default to those recorded with the release by '--record-defaults', if any.
+With '--record-defaults', the flags of the upgrade are recorded instead.
+
+With '--dry-run=server', the resources and the upgrade hooks are also sent to
+the API server in dry-run mode, to check them against its admission policies
+and webhooks. See 'helm install --help' for details.
+
+Jobs annotated with 'helm.sh/hook: migration' run after the pre-upgrade hooks,
+once per chart version: those that already succeeded for the version of the
+chart are skipped. If a migration failed or was interrupted, later upgrades
+fail until it has been looked into and '--retry-failed-migrations' is given.
+
+Values can refer to secrets with strings of the form
+'ref+<backend>://<path>#<key>', which are resolved when the chart is rendered.
+See 'helm install --help' for the supported backends.
 `
 
 func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewUpgrade(cfg)
+	client.RegistryClient = ociRegistryClient(cfg)
 	valueOpts := &values.Options{}
+	installValueOpts := &values.Options{}
 	var outfmt output.Format
 	var createNamespace bool
+	var confirm bool
+	var confirmFile string
+	var showDiff bool
+	var diffRenderer string
 
 	cmd := &cobra.Command{
 		Use:   "upgrade [RELEASE] [CHART]",
@@ -83,6 +151,16 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client.Namespace = settings.Namespace()
+			client.SecretResolver = newSecretResolver()
+			if err := applyProfileDefaults(cmd.Flags(), &client.Wait, &client.WaitForJobs, &client.Timeout, &client.PostRenderer); err != nil {
+				return err
+			}
+			if len(client.ValuesRefs) > 0 && !FeatureGateOCI.IsEnabled() {
+				return FeatureGateOCI.Error()
+			}
+			if err := applyEnvFeatures(&client.Features); err != nil {
+				return err
+			}
 
 			// Fixes #7002 - Support reading values from STDIN for `upgrade` command
 			// Must load values AFTER determining if we have to call install so that values loaded from stdin are are not read twice
@@ -91,42 +169,76 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				histClient := action.NewHistory(cfg)
 				histClient.Max = 1
 				if _, err := histClient.Run(args[0]); err == driver.ErrReleaseNotFound {
+					if confirm || confirmFile != "" || showDiff {
+						return errors.Errorf("release %q does not exist: --confirm and --diff only apply to upgrades", args[0])
+					}
 					// Only print this to stdout for table output
 					if outfmt == output.Table {
-						fmt.Fprintf(out, "Release %q does not exist. Installing it now.\n", args[0])
+						info(out, messages.ReleaseInstalling, args[0])
 					}
 					instClient := action.NewInstall(cfg)
 					instClient.CreateNamespace = createNamespace
 					instClient.ChartPathOptions = client.ChartPathOptions
 					instClient.DryRun = client.DryRun
+					instClient.ServerDryRun = client.ServerDryRun
 					instClient.DisableHooks = client.DisableHooks
 					instClient.SkipCRDs = client.SkipCRDs
 					instClient.Timeout = client.Timeout
 					instClient.Wait = client.Wait
 					instClient.WaitForJobs = client.WaitForJobs
+					instClient.WaitFor = client.WaitFor
+					instClient.WaitForEndpoints = client.WaitForEndpoints
 					instClient.Devel = client.Devel
 					instClient.Namespace = client.Namespace
 					instClient.Atomic = client.Atomic
 					instClient.PostRenderer = client.PostRenderer
 					instClient.DisableOpenAPIValidation = client.DisableOpenAPIValidation
 					instClient.SubNotes = client.SubNotes
+					instClient.OnlySubcharts = client.OnlySubcharts
+					instClient.SkipSubcharts = client.SkipSubcharts
 					instClient.Description = client.Description
+					instClient.OverridePause = client.OverridePause
+					instClient.NoDeprecated = client.NoDeprecated
+					instClient.StrictCompatibility = client.StrictCompatibility
+					instClient.StrictValues = client.StrictValues
+					instClient.AllowOwnershipTransfer = client.AllowOwnershipTransfer
+					instClient.ValuesRefs = client.ValuesRefs
+					instClient.Features = client.Features
+					instClient.StandardLabels = client.StandardLabels
+					instClient.LabelConflicts = client.LabelConflicts
+					instClient.RecordDefaults = client.RecordDefaults
+
+					installVals, err := installValueOpts.MergeValues(getter.All(settings))
+					if err != nil {
+						return err
+					}
+					if len(installVals) > 0 {
+						instClient.InstallValues = installVals
+					}
 
 					rel, err := runInstall(args, instClient, valueOpts, out)
 					if err != nil {
 						return err
 					}
-					return outfmt.Write(out, &statusPrinter{rel, settings.Debug, false})
+					if err := outfmt.Write(out, &statusPrinter{rel, settings.Debug, false, false}); err != nil {
+						return err
+					}
+					return reportAdmission(admissionOut(cmd, outfmt, out), instClient.ServerDryRun && instClient.DryRun, instClient.AdmissionResults)
 				} else if err != nil {
 					return err
 				}
 			}
 
+			applyReleaseDefaults(cfg, cmd.Flags(), args[0], &client.Wait, &client.WaitForJobs, &client.Timeout, &client.Atomic)
+
 			if client.Version == "" && client.Devel {
 				debug("setting version to >0.0.0-0")
 				client.Version = ">0.0.0-0"
 			}
 
+			if err := checkOCIChartRef(args[1]); err != nil {
+				return err
+			}
 			chartPath, err := client.ChartPathOptions.LocateChart(args[1], settings)
 			if err != nil {
 				return err
@@ -142,26 +254,66 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			verbose(messages.LoadedChart, ch.Name(), ch.Metadata.Version, chartPath)
 			if req := ch.Metadata.Dependencies; req != nil {
 				if err := action.CheckDependencies(ch, req); err != nil {
 					return err
 				}
 			}
 
-			if ch.Metadata.Deprecated {
-				warning("This chart is deprecated")
+			if confirm || confirmFile != "" || showDiff {
+				renderer, err := diff.NewRenderer(diffRenderer)
+				if err != nil {
+					return err
+				}
+				// The plan is kept apart from structured output
+				planOut := out
+				if outfmt != output.Table {
+					planOut = cmd.ErrOrStderr()
+				}
+				plan, err := client.Plan(args[0], ch, vals)
+				if err != nil {
+					return errors.Wrap(err, "UPGRADE FAILED")
+				}
+				if showDiff {
+					if err := renderer.Render(planOut, plan.Diffs); err != nil {
+						return err
+					}
+				}
+				if confirm || confirmFile != "" {
+					if err := writeUpgradePlan(planOut, plan); err != nil {
+						return err
+					}
+				}
+				if !client.DryRun && (confirm || confirmFile != "") {
+					if err := confirmUpgradePlan(cmd.InOrStdin(), planOut, plan, confirmFile); err != nil {
+						return err
+					}
+				}
 			}
 
 			rel, err := client.Run(args[0], ch, vals)
+			if !client.StrictCompatibility {
+				for _, c := range client.Incompatibilities {
+					warning("%s", c)
+				}
+			}
 			if err != nil {
 				return errors.Wrap(err, "UPGRADE FAILED")
 			}
 
 			if outfmt == output.Table {
-				fmt.Fprintf(out, "Release %q has been upgraded. Happy Helming!\n", args[0])
+				if client.Skipped {
+					info(out, messages.ReleaseUnchanged, args[0])
+				} else {
+					info(out, messages.ReleaseUpgraded, args[0])
+				}
 			}
 
-			return outfmt.Write(out, &statusPrinter{rel, settings.Debug, false})
+			if err := outfmt.Write(out, &statusPrinter{rel, settings.Debug, false, false}); err != nil {
+				return err
+			}
+			return reportAdmission(admissionOut(cmd, outfmt, out), client.ServerDryRun && client.DryRun, client.AdmissionResults)
 		},
 	}
 
@@ -169,11 +321,13 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&createNamespace, "create-namespace", false, "if --install is set, create the release namespace if not present")
 	f.BoolVarP(&client.Install, "install", "i", false, "if a release by this name doesn't already exist, run an install")
 	f.BoolVar(&client.Devel, "devel", false, "use development versions, too. Equivalent to version '>0.0.0-0'. If --version is set, this is ignored")
-	f.BoolVar(&client.DryRun, "dry-run", false, "simulate an upgrade")
+	addDryRunFlag(f, &client.DryRun, &client.ServerDryRun, "simulate an upgrade. With --dry-run=server, the resources and hooks are also sent to the API server in dry-run mode, to check them against its admission policies and webhooks")
 	f.BoolVar(&client.Recreate, "recreate-pods", false, "performs pods restart for the resource if applicable")
 	f.MarkDeprecated("recreate-pods", "functionality will no longer be updated. Consult the documentation for other methods to recreate pods")
 	f.BoolVar(&client.Force, "force", false, "force resource updates through a replacement strategy")
+	f.DurationVar(&client.DeletionTimeout, "deletion-timeout", 2*time.Minute, "with --force, time to wait for the deletion of the resources that cannot be replaced before recreating them")
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "disable pre/post upgrade hooks")
+	f.BoolVar(&client.RetryFailedMigrations, "retry-failed-migrations", false, "run the migration hooks that failed or were interrupted in the last revision again")
 	f.BoolVar(&client.DisableOpenAPIValidation, "disable-openapi-validation", false, "if set, the upgrade process will not validate rendered templates against the Kubernetes OpenAPI Schema")
 	f.BoolVar(&client.SkipCRDs, "skip-crds", false, "if set, no CRDs will be installed when an upgrade is performed with install flag enabled. By default, CRDs are installed if not already present, when an upgrade is performed with install flag enabled")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
@@ -181,14 +335,34 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.ReuseValues, "reuse-values", false, "when upgrading, reuse the last release's values and merge in any overrides from the command line via --set and -f. If '--reset-values' is specified, this is ignored")
 	f.BoolVar(&client.Wait, "wait", false, "if set, will wait until all Pods, PVCs, Services, and minimum number of Pods of a Deployment, StatefulSet, or ReplicaSet are in a ready state before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
+	bindWaitForFlag(f, &client.WaitFor)
+	bindFeatureFlag(f, &client.Features)
+	f.BoolVar(&client.StandardLabels, "standard-labels", false, "add the recommended app.kubernetes.io labels and the helm.sh/chart label to the resources of the release")
+	f.StringVar((*string)(&client.LabelConflicts), "label-conflicts", string(action.LabelConflictKeep), "with --standard-labels, what to do with the labels that resources already set to other values: keep, overwrite or error")
+	f.IntVar(&client.WaitForEndpoints, "wait-for-endpoints", 0, "if set, will wait until each Service with a selector has at least this number of ready endpoints before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.Atomic, "atomic", false, "if set, upgrade process rolls back changes made in case of failed upgrade. The --wait flag will be set automatically if --atomic is used")
 	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit")
 	f.BoolVar(&client.CleanupOnFail, "cleanup-on-fail", false, "allow deletion of new resources created in this upgrade when upgrade fails")
 	f.BoolVar(&client.SubNotes, "render-subchart-notes", false, "if set, render subchart notes along with the parent")
+	f.StringSliceVar(&client.OnlySubcharts, "only-subchart", []string{}, "render and apply only the given subcharts, deleting the resources of the rest of the chart (can specify multiple or separate values with commas: redis,ingress). Nested subcharts are given as dotted paths, such as backend.redis")
+	f.StringSliceVar(&client.SkipSubcharts, "skip-subchart", []string{}, "do not render the given subcharts, deleting their resources (can specify multiple or separate values with commas: metrics,ingress)")
 	f.StringVar(&client.Description, "description", "", "add a custom description")
+	f.BoolVar(&client.OverridePause, "override-pause", false, "upgrade the release even if it is paused. The release stays paused")
+	f.BoolVar(&client.NoDeprecated, "no-deprecated", false, "fail instead of warning if the chart or one of its subcharts is deprecated")
+	f.BoolVar(&client.StrictCompatibility, "strict-compatibility", false, "fail instead of warning if the compatibility matrix of the chart or one of its subcharts does not support the app or Kubernetes version, or upgrades from the deployed chart version")
+	f.BoolVar(&client.StrictValues, "strict-values", false, "fail instead of warning if values that the schema of the chart or of one of its subcharts deprecates are set")
+	f.BoolVar(&client.AllowOwnershipTransfer, "allow-ownership-transfer", false, "adopt existing resources owned by another release or by other field managers instead of failing")
+	f.BoolVar(&client.SkipIfUnchanged, "skip-if-unchanged", false, "do not create a new revision if the chart, values and rendered manifests are identical to the deployed revision")
+	f.BoolVar(&client.RecordDefaults, "record-defaults", false, "record --wait, --wait-for-jobs, --timeout and --atomic with the release as the defaults of its later upgrades and rollbacks")
+	f.BoolVar(&confirm, "confirm", false, "print the plan of the upgrade and ask for confirmation before performing it")
+	f.StringVar(&confirmFile, "confirm-file", "", "perform the upgrade only if its plan has the token held in this file, instead of asking for confirmation")
+	f.BoolVar(&showDiff, "diff", false, "print the changes of the resources of the release before upgrading it")
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
 	addValueOptionsFlags(f, valueOpts)
+	addValuesRefFlag(f, &client.ValuesRefs)
+	addInstallValueOptionsFlags(f, installValueOpts)
 	bindOutputFlag(cmd, &outfmt)
+	bindDiffRendererFlag(cmd, &diffRenderer)
 	bindPostRenderFlag(cmd, &client.PostRenderer)
 
 	err := cmd.RegisterFlagCompletionFunc("version", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -204,3 +378,56 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	return cmd
 }
+
+// writeUpgradePlan writes what an upgrade would do, along with the token of
+// the plan.
+func writeUpgradePlan(out io.Writer, plan *action.UpgradePlan) error {
+	fmt.Fprintf(out, "UPGRADE PLAN: release %q in namespace %q, revision %d to %d, chart %s\n",
+		plan.Release, plan.Namespace, plan.CurrentRevision, plan.Revision, plan.Chart)
+	table := uitable.New()
+	table.AddRow("CHANGE", "KIND", "NAME", "NAMESPACE")
+	for _, r := range plan.Resources {
+		name := r.Name
+		if name == "" {
+			name = r.GenerateName + "*"
+		}
+		table.AddRow(r.Change, r.Kind, name, r.Namespace)
+	}
+	if err := output.EncodeTable(out, table); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "SUMMARY: %d to create, %d to update, %d to delete, %d unchanged, %d kept by policy\n",
+		plan.Count(action.ChangeCreate), plan.Count(action.ChangeUpdate), plan.Count(action.ChangeDelete),
+		plan.Count(action.ChangeNone), plan.Count(action.ChangeKeep))
+	if err := writeHookPlan(out, plan.Hooks); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "PLAN TOKEN: %s\n", plan.Token())
+	return nil
+}
+
+// confirmUpgradePlan returns an error unless the plan is approved, either by
+// the token held in confirmFile, or interactively by typing the name of the
+// release.
+func confirmUpgradePlan(in io.Reader, out io.Writer, plan *action.UpgradePlan, confirmFile string) error {
+	if confirmFile != "" {
+		data, err := ioutil.ReadFile(confirmFile)
+		if err != nil {
+			return errors.Wrap(err, "unable to read the approved plan token")
+		}
+		if token := strings.TrimSpace(string(data)); token != plan.Token() {
+			return errors.Errorf("the plan token %q in %s does not match the plan of the upgrade, %s: the upgrade was not approved", token, confirmFile, plan.Token())
+		}
+		return nil
+	}
+
+	fmt.Fprintf(out, "Type the name of the release to perform the upgrade: ")
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return errors.Wrap(err, "unable to read the confirmation")
+	}
+	if strings.TrimSpace(answer) != plan.Release {
+		return errors.Errorf("the upgrade of release %q was not confirmed", plan.Release)
+	}
+	return nil
+}
@@ -349,6 +349,68 @@ func TestUpgradeInstallWithValuesFromStdin(t *testing.T) {
 
 }
 
+func TestUpgradeConfirm(t *testing.T) {
+	releaseName := "funny-bunny-confirm"
+	relMock, ch, chartPath := prepareMockRelease(releaseName, t)
+
+	defer resetEnv()()
+
+	store := storageFixture()
+	store.Create(relMock(releaseName, 3, ch))
+
+	tmpdir := ensure.TempDir(t)
+	defer os.RemoveAll(tmpdir)
+	answer := filepath.Join(tmpdir, "answer")
+
+	// A wrong answer aborts the upgrade
+	if err := ioutil.WriteFile(answer, []byte("no\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	in, err := os.Open(answer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	cmd := fmt.Sprintf("upgrade %s --confirm '%s'", releaseName, chartPath)
+	_, out, err := executeActionCommandStdinC(store, in, cmd)
+	if err == nil || !strings.Contains(err.Error(), "was not confirmed") {
+		t.Errorf("expected the upgrade not to be confirmed, got '%v'", err)
+	}
+	if !strings.Contains(out, "UPGRADE PLAN:") || !strings.Contains(out, "PLAN TOKEN:") {
+		t.Errorf("expected the plan to be printed, got %s", out)
+	}
+	if _, err := store.Get(releaseName, 4); err == nil {
+		t.Error("expected no new revision")
+	}
+
+	// A token that does not match the plan aborts the upgrade
+	token := filepath.Join(tmpdir, "token")
+	if err := ioutil.WriteFile(token, []byte("0123456789abcdef\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd = fmt.Sprintf("upgrade %s --confirm-file '%s' '%s'", releaseName, token, chartPath)
+	if _, _, err := executeActionCommandC(store, cmd); err == nil || !strings.Contains(err.Error(), "does not match the plan") {
+		t.Errorf("expected the token not to match, got '%v'", err)
+	}
+
+	// The name of the release confirms the upgrade
+	if err := ioutil.WriteFile(answer, []byte(releaseName+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	in2, err := os.Open(answer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in2.Close()
+	cmd = fmt.Sprintf("upgrade %s --confirm '%s'", releaseName, chartPath)
+	if _, _, err := executeActionCommandStdinC(store, in2, cmd); err != nil {
+		t.Errorf("unexpected error, got '%v'", err)
+	}
+	if _, err := store.Get(releaseName, 4); err != nil {
+		t.Errorf("expected a new revision, got '%v'", err)
+	}
+}
+
 func prepareMockRelease(releaseName string, t *testing.T) (func(n string, v int, ch *chart.Chart) *release.Release, *chart.Chart, string) {
 	tmpChart := ensure.TempDir(t)
 	configmapData, err := ioutil.ReadFile("testdata/testcharts/upgradetest/templates/configmap.yaml")
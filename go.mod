@@ -3,6 +3,7 @@ module helm.sh/helm/v3
 go 1.14
 
 require (
+	cuelang.org/go v0.2.1
 	github.com/BurntSushi/toml v0.3.1
 	github.com/DATA-DOG/go-sqlmock v1.5.0
 	github.com/Masterminds/goutils v1.1.0
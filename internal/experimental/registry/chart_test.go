@@ -0,0 +1,67 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChartReference(t *testing.T) {
+	is := assert.New(t)
+
+	is.True(IsOCI("oci://localhost:5000/charts/mychart"))
+	is.False(IsOCI("example/mychart"))
+	is.True(IsDigest(testDigest))
+	is.False(IsDigest("1.2.0"))
+
+	ref, err := ParseChartReference("oci://localhost:5000/charts/mychart")
+	is.NoError(err)
+	is.Equal("localhost:5000/charts/mychart", ref.Repo)
+	is.Empty(ref.Tag)
+	is.Empty(ref.Digest)
+
+	is.NoError(ref.SetVersion("1.2.0"))
+	is.Equal("1.2.0", ref.Tag)
+	is.Equal("localhost:5000/charts/mychart:1.2.0", ref.FullName())
+
+	is.NoError(ref.SetVersion(testDigest))
+	is.Equal(testDigest, ref.Digest)
+	is.Equal("localhost:5000/charts/mychart@"+testDigest, ref.FullName())
+	is.Equal("oci://localhost:5000/charts/mychart:1.2.0@"+testDigest, ref.String())
+
+	ref, err = ParseChartReference("oci://localhost:5000/charts/mychart@" + testDigest)
+	is.NoError(err)
+	is.Empty(ref.Tag)
+	is.Equal(testDigest, ref.Digest)
+	is.NoError(ref.SetVersion(""))
+	is.Error(ref.SetVersion("sha256:fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"), "other digest")
+
+	ref, err = ParseChartReference("oci://localhost:5000/charts/mychart:1.2.0")
+	is.NoError(err)
+	is.NoError(ref.SetVersion("1.2.0"))
+	is.Error(ref.SetVersion("1.3.0"), "other tag")
+
+	ref, err = ParseChartReference("oci://localhost:5000/charts/mychart")
+	is.NoError(err)
+	is.Error(ref.SetVersion("^1.2.0"), "version range")
+	is.Error(ref.SetVersion("1.2"), "partial version")
+
+	_, err = ParseChartReference("oci://localhost:5000/charts/mychart@sha256:nope")
+	is.Error(err, "invalid digest")
+}
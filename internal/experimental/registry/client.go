@@ -18,19 +18,24 @@ package registry // import "helm.sh/helm/v3/internal/experimental/registry"
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"sort"
 
+	"github.com/containerd/containerd/remotes/docker"
 	auth "github.com/deislabs/oras/pkg/auth/docker"
+	orascontent "github.com/deislabs/oras/pkg/content"
 	"github.com/deislabs/oras/pkg/oras"
 	"github.com/gosuri/uitable"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 
+	helmauth "helm.sh/helm/v3/pkg/auth"
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/helmpath"
 )
 
@@ -45,10 +50,14 @@ type (
 		debug bool
 		// path to repository config file e.g. ~/.docker/config.json
 		credentialsFile string
-		out             io.Writer
-		authorizer      *Authorizer
-		resolver        *Resolver
-		cache           *Cache
+		// credentialsProvider is consulted before the credentials file
+		credentialsProvider helmauth.Provider
+		out                 io.Writer
+		authorizer          *Authorizer
+		resolver            *Resolver
+		cache               *Cache
+		// offline refuses every operation reaching a registry
+		offline bool
 	}
 )
 
@@ -78,6 +87,12 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 		if err != nil {
 			return nil, err
 		}
+		if client.credentialsProvider != nil {
+			resolver = docker.NewResolver(docker.ResolverOptions{
+				Credentials: client.credential,
+				Client:      http.DefaultClient,
+			})
+		}
 		client.resolver = &Resolver{
 			Resolver: resolver,
 		}
@@ -98,6 +113,9 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 
 // Login logs into a registry
 func (c *Client) Login(hostname string, username string, password string, insecure bool) error {
+	if err := c.checkOnline(hostname); err != nil {
+		return err
+	}
 	err := c.authorizer.Login(ctx(c.out, c.debug), hostname, username, password, insecure)
 	if err != nil {
 		return err
@@ -108,6 +126,9 @@ func (c *Client) Login(hostname string, username string, password string, insecu
 
 // Logout logs out of a registry
 func (c *Client) Logout(hostname string) error {
+	if err := c.checkOnline(hostname); err != nil {
+		return err
+	}
 	err := c.authorizer.Logout(ctx(c.out, c.debug), hostname)
 	if err != nil {
 		return err
@@ -116,8 +137,37 @@ func (c *Client) Logout(hostname string) error {
 	return nil
 }
 
+// checkOnline fails with getter.ErrOffline when the client is offline
+func (c *Client) checkOnline(host string) error {
+	if c.offline {
+		return errors.Wrapf(getter.ErrOffline, "cannot reach registry %s", host)
+	}
+	return nil
+}
+
+// credential returns the credentials of a registry host from the credentials
+// provider, or from the credentials file if the provider has none
+func (c *Client) credential(hostname string) (string, string, error) {
+	creds, err := c.credentialsProvider.Credentials(hostname)
+	if err != nil {
+		return "", "", err
+	}
+	if creds != nil {
+		return creds.Username, creds.Password, nil
+	}
+	if cc, ok := c.authorizer.Client.(interface {
+		Credential(string) (string, string, error)
+	}); ok {
+		return cc.Credential(hostname)
+	}
+	return "", "", nil
+}
+
 // PushChart uploads a chart to a registry
 func (c *Client) PushChart(ref *Reference) error {
+	if err := c.checkOnline(ref.Repo); err != nil {
+		return err
+	}
 	r, err := c.cache.FetchReference(ref)
 	if err != nil {
 		return err
@@ -143,11 +193,43 @@ func (c *Client) PushChart(ref *Reference) error {
 	return nil
 }
 
+// PushChartArchive uploads a packaged chart to a registry without storing it
+// in the local cache, and returns the digest of the pushed manifest
+func (c *Client) PushChartArchive(ref *Reference, ch *chart.Chart, archive []byte) (string, error) {
+	if ref.Tag == "" {
+		return "", errors.New("tag explicitly required")
+	}
+	if err := c.checkOnline(ref.Repo); err != nil {
+		return "", err
+	}
+	configBytes, err := json.Marshal(ch.Metadata)
+	if err != nil {
+		return "", err
+	}
+	store := orascontent.NewMemoryStore()
+	config := store.Add("", HelmChartConfigMediaType, configBytes)
+	layer := store.Add("", HelmChartContentLayerMediaType, archive)
+
+	fmt.Fprintf(c.out, "The push refers to repository [%s]\n", ref.Repo)
+	fmt.Fprintf(c.out, "name: %s\nversion: %s\n", ch.Metadata.Name, ch.Metadata.Version)
+	manifest, err := oras.Push(ctx(c.out, c.debug), c.resolver, ref.FullName(), store,
+		[]ocispec.Descriptor{layer}, oras.WithConfig(config), oras.WithNameValidation(nil))
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(c.out, "%s: pushed to remote (1 layer, %s total)\n", ref.Tag, byteCountBinary(layer.Size))
+	fmt.Fprintf(c.out, "Digest: %s\n", manifest.Digest)
+	return manifest.Digest.String(), nil
+}
+
 // PullChart downloads a chart from a registry
 func (c *Client) PullChart(ref *Reference) error {
 	if ref.Tag == "" {
 		return errors.New("tag explicitly required")
 	}
+	if err := c.checkOnline(ref.Repo); err != nil {
+		return err
+	}
 	existing, err := c.cache.FetchReference(ref)
 	if err != nil {
 		return err
@@ -18,6 +18,8 @@ package registry // import "helm.sh/helm/v3/internal/experimental/registry"
 
 import (
 	"io"
+
+	"helm.sh/helm/v3/pkg/auth"
 )
 
 type (
@@ -67,3 +69,19 @@ func ClientOptCredentialsFile(credentialsFile string) ClientOption {
 		client.credentialsFile = credentialsFile
 	}
 }
+
+// ClientOptCredentialsProvider returns a function that sets the provider consulted for the
+// credentials of registries before the credentials file on a client options set
+func ClientOptCredentialsProvider(provider auth.Provider) ClientOption {
+	return func(client *Client) {
+		client.credentialsProvider = provider
+	}
+}
+
+// ClientOptOffline returns a function that sets the offline setting on a client options set.
+// An offline client only works with the local chart cache
+func ClientOptOffline(offline bool) ClientOption {
+	return func(client *Client) {
+		client.offline = offline
+	}
+}
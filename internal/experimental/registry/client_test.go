@@ -39,10 +39,12 @@ import (
 	"github.com/docker/distribution/registry"
 	_ "github.com/docker/distribution/registry/auth/htpasswd"
 	_ "github.com/docker/distribution/registry/storage/driver/inmemory"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/crypto/bcrypt"
 
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/getter"
 )
 
 var (
@@ -212,6 +214,69 @@ func (suite *RegistryClientTestSuite) Test_4_PullChart() {
 	suite.Nil(err)
 }
 
+func (suite *RegistryClientTestSuite) Test_4a_PushPullValues() {
+	data := []byte("replicaCount: 3\n")
+
+	ref, err := ParseReference(fmt.Sprintf("%s/testrepo/overlays/prod:1.0.0", suite.DockerRegistryHost))
+	suite.Nil(err)
+	dgst, err := suite.RegistryClient.PushValues(ref, data)
+	suite.Nil(err)
+	suite.NotEmpty(dgst)
+
+	// by tag
+	valuesRef, err := ParseValuesReference(fmt.Sprintf("oci://%s/testrepo/overlays/prod:1.0.0", suite.DockerRegistryHost))
+	suite.Nil(err)
+	overlay, err := suite.RegistryClient.PullValues(valuesRef)
+	suite.Nil(err)
+	suite.Equal(dgst, overlay.Digest)
+	suite.Equal(data, overlay.Data)
+
+	// by digest
+	valuesRef, err = ParseValuesReference(fmt.Sprintf("oci://%s/testrepo/overlays/prod@%s", suite.DockerRegistryHost, dgst))
+	suite.Nil(err)
+	overlay, err = suite.RegistryClient.PullValues(valuesRef)
+	suite.Nil(err)
+	suite.Equal(data, overlay.Data)
+
+	// non-existent ref
+	valuesRef, err = ParseValuesReference(fmt.Sprintf("oci://%s/testrepo/overlays/whodis:9.9.9", suite.DockerRegistryHost))
+	suite.Nil(err)
+	_, err = suite.RegistryClient.PullValues(valuesRef)
+	suite.NotNil(err)
+}
+
+func (suite *RegistryClientTestSuite) Test_4b_PushPullChartArchive() {
+	ch := &chart.Chart{Metadata: &chart.Metadata{APIVersion: "v1", Name: "archived", Version: "0.2.0"}}
+	archive := []byte("chart archive")
+
+	ref, err := ParseReference(fmt.Sprintf("%s/testrepo/archived:0.2.0", suite.DockerRegistryHost))
+	suite.Nil(err)
+	dgst, err := suite.RegistryClient.PushChartArchive(ref, ch, archive)
+	suite.Nil(err)
+
+	// by version
+	chartRef, err := ParseChartReference(fmt.Sprintf("oci://%s/testrepo/archived", suite.DockerRegistryHost))
+	suite.Nil(err)
+	suite.Nil(chartRef.SetVersion("0.2.0"))
+	pulled, err := suite.RegistryClient.PullChartArchive(chartRef)
+	suite.Nil(err)
+	suite.Equal(dgst, pulled.Digest)
+	suite.Equal(archive, pulled.Data)
+
+	// by digest
+	chartRef, err = ParseChartReference(fmt.Sprintf("oci://%s/testrepo/archived@%s", suite.DockerRegistryHost, dgst))
+	suite.Nil(err)
+	pulled, err = suite.RegistryClient.PullChartArchive(chartRef)
+	suite.Nil(err)
+	suite.Equal(archive, pulled.Data)
+
+	// neither tag nor digest
+	chartRef, err = ParseChartReference(fmt.Sprintf("oci://%s/testrepo/archived", suite.DockerRegistryHost))
+	suite.Nil(err)
+	_, err = suite.RegistryClient.PullChartArchive(chartRef)
+	suite.NotNil(err)
+}
+
 func (suite *RegistryClientTestSuite) Test_5_PrintChartTable() {
 	err := suite.RegistryClient.PrintChartTable()
 	suite.Nil(err)
@@ -250,6 +315,19 @@ func (suite *RegistryClientTestSuite) Test_8_ManInTheMiddle() {
 	suite.True(errdefs.IsFailedPrecondition(err))
 }
 
+func (suite *RegistryClientTestSuite) Test_9_Offline() {
+	suite.RegistryClient.offline = true
+	defer func() { suite.RegistryClient.offline = false }()
+
+	ref, err := ParseReference(fmt.Sprintf("%s/testrepo/testchart:1.2.3", suite.DockerRegistryHost))
+	suite.Nil(err)
+	err = suite.RegistryClient.PullChart(ref)
+	suite.Equal(getter.ErrOffline, errors.Cause(err), "pulling fails in offline mode")
+
+	err = suite.RegistryClient.Login(suite.DockerRegistryHost, testUsername, testPassword, false)
+	suite.Equal(getter.ErrOffline, errors.Cause(err), "logging in fails in offline mode")
+}
+
 func TestRegistryClientTestSuite(t *testing.T) {
 	suite.Run(t, new(RegistryClientTestSuite))
 }
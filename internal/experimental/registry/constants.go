@@ -22,6 +22,12 @@ const (
 
 	// HelmChartContentLayerMediaType is the reserved media type for Helm chart package content
 	HelmChartContentLayerMediaType = "application/tar+gzip"
+
+	// HelmValuesConfigMediaType is the reserved media type for the manifest config of a values overlay
+	HelmValuesConfigMediaType = "application/vnd.cncf.helm.values.config.v1+json"
+
+	// HelmValuesLayerMediaType is the reserved media type for the content of a values overlay
+	HelmValuesLayerMediaType = "application/vnd.cncf.helm.values.v1+yaml"
 )
 
 // KnownMediaTypes returns a list of layer mediaTypes that the Helm client knows about
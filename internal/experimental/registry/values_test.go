@@ -0,0 +1,55 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDigest = "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+
+func TestParseValuesReference(t *testing.T) {
+	is := assert.New(t)
+
+	ref, err := ParseValuesReference("oci://localhost:5000/overlays/prod:1.2.0")
+	is.NoError(err)
+	is.Equal("localhost:5000/overlays/prod", ref.Repo)
+	is.Equal("1.2.0", ref.Tag)
+	is.Empty(ref.Digest)
+	is.Equal("localhost:5000/overlays/prod:1.2.0", ref.FullName())
+	is.Equal("oci://localhost:5000/overlays/prod:1.2.0", ref.String())
+
+	ref, err = ParseValuesReference("oci://localhost:5000/overlays/prod:1.2.0@" + testDigest)
+	is.NoError(err)
+	is.Equal("1.2.0", ref.Tag)
+	is.Equal(testDigest, ref.Digest)
+	is.Equal("localhost:5000/overlays/prod@"+testDigest, ref.FullName())
+	is.Equal("oci://localhost:5000/overlays/prod:1.2.0@"+testDigest, ref.String())
+
+	ref, err = ParseValuesReference("registry.example.com/overlays/prod@" + testDigest)
+	is.NoError(err)
+	is.Empty(ref.Tag)
+	is.Equal(testDigest, ref.Digest)
+
+	_, err = ParseValuesReference("oci://registry.example.com/overlays/prod")
+	is.Error(err, "neither tag nor digest")
+
+	_, err = ParseValuesReference("oci://registry.example.com/overlays/prod@sha256:nope")
+	is.Error(err, "invalid digest")
+}
@@ -0,0 +1,45 @@
+// Copyright The Helm Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build windows
+
+package fileutil
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// maxPath is the length from which Windows refuses paths that are not in the
+// extended-length form. It is MAX_PATH minus room for a file name of 8.3
+// characters, since directories are limited to 248 characters.
+const maxPath = 248
+
+// LongPath returns a path that can be used to access name even when it
+// exceeds the length limits of the operating system. Long paths are turned
+// into absolute paths with the \\?\ prefix, which lifts the MAX_PATH limit of
+// Windows.
+func LongPath(name string) string {
+	if len(name) < maxPath || strings.HasPrefix(name, `\\?\`) {
+		return name
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return name
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		// UNC paths take the \\?\UNC\server\share form.
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/Masterminds/semver/v3"
 	"github.com/pkg/errors"
 
+	"helm.sh/helm/v3/internal/experimental/registry"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/helmpath"
@@ -88,6 +89,22 @@ func (r *Resolver) Resolve(reqs []*chart.Dependency, repoNames map[string]string
 			}
 			continue
 		}
+		if registry.IsOCI(d.Repository) {
+			// Registries cannot be searched for the versions of a chart, so
+			// the version must be exact or the digest of the chart
+			locked[i] = &chart.Dependency{
+				Name:       d.Name,
+				Repository: d.Repository,
+			}
+			if registry.IsDigest(d.Version) {
+				locked[i].Digest = d.Version
+			} else if _, err := semver.StrictNewVersion(d.Version); err == nil {
+				locked[i].Version = d.Version
+			} else {
+				return nil, errors.Errorf("dependency %q from %s must have an exact version or a digest", d.Name, d.Repository)
+			}
+			continue
+		}
 		constraint, err := semver.NewConstraint(d.Version)
 		if err != nil {
 			return nil, errors.Wrapf(err, "dependency %q has an invalid version/constraint format", d.Name)
@@ -123,6 +123,35 @@ func TestResolve(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			name: "registry with exact version",
+			req: []*chart.Dependency{
+				{Name: "mychart", Repository: "oci://registry.example.com/charts", Version: "1.2.0"},
+			},
+			expect: &chart.Lock{
+				Dependencies: []*chart.Dependency{
+					{Name: "mychart", Repository: "oci://registry.example.com/charts", Version: "1.2.0"},
+				},
+			},
+		},
+		{
+			name: "registry with digest",
+			req: []*chart.Dependency{
+				{Name: "mychart", Repository: "oci://registry.example.com/charts", Version: "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"},
+			},
+			expect: &chart.Lock{
+				Dependencies: []*chart.Dependency{
+					{Name: "mychart", Repository: "oci://registry.example.com/charts", Digest: "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"},
+				},
+			},
+		},
+		{
+			name: "registry with version range failure",
+			req: []*chart.Dependency{
+				{Name: "mychart", Repository: "oci://registry.example.com/charts", Version: "^1.2.0"},
+			},
+			err: true,
+		},
 	}
 
 	repoNames := map[string]string{"alpine": "kubernetes-charts", "redis": "kubernetes-charts"}
@@ -162,6 +191,9 @@ func TestResolve(t *testing.T) {
 			if d0.Version != e0.Version {
 				t.Errorf("%s: expected version %s, got %s", tt.name, e0.Version, d0.Version)
 			}
+			if d0.Digest != e0.Digest {
+				t.Errorf("%s: expected digest %s, got %s", tt.name, e0.Digest, d0.Digest)
+			}
 		})
 	}
 }
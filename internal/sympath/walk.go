@@ -25,21 +25,37 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 )
 
+// Options tunes how WalkWithOptions follows symbolic links.
+type Options struct {
+	// Confine rejects the symbolic links resolving outside of the walked root.
+	Confine bool
+}
+
 // Walk walks the file tree rooted at root, calling walkFn for each file or directory
 // in the tree, including root. All errors that arise visiting files and directories
 // are filtered by walkFn. The files are walked in lexical order, which makes the
 // output deterministic but means that for very large directories Walk can be
-// inefficient. Walk follows symbolic links.
+// inefficient. Walk follows symbolic links, and fails on the links to a
+// directory being walked since they would make it loop forever.
 func Walk(root string, walkFn filepath.WalkFunc) error {
+	return WalkWithOptions(root, Options{}, walkFn)
+}
+
+// WalkWithOptions walks the file tree rooted at root as Walk does, following
+// symbolic links as set by opts.
+func WalkWithOptions(root string, opts Options, walkFn filepath.WalkFunc) error {
+	w := &walker{opts: opts, walkFn: walkFn, active: map[string]bool{}}
+	w.root = realPath(root)
 	info, err := os.Lstat(root)
 	if err != nil {
 		err = walkFn(root, nil, err)
 	} else {
-		err = symwalk(root, info, walkFn)
+		err = w.walk(root, w.root, info)
 	}
 	if err == filepath.SkipDir {
 		return nil
@@ -47,6 +63,34 @@ func Walk(root string, walkFn filepath.WalkFunc) error {
 	return err
 }
 
+// walker holds the state of a walk.
+type walker struct {
+	root   string
+	opts   Options
+	walkFn filepath.WalkFunc
+	// active holds the real paths of the directories being walked.
+	active map[string]bool
+}
+
+// realPath returns the absolute path of name with all symbolic links
+// resolved, or name itself if it cannot be resolved.
+func realPath(name string) string {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return name
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// within tells whether name is dir or is inside of it.
+func within(dir, name string) bool {
+	rel, err := filepath.Rel(dir, name)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // readDirNames reads the directory named by dirname and returns
 // a sorted list of directory entries.
 func readDirNames(dirname string) ([]string, error) {
@@ -63,25 +107,32 @@ func readDirNames(dirname string) ([]string, error) {
 	return names, nil
 }
 
-// symwalk recursively descends path, calling walkFn.
-func symwalk(path string, info os.FileInfo, walkFn filepath.WalkFunc) error {
+// walk recursively descends path, whose real path is real, calling walkFn.
+func (w *walker) walk(path, real string, info os.FileInfo) error {
 	// Recursively walk symlinked directories.
 	if IsSymlink(info) {
 		resolved, err := filepath.EvalSymlinks(path)
 		if err != nil {
 			return errors.Wrapf(err, "error evaluating symlink %s", path)
 		}
+		resolved = realPath(resolved)
+		if w.opts.Confine && !within(w.root, resolved) {
+			return errors.Errorf("symbolic link %s resolves to %s, outside of %s", path, resolved, w.root)
+		}
+		if w.active[resolved] {
+			return errors.Errorf("symbolic link %s resolves to %s, which contains it", path, resolved)
+		}
 		log.Printf("found symbolic link in path: %s resolves to %s", path, resolved)
 		if info, err = os.Lstat(resolved); err != nil {
 			return err
 		}
-		if err := symwalk(path, info, walkFn); err != nil && err != filepath.SkipDir {
+		if err := w.walk(path, resolved, info); err != nil && err != filepath.SkipDir {
 			return err
 		}
 		return nil
 	}
 
-	if err := walkFn(path, info, nil); err != nil {
+	if err := w.walkFn(path, info, nil); err != nil {
 		return err
 	}
 
@@ -91,18 +142,20 @@ func symwalk(path string, info os.FileInfo, walkFn filepath.WalkFunc) error {
 
 	names, err := readDirNames(path)
 	if err != nil {
-		return walkFn(path, info, err)
+		return w.walkFn(path, info, err)
 	}
 
+	w.active[real] = true
+	defer delete(w.active, real)
 	for _, name := range names {
 		filename := filepath.Join(path, name)
 		fileInfo, err := os.Lstat(filename)
 		if err != nil {
-			if err := walkFn(filename, fileInfo, err); err != nil && err != filepath.SkipDir {
+			if err := w.walkFn(filename, fileInfo, err); err != nil && err != filepath.SkipDir {
 				return err
 			}
 		} else {
-			err = symwalk(filename, fileInfo, walkFn)
+			err = w.walk(filename, filepath.Join(real, name), fileInfo)
 			if err != nil {
 				if (!fileInfo.IsDir() && !IsSymlink(fileInfo)) || err != filepath.SkipDir {
 					return err
@@ -21,8 +21,10 @@ limitations under the License.
 package sympath
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -149,3 +151,57 @@ func TestWalk(t *testing.T) {
 		t.Errorf("removeTree: %v", err)
 	}
 }
+
+func TestWalkSymlinkCycle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sympath-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "templates", "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("..", filepath.Join(dir, "templates", "nested", "loop")); err != nil {
+		t.Fatal(err)
+	}
+
+	err = Walk(dir, func(path string, info os.FileInfo, err error) error { return err })
+	if err == nil || !strings.Contains(err.Error(), "which contains it") {
+		t.Errorf("expected a symbolic link cycle to be rejected, got %v", err)
+	}
+}
+
+func TestWalkConfine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sympath-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "chart")
+	for _, d := range []string{filepath.Join(root, "shared"), filepath.Join(dir, "outside")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("shared", filepath.Join(root, "templates")); err != nil {
+		t.Fatal(err)
+	}
+
+	noop := func(path string, info os.FileInfo, err error) error { return err }
+	if err := WalkWithOptions(root, Options{Confine: true}, noop); err != nil {
+		t.Errorf("expected a symbolic link inside of the root to be followed, got %v", err)
+	}
+
+	if err := os.Symlink(filepath.Join("..", "outside"), filepath.Join(root, "external")); err != nil {
+		t.Fatal(err)
+	}
+	if err := Walk(root, noop); err != nil {
+		t.Errorf("expected Walk to follow a symbolic link outside of the root, got %v", err)
+	}
+	err = WalkWithOptions(root, Options{Confine: true}, noop)
+	if err == nil || !strings.Contains(err.Error(), "outside of") {
+		t.Errorf("expected a symbolic link outside of the root to be rejected, got %v", err)
+	}
+}
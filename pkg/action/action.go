@@ -23,10 +23,12 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
@@ -94,6 +96,27 @@ type Configuration struct {
 	// Capabilities describes the capabilities of the Kubernetes cluster.
 	Capabilities *chartutil.Capabilities
 
+	// Clock tells the time of release and hook timestamps and of hook
+	// deadlines. If it is nil, Timestamper is used. Waiting for resources is
+	// done by the KubeClient, which has a clock of its own.
+	Clock clock.PassiveClock
+
+	// NameGenerator generates the names of the releases installed without
+	// one. If it is nil, the Unix time is appended to the name of the chart.
+	NameGenerator NameGenerator
+
+	// NameValidator enforces naming conventions on the names of new
+	// releases, if it is set.
+	NameValidator NameValidator
+
+	// Warnings receives the non-fatal issues met by actions, such as
+	// deprecated charts. If it is nil, warnings are logged.
+	Warnings WarningHandler
+
+	// Notifier receives the events of releases, such as installs that
+	// succeeded or failed, if it is set.
+	Notifier Notifier
+
 	Log func(string, ...interface{})
 }
 
@@ -102,18 +125,18 @@ type Configuration struct {
 // TODO: This function is badly in need of a refactor.
 // TODO: As part of the refactor the duplicate code in cmd/helm/template.go should be removed
 //       This code has to do with writing files to disk.
-func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values, releaseName, outputDir string, subNotes, useReleaseName, includeCrds bool, pr postrender.PostRenderer, dryRun bool) ([]*release.Hook, *bytes.Buffer, string, error) {
+func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values, releaseName, outputDir string, subNotes, useReleaseName, includeCrds bool, pr postrender.PostRenderer, dryRun bool) ([]*release.Hook, *bytes.Buffer, string, *release.StructuredNotes, error) {
 	hs := []*release.Hook{}
 	b := bytes.NewBuffer(nil)
 
 	caps, err := c.getCapabilities()
 	if err != nil {
-		return hs, b, "", err
+		return hs, b, "", nil, err
 	}
 
 	if ch.Metadata.KubeVersion != "" {
 		if !chartutil.IsCompatibleRange(ch.Metadata.KubeVersion, caps.KubeVersion.String()) {
-			return hs, b, "", errors.Errorf("chart requires kubeVersion: %s which is incompatible with Kubernetes %s", ch.Metadata.KubeVersion, caps.KubeVersion.String())
+			return hs, b, "", nil, errors.Errorf("chart requires kubeVersion: %s which is incompatible with Kubernetes %s", ch.Metadata.KubeVersion, caps.KubeVersion.String())
 		}
 	}
 
@@ -128,7 +151,7 @@ func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values
 	if !dryRun && c.RESTClientGetter != nil {
 		rest, err := c.RESTClientGetter.ToRESTConfig()
 		if err != nil {
-			return hs, b, "", err
+			return hs, b, "", nil, err
 		}
 		files, err2 = engine.RenderWithClient(ch, values, rest)
 	} else {
@@ -136,7 +159,7 @@ func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values
 	}
 
 	if err2 != nil {
-		return hs, b, "", err2
+		return hs, b, "", nil, err2
 	}
 
 	// NOTES.txt gets rendered like all the other files, but because it's not a hook nor a resource,
@@ -159,6 +182,32 @@ func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values
 	}
 	notes := notesBuffer.String()
 
+	// NOTES.yaml is pulled out the same way, and the notes of all the charts
+	// rendering them are merged.
+	var structuredNotesFiles []string
+	for k := range files {
+		if path.Base(k) == structuredNotesFileName && path.Base(path.Dir(k)) == "templates" {
+			structuredNotesFiles = append(structuredNotesFiles, k)
+		}
+	}
+	sort.Strings(structuredNotesFiles)
+	var structuredNotes *release.StructuredNotes
+	for _, k := range structuredNotesFiles {
+		isParent := k == path.Join(ch.Name(), "templates", structuredNotesFileName)
+		if (subNotes || isParent) && strings.TrimSpace(files[k]) != "" {
+			n, err := release.ParseStructuredNotes([]byte(files[k]))
+			if err != nil {
+				return hs, b, notes, nil, errors.Wrapf(err, "error parsing %s", k)
+			}
+			if structuredNotes == nil {
+				structuredNotes = n
+			} else {
+				structuredNotes.Merge(n)
+			}
+		}
+		delete(files, k)
+	}
+
 	// Sort hooks, manifests, and partials. Only hooks and manifests are returned,
 	// as partials are not used after renderer.Render. Empty manifests are also
 	// removed here.
@@ -175,7 +224,19 @@ func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values
 			}
 			fmt.Fprintf(b, "---\n# Source: %s\n%s\n", name, content)
 		}
-		return hs, b, "", err
+		return hs, b, "", nil, err
+	}
+	for _, h := range hs {
+		if h.SuccessCondition == "" {
+			continue
+		}
+		if _, err := hookSuccessCondition(h); err != nil {
+			return hs, b, "", nil, err
+		}
+	}
+
+	if err := injectConfigChecksums(manifests); err != nil {
+		return hs, b, "", nil, err
 	}
 
 	// Aggregate all valid manifests into one big doc.
@@ -188,7 +249,7 @@ func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values
 			} else {
 				err = writeToFile(outputDir, crd.Filename, string(crd.File.Data[:]), fileWritten[crd.Name])
 				if err != nil {
-					return hs, b, "", err
+					return hs, b, "", nil, err
 				}
 				fileWritten[crd.Name] = true
 			}
@@ -209,7 +270,7 @@ func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values
 			// used by install or upgrade
 			err = writeToFile(newDir, m.Name, m.Content, fileWritten[m.Name])
 			if err != nil {
-				return hs, b, "", err
+				return hs, b, "", nil, err
 			}
 			fileWritten[m.Name] = true
 		}
@@ -218,11 +279,11 @@ func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values
 	if pr != nil {
 		b, err = pr.Run(b)
 		if err != nil {
-			return hs, b, notes, errors.Wrap(err, "error while running post render on files")
+			return hs, b, notes, structuredNotes, errors.Wrap(err, "error while running post render on files")
 		}
 	}
 
-	return hs, b, notes, nil
+	return hs, b, notes, structuredNotes, nil
 }
 
 // RESTClientGetter gets the rest client
@@ -258,8 +319,8 @@ func (c *Configuration) getCapabilities() (*chartutil.Capabilities, error) {
 	apiVersions, err := GetVersionSet(dc)
 	if err != nil {
 		if discovery.IsGroupDiscoveryFailedError(err) {
-			c.Log("WARNING: The Kubernetes server has an orphaned API service. Server reports: %s", err)
-			c.Log("WARNING: To fix this, kubectl delete apiservice <service-name>")
+			c.warn(WarningClient, "The Kubernetes server has an orphaned API service. Server reports: %s", err)
+			c.warn(WarningClient, "To fix this, kubectl delete apiservice <service-name>")
 		} else {
 			return nil, errors.Wrap(err, "could not get apiVersions from Kubernetes")
 		}
@@ -272,6 +333,7 @@ func (c *Configuration) getCapabilities() (*chartutil.Capabilities, error) {
 			Major:   kubeVersion.Major,
 			Minor:   kubeVersion.Minor,
 		},
+		CRDs: chartutil.NewCRDs(c.listCRDs),
 	}
 	return c.Capabilities, nil
 }
@@ -288,9 +350,12 @@ func (c *Configuration) KubernetesClientSet() (kubernetes.Interface, error) {
 
 // Now generates a timestamp
 //
-// If the configuration has a Timestamper on it, that will be used.
-// Otherwise, this will use time.Now().
+// If the configuration has a Clock on it, that will be used.
+// Otherwise, this will use Timestamper().
 func (c *Configuration) Now() time.Time {
+	if c.Clock != nil {
+		return time.Time{Time: c.Clock.Now()}
+	}
 	return Timestamper()
 }
 
@@ -354,6 +419,15 @@ func GetVersionSet(client discovery.ServerResourcesInterface) (chartutil.Version
 	return chartutil.VersionSet(versions), nil
 }
 
+// startOperation starts caching the GETs of the Kubernetes client, if it
+// supports it, until the returned function is called.
+func (c *Configuration) startOperation() func() {
+	if oc, ok := c.KubeClient.(kube.InterfaceOperations); ok {
+		return oc.StartOperation()
+	}
+	return func() {}
+}
+
 // recordRelease with an update operation in case reuse has been set.
 func (c *Configuration) recordRelease(r *release.Release) {
 	if err := c.Releases.Update(r); err != nil {
@@ -361,6 +435,16 @@ func (c *Configuration) recordRelease(r *release.Release) {
 	}
 }
 
+// updateResources updates the resources of a release as KubeClient.Update
+// does. If the client supports it, forced updates recreate the resources that
+// cannot be replaced, waiting up to deletionTimeout for their deletion.
+func (c *Configuration) updateResources(current, target kube.ResourceList, force bool, deletionTimeout time.Duration) (*kube.Result, error) {
+	if client, ok := c.KubeClient.(kube.InterfaceRecreate); ok {
+		return client.UpdateRecreate(current, target, force, deletionTimeout)
+	}
+	return c.KubeClient.Update(current, target, force)
+}
+
 // Init initializes the action configuration
 func (c *Configuration) Init(getter genericclioptions.RESTClientGetter, namespace, helmDriver string, log DebugLog) error {
 	kc := kube.New(getter)
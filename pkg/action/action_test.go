@@ -208,6 +208,15 @@ func withNotes(notes string) chartOption {
 	}
 }
 
+func withStructuredNotes(notes string) chartOption {
+	return func(opts *chartOptions) {
+		opts.Templates = append(opts.Templates, &chart.File{
+			Name: "templates/NOTES.yaml",
+			Data: []byte(notes),
+		})
+	}
+}
+
 func withDependency(dependencyOpts ...chartOption) chartOption {
 	return func(opts *chartOptions) {
 		opts.AddDependency(buildChart(dependencyOpts...))
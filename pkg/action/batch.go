@@ -0,0 +1,167 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// BatchOperation is an operation applied by Batch to a single release, given
+// its name.
+type BatchOperation func(name string) error
+
+// BatchResult is the outcome of a BatchOperation on a release.
+type BatchResult struct {
+	Name      string
+	Namespace string
+	// Error is the error returned by the operation, nil if it succeeded.
+	Error error
+}
+
+// BatchReport is the aggregated outcome of a Batch run, with a result per
+// release, in the order in which the releases were listed.
+type BatchReport struct {
+	Results []BatchResult
+}
+
+// Failed returns the results of the releases the operation failed on.
+func (r *BatchReport) Failed() []BatchResult {
+	var failed []BatchResult
+	for _, res := range r.Results {
+		if res.Error != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Err returns an error aggregating the errors of the failed operations, or
+// nil if the operation succeeded on every release.
+func (r *BatchReport) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	errs := make([]error, 0, len(failed))
+	for _, res := range failed {
+		errs = append(errs, errors.Wrapf(res.Error, "release %q", res.Name))
+	}
+	return errors.Errorf("%d of %d releases failed: %s", len(failed), len(r.Results), joinErrors(errs))
+}
+
+// Batch applies an operation to all the releases matching a label selector
+// and a name filter, on a bounded number of releases at a time.
+type Batch struct {
+	cfg *Configuration
+
+	// Selector is a label query the releases must match, as in 'helm list'.
+	Selector string
+	// Filter is a regular expression the release names must match.
+	Filter string
+	// StateMask limits the releases to the ones in the given states.
+	StateMask ListStates
+	// Workers is the maximum number of releases the operation is applied to
+	// concurrently. Values below one are treated as one.
+	Workers int
+}
+
+// NewBatch creates a new Batch object with the given configuration.
+func NewBatch(cfg *Configuration) *Batch {
+	return &Batch{
+		cfg:       cfg,
+		StateMask: ListDeployed | ListFailed,
+		Workers:   1,
+	}
+}
+
+// Releases returns the latest revisions of the releases matching the selector
+// and the filter, sorted by name.
+func (b *Batch) Releases() ([]*release.Release, error) {
+	if b.Selector == "" && b.Filter == "" {
+		return nil, errors.New("a selector or a filter is required to select the releases of a batch")
+	}
+	l := NewList(b.cfg)
+	l.Selector = b.Selector
+	l.Filter = b.Filter
+	l.StateMask = b.StateMask
+	return l.Run()
+}
+
+// Run applies the operation to every matching release. The returned error is
+// only set if the releases cannot be listed; the failures of the operation
+// are reported per release.
+func (b *Batch) Run(op BatchOperation) (*BatchReport, error) {
+	rels, err := b.Releases()
+	if err != nil {
+		return nil, err
+	}
+
+	workers := b.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	report := &BatchReport{Results: make([]BatchResult, len(rels))}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, rel := range rels {
+		report.Results[i] = BatchResult{Name: rel.Name, Namespace: rel.Namespace}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(res *BatchResult) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			b.cfg.Log("batch: running the operation on %s", res.Name)
+			res.Error = op(res.Name)
+		}(&report.Results[i])
+	}
+	wg.Wait()
+
+	return report, nil
+}
+
+// BatchUninstall returns a BatchOperation uninstalling the releases with u.
+func BatchUninstall(u *Uninstall) BatchOperation {
+	return func(name string) error {
+		_, err := u.Run(name)
+		return err
+	}
+}
+
+// BatchRollback returns a BatchOperation rolling the releases back with the
+// settings of r. Each release is rolled back with a copy of r, as Run sets
+// its results.
+func BatchRollback(r *Rollback) BatchOperation {
+	return func(name string) error {
+		rb := *r
+		return rb.Run(name)
+	}
+}
+
+// BatchTest returns a BatchOperation running the tests of the releases with t.
+func BatchTest(t *ReleaseTesting) BatchOperation {
+	return func(name string) error {
+		_, err := t.Run(name)
+		return err
+	}
+}
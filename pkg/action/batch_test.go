@@ -0,0 +1,113 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func batchFixture(t *testing.T) *Configuration {
+	t.Helper()
+	config := actionConfigFixture(t)
+	for _, r := range []struct {
+		name string
+		team string
+	}{
+		{"checkout", "payments"},
+		{"invoices", "payments"},
+		{"ledger", "payments"},
+		{"search", "discovery"},
+	} {
+		rel := namedReleaseStub(r.name, release.StatusDeployed)
+		rel.Labels = map[string]string{"team": r.team}
+		if err := config.Releases.Create(rel); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return config
+}
+
+func TestBatchReleases(t *testing.T) {
+	is := assert.New(t)
+	b := NewBatch(batchFixture(t))
+
+	_, err := b.Releases()
+	is.Error(err, "a batch without a selector or a filter must not select every release")
+
+	b.Selector = "team=payments"
+	rels, err := b.Releases()
+	is.NoError(err)
+	is.Len(rels, 3)
+
+	b.Filter = "^(checkout|search)$"
+	rels, err = b.Releases()
+	is.NoError(err)
+	is.Len(rels, 1)
+	is.Equal("checkout", rels[0].Name)
+}
+
+func TestBatchRun(t *testing.T) {
+	is := assert.New(t)
+	b := NewBatch(batchFixture(t))
+	b.Selector = "team=payments"
+	b.Workers = 2
+
+	var mu sync.Mutex
+	var seen []string
+	report, err := b.Run(func(name string) error {
+		mu.Lock()
+		seen = append(seen, name)
+		mu.Unlock()
+		if name == "invoices" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	is.NoError(err)
+
+	sort.Strings(seen)
+	is.Equal([]string{"checkout", "invoices", "ledger"}, seen)
+	is.Len(report.Results, 3)
+	failed := report.Failed()
+	is.Len(failed, 1)
+	is.Equal("invoices", failed[0].Name)
+	is.EqualError(report.Err(), `1 of 3 releases failed: release "invoices": boom`)
+}
+
+func TestBatchUninstall(t *testing.T) {
+	is := assert.New(t)
+	config := batchFixture(t)
+	b := NewBatch(config)
+	b.Selector = "team=payments"
+	b.Workers = 3
+
+	report, err := b.Run(BatchUninstall(NewUninstall(config)))
+	is.NoError(err)
+	is.NoError(report.Err())
+
+	rels, err := config.Releases.ListReleases()
+	is.NoError(err)
+	is.Len(rels, 1)
+	is.Equal("search", rels[0].Name)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// CapabilitiesExport is the action for taking a snapshot of the capabilities
+// of the cluster, to render charts elsewhere as they would be in it.
+//
+// It provides the implementation of 'helm capabilities export'.
+type CapabilitiesExport struct {
+	cfg *Configuration
+}
+
+// NewCapabilitiesExport creates a new CapabilitiesExport object with the given
+// configuration.
+func NewCapabilitiesExport(cfg *Configuration) *CapabilitiesExport {
+	return &CapabilitiesExport{cfg: cfg}
+}
+
+// Run takes the snapshot. The Kubernetes version and API resources are read
+// from the discovery API, and the CustomResourceDefinitions are listed along
+// with their schemas.
+func (c *CapabilitiesExport) Run() (*chartutil.CapabilitiesSnapshot, error) {
+	if err := c.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	caps, err := c.cfg.getCapabilities()
+	if err != nil {
+		return nil, err
+	}
+	snapshot := chartutil.NewCapabilitiesSnapshot(caps)
+	snapshot.HelmVersion = chartutil.DefaultCapabilities.HelmVersion.Version
+	return snapshot, nil
+}
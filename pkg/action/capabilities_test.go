@@ -0,0 +1,72 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestCapabilitiesExport(t *testing.T) {
+	is := assert.New(t)
+
+	snapshot, err := NewCapabilitiesExport(actionConfigFixture(t)).Run()
+	is.NoError(err)
+	is.Equal("v1.18.0", snapshot.KubeVersion)
+	is.Equal("18", snapshot.KubeMinor)
+	is.Contains(snapshot.APIVersions, "v1")
+	is.Equal(chartutil.DefaultCapabilities.HelmVersion.Version, snapshot.HelmVersion)
+}
+
+func withCapabilitiesTemplate() chartOption {
+	return func(opts *chartOptions) {
+		opts.Templates = append(opts.Templates, &chart.File{
+			Name: "templates/capabilities",
+			Data: []byte(`capabilities: "{{ .Capabilities.KubeVersion }} {{ .Capabilities.APIVersions.Has "example.com/v1/Widget" }} {{ .Capabilities.CRDs.Has "widgets.example.com" }}"`),
+		})
+	}
+}
+
+func TestInstallCapabilitiesSnapshot(t *testing.T) {
+	is := assert.New(t)
+	snapshot := &chartutil.CapabilitiesSnapshot{
+		KubeVersion: "v1.20.4",
+		KubeMajor:   "1",
+		KubeMinor:   "20",
+		APIVersions: chartutil.VersionSet{"v1", "example.com/v1", "example.com/v1/Widget"},
+		CRDs:        []chartutil.CRD{{Name: "widgets.example.com", Group: "example.com", Kind: "Widget"}},
+	}
+
+	for _, clientOnly := range []bool{true, false} {
+		instAction := installAction(t)
+		instAction.ClientOnly = clientOnly
+		instAction.DryRun = true
+		instAction.CapabilitiesSnapshot = snapshot
+		res, err := instAction.Run(buildChart(withCapabilitiesTemplate()), map[string]interface{}{})
+		is.NoError(err)
+		is.Contains(res.Manifest, `capabilities: "v1.20.4 true true"`)
+	}
+
+	instAction := installAction(t)
+	instAction.CapabilitiesSnapshot = snapshot
+	_, err := instAction.Run(buildChart(withCapabilitiesTemplate()), map[string]interface{}{})
+	is.EqualError(err, "a capabilities snapshot can only be used with a dry run")
+}
@@ -0,0 +1,146 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// Fields of the compatibility matrix checked by ChartIncompatibilities.
+const (
+	CompatibilityAppVersion  = "appVersion"
+	CompatibilityKubeVersion = "kubeVersion"
+	CompatibilityUpgradeFrom = "upgradeFrom"
+)
+
+// ChartIncompatibility describes a version that the compatibility matrix of a
+// chart or subchart does not support.
+type ChartIncompatibility struct {
+	Chart   string `json:"chart"`
+	Version string `json:"version"`
+	// Field is the field of the matrix that is not satisfied, one of
+	// appVersion, kubeVersion or upgradeFrom
+	Field string `json:"field"`
+	// Value is the unsupported version
+	Value string `json:"value"`
+	// Supported are the constraints of the field in the rows of the matrix
+	// applying to the chart
+	Supported []string `json:"supported"`
+}
+
+func (c ChartIncompatibility) String() string {
+	var what string
+	switch c.Field {
+	case CompatibilityAppVersion:
+		what = "app version " + c.Value
+	case CompatibilityKubeVersion:
+		what = "Kubernetes " + c.Value
+	case CompatibilityUpgradeFrom:
+		what = "upgrades from version " + c.Value
+	default:
+		what = fmt.Sprintf("%s %s", c.Field, c.Value)
+	}
+	return fmt.Sprintf("chart %s-%s does not support %s (supported: %s)", c.Chart, c.Version, what, strings.Join(c.Supported, ", "))
+}
+
+// ChartIncompatibilities checks a chart and its subcharts against their
+// compatibility matrices.
+//
+// The rows of a matrix applying to a chart are those whose version range
+// contains the chart version. The app version of the chart, the Kubernetes
+// version and, for the top level chart only, the version of the chart being
+// upgraded from must then be supported by one of these rows, each check
+// narrowing the rows left for the next. Empty versions are not checked, and
+// charts without applicable rows have no incompatibilities.
+func ChartIncompatibilities(ch *chart.Chart, kubeVersion, upgradeFrom string) []ChartIncompatibility {
+	var incompatibilities []ChartIncompatibility
+	var walk func(*chart.Chart, string)
+	walk = func(c *chart.Chart, from string) {
+		md := c.Metadata
+		rows, _ := compatibleRows(md.Compatibility, func(r *chart.Compatibility) string { return r.Version }, md.Version)
+		if len(rows) > 0 {
+			for _, check := range []struct {
+				field, value string
+				constraint   func(*chart.Compatibility) string
+			}{
+				{CompatibilityAppVersion, md.AppVersion, func(r *chart.Compatibility) string { return r.AppVersion }},
+				{CompatibilityKubeVersion, kubeVersion, func(r *chart.Compatibility) string { return r.KubeVersion }},
+				{CompatibilityUpgradeFrom, from, func(r *chart.Compatibility) string { return r.UpgradeFrom }},
+			} {
+				if check.value == "" {
+					continue
+				}
+				matched, supported := compatibleRows(rows, check.constraint, check.value)
+				if len(supported) == 0 {
+					continue
+				}
+				if len(matched) == 0 {
+					incompatibilities = append(incompatibilities, ChartIncompatibility{
+						Chart:     md.Name,
+						Version:   md.Version,
+						Field:     check.field,
+						Value:     check.value,
+						Supported: supported,
+					})
+					continue
+				}
+				rows = matched
+			}
+		}
+		for _, dep := range c.Dependencies() {
+			walk(dep, "")
+		}
+	}
+	walk(ch, upgradeFrom)
+	return incompatibilities
+}
+
+// compatibleRows returns the rows whose constraint is empty or satisfied by
+// version, and the distinct non-empty constraints of all rows.
+func compatibleRows(rows []*chart.Compatibility, constraint func(*chart.Compatibility) string, version string) ([]*chart.Compatibility, []string) {
+	var matched []*chart.Compatibility
+	var supported []string
+	seen := map[string]bool{}
+	for _, r := range rows {
+		if r == nil {
+			continue
+		}
+		c := constraint(r)
+		if c != "" && !seen[c] {
+			seen[c] = true
+			supported = append(supported, c)
+		}
+		if c == "" || chartutil.IsCompatibleRange(c, version) {
+			matched = append(matched, r)
+		}
+	}
+	return matched, supported
+}
+
+// checkCompatibility returns an error for the first incompatibility.
+func checkCompatibility(incompatibilities []ChartIncompatibility) error {
+	if len(incompatibilities) > 0 {
+		return errors.Errorf("refusing to deploy incompatible charts: %s", incompatibilities[0])
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func withCompatibility(version, appVersion string, rows ...*chart.Compatibility) chartOption {
+	return func(opts *chartOptions) {
+		opts.Metadata.Version = version
+		opts.Metadata.AppVersion = appVersion
+		opts.Metadata.Compatibility = rows
+	}
+}
+
+func TestChartIncompatibilities(t *testing.T) {
+	is := assert.New(t)
+
+	rows := []*chart.Compatibility{
+		{Version: "1.x", AppVersion: "2.x", KubeVersion: ">=1.16.0-0", UpgradeFrom: ">=0.9.0"},
+		{Version: "2.x", AppVersion: "3.x", KubeVersion: ">=1.19.0-0", UpgradeFrom: "^1.4.0"},
+		{Version: "2.x", AppVersion: "2.x", KubeVersion: ">=1.16.0-0", UpgradeFrom: "^1.4.0"},
+	}
+
+	// No matrix, or no row for the chart version
+	is.Empty(ChartIncompatibilities(buildChart(), "v1.18.0", "0.1.0"))
+	is.Empty(ChartIncompatibilities(buildChart(withCompatibility("3.0.0", "4.0.0", rows...)), "v1.10.0", "0.1.0"))
+
+	is.Empty(ChartIncompatibilities(buildChart(withCompatibility("2.1.0", "3.2.0", rows...)), "v1.20.0", "1.4.2"))
+	is.Empty(ChartIncompatibilities(buildChart(withCompatibility("2.1.0", "2.8.0", rows...)), "v1.18.0", ""))
+
+	// App version 3 requires a more recent Kubernetes than app version 2
+	incompatibilities := ChartIncompatibilities(buildChart(withCompatibility("2.1.0", "3.2.0", rows...)), "v1.18.0", "1.2.0")
+	is.Equal([]ChartIncompatibility{
+		{Chart: "hello", Version: "2.1.0", Field: CompatibilityKubeVersion, Value: "v1.18.0", Supported: []string{">=1.19.0-0"}},
+		{Chart: "hello", Version: "2.1.0", Field: CompatibilityUpgradeFrom, Value: "1.2.0", Supported: []string{"^1.4.0"}},
+	}, incompatibilities)
+	is.Equal("chart hello-2.1.0 does not support Kubernetes v1.18.0 (supported: >=1.19.0-0)", incompatibilities[0].String())
+	is.Equal("chart hello-2.1.0 does not support upgrades from version 1.2.0 (supported: ^1.4.0)", incompatibilities[1].String())
+
+	// Subcharts are checked against their own matrix, except for upgrades
+	ch := buildChart(
+		withCompatibility("1.0.0", "1.0.0", rows[0]),
+		withDependency(withName("child"), withCompatibility("2.0.0", "3.0.0", rows[1:]...)),
+	)
+	incompatibilities = ChartIncompatibilities(ch, "v1.18.0", "0.1.0")
+	is.Equal([]ChartIncompatibility{
+		{Chart: "hello", Version: "1.0.0", Field: CompatibilityAppVersion, Value: "1.0.0", Supported: []string{"2.x"}},
+		{Chart: "hello", Version: "1.0.0", Field: CompatibilityUpgradeFrom, Value: "0.1.0", Supported: []string{">=0.9.0"}},
+		{Chart: "child", Version: "2.0.0", Field: CompatibilityKubeVersion, Value: "v1.18.0", Supported: []string{">=1.19.0-0"}},
+	}, incompatibilities)
+}
+
+func TestInstallReleaseStrictCompatibility(t *testing.T) {
+	is := assert.New(t)
+	ch := buildChart(withCompatibility("1.0.0", "2.0.0", &chart.Compatibility{KubeVersion: ">=1.19.0-0"}))
+
+	instAction := installAction(t)
+	_, err := instAction.Run(ch, map[string]interface{}{})
+	is.NoError(err)
+	is.Len(instAction.Incompatibilities, 1)
+
+	instAction = installAction(t)
+	instAction.StrictCompatibility = true
+	_, err = instAction.Run(ch, map[string]interface{}{})
+	is.Error(err)
+	is.Contains(err.Error(), "refusing to deploy incompatible charts: chart hello-1.0.0 does not support Kubernetes v1.18.0")
+}
+
+func TestUpgradeReleaseStrictCompatibility(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Info.Status = release.StatusDeployed
+	upAction.cfg.Releases.Create(rel)
+
+	ch := buildChart(withCompatibility("1.0.0", "2.0.0", &chart.Compatibility{UpgradeFrom: ">=0.5.0"}))
+	upAction.StrictCompatibility = true
+	_, err := upAction.Run(rel.Name, ch, map[string]interface{}{})
+	req.Error(err)
+	is.Contains(err.Error(), "does not support upgrades from version 0.1.0")
+	is.Len(upAction.Incompatibilities, 1)
+
+	upAction.StrictCompatibility = false
+	_, err = upAction.Run(rel.Name, ch, map[string]interface{}{})
+	req.NoError(err)
+	is.Len(upAction.Incompatibilities, 1)
+}
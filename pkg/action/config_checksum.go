@@ -0,0 +1,212 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// configChecksumAnnotation is the conventional pod template annotation
+// holding a checksum of the configuration of the pods, so that the pods are
+// restarted when it changes.
+const configChecksumAnnotation = "checksum/config"
+
+// configChecksumAuto is the value of the checksum/config annotation asking
+// Helm to compute it. It can be suffixed with ':' and the name of a hash
+// function registered with engine.RegisterChecksumHash, as in "auto:sha512".
+const configChecksumAuto = "auto"
+
+// configChecksumPattern matches the lines of a manifest setting the
+// checksum/config annotation to be computed.
+var configChecksumPattern = regexp.MustCompile(`(?m)^(\s*["']?checksum/config["']?\s*:\s*)["']?auto(?::[\w-]+)?["']?[ \t]*$`)
+
+// injectConfigChecksums computes the checksum/config annotations of the pod
+// templates of the manifests set to "auto". The checksum covers the
+// ConfigMaps and Secrets of the release that the pods reference through
+// their volumes, environment variables and envFrom sources, so the pods are
+// restarted when any of them changes. ConfigMaps and Secrets that are not
+// part of the release do not contribute to the checksum.
+//
+// The annotation is replaced in the text of the manifest so that the rest of
+// it is kept as rendered.
+func injectConfigChecksums(manifests []releaseutil.Manifest) error {
+	configs := make(map[string]string)
+	for _, m := range manifests {
+		if m.Head == nil || m.Head.Metadata == nil {
+			continue
+		}
+		if m.Head.Kind == "ConfigMap" || m.Head.Kind == "Secret" {
+			configs[m.Head.Kind+"/"+m.Head.Metadata.Name] = m.Content
+		}
+	}
+
+	for i, m := range manifests {
+		if !strings.Contains(m.Content, configChecksumAnnotation) {
+			continue
+		}
+		tmpl, spec, err := podTemplateOf(m.Content)
+		if err != nil {
+			return errors.Wrapf(err, "cannot compute the %s annotation of %s", configChecksumAnnotation, m.Name)
+		}
+		if spec == nil {
+			continue
+		}
+		value := tmpl.Annotations[configChecksumAnnotation]
+		if value != configChecksumAuto && !strings.HasPrefix(value, configChecksumAuto+":") {
+			continue
+		}
+		algorithm := engine.DefaultChecksumHash
+		if value != configChecksumAuto {
+			algorithm = strings.TrimPrefix(value, configChecksumAuto+":")
+		}
+
+		refs := configReferences(spec)
+		parts := make([]string, 0, len(refs))
+		for _, ref := range refs {
+			if content, ok := configs[ref]; ok {
+				parts = append(parts, ref+"\n"+content+"\n")
+			}
+		}
+		sum, err := engine.Checksum(algorithm, parts...)
+		if err != nil {
+			return errors.Wrapf(err, "cannot compute the %s annotation of %s", configChecksumAnnotation, m.Name)
+		}
+		manifests[i].Content = configChecksumPattern.ReplaceAllString(m.Content, `${1}"`+sum+`"`)
+	}
+	return nil
+}
+
+// podTemplateOf returns the template and the spec of the pods of a manifest:
+// those of a Pod, of the pod template of a workload or of the job template of
+// a CronJob. The spec is nil if the manifest describes no pods.
+func podTemplateOf(content string) (*v1.PodTemplateSpec, *v1.PodSpec, error) {
+	var head struct {
+		Kind string `json:"kind"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &head); err != nil {
+		return nil, nil, err
+	}
+
+	switch head.Kind {
+	case "Pod":
+		var pod v1.Pod
+		if err := yaml.Unmarshal([]byte(content), &pod); err != nil {
+			return nil, nil, err
+		}
+		return &v1.PodTemplateSpec{ObjectMeta: pod.ObjectMeta}, &pod.Spec, nil
+	case "CronJob":
+		var cronJob struct {
+			Spec struct {
+				JobTemplate struct {
+					Spec struct {
+						Template *v1.PodTemplateSpec `json:"template"`
+					} `json:"spec"`
+				} `json:"jobTemplate"`
+			} `json:"spec"`
+		}
+		if err := yaml.Unmarshal([]byte(content), &cronJob); err != nil {
+			return nil, nil, err
+		}
+		return podTemplateSpec(cronJob.Spec.JobTemplate.Spec.Template)
+	default:
+		var workload struct {
+			Spec struct {
+				Template *v1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		}
+		if err := yaml.Unmarshal([]byte(content), &workload); err != nil {
+			return nil, nil, err
+		}
+		return podTemplateSpec(workload.Spec.Template)
+	}
+}
+
+func podTemplateSpec(t *v1.PodTemplateSpec) (*v1.PodTemplateSpec, *v1.PodSpec, error) {
+	if t == nil {
+		return nil, nil, nil
+	}
+	return t, &t.Spec, nil
+}
+
+// configReferences returns the ConfigMaps and Secrets referenced by a pod
+// spec, as sorted "Kind/name" keys.
+func configReferences(spec *v1.PodSpec) []string {
+	refs := make(map[string]bool)
+	add := func(kind, name string) {
+		if name != "" {
+			refs[kind+"/"+name] = true
+		}
+	}
+
+	for _, vol := range spec.Volumes {
+		if vol.ConfigMap != nil {
+			add("ConfigMap", vol.ConfigMap.Name)
+		}
+		if vol.Secret != nil {
+			add("Secret", vol.Secret.SecretName)
+		}
+		if vol.Projected != nil {
+			for _, src := range vol.Projected.Sources {
+				if src.ConfigMap != nil {
+					add("ConfigMap", src.ConfigMap.Name)
+				}
+				if src.Secret != nil {
+					add("Secret", src.Secret.Name)
+				}
+			}
+		}
+	}
+
+	containers := append(append([]v1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		for _, env := range c.EnvFrom {
+			if env.ConfigMapRef != nil {
+				add("ConfigMap", env.ConfigMapRef.Name)
+			}
+			if env.SecretRef != nil {
+				add("Secret", env.SecretRef.Name)
+			}
+		}
+		for _, env := range c.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				add("ConfigMap", env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				add("Secret", env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(refs))
+	for k := range refs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
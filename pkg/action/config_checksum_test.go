@@ -0,0 +1,150 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+const checksumConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+data:
+  level: %s
+`
+
+const checksumDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    metadata:
+      annotations:
+        checksum/config: %s
+    spec:
+      containers:
+        - name: app
+          image: app
+          env:
+            - name: TOKEN
+              valueFrom:
+                secretKeyRef:
+                  name: app-secret
+                  key: token
+      volumes:
+        - name: config
+          configMap:
+            name: app-config
+`
+
+const checksumCronJob = `apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: cleanup
+spec:
+  schedule: "@daily"
+  jobTemplate:
+    spec:
+      template:
+        metadata:
+          annotations:
+            "checksum/config": "auto:sha512"
+        spec:
+          containers:
+            - name: cleanup
+              image: cleanup
+              envFrom:
+                - secretRef:
+                    name: app-secret
+`
+
+const checksumSecret = `apiVersion: v1
+kind: Secret
+metadata:
+  name: app-secret
+stringData:
+  token: abc
+`
+
+func renderChecksums(t *testing.T, level, annotation string) map[string]string {
+	t.Helper()
+	files := map[string]string{
+		"templates/configmap.yaml":  strings.Replace(checksumConfigMap, "%s", level, 1),
+		"templates/secret.yaml":     checksumSecret,
+		"templates/deployment.yaml": strings.Replace(checksumDeployment, "%s", annotation, 1),
+		"templates/cronjob.yaml":    checksumCronJob,
+	}
+	_, manifests, err := releaseutil.SortManifests(files, chartutil.DefaultVersionSet, releaseutil.InstallOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := injectConfigChecksums(manifests); err != nil {
+		t.Fatal(err)
+	}
+
+	checksums := make(map[string]string)
+	pattern := regexp.MustCompile(`checksum/config"?: "?([^"\n]*)"?`)
+	for _, m := range manifests {
+		if match := pattern.FindStringSubmatch(m.Content); match != nil {
+			checksums[m.Head.Metadata.Name] = match[1]
+		}
+	}
+	return checksums
+}
+
+func TestInjectConfigChecksums(t *testing.T) {
+	checksums := renderChecksums(t, "info", "auto")
+	if len(checksums["app"]) != 64 {
+		t.Errorf("expected a sha256 checksum for the deployment, got %q", checksums["app"])
+	}
+	if len(checksums["cleanup"]) != 128 {
+		t.Errorf("expected a sha512 checksum for the cronjob, got %q", checksums["cleanup"])
+	}
+
+	changed := renderChecksums(t, "debug", "auto")
+	if changed["app"] == checksums["app"] {
+		t.Error("expected the checksum of the deployment to change with its ConfigMap")
+	}
+	if changed["cleanup"] != checksums["cleanup"] {
+		t.Error("expected the checksum of the cronjob not to change, as it does not use the ConfigMap")
+	}
+
+	manual := renderChecksums(t, "info", "abc123")
+	if manual["app"] != "abc123" {
+		t.Errorf("expected a checksum set by the chart to be kept, got %q", manual["app"])
+	}
+}
+
+func TestInjectConfigChecksumsUnknownHash(t *testing.T) {
+	files := map[string]string{
+		"templates/deployment.yaml": strings.Replace(checksumDeployment, "%s", "auto:crc", 1),
+	}
+	_, manifests, err := releaseutil.SortManifests(files, chartutil.DefaultVersionSet, releaseutil.InstallOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := injectConfigChecksums(manifests); err == nil {
+		t.Error("expected an error for an unknown hash function")
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// listCRDs lists the CustomResourceDefinitions installed in the cluster for
+// the capabilities. If they cannot be listed, for instance because the user
+// is not allowed to, a warning is logged and none are returned, so that
+// charts render as they would without them.
+func (c *Configuration) listCRDs() []chartutil.CRD {
+	conf, err := c.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		c.warn(WarningClient, "unable to list CustomResourceDefinitions: %s", err)
+		return nil
+	}
+	client, err := dynamic.NewForConfig(conf)
+	if err != nil {
+		c.warn(WarningClient, "unable to list CustomResourceDefinitions: %s", err)
+		return nil
+	}
+	list, err := client.Resource(crdResource).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		c.warn(WarningClient, "unable to list CustomResourceDefinitions: %s", err)
+		return nil
+	}
+	crds := make([]chartutil.CRD, 0, len(list.Items))
+	for _, item := range list.Items {
+		crds = append(crds, crdFromObject(item.Object))
+	}
+	return crds
+}
+
+// crdFromObject describes an apiextensions.k8s.io/v1 CustomResourceDefinition.
+func crdFromObject(obj map[string]interface{}) chartutil.CRD {
+	u := unstructured.Unstructured{Object: obj}
+	crd := chartutil.CRD{Name: u.GetName()}
+	crd.Group, _, _ = unstructured.NestedString(obj, "spec", "group")
+	crd.Kind, _, _ = unstructured.NestedString(obj, "spec", "names", "kind")
+	crd.Scope, _, _ = unstructured.NestedString(obj, "spec", "scope")
+
+	versions, _, _ := unstructured.NestedSlice(obj, "spec", "versions")
+	for _, v := range versions {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		version := chartutil.CRDVersion{}
+		version.Name, _, _ = unstructured.NestedString(m, "name")
+		version.Served, _, _ = unstructured.NestedBool(m, "served")
+		version.Storage, _, _ = unstructured.NestedBool(m, "storage")
+		version.Schema, _, _ = unstructured.NestedMap(m, "schema", "openAPIV3Schema")
+		crd.Versions = append(crd.Versions, version)
+	}
+	return crd
+}
@@ -0,0 +1,105 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const certificateCRD = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: certificates.cert-manager.io
+spec:
+  group: cert-manager.io
+  names:
+    kind: Certificate
+  scope: Namespaced
+  versions:
+  - name: v1alpha2
+    served: false
+    storage: false
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              secretTemplate:
+                type: object
+`
+
+func TestCRDFromObject(t *testing.T) {
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(certificateCRD), &obj); err != nil {
+		t.Fatal(err)
+	}
+	crd := crdFromObject(obj)
+	is := assert.New(t)
+	is.Equal("certificates.cert-manager.io", crd.Name)
+	is.Equal("cert-manager.io", crd.Group)
+	is.Equal("Certificate", crd.Kind)
+	is.Equal("Namespaced", crd.Scope)
+	is.Len(crd.Versions, 2)
+	is.Nil(crd.Versions[0].Schema)
+	is.Equal("object", crd.Versions[1].Schema["type"])
+	is.Equal("v1", crd.StorageVersion())
+}
+
+func TestInstallRelease_CRDCapabilities(t *testing.T) {
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(certificateCRD), &obj); err != nil {
+		t.Fatal(err)
+	}
+	instAction := installAction(t)
+	caps := *chartutil.DefaultCapabilities
+	caps.CRDs = chartutil.NewCRDs(func() []chartutil.CRD {
+		return []chartutil.CRD{crdFromObject(obj)}
+	})
+	instAction.cfg.Capabilities = &caps
+
+	tpl := `{{- with .Capabilities.CRDs.Get "certificates.cert-manager.io" }}
+apiVersion: cert-manager.io/{{ .StorageVersion }}
+kind: Certificate
+{{- if (.Version "v1").Schema.properties.spec.properties.secretTemplate }}
+secretTemplate: supported
+{{- end }}
+{{- end }}
+missing: {{ .Capabilities.CRDs.Has "issuers.cert-manager.io" }}
+`
+	ch := buildChart()
+	ch.Templates = append(ch.Templates, &chart.File{Name: "templates/certificate", Data: []byte(tpl)})
+	res, err := instAction.Run(ch, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	is := assert.New(t)
+	is.Contains(res.Manifest, "apiVersion: cert-manager.io/v1")
+	is.Contains(res.Manifest, "secretTemplate: supported")
+	is.Contains(res.Manifest, "missing: false")
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/Masterminds/semver/v3"
 	"github.com/gosuri/uitable"
 
+	"helm.sh/helm/v3/internal/experimental/registry"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 )
@@ -34,9 +35,10 @@ import (
 //
 // It provides the implementation of 'helm dependency' and its respective subcommands.
 type Dependency struct {
-	Verify      bool
-	Keyring     string
-	SkipRefresh bool
+	Verify       bool
+	Keyring      string
+	SkipRefresh  bool
+	SymlinkLocal bool
 }
 
 // NewDependency creates a new Dependency object with the given configuration.
@@ -125,7 +127,8 @@ func (d *Dependency) dependencyStatus(chartpath string, dep *chart.Dependency, p
 		return "missing"
 	}
 
-	if depChart.Metadata.Version != dep.Version {
+	// Charts pinned to a digest have no version to compare
+	if depChart.Metadata.Version != dep.Version && !registry.IsDigest(dep.Version) {
 		constraint, err := semver.NewConstraint(dep.Version)
 		if err != nil {
 			return "invalid version"
@@ -158,7 +161,7 @@ func statArchiveForStatus(archive string, dep *chart.Dependency) string {
 			return "misnamed"
 		}
 
-		if c.Metadata.Version != dep.Version {
+		if c.Metadata.Version != dep.Version && !registry.IsDigest(dep.Version) {
 			constraint, err := semver.NewConstraint(dep.Version)
 			if err != nil {
 				return "invalid version"
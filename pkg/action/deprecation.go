@@ -0,0 +1,113 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// ChartDeprecation describes a deprecated chart or subchart.
+type ChartDeprecation struct {
+	Chart   string `json:"chart"`
+	Version string `json:"version"`
+	// ReplacedBy is a reference to the chart replacing the deprecated chart
+	ReplacedBy string `json:"replacedBy,omitempty"`
+	// EndOfSupport is the date after which the chart is no longer supported
+	EndOfSupport string `json:"endOfSupport,omitempty"`
+	// Unsupported is set once the end of support date has passed
+	Unsupported bool `json:"unsupported,omitempty"`
+}
+
+func (d ChartDeprecation) String() string {
+	msg := fmt.Sprintf("chart %s-%s is deprecated", d.Chart, d.Version)
+	if d.Unsupported {
+		msg += fmt.Sprintf(" and is no longer supported since %s", d.EndOfSupport)
+	} else if d.EndOfSupport != "" {
+		msg += fmt.Sprintf(" and is supported until %s", d.EndOfSupport)
+	}
+	if d.ReplacedBy != "" {
+		msg += fmt.Sprintf(", use %s instead", d.ReplacedBy)
+	}
+	return msg
+}
+
+// ChartDeprecations returns the deprecations of a chart and its subcharts.
+//
+// A chart is deprecated if it is marked so in its Chart.yaml or if its end of
+// support date has passed.
+func ChartDeprecations(ch *chart.Chart) []ChartDeprecation {
+	var deprecations []ChartDeprecation
+	now := Timestamper().Time
+	var walk func(*chart.Chart)
+	walk = func(c *chart.Chart) {
+		md := c.Metadata
+		d := ChartDeprecation{
+			Chart:        md.Name,
+			Version:      md.Version,
+			ReplacedBy:   md.ReplacedBy,
+			EndOfSupport: md.EndOfSupport,
+		}
+		if eos, err := time.Parse(chart.EndOfSupportLayout, md.EndOfSupport); err == nil {
+			// Support ends at the end of the day
+			d.Unsupported = now.After(eos.AddDate(0, 0, 1))
+		}
+		if md.Deprecated || d.Unsupported {
+			deprecations = append(deprecations, d)
+		}
+		for _, dep := range c.Dependencies() {
+			walk(dep)
+		}
+	}
+	walk(ch)
+	return deprecations
+}
+
+// checkDeprecations returns an error for the first deprecation of the chart.
+func checkDeprecations(ch *chart.Chart) error {
+	if d := ChartDeprecations(ch); len(d) > 0 {
+		return errors.Errorf("refusing to deploy deprecated charts: %s", d[0])
+	}
+	return nil
+}
+
+// checkDeprecatedValues raises a warning for each value set by the user that
+// the schema of the chart or of a subchart deprecates. With strict, it returns
+// an error listing them instead.
+func (c *Configuration) checkDeprecatedValues(ch *chart.Chart, vals map[string]interface{}, strict bool) error {
+	deprecations, err := chartutil.DeprecatedValues(ch, vals)
+	if err != nil {
+		return err
+	}
+	if strict && len(deprecations) > 0 {
+		msgs := make([]string, len(deprecations))
+		for i, d := range deprecations {
+			msgs[i] = d.String()
+		}
+		return errors.Errorf("refusing to use deprecated values: %s", strings.Join(msgs, "; "))
+	}
+	for _, d := range deprecations {
+		c.warn(WarningDeprecatedValue, "%s", d)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+func withDeprecation(deprecated bool, replacedBy, endOfSupport string) chartOption {
+	return func(opts *chartOptions) {
+		opts.Metadata.Deprecated = deprecated
+		opts.Metadata.ReplacedBy = replacedBy
+		opts.Metadata.EndOfSupport = endOfSupport
+	}
+}
+
+func TestChartDeprecations(t *testing.T) {
+	is := assert.New(t)
+
+	defer func(ts func() helmtime.Time) { Timestamper = ts }(Timestamper)
+	Timestamper = func() helmtime.Time { return helmtime.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC) }
+
+	is.Empty(ChartDeprecations(buildChart()))
+	is.Empty(ChartDeprecations(buildChart(withDeprecation(false, "", "2021-07-01"))))
+
+	ch := buildChart(
+		withDeprecation(true, "example/goodbye", "2021-12-31"),
+		withDependency(withName("child"), withDeprecation(false, "", "2021-06-30")),
+	)
+	deprecations := ChartDeprecations(ch)
+	is.Equal([]ChartDeprecation{
+		{Chart: "hello", Version: "0.1.0", ReplacedBy: "example/goodbye", EndOfSupport: "2021-12-31"},
+		{Chart: "child", Version: "0.1.0", EndOfSupport: "2021-06-30", Unsupported: true},
+	}, deprecations)
+	is.Equal("chart hello-0.1.0 is deprecated and is supported until 2021-12-31, use example/goodbye instead", deprecations[0].String())
+	is.Equal("chart child-0.1.0 is deprecated and is no longer supported since 2021-06-30", deprecations[1].String())
+}
+
+func TestInstallReleaseNoDeprecated(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.NoDeprecated = true
+
+	_, err := instAction.Run(buildChart(withDeprecation(true, "", "")), map[string]interface{}{})
+	is.Error(err)
+	is.Contains(err.Error(), "chart hello-0.1.0 is deprecated")
+
+	instAction = installAction(t)
+	instAction.NoDeprecated = true
+	_, err = instAction.Run(buildChart(), map[string]interface{}{})
+	is.NoError(err)
+}
+
+func withSchema(schema string) chartOption {
+	return func(opts *chartOptions) {
+		opts.Schema = []byte(schema)
+	}
+}
+
+func TestInstallReleaseDeprecatedValues(t *testing.T) {
+	is := assert.New(t)
+	ch := buildChart(withSchema(`{"properties": {"port": {"type": "integer", "deprecated": true, "replacedBy": "service.port"}}}`))
+	vals := map[string]interface{}{"port": 80}
+
+	collector := &WarningCollector{}
+	instAction := installAction(t)
+	instAction.cfg.Warnings = collector
+	_, err := instAction.Run(ch, vals)
+	is.NoError(err)
+	is.Equal([]Warning{{
+		Reason:  WarningDeprecatedValue,
+		Message: `value "port" of chart hello is deprecated, use "service.port" instead`,
+	}}, collector.Warnings())
+
+	instAction = installAction(t)
+	instAction.StrictValues = true
+	_, err = instAction.Run(ch, vals)
+	is.Error(err)
+	is.Contains(err.Error(), `refusing to use deprecated values: value "port" of chart hello is deprecated`)
+
+	instAction = installAction(t)
+	instAction.StrictValues = true
+	_, err = instAction.Run(ch, map[string]interface{}{})
+	is.NoError(err)
+}
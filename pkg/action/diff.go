@@ -0,0 +1,105 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/diff"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// ReleaseDiff returns the changes of the resources from the manifest of the
+// current release to that of the target release, sorted by kind and name.
+// Unchanged resources are left out.
+//
+// The manifests of the resources are normalized, so that only changes of the
+// objects they describe are reported. The result is written out by a
+// diff.Renderer.
+func ReleaseDiff(current, target *release.Release) ([]diff.ResourceDiff, error) {
+	currentResources, err := plannedResources(current.Manifest, current.Namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse the current release manifest")
+	}
+	targetResources, err := plannedResources(target.Manifest, target.Namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse the target release manifest")
+	}
+
+	currentByKey := map[string]*manifestResource{}
+	for _, r := range currentResources {
+		currentByKey[r.key()] = r
+	}
+	targetByKey := map[string]*manifestResource{}
+	for _, r := range targetResources {
+		targetByKey[r.key()] = r
+	}
+
+	var diffs []diff.ResourceDiff
+	for _, r := range targetResources {
+		d := diff.ResourceDiff{Kind: r.Kind, Name: r.Name, Namespace: r.Namespace, Change: diff.Create}
+		if d.Name == "" {
+			d.Name = r.GenerateName
+		}
+		if c, ok := currentByKey[r.key()]; ok {
+			if reflect.DeepEqual(c.object, r.object) {
+				continue
+			}
+			d.Change = diff.Update
+			if d.Before, err = normalizedManifest(c); err != nil {
+				return nil, err
+			}
+		}
+		if d.After, err = normalizedManifest(r); err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, d)
+	}
+	for _, r := range currentResources {
+		if _, ok := targetByKey[r.key()]; ok {
+			continue
+		}
+		d := diff.ResourceDiff{Kind: r.Kind, Name: r.Name, Namespace: r.Namespace, Change: diff.Delete}
+		if d.Name == "" {
+			d.Name = r.GenerateName
+		}
+		if d.Before, err = normalizedManifest(r); err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, d)
+	}
+
+	sort.SliceStable(diffs, func(i, j int) bool {
+		if diffs[i].Kind != diffs[j].Kind {
+			return diffs[i].Kind < diffs[j].Kind
+		}
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs, nil
+}
+
+func normalizedManifest(r *manifestResource) (string, error) {
+	data, err := yaml.Marshal(r.object)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to encode %s", r.key())
+	}
+	return string(data), nil
+}
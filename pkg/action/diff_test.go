@@ -0,0 +1,129 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/diff"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const diffTargetManifest = `---
+# Source: hello/templates/resources.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: same
+data:
+  key: value
+---
+# Source: hello/templates/resources.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: changed
+data:
+  key: new
+---
+# Source: hello/templates/resources.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: added
+---
+# Source: hello/templates/resources.yaml
+apiVersion: batch/v1
+kind: Job
+metadata:
+  generateName: migrate-
+`
+
+func TestReleaseDiff(t *testing.T) {
+	is := assert.New(t)
+
+	current := releaseStub()
+	current.Namespace = "spaced"
+	current.Manifest = planCurrentManifest
+	target := releaseStub()
+	target.Namespace = "spaced"
+	target.Manifest = diffTargetManifest
+
+	diffs, err := ReleaseDiff(current, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.Equal([]diff.ResourceDiff{
+		{
+			Kind: "ConfigMap", Name: "changed", Namespace: "spaced", Change: diff.Update,
+			Before: "apiVersion: v1\ndata:\n  key: old\nkind: ConfigMap\nmetadata:\n  name: changed\n",
+			After:  "apiVersion: v1\ndata:\n  key: new\nkind: ConfigMap\nmetadata:\n  name: changed\n",
+		},
+		{
+			Kind: "PersistentVolumeClaim", Name: "data", Namespace: "spaced", Change: diff.Delete,
+			Before: "apiVersion: v1\nkind: PersistentVolumeClaim\nmetadata:\n  annotations:\n    helm.sh/resource-policy: keep\n  name: data\n",
+		},
+		{
+			Kind: "Secret", Name: "removed", Namespace: "spaced", Change: diff.Delete,
+			Before: "apiVersion: v1\nkind: Secret\nmetadata:\n  name: removed\n",
+		},
+		{
+			Kind: "Service", Name: "added", Namespace: "spaced", Change: diff.Create,
+			After: "apiVersion: v1\nkind: Service\nmetadata:\n  name: added\n",
+		},
+	}, diffs)
+}
+
+func TestRollbackDiff(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+
+	rel := releaseStub()
+	rel.Name = "diffed"
+	rel.Manifest = planCurrentManifest
+	rel.Info.Status = release.StatusSuperseded
+	config.Releases.Create(rel)
+	upgraded := releaseStub()
+	upgraded.Name = "diffed"
+	upgraded.Version = 2
+	upgraded.Manifest = diffTargetManifest
+	config.Releases.Create(upgraded)
+
+	diffs, err := NewRollback(config).Diff("diffed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := map[string]string{}
+	for _, d := range diffs {
+		changes[d.Kind+"/"+d.Name] = d.Change
+	}
+	is.Equal(map[string]string{
+		"ConfigMap/changed":          diff.Update,
+		"PersistentVolumeClaim/data": diff.Create,
+		"Secret/removed":             diff.Create,
+		"Service/added":              diff.Delete,
+	}, changes)
+
+	// Nothing is changed by the diff
+	last, err := config.Releases.Last("diffed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.Equal(2, last.Version)
+}
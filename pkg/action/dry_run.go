@@ -0,0 +1,85 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// serverDryRun sends the resources of a release, then the hooks that would
+// run on the given events, in order, to the API server in dry-run mode. The
+// current resources are those the target resources are patched from.
+//
+// The admission of each resource is returned, so that the ValidatingAdmission
+// Policies and admission webhooks that would reject the operation are known
+// before it is performed.
+func (c *Configuration) serverDryRun(rel *release.Release, current, target kube.ResourceList, events ...release.HookEvent) ([]kube.AdmissionResult, error) {
+	client, ok := c.KubeClient.(kube.InterfaceDryRun)
+	if !ok {
+		return nil, errors.New("server-side dry runs are not supported by the Kubernetes client")
+	}
+
+	c.Log("sending the resources of %s to the server in dry-run mode", rel.Name)
+	results, err := client.DryRun(current, target)
+	if err != nil {
+		return results, err
+	}
+
+	for _, event := range events {
+		var hooks []*release.Hook
+		for _, h := range rel.Hooks {
+			if h.HasEvent(event) {
+				hooks = append(hooks, h)
+			}
+		}
+		sort.Stable(hookByWeight(hooks))
+
+		for _, h := range hooks {
+			resources, err := c.KubeClient.Build(bytes.NewBufferString(h.Manifest), true)
+			if err != nil {
+				return results, errors.Wrapf(err, "unable to build kubernetes object for %s hook %s", event, h.Path)
+			}
+			hookResults, err := client.DryRun(nil, resources)
+			for _, r := range hookResults {
+				r.Hook = true
+				results = append(results, r)
+			}
+			if err != nil {
+				return results, err
+			}
+		}
+	}
+	return results, nil
+}
+
+// DeniedAdmissions returns the resources whose requests were rejected by the
+// server-side dry run.
+func DeniedAdmissions(results []kube.AdmissionResult) []kube.AdmissionResult {
+	var denied []kube.AdmissionResult
+	for _, r := range results {
+		if !r.Allowed {
+			denied = append(denied, r)
+		}
+	}
+	return denied
+}
@@ -0,0 +1,110 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+)
+
+// dryRunKubeClient reports a resource for each server-side dry run, and
+// denies all but the first one, which is that of the release resources.
+type dryRunKubeClient struct {
+	kubefake.PrintingKubeClient
+	calls int
+}
+
+func (c *dryRunKubeClient) DryRun(_, _ kube.ResourceList) ([]kube.AdmissionResult, error) {
+	c.calls++
+	r := kube.AdmissionResult{Kind: "ConfigMap", Name: fmt.Sprintf("call-%d", c.calls), Allowed: true}
+	if c.calls > 1 {
+		r.Allowed = false
+		r.Policy = "no-hooks"
+	}
+	return []kube.AdmissionResult{r}, nil
+}
+
+func TestInstallRelease_ServerDryRun(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	client := &dryRunKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard}}
+	instAction.cfg.KubeClient = client
+	instAction.DryRun = true
+	instAction.ServerDryRun = true
+
+	res, err := instAction.Run(buildChart(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	is.Equal("Dry run complete", res.Info.Description)
+	is.Equal([]kube.AdmissionResult{
+		{Kind: "ConfigMap", Name: "call-1", Allowed: true},
+		{Kind: "ConfigMap", Name: "call-2", Hook: true, Policy: "no-hooks"},
+	}, instAction.AdmissionResults)
+	is.Len(DeniedAdmissions(instAction.AdmissionResults), 1)
+
+	// Hooks are left out if they are disabled
+	client.calls = 0
+	instAction.DisableHooks = true
+	if _, err := instAction.Run(buildChart(), map[string]interface{}{}); err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	is.Len(instAction.AdmissionResults, 1)
+}
+
+func TestInstallRelease_ServerDryRunUnsupported(t *testing.T) {
+	instAction := installAction(t)
+	instAction.DryRun = true
+	instAction.ServerDryRun = true
+
+	_, err := instAction.Run(buildChart(), map[string]interface{}{})
+	assert.EqualError(t, err, "server-side dry runs are not supported by the Kubernetes client")
+}
+
+func TestUpgradeRelease_ServerDryRun(t *testing.T) {
+	is := assert.New(t)
+	upAction := upgradeAction(t)
+	client := &dryRunKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard}}
+	upAction.cfg.KubeClient = client
+	rel := releaseStub()
+	upAction.cfg.Releases.Create(rel)
+	upAction.DryRun = true
+	upAction.ServerDryRun = true
+
+	res, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed upgrade: %s", err)
+	}
+	is.Equal(2, res.Version)
+	is.Equal([]kube.AdmissionResult{
+		{Kind: "ConfigMap", Name: "call-1", Allowed: true},
+		{Kind: "ConfigMap", Name: "call-2", Hook: true, Policy: "no-hooks"},
+	}, upAction.AdmissionResults)
+
+	// Nothing is stored by the dry run
+	last, err := upAction.cfg.Releases.Last(rel.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.Equal(1, last.Version)
+}
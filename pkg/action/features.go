@@ -0,0 +1,72 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+var featureNameRegexp = regexp.MustCompile(`^[A-Za-z][-A-Za-z0-9_]*$`)
+
+// ParseFeatures parses the features of the Helm client enabled by users,
+// each given as "name" or "name=true|false". Later specifications of a
+// feature win. The built-in features are set by Helm and cannot be given.
+func ParseFeatures(specs []string) (chartutil.Features, error) {
+	builtin := map[string]bool{}
+	for _, name := range chartutil.BuiltinFeatures() {
+		builtin[name] = true
+	}
+
+	features := chartutil.Features{}
+	for _, spec := range specs {
+		name, enabled := spec, true
+		if i := strings.Index(spec, "="); i >= 0 {
+			v, err := strconv.ParseBool(spec[i+1:])
+			if err != nil {
+				return nil, errors.Errorf("invalid feature %q: the value must be true or false", spec)
+			}
+			name, enabled = spec[:i], v
+		}
+		if !featureNameRegexp.MatchString(name) {
+			return nil, errors.Errorf("invalid feature name %q", name)
+		}
+		if builtin[name] {
+			return nil, errors.Errorf("feature %q is set by Helm", name)
+		}
+		features[name] = enabled
+	}
+	return features, nil
+}
+
+// renderFeatures returns the features of the client for a render: the ones
+// enabled by users along with the built-in ones, which win.
+func renderFeatures(features chartutil.Features, dryRun, serverDryRun, clientOnly bool) chartutil.Features {
+	out := chartutil.Features{}
+	for name, enabled := range features {
+		out[name] = enabled
+	}
+	out[chartutil.FeatureDryRun] = dryRun
+	out[chartutil.FeatureServerDryRun] = dryRun && serverDryRun
+	out[chartutil.FeatureClientOnly] = clientOnly
+	return out
+}
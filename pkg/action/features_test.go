@@ -0,0 +1,78 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestParseFeatures(t *testing.T) {
+	is := assert.New(t)
+
+	features, err := ParseFeatures([]string{"canary", "legacy-ingress=false", "canary=0", "ssa=true"})
+	is.NoError(err)
+	is.Equal(chartutil.Features{"canary": false, "legacy-ingress": false, "ssa": true}, features)
+
+	for _, spec := range []string{"", "=true", "1canary", "canary=maybe", "with space", chartutil.FeatureDryRun, "clientOnly=false"} {
+		_, err := ParseFeatures([]string{spec})
+		is.Error(err, spec)
+	}
+}
+
+func withFeaturesTemplate() chartOption {
+	return func(opts *chartOptions) {
+		opts.Templates = append(opts.Templates, &chart.File{
+			Name: "templates/features",
+			Data: []byte(`features: "{{ index .Helm.Features "canary" }} {{ .Helm.Features.dryRun }} {{ .Helm.Features.clientOnly }}"`),
+		})
+	}
+}
+
+func TestInstallFeatures(t *testing.T) {
+	is := assert.New(t)
+
+	instAction := installAction(t)
+	instAction.Features = chartutil.Features{"canary": true, chartutil.FeatureDryRun: false}
+	instAction.DryRun = true
+	res, err := instAction.Run(buildChart(withFeaturesTemplate()), map[string]interface{}{})
+	is.NoError(err)
+	is.Contains(res.Manifest, `features: "true true false"`)
+
+	instAction = installAction(t)
+	instAction.ClientOnly = true
+	instAction.DryRun = true
+	res, err = instAction.Run(buildChart(withFeaturesTemplate()), map[string]interface{}{})
+	is.NoError(err)
+	is.Contains(res.Manifest, `features: "false true true"`)
+}
+
+func TestUpgradeFeatures(t *testing.T) {
+	is := assert.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	upAction.cfg.Releases.Create(rel)
+	upAction.Features = chartutil.Features{"canary": true}
+	res, err := upAction.Run(rel.Name, buildChart(withFeaturesTemplate()), map[string]interface{}{})
+	is.NoError(err)
+	is.Contains(res.Manifest, `features: "true false false"`)
+}
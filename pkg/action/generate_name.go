@@ -0,0 +1,82 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// recordGeneratedNames records in the release the names the API server
+// assigned to the created resources that use metadata.generateName. The
+// resources created by a previous revision are replaced, as they are deleted
+// when the release is upgraded or rolled back.
+func recordGeneratedNames(rel *release.Release, created kube.ResourceList) {
+	var names []release.GeneratedName
+	for _, info := range created {
+		generateName := resourceGenerateName(info)
+		if generateName == "" || info.Name == "" {
+			continue
+		}
+		names = append(names, release.GeneratedName{
+			Kind:         info.Mapping.GroupVersionKind.Kind,
+			Namespace:    info.Namespace,
+			GenerateName: generateName,
+			Name:         info.Name,
+		})
+	}
+	rel.GeneratedNames = names
+}
+
+// applyGeneratedNames names the resources of the release that use
+// metadata.generateName after the names recorded in the release, so that
+// they can be found in the cluster. The resources with no recorded name are
+// left unnamed.
+func (cfg *Configuration) applyGeneratedNames(resources kube.ResourceList, rel *release.Release) {
+	used := make([]bool, len(rel.GeneratedNames))
+	for _, info := range resources {
+		if !kube.GeneratesName(info) {
+			continue
+		}
+		if !applyGeneratedName(info, rel.GeneratedNames, used) {
+			cfg.Log("warning: no name recorded for %s %s: it cannot be found in the cluster", info.Mapping.GroupVersionKind.Kind, resourceGenerateName(info))
+		}
+	}
+}
+
+func applyGeneratedName(info *resource.Info, names []release.GeneratedName, used []bool) bool {
+	generateName := resourceGenerateName(info)
+	for i, n := range names {
+		if used[i] || n.Kind != info.Mapping.GroupVersionKind.Kind || n.Namespace != info.Namespace || n.GenerateName != generateName {
+			continue
+		}
+		if err := accessor.SetName(info.Object, n.Name); err != nil {
+			return false
+		}
+		used[i] = true
+		info.Name = n.Name
+		return true
+	}
+	return false
+}
+
+func resourceGenerateName(info *resource.Info) string {
+	generateName, _ := accessor.GenerateName(info.Object)
+	return generateName
+}
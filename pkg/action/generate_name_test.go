@@ -0,0 +1,79 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestRecordGeneratedNames(t *testing.T) {
+	created := kube.ResourceList{
+		fakeObject(`{apiVersion: batch/v1, kind: Job, metadata: {name: migrate-x7k2p, generateName: migrate-}}`),
+		fakeObject(`{apiVersion: v1, kind: ConfigMap, metadata: {name: config}}`),
+	}
+	rel := releaseStub()
+	rel.GeneratedNames = []release.GeneratedName{{Kind: "Job", GenerateName: "migrate-", Name: "migrate-old"}}
+	recordGeneratedNames(rel, created)
+	assert.Equal(t, []release.GeneratedName{
+		{Kind: "Job", Namespace: "spaced", GenerateName: "migrate-", Name: "migrate-x7k2p"},
+	}, rel.GeneratedNames)
+}
+
+func TestUninstallRelease_GeneratedNames(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+	config.KubeClient = &resourcesKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard}}
+
+	rel := releaseStub()
+	rel.Namespace = "spaced"
+	rel.Hooks = nil
+	rel.Manifest = `---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  generateName: migrate-
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  generateName: migrate-
+`
+	rel.GeneratedNames = []release.GeneratedName{
+		{Kind: "Job", Namespace: "spaced", GenerateName: "migrate-", Name: "migrate-x7k2p"},
+	}
+	if err := config.Releases.Create(rel); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := NewUninstall(config).Run(rel.Name)
+	if err != nil {
+		t.Fatalf("Failed uninstall: %s", err)
+	}
+	var names []string
+	for _, r := range res.Report.Resources {
+		names = append(names, r.Name)
+	}
+	// The second Job has no recorded name, so it cannot be found
+	is.Equal([]string{"migrate-x7k2p", ""}, names)
+}
@@ -17,7 +17,11 @@ limitations under the License.
 package action
 
 import (
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
 )
 
 // Get is the action for checking a given release's information.
@@ -45,3 +49,36 @@ func (g *Get) Run(name string) (*release.Release, error) {
 
 	return g.cfg.releaseContent(name, g.Version)
 }
+
+// RunRange returns the revisions of the given release from the revision from
+// to the revision to, both included, oldest first. A bound of 0 leaves that
+// end of the range open.
+func (g *Get) RunRange(name string, from, to int) ([]*release.Release, error) {
+	if err := g.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, errors.Errorf("release name is invalid: %s", name)
+	}
+
+	if from > 0 && to > 0 && from > to {
+		return nil, errors.Errorf("invalid revision range %d..%d", from, to)
+	}
+
+	hist, err := g.cfg.Releases.History(name)
+	if err != nil {
+		return nil, err
+	}
+	var rels []*release.Release
+	for _, r := range hist {
+		if (from == 0 || r.Version >= from) && (to == 0 || r.Version <= to) {
+			rels = append(rels, r)
+		}
+	}
+	if len(rels) == 0 {
+		return nil, errors.Errorf("release %q has no revisions in the range %d..%d", name, from, to)
+	}
+	releaseutil.SortByRevision(rels)
+	return rels, nil
+}
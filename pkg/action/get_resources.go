@@ -0,0 +1,280 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Sources of the resources of a release.
+const (
+	// ResourceSourceManifest is the source of the resources of the manifest.
+	ResourceSourceManifest = "manifest"
+	// ResourceSourceHook is the source of the hooks that still exist.
+	ResourceSourceHook = "hook"
+	// ResourceSourceLabel is the source of the objects labeled with the name
+	// of the release that are not in the manifest, such as the Pods of its
+	// Deployments.
+	ResourceSourceLabel = "label"
+)
+
+// ResourceStatusMissing is the status of the resources of the manifest that
+// do not exist.
+const ResourceStatusMissing = "Missing"
+
+// labelQueriedTypes are the resource types listed by label, along with the
+// types of the manifest, to find the objects created on behalf of a release.
+var labelQueriedTypes = []string{"pods", "replicasets.apps", "jobs.batch"}
+
+// ReleaseResource is a live object belonging to a release.
+type ReleaseResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	// Source is how the object was found, one of the ResourceSource
+	// constants
+	Source string `json:"source"`
+	// Status summarizes the state of the object, such as '2/3 ready'
+	Status string `json:"status"`
+	// Object is the live object. It is nil if the object is missing.
+	Object runtime.Object `json:"-"`
+}
+
+// GetResources is the action for listing the live resources of a release.
+//
+// It provides the implementation of 'helm get resources'.
+type GetResources struct {
+	cfg *Configuration
+
+	// Initializing Version to 0 will get the latest revision of the release.
+	Version int
+}
+
+// NewGetResources creates a new GetResources object with the given
+// configuration.
+func NewGetResources(cfg *Configuration) *GetResources {
+	return &GetResources{
+		cfg: cfg,
+	}
+}
+
+// Run lists the live objects of the given release: the resources of its
+// manifest, its hooks that still exist, and the objects labeled with its name.
+func (g *GetResources) Run(name string) ([]ReleaseResource, error) {
+	if err := g.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	rel, err := g.cfg.releaseContent(name, g.Version)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := g.cfg.GetResources(rel)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, r := range resources {
+		if r.Source == ResourceSourceManifest && r.Status == ResourceStatusMissing {
+			missing = append(missing, fmt.Sprintf("%s/%s", r.Kind, r.Name))
+		}
+	}
+	if len(missing) > 0 {
+		g.cfg.notify(EventDriftDetected, rel, fmt.Sprintf("%d resource(s) of the manifest are missing: %s", len(missing), strings.Join(missing, ", ")))
+	}
+	return resources, nil
+}
+
+// GetResources lists the live objects of the release: the resources of its
+// manifest, its hooks that still exist, and the objects labeled with its name.
+func (cfg *Configuration) GetResources(rel *release.Release) ([]ReleaseResource, error) {
+	client, ok := cfg.KubeClient.(kube.InterfaceResources)
+	if !ok {
+		return nil, errors.New("the Kubernetes client does not support listing resources")
+	}
+
+	manifest, err := cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build kubernetes objects from release manifest")
+	}
+	cfg.applyGeneratedNames(manifest, rel)
+	var hookManifests bytes.Buffer
+	for _, h := range rel.Hooks {
+		fmt.Fprintf(&hookManifests, "---\n%s\n", h.Manifest)
+	}
+	hooks, err := cfg.KubeClient.Build(&hookManifests, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build kubernetes objects from release hooks")
+	}
+
+	liveManifest, err := client.Get(manifest)
+	if err != nil {
+		return nil, err
+	}
+	liveHooks, err := client.Get(hooks)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []ReleaseResource
+	for _, info := range manifest {
+		if live := liveManifest.Get(info); live != nil {
+			resources = append(resources, newReleaseResource(live, ResourceSourceManifest))
+			continue
+		}
+		r := newReleaseResource(info, ResourceSourceManifest)
+		r.Status, r.Object = ResourceStatusMissing, nil
+		resources = append(resources, r)
+	}
+	for _, info := range liveHooks {
+		resources = append(resources, newReleaseResource(info, ResourceSourceHook))
+	}
+
+	types := append([]string{}, labelQueriedTypes...)
+	for _, info := range manifest {
+		types = append(types, info.Mapping.Resource.GroupResource().String())
+	}
+	labeled, err := client.List(rel.Namespace, appInstanceLabel+"="+rel.Name, uniqueStrings(types)...)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list the objects labeled with the release name")
+	}
+	known := append(append(kube.ResourceList{}, manifest...), liveHooks...)
+	for _, info := range labeled {
+		if !known.Contains(info) {
+			resources = append(resources, newReleaseResource(info, ResourceSourceLabel))
+		}
+	}
+	return resources, nil
+}
+
+func newReleaseResource(info *resource.Info, source string) ReleaseResource {
+	gvk := info.Mapping.GroupVersionKind
+	return ReleaseResource{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Name:       info.Name,
+		Namespace:  info.Namespace,
+		Source:     source,
+		Status:     resourceStatus(info.Object),
+		Object:     info.Object,
+	}
+}
+
+// resourceStatus summarizes the state of an object from its status.
+func resourceStatus(obj runtime.Object) string {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		if obj == nil {
+			return ResourceStatusMissing
+		}
+		m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return "Unknown"
+		}
+		u = &unstructured.Unstructured{Object: m}
+	}
+
+	if u.GetDeletionTimestamp() != nil {
+		return "Terminating"
+	}
+	switch u.GetKind() {
+	case "Deployment", "StatefulSet", "ReplicaSet":
+		desired, found := nestedInt(u.Object, "spec", "replicas")
+		if !found {
+			desired = 1
+		}
+		ready, _ := nestedInt(u.Object, "status", "readyReplicas")
+		return fmt.Sprintf("%d/%d ready", ready, desired)
+	case "DaemonSet":
+		desired, _ := nestedInt(u.Object, "status", "desiredNumberScheduled")
+		ready, _ := nestedInt(u.Object, "status", "numberReady")
+		return fmt.Sprintf("%d/%d ready", ready, desired)
+	case "Job":
+		if conditionTrue(u, "Complete") {
+			return "Complete"
+		}
+		if conditionTrue(u, "Failed") {
+			return "Failed"
+		}
+		return "Running"
+	case "Pod", "PersistentVolumeClaim", "PersistentVolume", "Namespace":
+		if phase, _, _ := unstructured.NestedString(u.Object, "status", "phase"); phase != "" {
+			return phase
+		}
+	}
+	for _, c := range []string{"Ready", "Available", "Established"} {
+		if conditionTrue(u, c) {
+			return c
+		}
+	}
+	return "Exists"
+}
+
+// conditionTrue returns true if the condition of the given type of the
+// object is true.
+func conditionTrue(u *unstructured.Unstructured, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if m["type"] == conditionType && strings.EqualFold(fmt.Sprint(m["status"]), "true") {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedInt returns the number at the given path of the object, whether it
+// was decoded as an integer or a float.
+func nestedInt(obj map[string]interface{}, fields ...string) (int64, bool) {
+	v, found, _ := unstructured.NestedFieldNoCopy(obj, fields...)
+	switch n := v.(type) {
+	case int64:
+		return n, found
+	case int:
+		return int64(n), found
+	case float64:
+		return int64(n), found
+	}
+	return 0, false
+}
+
+func uniqueStrings(s []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,168 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// resourcesKubeClient builds the resources of manifests, and holds the live
+// objects of a fake cluster.
+type resourcesKubeClient struct {
+	kubefake.PrintingKubeClient
+	live     kube.ResourceList
+	listed   []string
+	selector string
+}
+
+func (c *resourcesKubeClient) Build(r io.Reader, _ bool) (kube.ResourceList, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	manifests := releaseutil.SplitManifests(string(data))
+	keys := make([]string, 0, len(manifests))
+	for k := range manifests {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	var list kube.ResourceList
+	for _, k := range keys {
+		obj := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(manifests[k]), &obj); err != nil {
+			return nil, err
+		}
+		if len(obj) > 0 {
+			list = append(list, fakeInfo(&unstructured.Unstructured{Object: obj}))
+		}
+	}
+	return list, nil
+}
+
+func (c *resourcesKubeClient) Get(resources kube.ResourceList) (kube.ResourceList, error) {
+	return c.live.Intersect(resources), nil
+}
+
+func (c *resourcesKubeClient) List(namespace, selector string, types ...string) (kube.ResourceList, error) {
+	c.listed = types
+	c.selector = selector
+	return c.live, nil
+}
+
+func fakeInfo(u *unstructured.Unstructured) *resource.Info {
+	if u.GetNamespace() == "" {
+		u.SetNamespace("spaced")
+	}
+	gvk := u.GroupVersionKind()
+	return &resource.Info{
+		Name:      u.GetName(),
+		Namespace: u.GetNamespace(),
+		Object:    u,
+		Mapping: &meta.RESTMapping{
+			GroupVersionKind: gvk,
+			Resource:         schema.GroupVersionResource{Group: gvk.Group, Version: gvk.Version, Resource: strings.ToLower(gvk.Kind) + "s"},
+		},
+	}
+}
+
+func fakeObject(manifest string) *resource.Info {
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(manifest), &obj); err != nil {
+		panic(err)
+	}
+	return fakeInfo(&unstructured.Unstructured{Object: obj})
+}
+
+func TestGetResources(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+	client := &resourcesKubeClient{
+		live: kube.ResourceList{
+			fakeObject(`{apiVersion: apps/v1, kind: Deployment, metadata: {name: web}, spec: {replicas: 3}, status: {readyReplicas: 2}}`),
+			fakeObject(`{apiVersion: v1, kind: ConfigMap, metadata: {name: test-cm}}`),
+			fakeObject(`{apiVersion: v1, kind: Pod, metadata: {name: web-abc}, status: {phase: Running}}`),
+		},
+	}
+	config.KubeClient = client
+
+	rel := releaseStub()
+	rel.Namespace = "spaced"
+	rel.Manifest = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`
+	rel.Hooks = []*release.Hook{{Manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test-cm"}}
+	if err := config.Releases.Create(rel); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := NewGetResources(config).Run(rel.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range res {
+		got = append(got, strings.Join([]string{r.Kind, r.Name, r.Status, r.Source}, " "))
+	}
+	is.Equal([]string{
+		"Deployment web 2/3 ready manifest",
+		"Service web Missing manifest",
+		"ConfigMap test-cm Exists hook",
+		"Pod web-abc Running label",
+	}, got)
+	is.Nil(res[1].Object)
+	is.Equal("app.kubernetes.io/instance="+rel.Name, client.selector)
+	is.Equal([]string{"deployments.apps", "jobs.batch", "pods", "replicasets.apps", "services"}, client.listed)
+}
+
+func TestGetResourcesUnsupportedClient(t *testing.T) {
+	config := actionConfigFixture(t)
+	config.KubeClient = unsupportedKubeClient{config.KubeClient}
+	_, err := config.GetResources(releaseStub())
+	assert.EqualError(t, err, "the Kubernetes client does not support listing resources")
+}
+
+// unsupportedKubeClient hides the optional methods of a client.
+type unsupportedKubeClient struct {
+	kube.Interface
+}
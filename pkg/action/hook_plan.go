@@ -0,0 +1,94 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"sort"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// PlannedHook is a hook that would run during an operation on a release.
+type PlannedHook struct {
+	// Event is the event the hook runs on
+	Event release.HookEvent `json:"event"`
+	Name  string            `json:"name"`
+	Kind  string            `json:"kind"`
+	// Path is the path of the template of the hook in the chart
+	Path   string `json:"path"`
+	Weight int    `json:"weight"`
+	// DeletePolicies are the delete policies of the hook, including the
+	// default before-hook-creation policy if the hook has none
+	DeletePolicies []release.HookDeletePolicy `json:"delete_policies"`
+	// TimeoutSeconds is the timeout of the hook, if it has one
+	TimeoutSeconds int64 `json:"timeout_seconds,omitempty"`
+}
+
+// pendingHookEvents are the events of the hooks that run for each pending
+// status of a release.
+var pendingHookEvents = map[release.Status][]release.HookEvent{
+	release.StatusPendingInstall:  {release.HookPreInstall, release.HookMigration, release.HookPostInstall},
+	release.StatusPendingUpgrade:  {release.HookPreUpgrade, release.HookMigration, release.HookPostUpgrade},
+	release.StatusPendingRollback: {release.HookPreRollback, release.HookPostRollback},
+}
+
+// HookPlan returns the hooks of the release that would run on the given
+// events, in the order they would be executed: events in the given order,
+// then hooks by weight and name.
+//
+// If no events are given, the events of the pending operation on the release
+// are used, so the plan of a release returned by a dry run install or upgrade
+// lists the hooks that the operation would run.
+func HookPlan(rel *release.Release, events ...release.HookEvent) []PlannedHook {
+	if len(events) == 0 && rel.Info != nil {
+		events = pendingHookEvents[rel.Info.Status]
+	}
+
+	var plan []PlannedHook
+	for _, event := range events {
+		var hooks []*release.Hook
+		for _, h := range rel.Hooks {
+			// Migrations that already succeeded for the chart version do
+			// not run again, as execMigrations skips them
+			if event == release.HookMigration && migrationSucceeded(h, releaseChartVersion(rel)) {
+				continue
+			}
+			if h.HasEvent(event) {
+				hooks = append(hooks, h)
+			}
+		}
+		// Hooks are pre-ordered by kind, so keep order stable, as execHook does
+		sort.Stable(hookByWeight(hooks))
+
+		for _, h := range hooks {
+			policies := h.DeletePolicies
+			if len(policies) == 0 {
+				policies = []release.HookDeletePolicy{release.HookBeforeHookCreation}
+			}
+			plan = append(plan, PlannedHook{
+				Event:          event,
+				Name:           h.Name,
+				Kind:           h.Kind,
+				Path:           h.Path,
+				Weight:         h.Weight,
+				DeletePolicies: policies,
+				TimeoutSeconds: h.TimeoutSeconds,
+			})
+		}
+	}
+	return plan
+}
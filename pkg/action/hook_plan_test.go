@@ -0,0 +1,58 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestHookPlan(t *testing.T) {
+	is := assert.New(t)
+
+	rel := &release.Release{
+		Info: &release.Info{Status: release.StatusPendingUpgrade},
+		Hooks: []*release.Hook{
+			{Name: "migrate", Kind: "Job", Weight: 5, Events: []release.HookEvent{release.HookPreUpgrade}, DeletePolicies: []release.HookDeletePolicy{release.HookSucceeded}},
+			{Name: "notify", Kind: "Job", Events: []release.HookEvent{release.HookPostUpgrade, release.HookPostInstall}},
+			{Name: "backup", Kind: "Job", Weight: -1, Events: []release.HookEvent{release.HookPreUpgrade}, TimeoutSeconds: 60},
+			{Name: "seed", Kind: "Job", Events: []release.HookEvent{release.HookPreInstall}},
+		},
+	}
+
+	plan := HookPlan(rel)
+	is.Len(plan, 3)
+	is.Equal("backup", plan[0].Name)
+	is.Equal(release.HookPreUpgrade, plan[0].Event)
+	is.Equal(int64(60), plan[0].TimeoutSeconds)
+	is.Equal("migrate", plan[1].Name)
+	is.Equal([]release.HookDeletePolicy{release.HookSucceeded}, plan[1].DeletePolicies)
+	is.Equal("notify", plan[2].Name)
+	is.Equal(release.HookPostUpgrade, plan[2].Event)
+	is.Equal([]release.HookDeletePolicy{release.HookBeforeHookCreation}, plan[2].DeletePolicies)
+
+	// Explicit events override the pending operation
+	plan = HookPlan(rel, release.HookPreInstall)
+	is.Len(plan, 1)
+	is.Equal("seed", plan[0].Name)
+
+	// The hooks of the release are left untouched
+	is.Empty(rel.Hooks[1].DeletePolicies)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// setJobTTL sets spec.ttlSecondsAfterFinished on the Jobs among the hook
+// resources, so that the cluster removes them once they have finished. A TTL
+// set in the manifest itself takes precedence.
+func setJobTTL(resources kube.ResourceList, ttl int64) {
+	for _, info := range resources {
+		if info.Object.GetObjectKind().GroupVersionKind().Kind != "Job" {
+			continue
+		}
+		u, ok := info.Object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		if _, found, _ := unstructured.NestedFieldNoCopy(u.Object, "spec", "ttlSecondsAfterFinished"); found {
+			continue
+		}
+		// Errors can only occur if spec is not a map, in which case the
+		// resource is rejected on creation anyway.
+		_ = unstructured.SetNestedField(u.Object, ttl, "spec", "ttlSecondsAfterFinished")
+	}
+}
+
+// deleteExpiredHooks garbage collects the kept resources of successful hooks
+// of the named release whose TTL has expired. Jobs are normally removed by the
+// cluster already; this covers other kinds and clusters where the TTL
+// controller is disabled.
+//
+// Failures are logged rather than returned, as garbage collection must not
+// get in the way of the operation being performed.
+func (cfg *Configuration) deleteExpiredHooks(name string) {
+	history, err := cfg.Releases.History(name)
+	if err != nil || len(history) == 0 {
+		return
+	}
+	releaseutil.Reverse(history, releaseutil.SortByRevision)
+
+	now := cfg.Now()
+	// Hook resources are recreated under the same name by later revisions,
+	// so only the most recent run of a hook is considered.
+	seen := map[string]bool{}
+	for _, rel := range history {
+		changed := false
+		for _, h := range rel.Hooks {
+			if h.LastRun.StartedAt.IsZero() {
+				continue
+			}
+			key := h.Kind + "/" + h.Name
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if h.LastRun.ResourceState != release.HookResourceKept || h.LastRun.ExpiresAt.IsZero() || now.Before(h.LastRun.ExpiresAt) {
+				continue
+			}
+			if h.Kind == "CustomResourceDefinition" {
+				continue
+			}
+			resources, err := cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), false)
+			if err != nil {
+				cfg.Log("unable to build hook %s for garbage collection: %s", h.Path, err)
+				continue
+			}
+			if _, errs := cfg.KubeClient.Delete(resources); len(errs) > 0 {
+				cfg.Log("unable to garbage collect hook %s: %s", h.Path, joinErrors(errs))
+				continue
+			}
+			cfg.Log("garbage collected %s hook %s of %s revision %d", h.Kind, h.Name, rel.Name, rel.Version)
+			h.LastRun.ResourceState = release.HookResourceExpired
+			h.RecordLastRun()
+			changed = true
+		}
+		if changed {
+			cfg.recordRelease(rel)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+func TestSetJobTTL(t *testing.T) {
+	is := assert.New(t)
+
+	job := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata":   map[string]interface{}{"name": "migrate"},
+	}}
+	pinned := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata":   map[string]interface{}{"name": "pinned"},
+		"spec":       map[string]interface{}{"ttlSecondsAfterFinished": int64(10)},
+	}}
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "check"},
+	}}
+
+	setJobTTL(kube.ResourceList{{Object: job}, {Object: pinned}, {Object: pod}}, 300)
+
+	ttl, _, _ := unstructured.NestedInt64(job.Object, "spec", "ttlSecondsAfterFinished")
+	is.Equal(int64(300), ttl)
+	ttl, _, _ = unstructured.NestedInt64(pinned.Object, "spec", "ttlSecondsAfterFinished")
+	is.Equal(int64(10), ttl)
+	_, found, _ := unstructured.NestedFieldNoCopy(pod.Object, "spec")
+	is.False(found)
+}
+
+func TestKeepHookResourcesSchedulesExpiry(t *testing.T) {
+	is := assert.New(t)
+
+	ttl := int64(60)
+	completed := helmtime.Unix(1600000000, 0)
+	succeeded := &release.Hook{
+		TTLSeconds: &ttl,
+		LastRun: release.HookExecution{
+			CompletedAt:   completed,
+			Phase:         release.HookPhaseSucceeded,
+			ResourceState: release.HookResourceCreated,
+		},
+	}
+	failed := &release.Hook{
+		TTLSeconds: &ttl,
+		LastRun: release.HookExecution{
+			CompletedAt:   completed,
+			Phase:         release.HookPhaseFailed,
+			ResourceState: release.HookResourceCreated,
+		},
+	}
+
+	keepHookResources([]*release.Hook{succeeded, failed})
+
+	is.Equal(completed.Add(time.Minute), succeeded.LastRun.ExpiresAt)
+	is.True(failed.LastRun.ExpiresAt.IsZero(), "failed hooks are kept for inspection")
+}
+
+func TestDeleteExpiredHooks(t *testing.T) {
+	is := assert.New(t)
+	cfg := actionConfigFixture(t)
+
+	hook := func(name string, expiresAt helmtime.Time) *release.Hook {
+		return &release.Hook{
+			Name:   name,
+			Kind:   "ConfigMap",
+			Events: []release.HookEvent{release.HookPostInstall},
+			LastRun: release.HookExecution{
+				StartedAt:     helmtime.Now().Add(-time.Hour),
+				Phase:         release.HookPhaseSucceeded,
+				ResourceState: release.HookResourceKept,
+				ExpiresAt:     expiresAt,
+			},
+		}
+	}
+
+	rel := namedReleaseStub("ttl", release.StatusDeployed)
+	rel.Hooks = []*release.Hook{
+		hook("expired", helmtime.Now().Add(-time.Minute)),
+		hook("fresh", helmtime.Now().Add(time.Hour)),
+		hook("forever", helmtime.Time{}),
+	}
+	is.NoError(cfg.Releases.Create(rel))
+
+	cfg.deleteExpiredHooks(rel.Name)
+
+	stored, err := cfg.Releases.Get(rel.Name, rel.Version)
+	is.NoError(err)
+	is.Equal(release.HookResourceExpired, stored.Hooks[0].LastRun.ResourceState)
+	is.Equal(release.HookResourceKept, stored.Hooks[1].LastRun.ResourceState)
+	is.Equal(release.HookResourceKept, stored.Hooks[2].LastRun.ResourceState)
+}
@@ -18,16 +18,19 @@ package action
 import (
 	"bytes"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 
+	"helm.sh/helm/v3/pkg/kube"
 	"helm.sh/helm/v3/pkg/release"
-	helmtime "helm.sh/helm/v3/pkg/time"
 )
 
 // execHook executes all of the hooks for the given hook event.
 func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent, timeout time.Duration) error {
+	cfg.deleteExpiredHooks(rl.Name)
+
 	executingHooks := []*release.Hook{}
 
 	for _, h := range rl.Hooks {
@@ -38,10 +41,36 @@ func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent,
 		}
 	}
 
+	return cfg.execHooks(rl, hook, executingHooks, timeout)
+}
+
+// execHooks executes the given hooks for the given hook event.
+func (cfg *Configuration) execHooks(rl *release.Release, hook release.HookEvent, executingHooks []*release.Hook, timeout time.Duration) error {
 	// hooke are pre-ordered by kind, so keep order stable
 	sort.Stable(hookByWeight(executingHooks))
 
-	for _, h := range executingHooks {
+	// Whatever the outcome, the executions end up in the history of the hooks
+	defer func() {
+		for _, h := range executingHooks {
+			if h.LastRun.Revision == rl.Version && h.LastRun.Event == hook {
+				h.RecordLastRun()
+			}
+		}
+	}()
+
+	// Hooks of the same weight form a group that must complete within the
+	// timeout of the group.
+	var (
+		groupTimeout time.Duration
+		deadline     time.Time
+	)
+	for i, h := range executingHooks {
+		if i == 0 || h.Weight != executingHooks[i-1].Weight {
+			groupTimeout = hookGroupTimeout(executingHooks[i:], timeout)
+			deadline = cfg.Now().Time.Add(groupTimeout)
+			cfg.Log("executing %s hooks of weight %d with a timeout of %v", hook, h.Weight, groupTimeout)
+		}
+
 		// Set default delete policy to before-hook-creation
 		if h.DeletePolicies == nil || len(h.DeletePolicies) == 0 {
 			// TODO(jlegrone): Only apply before-hook-creation delete policy to run to completion
@@ -54,17 +83,33 @@ func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent,
 		if err := cfg.deleteHookByPolicy(h, release.HookBeforeHookCreation); err != nil {
 			return err
 		}
+		if err := cfg.waitForHookDeletion(h, groupTimeout); err != nil {
+			return err
+		}
 
 		resources, err := cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), true)
 		if err != nil {
 			return errors.Wrapf(err, "unable to build kubernetes object for %s hook %s", hook, h.Path)
 		}
+		if h.TTLSeconds != nil {
+			setJobTTL(resources, *h.TTLSeconds)
+		}
+		if hook == release.HookMigration {
+			if err := checkMigrationResources(h, resources); err != nil {
+				return err
+			}
+		}
 
 		// Record the time at which the hook was applied to the cluster
 		h.LastRun = release.HookExecution{
-			StartedAt: helmtime.Now(),
-			Phase:     release.HookPhaseRunning,
+			StartedAt:    cfg.Now(),
+			Phase:        release.HookPhaseRunning,
+			Revision:     rl.Version,
+			Event:        hook,
+			ChartVersion: releaseChartVersion(rl),
+			Resource:     hookResource(h, resources),
 		}
+		h.RecordLastRun()
 		cfg.recordRelease(rl)
 
 		// As long as the implementation of WatchUntilReady does not panic, HookPhaseFailed or HookPhaseSucceeded
@@ -74,24 +119,45 @@ func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent,
 
 		// Create hook resources
 		if _, err := cfg.KubeClient.Create(resources); err != nil {
-			h.LastRun.CompletedAt = helmtime.Now()
+			h.LastRun.CompletedAt = cfg.Now()
 			h.LastRun.Phase = release.HookPhaseFailed
+			h.LastRun.Message = err.Error()
+			keepHookResources(executingHooks[:i])
 			return errors.Wrapf(err, "warning: Hook %s %s failed", hook, h.Path)
 		}
+		h.LastRun.ResourceState = release.HookResourceCreated
 
-		// Watch hook resources until they have completed
-		err = cfg.KubeClient.WatchUntilReady(resources, timeout)
+		// Watch hook resources until they have completed, within what is left
+		// of the group's timeout. A zero timeout means waiting indefinitely.
+		remaining := groupTimeout
+		if groupTimeout > 0 {
+			if remaining = deadline.Sub(cfg.Now().Time); remaining <= 0 {
+				remaining = time.Nanosecond
+			}
+		}
+		if h.SuccessCondition != "" {
+			// The hook succeeds once its resource meets the condition,
+			// whatever its kind
+			var cond kube.WaitCondition
+			if cond, err = hookSuccessCondition(h); err == nil {
+				err = cfg.waitForConditions(resources, remaining, []kube.WaitCondition{cond})
+			}
+		} else {
+			err = cfg.KubeClient.WatchUntilReady(resources, remaining)
+		}
 		// Note the time of success/failure
-		h.LastRun.CompletedAt = helmtime.Now()
+		h.LastRun.CompletedAt = cfg.Now()
 		// Mark hook as succeeded or failed
 		if err != nil {
 			h.LastRun.Phase = release.HookPhaseFailed
+			h.LastRun.Message = err.Error()
 			// If a hook is failed, check the annotation of the hook to determine whether the hook should be deleted
 			// under failed condition. If so, then clear the corresponding resource object in the hook
 			if err := cfg.deleteHookByPolicy(h, release.HookFailed); err != nil {
 				return err
 			}
-			return err
+			keepHookResources(executingHooks[:i+1])
+			return errors.Wrapf(err, "%s hook %s (weight %d) failed", hook, h.Path, h.Weight)
 		}
 		h.LastRun.Phase = release.HookPhaseSucceeded
 	}
@@ -103,10 +169,74 @@ func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent,
 			return err
 		}
 	}
+	keepHookResources(executingHooks)
 
 	return nil
 }
 
+// keepHookResources marks the resources of the given hooks that were created
+// and not deleted by a delete policy as kept. Successful hooks with a TTL are
+// scheduled for garbage collection.
+func keepHookResources(hooks []*release.Hook) {
+	for _, h := range hooks {
+		if h.LastRun.ResourceState != release.HookResourceCreated {
+			continue
+		}
+		h.LastRun.ResourceState = release.HookResourceKept
+		if h.TTLSeconds != nil && h.LastRun.Phase == release.HookPhaseSucceeded {
+			h.LastRun.ExpiresAt = h.LastRun.CompletedAt.Add(time.Duration(*h.TTLSeconds) * time.Second)
+		}
+	}
+}
+
+// hookResource references the resource created by a hook, in the namespace
+// it is built in.
+func hookResource(h *release.Hook, resources kube.ResourceList) *release.HookResource {
+	r := &release.HookResource{Kind: h.Kind, Name: h.Name}
+	if len(resources) > 0 {
+		r.Namespace = resources[0].Namespace
+	}
+	return r
+}
+
+// hookGroupTimeout returns the timeout of the weight group starting at the
+// first of the given hooks: the largest timeout set on a hook of the group, or
+// the timeout of the operation if none is set.
+func hookGroupTimeout(hooks []*release.Hook, timeout time.Duration) time.Duration {
+	var groupTimeout time.Duration
+	for _, h := range hooks {
+		if h.Weight != hooks[0].Weight {
+			break
+		}
+		if t := time.Duration(h.TimeoutSeconds) * time.Second; t > groupTimeout {
+			groupTimeout = t
+		}
+	}
+	if groupTimeout == 0 {
+		return timeout
+	}
+	return groupTimeout
+}
+
+// hookSuccessCondition parses the success condition of a hook: the name of a
+// status condition, such as "Ready", or a condition in the form of
+// --wait-for, such as "jsonpath={.status.phase}=Completed". It applies to the
+// hook resource unless it names another resource.
+func hookSuccessCondition(h *release.Hook) (kube.WaitCondition, error) {
+	s := h.SuccessCondition
+	if !strings.Contains(s, "=") {
+		s = "condition=" + s
+	}
+	cond, err := kube.ParseWaitCondition(s)
+	if err != nil {
+		return cond, errors.Wrapf(err, "invalid %s annotation on hook %s", release.HookSuccessConditionAnnotation, h.Path)
+	}
+	if cond.Kind == "" {
+		cond.Kind, cond.Name = h.Kind, h.Name
+	}
+	return cond, nil
+}
+
 // hookByWeight is a sorter for hooks
 type hookByWeight []*release.Hook
 
@@ -135,10 +265,27 @@ func (cfg *Configuration) deleteHookByPolicy(h *release.Hook, policy release.Hoo
 		if len(errs) > 0 {
 			return errors.New(joinErrors(errs))
 		}
+		h.LastRun.ResourceState = release.HookResourceDeleted
+		h.LastRun.DeletedBy = policy
 	}
 	return nil
 }
 
+// waitForHookDeletion waits for the resources of a hook deleted before its
+// creation to be gone, so that the hook can be created again under the same
+// name, if the client supports it.
+func (cfg *Configuration) waitForHookDeletion(h *release.Hook, timeout time.Duration) error {
+	client, ok := cfg.KubeClient.(kube.InterfaceRecreate)
+	if !ok || h.Kind == "CustomResourceDefinition" || !hookHasDeletePolicy(h, release.HookBeforeHookCreation) {
+		return nil
+	}
+	resources, err := cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), false)
+	if err != nil {
+		return errors.Wrapf(err, "unable to build kubernetes object for hook %s", h.Path)
+	}
+	return errors.Wrapf(client.WaitForDelete(resources, timeout), "hook %s", h.Path)
+}
+
 // hookHasDeletePolicy determines whether the defined hook deletion policy matches the hook deletion polices
 // supported by helm. If so, mark the hook as one should be deleted.
 func hookHasDeletePolicy(h *release.Hook, policy release.HookDeletePolicy) bool {
@@ -0,0 +1,204 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestHookGroupTimeout(t *testing.T) {
+	is := assert.New(t)
+
+	hooks := []*release.Hook{
+		{Name: "a", Weight: 0},
+		{Name: "b", Weight: 0, TimeoutSeconds: 30},
+		{Name: "c", Weight: 0, TimeoutSeconds: 60},
+		{Name: "d", Weight: 5},
+		{Name: "e", Weight: 10, TimeoutSeconds: 600},
+	}
+
+	is.Equal(60*time.Second, hookGroupTimeout(hooks, 5*time.Minute))
+	is.Equal(5*time.Minute, hookGroupTimeout(hooks[3:], 5*time.Minute))
+	is.Equal(10*time.Minute, hookGroupTimeout(hooks[4:], 5*time.Minute))
+}
+
+func TestExecHookRecordsResourceState(t *testing.T) {
+	is := assert.New(t)
+	cfg := actionConfigFixture(t)
+
+	deleted := &release.Hook{
+		Name:           "deleted",
+		Kind:           "Job",
+		Events:         []release.HookEvent{release.HookPostInstall},
+		DeletePolicies: []release.HookDeletePolicy{release.HookSucceeded},
+	}
+	kept := &release.Hook{
+		Name:   "kept",
+		Kind:   "Job",
+		Events: []release.HookEvent{release.HookPostInstall},
+	}
+	rel := releaseStub()
+	rel.Hooks = []*release.Hook{deleted, kept}
+
+	is.NoError(cfg.execHook(rel, release.HookPostInstall, time.Minute))
+
+	is.Equal(release.HookPhaseSucceeded, deleted.LastRun.Phase)
+	is.Equal(release.HookResourceDeleted, deleted.LastRun.ResourceState)
+	is.Equal(release.HookSucceeded, deleted.LastRun.DeletedBy)
+	is.Equal(release.HookPhaseSucceeded, kept.LastRun.Phase)
+	is.Equal(release.HookResourceKept, kept.LastRun.ResourceState)
+	is.Empty(kept.LastRun.DeletedBy)
+}
+
+func TestExecHookRecordsExecutions(t *testing.T) {
+	is := assert.New(t)
+	cfg := actionConfigFixture(t)
+	fakeClock := clock.NewFakeClock(time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC))
+	cfg.Clock = fakeClock
+
+	hook := &release.Hook{
+		Name:   "migrate",
+		Kind:   "Job",
+		Path:   "templates/migrate.yaml",
+		Events: []release.HookEvent{release.HookPreUpgrade},
+	}
+	rel := releaseStub()
+	rel.Version = 2
+	rel.Hooks = []*release.Hook{hook}
+
+	cfg.KubeClient = &kubefake.FailingKubeClient{
+		PrintingKubeClient:   kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		WatchUntilReadyError: errors.New("job failed: BackoffLimitExceeded"),
+	}
+	is.Error(cfg.execHook(rel, release.HookPreUpgrade, time.Minute))
+	is.Len(hook.Executions, 1)
+	failed := hook.Executions[0]
+	is.Equal(release.HookPhaseFailed, failed.Phase)
+	is.Equal(2, failed.Revision)
+	is.Equal(release.HookPreUpgrade, failed.Event)
+	is.Contains(failed.Message, "BackoffLimitExceeded")
+	is.Equal(&release.HookResource{Kind: "Job", Name: "migrate"}, failed.Resource)
+
+	// The next revision carries the history of the hook over
+	next := &release.Hook{Name: hook.Name, Kind: hook.Kind, Path: hook.Path, Events: hook.Events}
+	release.CarryHookExecutions(rel.Hooks, []*release.Hook{next})
+	rel.Version = 3
+	rel.Hooks = []*release.Hook{next}
+
+	fakeClock.Step(time.Minute)
+	cfg.KubeClient = &kubefake.PrintingKubeClient{Out: ioutil.Discard}
+	is.NoError(cfg.execHook(rel, release.HookPreUpgrade, time.Minute))
+	is.Len(next.Executions, 2)
+	is.Equal(failed, next.Executions[0])
+	is.Equal(release.HookPhaseSucceeded, next.Executions[1].Phase)
+	is.Equal(3, next.Executions[1].Revision)
+	is.Equal(next.LastRun, next.Executions[1])
+}
+
+func TestHookSuccessCondition(t *testing.T) {
+	is := assert.New(t)
+
+	h := &release.Hook{Name: "backup", Kind: "Backup", Path: "templates/backup.yaml", SuccessCondition: "Completed"}
+	cond, err := hookSuccessCondition(h)
+	is.NoError(err)
+	is.Equal("Backup/backup:condition=Completed=True", cond.String())
+
+	h.SuccessCondition = "jsonpath={.status.phase}=Done"
+	cond, err = hookSuccessCondition(h)
+	is.NoError(err)
+	is.Equal("Backup/backup:jsonpath={.status.phase}=Done", cond.String())
+
+	h.SuccessCondition = "Pod/check:condition=Ready"
+	cond, err = hookSuccessCondition(h)
+	is.NoError(err)
+	is.Equal("Pod/check:condition=Ready=True", cond.String())
+
+	h.SuccessCondition = "status=Done"
+	_, err = hookSuccessCondition(h)
+	is.Error(err)
+	is.Contains(err.Error(), "invalid helm.sh/hook-success-condition annotation on hook templates/backup.yaml")
+}
+
+var _ kube.InterfaceConditionWait = (*conditionKubeClient)(nil)
+
+// conditionKubeClient records how hook resources are waited for.
+type conditionKubeClient struct {
+	kubefake.PrintingKubeClient
+	conditions []kube.WaitCondition
+	watched    int
+}
+
+func (c *conditionKubeClient) WaitForCondition(_ kube.ResourceList, _ time.Duration, conditions ...kube.WaitCondition) error {
+	c.conditions = append(c.conditions, conditions...)
+	return nil
+}
+
+func (c *conditionKubeClient) WatchUntilReady(_ kube.ResourceList, _ time.Duration) error {
+	c.watched++
+	return nil
+}
+
+func TestExecHookSuccessCondition(t *testing.T) {
+	is := assert.New(t)
+	cfg := actionConfigFixture(t)
+	client := &conditionKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard}}
+	cfg.KubeClient = client
+
+	rel := releaseStub()
+	rel.Hooks = []*release.Hook{
+		{Name: "backup", Kind: "Backup", Events: []release.HookEvent{release.HookPreUpgrade}, SuccessCondition: "Completed"},
+		{Name: "migrate", Kind: "Job", Events: []release.HookEvent{release.HookPreUpgrade}},
+	}
+
+	is.NoError(cfg.execHook(rel, release.HookPreUpgrade, time.Minute))
+	is.Len(client.conditions, 1)
+	is.Equal("Backup/backup:condition=Completed=True", client.conditions[0].String())
+	is.Equal(1, client.watched)
+	is.Equal(release.HookPhaseSucceeded, rel.Hooks[0].LastRun.Phase)
+}
+
+// interfaceOnlyKubeClient only implements kube.Interface, without the
+// optional interfaces of the Kubernetes client.
+type interfaceOnlyKubeClient struct {
+	kube.Interface
+}
+
+func TestExecHookSuccessConditionUnsupported(t *testing.T) {
+	is := assert.New(t)
+	cfg := actionConfigFixture(t)
+	cfg.KubeClient = interfaceOnlyKubeClient{&kubefake.PrintingKubeClient{Out: ioutil.Discard}}
+
+	rel := releaseStub()
+	rel.Hooks = []*release.Hook{
+		{Name: "backup", Kind: "Backup", Path: "templates/backup.yaml", Events: []release.HookEvent{release.HookPreUpgrade}, SuccessCondition: "Completed"},
+	}
+
+	err := cfg.execHook(rel, release.HookPreUpgrade, time.Minute)
+	is.Error(err)
+	is.Contains(err.Error(), "does not support waiting for conditions")
+	is.Equal(release.HookPhaseFailed, rel.Hooks[0].LastRun.Phase)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// containerLists are the fields holding the containers of a pod spec.
+var containerLists = map[string]bool{
+	"containers":          true,
+	"initContainers":      true,
+	"ephemeralContainers": true,
+}
+
+// Image is a container image run by a resource.
+type Image struct {
+	// Image is the image reference, such as 'nginx:1.19'
+	Image string `json:"image"`
+	// Kind and Name identify the resource running the image
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Container is the name of the container running the image
+	Container string `json:"container,omitempty"`
+	// Source is the template the resource was rendered from, if known
+	Source string `json:"source,omitempty"`
+	// Hook is true if the resource is a hook
+	Hook bool `json:"hook,omitempty"`
+}
+
+// Images is the action for listing the container images of a release.
+//
+// It provides the implementation of 'helm images' for releases.
+type Images struct {
+	cfg *Configuration
+
+	// Initializing Version to 0 will list the images of the latest revision
+	// of the release.
+	Version int
+}
+
+// NewImages creates a new Images object with the given configuration.
+func NewImages(cfg *Configuration) *Images {
+	return &Images{
+		cfg: cfg,
+	}
+}
+
+// Run lists the container images of the given release.
+func (i *Images) Run(name string) ([]Image, error) {
+	if err := i.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	rel, err := i.cfg.releaseContent(name, i.Version)
+	if err != nil {
+		return nil, err
+	}
+	return ReleaseImages(rel)
+}
+
+// ReleaseImages lists the container images of the resources of a release,
+// then those of its hooks, in order. Rendering a chart with a client-only
+// Install gives a release whose images can be listed before it is installed.
+func ReleaseImages(rel *release.Release) ([]Image, error) {
+	images, err := ExtractImages(rel.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range rel.Hooks {
+		hookImages, err := ExtractImages(h.Manifest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "hook %s", h.Path)
+		}
+		for _, img := range hookImages {
+			img.Source = h.Path
+			img.Hook = true
+			images = append(images, img)
+		}
+	}
+	return images, nil
+}
+
+// ExtractImages lists the container images run by the resources of a
+// manifest, in order.
+//
+// The containers are looked for in the 'containers', 'initContainers' and
+// 'ephemeralContainers' lists found anywhere in a resource, so that the pod
+// templates of workloads, CronJobs and custom resources are all covered.
+func ExtractImages(manifest string) ([]Image, error) {
+	manifests, err := releaseutil.ParseManifests(manifest)
+	if err != nil {
+		return nil, err
+	}
+	var images []Image
+	for _, m := range manifests {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(m.Content), &obj); err != nil {
+			return nil, errors.Wrapf(err, "YAML parse error on %s", m.Name)
+		}
+		var name string
+		if m.Head.Metadata != nil {
+			name = m.Head.Metadata.Name
+		}
+		for _, c := range findContainers(obj) {
+			images = append(images, Image{
+				Image:     c.image,
+				Kind:      m.Head.Kind,
+				Name:      name,
+				Container: c.name,
+				Source:    m.Name,
+			})
+		}
+	}
+	return images, nil
+}
+
+// UniqueImages returns the image references of the given images once each,
+// sorted.
+func UniqueImages(images []Image) []string {
+	seen := map[string]bool{}
+	refs := []string{}
+	for _, img := range images {
+		if !seen[img.Image] {
+			seen[img.Image] = true
+			refs = append(refs, img.Image)
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+type container struct {
+	name  string
+	image string
+}
+
+// findContainers walks an object, in the order of its keys, for the
+// containers with an image.
+func findContainers(v interface{}) []container {
+	var found []container
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if list, ok := v[k].([]interface{}); ok && containerLists[k] {
+				for _, item := range list {
+					c, _ := item.(map[string]interface{})
+					if image, ok := c["image"].(string); ok && image != "" {
+						name, _ := c["name"].(string)
+						found = append(found, container{name: name, image: image})
+					}
+				}
+				continue
+			}
+			found = append(found, findContainers(v[k])...)
+		}
+	case []interface{}:
+		for _, item := range v {
+			found = append(found, findContainers(item)...)
+		}
+	}
+	return found
+}
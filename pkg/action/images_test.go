@@ -0,0 +1,96 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const imagesManifest = `---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: migrate
+        image: example.com/migrate:1.0
+      containers:
+      - name: web
+        image: nginx:1.19
+      - name: sidecar
+        image: example.com/proxy:2.1
+---
+# Source: web/templates/cronjob.yaml
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: cleanup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: cleanup
+            image: nginx:1.19
+---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`
+
+func TestExtractImages(t *testing.T) {
+	is := assert.New(t)
+
+	images, err := ExtractImages(imagesManifest)
+	is.NoError(err)
+	is.Equal([]Image{
+		{Image: "nginx:1.19", Kind: "Deployment", Name: "web", Container: "web", Source: "web/templates/deployment.yaml"},
+		{Image: "example.com/proxy:2.1", Kind: "Deployment", Name: "web", Container: "sidecar", Source: "web/templates/deployment.yaml"},
+		{Image: "example.com/migrate:1.0", Kind: "Deployment", Name: "web", Container: "migrate", Source: "web/templates/deployment.yaml"},
+		{Image: "nginx:1.19", Kind: "CronJob", Name: "cleanup", Container: "cleanup", Source: "web/templates/cronjob.yaml"},
+	}, images)
+	is.Equal([]string{"example.com/migrate:1.0", "example.com/proxy:2.1", "nginx:1.19"}, UniqueImages(images))
+}
+
+func TestReleaseImages(t *testing.T) {
+	is := assert.New(t)
+
+	rel := releaseStub()
+	rel.Manifest = "apiVersion: v1\nkind: Pod\nmetadata:\n  name: app\nspec:\n  containers:\n  - name: app\n    image: app:1.0\n"
+	rel.Hooks = []*release.Hook{{
+		Path:     "templates/test.yaml",
+		Manifest: "apiVersion: v1\nkind: Pod\nmetadata:\n  name: test\nspec:\n  containers:\n  - name: test\n    image: busybox\n",
+	}}
+
+	images, err := ReleaseImages(rel)
+	is.NoError(err)
+	is.Equal([]Image{
+		{Image: "app:1.0", Kind: "Pod", Name: "app", Container: "app"},
+		{Image: "busybox", Kind: "Pod", Name: "test", Container: "test", Source: "templates/test.yaml", Hook: true},
+	}, images)
+}
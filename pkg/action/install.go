@@ -35,6 +35,7 @@ import (
 	"k8s.io/cli-runtime/pkg/resource"
 	"sigs.k8s.io/yaml"
 
+	"helm.sh/helm/v3/internal/experimental/registry"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
@@ -43,9 +44,11 @@ import (
 	"helm.sh/helm/v3/pkg/kube"
 	kubefake "helm.sh/helm/v3/pkg/kube/fake"
 	"helm.sh/helm/v3/pkg/postrender"
+	"helm.sh/helm/v3/pkg/provenance"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/releaseutil"
 	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/secrets"
 	"helm.sh/helm/v3/pkg/storage"
 	"helm.sh/helm/v3/pkg/storage/driver"
 )
@@ -63,6 +66,10 @@ const releaseNameMaxLen = 53
 // since there can be filepath in front of it.
 const notesFileSuffix = "NOTES.txt"
 
+// structuredNotesFileName is the machine-readable counterpart of NOTES.txt. It
+// is rendered like NOTES.txt, parsed and stored with the release.
+const structuredNotesFileName = "NOTES.yaml"
+
 const defaultDirectoryPermission = 0755
 
 // Install performs an installation operation.
@@ -95,12 +102,99 @@ type Install struct {
 	// APIVersions allows a manual set of supported API Versions to be passed
 	// (for things like templating). These are ignored if ClientOnly is false
 	APIVersions chartutil.VersionSet
+	// CapabilitiesFromCluster takes the API versions and the Kubernetes
+	// version from the cluster in client only mode, instead of defaults. The
+	// cluster is only queried through the discovery API. It is ignored if
+	// ClientOnly is false.
+	CapabilitiesFromCluster bool
+	// CapabilitiesSnapshot replaces the capabilities of the cluster with
+	// those recorded by a snapshot, so that the chart renders as it would in
+	// the cluster the snapshot was taken of. It requires DryRun.
+	CapabilitiesSnapshot *chartutil.CapabilitiesSnapshot
+	// WaitFor lists conditions the release's resources must meet before the
+	// release is marked as successful. It is honored independently of Wait.
+	WaitFor []kube.WaitCondition
+	// WaitForEndpoints is the number of ready endpoints each Service of the
+	// release must have before the release is marked as successful. It is
+	// honored independently of Wait.
+	WaitForEndpoints int
+	// ValuesRefs are references to values overlays stored in registries, such
+	// as oci://registry/overlays/prod:1.2.0. They are merged in order beneath
+	// the values and recorded in the release, pinned to the digests pulled.
+	ValuesRefs []string
+	// OverridePause replaces a paused release.
+	OverridePause bool
+	// NoDeprecated refuses to install deprecated charts.
+	NoDeprecated bool
+	// StrictCompatibility refuses to install charts whose compatibility
+	// matrix does not support their app version or the Kubernetes version.
+	StrictCompatibility bool
+	// StrictValues refuses to install with values that the schema of the chart
+	// or of one of its subcharts deprecates, instead of warning about them.
+	StrictValues bool
+	// Incompatibilities is set by Run to the versions that the compatibility
+	// matrices of the chart and its subcharts do not support.
+	Incompatibilities []ChartIncompatibility
+	// AllowOwnershipTransfer adopts existing resources owned by another
+	// release or by other field managers instead of failing.
+	AllowOwnershipTransfer bool
+	// InstallValues are merged over the values when rendering, but recorded
+	// separately in the release so that later upgrades do not reuse them.
+	// 'helm upgrade --install' uses them for one-time bootstrap values.
+	InstallValues map[string]interface{}
+	// RecordDefaults records Wait, WaitForJobs, Timeout and Atomic with the
+	// release, as the defaults of its later upgrades and rollbacks.
+	RecordDefaults bool
+	// ServerDryRun, with DryRun, sends the resources and the hooks of the
+	// release to the API server in dry-run mode, so that they go through its
+	// admission policies and webhooks. It is ignored if ClientOnly is set.
+	ServerDryRun bool
+	// AdmissionResults is set by Run to the outcome of the server-side dry
+	// run of each resource, then of each hook, when ServerDryRun is set.
+	AdmissionResults []kube.AdmissionResult
+	// Features are the features of the client enabled by users, available to
+	// templates as .Helm.Features along with the built-in ones.
+	Features chartutil.Features
+	// StandardLabels adds the recommended app.kubernetes.io labels and the
+	// helm.sh/chart label to the resources of the release when installing
+	// them.
+	StandardLabels bool
+	// LabelConflicts tells what to do with the standard labels that resources
+	// already set to other values. Empty means LabelConflictKeep.
+	LabelConflicts LabelConflictPolicy
+	// CheckQuota compares the compute and storage resources requested by the
+	// release with the ResourceQuotas of the namespace before installing it,
+	// and fails if any of them would be exceeded. It is ignored if ClientOnly
+	// is set.
+	CheckQuota bool
+	// QuotaChecks is set by Run to the comparison with each quota when
+	// CheckQuota is set.
+	QuotaChecks []kube.QuotaCheck
+	// OnlySubcharts restricts the release to the given subcharts, designated
+	// by name or alias, and by dotted paths such as "backend.redis" when
+	// nested. The templates of the charts above them are not rendered, but
+	// their partials are. The subcharts must be enabled by their conditions.
+	OnlySubcharts []string
+	// SkipSubcharts excludes the given subcharts from the release.
+	SkipSubcharts []string
 	// Used by helm template to render charts with .Release.IsUpgrade. Ignored if Dry-Run is false
 	IsUpgrade bool
 	// Used by helm template to add the release as part of OutputDir path
 	// OutputDir/<ReleaseName>
 	UseReleaseName bool
-	PostRenderer   postrender.PostRenderer
+	// OutputLayout is the layout of the files written to OutputDir, one of
+	// the OutputLayout constants. It defaults to OutputLayoutTemplates.
+	OutputLayout string
+	PostRenderer postrender.PostRenderer
+	// SecretResolver replaces the secret references of the values by their
+	// secrets when the chart is rendered. References are left as is if nil.
+	SecretResolver *secrets.Resolver
+	// Result is set by Run to the outcome of the creation of the resources
+	// of the release, when they are sent to the cluster.
+	Result *kube.Result
+	// OutputFiles is set by Run to the paths of the files written to
+	// OutputDir by the resources and kustomize layouts.
+	OutputFiles []string
 }
 
 // ChartPathOptions captures common options used for controlling chart paths
@@ -115,6 +209,13 @@ type ChartPathOptions struct {
 	Username              string // --username
 	Verify                bool   // --verify
 	Version               string // --version
+
+	// RegistryClient pulls the charts with oci:// references. They cannot be
+	// located without one.
+	RegistryClient *registry.Client
+
+	// source is set by LocateChart when the chart is downloaded
+	source *release.ChartSource
 }
 
 // NewInstall creates a new Install object with the given configuration.
@@ -171,23 +272,40 @@ func (i *Install) installCRDs(crds []chart.CRD) error {
 //
 // If DryRun is set to true, this will prepare the release, but not install it
 func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.Release, error) {
+	if i.CapabilitiesSnapshot != nil && !i.DryRun {
+		return nil, errors.New("a capabilities snapshot can only be used with a dry run")
+	}
+
 	// Check reachability of cluster unless in client-only mode (e.g. `helm template` without `--validate`)
 	if !i.ClientOnly {
 		if err := i.cfg.KubeClient.IsReachable(); err != nil {
 			return nil, err
 		}
+		defer i.cfg.startOperation()()
 	}
 
 	if err := i.availableName(); err != nil {
 		return nil, err
 	}
 
+	if err := validateOutputLayout(i.OutputLayout); err != nil {
+		return nil, err
+	}
+
+	if i.NoDeprecated {
+		if err := checkDeprecations(chrt); err != nil {
+			return nil, err
+		}
+	} else if chrt != nil {
+		i.cfg.warnDeprecations(ChartDeprecations(chrt))
+	}
+
 	// Pre-install anything in the crd/ directory. We do this before Helm
 	// contacts the upstream server and builds the capabilities object.
 	if crds := chrt.CRDObjects(); !i.ClientOnly && !i.SkipCRDs && len(crds) > 0 {
 		// On dry run, bail here
 		if i.DryRun {
-			i.cfg.Log("WARNING: This chart or one of its subcharts contains CRDs. Rendering may fail or contain inaccuracies.")
+			i.cfg.warn(WarningClient, "This chart or one of its subcharts contains CRDs. Rendering may fail or contain inaccuracies.")
 		} else if err := i.installCRDs(crds); err != nil {
 			return nil, err
 		}
@@ -196,7 +314,17 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 	if i.ClientOnly {
 		// Add mock objects in here so it doesn't use Kube API server
 		// NOTE(bacongobbler): used for `helm template`
-		i.cfg.Capabilities = chartutil.DefaultCapabilities
+		if i.CapabilitiesSnapshot != nil {
+			i.cfg.Capabilities = i.CapabilitiesSnapshot.Capabilities()
+		} else if i.CapabilitiesFromCluster {
+			caps, err := i.clusterCapabilities()
+			if err != nil {
+				return nil, err
+			}
+			i.cfg.Capabilities = caps
+		} else {
+			i.cfg.Capabilities = chartutil.DefaultCapabilities
+		}
 		i.cfg.Capabilities.APIVersions = append(i.cfg.Capabilities.APIVersions, i.APIVersions...)
 		i.cfg.KubeClient = &kubefake.PrintingKubeClient{Out: ioutil.Discard}
 
@@ -206,8 +334,36 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 	} else if !i.ClientOnly && len(i.APIVersions) > 0 {
 		i.cfg.Log("API Version list given outside of client only mode, this list will be ignored")
 	}
+	if !i.ClientOnly && i.CapabilitiesSnapshot != nil {
+		i.cfg.Capabilities = i.CapabilitiesSnapshot.Capabilities()
+	}
 
-	if err := chartutil.ProcessDependencies(chrt, vals); err != nil {
+	overlayVals, valuesRefs, err := i.cfg.loadValuesOverlays(i.ValuesRefs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Values overlays and install-only values take part in rendering, but
+	// are recorded apart from the release's values.
+	renderVals := vals
+	if len(valuesRefs) > 0 {
+		renderVals = chartutil.MergeTables(overlayVals, renderVals)
+	}
+	if len(i.InstallValues) > 0 {
+		renderVals = chartutil.MergeTables(renderVals, i.InstallValues)
+	}
+
+	subcharts, err := newSubchartSelection(chrt, i.OnlySubcharts, i.SkipSubcharts)
+	if err != nil {
+		return nil, err
+	}
+	if err := chartutil.ProcessDependencies(chrt, renderVals); err != nil {
+		return nil, err
+	}
+	if err := subcharts.apply(chrt); err != nil {
+		return nil, err
+	}
+	if err := i.cfg.checkDeprecatedValues(chrt, renderVals, i.StrictValues); err != nil {
 		return nil, err
 	}
 
@@ -220,6 +376,13 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 		return nil, err
 	}
 
+	i.Incompatibilities = ChartIncompatibilities(chrt, caps.KubeVersion.String(), "")
+	if i.StrictCompatibility {
+		if err := checkCompatibility(i.Incompatibilities); err != nil {
+			return nil, err
+		}
+	}
+
 	//special case for helm template --is-upgrade
 	isUpgrade := i.IsUpgrade && i.DryRun
 	options := chartutil.ReleaseOptions{
@@ -228,16 +391,34 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 		Revision:  1,
 		IsInstall: !isUpgrade,
 		IsUpgrade: isUpgrade,
+		Features:  renderFeatures(i.Features, i.DryRun, i.ServerDryRun && !i.ClientOnly, i.ClientOnly),
 	}
-	valuesToRender, err := chartutil.ToRenderValues(chrt, vals, options, caps)
+	if !i.ClientOnly {
+		options.NamespaceMetadata = i.cfg.namespaceMetadata(i.Namespace)
+	}
+	valuesToRender, err := chartutil.ToRenderValues(chrt, renderVals, options, caps)
 	if err != nil {
 		return nil, err
 	}
+	if err := i.cfg.resolveSecrets(i.SecretResolver, valuesToRender); err != nil {
+		return nil, err
+	}
 
 	rel := i.createRelease(chrt, vals)
+	rel.InstallConfig = i.InstallValues
+	rel.ValuesRefs = valuesRefs
+	if i.RecordDefaults {
+		rel.Defaults = &release.Defaults{Wait: i.Wait, WaitForJobs: i.WaitForJobs, Timeout: i.Timeout, Atomic: i.Atomic}
+	}
+
+	// Resources are split into files once the whole manifest is rendered
+	outputDir := i.OutputDir
+	if splitsResources(i.OutputLayout) {
+		outputDir = ""
+	}
 
 	var manifestDoc *bytes.Buffer
-	rel.Hooks, manifestDoc, rel.Info.Notes, err = i.cfg.renderResources(chrt, valuesToRender, i.ReleaseName, i.OutputDir, i.SubNotes, i.UseReleaseName, i.IncludeCRDs, i.PostRenderer, i.DryRun)
+	rel.Hooks, manifestDoc, rel.Info.Notes, rel.Info.StructuredNotes, err = i.cfg.renderResources(chrt, valuesToRender, i.ReleaseName, outputDir, i.SubNotes, i.UseReleaseName, i.IncludeCRDs, i.PostRenderer, i.DryRun)
 	// Even for errors, attach this if available
 	if manifestDoc != nil {
 		rel.Manifest = manifestDoc.String()
@@ -249,6 +430,21 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 		return rel, err
 	}
 
+	if i.OutputDir != "" && splitsResources(i.OutputLayout) {
+		dir := i.OutputDir
+		if i.UseReleaseName {
+			dir = filepath.Join(dir, i.ReleaseName)
+		}
+		var hooks []*release.Hook
+		if !i.DisableHooks {
+			hooks = rel.Hooks
+		}
+		i.OutputFiles, err = writeResourceFiles(dir, i.OutputLayout, rel.Manifest, hooks)
+		if err != nil {
+			return rel, err
+		}
+	}
+
 	// Mark this release as in-progress
 	rel.SetStatus(release.StatusPendingInstall, "Initial install underway")
 
@@ -263,6 +459,15 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 	if err != nil {
 		return nil, err
 	}
+	if i.StandardLabels {
+		policy, err := ParseLabelConflictPolicy(string(i.LabelConflicts))
+		if err != nil {
+			return nil, err
+		}
+		if err := resources.Visit(setStandardLabelsVisitor(StandardLabels(rel.Name, rel.Chart.Metadata), policy)); err != nil {
+			return nil, err
+		}
+	}
 
 	// Install requires an extra validation step of checking that resources
 	// don't already exist before we actually create resources. If we continue
@@ -271,14 +476,32 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 	// deleting the release because the manifest will be pointing at that
 	// resource
 	if !i.ClientOnly && !isUpgrade && len(resources) > 0 {
-		toBeAdopted, err = existingResourceConflict(resources, rel.Name, rel.Namespace)
+		toBeAdopted, err = existingResourceConflict(resources, rel.Name, rel.Namespace, i.AllowOwnershipTransfer)
 		if err != nil {
 			return nil, errors.Wrap(err, "rendered manifests contain a resource that already exists. Unable to continue with install")
 		}
 	}
 
+	if i.CheckQuota && !i.ClientOnly {
+		// Adopted resources already count against the quotas
+		i.QuotaChecks, err = i.cfg.checkQuotas(i.Namespace, resources.Difference(toBeAdopted))
+		if err != nil {
+			return rel, err
+		}
+	}
+
 	// Bail out here if it is a dry run
 	if i.DryRun {
+		if i.ServerDryRun && !i.ClientOnly {
+			var events []release.HookEvent
+			if !i.DisableHooks {
+				events = []release.HookEvent{release.HookPreInstall, release.HookMigration, release.HookPostInstall}
+			}
+			i.AdmissionResults, err = i.cfg.serverDryRun(rel, toBeAdopted, resources, events...)
+			if err != nil {
+				return rel, err
+			}
+		}
 		rel.Info.Description = "Dry run complete"
 		return rel, nil
 	}
@@ -316,6 +539,10 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 		}
 	}
 
+	if err := setChecksums(rel); err != nil {
+		return rel, err
+	}
+
 	// Store the release in history before continuing (new in Helm 3). We always know
 	// that this is a create operation.
 	if err := i.cfg.Releases.Create(rel); err != nil {
@@ -324,25 +551,37 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 		// not working.
 		return rel, err
 	}
+	i.cfg.notify(EventInstallStarted, rel, "")
 
 	// pre-install hooks
 	if !i.DisableHooks {
 		if err := i.cfg.execHook(rel, release.HookPreInstall, i.Timeout); err != nil {
 			return i.failRelease(rel, fmt.Errorf("failed pre-install: %s", err))
 		}
+		if err := i.cfg.execMigrations(rel, i.Timeout); err != nil {
+			return i.failRelease(rel, fmt.Errorf("failed migrations: %s", err))
+		}
 	}
 
 	// At this point, we can do the install. Note that before we were detecting whether to
 	// do an update, but it's not clear whether we WANT to do an update if the re-use is set
 	// to true, since that is basically an upgrade operation.
 	if len(toBeAdopted) == 0 && len(resources) > 0 {
-		if _, err := i.cfg.KubeClient.Create(resources); err != nil {
+		res, err := i.cfg.KubeClient.Create(resources)
+		if err != nil {
 			return i.failRelease(rel, err)
 		}
+		i.Result = res
+		i.cfg.warnResults(rel, res)
+		recordGeneratedNames(rel, res.Created)
 	} else if len(resources) > 0 {
-		if _, err := i.cfg.KubeClient.Update(toBeAdopted, resources, false); err != nil {
+		res, err := i.cfg.KubeClient.Update(toBeAdopted, resources, false)
+		if err != nil {
 			return i.failRelease(rel, err)
 		}
+		i.Result = res
+		i.cfg.warnResults(rel, res)
+		recordGeneratedNames(rel, res.Created)
 	}
 
 	if i.Wait {
@@ -357,6 +596,14 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 		}
 	}
 
+	if err := i.cfg.waitForConditions(resources, i.Timeout, i.WaitFor); err != nil {
+		return i.failRelease(rel, err)
+	}
+
+	if err := i.cfg.waitForEndpoints(resources, i.Timeout, i.WaitForEndpoints); err != nil {
+		return i.failRelease(rel, err)
+	}
+
 	if !i.DisableHooks {
 		if err := i.cfg.execHook(rel, release.HookPostInstall, i.Timeout); err != nil {
 			return i.failRelease(rel, fmt.Errorf("failed post-install: %s", err))
@@ -379,12 +626,15 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 	if err := i.recordRelease(rel); err != nil {
 		i.cfg.Log("failed to record the release: %s", err)
 	}
+	i.cfg.notify(EventInstallSucceeded, rel, "")
 
 	return rel, nil
 }
 
 func (i *Install) failRelease(rel *release.Release, err error) (*release.Release, error) {
 	rel.SetStatus(release.StatusFailed, fmt.Sprintf("Release %q failed: %s", i.ReleaseName, err.Error()))
+	recordNotReady(rel, err)
+	i.cfg.notify(EventInstallFailed, rel, err.Error())
 	if i.Atomic {
 		i.cfg.Log("Install failed and atomic is set, uninstalling release")
 		uninstall := NewUninstall(i.cfg)
@@ -400,14 +650,29 @@ func (i *Install) failRelease(rel *release.Release, err error) (*release.Release
 	return rel, err
 }
 
+// clusterCapabilities harvests the capabilities of the cluster for client
+// only mode. Nothing but the discovery API is queried, along with the
+// CustomResourceDefinitions if the chart uses them.
+func (i *Install) clusterCapabilities() (*chartutil.Capabilities, error) {
+	i.cfg.Capabilities = nil
+	caps, err := i.cfg.getCapabilities()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the capabilities of the cluster")
+	}
+	caps.HelmVersion = chartutil.DefaultCapabilities.HelmVersion
+	return caps, nil
+}
+
 // availableName tests whether a name is available
 //
 // Roughly, this will return an error if name is
 //
 //	- empty
 //	- too long
+//	- rejected by the NameValidator of the configuration
 //	- already in use, and not deleted
 //	- used by a deleted release, and i.Replace is false
+//	- used by a paused release, and i.OverridePause is false
 func (i *Install) availableName() error {
 	start := i.ReleaseName
 	if start == "" {
@@ -418,6 +683,10 @@ func (i *Install) availableName() error {
 		return errors.Errorf("release name %q exceeds max length of %d", start, releaseNameMaxLen)
 	}
 
+	if err := i.cfg.validateNewName(start); err != nil {
+		return err
+	}
+
 	if i.DryRun {
 		return nil
 	}
@@ -429,6 +698,9 @@ func (i *Install) availableName() error {
 	releaseutil.Reverse(h, releaseutil.SortByRevision)
 	rel := h[0]
 
+	if err := i.cfg.checkPause(rel, i.OverridePause); err != nil {
+		return err
+	}
 	if st := rel.Info.Status; i.Replace && (st == release.StatusUninstalled || st == release.StatusFailed) {
 		return nil
 	}
@@ -448,6 +720,7 @@ func (i *Install) createRelease(chrt *chart.Chart, rawVals map[string]interface{
 			LastDeployed:  ts,
 			Status:        release.StatusUnknown,
 		},
+		Source:  i.ChartPathOptions.chartSource(chrt),
 		Version: 1,
 	}
 }
@@ -573,7 +846,8 @@ func (i *Install) NameAndChart(args []string) (string, string, error) {
 		base = base[0:idx]
 	}
 
-	return fmt.Sprintf("%s-%d", base, time.Now().Unix()), args[0], nil
+	name, err := i.cfg.generateName(base)
+	return name, args[0], err
 }
 
 // TemplateName renders a name template, returning the name or an error.
@@ -621,6 +895,7 @@ OUTER:
 // Order of resolution:
 // - relative to current working directory
 // - if path is absolute or begins with '.', error out here
+// - oci:// reference, pulled from a registry by tag or digest
 // - URL
 //
 // If 'verify' was set on ChartPathOptions, this will attempt to also verify the chart.
@@ -655,6 +930,7 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 		},
 		RepositoryConfig: settings.RepositoryConfig,
 		RepositoryCache:  settings.RepositoryCache,
+		RegistryClient:   c.RegistryClient,
 	}
 	if c.Verify {
 		dl.Verify = downloader.VerifyAlways
@@ -672,13 +948,17 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 		return "", err
 	}
 
-	filename, _, err := dl.DownloadTo(name, version, settings.RepositoryCache)
-	if err == nil {
-		lname, err := filepath.Abs(filename)
+	if registry.IsOCI(name) {
+		filename, manifestDigest, err := dl.DownloadOCITo(name, version, settings.RepositoryCache)
 		if err != nil {
 			return filename, err
 		}
-		return lname, nil
+		return c.downloaded(name, version, manifestDigest, filename)
+	}
+
+	filename, _, err := dl.DownloadTo(name, version, settings.RepositoryCache)
+	if err == nil {
+		return c.downloaded(name, version, "", filename)
 	} else if settings.Debug {
 		return filename, err
 	}
@@ -689,3 +969,34 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 	}
 	return filename, errors.Errorf("failed to download %q%s (hint: running `helm repo update` may help)", name, atVersion)
 }
+
+// downloaded records the source of a chart downloaded by LocateChart and
+// returns the absolute path of the chart.
+func (c *ChartPathOptions) downloaded(ref, version, manifestDigest, filename string) (string, error) {
+	lname, err := filepath.Abs(filename)
+	if err != nil {
+		return filename, err
+	}
+	digest, err := provenance.DigestFile(lname)
+	if err != nil {
+		return lname, err
+	}
+	c.source = &release.ChartSource{
+		Ref:            ref,
+		Constraint:     version,
+		Digest:         "sha256:" + digest,
+		ManifestDigest: manifestDigest,
+	}
+	return lname, nil
+}
+
+// chartSource returns the source of the chart located by LocateChart, with
+// the version it resolved to, or nil if the chart was not downloaded.
+func (c *ChartPathOptions) chartSource(ch *chart.Chart) *release.ChartSource {
+	if c.source == nil || ch == nil || ch.Metadata == nil {
+		return nil
+	}
+	source := *c.source
+	source.Version = ch.Metadata.Version
+	return &source
+}
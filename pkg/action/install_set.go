@@ -0,0 +1,261 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// InstallSetAPIVersion is the API version of install set manifests.
+const InstallSetAPIVersion = "v1"
+
+// InstallSetManifest describes a set of charts installed together, as
+// separate releases, with values shared between them.
+//
+//	apiVersion: v1
+//	values:
+//	  global:
+//	    domain: example.com
+//	releases:
+//	  - name: db
+//	    chart: bitnami/postgresql
+//	    version: 10.3.11
+//	  - name: app
+//	    chart: ./charts/app
+//	    dependsOn: [db]
+//	    values:
+//	      replicaCount: 2
+type InstallSetManifest struct {
+	APIVersion string `json:"apiVersion"`
+	// Values are shared by every release of the set. The values of a release
+	// take precedence over them.
+	Values map[string]interface{} `json:"values,omitempty"`
+	// Releases are installed in order, except that a release is always
+	// installed after the releases it depends on.
+	Releases []InstallSetRelease `json:"releases"`
+}
+
+// InstallSetRelease is a release of an install set.
+type InstallSetRelease struct {
+	// Name is the name of the release.
+	Name string `json:"name"`
+	// Chart is a reference to the chart of the release, as given to 'helm
+	// install': a path, a URL or a repository reference.
+	Chart string `json:"chart"`
+	// Version is the version constraint of the chart.
+	Version string `json:"version,omitempty"`
+	// Values are merged over the shared values of the set.
+	Values map[string]interface{} `json:"values,omitempty"`
+	// DependsOn lists the releases of the set to install before this one.
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// ParseInstallSetManifest parses and validates an install set manifest.
+func ParseInstallSetManifest(data []byte) (*InstallSetManifest, error) {
+	m := &InstallSetManifest{}
+	if err := yaml.UnmarshalStrict(data, m); err != nil {
+		return nil, errors.Wrap(err, "cannot parse the install set manifest")
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Validate checks that the manifest is well formed and that the dependencies
+// between its releases can be satisfied.
+func (m *InstallSetManifest) Validate() error {
+	if m.APIVersion != InstallSetAPIVersion {
+		return errors.Errorf("install set manifest: unsupported apiVersion %q, expected %q", m.APIVersion, InstallSetAPIVersion)
+	}
+	if len(m.Releases) == 0 {
+		return errors.New("install set manifest: no releases")
+	}
+	names := make(map[string]bool, len(m.Releases))
+	for _, r := range m.Releases {
+		if err := chartutil.ValidateReleaseName(r.Name); err != nil {
+			return errors.Wrapf(err, "install set manifest: release %q", r.Name)
+		}
+		if r.Chart == "" {
+			return errors.Errorf("install set manifest: release %q has no chart", r.Name)
+		}
+		if names[r.Name] {
+			return errors.Errorf("install set manifest: release %q is listed more than once", r.Name)
+		}
+		names[r.Name] = true
+	}
+	for _, r := range m.Releases {
+		for _, dep := range r.DependsOn {
+			if !names[dep] {
+				return errors.Errorf("install set manifest: release %q depends on unknown release %q", r.Name, dep)
+			}
+		}
+	}
+	_, err := m.InstallOrder()
+	return err
+}
+
+// InstallOrder returns the releases in the order they are installed: the
+// order of the manifest, except that every release comes after the releases
+// it depends on. It fails if the dependencies form a cycle.
+func (m *InstallSetManifest) InstallOrder() ([]InstallSetRelease, error) {
+	placed := make(map[string]bool, len(m.Releases))
+	order := make([]InstallSetRelease, 0, len(m.Releases))
+	for len(order) < len(m.Releases) {
+		progressed := false
+		for _, r := range m.Releases {
+			if placed[r.Name] || !dependenciesPlaced(r, placed) {
+				continue
+			}
+			placed[r.Name] = true
+			order = append(order, r)
+			progressed = true
+			break
+		}
+		if !progressed {
+			var cycle []string
+			for _, r := range m.Releases {
+				if !placed[r.Name] {
+					cycle = append(cycle, r.Name)
+				}
+			}
+			return nil, errors.Errorf("install set manifest: the dependencies of releases %v form a cycle", cycle)
+		}
+	}
+	return order, nil
+}
+
+func dependenciesPlaced(r InstallSetRelease, placed map[string]bool) bool {
+	for _, dep := range r.DependsOn {
+		if !placed[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// InstallSet is the action for installing the releases of an install set.
+//
+// It provides the implementation of 'helm install-set'. The releases are
+// installed one at a time, in dependency order, which with Wait makes every
+// release ready before the releases depending on it are installed.
+type InstallSet struct {
+	cfg *Configuration
+
+	Namespace       string
+	CreateNamespace bool
+	DryRun          bool
+	DisableHooks    bool
+	Wait            bool
+	WaitForJobs     bool
+	Timeout         time.Duration
+	// Atomic uninstalls the releases of the set installed so far, in reverse
+	// order, when a release fails to install, so that the set is installed
+	// completely or not at all.
+	Atomic bool
+	// LoadChart loads the chart of a release of the set. It is required.
+	LoadChart func(r InstallSetRelease) (*chart.Chart, error)
+}
+
+// NewInstallSet creates a new InstallSet object with the given configuration.
+func NewInstallSet(cfg *Configuration) *InstallSet {
+	return &InstallSet{
+		cfg: cfg,
+	}
+}
+
+// Run installs the releases of the manifest and returns them in the order
+// they were installed. vals are merged over the values of every release.
+//
+// The charts are all loaded before anything is installed. When a release
+// fails and Atomic is set, the releases installed before it are uninstalled
+// and no release is returned.
+func (s *InstallSet) Run(m *InstallSetManifest, vals map[string]interface{}) ([]*release.Release, error) {
+	if s.LoadChart == nil {
+		return nil, errors.New("install set: no chart loader")
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	order, err := m.InstallOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	charts := make([]*chart.Chart, len(order))
+	for i, r := range order {
+		if charts[i], err = s.LoadChart(r); err != nil {
+			return nil, errors.Wrapf(err, "install set: cannot load the chart of release %q", r.Name)
+		}
+	}
+
+	var installed []*release.Release
+	for i, r := range order {
+		s.cfg.Log("install set: installing release %s", r.Name)
+		rel, err := s.newInstall(r).Run(charts[i], chartutil.MergeTables(chartutil.MergeTables(m.Values, r.Values), vals))
+		if err != nil {
+			err = errors.Wrapf(err, "install set: release %q failed", r.Name)
+			if s.Atomic && !s.DryRun {
+				return nil, s.rollback(installed, err)
+			}
+			return installed, err
+		}
+		installed = append(installed, rel)
+	}
+	return installed, nil
+}
+
+func (s *InstallSet) newInstall(r InstallSetRelease) *Install {
+	i := NewInstall(s.cfg)
+	i.ReleaseName = r.Name
+	i.Version = r.Version
+	i.Namespace = s.Namespace
+	i.CreateNamespace = s.CreateNamespace
+	i.DryRun = s.DryRun
+	i.DisableHooks = s.DisableHooks
+	i.Wait = s.Wait
+	i.WaitForJobs = s.WaitForJobs
+	i.Timeout = s.Timeout
+	// An atomic set also removes the failed release itself.
+	i.Atomic = s.Atomic
+	return i
+}
+
+// rollback uninstalls the installed releases in reverse order after cause.
+func (s *InstallSet) rollback(installed []*release.Release, cause error) error {
+	s.cfg.Log("install set: %s, uninstalling the %d releases installed", cause, len(installed))
+	var errs []error
+	for i := len(installed) - 1; i >= 0; i-- {
+		u := NewUninstall(s.cfg)
+		u.DisableHooks = s.DisableHooks
+		u.Timeout = s.Timeout
+		if _, err := u.Run(installed[i].Name); err != nil {
+			errs = append(errs, errors.Wrapf(err, "release %q", installed[i].Name))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("%s, and rolling back the install set failed: %s", cause, joinErrors(errs))
+	}
+	return errors.Wrap(cause, "the install set has been rolled back due to atomic being set")
+}
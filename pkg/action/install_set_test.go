@@ -0,0 +1,168 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestParseInstallSetManifest(t *testing.T) {
+	m, err := ParseInstallSetManifest([]byte(`apiVersion: v1
+values:
+  global:
+    domain: example.com
+releases:
+  - name: app
+    chart: ./charts/app
+    dependsOn: [db, cache]
+  - name: db
+    chart: repo/postgresql
+    version: 10.3.11
+  - name: cache
+    chart: repo/redis
+    dependsOn: [db]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := m.InstallOrder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, r := range order {
+		names = append(names, r.Name)
+	}
+	if got := strings.Join(names, ","); got != "db,cache,app" {
+		t.Errorf("expected install order db,cache,app, got %s", got)
+	}
+}
+
+func TestParseInstallSetManifestErrors(t *testing.T) {
+	for name, manifest := range map[string]string{
+		"api version":    "apiVersion: v2\nreleases: [{name: a, chart: c}]",
+		"no releases":    "apiVersion: v1",
+		"no chart":       "apiVersion: v1\nreleases: [{name: a}]",
+		"duplicate":      "apiVersion: v1\nreleases: [{name: a, chart: c}, {name: a, chart: c}]",
+		"invalid name":   "apiVersion: v1\nreleases: [{name: A_, chart: c}]",
+		"unknown dep":    "apiVersion: v1\nreleases: [{name: a, chart: c, dependsOn: [b]}]",
+		"cycle":          "apiVersion: v1\nreleases: [{name: a, chart: c, dependsOn: [b]}, {name: b, chart: c, dependsOn: [a]}]",
+		"unknown fields": "apiVersion: v1\nreleases: [{name: a, chart: c, depends: [b]}]",
+	} {
+		if _, err := ParseInstallSetManifest([]byte(manifest)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func installSetManifest() *InstallSetManifest {
+	return &InstallSetManifest{
+		APIVersion: InstallSetAPIVersion,
+		Values:     map[string]interface{}{"shared": "yes", "name": "shared"},
+		Releases: []InstallSetRelease{
+			{Name: "frontend", Chart: "good", DependsOn: []string{"backend"}},
+			{Name: "backend", Chart: "good", Values: map[string]interface{}{"name": "backend"}},
+		},
+	}
+}
+
+func loadInstallSetChart(r InstallSetRelease) (*chart.Chart, error) {
+	if r.Chart == "bad" {
+		return buildChart(withSampleIncludingIncorrectTemplates()), nil
+	}
+	return buildChart(), nil
+}
+
+func TestInstallSet(t *testing.T) {
+	set := NewInstallSet(actionConfigFixture(t))
+	set.Namespace = "spaced"
+	set.LoadChart = loadInstallSetChart
+
+	rels, err := set.Run(installSetManifest(), map[string]interface{}{"override": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rels) != 2 || rels[0].Name != "backend" || rels[1].Name != "frontend" {
+		t.Fatalf("expected backend then frontend to be installed, got %v", rels)
+	}
+
+	backend := rels[0].Config
+	if backend["name"] != "backend" || backend["shared"] != "yes" || backend["override"] != true {
+		t.Errorf("unexpected values for backend: %v", backend)
+	}
+	if frontend := rels[1].Config; frontend["name"] != "shared" {
+		t.Errorf("expected the shared values for frontend, got %v", frontend)
+	}
+	for _, rel := range rels {
+		if rel.Info.Status != release.StatusDeployed {
+			t.Errorf("expected %s to be deployed, got %s", rel.Name, rel.Info.Status)
+		}
+	}
+}
+
+func TestInstallSetAtomic(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	set := NewInstallSet(cfg)
+	set.Namespace = "spaced"
+	set.Atomic = true
+	set.LoadChart = loadInstallSetChart
+
+	m := installSetManifest()
+	m.Releases = append(m.Releases, InstallSetRelease{Name: "broken", Chart: "bad", DependsOn: []string{"frontend"}})
+
+	rels, err := set.Run(m, nil)
+	if err == nil {
+		t.Fatal("expected the install set to fail")
+	}
+	if !strings.Contains(err.Error(), "rolled back") || !strings.Contains(err.Error(), `release "broken" failed`) {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if rels != nil {
+		t.Errorf("expected no release to be returned, got %v", rels)
+	}
+
+	deployed, err := cfg.Releases.ListDeployed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deployed) != 0 {
+		t.Errorf("expected every release to be uninstalled, got %d", len(deployed))
+	}
+}
+
+func TestInstallSetWithoutAtomic(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	set := NewInstallSet(cfg)
+	set.Namespace = "spaced"
+	set.LoadChart = loadInstallSetChart
+
+	m := installSetManifest()
+	m.Releases = append(m.Releases, InstallSetRelease{Name: "broken", Chart: "bad"})
+
+	rels, err := set.Run(m, nil)
+	if err == nil {
+		t.Fatal("expected the install set to fail")
+	}
+	if len(rels) != 2 {
+		t.Errorf("expected the two releases installed before the failure, got %d", len(rels))
+	}
+}
@@ -27,12 +27,20 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	kubetesting "k8s.io/client-go/testing"
 
 	"helm.sh/helm/v3/internal/test"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/kube"
 	kubefake "helm.sh/helm/v3/pkg/kube/fake"
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/secrets"
 	"helm.sh/helm/v3/pkg/storage/driver"
 	"helm.sh/helm/v3/pkg/time"
 )
@@ -112,6 +120,28 @@ func TestInstallReleaseWithValues(t *testing.T) {
 	is.Equal(expectedUserValues, rel.Config)
 }
 
+func TestInstallReleaseWithInstallValues(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.InstallValues = map[string]interface{}{
+		"auth": map[string]interface{}{"adminPassword": "s3cr3t"},
+	}
+	userVals := map[string]interface{}{
+		"auth": map[string]interface{}{"adminUser": "admin"},
+	}
+
+	res, err := instAction.Run(buildChart(withNotes("{{ .Values.auth.adminUser }}:{{ .Values.auth.adminPassword }}")), userVals)
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+
+	rel, err := instAction.cfg.Releases.Get(res.Name, res.Version)
+	is.NoError(err)
+	is.Equal("admin:s3cr3t", rel.Info.Notes)
+	is.Equal(userVals, rel.Config)
+	is.Equal(instAction.InstallValues, rel.InstallConfig)
+}
+
 func TestInstallReleaseClientOnly(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -218,6 +248,54 @@ func TestInstallRelease_WithChartAndDependencyAllNotes(t *testing.T) {
 	is.Equal(rel.Info.Description, "Install complete")
 }
 
+func TestInstallRelease_WithStructuredNotes(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ReleaseName = "with-notes"
+	instAction.SubNotes = true
+	notes := `endpoints:
+- name: web
+  url: https://{{ .Release.Name }}.example.com
+credentials:
+- name: admin
+  secret_name: {{ .Release.Name }}-admin
+  secret_key: password
+next_steps:
+- log in as admin
+`
+	res, err := instAction.Run(buildChart(withStructuredNotes(notes), withDependency(withName("child"), withStructuredNotes("next_steps: [child]"))), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+
+	rel, err := instAction.cfg.Releases.Get(res.Name, res.Version)
+	is.NoError(err)
+	is.NotContains(rel.Manifest, "next_steps")
+	is.Equal(&release.StructuredNotes{
+		Endpoints: []release.NotesEndpoint{
+			{Name: "web", URL: "https://with-notes.example.com"},
+		},
+		Credentials: []release.NotesCredential{
+			{Name: "admin", SecretName: "with-notes-admin", SecretKey: "password"},
+		},
+		NextSteps: []string{"log in as admin", "child"},
+	}, rel.Info.StructuredNotes)
+
+	instAction = installAction(t)
+	_, err = instAction.Run(buildChart(withStructuredNotes("endpoints:\n- name: web\n")), map[string]interface{}{})
+	is.Error(err)
+	is.Contains(err.Error(), "hello/templates/NOTES.yaml")
+
+	// Any other notes.yaml is an ordinary manifest
+	instAction = installAction(t)
+	ch := buildChart()
+	ch.Templates = append(ch.Templates, &chart.File{Name: "templates/notes.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: notes\n")})
+	res, err = instAction.Run(ch, map[string]interface{}{})
+	is.NoError(err)
+	is.Contains(res.Manifest, "name: notes")
+	is.Nil(res.Info.StructuredNotes)
+}
+
 func TestInstallRelease_DryRun(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -379,6 +457,56 @@ func TestInstallRelease_WaitForJobs(t *testing.T) {
 	is.Equal(res.Info.Status, release.StatusFailed)
 }
 
+func TestInstallRelease_WaitForCondition(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ReleaseName = "come-fail-away"
+	failer := instAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	failer.WaitForConditionError = fmt.Errorf("I timed out")
+	instAction.cfg.KubeClient = failer
+	instAction.WaitFor = []kube.WaitCondition{{ConditionType: "Ready", ConditionStatus: "True"}}
+	vals := map[string]interface{}{}
+
+	res, err := instAction.Run(buildChart(), vals)
+	is.Error(err)
+	is.Contains(res.Info.Description, "I timed out")
+	is.Equal(res.Info.Status, release.StatusFailed)
+}
+
+func TestInstallRelease_WaitForEndpoints(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ReleaseName = "come-fail-away"
+	failer := instAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	failer.WaitForEndpointsError = fmt.Errorf("I timed out")
+	instAction.cfg.KubeClient = failer
+	instAction.WaitForEndpoints = 1
+	vals := map[string]interface{}{}
+
+	res, err := instAction.Run(buildChart(), vals)
+	is.Error(err)
+	is.Contains(res.Info.Description, "I timed out")
+	is.Equal(res.Info.Status, release.StatusFailed)
+}
+
+func TestInstallRelease_RecordDefaults(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.Wait = true
+	instAction.WaitForJobs = true
+	res, err := instAction.Run(buildChart(), map[string]interface{}{})
+	is.NoError(err)
+	is.Nil(res.Defaults)
+
+	instAction = installAction(t)
+	instAction.RecordDefaults = true
+	instAction.Wait = true
+	instAction.WaitForJobs = true
+	res, err = instAction.Run(buildChart(), map[string]interface{}{})
+	is.NoError(err)
+	is.Equal(&release.Defaults{Wait: true, WaitForJobs: true, Timeout: instAction.Timeout}, res.Defaults)
+}
+
 func TestInstallRelease_Atomic(t *testing.T) {
 	is := assert.New(t)
 
@@ -565,6 +693,61 @@ func TestInstallOutputDirWithReleaseName(t *testing.T) {
 	is.True(os.IsNotExist(err))
 }
 
+func TestInstallReleaseOutputLayout(t *testing.T) {
+	is := assert.New(t)
+
+	for _, layout := range []string{OutputLayoutResources, OutputLayoutKustomize} {
+		instAction := installAction(t)
+		dir, err := ioutil.TempDir("", "output-layout")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		instAction.OutputDir = dir
+		instAction.OutputLayout = layout
+
+		_, err = instAction.Run(buildChart(withSampleTemplates(), withMultipleManifestTemplate()), map[string]interface{}{})
+		if err != nil {
+			t.Fatalf("Failed install with %s layout: %s", layout, err)
+		}
+
+		hookFile := "configmap_test-cm.yaml"
+		if layout == OutputLayoutKustomize {
+			hookFile = "hooks/configmap_test-cm.yaml"
+		}
+		for _, name := range []string{"role_schedule-agents.yaml", "rolebinding_schedule-agents.yaml", "hello.yaml", "with-partials.yaml", hookFile, "index.yaml"} {
+			_, err = os.Stat(filepath.Join(dir, name))
+			is.NoError(err, "%s layout: %s", layout, name)
+			is.Contains(instAction.OutputFiles, filepath.Join(dir, name), "%s layout: %s", layout, name)
+		}
+		_, err = os.Stat(filepath.Join(dir, "hello/templates"))
+		is.True(os.IsNotExist(err), "%s layout wrote the templates", layout)
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, "index.yaml"))
+		is.NoError(err)
+		is.Contains(string(data), "file: role_schedule-agents.yaml")
+		is.Contains(string(data), "source: hello/templates/rbac")
+
+		_, err = os.Stat(filepath.Join(dir, "kustomization.yaml"))
+		if layout != OutputLayoutKustomize {
+			is.True(os.IsNotExist(err))
+			continue
+		}
+		data, err = ioutil.ReadFile(filepath.Join(dir, "kustomization.yaml"))
+		is.NoError(err)
+		is.Contains(string(data), "- role_schedule-agents.yaml")
+		is.NotContains(string(data), "configmap_test-cm.yaml")
+	}
+}
+
+func TestInstallReleaseOutputLayoutInvalid(t *testing.T) {
+	instAction := installAction(t)
+	instAction.OutputLayout = "flat"
+	_, err := instAction.Run(buildChart(), map[string]interface{}{})
+	assert.EqualError(t, err, `unknown output layout "flat": must be one of templates, resources or kustomize`)
+}
+
 func TestNameAndChart(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -668,3 +851,118 @@ func TestNameAndChartGenerateName(t *testing.T) {
 		})
 	}
 }
+
+// cachedDiscovery makes a fake discovery client a cached one.
+type cachedDiscovery struct {
+	*fakediscovery.FakeDiscovery
+}
+
+func (cachedDiscovery) Fresh() bool { return true }
+func (cachedDiscovery) Invalidate() {}
+
+func TestInstallRelease_CapabilitiesFromCluster(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ClientOnly = true
+	instAction.DryRun = true
+	instAction.CapabilitiesFromCluster = true
+	instAction.APIVersions = []string{"extra.example.com/v1"}
+
+	dc := &fakediscovery.FakeDiscovery{
+		Fake:               &kubetesting.Fake{},
+		FakedServerVersion: &version.Info{GitVersion: "v1.99.0", Major: "1", Minor: "99"},
+	}
+	dc.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "widgets", Kind: "Widget"}},
+	}}
+	instAction.cfg.RESTClientGetter = genericclioptions.NewTestConfigFlags().WithDiscoveryClient(cachedDiscovery{dc})
+
+	chrt := buildChart()
+	chrt.Templates = append(chrt.Templates, &chart.File{
+		Name: "templates/capabilities",
+		Data: []byte(`kube: {{ .Capabilities.KubeVersion.Version }}
+widget: {{ .Capabilities.APIVersions.Has "example.com/v1/Widget" }}
+extra: {{ .Capabilities.APIVersions.Has "extra.example.com/v1" }}`),
+	})
+	rel, err := instAction.Run(chrt, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	is.Contains(rel.Manifest, "kube: v1.99.0")
+	is.Contains(rel.Manifest, "widget: true")
+	is.Contains(rel.Manifest, "extra: true")
+}
+
+func TestInstallRelease_ChartSource(t *testing.T) {
+	is := assert.New(t)
+
+	// Charts from local paths have no source
+	instAction := installAction(t)
+	res, err := instAction.Run(buildChart(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	is.Nil(res.Source)
+
+	instAction = installAction(t)
+	instAction.ChartPathOptions.source = &release.ChartSource{
+		Ref:        "example/hello",
+		Constraint: ">=0.1.0 <1.0.0",
+		Digest:     "sha256:1234",
+	}
+	res, err = instAction.Run(buildChart(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+
+	stored, err := instAction.cfg.Releases.Get(res.Name, res.Version)
+	is.NoError(err)
+	is.Equal(&release.ChartSource{
+		Ref:        "example/hello",
+		Constraint: ">=0.1.0 <1.0.0",
+		Version:    "0.1.0",
+		Digest:     "sha256:1234",
+	}, stored.Source)
+}
+
+func TestInstallRelease_Clock(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	now := time.Unix(1580702706, 0)
+	instAction.cfg.Clock = clock.NewFakePassiveClock(now.Time)
+
+	res, err := instAction.Run(buildChart(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	is.True(res.Info.FirstDeployed.Equal(now))
+	is.True(res.Info.LastDeployed.Equal(now))
+}
+
+type staticSecretBackend map[string]string
+
+func (b staticSecretBackend) Resolve(ref *secrets.Reference) (interface{}, error) {
+	return b[ref.Path+"#"+ref.Key], nil
+}
+
+func TestInstallRelease_SecretReferences(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.SecretResolver = secrets.NewResolver(secrets.Providers{{
+		Schemes: []string{"vault"},
+		Backend: staticSecretBackend{"secret/db#password": "hunter2"},
+	}})
+
+	ch := buildChart(withValues(map[string]interface{}{"user": "admin"}))
+	ch.Templates = append(ch.Templates, &chart.File{Name: "templates/secret", Data: []byte("password: {{ .Values.password }}\nuser: {{ .Values.user }}")})
+	vals := map[string]interface{}{"password": "ref+vault://secret/db#password"}
+
+	res, err := instAction.Run(ch, vals)
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	is.Contains(res.Manifest, "password: hunter2\nuser: admin")
+	is.Equal("ref+vault://secret/db#password", res.Config["password"])
+	is.NotContains(fmt.Sprint(res.Config), "hunter2")
+}
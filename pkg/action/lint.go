@@ -17,6 +17,7 @@ limitations under the License.
 package action
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -26,6 +27,7 @@ import (
 
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/lint"
+	"helm.sh/helm/v3/pkg/lint/rules"
 	"helm.sh/helm/v3/pkg/lint/support"
 )
 
@@ -36,6 +38,15 @@ type Lint struct {
 	Strict        bool
 	Namespace     string
 	WithSubcharts bool
+	// ValueReferences reports the values that are defined but never
+	// referenced by the templates, and those referenced but never defined.
+	ValueReferences bool
+	// CapabilitiesSnapshot renders the templates with the capabilities
+	// recorded by a snapshot instead of the default ones.
+	CapabilitiesSnapshot *chartutil.CapabilitiesSnapshot
+	// Matrix lints the chart with every combination of the values of its
+	// values matrix, ci/values-matrix.yaml, on top of the given values.
+	Matrix bool
 }
 
 // LintResult is the result of Lint
@@ -56,9 +67,13 @@ func (l *Lint) Run(paths []string, vals map[string]interface{}) *LintResult {
 	if l.Strict {
 		lowestTolerance = support.WarningSev
 	}
+	var caps *chartutil.Capabilities
+	if l.CapabilitiesSnapshot != nil {
+		caps = l.CapabilitiesSnapshot.Capabilities()
+	}
 	result := &LintResult{}
 	for _, path := range paths {
-		linter, err := lintChart(path, vals, l.Namespace, l.Strict)
+		linter, err := lintChart(path, vals, l.Namespace, l.Strict, l.ValueReferences, l.Matrix, caps)
 		if err != nil {
 			result.Errors = append(result.Errors, err)
 			continue
@@ -75,7 +90,7 @@ func (l *Lint) Run(paths []string, vals map[string]interface{}) *LintResult {
 	return result
 }
 
-func lintChart(path string, vals map[string]interface{}, namespace string, strict bool) (support.Linter, error) {
+func lintChart(path string, vals map[string]interface{}, namespace string, strict, valueReferences, matrix bool, caps *chartutil.Capabilities) (support.Linter, error) {
 	var chartPath string
 	linter := support.Linter{}
 
@@ -114,5 +129,68 @@ func lintChart(path string, vals map[string]interface{}, namespace string, stric
 		return linter, errors.Wrap(err, "unable to check Chart.yaml file in chart")
 	}
 
-	return lint.All(chartPath, vals, namespace, strict), nil
+	if matrix {
+		var err error
+		if linter, err = lintMatrix(chartPath, vals, namespace, strict, caps); err != nil {
+			return linter, err
+		}
+	} else {
+		linter = lint.AllWithCapabilities(chartPath, vals, namespace, strict, caps)
+	}
+	if valueReferences {
+		rules.ValueReferences(&linter)
+	}
+	return linter, nil
+}
+
+// lintMatrix lints the chart with each combination of its values matrix. The
+// messages of all the combinations are reported once, along with the
+// combinations they were found with, unless they were found with all of them.
+func lintMatrix(chartPath string, vals map[string]interface{}, namespace string, strict bool, caps *chartutil.Capabilities) (support.Linter, error) {
+	linter := support.Linter{}
+
+	data, err := ioutil.ReadFile(filepath.Join(chartPath, chartutil.ValuesMatrixFile))
+	if err != nil {
+		return linter, errors.Wrap(err, "unable to read the values matrix")
+	}
+	matrix, err := chartutil.ReadValuesMatrix(data)
+	if err != nil {
+		return linter, err
+	}
+	combinations, err := matrix.Combinations()
+	if err != nil {
+		return linter, err
+	}
+
+	var keys []string
+	messages := map[string]support.Message{}
+	foundWith := map[string][]string{}
+	for _, c := range combinations {
+		cvals, err := c.Merge(vals)
+		if err != nil {
+			return linter, err
+		}
+		l := lint.AllWithCapabilities(chartPath, cvals, namespace, strict, caps)
+		linter.ChartDir = l.ChartDir
+		if l.HighestSeverity > linter.HighestSeverity {
+			linter.HighestSeverity = l.HighestSeverity
+		}
+		for _, msg := range l.Messages {
+			key := msg.Error()
+			if _, ok := messages[key]; !ok {
+				keys = append(keys, key)
+				messages[key] = msg
+			}
+			foundWith[key] = append(foundWith[key], c.Name)
+		}
+	}
+
+	for _, key := range keys {
+		msg := messages[key]
+		if names := foundWith[key]; len(names) < len(combinations) {
+			msg.Path = fmt.Sprintf("%s (values matrix: %s)", msg.Path, strings.Join(names, "; "))
+		}
+		linter.Messages = append(linter.Messages, msg)
+	}
+	return linter, nil
 }
@@ -17,7 +17,10 @@ limitations under the License.
 package action
 
 import (
+	"strings"
 	"testing"
+
+	"helm.sh/helm/v3/pkg/lint/support"
 )
 
 var (
@@ -28,6 +31,7 @@ var (
 	chart2MultipleChartLint = "testdata/charts/multiplecharts-lint-chart-2"
 	corruptedTgzChart       = "testdata/charts/corrupted-compressed-chart.tgz"
 	chartWithNoTemplatesDir = "testdata/charts/chart-with-no-templates-dir"
+	chartWithValuesMatrix   = "testdata/charts/chart-with-values-matrix"
 )
 
 func TestLintChart(t *testing.T) {
@@ -78,7 +82,7 @@ func TestLintChart(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := lintChart(tt.chartPath, map[string]interface{}{}, namespace, strict)
+			_, err := lintChart(tt.chartPath, map[string]interface{}{}, namespace, strict, false, false, nil)
 			switch {
 			case err != nil && !tt.err:
 				t.Errorf("%s", err)
@@ -158,3 +162,32 @@ func TestLint_ChartWithWarnings(t *testing.T) {
 		}
 	})
 }
+
+func TestLint_Matrix(t *testing.T) {
+	testCharts := []string{chartWithValuesMatrix}
+	testLint := NewLint()
+	if result := testLint.Run(testCharts, values); len(result.Errors) > 0 {
+		t.Errorf("Expected no error without the values matrix, got %v", result.Errors)
+	}
+
+	testLint.Matrix = true
+	result := testLint.Run(testCharts, values)
+	if len(result.Errors) != 1 {
+		t.Fatalf("Expected one error, got %v", result.Errors)
+	}
+	var found bool
+	for _, msg := range result.Messages {
+		if msg.Severity == support.ErrorSev {
+			found = true
+			if msg.Path != "templates/ (values matrix: persistence=volume)" {
+				t.Errorf("Expected the error to be found with persistence=volume only, got %q", msg.Path)
+			}
+			if !strings.Contains(msg.Err.Error(), "persistence.size is required") {
+				t.Errorf("Unexpected error %q", msg.Err)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected an error message, got %v", result.Messages)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// execMigrations runs the migration hooks of the release that have not
+// succeeded yet for the version of its chart, so that a migration runs once
+// per chart version however many times the release is upgraded.
+func (cfg *Configuration) execMigrations(rl *release.Release, timeout time.Duration) error {
+	version := releaseChartVersion(rl)
+	var pending []*release.Hook
+	for _, h := range rl.Hooks {
+		if !h.HasEvent(release.HookMigration) {
+			continue
+		}
+		if migrationSucceeded(h, version) {
+			cfg.Log("skipping migration %s: it already succeeded for chart version %s", h.Path, version)
+			continue
+		}
+		pending = append(pending, h)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	return cfg.execHooks(rl, release.HookMigration, pending, timeout)
+}
+
+// migrationSucceeded tells whether the hook succeeded as a migration for the
+// chart version in a revision of the release.
+func migrationSucceeded(h *release.Hook, chartVersion string) bool {
+	for _, e := range h.Executions {
+		if e.Event == release.HookMigration && e.ChartVersion == chartVersion && e.Phase == release.HookPhaseSucceeded {
+			return true
+		}
+	}
+	return false
+}
+
+// failedMigrations returns an error naming the migration hooks of the release
+// whose last run did not succeed. A migration that failed or was interrupted
+// may have been partially applied, so it is not retried, nor are the
+// migrations of later chart versions run, until the failure has been looked
+// into.
+func failedMigrations(rl *release.Release) error {
+	var failed []string
+	for _, h := range rl.Hooks {
+		var last *release.HookExecution
+		for i := range h.Executions {
+			if h.Executions[i].Event == release.HookMigration {
+				last = &h.Executions[i]
+			}
+		}
+		if last != nil && last.Phase != release.HookPhaseSucceeded {
+			failed = append(failed, fmt.Sprintf("%s (%s for chart version %s in revision %d)", h.Path, strings.ToLower(last.Phase.String()), last.ChartVersion, last.Revision))
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("migrations of release %s did not complete: %s; check what they left behind before retrying them", rl.Name, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// checkMigrationResources ensures a migration hook is a Job, and disables the
+// retries of its Pods unless the Job sets a backoff limit, as retrying a
+// migration that failed partway is rarely safe.
+func checkMigrationResources(h *release.Hook, resources kube.ResourceList) error {
+	if h.Kind != "Job" {
+		return errors.Errorf("migration hook %s is a %s: migrations must be Jobs", h.Path, h.Kind)
+	}
+	for _, info := range resources {
+		u, ok := info.Object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		if _, found, _ := unstructured.NestedFieldNoCopy(u.Object, "spec", "backoffLimit"); found {
+			continue
+		}
+		// Errors can only occur if spec is not a map, in which case the
+		// resource is rejected on creation anyway.
+		_ = unstructured.SetNestedField(u.Object, int64(0), "spec", "backoffLimit")
+	}
+	return nil
+}
+
+// releaseChartVersion returns the version of the chart of the release.
+func releaseChartVersion(rl *release.Release) string {
+	if rl.Chart == nil || rl.Chart.Metadata == nil {
+		return ""
+	}
+	return rl.Chart.Metadata.Version
+}
@@ -0,0 +1,149 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func migrationHook() *release.Hook {
+	return &release.Hook{
+		Name:   "migrate",
+		Kind:   "Job",
+		Path:   "templates/migrate.yaml",
+		Events: []release.HookEvent{release.HookMigration},
+	}
+}
+
+func TestExecMigrationsOncePerChartVersion(t *testing.T) {
+	is := assert.New(t)
+	cfg := actionConfigFixture(t)
+
+	hook := migrationHook()
+	rel := releaseStub()
+	rel.Hooks = []*release.Hook{hook}
+
+	is.NoError(cfg.execMigrations(rel, time.Minute))
+	is.Len(hook.Executions, 1)
+	is.Equal(release.HookPhaseSucceeded, hook.Executions[0].Phase)
+	is.Equal(release.HookMigration, hook.Executions[0].Event)
+	is.Equal("0.1.0", hook.Executions[0].ChartVersion)
+
+	// An upgrade to the same chart version skips the migration
+	next := migrationHook()
+	release.CarryHookExecutions(rel.Hooks, []*release.Hook{next})
+	rel.Version = 2
+	rel.Hooks = []*release.Hook{next}
+	is.Empty(HookPlan(rel, release.HookMigration))
+	is.NoError(cfg.execMigrations(rel, time.Minute))
+	is.Len(next.Executions, 1)
+
+	// An upgrade to a new chart version runs it again
+	rel.Version = 3
+	rel.Chart.Metadata.Version = "0.2.0"
+	is.Len(HookPlan(rel, release.HookMigration), 1)
+	is.NoError(cfg.execMigrations(rel, time.Minute))
+	is.Len(next.Executions, 2)
+	is.Equal("0.2.0", next.Executions[1].ChartVersion)
+	is.Equal(3, next.Executions[1].Revision)
+}
+
+func TestFailedMigrations(t *testing.T) {
+	is := assert.New(t)
+	cfg := actionConfigFixture(t)
+	cfg.KubeClient = &kubefake.FailingKubeClient{
+		PrintingKubeClient:   kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		WatchUntilReadyError: errors.New("job failed: BackoffLimitExceeded"),
+	}
+
+	hook := migrationHook()
+	rel := releaseStub()
+	rel.Hooks = []*release.Hook{hook}
+	is.NoError(failedMigrations(rel))
+
+	is.Error(cfg.execMigrations(rel, time.Minute))
+	err := failedMigrations(rel)
+	is.Error(err)
+	is.Contains(err.Error(), "templates/migrate.yaml (failed for chart version 0.1.0 in revision 1)")
+
+	// Retrying it successfully clears the failure
+	cfg.KubeClient = &kubefake.PrintingKubeClient{Out: ioutil.Discard}
+	rel.Version = 2
+	is.NoError(cfg.execMigrations(rel, time.Minute))
+	is.NoError(failedMigrations(rel))
+}
+
+func TestUpgradeRefusesFailedMigrations(t *testing.T) {
+	is := assert.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	hook := migrationHook()
+	hook.Executions = []release.HookExecution{{
+		Event:        release.HookMigration,
+		Phase:        release.HookPhaseRunning,
+		ChartVersion: "0.1.0",
+		Revision:     1,
+	}}
+	rel.Hooks = []*release.Hook{hook}
+	upAction.cfg.Releases.Create(rel)
+
+	_, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	is.Error(err)
+	is.Contains(err.Error(), "migrations of release angry-panda did not complete")
+
+	upAction.RetryFailedMigrations = true
+	res, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	is.NoError(err)
+	is.Equal(release.StatusDeployed, res.Info.Status)
+}
+
+func TestCheckMigrationResources(t *testing.T) {
+	is := assert.New(t)
+
+	job := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata":   map[string]interface{}{"name": "migrate"},
+	}}
+	retried := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata":   map[string]interface{}{"name": "retried"},
+		"spec":       map[string]interface{}{"backoffLimit": int64(2)},
+	}}
+
+	is.NoError(checkMigrationResources(migrationHook(), kube.ResourceList{{Object: job}, {Object: retried}}))
+	limit, _, _ := unstructured.NestedInt64(job.Object, "spec", "backoffLimit")
+	is.Equal(int64(0), limit)
+	limit, _, _ = unstructured.NestedInt64(retried.Object, "spec", "backoffLimit")
+	is.Equal(int64(2), limit)
+
+	pod := migrationHook()
+	pod.Kind = "Pod"
+	is.EqualError(checkMigrationResources(pod, nil), "migration hook templates/migrate.yaml is a Pod: migrations must be Jobs")
+}
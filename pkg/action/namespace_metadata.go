@@ -0,0 +1,43 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/kube"
+)
+
+// namespaceMetadata fetches the labels and annotations of the namespace a
+// release is rendered for. It is empty when the Kubernetes client cannot fetch
+// namespaces, when the namespace does not exist yet or when it may not be
+// read, so that rendering does not depend on it.
+func (c *Configuration) namespaceMetadata(namespace string) chartutil.NamespaceMetadata {
+	client, ok := c.KubeClient.(kube.InterfaceNamespaces)
+	if !ok || namespace == "" {
+		return chartutil.NamespaceMetadata{}
+	}
+	ns, err := client.GetNamespace(namespace)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			c.warn(WarningClient, "unable to get the metadata of namespace %q: %s", namespace, err)
+		}
+		return chartutil.NamespaceMetadata{}
+	}
+	return chartutil.NamespaceMetadata{Labels: ns.Labels, Annotations: ns.Annotations}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"helm.sh/helm/v3/pkg/chart"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+)
+
+// namespaceKubeClient serves the namespaces it holds
+type namespaceKubeClient struct {
+	kubefake.PrintingKubeClient
+	namespaces map[string]*corev1.Namespace
+}
+
+func (c *namespaceKubeClient) GetNamespace(name string) (*corev1.Namespace, error) {
+	if ns, ok := c.namespaces[name]; ok {
+		return ns, nil
+	}
+	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, name)
+}
+
+func TestNamespaceMetadata(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+	is.Empty(config.namespaceMetadata("spaced"), "the client cannot fetch namespaces")
+
+	config.KubeClient = &namespaceKubeClient{
+		PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		namespaces: map[string]*corev1.Namespace{
+			"spaced": {ObjectMeta: metav1.ObjectMeta{
+				Name:        "spaced",
+				Labels:      map[string]string{"pod-security.kubernetes.io/enforce": "restricted"},
+				Annotations: map[string]string{"owner": "team-a"},
+			}},
+		},
+	}
+	md := config.namespaceMetadata("spaced")
+	is.Equal("restricted", md.Labels["pod-security.kubernetes.io/enforce"])
+	is.Equal("team-a", md.Annotations["owner"])
+	is.Empty(config.namespaceMetadata("missing"))
+}
+
+func TestInstallRelease_NamespaceMetadata(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.cfg.KubeClient = &namespaceKubeClient{
+		PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		namespaces: map[string]*corev1.Namespace{
+			"spaced": {ObjectMeta: metav1.ObjectMeta{
+				Name:   "spaced",
+				Labels: map[string]string{"istio-injection": "enabled"},
+			}},
+		},
+	}
+	chrt := buildChart()
+	chrt.Templates = append(chrt.Templates, &chart.File{
+		Name: "templates/sidecar",
+		Data: []byte(`sidecar: {{ index .Release.NamespaceMetadata.Labels "istio-injection" | default "disabled" }}`),
+	})
+
+	res, err := instAction.Run(chrt, map[string]interface{}{})
+	is.NoError(err)
+	is.Contains(res.Manifest, "sidecar: enabled")
+
+	instAction = installAction(t)
+	instAction.ReleaseName = "no-cluster"
+	instAction.ClientOnly = true
+	res, err = instAction.Run(chrt, map[string]interface{}{})
+	is.NoError(err)
+	is.Contains(res.Manifest, "sidecar: disabled")
+}
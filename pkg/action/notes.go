@@ -0,0 +1,40 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+
+	"helm.sh/helm/v3/pkg/diff"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// NotesDiff returns the unified diff of the rendered notes of two revisions of
+// a release, or an empty string if they are the same.
+func NotesDiff(from, to *release.Release) string {
+	return diff.Text(
+		fmt.Sprintf("%s revision %d", from.Name, from.Version),
+		fmt.Sprintf("%s revision %d", to.Name, to.Version),
+		releaseNotes(from), releaseNotes(to), 3)
+}
+
+func releaseNotes(rel *release.Release) string {
+	if rel.Info == nil {
+		return ""
+	}
+	return rel.Info.Notes
+}
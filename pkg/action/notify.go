@@ -0,0 +1,103 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/time"
+)
+
+// Types of release events
+const (
+	EventInstallStarted     = "install.started"
+	EventInstallSucceeded   = "install.succeeded"
+	EventInstallFailed      = "install.failed"
+	EventUpgradeStarted     = "upgrade.started"
+	EventUpgradeSucceeded   = "upgrade.succeeded"
+	EventUpgradeFailed      = "upgrade.failed"
+	EventRollbackSucceeded  = "rollback.succeeded"
+	EventRollbackFailed     = "rollback.failed"
+	EventUninstallSucceeded = "uninstall.succeeded"
+	EventUninstallFailed    = "uninstall.failed"
+	// EventDriftDetected is sent when resources of the manifest of a release
+	// are found missing from the cluster.
+	EventDriftDetected = "drift.detected"
+)
+
+// ReleaseEvent is a change of a release, reported to the Notifier of the
+// configuration.
+type ReleaseEvent struct {
+	// Type is one of the Event constants, such as EventInstallSucceeded.
+	Type         string `json:"type"`
+	Release      string `json:"release"`
+	Namespace    string `json:"namespace"`
+	Revision     int    `json:"revision,omitempty"`
+	Chart        string `json:"chart,omitempty"`
+	ChartVersion string `json:"chart_version,omitempty"`
+	AppVersion   string `json:"app_version,omitempty"`
+	// Status is the status of the release revision after the event.
+	Status string `json:"status,omitempty"`
+	// Message is the error of the failures, and the description of the
+	// release revision otherwise.
+	Message string    `json:"message,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// Notifier receives the events of releases as actions emit them, such as to
+// post them to webhooks. The actions do not fail if it does: its errors are
+// raised as warnings.
+type Notifier interface {
+	Notify(ReleaseEvent) error
+}
+
+// NotifierFunc is a function that implements Notifier.
+type NotifierFunc func(ReleaseEvent) error
+
+// Notify calls f(e).
+func (f NotifierFunc) Notify(e ReleaseEvent) error {
+	return f(e)
+}
+
+// notify sends an event about rel to the notifier, if there is one. The
+// message defaults to the description of the release.
+func (c *Configuration) notify(eventType string, rel *release.Release, message string) {
+	if c.Notifier == nil || rel == nil {
+		return
+	}
+	e := ReleaseEvent{
+		Type:      eventType,
+		Release:   rel.Name,
+		Namespace: rel.Namespace,
+		Revision:  rel.Version,
+		Message:   message,
+		Time:      c.Now(),
+	}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		e.Chart = rel.Chart.Metadata.Name
+		e.ChartVersion = rel.Chart.Metadata.Version
+		e.AppVersion = rel.Chart.Metadata.AppVersion
+	}
+	if rel.Info != nil {
+		e.Status = rel.Info.Status.String()
+		if e.Message == "" {
+			e.Message = rel.Info.Description
+		}
+	}
+	if err := c.Notifier.Notify(e); err != nil {
+		c.warn(WarningNotification, "unable to send the %s event of release %s: %s", eventType, rel.Name, err)
+	}
+}
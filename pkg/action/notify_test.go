@@ -0,0 +1,80 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+)
+
+func TestInstallReleaseNotify(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ReleaseName = "notified"
+	var events []ReleaseEvent
+	instAction.cfg.Notifier = NotifierFunc(func(e ReleaseEvent) error {
+		events = append(events, e)
+		return nil
+	})
+
+	_, err := instAction.Run(buildChart(), map[string]interface{}{})
+	is.NoError(err)
+	is.Len(events, 2)
+	is.Equal(EventInstallStarted, events[0].Type)
+	is.Equal(EventInstallSucceeded, events[1].Type)
+	is.Equal("notified", events[1].Release)
+	is.Equal("spaced", events[1].Namespace)
+	is.Equal(1, events[1].Revision)
+	is.Equal("hello", events[1].Chart)
+	is.Equal("deployed", events[1].Status)
+	is.Equal("Install complete", events[1].Message)
+}
+
+func TestInstallReleaseNotifyFailure(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ReleaseName = "come-fail-away"
+	failer := instAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	failer.WaitError = fmt.Errorf("I timed out")
+	instAction.Wait = true
+	var events []ReleaseEvent
+	instAction.cfg.Notifier = NotifierFunc(func(e ReleaseEvent) error {
+		events = append(events, e)
+		return fmt.Errorf("webhook unreachable")
+	})
+	var warnings []Warning
+	instAction.cfg.Warnings = WarningHandlerFunc(func(w Warning) {
+		if w.Reason == WarningNotification {
+			warnings = append(warnings, w)
+		}
+	})
+
+	_, err := instAction.Run(buildChart(), map[string]interface{}{})
+	is.EqualError(err, "I timed out")
+	is.Len(events, 2)
+	is.Equal(EventInstallFailed, events[1].Type)
+	is.Equal("failed", events[1].Status)
+	is.Equal("I timed out", events[1].Message)
+
+	// Failing to notify does not fail the action, but raises warnings.
+	is.Len(warnings, 2)
+	is.Contains(warnings[1].Message, "webhook unreachable")
+}
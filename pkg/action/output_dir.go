@@ -0,0 +1,210 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// Layouts of the files written to Install.OutputDir.
+const (
+	// OutputLayoutTemplates writes one file per template of the chart, under
+	// the path of the template. This is the default.
+	OutputLayoutTemplates = "templates"
+	// OutputLayoutResources writes one file per resource, named
+	// <kind>_<name>.yaml, along with an index of the files.
+	OutputLayoutResources = "resources"
+	// OutputLayoutKustomize writes one file per resource like
+	// OutputLayoutResources, with hooks in a hooks directory, and a
+	// kustomization.yaml listing the resources that are not hooks.
+	OutputLayoutKustomize = "kustomize"
+)
+
+// manifestIndexFileName is the name of the index written with the resources.
+const manifestIndexFileName = "index.yaml"
+
+// ManifestIndex lists the files written to the output directory by the
+// resources and kustomize layouts.
+type ManifestIndex struct {
+	Resources []ManifestIndexEntry `json:"resources"`
+}
+
+// ManifestIndexEntry describes a file holding a single resource.
+type ManifestIndexEntry struct {
+	// File is the path of the file relative to the output directory
+	File       string `json:"file"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	// Source is the template the resource was rendered from
+	Source string `json:"source"`
+	// Hook lists the hook events of the resource if it is a hook
+	Hook []release.HookEvent `json:"hook,omitempty"`
+}
+
+func validateOutputLayout(layout string) error {
+	switch layout {
+	case "", OutputLayoutTemplates, OutputLayoutResources, OutputLayoutKustomize:
+		return nil
+	}
+	return errors.Errorf("unknown output layout %q: must be one of %s, %s or %s", layout, OutputLayoutTemplates, OutputLayoutResources, OutputLayoutKustomize)
+}
+
+// splitsResources returns true if the layout writes one file per resource.
+func splitsResources(layout string) bool {
+	return layout == OutputLayoutResources || layout == OutputLayoutKustomize
+}
+
+// writeResourceFiles writes each resource of a rendered manifest, and each
+// hook unless they are nil, to a file of its own in dir, and writes an index
+// of the files. With the kustomize layout, hooks are written to a hooks
+// directory, and a kustomization.yaml lists the other resources. It returns
+// the paths of the files written.
+func writeResourceFiles(dir, layout, manifest string, hooks []*release.Hook) ([]string, error) {
+	type doc struct {
+		content string
+		hook    []release.HookEvent
+	}
+	var docs []doc
+	split := releaseutil.SplitManifests(manifest)
+	keys := make([]string, 0, len(split))
+	for k := range split {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+	for _, k := range keys {
+		docs = append(docs, doc{content: split[k]})
+	}
+	for _, h := range hooks {
+		docs = append(docs, doc{
+			content: fmt.Sprintf("# Source: %s\n%s", h.Path, strings.TrimSpace(h.Manifest)),
+			hook:    h.Events,
+		})
+	}
+
+	index := &ManifestIndex{}
+	var files, kustomizeResources []string
+	written := map[string]bool{}
+	for _, d := range docs {
+		var head struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Metadata   struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(d.content), &head); err != nil {
+			return files, errors.Wrap(err, "unable to parse rendered resource")
+		}
+		source := ""
+		if strings.HasPrefix(d.content, "# Source: ") {
+			source = strings.TrimPrefix(strings.SplitN(d.content, "\n", 2)[0], "# Source: ")
+		}
+
+		file := resourceFileName(head.Kind, head.Metadata.Name, source, written)
+		if layout == OutputLayoutKustomize {
+			if len(d.hook) > 0 {
+				file = filepath.Join("hooks", file)
+			} else {
+				kustomizeResources = append(kustomizeResources, file)
+			}
+		}
+		filename, err := writeOutputFile(dir, file, []byte(d.content+"\n"))
+		if err != nil {
+			return files, err
+		}
+		files = append(files, filename)
+		index.Resources = append(index.Resources, ManifestIndexEntry{
+			File:       filepath.ToSlash(file),
+			APIVersion: head.APIVersion,
+			Kind:       head.Kind,
+			Name:       head.Metadata.Name,
+			Namespace:  head.Metadata.Namespace,
+			Source:     source,
+			Hook:       d.hook,
+		})
+	}
+
+	data, err := yaml.Marshal(index)
+	if err != nil {
+		return files, err
+	}
+	filename, err := writeOutputFile(dir, manifestIndexFileName, data)
+	if err != nil {
+		return files, err
+	}
+	files = append(files, filename)
+
+	if layout == OutputLayoutKustomize {
+		data, err := yaml.Marshal(map[string]interface{}{
+			"apiVersion": "kustomize.config.k8s.io/v1beta1",
+			"kind":       "Kustomization",
+			"resources":  kustomizeResources,
+		})
+		if err != nil {
+			return files, err
+		}
+		filename, err := writeOutputFile(dir, "kustomization.yaml", data)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, filename)
+	}
+	return files, nil
+}
+
+// resourceFileName returns a file name for a resource that is not in use yet,
+// such as deployment_web.yaml, and marks it as used. Resources without a kind
+// or a name are named after their template.
+func resourceFileName(kind, name, source string, used map[string]bool) string {
+	base := strings.ToLower(kind) + "_" + name
+	if kind == "" || name == "" {
+		base = strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	}
+	if base == "" || base == "." {
+		base = "resource"
+	}
+	file := base + ".yaml"
+	for i := 2; used[file]; i++ {
+		file = fmt.Sprintf("%s_%d.yaml", base, i)
+	}
+	used[file] = true
+	return file
+}
+
+// writeOutputFile writes data to the file name of dir, creating the
+// directories it is in, and returns the path of the file.
+func writeOutputFile(dir, name string, data []byte) (string, error) {
+	filename := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(filename), defaultDirectoryPermission); err != nil {
+		return "", err
+	}
+	return filename, ioutil.WriteFile(filename, data, 0644)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Plan is the action for computing what an upgrade of a release would do,
+// for controllers reconciling releases with a desired chart and values.
+//
+// It only reads from the cluster and the release storage: nothing is
+// created, changed or recorded. The options of the upgrade planned, such as
+// ReuseValues or DisableHooks, are those of the embedded Upgrade.
+type Plan struct {
+	*Upgrade
+}
+
+// NewPlan creates a new Plan object with the given configuration.
+func NewPlan(cfg *Configuration) *Plan {
+	return &Plan{
+		Upgrade: NewUpgrade(cfg),
+	}
+}
+
+// ReleasePlan is the full plan of an upgrade: the changes of the resources
+// and the hooks it would run, along with the revision it would create.
+type ReleasePlan struct {
+	*UpgradePlan
+	// Manifest is the rendered manifest of the revision the upgrade would
+	// create, and Notes its rendered notes.
+	Manifest string `json:"manifest"`
+	Notes    string `json:"notes,omitempty"`
+	// Unchanged is set if the upgrade would deploy the same chart, values
+	// and manifests as the current release.
+	Unchanged bool `json:"unchanged"`
+	// Current is the release the upgrade is computed against, and Release
+	// the revision the upgrade would create, with its hooks. Release is
+	// never stored.
+	Current *release.Release `json:"-"`
+	Release *release.Release `json:"-"`
+}
+
+// UpgradeNeeded returns whether the upgrade would change the release, so
+// reconcilers can skip the upgrades that would not.
+func (p *ReleasePlan) UpgradeNeeded() bool {
+	return !p.Unchanged
+}
+
+// Run computes the plan of upgrading the named release with the given chart
+// and values.
+func (p *Plan) Run(name string, chart *chart.Chart, vals map[string]interface{}) (*ReleasePlan, error) {
+	current, upgraded, up, err := p.plan(name, chart, vals)
+	if err != nil {
+		return nil, err
+	}
+	unchanged, err := isUnchanged(current, upgraded)
+	if err != nil {
+		return nil, err
+	}
+	return &ReleasePlan{
+		UpgradePlan: up,
+		Manifest:    upgraded.Manifest,
+		Notes:       upgraded.Info.Notes,
+		Unchanged:   unchanged,
+		Current:     current,
+		Release:     upgraded,
+	}, nil
+}
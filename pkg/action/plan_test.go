@@ -0,0 +1,74 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestPlan(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+
+	rel := releaseStub()
+	rel.Namespace = "spaced"
+	rel.Manifest = planCurrentManifest
+	if err := config.Releases.Create(rel); err != nil {
+		t.Fatal(err)
+	}
+
+	ch := buildChart()
+	ch.Templates = append(ch.Templates, &chart.File{Name: "templates/resources.yaml", Data: []byte(planUpgradedTemplate)})
+	vals := map[string]interface{}{"key": "new"}
+
+	planAction := NewPlan(config)
+	plan, err := planAction.Run(rel.Name, ch, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.True(plan.UpgradeNeeded())
+	is.Equal(2, plan.Revision)
+	is.Equal(2, plan.Release.Version)
+	is.Equal(1, plan.Current.Version)
+	is.Contains(plan.Manifest, "key: new")
+	is.Equal(plan.Release.Manifest, plan.Manifest)
+	is.Equal(2, plan.Count(ChangeCreate), "the Service and the generated Job are created")
+	is.NotEmpty(plan.Diffs)
+
+	history, err := config.Releases.History(rel.Name)
+	is.NoError(err)
+	is.Len(history, 1, "planning must not record a revision")
+
+	// Once the planned revision is deployed, planning the same chart and
+	// values again needs no upgrade
+	plan.Release.Info.Status = release.StatusDeployed
+	if err := config.Releases.Create(plan.Release); err != nil {
+		t.Fatal(err)
+	}
+	plan, err = planAction.Run(rel.Name, ch, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.False(plan.UpgradeNeeded())
+	is.Equal(3, plan.Revision)
+	is.Equal(3, plan.Count(ChangeNone))
+}
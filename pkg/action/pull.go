@@ -18,13 +18,17 @@ package action
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/pkg/errors"
 
+	"helm.sh/helm/v3/internal/experimental/registry"
+	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/downloader"
@@ -45,6 +49,10 @@ type Pull struct {
 	VerifyLater bool
 	UntarDir    string
 	DestDir     string
+	// ExpandDeps expands the dependencies of an untarred chart into its
+	// charts/ directory, downloading those it does not vendor, so that the
+	// chart and its dependencies can be edited right away.
+	ExpandDeps bool
 }
 
 // NewPull creates a new Pull object with the given configuration.
@@ -56,6 +64,10 @@ func NewPull() *Pull {
 func (p *Pull) Run(chartRef string) (string, error) {
 	var out strings.Builder
 
+	if p.ExpandDeps && !p.Untar {
+		return out.String(), errors.New("expanding the dependencies of a chart requires untarring it")
+	}
+
 	c := downloader.ChartDownloader{
 		Out:     &out,
 		Keyring: p.Keyring,
@@ -68,6 +80,7 @@ func (p *Pull) Run(chartRef string) (string, error) {
 		},
 		RepositoryConfig: p.Settings.RepositoryConfig,
 		RepositoryCache:  p.Settings.RepositoryCache,
+		RegistryClient:   p.RegistryClient,
 	}
 
 	if p.Verify {
@@ -116,11 +129,19 @@ func (p *Pull) Run(chartRef string) (string, error) {
 			ud = filepath.Join(p.DestDir, ud)
 		}
 		// Let udCheck to check conflict file/dir without replacing ud when untarDir is the current directory(.).
+		_, chartName := filepath.Split(chartRef)
+		if registry.IsOCI(chartRef) {
+			// Drop the tag and the digest of the reference
+			r, err := registry.ParseChartReference(chartRef)
+			if err != nil {
+				return out.String(), err
+			}
+			chartName = path.Base(r.Repo)
+		}
 		udCheck := ud
 		if udCheck == "." {
-			_, udCheck = filepath.Split(chartRef)
+			udCheck = chartName
 		} else {
-			_, chartName := filepath.Split(chartRef)
 			udCheck = filepath.Join(udCheck, chartName)
 		}
 		if _, err := os.Stat(udCheck); err != nil {
@@ -132,7 +153,85 @@ func (p *Pull) Run(chartRef string) (string, error) {
 			return out.String(), errors.Errorf("failed to untar: a file or directory with the name %s already exists", udCheck)
 		}
 
-		return out.String(), chartutil.ExpandFile(ud, saved)
+		if err := chartutil.ExpandFile(ud, saved); err != nil {
+			return out.String(), err
+		}
+		if p.ExpandDeps {
+			ch, err := loader.Load(saved)
+			if err != nil {
+				return out.String(), err
+			}
+			if err := p.expandDependencies(&out, filepath.Join(ud, ch.Name())); err != nil {
+				return out.String(), err
+			}
+		}
 	}
 	return out.String(), nil
 }
+
+// expandDependencies downloads the dependencies the chart in chartDir does
+// not vendor, then expands the archives of its charts/ directory.
+func (p *Pull) expandDependencies(out io.Writer, chartDir string) error {
+	ch, err := loader.LoadDir(chartDir)
+	if err != nil {
+		return err
+	}
+	if reqs := ch.Metadata.Dependencies; reqs != nil {
+		if err := CheckDependencies(ch, reqs); err != nil {
+			man := &downloader.Manager{
+				Out:              out,
+				ChartPath:        chartDir,
+				Keyring:          p.Keyring,
+				Getters:          getter.All(p.Settings),
+				RepositoryConfig: p.Settings.RepositoryConfig,
+				RepositoryCache:  p.Settings.RepositoryCache,
+				Debug:            p.Settings.Debug,
+				RegistryClient:   p.RegistryClient,
+			}
+			if err := man.Build(); err != nil {
+				return errors.Wrapf(err, "unable to download the dependencies of %s", ch.Name())
+			}
+		}
+	}
+	return expandChartArchives(filepath.Join(chartDir, chartutil.ChartsDir))
+}
+
+// expandChartArchives replaces the chart archives of a charts/ directory by
+// the charts they hold, recursively.
+func expandChartArchives(dir string) error {
+	archives, err := filepath.Glob(filepath.Join(dir, "*.tgz"))
+	if err != nil {
+		return err
+	}
+	for _, archive := range archives {
+		ch, err := loader.Load(archive)
+		if err != nil {
+			return errors.Wrapf(err, "unable to load %s", archive)
+		}
+		if _, err := os.Stat(filepath.Join(dir, ch.Name())); err == nil {
+			return errors.Errorf("unable to expand %s: %s already exists", archive, filepath.Join(dir, ch.Name()))
+		}
+		if err := chartutil.ExpandFile(dir, archive); err != nil {
+			return err
+		}
+		if err := os.Remove(archive); err != nil {
+			return err
+		}
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			if err := expandChartArchives(filepath.Join(dir, e.Name(), chartutil.ChartsDir)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestExpandChartArchives(t *testing.T) {
+	dir := ensure.TempDir(t)
+	defer os.RemoveAll(dir)
+
+	newChart := func(name string) *chart.Chart {
+		return &chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: name, Version: "0.1.0"}}
+	}
+	if err := chartutil.SaveDir(newChart("parent"), dir); err != nil {
+		t.Fatal(err)
+	}
+	chartsDir := filepath.Join(dir, "parent", "charts")
+	if _, err := chartutil.Save(newChart("packaged"), chartsDir); err != nil {
+		t.Fatal(err)
+	}
+	// A vendored chart directory holding the archive of its own dependency
+	unpacked := newChart("unpacked")
+	unpacked.AddDependency(newChart("nested"))
+	if err := chartutil.SaveDir(unpacked, chartsDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := expandChartArchives(chartsDir); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range []string{
+		"packaged/Chart.yaml",
+		"unpacked/Chart.yaml",
+		"unpacked/charts/nested/Chart.yaml",
+	} {
+		if _, err := os.Stat(filepath.Join(chartsDir, f)); err != nil {
+			t.Errorf("Expected %s to be expanded: %s", f, err)
+		}
+	}
+	for _, f := range []string{"packaged-0.1.0.tgz", "unpacked/charts/nested-0.1.0.tgz"} {
+		if _, err := os.Stat(filepath.Join(chartsDir, f)); !os.IsNotExist(err) {
+			t.Errorf("Expected archive %s to be removed", f)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"helm.sh/helm/v3/internal/experimental/registry"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// Push is the action for pushing a chart to a registry in one step, packaging
+// chart directories in memory.
+//
+// It provides the implementation of 'helm push'.
+type Push struct {
+	cfg *Configuration
+
+	// ModTime is the time the entries of the archive of a chart directory are
+	// stamped with. A fixed time makes the pushed archive, and thus its digest,
+	// reproducible. If it is zero, the current time is used.
+	ModTime time.Time
+}
+
+// NewPush creates a new Push object with the given configuration.
+func NewPush(cfg *Configuration) *Push {
+	return &Push{
+		cfg: cfg,
+	}
+}
+
+// Run pushes the chart at path, a chart directory or a packaged chart, to
+// the given reference. If the reference has no tag, the chart version is used.
+func (p *Push) Run(out io.Writer, path, ref string) error {
+	r, err := registry.ParseReference(strings.TrimPrefix(ref, registry.OCIScheme))
+	if err != nil {
+		return err
+	}
+	ch, archive, err := p.packageChart(path)
+	if err != nil {
+		return err
+	}
+	if r.Tag == "" {
+		r.Tag = ch.Metadata.Version
+	}
+	_, err = p.cfg.RegistryClient.PushChartArchive(r, ch, archive)
+	return err
+}
+
+// packageChart loads the chart at path and returns its archive: the file
+// itself for packaged charts, or the chart packaged in memory for chart
+// directories.
+func (p *Push) packageChart(path string) (*chart.Chart, []byte, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !fi.IsDir() {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		ch, err := loader.LoadArchive(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, err
+		}
+		return ch, data, nil
+	}
+
+	ch, err := loader.LoadDir(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if reqs := ch.Metadata.Dependencies; reqs != nil {
+		if err := CheckDependencies(ch, reqs); err != nil {
+			return nil, nil, err
+		}
+	}
+	var buf bytes.Buffer
+	if err := chartutil.Archive(ch, &buf, p.ModTime); err != nil {
+		return nil, nil, err
+	}
+	return ch, buf.Bytes(), nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+)
+
+func TestPushPackageChart(t *testing.T) {
+	p := &Push{ModTime: time.Unix(1600000000, 0)}
+
+	ch, archive, err := p.packageChart("testdata/charts/chart-with-compressed-dependencies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ch.Name() != "chart-with-compressed-dependencies" {
+		t.Errorf("Unexpected chart %s", ch.Name())
+	}
+	_, again, err := p.packageChart("testdata/charts/chart-with-compressed-dependencies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(archive, again) {
+		t.Error("Expected the chart to be packaged reproducibly")
+	}
+
+	// Packaged charts are pushed as they are
+	dir := ensure.TempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "chart.tgz")
+	if err := ioutil.WriteFile(path, archive, 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, packaged, err := p.packageChart(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(archive, packaged) {
+		t.Error("Expected the packaged chart to be pushed unchanged")
+	}
+
+	if _, _, err := p.packageChart("testdata/charts/chart-missing-deps"); err == nil {
+		t.Error("Expected an error for a chart missing dependencies")
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/kube"
+)
+
+// checkQuotas compares the compute and storage resources requested by the
+// resources with the ResourceQuotas of the namespace, and fails with a report
+// of the exceeded quotas, rather than the API server rejecting the resources
+// half-way through their creation.
+func (c *Configuration) checkQuotas(namespace string, resources kube.ResourceList) ([]kube.QuotaCheck, error) {
+	client, ok := c.KubeClient.(kube.InterfaceResourceQuotas)
+	if !ok {
+		return nil, errors.New("resource quota checks are not supported by the Kubernetes client")
+	}
+
+	quotas, err := client.ResourceQuotas(namespace)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to list the resource quotas of namespace %q", namespace)
+	}
+	checks := kube.CheckQuotas(quotas, kube.RequestedResources(resources))
+
+	var exceeded []string
+	for _, q := range checks {
+		if q.Exceeded() {
+			exceeded = append(exceeded, fmt.Sprintf("%s: %s requested, %s used of %s in quota %s",
+				q.Resource, q.Requested.String(), q.Used.String(), q.Hard.String(), q.Quota))
+		}
+	}
+	if len(exceeded) > 0 {
+		return checks, errors.Errorf("resource quotas of namespace %q exceeded:\n  %s", namespace, strings.Join(exceeded, "\n  "))
+	}
+	return checks, nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cliresource "k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+)
+
+// quotaKubeClient reports the quota of a namespace
+type quotaKubeClient struct {
+	kubefake.PrintingKubeClient
+	quotas []corev1.ResourceQuota
+}
+
+func (c *quotaKubeClient) ResourceQuotas(_ string) ([]corev1.ResourceQuota, error) {
+	return c.quotas, nil
+}
+
+func TestCheckQuotas(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+
+	_, err := config.checkQuotas("spaced", nil)
+	is.EqualError(err, "resource quota checks are not supported by the Kubernetes client")
+
+	config.KubeClient = &quotaKubeClient{
+		PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		quotas: []corev1.ResourceQuota{{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{
+					corev1.ResourcePods:           resource.MustParse("10"),
+					corev1.ResourceRequestsMemory: resource.MustParse("1Gi"),
+				},
+				Used: corev1.ResourceList{
+					corev1.ResourcePods:           resource.MustParse("2"),
+					corev1.ResourceRequestsMemory: resource.MustParse("512Mi"),
+				},
+			},
+		}},
+	}
+
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "web",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+						},
+					}},
+				},
+			},
+		},
+	}
+	resources := kube.ResourceList{&cliresource.Info{Name: "web", Object: deployment}}
+
+	checks, err := config.checkQuotas("spaced", resources)
+	is.NoError(err)
+	is.Len(checks, 2)
+
+	deployment.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory] = resource.MustParse("512Mi")
+	_, err = config.checkQuotas("spaced", resources)
+	is.EqualError(err, "resource quotas of namespace \"spaced\" exceeded:\n  requests.memory: 1Gi requested, 512Mi used of 1Gi in quota compute")
+}
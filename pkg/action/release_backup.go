@@ -0,0 +1,181 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// ReleaseArchiveAPIVersion is the version of the release archive format
+// written by ReleaseBackup.
+const ReleaseArchiveAPIVersion = "helm.sh/release-archive/v1"
+
+// ReleaseArchive is a serialized set of release records, including every
+// stored revision of each release.
+type ReleaseArchive struct {
+	APIVersion string             `json:"apiVersion"`
+	Releases   []*release.Release `json:"releases"`
+}
+
+// Write encodes the archive as JSON to the given writer.
+func (a *ReleaseArchive) Write(out io.Writer) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(a)
+}
+
+// LoadReleaseArchive decodes a release archive from the given reader.
+func LoadReleaseArchive(in io.Reader) (*ReleaseArchive, error) {
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	a := &ReleaseArchive{}
+	if err := json.Unmarshal(data, a); err != nil {
+		return nil, errors.Wrap(err, "failed to decode release archive")
+	}
+	if a.APIVersion != ReleaseArchiveAPIVersion {
+		return nil, errors.Errorf("unsupported release archive version %q", a.APIVersion)
+	}
+	return a, nil
+}
+
+// ReleaseBackup is the action for exporting release records.
+//
+// It provides the implementation of 'helm release backup'.
+type ReleaseBackup struct {
+	cfg *Configuration
+}
+
+// NewReleaseBackup creates a new ReleaseBackup object with the given configuration.
+func NewReleaseBackup(cfg *Configuration) *ReleaseBackup {
+	return &ReleaseBackup{
+		cfg: cfg,
+	}
+}
+
+// Run collects every revision of the named releases. If no names are given,
+// all releases known to the storage backend are collected.
+func (b *ReleaseBackup) Run(names ...string) (*ReleaseArchive, error) {
+	var rels []*release.Release
+	if len(names) == 0 {
+		all, err := b.cfg.Releases.ListReleases()
+		if err != nil {
+			return nil, err
+		}
+		rels = all
+	}
+
+	for _, name := range names {
+		if err := chartutil.ValidateReleaseName(name); err != nil {
+			return nil, errors.Errorf("release name is invalid: %s", name)
+		}
+		b.cfg.Log("backing up history for release %s", name)
+		h, err := b.cfg.Releases.History(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read history of release %q", name)
+		}
+		rels = append(rels, h...)
+	}
+
+	sort.SliceStable(rels, func(i, j int) bool {
+		if rels[i].Name != rels[j].Name {
+			return rels[i].Name < rels[j].Name
+		}
+		return rels[i].Version < rels[j].Version
+	})
+
+	return &ReleaseArchive{
+		APIVersion: ReleaseArchiveAPIVersion,
+		Releases:   rels,
+	}, nil
+}
+
+// ReleaseRestore is the action for importing release records.
+//
+// It provides the implementation of 'helm release restore'.
+type ReleaseRestore struct {
+	cfg *Configuration
+
+	// Namespace is the namespace of the storage backend. Records of releases
+	// in other namespaces are rejected, as they would be stored apart from the
+	// resources of the releases.
+	Namespace string
+	// Force replaces revisions that already exist in the storage backend
+	// instead of failing.
+	Force bool
+}
+
+// NewReleaseRestore creates a new ReleaseRestore object with the given configuration.
+func NewReleaseRestore(cfg *Configuration) *ReleaseRestore {
+	return &ReleaseRestore{
+		cfg: cfg,
+	}
+}
+
+// Run writes every record in the archive to the storage backend and returns
+// the restored records.
+//
+// Namespaces and existing revisions are checked before anything is written, so
+// a mismatch or a conflict leaves the storage backend untouched unless Force is
+// set for the conflict.
+func (r *ReleaseRestore) Run(archive *ReleaseArchive) ([]*release.Release, error) {
+	if archive == nil || len(archive.Releases) == 0 {
+		return nil, errors.New("release archive contains no releases")
+	}
+
+	var conflicts []*release.Release
+	for _, rel := range archive.Releases {
+		if rel == nil || rel.Info == nil {
+			return nil, errors.New("release archive contains an incomplete release record")
+		}
+		if err := chartutil.ValidateReleaseName(rel.Name); err != nil {
+			return nil, errors.Errorf("release name is invalid: %s", rel.Name)
+		}
+		if r.Namespace != "" && rel.Namespace != r.Namespace {
+			return nil, errors.Errorf("release %q revision %d belongs to namespace %q, not %q", rel.Name, rel.Version, rel.Namespace, r.Namespace)
+		}
+		if _, err := r.cfg.Releases.Get(rel.Name, rel.Version); err == nil {
+			if !r.Force {
+				return nil, errors.Errorf("release %q revision %d already exists", rel.Name, rel.Version)
+			}
+			conflicts = append(conflicts, rel)
+		}
+	}
+
+	for _, rel := range conflicts {
+		if _, err := r.cfg.Releases.Delete(rel.Name, rel.Version); err != nil {
+			return nil, errors.Wrapf(err, "failed to replace release %q revision %d", rel.Name, rel.Version)
+		}
+	}
+
+	for _, rel := range archive.Releases {
+		r.cfg.Log("restoring release %s revision %d", rel.Name, rel.Version)
+		if err := r.cfg.Releases.Create(rel); err != nil {
+			return nil, errors.Wrapf(err, "failed to restore release %q revision %d", rel.Name, rel.Version)
+		}
+	}
+	return archive.Releases, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestReleaseBackupRestore(t *testing.T) {
+	is := assert.New(t)
+
+	src := actionConfigFixture(t)
+	for i := 1; i <= 3; i++ {
+		rel := namedReleaseStub("angry-panda", release.StatusSuperseded)
+		rel.Version = i
+		is.NoError(src.Releases.Create(rel))
+	}
+	other := namedReleaseStub("quiet-owl", release.StatusDeployed)
+	is.NoError(src.Releases.Create(other))
+
+	archive, err := NewReleaseBackup(src).Run("angry-panda")
+	is.NoError(err)
+	is.Len(archive.Releases, 3)
+	for i, rel := range archive.Releases {
+		is.Equal(i+1, rel.Version)
+	}
+
+	var buf bytes.Buffer
+	is.NoError(archive.Write(&buf))
+	loaded, err := LoadReleaseArchive(&buf)
+	is.NoError(err)
+
+	dst := actionConfigFixture(t)
+	rels, err := NewReleaseRestore(dst).Run(loaded)
+	is.NoError(err)
+	is.Len(rels, 3)
+
+	hist, err := dst.Releases.History("angry-panda")
+	is.NoError(err)
+	is.Len(hist, 3)
+
+	// Restoring twice conflicts unless forced.
+	_, err = NewReleaseRestore(dst).Run(loaded)
+	is.Error(err)
+
+	restore := NewReleaseRestore(dst)
+	restore.Force = true
+	_, err = restore.Run(loaded)
+	is.NoError(err)
+}
+
+func TestReleaseRestoreNamespaceMismatch(t *testing.T) {
+	is := assert.New(t)
+
+	ours := namedReleaseStub("angry-panda", release.StatusDeployed)
+	ours.Namespace = "spaced"
+	theirs := namedReleaseStub("quiet-owl", release.StatusDeployed)
+	theirs.Namespace = "elsewhere"
+	archive := &ReleaseArchive{
+		APIVersion: ReleaseArchiveAPIVersion,
+		Releases:   []*release.Release{ours, theirs},
+	}
+
+	cfg := actionConfigFixture(t)
+	restore := NewReleaseRestore(cfg)
+	restore.Namespace = "spaced"
+	_, err := restore.Run(archive)
+	is.EqualError(err, `release "quiet-owl" revision 1 belongs to namespace "elsewhere", not "spaced"`)
+
+	// Nothing is written when a record is rejected.
+	_, err = cfg.Releases.Get("angry-panda", 1)
+	is.Error(err)
+}
+
+func TestReleaseBackupAll(t *testing.T) {
+	is := assert.New(t)
+
+	cfg := actionConfigFixture(t)
+	is.NoError(cfg.Releases.Create(namedReleaseStub("one", release.StatusDeployed)))
+	is.NoError(cfg.Releases.Create(namedReleaseStub("two", release.StatusDeployed)))
+
+	archive, err := NewReleaseBackup(cfg).Run()
+	is.NoError(err)
+	is.Len(archive.Releases, 2)
+	is.Equal("one", archive.Releases[0].Name)
+	is.Equal("two", archive.Releases[1].Name)
+}
+
+func TestLoadReleaseArchiveVersion(t *testing.T) {
+	_, err := LoadReleaseArchive(bytes.NewBufferString(`{"apiVersion": "v0", "releases": []}`))
+	assert.Error(t, err)
+}
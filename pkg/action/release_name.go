@@ -0,0 +1,210 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-shellwords"
+	"github.com/pkg/errors"
+)
+
+// ExternalNameGeneratorPrefix prefixes the name generators that are external
+// commands.
+const ExternalNameGeneratorPrefix = "external:"
+
+// NameGenerator generates the names of the releases installed without one,
+// as with 'helm install --generate-name'.
+type NameGenerator interface {
+	// GenerateName returns a new release name. The base is derived from the
+	// chart, such as 'nginx' for './nginx-1.2.0.tgz'.
+	GenerateName(base string) (string, error)
+}
+
+// NameGeneratorFunc is a function that implements NameGenerator.
+type NameGeneratorFunc func(base string) (string, error)
+
+// GenerateName calls f(base).
+func (f NameGeneratorFunc) GenerateName(base string) (string, error) {
+	return f(base)
+}
+
+// NameValidator enforces naming conventions, such as prefixes or team codes,
+// on the names of new releases, on top of the rules of chartutil.ValidateReleaseName.
+type NameValidator interface {
+	ValidateName(name string) error
+}
+
+// NameValidatorFunc is a function that implements NameValidator.
+type NameValidatorFunc func(name string) error
+
+// ValidateName calls f(name).
+func (f NameValidatorFunc) ValidateName(name string) error {
+	return f(name)
+}
+
+var nameGenerators = map[string]func(cfg *Configuration) NameGenerator{
+	"timestamp": func(cfg *Configuration) NameGenerator {
+		return NameGeneratorFunc(func(base string) (string, error) {
+			return fmt.Sprintf("%s-%d", base, cfg.Now().Unix()), nil
+		})
+	},
+	"sequence": func(cfg *Configuration) NameGenerator {
+		return NameGeneratorFunc(func(base string) (string, error) {
+			return sequenceName(cfg, base)
+		})
+	},
+	"random": func(cfg *Configuration) NameGenerator {
+		return NameGeneratorFunc(randomName)
+	},
+}
+
+// RegisterNameGenerator makes a name generator available by name to
+// NewNameGenerator, replacing any generator registered with the same name. It
+// is meant to be called from init functions, as it is not safe for concurrent
+// use.
+func RegisterNameGenerator(name string, factory func(cfg *Configuration) NameGenerator) {
+	nameGenerators[name] = factory
+}
+
+// NameGeneratorNames returns the names of the registered name generators,
+// sorted.
+func NameGeneratorNames() []string {
+	names := make([]string, 0, len(nameGenerators))
+	for name := range nameGenerators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewNameGenerator returns the name generator for the given spec: the name of
+// a registered generator, or 'external:' followed by a command line, which is
+// given the base name as its last argument and prints the release name. An
+// empty spec gives the timestamp generator, which appends the Unix time to
+// the base name.
+func NewNameGenerator(cfg *Configuration, spec string) (NameGenerator, error) {
+	if spec == "" {
+		spec = "timestamp"
+	}
+	if strings.HasPrefix(spec, ExternalNameGeneratorPrefix) {
+		args, err := shellwords.Parse(strings.TrimPrefix(spec, ExternalNameGeneratorPrefix))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid name generator %q", spec)
+		}
+		if len(args) == 0 {
+			return nil, errors.Errorf("invalid name generator %q: no command given", spec)
+		}
+		return NameGeneratorFunc(func(base string) (string, error) {
+			return externalName(args, base)
+		}), nil
+	}
+	factory, ok := nameGenerators[spec]
+	if !ok {
+		return nil, errors.Errorf("unknown name generator %q, must be one of %s, or start with %q", spec, strings.Join(NameGeneratorNames(), ", "), ExternalNameGeneratorPrefix)
+	}
+	return factory(cfg), nil
+}
+
+// NewNamePatternValidator returns a name validator requiring the names to
+// fully match the regular expression, such as '^team-(a|b)-'.
+func NewNamePatternValidator(pattern string) (NameValidator, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid release name pattern %q", pattern)
+	}
+	return NameValidatorFunc(func(name string) error {
+		if !re.MatchString(name) {
+			return errors.Errorf("release name %q does not match the naming convention %q", name, pattern)
+		}
+		return nil
+	}), nil
+}
+
+// generateName generates a release name with the generator of the
+// configuration, or the timestamp generator if it has none.
+func (c *Configuration) generateName(base string) (string, error) {
+	gen := c.NameGenerator
+	if gen == nil {
+		gen = nameGenerators["timestamp"](c)
+	}
+	return gen.GenerateName(base)
+}
+
+// validateNewName checks the name of a new release with the validator of the
+// configuration, if any.
+func (c *Configuration) validateNewName(name string) error {
+	if c.NameValidator == nil {
+		return nil
+	}
+	return c.NameValidator.ValidateName(name)
+}
+
+// sequenceName returns the base name followed by the number after the highest
+// one of the releases named as such.
+func sequenceName(cfg *Configuration, base string) (string, error) {
+	rels, err := cfg.Releases.ListReleases()
+	if err != nil {
+		return "", err
+	}
+	next := 1
+	for _, rel := range rels {
+		if !strings.HasPrefix(rel.Name, base+"-") {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(rel.Name, base+"-")); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return fmt.Sprintf("%s-%d", base, next), nil
+}
+
+const randomNameChars = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// randomName returns the base name followed by 8 random lowercase letters and
+// digits.
+func randomName(base string) (string, error) {
+	suffix := make([]byte, 8)
+	for i := range suffix {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(randomNameChars))))
+		if err != nil {
+			return "", err
+		}
+		suffix[i] = randomNameChars[n.Int64()]
+	}
+	return base + "-" + string(suffix), nil
+}
+
+func externalName(args []string, base string) (string, error) {
+	var out bytes.Buffer
+	cmd := exec.Command(args[0], append(append([]string{}, args[1:]...), base)...)
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrapf(err, "name generator %q failed", strings.Join(args, " "))
+	}
+	return strings.TrimSpace(out.String()), nil
+}
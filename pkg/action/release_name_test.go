@@ -0,0 +1,88 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewNameGenerator(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+
+	_, err := NewNameGenerator(config, "ulid")
+	is.EqualError(err, `unknown name generator "ulid", must be one of random, sequence, timestamp, or start with "external:"`)
+	_, err = NewNameGenerator(config, "external:")
+	is.Error(err)
+
+	gen, err := NewNameGenerator(config, "random")
+	is.NoError(err)
+	name, err := gen.GenerateName("nginx")
+	is.NoError(err)
+	is.Regexp(regexp.MustCompile(`^nginx-[a-z0-9]{8}$`), name)
+
+	for _, n := range []string{"nginx-2", "nginx-9", "nginx-x", "redis-12"} {
+		rel := releaseStub()
+		rel.Name = n
+		config.Releases.Create(rel)
+	}
+	gen, err = NewNameGenerator(config, "sequence")
+	is.NoError(err)
+	name, err = gen.GenerateName("nginx")
+	is.NoError(err)
+	is.Equal("nginx-10", name)
+	name, err = gen.GenerateName("postgres")
+	is.NoError(err)
+	is.Equal("postgres-1", name)
+
+	if runtime.GOOS != "windows" {
+		gen, err = NewNameGenerator(config, `external:sh -c 'echo "team-a-$0"'`)
+		is.NoError(err)
+		name, err = gen.GenerateName("nginx")
+		is.NoError(err)
+		is.Equal("team-a-nginx", name)
+	}
+}
+
+func TestInstallRelease_NameConventions(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ReleaseName = ""
+	instAction.GenerateName = true
+
+	instAction.cfg.NameGenerator = NameGeneratorFunc(func(base string) (string, error) {
+		return "team-b-" + base, nil
+	})
+	name, _, err := instAction.NameAndChart([]string{"./nginx"})
+	is.NoError(err)
+	is.Equal("team-b-nginx", name)
+
+	validator, err := NewNamePatternValidator("team-a-.*")
+	is.NoError(err)
+	instAction.cfg.NameValidator = validator
+	instAction.ReleaseName = name
+	_, err = instAction.Run(buildChart(), map[string]interface{}{})
+	is.EqualError(err, `release name "team-b-nginx" does not match the naming convention "team-a-.*"`)
+
+	instAction.ReleaseName = "team-a-nginx"
+	_, err = instAction.Run(buildChart(), map[string]interface{}{})
+	is.NoError(err)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// ErrReleasePaused is returned, wrapped, when an operation is refused because
+// the release is paused.
+var ErrReleasePaused = errors.New("release is paused")
+
+// ReleasePause is the action for pausing a release.
+//
+// It provides the implementation of 'helm release pause'. The pause is
+// recorded on the latest revision of the release, and install, upgrade and
+// rollback refuse to act on a paused release unless told to override it.
+type ReleasePause struct {
+	cfg *Configuration
+
+	// Reason explains why the release is paused.
+	Reason string
+}
+
+// NewReleasePause creates a new ReleasePause object with the given configuration.
+func NewReleasePause(cfg *Configuration) *ReleasePause {
+	return &ReleasePause{
+		cfg: cfg,
+	}
+}
+
+// Run pauses the named release and returns the updated release.
+func (p *ReleasePause) Run(name string) (*release.Release, error) {
+	rel, err := pauseTarget(p.cfg, name)
+	if err != nil {
+		return nil, err
+	}
+
+	rel.Info.Pause = &release.Pause{
+		Reason:   p.Reason,
+		PausedAt: p.cfg.Now(),
+	}
+	if err := p.cfg.Releases.Update(rel); err != nil {
+		return nil, errors.Wrapf(err, "unable to pause release %q", name)
+	}
+	return rel, nil
+}
+
+// ReleaseResume is the action for resuming a paused release.
+//
+// It provides the implementation of 'helm release resume'.
+type ReleaseResume struct {
+	cfg *Configuration
+}
+
+// NewReleaseResume creates a new ReleaseResume object with the given configuration.
+func NewReleaseResume(cfg *Configuration) *ReleaseResume {
+	return &ReleaseResume{
+		cfg: cfg,
+	}
+}
+
+// Run resumes the named release and returns the updated release.
+func (r *ReleaseResume) Run(name string) (*release.Release, error) {
+	rel, err := pauseTarget(r.cfg, name)
+	if err != nil {
+		return nil, err
+	}
+	if !rel.IsPaused() {
+		return nil, errors.Errorf("release %q is not paused", name)
+	}
+
+	rel.Info.Pause = nil
+	if err := r.cfg.Releases.Update(rel); err != nil {
+		return nil, errors.Wrapf(err, "unable to resume release %q", name)
+	}
+	return rel, nil
+}
+
+// pauseTarget returns the latest revision of the named release.
+func pauseTarget(cfg *Configuration, name string) (*release.Release, error) {
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, errors.Errorf("release name is invalid: %s", name)
+	}
+	rel, err := cfg.Releases.Last(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "release %q not found", name)
+	}
+	if rel.Info.Status.IsPending() {
+		return nil, errPending
+	}
+	return rel, nil
+}
+
+// checkPause returns an error wrapping ErrReleasePaused if the release is
+// paused and the pause is not overridden.
+func (cfg *Configuration) checkPause(rel *release.Release, override bool) error {
+	if !rel.IsPaused() {
+		return nil
+	}
+	if override {
+		cfg.Log("overriding the pause of release %s", rel.Name)
+		return nil
+	}
+	if reason := rel.Info.Pause.Reason; reason != "" {
+		return errors.Wrapf(ErrReleasePaused, "%s (%s)", rel.Name, reason)
+	}
+	return errors.Wrap(ErrReleasePaused, rel.Name)
+}
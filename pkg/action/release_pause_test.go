@@ -0,0 +1,65 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestReleasePause(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := namedReleaseStub("frozen", release.StatusDeployed)
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	pause := NewReleasePause(upAction.cfg)
+	pause.Reason = "incident 42"
+	paused, err := pause.Run(rel.Name)
+	req.NoError(err)
+	is.True(paused.IsPaused())
+	is.Equal("incident 42", paused.Info.Pause.Reason)
+
+	_, err = upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	req.Error(err)
+	is.True(errors.Is(err, ErrReleasePaused))
+	is.Contains(err.Error(), "incident 42")
+
+	rollback := NewRollback(upAction.cfg)
+	err = rollback.Run(rel.Name)
+	req.Error(err)
+	is.True(errors.Is(err, ErrReleasePaused))
+
+	upAction.OverridePause = true
+	upgraded, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	req.NoError(err)
+	is.True(upgraded.IsPaused(), "the pause outlives an overriding upgrade")
+
+	resumed, err := NewReleaseResume(upAction.cfg).Run(rel.Name)
+	req.NoError(err)
+	is.False(resumed.IsPaused())
+
+	_, err = NewReleaseResume(upAction.cfg).Run(rel.Name)
+	is.Error(err)
+}
@@ -0,0 +1,239 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// Outcomes of relabeling a resource.
+const (
+	// ResourceRelabeled means the ownership metadata of the resource was
+	// rewritten.
+	ResourceRelabeled = "relabeled"
+	// ResourceOutdated means the ownership metadata of the resource would be
+	// rewritten, but the relabel is a dry run.
+	ResourceOutdated = "outdated"
+	// ResourceUnchanged means the resource already carries the ownership
+	// metadata of the release.
+	ResourceUnchanged = "unchanged"
+	// ResourceConflict means the resource is owned by something else and was
+	// left untouched.
+	ResourceConflict = "conflict"
+	// ResourceMissing means the resource of the manifest does not exist in
+	// the cluster.
+	ResourceMissing = "missing"
+)
+
+// RelabelResult is the outcome of relabeling a resource of a release.
+type RelabelResult struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	State     string `json:"state"`
+	// Detail explains a conflict, such as the field managers owning the metadata
+	Detail string `json:"detail,omitempty"`
+}
+
+// ReleaseRelabel is the action for rewriting the ownership metadata of the
+// resources of a release.
+//
+// It provides the implementation of 'helm release relabel'. The managed-by
+// label and the release name and namespace annotations of every live resource
+// of the release are set to the values Helm expects, such as after migrating
+// the resources from another tool.
+type ReleaseRelabel struct {
+	cfg *Configuration
+
+	// DryRun reports what would be relabeled without changing anything.
+	DryRun bool
+	// AllowedManagers are the field managers, besides Helm, whose ownership
+	// metadata may be rewritten. The metadata owned by any other field manager
+	// is a conflict.
+	AllowedManagers []string
+	// Force rewrites the metadata of the resources in conflict as well.
+	Force bool
+}
+
+// NewReleaseRelabel creates a new ReleaseRelabel object with the given configuration.
+func NewReleaseRelabel(cfg *Configuration) *ReleaseRelabel {
+	return &ReleaseRelabel{
+		cfg: cfg,
+	}
+}
+
+// Run relabels the resources of the last revision of the named release.
+//
+// An error is only returned if the resources cannot be read or patched;
+// resources in conflict are reported in the results.
+func (r *ReleaseRelabel) Run(name string) ([]RelabelResult, error) {
+	if err := r.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, errors.Errorf("release name is invalid: %s", name)
+	}
+
+	rel, err := r.cfg.Releases.Last(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "release %q not found", name)
+	}
+	resources, err := r.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build kubernetes objects from release manifest")
+	}
+
+	patch, err := metadataPatch(
+		map[string]string{
+			appManagedByLabel: appManagedByHelm,
+		},
+		map[string]string{
+			helmReleaseNameAnnotation:      rel.Name,
+			helmReleaseNamespaceAnnotation: rel.Namespace,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []RelabelResult
+	err = patchLiveResources(resources, func(info *resource.Info, live runtime.Object) ([]byte, error) {
+		_, kind := info.Mapping.GroupVersionKind.ToAPIVersionAndKind()
+		result := RelabelResult{Kind: kind, Name: info.Name, Namespace: info.Namespace}
+		if live == nil {
+			result.State = ResourceMissing
+			results = append(results, result)
+			return nil, nil
+		}
+
+		result.State, result.Detail = relabelState(live, rel.Name, rel.Namespace, r.AllowedManagers)
+		if result.State == ResourceConflict && r.Force {
+			result.State = ResourceOutdated
+		}
+		if result.State != ResourceOutdated || r.DryRun {
+			results = append(results, result)
+			return nil, nil
+		}
+		r.cfg.Log("relabeling %s", resourceString(info))
+		result.State = ResourceRelabeled
+		results = append(results, result)
+		return patch, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// relabelState tells whether the ownership metadata of obj must be rewritten
+// for the release, or why it cannot be.
+//
+// The metadata is in conflict if the object is annotated for another release,
+// or if any of the ownership fields is owned by a field manager that is not
+// allowed.
+func relabelState(obj runtime.Object, releaseName, releaseNamespace string, allowed []string) (string, string) {
+	if checkOwnership(obj, releaseName, releaseNamespace) == nil {
+		return ResourceUnchanged, ""
+	}
+
+	var reasons []string
+	annos, _ := accessor.Annotations(obj)
+	if owner := annos[helmReleaseNameAnnotation]; owner != "" && owner != releaseName {
+		reasons = append(reasons, fmt.Sprintf("annotated for release %q in namespace %q", owner, annos[helmReleaseNamespaceAnnotation]))
+	}
+	if managers := ownershipManagers(obj, allowed); len(managers) > 0 {
+		reasons = append(reasons, fmt.Sprintf("ownership metadata managed by %s", strings.Join(managers, ", ")))
+	}
+	if len(reasons) > 0 {
+		return ResourceConflict, strings.Join(reasons, "; ")
+	}
+	return ResourceOutdated, ""
+}
+
+// helmFieldManager is the field manager of the requests sent by Helm, which
+// the API server derives from its user agent.
+const helmFieldManager = "helm"
+
+// ownershipFields are the paths of the ownership metadata in the managed
+// fields of an object.
+var ownershipFields = [][]string{
+	{"f:metadata", "f:labels", "f:" + appManagedByLabel},
+	{"f:metadata", "f:annotations", "f:" + helmReleaseNameAnnotation},
+	{"f:metadata", "f:annotations", "f:" + helmReleaseNamespaceAnnotation},
+}
+
+// ownershipManagers returns the quoted names of the field managers, other
+// than Helm and the allowed ones, that own any of the ownership metadata of obj.
+func ownershipManagers(obj runtime.Object, allowed []string) []string {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return nil
+	}
+	skip := map[string]bool{helmFieldManager: true}
+	for _, name := range allowed {
+		skip[name] = true
+	}
+
+	var managers []string
+	for _, f := range m.GetManagedFields() {
+		if skip[f.Manager] || f.FieldsV1 == nil {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(f.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		for _, path := range ownershipFields {
+			if hasField(fields, path) {
+				skip[f.Manager] = true
+				managers = append(managers, fmt.Sprintf("%q", f.Manager))
+				break
+			}
+		}
+	}
+	sort.Strings(managers)
+	return managers
+}
+
+func hasField(fields map[string]interface{}, path []string) bool {
+	for i, key := range path {
+		v, ok := fields[key]
+		if !ok {
+			return false
+		}
+		if i == len(path)-1 {
+			return true
+		}
+		if fields, ok = v.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return false
+}
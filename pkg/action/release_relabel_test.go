@@ -0,0 +1,101 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func managedFieldsEntry(manager, fields string) v1.ManagedFieldsEntry {
+	return v1.ManagedFieldsEntry{
+		Manager:   manager,
+		Operation: v1.ManagedFieldsOperationApply,
+		FieldsV1:  &v1.FieldsV1{Raw: []byte(fields)},
+	}
+}
+
+func TestRelabelState(t *testing.T) {
+	is := assert.New(t)
+	deployFoo := newDeploymentResource("foo", "ns-a")
+	var allowed []string
+
+	// Without any ownership metadata, the resource is relabeled
+	state, detail := relabelState(deployFoo.Object, "rel-a", "ns-a", allowed)
+	is.Equal(ResourceOutdated, state)
+	is.Empty(detail)
+
+	// Labels from another tool that manages other fields are rewritten
+	_ = accessor.SetLabels(deployFoo.Object, map[string]string{appManagedByLabel: "kustomize"})
+	deployFoo.Object.(*appsv1.Deployment).ManagedFields = []v1.ManagedFieldsEntry{
+		managedFieldsEntry("helm", `{"f:metadata":{"f:labels":{"f:app.kubernetes.io/managed-by":{}}}}`),
+		managedFieldsEntry("kube-controller-manager", `{"f:status":{"f:replicas":{}}}`),
+	}
+	state, _ = relabelState(deployFoo.Object, "rel-a", "ns-a", allowed)
+	is.Equal(ResourceOutdated, state)
+
+	// Ownership metadata managed by another field manager is a conflict
+	deployFoo.Object.(*appsv1.Deployment).ManagedFields = append(deployFoo.Object.(*appsv1.Deployment).ManagedFields,
+		managedFieldsEntry("argocd", `{"f:metadata":{"f:annotations":{"f:meta.helm.sh/release-name":{}}}}`),
+	)
+	state, detail = relabelState(deployFoo.Object, "rel-a", "ns-a", allowed)
+	is.Equal(ResourceConflict, state)
+	is.Equal(`ownership metadata managed by "argocd"`, detail)
+
+	// Unless the field manager is allowed
+	state, _ = relabelState(deployFoo.Object, "rel-a", "ns-a", []string{"argocd"})
+	is.Equal(ResourceOutdated, state)
+
+	// Annotated for another release
+	_ = accessor.SetAnnotations(deployFoo.Object, map[string]string{
+		helmReleaseNameAnnotation:      "rel-b",
+		helmReleaseNamespaceAnnotation: "ns-b",
+	})
+	state, detail = relabelState(deployFoo.Object, "rel-a", "ns-a", allowed)
+	is.Equal(ResourceConflict, state)
+	is.Equal(`annotated for release "rel-b" in namespace "ns-b"; ownership metadata managed by "argocd"`, detail)
+
+	// Already owned by the release
+	_ = accessor.SetLabels(deployFoo.Object, map[string]string{appManagedByLabel: appManagedByHelm})
+	_ = accessor.SetAnnotations(deployFoo.Object, map[string]string{
+		helmReleaseNameAnnotation:      "rel-a",
+		helmReleaseNamespaceAnnotation: "ns-a",
+	})
+	state, detail = relabelState(deployFoo.Object, "rel-a", "ns-a", allowed)
+	is.Equal(ResourceUnchanged, state)
+	is.Empty(detail)
+}
+
+func TestReleaseRelabel(t *testing.T) {
+	is := assert.New(t)
+	cfg := actionConfigFixture(t)
+	rel := namedReleaseStub("angry-panda", release.StatusDeployed)
+	is.NoError(cfg.Releases.Create(rel))
+
+	client := NewReleaseRelabel(cfg)
+	_, err := client.Run("angry-panda")
+	is.NoError(err)
+
+	_, err = client.Run("calm-panda")
+	is.Error(err)
+	is.Contains(err.Error(), `release "calm-panda" not found`)
+}
@@ -0,0 +1,227 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// appInstanceLabel is the label holding the release name in the objects of
+// charts following the recommended labels.
+const appInstanceLabel = "app.kubernetes.io/instance"
+
+// ReleaseRename is the action for renaming a release in place.
+//
+// It provides the implementation of 'helm release rename'. Every stored
+// revision is rewritten under the new name and the ownership metadata of the
+// live resources is updated, so the workloads keep running throughout.
+type ReleaseRename struct {
+	cfg *Configuration
+
+	// DryRun reports what would be renamed without changing anything.
+	DryRun bool
+}
+
+// NewReleaseRename creates a new ReleaseRename object with the given configuration.
+func NewReleaseRename(cfg *Configuration) *ReleaseRename {
+	return &ReleaseRename{
+		cfg: cfg,
+	}
+}
+
+// Run renames the release called oldName to newName and returns the renamed
+// history.
+//
+// The live resources are relabeled first, then the revisions are stored under
+// the new name, and the revisions of the old name are removed last. If
+// relabeling or storing fails, the changes made so far are reverted. If
+// removing fails, both names are stored until the old revisions are removed
+// by hand.
+func (r *ReleaseRename) Run(oldName, newName string) ([]*release.Release, error) {
+	if err := r.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{oldName, newName} {
+		if err := chartutil.ValidateReleaseName(name); err != nil {
+			return nil, errors.Errorf("release name is invalid: %s", name)
+		}
+	}
+	if err := r.cfg.validateNewName(newName); err != nil {
+		return nil, err
+	}
+	if oldName == newName {
+		return nil, errors.New("the new release name must differ from the current name")
+	}
+
+	hist, err := r.cfg.Releases.History(oldName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "release %q not found", oldName)
+	}
+	if h, err := r.cfg.Releases.History(newName); err == nil && len(h) > 0 {
+		return nil, errors.Errorf("cannot rename to %q: a release with that name already exists", newName)
+	}
+
+	last, err := r.cfg.Releases.Last(oldName)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range hist {
+		if rel.Info.Status.IsPending() {
+			return nil, errPending
+		}
+	}
+
+	renamed := make([]*release.Release, 0, len(hist))
+	for _, rel := range hist {
+		cp := *rel
+		cp.Name = newName
+		renamed = append(renamed, &cp)
+	}
+
+	if r.DryRun {
+		return renamed, nil
+	}
+
+	// The live resources are moved first, so that the records of the new
+	// release are only written once its resources carry its name.
+	resources, err := r.cfg.KubeClient.Build(bytes.NewBufferString(last.Manifest), false)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build kubernetes objects from release manifest")
+	}
+	rollbackResources := func() {
+		if err := relabelResources(resources, newName, oldName); err != nil {
+			r.cfg.Log("failed to revert ownership metadata: %s", err)
+		}
+	}
+	if err := relabelResources(resources, oldName, newName); err != nil {
+		rollbackResources()
+		return nil, err
+	}
+
+	var created []*release.Release
+	for _, rel := range renamed {
+		r.cfg.Log("renaming release %s revision %d to %s", oldName, rel.Version, newName)
+		if err := r.cfg.Releases.Create(rel); err != nil {
+			for _, rel := range created {
+				if _, err := r.cfg.Releases.Delete(rel.Name, rel.Version); err != nil {
+					r.cfg.Log("failed to remove release %s revision %d while reverting rename: %s", rel.Name, rel.Version, err)
+				}
+			}
+			rollbackResources()
+			return nil, errors.Wrapf(err, "failed to store release %q revision %d", newName, rel.Version)
+		}
+		created = append(created, rel)
+	}
+
+	for _, rel := range hist {
+		if _, err := r.cfg.Releases.Delete(oldName, rel.Version); err != nil {
+			return renamed, errors.Wrapf(err, "release was renamed, but revision %d of %q could not be removed", rel.Version, oldName)
+		}
+	}
+	return renamed, nil
+}
+
+// relabelResources moves the live resources in the list from one release to
+// another. Resources that no longer exist, or that are owned by some other
+// release, are left untouched.
+func relabelResources(resources kube.ResourceList, from, to string) error {
+	return patchLiveResources(resources, func(_ *resource.Info, live runtime.Object) ([]byte, error) {
+		if live == nil {
+			return nil, nil
+		}
+		return renamePatch(live, from, to)
+	})
+}
+
+// renamePatch returns the merge patch moving obj from one release to another,
+// or nil if obj does not belong to the release called from. The release name
+// annotation is rewritten, along with the app.kubernetes.io/instance label of
+// the charts following the recommended labels.
+func renamePatch(obj runtime.Object, from, to string) ([]byte, error) {
+	annos, err := accessor.Annotations(obj)
+	if err != nil {
+		return nil, err
+	}
+	if annos[helmReleaseNameAnnotation] != from {
+		return nil, nil
+	}
+	lbls, err := accessor.Labels(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels map[string]string
+	if lbls[appInstanceLabel] == from {
+		labels = map[string]string{appInstanceLabel: to}
+	}
+	return metadataPatch(labels, map[string]string{helmReleaseNameAnnotation: to})
+}
+
+// patchLiveResources gets the live object of every resource in the list and
+// applies the merge patch returned by patchFor to it. patchFor is called with
+// a nil object for the resources that do not exist, and returns a nil patch to
+// leave the object untouched.
+func patchLiveResources(resources kube.ResourceList, patchFor func(info *resource.Info, live runtime.Object) ([]byte, error)) error {
+	return resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+
+		helper := resource.NewHelper(info.Client, info.Mapping)
+		live, err := helper.Get(info.Namespace, info.Name)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return errors.Wrap(err, "could not get information about the resource")
+			}
+			live = nil
+		}
+
+		patch, err := patchFor(info, live)
+		if err != nil || patch == nil {
+			return err
+		}
+		if _, err := helper.Patch(info.Namespace, info.Name, types.MergePatchType, patch, nil); err != nil {
+			return errors.Wrapf(err, "failed to update ownership of %s", resourceString(info))
+		}
+		return nil
+	})
+}
+
+// metadataPatch returns a merge patch setting the given labels and annotations
+// of an object.
+func metadataPatch(labels, annotations map[string]string) ([]byte, error) {
+	metadata := map[string]interface{}{}
+	if len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	if len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+	return json.Marshal(map[string]interface{}{"metadata": metadata})
+}
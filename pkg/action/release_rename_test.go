@@ -0,0 +1,126 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func renameFixture(t *testing.T) *ReleaseRename {
+	t.Helper()
+	cfg := actionConfigFixture(t)
+	for i := 1; i <= 2; i++ {
+		rel := namedReleaseStub("angry-panda", release.StatusSuperseded)
+		if i == 2 {
+			rel.Info.Status = release.StatusDeployed
+		}
+		rel.Version = i
+		if err := cfg.Releases.Create(rel); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewReleaseRename(cfg)
+}
+
+func TestReleaseRename(t *testing.T) {
+	is := assert.New(t)
+	client := renameFixture(t)
+
+	rels, err := client.Run("angry-panda", "calm-panda")
+	is.NoError(err)
+	is.Len(rels, 2)
+
+	hist, err := client.cfg.Releases.History("calm-panda")
+	is.NoError(err)
+	is.Len(hist, 2)
+
+	_, err = client.cfg.Releases.History("angry-panda")
+	is.Error(err)
+}
+
+func TestReleaseRenameDryRun(t *testing.T) {
+	is := assert.New(t)
+	client := renameFixture(t)
+	client.DryRun = true
+
+	rels, err := client.Run("angry-panda", "calm-panda")
+	is.NoError(err)
+	is.Len(rels, 2)
+	is.Equal("calm-panda", rels[0].Name)
+
+	_, err = client.cfg.Releases.History("calm-panda")
+	is.Error(err)
+	hist, err := client.cfg.Releases.History("angry-panda")
+	is.NoError(err)
+	is.Len(hist, 2)
+}
+
+func TestReleaseRenameConflicts(t *testing.T) {
+	is := assert.New(t)
+	client := renameFixture(t)
+	is.NoError(client.cfg.Releases.Create(namedReleaseStub("calm-panda", release.StatusDeployed)))
+
+	_, err := client.Run("angry-panda", "calm-panda")
+	is.Error(err)
+
+	_, err = client.Run("angry-panda", "angry-panda")
+	is.Error(err)
+}
+
+func TestReleaseRenameRevertsOnFailure(t *testing.T) {
+	is := assert.New(t)
+	client := renameFixture(t)
+	failer := client.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	failer.BuildError = fmt.Errorf("build failed")
+
+	_, err := client.Run("angry-panda", "calm-panda")
+	is.Error(err)
+
+	_, err = client.cfg.Releases.History("calm-panda")
+	is.Error(err)
+	hist, err := client.cfg.Releases.History("angry-panda")
+	is.NoError(err)
+	is.Len(hist, 2)
+}
+
+func TestRenamePatch(t *testing.T) {
+	is := assert.New(t)
+	deployFoo := newDeploymentResource("foo", "ns-a")
+
+	// Resources of other releases are left untouched
+	_ = accessor.SetAnnotations(deployFoo.Object, map[string]string{helmReleaseNameAnnotation: "other-panda"})
+	patch, err := renamePatch(deployFoo.Object, "angry-panda", "calm-panda")
+	is.NoError(err)
+	is.Nil(patch)
+
+	_ = accessor.SetAnnotations(deployFoo.Object, map[string]string{helmReleaseNameAnnotation: "angry-panda"})
+	patch, err = renamePatch(deployFoo.Object, "angry-panda", "calm-panda")
+	is.NoError(err)
+	is.JSONEq(`{"metadata":{"annotations":{"meta.helm.sh/release-name":"calm-panda"}}}`, string(patch))
+
+	// The recommended instance label is renamed along
+	_ = accessor.SetLabels(deployFoo.Object, map[string]string{appInstanceLabel: "angry-panda"})
+	patch, err = renamePatch(deployFoo.Object, "angry-panda", "calm-panda")
+	is.NoError(err)
+	is.JSONEq(`{"metadata":{"labels":{"app.kubernetes.io/instance":"calm-panda"},"annotations":{"meta.helm.sh/release-name":"calm-panda"}}}`, string(patch))
+}
@@ -0,0 +1,175 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// Integrity states of a release revision.
+const (
+	// RevisionIntact means the content of the revision matches its checksums.
+	RevisionIntact = "intact"
+	// RevisionModified means the content of the revision does not match its
+	// checksums.
+	RevisionModified = "modified"
+	// RevisionUnverified means the revision has no checksums, such as the
+	// revisions stored by older versions of Helm.
+	RevisionUnverified = "unverified"
+)
+
+// RevisionVerification is the result of the verification of a release
+// revision.
+type RevisionVerification struct {
+	Revision int    `json:"revision"`
+	State    string `json:"state"`
+	// Modified lists what does not match the checksums: manifest or values
+	Modified []string `json:"modified,omitempty"`
+}
+
+// ReleaseVerify is the action for verifying the integrity of stored releases.
+//
+// It provides the implementation of 'helm release verify'.
+type ReleaseVerify struct {
+	cfg *Configuration
+
+	// Version is the revision to verify. Every revision is verified if it is
+	// zero.
+	Version int
+}
+
+// NewReleaseVerify creates a new ReleaseVerify object with the given configuration.
+func NewReleaseVerify(cfg *Configuration) *ReleaseVerify {
+	return &ReleaseVerify{
+		cfg: cfg,
+	}
+}
+
+// Run verifies the revisions of the named release against their checksums.
+//
+// An error is only returned if the revisions cannot be read; modified
+// revisions are reported in the results.
+func (v *ReleaseVerify) Run(name string) ([]RevisionVerification, error) {
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, errors.Errorf("release name is invalid: %s", name)
+	}
+
+	var rels []*release.Release
+	if v.Version > 0 {
+		rel, err := v.cfg.Releases.Get(name, v.Version)
+		if err != nil {
+			return nil, err
+		}
+		rels = append(rels, rel)
+	} else {
+		h, err := v.cfg.Releases.History(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read history of release %q", name)
+		}
+		rels = h
+	}
+	releaseutil.SortByRevision(rels)
+
+	results := make([]RevisionVerification, 0, len(rels))
+	for _, rel := range rels {
+		result := RevisionVerification{Revision: rel.Version, State: RevisionUnverified}
+		if rel.Checksums != nil {
+			sums, err := ReleaseChecksums(rel)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to compute checksums of revision %d of release %q", rel.Version, name)
+			}
+			if sums.Manifest != rel.Checksums.Manifest {
+				result.Modified = append(result.Modified, "manifest")
+			}
+			if sums.Values != rel.Checksums.Values {
+				result.Modified = append(result.Modified, "values")
+			}
+			result.State = RevisionIntact
+			if len(result.Modified) > 0 {
+				result.State = RevisionModified
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ReleaseChecksums computes the checksums of a release revision: the digest
+// of its manifest and hooks, and the digest of the chart's values coalesced
+// with the values of the release.
+//
+// Comparing the checksums of two revisions is a cheap way to find out if
+// anything changed between them.
+func ReleaseChecksums(rel *release.Release) (*release.Checksums, error) {
+	values, err := valuesChecksum(rel, rel.InstallConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &release.Checksums{
+		Manifest: manifestChecksum(rel),
+		Values:   values,
+	}, nil
+}
+
+// manifestChecksum returns the digest of the manifest and hooks of a release.
+func manifestChecksum(rel *release.Release) string {
+	h := sha256.New()
+	io.WriteString(h, rel.Manifest)
+	for _, hook := range rel.Hooks {
+		io.WriteString(h, "\x00"+hook.Path+"\x00"+hook.Manifest)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// valuesChecksum returns the digest of the chart's values coalesced with the
+// values of a release, along with the given install-only values.
+func valuesChecksum(rel *release.Release, installConfig map[string]interface{}) (string, error) {
+	vals := rel.Config
+	if rel.Chart != nil {
+		coalesced, err := chartutil.CoalesceValues(rel.Chart, rel.Config)
+		if err != nil {
+			return "", err
+		}
+		vals = coalesced
+	}
+	data, err := json.Marshal([]interface{}{vals, installConfig})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// setChecksums records the checksums of a release revision before it is
+// stored.
+func setChecksums(rel *release.Release) error {
+	sums, err := ReleaseChecksums(rel)
+	if err != nil {
+		return errors.Wrap(err, "failed to compute release checksums")
+	}
+	rel.Checksums = sums
+	return nil
+}
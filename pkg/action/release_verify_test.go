@@ -0,0 +1,62 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestReleaseVerify(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	instAction := installAction(t)
+	rel, err := instAction.Run(buildChart(), map[string]interface{}{"name": "value"})
+	req.NoError(err)
+	req.NotNil(rel.Checksums)
+
+	// A revision stored by an older version of Helm has no checksums
+	old := namedReleaseStub(rel.Name, release.StatusDeployed)
+	old.Version = 2
+	req.NoError(instAction.cfg.Releases.Create(old))
+
+	verify := NewReleaseVerify(instAction.cfg)
+	results, err := verify.Run(rel.Name)
+	req.NoError(err)
+	is.Equal([]RevisionVerification{
+		{Revision: 1, State: RevisionIntact},
+		{Revision: 2, State: RevisionUnverified},
+	}, results)
+
+	stored, err := instAction.cfg.Releases.Get(rel.Name, 1)
+	req.NoError(err)
+	stored.Manifest += "\n# tampered"
+	stored.Config = map[string]interface{}{"name": "other"}
+	req.NoError(instAction.cfg.Releases.Update(stored))
+
+	verify.Version = 1
+	results, err = verify.Run(rel.Name)
+	req.NoError(err)
+	is.Equal([]RevisionVerification{
+		{Revision: 1, State: RevisionModified, Modified: []string{"manifest", "values"}},
+	}, results)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// DefaultRetryBackoff is the delay before the second attempt of an operation
+// retried by a RetryPolicy that sets none.
+const DefaultRetryBackoff = 5 * time.Second
+
+// RetryPolicy tells how to retry an operation that failed.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts, the first one included.
+	// Less than two disables retries.
+	Attempts int
+	// Backoff is the delay before the second attempt, doubled for each
+	// attempt after it. Zero means DefaultRetryBackoff.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between attempts. Zero means no cap.
+	MaxBackoff time.Duration
+	// Retryable tells whether an attempt that failed with the error is
+	// retried. Nil means IsRetryable.
+	Retryable func(error) bool
+}
+
+// backoff returns the delay before the attempt following the given one.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.Backoff
+	if delay <= 0 {
+		delay = DefaultRetryBackoff
+	}
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// transientMessages are parts of the messages of transient errors that reach
+// Helm as text, such as through the errors of several resources being joined.
+var transientMessages = []string{
+	"failed calling webhook",
+	"the server is currently unable to handle the request",
+	"the server was unable to return a response in the time allotted",
+	"etcdserver: request timed out",
+	"etcdserver: leader changed",
+	"connection refused",
+	"connection reset by peer",
+	"i/o timeout",
+	"TLS handshake timeout",
+}
+
+// IsRetryable tells whether an error is likely transient, so that the
+// operation that failed with it may succeed if retried: the API server being
+// unavailable, overloaded or timing out, or an admission webhook that could
+// not be called.
+//
+// Errors such as invalid resources, conflicts with existing ones or resources
+// that are not ready in time are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if apierrors.IsServiceUnavailable(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) || apierrors.IsInternalError(err) {
+		return true
+	}
+	if cause := errors.Cause(err); utilnet.IsConnectionRefused(cause) || utilnet.IsConnectionReset(cause) || utilnet.IsProbableEOF(cause) {
+		return true
+	}
+	msg := err.Error()
+	for _, m := range transientMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
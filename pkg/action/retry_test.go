@@ -0,0 +1,148 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestIsRetryable(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	tests := []struct {
+		err       error
+		retryable bool
+	}{
+		{nil, false},
+		{apierrors.NewServiceUnavailable("try later"), true},
+		{errors.Wrap(apierrors.NewTooManyRequests("slow down", 1), "failed to update"), true},
+		{apierrors.NewInternalError(errors.New(`Internal error occurred: failed calling webhook "validate.example.com": connection refused`)), true},
+		{fmt.Errorf("failed to create resource: %s", `Internal error occurred: failed calling webhook "validate.example.com"`), true},
+		{apierrors.NewTimeoutError("etcd", 1), true},
+		{errors.New("Kubernetes cluster unreachable: dial tcp 127.0.0.1:6443: connect: connection refused"), true},
+		{apierrors.NewConflict(deployments, "web", errors.New("modified")), false},
+		{apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "web", nil), false},
+		{apierrors.NewForbidden(deployments, "web", errors.New(`admission webhook "deny.example.com" denied the request`)), false},
+		{errors.New("timed out waiting for the condition"), false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.retryable {
+			t.Errorf("IsRetryable(%v) = %t, expected %t", tt.err, got, tt.retryable)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	is := assert.New(t)
+
+	p := &RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	is.Equal(time.Second, p.backoff(1))
+	is.Equal(2*time.Second, p.backoff(2))
+	is.Equal(4*time.Second, p.backoff(3))
+	is.Equal(5*time.Second, p.backoff(4))
+	is.Equal(5*time.Second, p.backoff(100))
+
+	is.Equal(DefaultRetryBackoff, (&RetryPolicy{}).backoff(1))
+}
+
+// flakyKubeClient fails the first updates with an error.
+type flakyKubeClient struct {
+	*kubefake.FailingKubeClient
+	failures int
+	err      error
+}
+
+func (c *flakyKubeClient) Update(original, target kube.ResourceList, force bool) (*kube.Result, error) {
+	if c.failures > 0 {
+		c.failures--
+		return &kube.Result{}, c.err
+	}
+	return c.FailingKubeClient.Update(original, target, force)
+}
+
+func TestUpgradeRetry(t *testing.T) {
+	is := assert.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	upAction.cfg.Releases.Create(rel)
+	upAction.cfg.KubeClient = &flakyKubeClient{
+		FailingKubeClient: upAction.cfg.KubeClient.(*kubefake.FailingKubeClient),
+		failures:          2,
+		err:               apierrors.NewServiceUnavailable("webhook unavailable"),
+	}
+	var delays []time.Duration
+	upAction.sleep = func(d time.Duration) { delays = append(delays, d) }
+	upAction.Retry = &RetryPolicy{Attempts: 3, Backoff: time.Second}
+
+	res, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	is.NoError(err)
+	is.Equal(release.StatusDeployed, res.Info.Status)
+	is.Equal(4, res.Version)
+	is.Equal([]time.Duration{time.Second, 2 * time.Second}, delays)
+
+	is.Len(res.Info.Attempts, 2)
+	is.Equal(2, res.Info.Attempts[0].Revision)
+	is.Equal(3, res.Info.Attempts[1].Revision)
+	is.Contains(res.Info.Attempts[0].Error, "webhook unavailable")
+
+	stored, err := upAction.cfg.Releases.Get(rel.Name, res.Version)
+	is.NoError(err)
+	is.Equal(res.Info.Attempts, stored.Info.Attempts)
+}
+
+func TestUpgradeRetryGivesUp(t *testing.T) {
+	is := assert.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	upAction.cfg.Releases.Create(rel)
+	flaky := &flakyKubeClient{
+		FailingKubeClient: upAction.cfg.KubeClient.(*kubefake.FailingKubeClient),
+		failures:          5,
+		err:               apierrors.NewServiceUnavailable("webhook unavailable"),
+	}
+	upAction.cfg.KubeClient = flaky
+	var delays []time.Duration
+	upAction.sleep = func(d time.Duration) { delays = append(delays, d) }
+	upAction.Retry = &RetryPolicy{Attempts: 2, Backoff: time.Second}
+
+	res, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	is.Error(err)
+	is.Equal(release.StatusFailed, res.Info.Status)
+	is.Len(delays, 1)
+	is.Len(res.Info.Attempts, 1)
+	is.Equal(3, flaky.failures)
+
+	// Errors that are not retryable fail at once
+	flaky.err = apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "hello", errors.New("modified"))
+	delays = nil
+	_, err = upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	is.Error(err)
+	is.Empty(delays)
+	is.Equal(2, flaky.failures)
+}
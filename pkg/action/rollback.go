@@ -25,8 +25,9 @@ import (
 	"github.com/pkg/errors"
 
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/diff"
+	"helm.sh/helm/v3/pkg/kube"
 	"helm.sh/helm/v3/pkg/release"
-	helmtime "helm.sh/helm/v3/pkg/time"
 )
 
 // Rollback is the action for rolling back to a given release.
@@ -44,7 +45,15 @@ type Rollback struct {
 	Recreate      bool // will (if true) recreate pods after a rollback.
 	Force         bool // will (if true) force resource upgrade through uninstall/recreate if needed
 	CleanupOnFail bool
-	MaxHistory    int // MaxHistory limits the maximum number of revisions saved per release
+	MaxHistory    int  // MaxHistory limits the maximum number of revisions saved per release
+	OverridePause bool // will (if true) roll back the release even if it is paused
+	// DeletionTimeout is how long to wait for the deletion of the resources
+	// that are recreated, as they cannot be replaced, when Force is set. Zero
+	// disables the recreation.
+	DeletionTimeout time.Duration
+	// Result is set by Run to the outcome of the update of the resources of
+	// the release, when they are sent to the cluster.
+	Result *kube.Result
 }
 
 // NewRollback creates a new Rollback object with the given configuration.
@@ -70,6 +79,9 @@ func (r *Rollback) Run(name string) error {
 
 	if !r.DryRun {
 		r.cfg.Log("creating rolled back release for %s", name)
+		if err := setChecksums(targetRelease); err != nil {
+			return err
+		}
 		if err := r.cfg.Releases.Create(targetRelease); err != nil {
 			return err
 		}
@@ -77,6 +89,9 @@ func (r *Rollback) Run(name string) error {
 
 	r.cfg.Log("performing rollback of %s", name)
 	if _, err := r.performRollback(currentRelease, targetRelease); err != nil {
+		if !r.DryRun {
+			r.cfg.notify(EventRollbackFailed, targetRelease, err.Error())
+		}
 		return err
 	}
 
@@ -85,10 +100,22 @@ func (r *Rollback) Run(name string) error {
 		if err := r.cfg.Releases.Update(targetRelease); err != nil {
 			return err
 		}
+		r.cfg.notify(EventRollbackSucceeded, targetRelease, "")
 	}
 	return nil
 }
 
+// Diff returns the changes of the resources of the named release that rolling
+// it back would make, without changing anything in the cluster or in the
+// release history.
+func (r *Rollback) Diff(name string) ([]diff.ResourceDiff, error) {
+	currentRelease, targetRelease, err := r.prepareRollback(name)
+	if err != nil {
+		return nil, err
+	}
+	return ReleaseDiff(currentRelease, targetRelease)
+}
+
 // prepareRollback finds the previous release and prepares a new release object with
 // the previous release's configuration
 func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Release, error) {
@@ -104,6 +131,9 @@ func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Rele
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := r.cfg.checkPause(currentRelease, r.OverridePause); err != nil {
+		return nil, nil, err
+	}
 
 	previousVersion := r.Version
 	if r.Version == 0 {
@@ -116,6 +146,7 @@ func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Rele
 	if err != nil {
 		return nil, nil, err
 	}
+	release.CarryHookExecutions(currentRelease.Hooks, previousRelease.Hooks)
 
 	// Store a new release object with previous release's configuration
 	targetRelease := &release.Release{
@@ -125,16 +156,19 @@ func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Rele
 		Config:    previousRelease.Config,
 		Info: &release.Info{
 			FirstDeployed: currentRelease.Info.FirstDeployed,
-			LastDeployed:  helmtime.Now(),
+			LastDeployed:  r.cfg.Now(),
 			Status:        release.StatusPendingRollback,
 			Notes:         previousRelease.Info.Notes,
 			// Because we lose the reference to previous version elsewhere, we set the
 			// message here, and only override it later if we experience failure.
 			Description: fmt.Sprintf("Rollback to %d", previousVersion),
+			Pause:       currentRelease.Info.Pause,
 		},
-		Version:  currentRelease.Version + 1,
-		Manifest: previousRelease.Manifest,
-		Hooks:    previousRelease.Hooks,
+		Version:    currentRelease.Version + 1,
+		Manifest:   previousRelease.Manifest,
+		Hooks:      previousRelease.Hooks,
+		ValuesRefs: previousRelease.ValuesRefs,
+		Defaults:   currentRelease.Defaults,
 	}
 
 	return currentRelease, targetRelease, nil
@@ -150,6 +184,7 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 	if err != nil {
 		return targetRelease, errors.Wrap(err, "unable to build kubernetes objects from current release manifest")
 	}
+	r.cfg.applyGeneratedNames(current, currentRelease)
 	target, err := r.cfg.KubeClient.Build(bytes.NewBufferString(targetRelease.Manifest), false)
 	if err != nil {
 		return targetRelease, errors.Wrap(err, "unable to build kubernetes objects from new release manifest")
@@ -164,7 +199,12 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 		r.cfg.Log("rollback hooks disabled for %s", targetRelease.Name)
 	}
 
-	results, err := r.cfg.KubeClient.Update(current, target, r.Force)
+	results, err := r.cfg.updateResources(current, target, r.Force, r.DeletionTimeout)
+	r.Result = results
+	if results != nil {
+		recordGeneratedNames(targetRelease, results.Created)
+		r.cfg.warnResults(targetRelease, results)
+	}
 
 	if err != nil {
 		msg := fmt.Sprintf("Rollback %q failed: %s", targetRelease.Name, err)
@@ -203,6 +243,7 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 		if r.WaitForJobs {
 			if err := r.cfg.KubeClient.WaitWithJobs(target, r.Timeout); err != nil {
 				targetRelease.SetStatus(release.StatusFailed, fmt.Sprintf("Release %q failed: %s", targetRelease.Name, err.Error()))
+				recordNotReady(targetRelease, err)
 				r.cfg.recordRelease(currentRelease)
 				r.cfg.recordRelease(targetRelease)
 				return targetRelease, errors.Wrapf(err, "release %s failed", targetRelease.Name)
@@ -210,6 +251,7 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 		} else {
 			if err := r.cfg.KubeClient.Wait(target, r.Timeout); err != nil {
 				targetRelease.SetStatus(release.StatusFailed, fmt.Sprintf("Release %q failed: %s", targetRelease.Name, err.Error()))
+				recordNotReady(targetRelease, err)
 				r.cfg.recordRelease(currentRelease)
 				r.cfg.recordRelease(targetRelease)
 				return targetRelease, errors.Wrapf(err, "release %s failed", targetRelease.Name)
@@ -0,0 +1,51 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/secrets"
+)
+
+// resolveSecrets replaces the secret references of the values given to the
+// templates by their secrets. Only the rendered manifests hold the secrets;
+// the values stored in the release keep the references.
+func (cfg *Configuration) resolveSecrets(r *secrets.Resolver, valuesToRender chartutil.Values) error {
+	if r == nil {
+		return nil
+	}
+	if r.Log == nil {
+		r.Log = cfg.Log
+	}
+	vals, err := valuesToRender.Table("Values")
+	if err != nil {
+		// There is nothing to resolve without values
+		var noTable chartutil.ErrNoTable
+		if errors.As(err, &noTable) {
+			return nil
+		}
+		return err
+	}
+	resolved, err := r.ResolveValues(vals.AsMap())
+	if err != nil {
+		return err
+	}
+	valuesToRender["Values"] = chartutil.Values(resolved)
+	return nil
+}
@@ -0,0 +1,40 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/secrets"
+)
+
+func TestResolveSecretsWithoutValues(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	r := secrets.NewResolver(secrets.Providers{{
+		Schemes: []string{"vault"},
+		Backend: staticSecretBackend{"secret/db#password": "hunter2"},
+	}})
+
+	valuesToRender := chartutil.Values{"Release": map[string]interface{}{"Name": "test"}}
+	if err := cfg.resolveSecrets(r, valuesToRender); err != nil {
+		t.Fatalf("expected values without a Values table to be left as is, got %s", err)
+	}
+	if _, ok := valuesToRender["Values"]; ok {
+		t.Error("expected no Values table to be added")
+	}
+}
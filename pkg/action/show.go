@@ -57,7 +57,10 @@ type Show struct {
 	Devel            bool
 	OutputFormat     ShowOutputFormat
 	JSONPathTemplate string
-	chart            *chart.Chart // for testing
+	// ValuesDocs shows the documentation of the values instead of values.yaml,
+	// in the given format: markdown or json
+	ValuesDocs string
+	chart      *chart.Chart // for testing
 }
 
 // NewShow creates a new Show object with the given configuration.
@@ -96,6 +99,12 @@ func (s *Show) Run(chartpath string) (string, error) {
 				return "", errors.Wrapf(err, "error parsing jsonpath %s", s.JSONPathTemplate)
 			}
 			printer.Execute(&out, s.chart.Values)
+		} else if s.ValuesDocs != "" {
+			docs, err := s.valuesDocs()
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprint(&out, docs)
 		} else {
 			for _, f := range s.chart.Raw {
 				if f.Name == chartutil.ValuesfileName {
@@ -118,6 +127,21 @@ func (s *Show) Run(chartpath string) (string, error) {
 	return out.String(), nil
 }
 
+func (s *Show) valuesDocs() (string, error) {
+	docs, err := chartutil.DocumentValues(s.chart)
+	if err != nil {
+		return "", err
+	}
+	switch s.ValuesDocs {
+	case "markdown":
+		return docs.Markdown(), nil
+	case "json":
+		return docs.JSON()
+	default:
+		return "", errors.Errorf("unknown values documentation format %q, must be markdown or json", s.ValuesDocs)
+	}
+}
+
 func findReadme(files []*chart.File) (file *chart.File) {
 	for _, file := range files {
 		for _, n := range readmeFileNames {
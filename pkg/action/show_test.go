@@ -54,6 +54,34 @@ README
 	}
 }
 
+func TestShowCompatibility(t *testing.T) {
+	client := NewShow(ShowChart)
+	client.chart = &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "alpine",
+			Compatibility: []*chart.Compatibility{
+				{Version: "^1.0.0", AppVersion: ">=2.0.0", KubeVersion: ">=1.18.0-0"},
+			},
+		},
+	}
+
+	output, err := client.Run("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `compatibility:
+- appVersion: '>=2.0.0'
+  kubeVersion: '>=1.18.0-0'
+  version: ^1.0.0
+name: alpine
+
+`
+	if output != expect {
+		t.Errorf("Expected\n%q\nGot\n%q\n", expect, output)
+	}
+}
+
 func TestShowNoValues(t *testing.T) {
 	client := NewShow(ShowAll)
 	client.chart = new(chart.Chart)
@@ -83,3 +111,33 @@ func TestShowValuesByJsonPathFormat(t *testing.T) {
 		t.Errorf("Expected\n%q\nGot\n%q\n", expect, output)
 	}
 }
+
+func TestShowValuesDocs(t *testing.T) {
+	client := NewShow(ShowValues)
+	client.ValuesDocs = "json"
+	client.chart = &chart.Chart{
+		Metadata: &chart.Metadata{Name: "alpine"},
+		Raw: []*chart.File{
+			{Name: "values.yaml", Data: []byte("# Name of the pet\nname: fido\n")},
+		},
+		Values: map[string]interface{}{"name": "fido"},
+	}
+
+	output, err := client.Run("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `[
+  {
+    "key": "name",
+    "type": "string",
+    "default": "fido",
+    "description": "Name of the pet"
+  }
+]
+`
+	if output != expect {
+		t.Errorf("Expected\n%q\nGot\n%q\n", expect, output)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// Labels added to the resources of a release with StandardLabels, along with
+// appInstanceLabel and app.kubernetes.io/managed-by, which Helm always sets.
+const (
+	appNameLabel    = "app.kubernetes.io/name"
+	appVersionLabel = "app.kubernetes.io/version"
+	helmChartLabel  = "helm.sh/chart"
+)
+
+// LabelConflictPolicy tells what to do with a standard label that a resource
+// already sets to another value.
+type LabelConflictPolicy string
+
+const (
+	// LabelConflictKeep keeps the value set by the chart.
+	LabelConflictKeep LabelConflictPolicy = "keep"
+	// LabelConflictOverwrite replaces the value set by the chart.
+	LabelConflictOverwrite LabelConflictPolicy = "overwrite"
+	// LabelConflictError fails the operation.
+	LabelConflictError LabelConflictPolicy = "error"
+)
+
+// ParseLabelConflictPolicy returns the policy of a name. The empty name is
+// LabelConflictKeep.
+func ParseLabelConflictPolicy(s string) (LabelConflictPolicy, error) {
+	switch p := LabelConflictPolicy(s); p {
+	case LabelConflictKeep, LabelConflictOverwrite, LabelConflictError:
+		return p, nil
+	case "":
+		return LabelConflictKeep, nil
+	}
+	return "", errors.Errorf("invalid label conflict policy %q, must be one of keep, overwrite or error", s)
+}
+
+// StandardLabels returns the recommended labels of the resources of a release
+// of a chart: app.kubernetes.io/name, instance and version, and helm.sh/chart.
+// Values are made valid label values, and labels without a value, such as the
+// version of charts without an app version, are left out.
+func StandardLabels(releaseName string, md *chart.Metadata) map[string]string {
+	labels := map[string]string{
+		appNameLabel:     labelValue(md.Name),
+		appInstanceLabel: labelValue(releaseName),
+		appVersionLabel:  labelValue(md.AppVersion),
+		helmChartLabel:   labelValue(md.Name + "-" + md.Version),
+	}
+	for k, v := range labels {
+		if v == "" {
+			delete(labels, k)
+		}
+	}
+	return labels
+}
+
+var invalidLabelChars = regexp.MustCompile(`[^-_.A-Za-z0-9]`)
+
+// labelValue turns s into a valid label value, replacing the characters that
+// are not allowed, such as the '+' of versions, with '_' and truncating it.
+func labelValue(s string) string {
+	s = invalidLabelChars.ReplaceAllString(s, "_")
+	if len(s) > validation.LabelValueMaxLength {
+		s = s[:validation.LabelValueMaxLength]
+	}
+	return strings.Trim(s, "-_.")
+}
+
+// setStandardLabelsVisitor adds standard labels to all resources. The labels
+// that a resource already sets to another value are handled according to the
+// policy.
+func setStandardLabelsVisitor(labels map[string]string, policy LabelConflictPolicy) resource.VisitorFunc {
+	return func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+
+		current, err := accessor.Labels(info.Object)
+		if err != nil {
+			return fmt.Errorf("%s labels could not be read: %s", resourceString(info), err)
+		}
+		desired := map[string]string{}
+		for k, v := range labels {
+			if actual, ok := current[k]; ok && actual != v {
+				switch policy {
+				case LabelConflictKeep, "":
+					continue
+				case LabelConflictError:
+					return fmt.Errorf("%s sets label %q to %q instead of %q", resourceString(info), k, actual, v)
+				}
+			}
+			desired[k] = v
+		}
+
+		if err := mergeLabels(info.Object, desired); err != nil {
+			return fmt.Errorf("%s labels could not be updated: %s", resourceString(info), err)
+		}
+		return nil
+	}
+}
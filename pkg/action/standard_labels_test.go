@@ -0,0 +1,93 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/kube"
+)
+
+func TestStandardLabels(t *testing.T) {
+	is := assert.New(t)
+
+	is.Equal(map[string]string{
+		"app.kubernetes.io/name":     "wordpress",
+		"app.kubernetes.io/instance": "blog",
+		"app.kubernetes.io/version":  "5.8.1_build.3",
+		"helm.sh/chart":              "wordpress-12.1.0",
+	}, StandardLabels("blog", &chart.Metadata{Name: "wordpress", Version: "12.1.0", AppVersion: "5.8.1+build.3"}))
+
+	labels := StandardLabels("blog", &chart.Metadata{Name: "wordpress", Version: "1.0.0-" + strings.Repeat("x", 70)})
+	is.NotContains(labels, "app.kubernetes.io/version")
+	is.Len(labels["helm.sh/chart"], 63)
+}
+
+func TestParseLabelConflictPolicy(t *testing.T) {
+	is := assert.New(t)
+
+	for s, expect := range map[string]LabelConflictPolicy{
+		"":          LabelConflictKeep,
+		"keep":      LabelConflictKeep,
+		"overwrite": LabelConflictOverwrite,
+		"error":     LabelConflictError,
+	} {
+		p, err := ParseLabelConflictPolicy(s)
+		is.NoError(err)
+		is.Equal(expect, p)
+	}
+	_, err := ParseLabelConflictPolicy("replace")
+	is.Error(err)
+}
+
+func TestSetStandardLabelsVisitor(t *testing.T) {
+	labels := map[string]string{
+		"app.kubernetes.io/name":     "wordpress",
+		"app.kubernetes.io/instance": "blog",
+	}
+	newResources := func() kube.ResourceList {
+		web := newDeploymentResource("web", "default")
+		mergeLabels(web.Object, map[string]string{"app.kubernetes.io/name": "frontend", "tier": "web"})
+		return kube.ResourceList{web, newDeploymentResource("db", "default")}
+	}
+
+	t.Run("keep", func(t *testing.T) {
+		resources := newResources()
+		assert.NoError(t, resources.Visit(setStandardLabelsVisitor(labels, LabelConflictKeep)))
+		web, _ := accessor.Labels(resources[0].Object)
+		assert.Equal(t, map[string]string{"app.kubernetes.io/name": "frontend", "app.kubernetes.io/instance": "blog", "tier": "web"}, web)
+		db, _ := accessor.Labels(resources[1].Object)
+		assert.Equal(t, labels, db)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		resources := newResources()
+		assert.NoError(t, resources.Visit(setStandardLabelsVisitor(labels, LabelConflictOverwrite)))
+		web, _ := accessor.Labels(resources[0].Object)
+		assert.Equal(t, map[string]string{"app.kubernetes.io/name": "wordpress", "app.kubernetes.io/instance": "blog", "tier": "web"}, web)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		resources := newResources()
+		err := resources.Visit(setStandardLabelsVisitor(labels, LabelConflictError))
+		assert.EqualError(t, err, `Deployment "web" in namespace "" sets label "app.kubernetes.io/name" to "frontend" instead of "wordpress"`)
+	})
+}
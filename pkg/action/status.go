@@ -32,6 +32,10 @@ type Status struct {
 	// only affect print type table.
 	// TODO Helm 4: Remove this flag and output the description by default.
 	ShowDescription bool
+
+	// If true, display the last run of each hook and the state of its
+	// resource, only affect print type table.
+	ShowHooks bool
 }
 
 // NewStatus creates a new Status object with the given configuration.
@@ -0,0 +1,204 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// Reasons for collecting release records.
+const (
+	// GarbageOrphaned is the reason of the records of the releases whose
+	// resources are all gone.
+	GarbageOrphaned = "orphaned"
+	// GarbageSuperseded is the reason of the superseded revisions beyond the
+	// history kept per release.
+	GarbageSuperseded = "superseded"
+	// GarbageStalePending is the reason of the revisions left pending by a
+	// crashed operation for longer than the pending TTL.
+	GarbageStalePending = "stale-pending"
+)
+
+// GarbageRecord is a release record that can be collected.
+type GarbageRecord struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Revision  int    `json:"revision"`
+	Status    string `json:"status"`
+	// Reason is why the record can be collected, one of the Garbage
+	// constants
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// StorageGC is the action for collecting the garbage of the release storage.
+//
+// It provides the implementation of 'helm storage gc'.
+type StorageGC struct {
+	cfg *Configuration
+
+	// Orphaned collects the records of the releases whose latest revision
+	// is deployed or failed and none of its resources exist anymore.
+	Orphaned bool
+	// MaxHistory is the number of revisions kept per release. The superseded
+	// revisions beyond it are collected. Zero keeps them all.
+	MaxHistory int
+	// PendingTTL is how long a revision can be pending before it is
+	// considered left behind by a crashed operation and collected. Zero
+	// keeps the pending revisions.
+	PendingTTL time.Duration
+}
+
+// NewStorageGC creates a new StorageGC object with the given configuration.
+func NewStorageGC(cfg *Configuration) *StorageGC {
+	return &StorageGC{
+		cfg:        cfg,
+		Orphaned:   true,
+		PendingTTL: 24 * time.Hour,
+	}
+}
+
+// Find returns the records that can be collected, sorted by namespace, name
+// and revision. Nothing is deleted.
+func (g *StorageGC) Find() ([]GarbageRecord, error) {
+	var client kube.InterfaceResources
+	if g.Orphaned {
+		if err := g.cfg.KubeClient.IsReachable(); err != nil {
+			return nil, err
+		}
+		c, ok := g.cfg.KubeClient.(kube.InterfaceResources)
+		if !ok {
+			return nil, errors.New("the Kubernetes client does not support listing resources, which is required to find orphaned releases")
+		}
+		client = c
+	}
+
+	rels, err := g.cfg.Releases.ListReleases()
+	if err != nil {
+		return nil, err
+	}
+	histories := map[string][]*release.Release{}
+	var keys []string
+	for _, rel := range rels {
+		key := rel.Namespace + "/" + rel.Name
+		if _, ok := histories[key]; !ok {
+			keys = append(keys, key)
+		}
+		histories[key] = append(histories[key], rel)
+	}
+	sort.Strings(keys)
+
+	var garbage []GarbageRecord
+	for _, key := range keys {
+		history := histories[key]
+		releaseutil.SortByRevision(history)
+		found, err := g.findInHistory(client, history)
+		if err != nil {
+			return nil, err
+		}
+		garbage = append(garbage, found...)
+	}
+	return garbage, nil
+}
+
+// findInHistory returns the records of the history of a release, sorted by
+// revision, that can be collected.
+func (g *StorageGC) findInHistory(client kube.InterfaceResources, history []*release.Release) ([]GarbageRecord, error) {
+	latest := history[len(history)-1]
+	status := latest.Info.Status
+
+	if client != nil && (status == release.StatusDeployed || status == release.StatusFailed) {
+		orphaned, err := g.orphaned(client, latest)
+		if err != nil {
+			return nil, err
+		}
+		if orphaned {
+			garbage := make([]GarbageRecord, 0, len(history))
+			for _, rel := range history {
+				garbage = append(garbage, newGarbageRecord(rel, GarbageOrphaned, "none of the resources of the release exist"))
+			}
+			return garbage, nil
+		}
+	}
+
+	var garbage []GarbageRecord
+	if g.MaxHistory > 0 && len(history) > g.MaxHistory {
+		for _, rel := range history[:len(history)-g.MaxHistory] {
+			if rel.Info.Status == release.StatusSuperseded {
+				garbage = append(garbage, newGarbageRecord(rel, GarbageSuperseded, fmt.Sprintf("beyond the %d revisions kept per release", g.MaxHistory)))
+			}
+		}
+	}
+	if g.PendingTTL > 0 && status.IsPending() {
+		if age := g.cfg.Now().Sub(latest.Info.LastDeployed); age > g.PendingTTL {
+			garbage = append(garbage, newGarbageRecord(latest, GarbageStalePending, fmt.Sprintf("%s for %s, longer than %s", status, age.Round(time.Second), g.PendingTTL)))
+		}
+	}
+	return garbage, nil
+}
+
+// orphaned returns whether none of the resources of the manifest of the
+// release exist. Releases without resources are never orphaned.
+func (g *StorageGC) orphaned(client kube.InterfaceResources, rel *release.Release) (bool, error) {
+	resources, err := g.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		// The resources of kinds that are gone cannot be built either, so
+		// the release is kept rather than guessed about
+		g.cfg.Log("storage gc: unable to build the resources of %s: %s", rel.Name, err)
+		return false, nil
+	}
+	if len(resources) == 0 {
+		return false, nil
+	}
+	g.cfg.applyGeneratedNames(resources, rel)
+	live, err := client.Get(resources)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to get the resources of release %q", rel.Name)
+	}
+	return len(live) == 0, nil
+}
+
+// Collect deletes the given records from the storage.
+func (g *StorageGC) Collect(garbage []GarbageRecord) error {
+	for _, r := range garbage {
+		g.cfg.Log("storage gc: deleting revision %d of %s (%s)", r.Revision, r.Name, r.Reason)
+		if _, err := g.cfg.Releases.Delete(r.Name, r.Revision); err != nil {
+			return errors.Wrapf(err, "unable to delete revision %d of release %q", r.Revision, r.Name)
+		}
+	}
+	return nil
+}
+
+func newGarbageRecord(rel *release.Release, reason, message string) GarbageRecord {
+	return GarbageRecord{
+		Name:      rel.Name,
+		Namespace: rel.Namespace,
+		Revision:  rel.Version,
+		Status:    rel.Info.Status.String(),
+		Reason:    reason,
+		Message:   message,
+	}
+}
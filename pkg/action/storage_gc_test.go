@@ -0,0 +1,123 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const (
+	gcLiveManifest    = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: alive\n"
+	gcMissingManifest = "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: gone\n"
+)
+
+func storageGCFixture(t *testing.T) *Configuration {
+	t.Helper()
+	config := actionConfigFixture(t)
+	config.KubeClient = &resourcesKubeClient{
+		live: kube.ResourceList{fakeObject(gcLiveManifest)},
+	}
+
+	add := func(name string, version int, status release.Status, manifest string, age time.Duration) {
+		rel := namedReleaseStub(name, status)
+		rel.Namespace = "spaced"
+		rel.Version = version
+		rel.Manifest = manifest
+		rel.Info.LastDeployed = config.Now().Add(-age)
+		if err := config.Releases.Create(rel); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("orphan", 1, release.StatusSuperseded, gcMissingManifest, 0)
+	add("orphan", 2, release.StatusDeployed, gcMissingManifest, 0)
+	for v := 1; v < 4; v++ {
+		add("alive", v, release.StatusSuperseded, gcLiveManifest, 0)
+	}
+	add("alive", 4, release.StatusDeployed, gcLiveManifest, 0)
+	add("stuck", 1, release.StatusPendingInstall, gcLiveManifest, 48*time.Hour)
+	add("busy", 1, release.StatusDeployed, gcLiveManifest, 0)
+	add("busy", 2, release.StatusPendingUpgrade, gcLiveManifest, time.Minute)
+	return config
+}
+
+func garbageKeys(garbage []GarbageRecord) []string {
+	var keys []string
+	for _, r := range garbage {
+		keys = append(keys, fmt.Sprintf("%s.v%d %s", r.Name, r.Revision, r.Reason))
+	}
+	return keys
+}
+
+func TestStorageGCFind(t *testing.T) {
+	is := assert.New(t)
+	gc := NewStorageGC(storageGCFixture(t))
+	gc.MaxHistory = 2
+
+	garbage, err := gc.Find()
+	is.NoError(err)
+	is.Equal([]string{
+		"alive.v1 superseded",
+		"alive.v2 superseded",
+		"orphan.v1 orphaned",
+		"orphan.v2 orphaned",
+		"stuck.v1 stale-pending",
+	}, garbageKeys(garbage))
+}
+
+func TestStorageGCFindDisabled(t *testing.T) {
+	is := assert.New(t)
+	gc := NewStorageGC(storageGCFixture(t))
+	gc.Orphaned = false
+	gc.PendingTTL = 0
+
+	garbage, err := gc.Find()
+	is.NoError(err)
+	is.Empty(garbage)
+}
+
+func TestStorageGCCollect(t *testing.T) {
+	is := assert.New(t)
+	config := storageGCFixture(t)
+	gc := NewStorageGC(config)
+
+	garbage, err := gc.Find()
+	is.NoError(err)
+	is.NoError(gc.Collect(garbage))
+
+	_, err = config.Releases.History("orphan")
+	is.Error(err, "the orphaned release should be gone")
+	_, err = config.Releases.History("stuck")
+	is.Error(err, "the stale pending release should be gone")
+	history, err := config.Releases.History("alive")
+	is.NoError(err)
+	is.Len(history, 4)
+}
+
+func TestStorageGCUnsupportedClient(t *testing.T) {
+	config := actionConfigFixture(t)
+	config.KubeClient = unsupportedKubeClient{config.KubeClient}
+	if _, err := NewStorageGC(config).Find(); err == nil {
+		t.Error("expected an error finding orphaned releases without listing resources")
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"sort"
+
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// Statuses of the checks of the release storage.
+const (
+	StorageCheckOK      = "ok"
+	StorageCheckWarning = "warning"
+	StorageCheckFailed  = "failed"
+)
+
+// recordSizeWarningRatio is the share of the size limit of the records above
+// which a warning is reported.
+const recordSizeWarningRatio = 0.75
+
+// StoredRecord is a release revision as stored by the storage driver.
+type StoredRecord struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Revision  int    `json:"revision"`
+	Status    string `json:"status"`
+	// Size is the size of the encoded record in bytes
+	Size int `json:"size"`
+}
+
+// StorageCheck is the result of a check of the release storage.
+type StorageCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// StorageReport describes the release storage and its health.
+type StorageReport struct {
+	Driver    string `json:"driver"`
+	Namespace string `json:"namespace,omitempty"`
+	// Releases is the number of releases, and Revisions the number of
+	// records of all their revisions
+	Releases  int            `json:"releases"`
+	Revisions int            `json:"revisions"`
+	ByStatus  map[string]int `json:"by_status"`
+	// MaxRecordSize is the size limit of the records in bytes, if the driver
+	// has one
+	MaxRecordSize int `json:"max_record_size,omitempty"`
+	// Largest are the largest records, largest first
+	Largest []StoredRecord `json:"largest"`
+	Checks  []StorageCheck `json:"checks"`
+}
+
+// StorageInfo is the action for describing the release storage.
+//
+// It provides the implementation of 'helm env storage'.
+type StorageInfo struct {
+	cfg *Configuration
+
+	// Namespace is the namespace the storage driver is configured for. It
+	// is only reported.
+	Namespace string
+	// Top is the number of largest records to report
+	Top int
+}
+
+// NewStorageInfo creates a new StorageInfo object with the given
+// configuration.
+func NewStorageInfo(cfg *Configuration) *StorageInfo {
+	return &StorageInfo{
+		cfg: cfg,
+		Top: 5,
+	}
+}
+
+// Run describes the release storage. Failures to reach the storage are
+// reported as failed checks rather than returned, so that they can be
+// diagnosed.
+func (s *StorageInfo) Run() (*StorageReport, error) {
+	d := s.cfg.Releases.Driver
+	report := &StorageReport{
+		Driver:    d.Name(),
+		Namespace: s.Namespace,
+		ByStatus:  map[string]int{},
+		Largest:   []StoredRecord{},
+	}
+
+	if p, ok := d.(driver.Pinger); ok {
+		check := StorageCheck{Name: "connectivity", Status: StorageCheckOK, Message: "the store can be reached"}
+		if err := p.Ping(); err != nil {
+			check.Status, check.Message = StorageCheckFailed, err.Error()
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	rels, err := s.cfg.Releases.ListReleases()
+	if err != nil {
+		report.Checks = append(report.Checks, StorageCheck{Name: "records", Status: StorageCheckFailed, Message: err.Error()})
+		return report, nil
+	}
+	report.Checks = append(report.Checks, StorageCheck{Name: "records", Status: StorageCheckOK, Message: "the records can be listed and decoded"})
+
+	names := map[string]bool{}
+	var records []StoredRecord
+	for _, rel := range rels {
+		names[rel.Namespace+"/"+rel.Name] = true
+		status := ""
+		if rel.Info != nil {
+			status = rel.Info.Status.String()
+		}
+		report.ByStatus[status]++
+		size, err := driver.EncodedSize(rel)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, StoredRecord{
+			Name:      rel.Name,
+			Namespace: rel.Namespace,
+			Revision:  rel.Version,
+			Status:    status,
+			Size:      size,
+		})
+	}
+	report.Releases = len(names)
+	report.Revisions = len(records)
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Size > records[j].Size })
+	if len(records) > s.Top {
+		report.Largest = append(report.Largest, records[:s.Top]...)
+	} else {
+		report.Largest = append(report.Largest, records...)
+	}
+
+	if l, ok := d.(driver.SizeLimiter); ok {
+		report.MaxRecordSize = l.MaxRecordSize()
+		report.Checks = append(report.Checks, recordSizeCheck(records, report.MaxRecordSize))
+	}
+	return report, nil
+}
+
+// recordSizeCheck checks how close the records, sorted largest first, are to
+// the size limit.
+func recordSizeCheck(records []StoredRecord, limit int) StorageCheck {
+	check := StorageCheck{Name: "record size", Status: StorageCheckOK}
+	var near []StoredRecord
+	for _, r := range records {
+		if float64(r.Size) >= float64(limit)*recordSizeWarningRatio {
+			near = append(near, r)
+		}
+	}
+	switch {
+	case len(near) > 0:
+		check.Status = StorageCheckWarning
+		r := near[0]
+		check.Message = fmt.Sprintf("%d record(s) use more than %d%% of the size limit of %d bytes, the largest being revision %d of %q at %d bytes. Upgrades may fail once the limit is reached: consider reducing the size of the chart or of its values",
+			len(near), int(recordSizeWarningRatio*100), limit, r.Revision, r.Name, r.Size)
+	case len(records) > 0:
+		check.Message = fmt.Sprintf("the largest record uses %d%% of the size limit of %d bytes", records[0].Size*100/limit, limit)
+	default:
+		check.Message = fmt.Sprintf("no records, the size limit is %d bytes", limit)
+	}
+	return check
+}
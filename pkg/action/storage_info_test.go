@@ -0,0 +1,70 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestStorageInfo(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+
+	small := namedReleaseStub("small", release.StatusDeployed)
+	large := namedReleaseStub("large", release.StatusSuperseded)
+	large.Manifest = strings.Repeat("a large manifest\n", 1000)
+	latest := namedReleaseStub("large", release.StatusDeployed)
+	latest.Version = 2
+	for _, rel := range []*release.Release{small, large, latest} {
+		if err := config.Releases.Create(rel); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := NewStorageInfo(config)
+	client.Top = 2
+	report, err := client.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.Equal("Memory", report.Driver)
+	is.Equal(2, report.Releases)
+	is.Equal(3, report.Revisions)
+	is.Equal(map[string]int{"deployed": 2, "superseded": 1}, report.ByStatus)
+	is.Len(report.Largest, 2)
+	is.Equal("large", report.Largest[0].Name)
+	is.Equal(1, report.Largest[0].Revision)
+	is.Zero(report.MaxRecordSize)
+	is.Equal([]StorageCheck{{Name: "records", Status: StorageCheckOK, Message: "the records can be listed and decoded"}}, report.Checks)
+}
+
+func TestRecordSizeCheck(t *testing.T) {
+	is := assert.New(t)
+
+	check := recordSizeCheck([]StoredRecord{{Name: "small", Revision: 1, Size: 100}}, 1000)
+	is.Equal(StorageCheckOK, check.Status)
+	is.Equal("the largest record uses 10% of the size limit of 1000 bytes", check.Message)
+
+	check = recordSizeCheck([]StoredRecord{{Name: "large", Revision: 3, Size: 900}, {Name: "large", Revision: 2, Size: 800}, {Name: "small", Revision: 1, Size: 100}}, 1000)
+	is.Equal(StorageCheckWarning, check.Status)
+	is.Contains(check.Message, `2 record(s) use more than 75% of the size limit of 1000 bytes, the largest being revision 3 of "large" at 900 bytes`)
+}
@@ -0,0 +1,173 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// subchartSelection restricts a chart to some of its subcharts. Subcharts are
+// designated by name, or by alias when they have one, and nested subcharts by
+// dotted paths such as "backend.redis".
+type subchartSelection struct {
+	only []string
+	skip []string
+}
+
+// newSubchartSelection checks that the chart declares the subcharts to keep
+// and to skip. It must be called before the dependencies of the chart are
+// processed, as the subcharts disabled by their conditions and tags are
+// removed then. It returns nil if no subchart is selected.
+func newSubchartSelection(chrt *chart.Chart, only, skip []string) (*subchartSelection, error) {
+	if len(only) == 0 && len(skip) == 0 {
+		return nil, nil
+	}
+	declared := make(map[string]bool)
+	declaredSubcharts(chrt, "", declared)
+	for _, p := range append(append([]string{}, only...), skip...) {
+		if !declared[p] {
+			return nil, errors.Errorf("chart %s has no subchart %q", chrt.Name(), p)
+		}
+	}
+	return &subchartSelection{only: only, skip: skip}, nil
+}
+
+// declaredSubcharts adds the paths of the subcharts of c to paths. Aliased
+// subcharts are known by their aliases only, unless they are also required
+// without one.
+func declaredSubcharts(c *chart.Chart, prefix string, paths map[string]bool) {
+	for _, d := range c.Dependencies() {
+		var names []string
+		plain, required := false, false
+		for _, req := range c.Metadata.Dependencies {
+			if req.Name != d.Name() {
+				continue
+			}
+			required = true
+			if req.Alias != "" {
+				names = append(names, req.Alias)
+			} else {
+				plain = true
+			}
+		}
+		if plain || !required {
+			names = append(names, d.Name())
+		}
+		for _, n := range names {
+			paths[prefix+n] = true
+			declaredSubcharts(d, prefix+n+".", paths)
+		}
+	}
+}
+
+// apply removes the skipped subcharts from the chart and, if subcharts are
+// kept, everything else: the other subcharts and the templates of the charts
+// above the kept subcharts, except their partials. It must be called once the
+// dependencies of the chart are processed, and fails if a subchart to keep is
+// disabled by its condition or tags.
+func (s *subchartSelection) apply(chrt *chart.Chart) error {
+	if s == nil {
+		return nil
+	}
+
+	for _, p := range s.skip {
+		parent, name := subchartParent(chrt, p)
+		if parent == nil {
+			// Disabled already
+			continue
+		}
+		var deps []*chart.Chart
+		for _, d := range parent.Dependencies() {
+			if d.Name() != name {
+				deps = append(deps, d)
+			}
+		}
+		parent.SetDependencies(deps...)
+	}
+
+	if len(s.only) == 0 {
+		return nil
+	}
+	keep := make(map[*chart.Chart]bool)
+	for _, p := range s.only {
+		parent, name := subchartParent(chrt, p)
+		sub := findSubchart(parent, name)
+		if sub == nil {
+			return errors.Errorf("subchart %q is disabled by its condition or tags", p)
+		}
+		keep[sub] = true
+	}
+	restrictToSubcharts(chrt, keep)
+	return nil
+}
+
+// restrictToSubcharts removes from c the subcharts that neither are kept nor
+// lead to kept subcharts, and the templates of c if it is not kept itself. It
+// returns whether c is or leads to a kept subchart.
+func restrictToSubcharts(c *chart.Chart, keep map[*chart.Chart]bool) bool {
+	if keep[c] {
+		return true
+	}
+
+	var deps []*chart.Chart
+	for _, d := range c.Dependencies() {
+		if restrictToSubcharts(d, keep) {
+			deps = append(deps, d)
+		}
+	}
+	c.SetDependencies(deps...)
+
+	var partials []*chart.File
+	for _, t := range c.Templates {
+		if strings.HasPrefix(path.Base(t.Name), "_") {
+			partials = append(partials, t)
+		}
+	}
+	c.Templates = partials
+	return len(deps) > 0
+}
+
+// subchartParent returns the chart holding the subchart at the dotted path p,
+// and the name of the subchart in it. The parent is nil if a chart on the way
+// is disabled.
+func subchartParent(chrt *chart.Chart, p string) (*chart.Chart, string) {
+	names := strings.Split(p, ".")
+	parent := chrt
+	for _, name := range names[:len(names)-1] {
+		if parent = findSubchart(parent, name); parent == nil {
+			return nil, ""
+		}
+	}
+	return parent, names[len(names)-1]
+}
+
+func findSubchart(c *chart.Chart, name string) *chart.Chart {
+	if c == nil {
+		return nil
+	}
+	for _, d := range c.Dependencies() {
+		if d.Name() == name {
+			return d
+		}
+	}
+	return nil
+}
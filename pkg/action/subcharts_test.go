@@ -0,0 +1,139 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// umbrellaChart builds a chart with the subcharts redis, metrics, disabled
+// by default, and backend, itself with a cache subchart aliased from redis.
+func umbrellaChart() *chart.Chart {
+	return buildChart(
+		withName("umbrella"),
+		withSampleTemplates(),
+		withValues(map[string]interface{}{
+			"metrics": map[string]interface{}{"enabled": false},
+		}),
+		withDependency(withName("redis")),
+		withDependency(withName("metrics")),
+		withDependency(
+			withName("backend"),
+			withDependency(withName("redis")),
+			withMetadataDependency(chart.Dependency{Name: "redis", Version: "0.1.0", Alias: "cache"}),
+		),
+		withMetadataDependency(chart.Dependency{Name: "redis", Version: "0.1.0"}),
+		withMetadataDependency(chart.Dependency{Name: "metrics", Version: "0.1.0", Condition: "metrics.enabled"}),
+		withMetadataDependency(chart.Dependency{Name: "backend", Version: "0.1.0"}),
+	)
+}
+
+// renderedSources returns the templates rendered in a release manifest,
+// sorted.
+func renderedSources(manifest string) []string {
+	var sources []string
+	for _, line := range strings.Split(manifest, "\n") {
+		if strings.HasPrefix(line, "# Source: ") {
+			sources = append(sources, strings.TrimPrefix(line, "# Source: "))
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+func TestInstallSubchartSelection(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		only     []string
+		skip     []string
+		expected string
+	}{{
+		name:     "all the enabled subcharts",
+		expected: "umbrella/charts/backend/charts/cache/templates/hello,umbrella/charts/backend/templates/hello,umbrella/charts/redis/templates/hello,umbrella/templates/goodbye,umbrella/templates/hello,umbrella/templates/with-partials",
+	}, {
+		name:     "only a subchart",
+		only:     []string{"redis"},
+		expected: "umbrella/charts/redis/templates/hello",
+	}, {
+		name:     "only a nested aliased subchart",
+		only:     []string{"backend.cache"},
+		expected: "umbrella/charts/backend/charts/cache/templates/hello",
+	}, {
+		name:     "only a subchart and a subchart of another",
+		only:     []string{"redis", "backend.cache"},
+		expected: "umbrella/charts/backend/charts/cache/templates/hello,umbrella/charts/redis/templates/hello",
+	}, {
+		name:     "skipped subcharts",
+		skip:     []string{"redis", "backend.cache", "metrics"},
+		expected: "umbrella/charts/backend/templates/hello,umbrella/templates/goodbye,umbrella/templates/hello,umbrella/templates/with-partials",
+	}, {
+		name:     "only a subchart without one of its subcharts",
+		only:     []string{"backend"},
+		skip:     []string{"backend.cache"},
+		expected: "umbrella/charts/backend/templates/hello",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			instAction := installAction(t)
+			instAction.OnlySubcharts = tt.only
+			instAction.SkipSubcharts = tt.skip
+
+			rel, err := instAction.Run(umbrellaChart(), map[string]interface{}{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(renderedSources(rel.Manifest), ","); got != tt.expected {
+				t.Errorf("expected the templates %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestInstallSubchartSelectionErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		only     []string
+		skip     []string
+		expected string
+	}{{
+		name:     "unknown subchart",
+		only:     []string{"postgresql"},
+		expected: `chart umbrella has no subchart "postgresql"`,
+	}, {
+		name:     "unknown nested subchart",
+		skip:     []string{"backend.redis"},
+		expected: `chart umbrella has no subchart "backend.redis"`,
+	}, {
+		name:     "disabled subchart",
+		only:     []string{"metrics"},
+		expected: `subchart "metrics" is disabled by its condition or tags`,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			instAction := installAction(t)
+			instAction.OnlySubcharts = tt.only
+			instAction.SkipSubcharts = tt.skip
+
+			_, err := instAction.Run(umbrellaChart(), map[string]interface{}{})
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("expected the error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -17,15 +17,16 @@ limitations under the License.
 package action
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/kube"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/releaseutil"
-	helmtime "helm.sh/helm/v3/pkg/time"
 )
 
 // Uninstall is the action for uninstalling releases.
@@ -39,6 +40,9 @@ type Uninstall struct {
 	KeepHistory  bool
 	Timeout      time.Duration
 	Description  string
+	// Resume resumes a partially failed uninstall. The release must still
+	// be uninstalling. The pre-delete hooks, which already ran, are skipped.
+	Resume bool
 }
 
 // NewUninstall creates a new Uninstall object with the given configuration.
@@ -78,6 +82,10 @@ func (u *Uninstall) Run(name string) (*release.UninstallReleaseResponse, error)
 	releaseutil.SortByRevision(rels)
 	rel := rels[len(rels)-1]
 
+	if u.Resume && rel.Info.Status != release.StatusUninstalling {
+		return nil, errors.Errorf("the release named %q cannot be resumed: it is %s, not %s", name, rel.Info.Status, release.StatusUninstalling)
+	}
+
 	// TODO: Are there any cases where we want to force a delete even if it's
 	// already marked deleted?
 	if rel.Info.Status == release.StatusUninstalled {
@@ -91,17 +99,18 @@ func (u *Uninstall) Run(name string) (*release.UninstallReleaseResponse, error)
 	}
 
 	u.cfg.Log("uninstall: Deleting %s", name)
+	report := &release.UninstallReport{Started: u.cfg.Now(), Resumed: u.Resume}
 	rel.Info.Status = release.StatusUninstalling
-	rel.Info.Deleted = helmtime.Now()
+	rel.Info.Deleted = report.Started
 	rel.Info.Description = "Deletion in progress (or silently failed)"
-	res := &release.UninstallReleaseResponse{Release: rel}
+	res := &release.UninstallReleaseResponse{Release: rel, Report: report}
 
-	if !u.DisableHooks {
-		if err := u.cfg.execHook(rel, release.HookPreDelete, u.Timeout); err != nil {
-			return res, err
-		}
-	} else {
+	if u.DisableHooks {
 		u.cfg.Log("delete hooks disabled for %s", name)
+	} else if u.Resume {
+		u.cfg.Log("uninstall: Resuming the deletion of %s, skipping the pre-delete hooks", name)
+	} else if err := u.cfg.execHook(rel, release.HookPreDelete, u.Timeout); err != nil {
+		return res, err
 	}
 
 	// From here on out, the release is currently considered to be in StatusUninstalling
@@ -110,8 +119,20 @@ func (u *Uninstall) Run(name string) (*release.UninstallReleaseResponse, error)
 		u.cfg.Log("uninstall: Failed to store updated release: %s", err)
 	}
 
-	kept, errs := u.deleteRelease(rel)
+	kept, errs := u.deleteRelease(rel, report)
 	res.Info = kept
+	report.Finished = u.cfg.Now()
+
+	// Keep the release record if resources could not be deleted, so that the
+	// uninstall can be resumed.
+	if failed := report.Failed(); len(failed) > 0 {
+		rel.Info.Description = fmt.Sprintf("Uninstallation failed: %d resource(s) could not be deleted", len(failed))
+		if err := u.cfg.Releases.Update(rel); err != nil {
+			u.cfg.Log("uninstall: Failed to store updated release: %s", err)
+		}
+		u.cfg.notify(EventUninstallFailed, rel, joinErrors(errs))
+		return res, errors.Errorf("uninstallation failed with %d error(s): %s\nrun 'helm uninstall %s --resume' to retry deleting the remaining resources", len(errs), joinErrors(errs), name)
+	}
 
 	if !u.DisableHooks {
 		if err := u.cfg.execHook(rel, release.HookPostDelete, u.Timeout); err != nil {
@@ -125,6 +146,7 @@ func (u *Uninstall) Run(name string) (*release.UninstallReleaseResponse, error)
 	} else {
 		rel.Info.Description = "Uninstallation complete"
 	}
+	u.cfg.notify(EventUninstallSucceeded, rel, "")
 
 	if !u.KeepHistory {
 		u.cfg.Log("purge requested for %s", name)
@@ -168,8 +190,9 @@ func joinErrors(errs []error) string {
 	return strings.Join(es, "; ")
 }
 
-// deleteRelease deletes the release and returns manifests that were kept in the deletion process
-func (u *Uninstall) deleteRelease(rel *release.Release) (string, []error) {
+// deleteRelease deletes the release and returns manifests that were kept in the deletion process.
+// The outcome of the deletion of each resource is added to the report.
+func (u *Uninstall) deleteRelease(rel *release.Release, report *release.UninstallReport) (string, []error) {
 	var errs []error
 	caps, err := u.cfg.getCapabilities()
 	if err != nil {
@@ -190,6 +213,11 @@ func (u *Uninstall) deleteRelease(rel *release.Release) (string, []error) {
 	var kept string
 	for _, f := range filesToKeep {
 		kept += f.Name + "\n"
+		r := release.DeletedResource{Kind: f.Head.Kind, Outcome: release.DeletionKept}
+		if f.Head.Metadata != nil {
+			r.Name = f.Head.Metadata.Name
+		}
+		report.Resources = append(report.Resources, r)
 	}
 
 	var builder strings.Builder
@@ -201,8 +229,29 @@ func (u *Uninstall) deleteRelease(rel *release.Release) (string, []error) {
 	if err != nil {
 		return "", []error{errors.Wrap(err, "unable to build kubernetes objects for delete")}
 	}
+	u.cfg.applyGeneratedNames(resources, rel)
 	if len(resources) > 0 {
-		_, errs = u.cfg.KubeClient.Delete(resources)
+		var result *kube.Result
+		result, errs = u.cfg.KubeClient.Delete(resources)
+		if result != nil {
+			for _, r := range result.Resources {
+				report.Resources = append(report.Resources, release.DeletedResource{
+					Kind:      r.Kind,
+					Name:      r.Name,
+					Namespace: r.Namespace,
+					Outcome:   deletionOutcomes[r.Outcome],
+					Error:     r.Error,
+				})
+			}
+		}
 	}
 	return kept, errs
 }
+
+// deletionOutcomes maps the outcomes of the deletion of resources to those of
+// the uninstall report.
+var deletionOutcomes = map[kube.Outcome]release.DeletionOutcome{
+	kube.OutcomeDeleted:  release.DeletionDeleted,
+	kube.OutcomeNotFound: release.DeletionNotFound,
+	kube.OutcomeFailed:   release.DeletionFailed,
+}
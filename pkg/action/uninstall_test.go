@@ -0,0 +1,155 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const uninstallManifest = `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: secret
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+  annotations:
+    helm.sh/resource-policy: keep
+`
+
+// failingDeleteKubeClient fails to delete the Secrets.
+type failingDeleteKubeClient struct {
+	resourcesKubeClient
+}
+
+func (c *failingDeleteKubeClient) Delete(resources kube.ResourceList) (*kube.Result, []error) {
+	res := &kube.Result{}
+	var errs []error
+	for _, info := range resources {
+		r := kube.ResourceResult{Kind: info.Mapping.GroupVersionKind.Kind, Name: info.Name, Namespace: info.Namespace, Outcome: kube.OutcomeDeleted}
+		if r.Kind == "Secret" {
+			err := errors.Errorf("secrets %q is forbidden", info.Name)
+			r.Outcome, r.Error = kube.OutcomeFailed, err.Error()
+			errs = append(errs, err)
+		}
+		res.Resources = append(res.Resources, r)
+	}
+	return res, errs
+}
+
+func uninstallReleaseStub(t *testing.T, config *Configuration) *release.Release {
+	rel := releaseStub()
+	rel.Namespace = "spaced"
+	rel.Manifest = uninstallManifest
+	rel.Hooks = nil
+	if err := config.Releases.Create(rel); err != nil {
+		t.Fatal(err)
+	}
+	return rel
+}
+
+func reportOutcomes(report *release.UninstallReport) map[string]release.DeletionOutcome {
+	outcomes := map[string]release.DeletionOutcome{}
+	for _, r := range report.Resources {
+		outcomes[r.Kind+"/"+r.Name] = r.Outcome
+	}
+	return outcomes
+}
+
+func TestUninstallRelease_Report(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+	config.KubeClient = &resourcesKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard}}
+	rel := uninstallReleaseStub(t, config)
+
+	res, err := NewUninstall(config).Run(rel.Name)
+	if err != nil {
+		t.Fatalf("Failed uninstall: %s", err)
+	}
+	is.Equal(map[string]release.DeletionOutcome{
+		"ConfigMap/config":           release.DeletionDeleted,
+		"Secret/secret":              release.DeletionDeleted,
+		"PersistentVolumeClaim/data": release.DeletionKept,
+	}, reportOutcomes(res.Report))
+	is.False(res.Report.Started.IsZero())
+	is.False(res.Report.Finished.Before(res.Report.Started))
+	is.False(res.Report.Resumed)
+
+	_, err = config.Releases.History(rel.Name)
+	is.Error(err, "the release should be purged")
+}
+
+func TestUninstallRelease_Resume(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+	config.KubeClient = &failingDeleteKubeClient{}
+	rel := uninstallReleaseStub(t, config)
+
+	res, err := NewUninstall(config).Run(rel.Name)
+	is.Error(err)
+	is.Contains(err.Error(), `secrets "secret" is forbidden`)
+	is.Contains(err.Error(), "--resume")
+	is.Equal(release.DeletionFailed, reportOutcomes(res.Report)["Secret/secret"])
+	is.Equal(`secrets "secret" is forbidden`, res.Report.Failed()[0].Error)
+
+	// The release is kept, still uninstalling, so that the uninstall can be
+	// resumed
+	last, err := config.Releases.Last(rel.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.Equal(release.StatusUninstalling, last.Info.Status)
+
+	config.KubeClient = &resourcesKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard}}
+	uninstall := NewUninstall(config)
+	uninstall.Resume = true
+	res, err = uninstall.Run(rel.Name)
+	if err != nil {
+		t.Fatalf("Failed to resume uninstall: %s", err)
+	}
+	is.True(res.Report.Resumed)
+	is.Equal(release.DeletionDeleted, reportOutcomes(res.Report)["Secret/secret"])
+
+	_, err = config.Releases.History(rel.Name)
+	is.Error(err, "the release should be purged")
+}
+
+func TestUninstallRelease_ResumeNotUninstalling(t *testing.T) {
+	config := actionConfigFixture(t)
+	rel := uninstallReleaseStub(t, config)
+
+	uninstall := NewUninstall(config)
+	uninstall.Resume = true
+	_, err := uninstall.Run(rel.Name)
+	assert.EqualError(t, err, `the release named "angry-panda" cannot be resumed: it is deployed, not uninstalling`)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/unittest"
+)
+
+// UnitTest is the action for running chart unit tests locally.
+//
+// It provides the implementation of 'helm test --unit'.
+type UnitTest struct {
+	// Filters restricts the suites and test cases to run, keyed by "suite"
+	// and "name". A test runs if it matches any of the given values.
+	Filters map[string][]string
+}
+
+// NewUnitTest creates a new UnitTest object.
+func NewUnitTest() *UnitTest {
+	return &UnitTest{
+		Filters: map[string][]string{},
+	}
+}
+
+// Run loads the chart at the given path and runs its unit test suites.
+func (u *UnitTest) Run(chartPath string) (*unittest.Report, error) {
+	ch, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, err
+	}
+
+	suites, err := unittest.LoadSuites(ch)
+	if err != nil {
+		return nil, err
+	}
+	if len(suites) == 0 {
+		return nil, errors.Errorf("chart %s has no unit tests in %s", ch.Name(), strings.TrimSuffix(unittest.TestsDir, "/"))
+	}
+
+	var selected []*unittest.Suite
+	for _, s := range suites {
+		if !matchesFilter(u.Filters["suite"], s.Name) {
+			continue
+		}
+		var cases []*unittest.Case
+		for _, c := range s.Tests {
+			if matchesFilter(u.Filters["name"], c.Name) {
+				cases = append(cases, c)
+			}
+		}
+		if len(cases) == 0 {
+			continue
+		}
+		cp := *s
+		cp.Tests = cases
+		selected = append(selected, &cp)
+	}
+
+	return unittest.Run(ch, selected...), nil
+}
+
+func matchesFilter(filter []string, value string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, f := range filter {
+		if f == value {
+			return true
+		}
+	}
+	return false
+}
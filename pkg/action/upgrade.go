@@ -33,6 +33,7 @@ import (
 	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/releaseutil"
+	"helm.sh/helm/v3/pkg/secrets"
 	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
@@ -66,8 +67,18 @@ type Upgrade struct {
 	Wait bool
 	// WaitForJobs determines whether the wait operation for the Jobs should be performed after the upgrade is requested.
 	WaitForJobs bool
+	// WaitFor lists conditions the release's resources must meet before the
+	// upgrade is marked as successful. It is honored independently of Wait.
+	WaitFor []kube.WaitCondition
+	// WaitForEndpoints is the number of ready endpoints each Service of the
+	// release must have before the upgrade is marked as successful. It is
+	// honored independently of Wait.
+	WaitForEndpoints int
 	// DisableHooks disables hook processing if set to true.
 	DisableHooks bool
+	// RetryFailedMigrations runs the migration hooks that failed or were
+	// interrupted in the last revision again, instead of refusing to upgrade.
+	RetryFailedMigrations bool
 	// DryRun controls whether the operation is prepared, but not executed.
 	// If `true`, the upgrade is prepared but not performed.
 	DryRun bool
@@ -75,6 +86,10 @@ type Upgrade struct {
 	//
 	// This should be used with caution.
 	Force bool
+	// DeletionTimeout is how long to wait for the deletion of the resources
+	// that are recreated, as they cannot be replaced, when Force is set. Zero
+	// disables the recreation.
+	DeletionTimeout time.Duration
 	// ResetValues will reset the values to the chart's built-ins rather than merging with existing.
 	ResetValues bool
 	// ReuseValues will re-use the user's last supplied values.
@@ -96,8 +111,78 @@ type Upgrade struct {
 	// If this is non-nil, then after templates are rendered, they will be sent to the
 	// post renderer before sending to the Kuberntes API server.
 	PostRenderer postrender.PostRenderer
+	// SecretResolver replaces the secret references of the values by their
+	// secrets when the chart is rendered. References are left as is if nil.
+	SecretResolver *secrets.Resolver
 	// DisableOpenAPIValidation controls whether OpenAPI validation is enforced.
 	DisableOpenAPIValidation bool
+	// ValuesRefs are references to values overlays stored in registries. They
+	// are merged in order beneath the values. If none are given and values
+	// are reused, the overlays of the current release are reused.
+	ValuesRefs []string
+	// OverridePause upgrades the release even if it is paused. The release
+	// stays paused.
+	OverridePause bool
+	// NoDeprecated refuses to upgrade to deprecated charts.
+	NoDeprecated bool
+	// StrictCompatibility refuses to upgrade to charts whose compatibility
+	// matrix does not support their app version, the Kubernetes version or
+	// the chart version of the release being upgraded.
+	StrictCompatibility bool
+	// StrictValues refuses to upgrade with values that the schema of the chart
+	// or of one of its subcharts deprecates, instead of warning about them.
+	StrictValues bool
+	// Incompatibilities is set by Run to the versions that the compatibility
+	// matrices of the chart and its subcharts do not support.
+	Incompatibilities []ChartIncompatibility
+	// AllowOwnershipTransfer adopts existing resources owned by another
+	// release or by other field managers instead of failing.
+	AllowOwnershipTransfer bool
+	// SkipIfUnchanged skips the upgrade if the rendered manifests, the values
+	// and the chart are identical to those of the deployed revision, so that
+	// no new revision is created.
+	SkipIfUnchanged bool
+	// OnlySubcharts restricts the release to the given subcharts, as for
+	// Install. The resources of the rest of the chart are deleted.
+	OnlySubcharts []string
+	// SkipSubcharts excludes the given subcharts from the release. Their
+	// resources are deleted.
+	SkipSubcharts []string
+	// RecordDefaults records Wait, WaitForJobs, Timeout and Atomic with the
+	// release, replacing the defaults of its later upgrades and rollbacks.
+	// Otherwise, the defaults of the last revision are kept.
+	RecordDefaults bool
+	// ServerDryRun, with DryRun, sends the resources and the hooks of the
+	// release to the API server in dry-run mode, so that they go through its
+	// admission policies and webhooks.
+	ServerDryRun bool
+	// Features are the features of the client enabled by users, available to
+	// templates as .Helm.Features along with the built-in ones.
+	Features chartutil.Features
+	// StandardLabels adds the recommended app.kubernetes.io labels and the
+	// helm.sh/chart label to the resources of the release when upgrading
+	// them.
+	StandardLabels bool
+	// LabelConflicts tells what to do with the standard labels that resources
+	// already set to other values. Empty means LabelConflictKeep.
+	LabelConflicts LabelConflictPolicy
+	// AdmissionResults is set by Run to the outcome of the server-side dry
+	// run of each resource, then of each hook, when ServerDryRun is set.
+	AdmissionResults []kube.AdmissionResult
+	// Result is set by Run to the outcome of the update of the resources of
+	// the release, when they are sent to the cluster.
+	Result *kube.Result
+	// Skipped is set by Run if the upgrade was skipped because nothing
+	// changed. The deployed revision is returned instead of a new one.
+	Skipped bool
+	// Retry retries the upgrade when it fails with a retryable error, such
+	// as a webhook or the API server being unavailable. Nil disables retries.
+	Retry *RetryPolicy
+
+	// attempts are the failed attempts of the upgrade being retried.
+	attempts []release.Attempt
+	// sleep waits between attempts, time.Sleep unless tests replace it.
+	sleep func(time.Duration)
 }
 
 // NewUpgrade creates a new Upgrade object with the given configuration.
@@ -108,10 +193,44 @@ func NewUpgrade(cfg *Configuration) *Upgrade {
 }
 
 // Run executes the upgrade on the given release.
+//
+// With a retry policy, failed attempts are retried as it allows, each
+// creating a new revision if it gets that far. The failed attempts are
+// recorded in the revision of the last one.
 func (u *Upgrade) Run(name string, chart *chart.Chart, vals map[string]interface{}) (*release.Release, error) {
+	u.attempts = nil
+	if u.Retry == nil || u.DryRun {
+		return u.run(name, chart, vals)
+	}
+
+	sleep := u.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	for attempt := 1; ; attempt++ {
+		startedAt := u.cfg.Now()
+		res, err := u.run(name, chart, vals)
+		if err == nil || attempt >= u.Retry.Attempts || !u.Retry.retryable(err) {
+			return res, err
+		}
+
+		failed := release.Attempt{StartedAt: startedAt, Error: err.Error()}
+		if res != nil {
+			failed.Revision = res.Version
+		}
+		u.attempts = append(u.attempts, failed)
+
+		delay := u.Retry.backoff(attempt)
+		u.cfg.Log("upgrade of %s failed, retrying in %s (attempt %d of %d): %s", name, delay, attempt+1, u.Retry.Attempts, err)
+		sleep(delay)
+	}
+}
+
+func (u *Upgrade) run(name string, chart *chart.Chart, vals map[string]interface{}) (*release.Release, error) {
 	if err := u.cfg.KubeClient.IsReachable(); err != nil {
 		return nil, err
 	}
+	defer u.cfg.startOperation()()
 
 	// Make sure if Atomic is set, that wait is set as well. This makes it so
 	// the user doesn't have to specify both
@@ -120,12 +239,33 @@ func (u *Upgrade) Run(name string, chart *chart.Chart, vals map[string]interface
 	if err := chartutil.ValidateReleaseName(name); err != nil {
 		return nil, errors.Errorf("release name is invalid: %s", name)
 	}
+	if u.NoDeprecated {
+		if err := checkDeprecations(chart); err != nil {
+			return nil, err
+		}
+	} else if chart != nil {
+		u.cfg.warnDeprecations(ChartDeprecations(chart))
+	}
+	u.Skipped = false
+	u.Incompatibilities = nil
 	u.cfg.Log("preparing upgrade for %s", name)
 	currentRelease, upgradedRelease, err := u.prepareUpgrade(name, chart, vals)
 	if err != nil {
 		return nil, err
 	}
 
+	if u.SkipIfUnchanged {
+		unchanged, err := isUnchanged(currentRelease, upgradedRelease)
+		if err != nil {
+			return nil, err
+		}
+		if unchanged {
+			u.cfg.Log("release %s is unchanged, skipping upgrade", name)
+			u.Skipped = true
+			return currentRelease, nil
+		}
+	}
+
 	u.cfg.Releases.MaxHistory = u.MaxHistory
 
 	u.cfg.Log("performing update for %s", name)
@@ -139,6 +279,7 @@ func (u *Upgrade) Run(name string, chart *chart.Chart, vals map[string]interface
 		if err := u.cfg.Releases.Update(upgradedRelease); err != nil {
 			return res, err
 		}
+		u.cfg.notify(EventUpgradeSucceeded, upgradedRelease, "")
 	}
 
 	return res, nil
@@ -164,6 +305,9 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 	if lastRelease.Info.Status.IsPending() {
 		return nil, nil, errPending
 	}
+	if err := u.cfg.checkPause(lastRelease, u.OverridePause); err != nil {
+		return nil, nil, err
+	}
 
 	var currentRelease *release.Release
 	if lastRelease.Info.Status == release.StatusDeployed {
@@ -188,7 +332,32 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 		return nil, nil, err
 	}
 
-	if err := chartutil.ProcessDependencies(chart, vals); err != nil {
+	// Values overlays are reused, pinned to the digests recorded in the
+	// current release, along with its values.
+	refs := u.ValuesRefs
+	if len(refs) == 0 && u.ReuseValues && !u.ResetValues {
+		refs = pinnedValuesRefs(currentRelease.ValuesRefs)
+	}
+	overlayVals, valuesRefs, err := u.cfg.loadValuesOverlays(refs)
+	if err != nil {
+		return nil, nil, err
+	}
+	renderVals := vals
+	if len(valuesRefs) > 0 {
+		renderVals = chartutil.MergeTables(overlayVals, vals)
+	}
+
+	subcharts, err := newSubchartSelection(chart, u.OnlySubcharts, u.SkipSubcharts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := chartutil.ProcessDependencies(chart, renderVals); err != nil {
+		return nil, nil, err
+	}
+	if err := subcharts.apply(chart); err != nil {
+		return nil, nil, err
+	}
+	if err := u.cfg.checkDeprecatedValues(chart, renderVals, u.StrictValues); err != nil {
 		return nil, nil, err
 	}
 
@@ -197,25 +366,46 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 	revision := lastRelease.Version + 1
 
 	options := chartutil.ReleaseOptions{
-		Name:      name,
-		Namespace: currentRelease.Namespace,
-		Revision:  revision,
-		IsUpgrade: true,
+		Name:              name,
+		Namespace:         currentRelease.Namespace,
+		Revision:          revision,
+		IsUpgrade:         true,
+		NamespaceMetadata: u.cfg.namespaceMetadata(currentRelease.Namespace),
+		Features:          renderFeatures(u.Features, u.DryRun, u.ServerDryRun, false),
 	}
 
 	caps, err := u.cfg.getCapabilities()
 	if err != nil {
 		return nil, nil, err
 	}
-	valuesToRender, err := chartutil.ToRenderValues(chart, vals, options, caps)
+	var upgradeFrom string
+	if currentRelease.Chart != nil && currentRelease.Chart.Metadata != nil {
+		upgradeFrom = currentRelease.Chart.Metadata.Version
+	}
+	u.Incompatibilities = ChartIncompatibilities(chart, caps.KubeVersion.String(), upgradeFrom)
+	if u.StrictCompatibility {
+		if err := checkCompatibility(u.Incompatibilities); err != nil {
+			return nil, nil, err
+		}
+	}
+	valuesToRender, err := chartutil.ToRenderValues(chart, renderVals, options, caps)
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := u.cfg.resolveSecrets(u.SecretResolver, valuesToRender); err != nil {
+		return nil, nil, err
+	}
 
-	hooks, manifestDoc, notesTxt, err := u.cfg.renderResources(chart, valuesToRender, "", "", u.SubNotes, false, false, u.PostRenderer, u.DryRun)
+	hooks, manifestDoc, notesTxt, structuredNotes, err := u.cfg.renderResources(chart, valuesToRender, "", "", u.SubNotes, false, false, u.PostRenderer, u.DryRun)
 	if err != nil {
 		return nil, nil, err
 	}
+	release.CarryHookExecutions(lastRelease.Hooks, hooks)
+	if !u.DisableHooks && !u.RetryFailedMigrations {
+		if err := failedMigrations(lastRelease); err != nil {
+			return nil, nil, err
+		}
+	}
 
 	// Store an upgraded release.
 	upgradedRelease := &release.Release{
@@ -225,22 +415,68 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 		Config:    vals,
 		Info: &release.Info{
 			FirstDeployed: currentRelease.Info.FirstDeployed,
-			LastDeployed:  Timestamper(),
+			LastDeployed:  u.cfg.Now(),
 			Status:        release.StatusPendingUpgrade,
 			Description:   "Preparing upgrade", // This should be overwritten later.
+			Pause:         lastRelease.Info.Pause,
+			Attempts:      u.attempts,
 		},
-		Version:  revision,
-		Manifest: manifestDoc.String(),
-		Hooks:    hooks,
+		Source:     u.ChartPathOptions.chartSource(chart),
+		Version:    revision,
+		Manifest:   manifestDoc.String(),
+		Hooks:      hooks,
+		ValuesRefs: valuesRefs,
+		Defaults:   lastRelease.Defaults,
+	}
+	if u.RecordDefaults {
+		upgradedRelease.Defaults = &release.Defaults{Wait: u.Wait, WaitForJobs: u.WaitForJobs, Timeout: u.Timeout, Atomic: u.Atomic}
 	}
 
 	if len(notesTxt) > 0 {
 		upgradedRelease.Info.Notes = notesTxt
 	}
+	upgradedRelease.Info.NotesDiff = NotesDiff(currentRelease, upgradedRelease)
+	upgradedRelease.Info.StructuredNotes = structuredNotes
 	err = validateManifest(u.cfg.KubeClient, manifestDoc.Bytes(), !u.DisableOpenAPIValidation)
 	return currentRelease, upgradedRelease, err
 }
 
+// isUnchanged returns true if the upgraded release would deploy the same
+// chart, values and manifests as the current release, which must be the
+// deployed revision the upgrade follows. The manifest checksum recorded in the
+// current release is used if it has one.
+//
+// Upgrades do not carry the install-only values of a release forward, so the
+// values are compared without them; their effect on the rendered manifests is
+// compared through the manifest checksums.
+func isUnchanged(current, upgraded *release.Release) (bool, error) {
+	if current.Info.Status != release.StatusDeployed || upgraded.Version != current.Version+1 {
+		return false, nil
+	}
+	if current.Chart == nil || current.Chart.Metadata == nil ||
+		current.Chart.Metadata.Name != upgraded.Chart.Metadata.Name ||
+		current.Chart.Metadata.Version != upgraded.Chart.Metadata.Version {
+		return false, nil
+	}
+
+	currentManifest := manifestChecksum(current)
+	if current.Checksums != nil {
+		currentManifest = current.Checksums.Manifest
+	}
+	if currentManifest != manifestChecksum(upgraded) {
+		return false, nil
+	}
+	currentValues, err := valuesChecksum(current, nil)
+	if err != nil {
+		return false, err
+	}
+	upgradedValues, err := valuesChecksum(upgraded, nil)
+	if err != nil {
+		return false, err
+	}
+	return currentValues == upgradedValues, nil
+}
+
 func (u *Upgrade) performUpgrade(originalRelease, upgradedRelease *release.Release) (*release.Release, error) {
 	current, err := u.cfg.KubeClient.Build(bytes.NewBufferString(originalRelease.Manifest), false)
 	if err != nil {
@@ -253,6 +489,7 @@ func (u *Upgrade) performUpgrade(originalRelease, upgradedRelease *release.Relea
 		}
 		return upgradedRelease, errors.Wrap(err, "unable to build kubernetes objects from current release manifest")
 	}
+	u.cfg.applyGeneratedNames(current, originalRelease)
 	target, err := u.cfg.KubeClient.Build(bytes.NewBufferString(upgradedRelease.Manifest), !u.DisableOpenAPIValidation)
 	if err != nil {
 		return upgradedRelease, errors.Wrap(err, "unable to build kubernetes objects from new release manifest")
@@ -263,6 +500,15 @@ func (u *Upgrade) performUpgrade(originalRelease, upgradedRelease *release.Relea
 	if err != nil {
 		return upgradedRelease, err
 	}
+	if u.StandardLabels {
+		policy, err := ParseLabelConflictPolicy(string(u.LabelConflicts))
+		if err != nil {
+			return upgradedRelease, err
+		}
+		if err := target.Visit(setStandardLabelsVisitor(StandardLabels(upgradedRelease.Name, upgradedRelease.Chart.Metadata), policy)); err != nil {
+			return upgradedRelease, err
+		}
+	}
 
 	// Do a basic diff using gvk + name to figure out what new resources are being created so we can validate they don't already exist
 	existingResources := make(map[string]bool)
@@ -277,7 +523,7 @@ func (u *Upgrade) performUpgrade(originalRelease, upgradedRelease *release.Relea
 		}
 	}
 
-	toBeUpdated, err := existingResourceConflict(toBeCreated, upgradedRelease.Name, upgradedRelease.Namespace)
+	toBeUpdated, err := existingResourceConflict(toBeCreated, upgradedRelease.Name, upgradedRelease.Namespace, u.AllowOwnershipTransfer)
 	if err != nil {
 		return nil, errors.Wrap(err, "rendered manifests contain a resource that already exists. Unable to continue with update")
 	}
@@ -292,6 +538,16 @@ func (u *Upgrade) performUpgrade(originalRelease, upgradedRelease *release.Relea
 
 	if u.DryRun {
 		u.cfg.Log("dry run for %s", upgradedRelease.Name)
+		if u.ServerDryRun {
+			var events []release.HookEvent
+			if !u.DisableHooks {
+				events = []release.HookEvent{release.HookPreUpgrade, release.HookMigration, release.HookPostUpgrade}
+			}
+			u.AdmissionResults, err = u.cfg.serverDryRun(upgradedRelease, current, target, events...)
+			if err != nil {
+				return upgradedRelease, err
+			}
+		}
 		if len(u.Description) > 0 {
 			upgradedRelease.Info.Description = u.Description
 		} else {
@@ -301,20 +557,32 @@ func (u *Upgrade) performUpgrade(originalRelease, upgradedRelease *release.Relea
 	}
 
 	u.cfg.Log("creating upgraded release for %s", upgradedRelease.Name)
+	if err := setChecksums(upgradedRelease); err != nil {
+		return nil, err
+	}
 	if err := u.cfg.Releases.Create(upgradedRelease); err != nil {
 		return nil, err
 	}
+	u.cfg.notify(EventUpgradeStarted, upgradedRelease, "")
 
 	// pre-upgrade hooks
 	if !u.DisableHooks {
 		if err := u.cfg.execHook(upgradedRelease, release.HookPreUpgrade, u.Timeout); err != nil {
 			return u.failRelease(upgradedRelease, kube.ResourceList{}, fmt.Errorf("pre-upgrade hooks failed: %s", err))
 		}
+		if err := u.cfg.execMigrations(upgradedRelease, u.Timeout); err != nil {
+			return u.failRelease(upgradedRelease, kube.ResourceList{}, fmt.Errorf("migrations failed: %s", err))
+		}
 	} else {
 		u.cfg.Log("upgrade hooks disabled for %s", upgradedRelease.Name)
 	}
 
-	results, err := u.cfg.KubeClient.Update(current, target, u.Force)
+	results, err := u.cfg.updateResources(current, target, u.Force, u.DeletionTimeout)
+	u.Result = results
+	if results != nil {
+		recordGeneratedNames(upgradedRelease, results.Created)
+		u.cfg.warnResults(upgradedRelease, results)
+	}
 	if err != nil {
 		u.cfg.recordRelease(originalRelease)
 		return u.failRelease(upgradedRelease, results.Created, err)
@@ -344,6 +612,16 @@ func (u *Upgrade) performUpgrade(originalRelease, upgradedRelease *release.Relea
 		}
 	}
 
+	if err := u.cfg.waitForConditions(target, u.Timeout, u.WaitFor); err != nil {
+		u.cfg.recordRelease(originalRelease)
+		return u.failRelease(upgradedRelease, results.Created, err)
+	}
+
+	if err := u.cfg.waitForEndpoints(target, u.Timeout, u.WaitForEndpoints); err != nil {
+		u.cfg.recordRelease(originalRelease)
+		return u.failRelease(upgradedRelease, results.Created, err)
+	}
+
 	// post-upgrade hooks
 	if !u.DisableHooks {
 		if err := u.cfg.execHook(upgradedRelease, release.HookPostUpgrade, u.Timeout); err != nil {
@@ -370,7 +648,9 @@ func (u *Upgrade) failRelease(rel *release.Release, created kube.ResourceList, e
 
 	rel.Info.Status = release.StatusFailed
 	rel.Info.Description = msg
+	recordNotReady(rel, err)
 	u.cfg.recordRelease(rel)
+	u.cfg.notify(EventUpgradeFailed, rel, err.Error())
 	if u.CleanupOnFail && len(created) > 0 {
 		u.cfg.Log("Cleanup on fail set, cleaning up %d resources", len(created))
 		_, errs := u.cfg.KubeClient.Delete(created)
@@ -413,6 +693,7 @@ func (u *Upgrade) failRelease(rel *release.Release, created kube.ResourceList, e
 		rollin.DisableHooks = u.DisableHooks
 		rollin.Recreate = u.Recreate
 		rollin.Force = u.Force
+		rollin.DeletionTimeout = u.DeletionTimeout
 		rollin.Timeout = u.Timeout
 		if rollErr := rollin.Run(rel.Name); rollErr != nil {
 			return rel, errors.Wrapf(rollErr, "an error occurred while rolling back the release. original upgrade error: %s", err)
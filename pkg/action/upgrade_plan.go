@@ -0,0 +1,305 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/diff"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// ResourceChange is the change planned for a resource of a release.
+type ResourceChange string
+
+// Changes planned for the resources of a release.
+const (
+	// ChangeCreate is planned for the resources that are not in the
+	// current release, and for those using metadata.generateName.
+	ChangeCreate ResourceChange = "create"
+	// ChangeUpdate is planned for the resources whose manifest changed.
+	ChangeUpdate ResourceChange = "update"
+	// ChangeNone is planned for the resources whose manifest did not change.
+	ChangeNone ResourceChange = "unchanged"
+	// ChangeDelete is planned for the resources that are no longer in the
+	// chart.
+	ChangeDelete ResourceChange = "delete"
+	// ChangeKeep is planned for the resources that are no longer in the
+	// chart but are kept because of their resource policy.
+	ChangeKeep ResourceChange = "keep"
+)
+
+// PlannedResource is a resource of a release and the change planned for it.
+type PlannedResource struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// GenerateName is the metadata.generateName of the resource, if it uses
+	// one. Name is then empty for the resources to create.
+	GenerateName string         `json:"generate_name,omitempty"`
+	Change       ResourceChange `json:"change"`
+}
+
+// UpgradePlan is what an upgrade of a release would do.
+type UpgradePlan struct {
+	Release   string `json:"release"`
+	Namespace string `json:"namespace"`
+	// CurrentRevision is the revision the upgrade is computed against
+	CurrentRevision int `json:"current_revision"`
+	// Revision is the revision the upgrade would create
+	Revision  int               `json:"revision"`
+	Chart     string            `json:"chart"`
+	Resources []PlannedResource `json:"resources"`
+	// Hooks are the hooks the upgrade would run, in order
+	Hooks []PlannedHook `json:"hooks"`
+	// Checksums are the digests of the manifests and values of the new
+	// revision
+	Checksums *release.Checksums `json:"checksums"`
+	// Diffs are the changes of the resources, for a diff.Renderer. They are
+	// not part of the token, which covers the manifests by their checksums.
+	Diffs []diff.ResourceDiff `json:"-"`
+}
+
+// Token returns a digest of the plan. Approving a plan by its token ensures
+// that the upgrade performed is the one that was reviewed, as any change of
+// the release, the chart, the values or the rendered manifests changes it.
+func (p *UpgradePlan) Token() string {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// Count returns the number of resources the given change is planned for.
+func (p *UpgradePlan) Count(change ResourceChange) int {
+	n := 0
+	for _, r := range p.Resources {
+		if r.Change == change {
+			n++
+		}
+	}
+	return n
+}
+
+// Plan computes what upgrading the named release with the given chart and
+// values would do, without changing anything in the cluster or in the release
+// history.
+func (u *Upgrade) Plan(name string, chart *chart.Chart, vals map[string]interface{}) (*UpgradePlan, error) {
+	_, _, plan, err := u.plan(name, chart, vals)
+	return plan, err
+}
+
+// plan computes the plan of the upgrade along with the current release and
+// the upgraded one it compares.
+func (u *Upgrade) plan(name string, chart *chart.Chart, vals map[string]interface{}) (*release.Release, *release.Release, *UpgradePlan, error) {
+	if err := u.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, nil, nil, errors.Errorf("release name is invalid: %s", name)
+	}
+	currentRelease, upgradedRelease, err := u.prepareUpgrade(name, chart, vals)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	plan, err := NewUpgradePlan(currentRelease, upgradedRelease, u.DisableHooks)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return currentRelease, upgradedRelease, plan, nil
+}
+
+// NewUpgradePlan computes the plan of the upgrade from the current release to
+// the upgraded one, comparing their manifests. The hooks of the upgraded
+// release are left out if hooks are disabled.
+func NewUpgradePlan(current, upgraded *release.Release, disableHooks bool) (*UpgradePlan, error) {
+	currentResources, err := plannedResources(current.Manifest, current.Namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse the current release manifest")
+	}
+	upgradedResources, err := plannedResources(upgraded.Manifest, upgraded.Namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse the upgraded release manifest")
+	}
+	sums, err := ReleaseChecksums(upgraded)
+	if err != nil {
+		return nil, err
+	}
+	diffs, err := ReleaseDiff(current, upgraded)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &UpgradePlan{
+		Release:         upgraded.Name,
+		Namespace:       upgraded.Namespace,
+		CurrentRevision: current.Version,
+		Revision:        upgraded.Version,
+		Checksums:       sums,
+		Hooks:           []PlannedHook{},
+		Diffs:           diffs,
+	}
+	if upgraded.Chart != nil && upgraded.Chart.Metadata != nil {
+		plan.Chart = upgraded.Chart.Metadata.Name + "-" + upgraded.Chart.Metadata.Version
+	}
+	if !disableHooks {
+		plan.Hooks = append(plan.Hooks, HookPlan(upgraded, release.HookPreUpgrade, release.HookMigration, release.HookPostUpgrade)...)
+	}
+
+	currentByKey := map[string]*manifestResource{}
+	for _, r := range currentResources {
+		currentByKey[r.key()] = r
+	}
+	upgradedByKey := map[string]*manifestResource{}
+	for _, r := range upgradedResources {
+		upgradedByKey[r.key()] = r
+	}
+
+	for _, r := range upgradedResources {
+		change := ChangeCreate
+		if c, ok := currentByKey[r.key()]; ok && r.GenerateName == "" {
+			change = ChangeUpdate
+			if reflect.DeepEqual(c.object, r.object) {
+				change = ChangeNone
+			}
+		}
+		plan.Resources = append(plan.Resources, r.planned(change))
+	}
+
+	// Resources using metadata.generateName are replaced by new ones, so
+	// those of the current release are deleted, under their recorded names
+	generated := map[string][]string{}
+	for _, n := range current.GeneratedNames {
+		k := n.Kind + "/" + n.Namespace + "/" + n.GenerateName
+		generated[k] = append(generated[k], n.Name)
+	}
+	for _, r := range currentResources {
+		if _, ok := upgradedByKey[r.key()]; ok && r.GenerateName == "" {
+			continue
+		}
+		change := ChangeDelete
+		if r.annotations[kube.ResourcePolicyAnno] == kube.KeepPolicy {
+			change = ChangeKeep
+		}
+		p := r.planned(change)
+		if r.GenerateName != "" {
+			k := r.key()
+			if names := generated[k]; len(names) > 0 {
+				p.Name, generated[k] = names[0], names[1:]
+			}
+		}
+		plan.Resources = append(plan.Resources, p)
+	}
+
+	sort.SliceStable(plan.Resources, func(i, j int) bool {
+		a, b := plan.Resources[i], plan.Resources[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Name+a.GenerateName != b.Name+b.GenerateName {
+			return a.Name+a.GenerateName < b.Name+b.GenerateName
+		}
+		return a.Change < b.Change
+	})
+	return plan, nil
+}
+
+// manifestResource is a resource parsed from the manifest of a release.
+type manifestResource struct {
+	PlannedResource
+	annotations map[string]string
+	object      map[string]interface{}
+}
+
+// key identifies the resource in the manifests of the current and upgraded
+// releases. All the resources using the same metadata.generateName share it.
+func (r *manifestResource) key() string {
+	name := r.Name
+	if name == "" {
+		name = r.GenerateName
+	}
+	return r.Kind + "/" + r.Namespace + "/" + name
+}
+
+func (r *manifestResource) planned(change ResourceChange) PlannedResource {
+	p := r.PlannedResource
+	p.Change = change
+	return p
+}
+
+// plannedResources parses the resources of a manifest, in manifest order.
+func plannedResources(manifest, namespace string) ([]*manifestResource, error) {
+	manifests := releaseutil.SplitManifests(manifest)
+	keys := make([]string, 0, len(manifests))
+	for k := range manifests {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	var resources []*manifestResource
+	for _, k := range keys {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(manifests[k]), &obj); err != nil {
+			return nil, err
+		}
+		var head struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name         string            `json:"name"`
+				GenerateName string            `json:"generateName"`
+				Namespace    string            `json:"namespace"`
+				Annotations  map[string]string `json:"annotations"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(manifests[k]), &head); err != nil {
+			return nil, err
+		}
+		if head.Kind == "" {
+			continue
+		}
+		r := &manifestResource{
+			PlannedResource: PlannedResource{
+				Kind:      head.Kind,
+				Name:      head.Metadata.Name,
+				Namespace: head.Metadata.Namespace,
+			},
+			annotations: head.Metadata.Annotations,
+			object:      obj,
+		}
+		if r.Namespace == "" {
+			r.Namespace = namespace
+		}
+		if r.Name == "" {
+			r.GenerateName = head.Metadata.GenerateName
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
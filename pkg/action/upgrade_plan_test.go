@@ -0,0 +1,143 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const planCurrentManifest = `---
+# Source: hello/templates/resources.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: same
+data:
+  key: value
+---
+# Source: hello/templates/resources.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: changed
+data:
+  key: old
+---
+# Source: hello/templates/resources.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: removed
+---
+# Source: hello/templates/resources.yaml
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+  annotations:
+    helm.sh/resource-policy: keep
+---
+# Source: hello/templates/resources.yaml
+apiVersion: batch/v1
+kind: Job
+metadata:
+  generateName: migrate-
+`
+
+const planUpgradedTemplate = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: same
+data:
+  key: value
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: changed
+data:
+  key: {{ .Values.key }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: added
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  generateName: migrate-
+`
+
+func TestUpgradePlan(t *testing.T) {
+	is := assert.New(t)
+	upAction := upgradeAction(t)
+
+	rel := releaseStub()
+	rel.Namespace = "spaced"
+	rel.Manifest = planCurrentManifest
+	rel.GeneratedNames = []release.GeneratedName{
+		{Kind: "Job", Namespace: "spaced", GenerateName: "migrate-", Name: "migrate-x7k2p"},
+	}
+	upAction.cfg.Releases.Create(rel)
+
+	ch := buildChart()
+	ch.Templates = append(ch.Templates, &chart.File{Name: "templates/resources.yaml", Data: []byte(planUpgradedTemplate)})
+
+	plan, err := upAction.Plan(rel.Name, ch, map[string]interface{}{"key": "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.Equal(1, plan.CurrentRevision)
+	is.Equal(2, plan.Revision)
+	is.Equal("hello-0.1.0", plan.Chart)
+	is.Equal([]PlannedResource{
+		{Kind: "ConfigMap", Name: "changed", Namespace: "spaced", Change: ChangeUpdate},
+		{Kind: "ConfigMap", Name: "same", Namespace: "spaced", Change: ChangeNone},
+		{Kind: "Job", GenerateName: "migrate-", Namespace: "spaced", Change: ChangeCreate},
+		{Kind: "Job", Name: "migrate-x7k2p", GenerateName: "migrate-", Namespace: "spaced", Change: ChangeDelete},
+		{Kind: "PersistentVolumeClaim", Name: "data", Namespace: "spaced", Change: ChangeKeep},
+		{Kind: "Secret", Name: "removed", Namespace: "spaced", Change: ChangeDelete},
+		{Kind: "Service", Name: "added", Namespace: "spaced", Change: ChangeCreate},
+	}, plan.Resources)
+	is.Len(plan.Hooks, 1)
+	is.Equal(release.HookPostUpgrade, plan.Hooks[0].Event)
+
+	// Nothing is changed by planning
+	last, err := upAction.cfg.Releases.Last(rel.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.Equal(1, last.Version)
+
+	// The token is stable, and changes with the values
+	again, err := upAction.Plan(rel.Name, ch, map[string]interface{}{"key": "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.Equal(plan.Token(), again.Token())
+	other, err := upAction.Plan(rel.Name, ch, map[string]interface{}{"key": "other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.NotEqual(plan.Token(), other.Token())
+}
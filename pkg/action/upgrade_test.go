@@ -18,13 +18,16 @@ package action
 
 import (
 	"fmt"
+	"io/ioutil"
 	"testing"
+	stdtime "time"
 
 	"helm.sh/helm/v3/pkg/chart"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"helm.sh/helm/v3/pkg/kube"
 	kubefake "helm.sh/helm/v3/pkg/kube/fake"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/time"
@@ -296,3 +299,148 @@ func TestUpgradeRelease_Pending(t *testing.T) {
 	_, err := upAction.Run(rel.Name, buildChart(), vals)
 	req.Contains(err.Error(), "progress", err)
 }
+
+func TestUpgradeRelease_SkipIfUnchanged(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "steady"
+	rel.Info.Status = release.StatusDeployed
+	upAction.cfg.Releases.Create(rel)
+
+	vals := map[string]interface{}{"name": "value"}
+	res, err := upAction.Run(rel.Name, buildChart(), vals)
+	req.NoError(err)
+	is.Equal(2, res.Version)
+	is.False(upAction.Skipped)
+
+	upAction.SkipIfUnchanged = true
+	res, err = upAction.Run(rel.Name, buildChart(), vals)
+	req.NoError(err)
+	is.True(upAction.Skipped)
+	is.Equal(2, res.Version)
+	is.Equal(release.StatusDeployed, res.Info.Status)
+
+	res, err = upAction.Run(rel.Name, buildChart(), map[string]interface{}{"name": "other"})
+	req.NoError(err)
+	is.False(upAction.Skipped)
+	is.Equal(3, res.Version)
+}
+
+func TestUpgradeRelease_SkipIfUnchangedAfterInstallValues(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	instAction := installAction(t)
+	instAction.InstallValues = map[string]interface{}{"bootstrap": true}
+	vals := map[string]interface{}{"name": "value"}
+	rel, err := instAction.Run(buildChart(), vals)
+	req.NoError(err)
+
+	// The install-only values are not sent again, which is no change
+	upAction := NewUpgrade(instAction.cfg)
+	upAction.Namespace = "spaced"
+	upAction.SkipIfUnchanged = true
+	res, err := upAction.Run(rel.Name, buildChart(), vals)
+	req.NoError(err)
+	is.True(upAction.Skipped)
+	is.Equal(1, res.Version)
+}
+
+// recreateKubeClient records the calls to the methods recreating resources.
+type recreateKubeClient struct {
+	kubefake.PrintingKubeClient
+	force           bool
+	deletionTimeout stdtime.Duration
+	waitedDeletions int
+}
+
+func (c *recreateKubeClient) UpdateRecreate(original, target kube.ResourceList, force bool, deletionTimeout stdtime.Duration) (*kube.Result, error) {
+	c.force, c.deletionTimeout = force, deletionTimeout
+	return c.Update(original, target, force)
+}
+
+func (c *recreateKubeClient) WaitForDelete(_ kube.ResourceList, _ stdtime.Duration) error {
+	c.waitedDeletions++
+	return nil
+}
+
+func TestUpgradeRelease_DeletionTimeout(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	client := &recreateKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard}}
+	upAction.cfg.KubeClient = client
+	rel := releaseStub()
+	rel.Name = "recreated"
+	rel.Info.Status = release.StatusDeployed
+	upAction.cfg.Releases.Create(rel)
+
+	upAction.Force = true
+	upAction.DeletionTimeout = 2 * stdtime.Minute
+	_, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	req.NoError(err)
+	is.True(client.force)
+	is.Equal(2*stdtime.Minute, client.deletionTimeout)
+	// The post-upgrade hook is deleted before its creation, by default
+	is.Equal(1, client.waitedDeletions)
+}
+
+func TestUpgradeRelease_RecordDefaults(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "defaulted"
+	rel.Info.Status = release.StatusDeployed
+	rel.Defaults = &release.Defaults{Wait: true, Timeout: 10 * stdtime.Minute}
+	upAction.cfg.Releases.Create(rel)
+
+	// The defaults of the last revision are kept
+	res, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(rel.Defaults, res.Defaults)
+
+	upAction.RecordDefaults = true
+	upAction.Atomic = true
+	upAction.Timeout = stdtime.Minute
+	res, err = upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(&release.Defaults{Wait: true, Timeout: stdtime.Minute, Atomic: true}, res.Defaults)
+}
+
+func TestUpgradeRelease_NotesDiff(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "noted"
+	rel.Info.Status = release.StatusDeployed
+	rel.Info.Notes = "Run the migration first.\nThen visit the dashboard.\n"
+	upAction.cfg.Releases.Create(rel)
+
+	res, err := upAction.Run(rel.Name, buildChart(withNotes("Then visit the new dashboard.\n")), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(`--- noted revision 1
++++ noted revision 2
+@@ -1,2 +1 @@
+-Run the migration first.
+-Then visit the dashboard.
++Then visit the new dashboard.
+`, res.Info.NotesDiff)
+
+	// The diff is stored with the release
+	stored, err := upAction.cfg.Releases.Get(rel.Name, 2)
+	req.NoError(err)
+	is.Equal(res.Info.NotesDiff, stored.Info.NotesDiff)
+
+	// Unchanged notes have no diff
+	res, err = upAction.Run(rel.Name, buildChart(withNotes("Then visit the new dashboard.\n")), map[string]interface{}{})
+	req.NoError(err)
+	is.Empty(res.Info.NotesDiff)
+}
@@ -18,10 +18,13 @@ package action
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/resource"
 
@@ -37,14 +40,48 @@ const (
 	helmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
 )
 
-func existingResourceConflict(resources kube.ResourceList, releaseName, releaseNamespace string) (kube.ResourceList, error) {
+// OwnershipConflict describes an existing resource that a release cannot
+// adopt without taking it over from its current owner.
+type OwnershipConflict struct {
+	// Resource identifies the resource, such as 'Deployment "web" in namespace "default"'
+	Resource string
+	// Reason describes who owns the resource
+	Reason string
+}
+
+// OwnershipConflictError is returned when rendered resources already exist
+// and are owned by another release or by other field managers.
+type OwnershipConflictError struct {
+	Conflicts []OwnershipConflict
+}
+
+func (e *OwnershipConflictError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d resource(s) are owned by something else, use --allow-ownership-transfer to take them over:", len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		fmt.Fprintf(&b, "\n  %s: %s", c.Resource, c.Reason)
+	}
+	return b.String()
+}
+
+// existingResourceConflict returns the resources that already exist and can
+// be adopted by the release. All the resources owned by something else are
+// reported at once, unless allowTransfer is set, in which case they are
+// adopted as well.
+func existingResourceConflict(resources kube.ResourceList, releaseName, releaseNamespace string, allowTransfer bool) (kube.ResourceList, error) {
 	var requireUpdate kube.ResourceList
+	var conflicts []OwnershipConflict
 
 	err := resources.Visit(func(info *resource.Info, err error) error {
 		if err != nil {
 			return err
 		}
 
+		// Resources using metadata.generateName are always created anew
+		if kube.GeneratesName(info) {
+			return nil
+		}
+
 		helper := resource.NewHelper(info.Client, info.Mapping)
 		existing, err := helper.Get(info.Namespace, info.Name)
 		if err != nil {
@@ -55,15 +92,67 @@ func existingResourceConflict(resources kube.ResourceList, releaseName, releaseN
 		}
 
 		// Allow adoption of the resource if it is managed by Helm and is annotated with correct release name and namespace.
-		if err := checkOwnership(existing, releaseName, releaseNamespace); err != nil {
-			return fmt.Errorf("%s exists and cannot be imported into the current release: %s", resourceString(info), err)
+		if err := checkOwnership(existing, releaseName, releaseNamespace); err != nil && !allowTransfer {
+			conflicts = append(conflicts, OwnershipConflict{
+				Resource: resourceString(info),
+				Reason:   ownershipConflictReason(existing, releaseName, releaseNamespace, err),
+			})
+			return nil
 		}
 
 		requireUpdate.Append(info)
 		return nil
 	})
+	if err != nil {
+		return requireUpdate, err
+	}
+	if len(conflicts) > 0 {
+		return requireUpdate, &OwnershipConflictError{Conflicts: conflicts}
+	}
+	return requireUpdate, nil
+}
 
-	return requireUpdate, err
+// ownershipConflictReason describes who owns a resource that failed the
+// ownership check with the given error.
+func ownershipConflictReason(obj runtime.Object, releaseName, releaseNamespace string, ownershipErr error) string {
+	var reasons []string
+
+	annos, _ := accessor.Annotations(obj)
+	name, namespace := annos[helmReleaseNameAnnotation], annos[helmReleaseNamespaceAnnotation]
+	if name != "" && (name != releaseName || namespace != releaseNamespace) {
+		reasons = append(reasons, fmt.Sprintf("owned by release %q in namespace %q", name, namespace))
+	}
+
+	// Helm does not use server-side apply, so every apply manager is
+	// someone else
+	if managers := applyManagers(obj); len(managers) > 0 {
+		reasons = append(reasons, fmt.Sprintf("managed by field manager(s) %s", strings.Join(managers, ", ")))
+	}
+
+	if len(reasons) == 0 {
+		return ownershipErr.Error()
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// applyManagers returns the quoted names of the field managers that own
+// fields of the object through server-side apply.
+func applyManagers(obj runtime.Object) []string {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	var managers []string
+	for _, f := range m.GetManagedFields() {
+		if f.Operation != metav1.ManagedFieldsOperationApply || seen[f.Manager] {
+			continue
+		}
+		seen[f.Manager] = true
+		managers = append(managers, fmt.Sprintf("%q", f.Manager))
+	}
+	sort.Strings(managers)
+	return managers
 }
 
 func checkOwnership(obj runtime.Object, releaseName, releaseNamespace string) error {
@@ -121,3 +121,42 @@ func TestSetMetadataVisitor(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), `Deployment "baz" in namespace "" cannot be owned`)
 }
+
+func TestOwnershipConflictReason(t *testing.T) {
+	deployFoo := newDeploymentResource("foo", "ns-a")
+
+	// Without any ownership metadata, the ownership error is the reason
+	err := checkOwnership(deployFoo.Object, "rel-a", "ns-a")
+	assert.Equal(t, err.Error(), ownershipConflictReason(deployFoo.Object, "rel-a", "ns-a", err))
+
+	// Owned by another release
+	_ = accessor.SetLabels(deployFoo.Object, map[string]string{
+		appManagedByLabel: appManagedByHelm,
+	})
+	_ = accessor.SetAnnotations(deployFoo.Object, map[string]string{
+		helmReleaseNameAnnotation:      "rel-b",
+		helmReleaseNamespaceAnnotation: "ns-b",
+	})
+	err = checkOwnership(deployFoo.Object, "rel-a", "ns-a")
+	assert.Equal(t, `owned by release "rel-b" in namespace "ns-b"`, ownershipConflictReason(deployFoo.Object, "rel-a", "ns-a", err))
+
+	// Also managed through server-side apply. Managers of updates, such as
+	// controllers updating the status, are not reported.
+	deployFoo.Object.(*appsv1.Deployment).ManagedFields = []v1.ManagedFieldsEntry{
+		{Manager: "kube-controller-manager", Operation: v1.ManagedFieldsOperationUpdate},
+		{Manager: "kubectl", Operation: v1.ManagedFieldsOperationApply},
+		{Manager: "argocd", Operation: v1.ManagedFieldsOperationApply},
+		{Manager: "kubectl", Operation: v1.ManagedFieldsOperationApply},
+	}
+	assert.Equal(t, `owned by release "rel-b" in namespace "ns-b"; managed by field manager(s) "argocd", "kubectl"`, ownershipConflictReason(deployFoo.Object, "rel-a", "ns-a", err))
+}
+
+func TestOwnershipConflictError(t *testing.T) {
+	err := &OwnershipConflictError{Conflicts: []OwnershipConflict{
+		{Resource: `Deployment "foo" in namespace "ns-a"`, Reason: `owned by release "rel-b" in namespace "ns-a"`},
+		{Resource: `Service "foo" in namespace "ns-a"`, Reason: `managed by field manager(s) "kubectl"`},
+	}}
+	assert.EqualError(t, err, `2 resource(s) are owned by something else, use --allow-ownership-transfer to take them over:
+  Deployment "foo" in namespace "ns-a": owned by release "rel-b" in namespace "ns-a"
+  Service "foo" in namespace "ns-a": managed by field manager(s) "kubectl"`)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/internal/experimental/registry"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// ValuesPush performs a values overlay push operation.
+type ValuesPush struct {
+	cfg *Configuration
+}
+
+// NewValuesPush creates a new ValuesPush object with the given configuration.
+func NewValuesPush(cfg *Configuration) *ValuesPush {
+	return &ValuesPush{
+		cfg: cfg,
+	}
+}
+
+// Run pushes the values file at path to the registry as a values overlay.
+func (a *ValuesPush) Run(out io.Writer, path, ref string) error {
+	r, err := registry.ParseReference(strings.TrimPrefix(ref, registry.OCIScheme))
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if _, err := chartutil.ReadValues(data); err != nil {
+		return errors.Wrapf(err, "%s is not a valid values file", path)
+	}
+	_, err = a.cfg.RegistryClient.PushValues(r, data)
+	return err
+}
+
+// loadValuesOverlays pulls the given values overlays from their registries
+// and merges them in order, later overlays taking precedence. It returns the
+// merged values and the overlays pinned to the digests that were pulled.
+func (cfg *Configuration) loadValuesOverlays(refs []string) (map[string]interface{}, []release.ValuesReference, error) {
+	if len(refs) == 0 {
+		return nil, nil, nil
+	}
+	if cfg.RegistryClient == nil {
+		return nil, nil, errors.New("a registry client is required to pull values overlays")
+	}
+
+	vals := map[string]interface{}{}
+	pinned := make([]release.ValuesReference, 0, len(refs))
+	for _, s := range refs {
+		ref, err := registry.ParseValuesReference(s)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid values overlay reference %q", s)
+		}
+		overlay, err := cfg.RegistryClient.PullValues(ref)
+		if err != nil {
+			return nil, nil, err
+		}
+		overlayVals, err := chartutil.ReadValues(overlay.Data)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "values overlay %s is not a valid values file", ref)
+		}
+		cfg.Log("pulled values overlay %s (%s)", ref, overlay.Digest)
+		vals = chartutil.MergeTables(vals, overlayVals.AsMap())
+		pinned = append(pinned, release.ValuesReference{
+			Ref:    registry.OCIScheme + ref.Reference.FullName(),
+			Digest: overlay.Digest,
+		})
+	}
+	return vals, pinned, nil
+}
+
+// pinnedValuesRefs returns the references of the recorded values overlays,
+// pinned to their digests.
+func pinnedValuesRefs(refs []release.ValuesReference) []string {
+	pinned := make([]string, 0, len(refs))
+	for _, r := range refs {
+		pinned = append(pinned, r.Pinned())
+	}
+	return pinned
+}
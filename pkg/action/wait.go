@@ -0,0 +1,80 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// waitForConditions blocks until the resources meet the given conditions. It
+// is a no-op when no conditions are given.
+func (c *Configuration) waitForConditions(resources kube.ResourceList, timeout time.Duration, conditions []kube.WaitCondition) error {
+	if len(conditions) == 0 {
+		return nil
+	}
+	waiter, ok := c.KubeClient.(kube.InterfaceConditionWait)
+	if !ok {
+		return errors.New("the Kubernetes client does not support waiting for conditions")
+	}
+	return waiter.WaitForCondition(resources, timeout, conditions...)
+}
+
+// waitForEndpoints blocks until the Services of the resources have at least
+// minReady ready endpoints. It is a no-op when minReady is zero.
+func (c *Configuration) waitForEndpoints(resources kube.ResourceList, timeout time.Duration, minReady int) error {
+	if minReady <= 0 {
+		return nil
+	}
+	waiter, ok := c.KubeClient.(kube.InterfaceEndpointsWait)
+	if !ok {
+		return errors.New("the Kubernetes client does not support waiting for endpoints")
+	}
+	return waiter.WaitForEndpoints(resources, timeout, minReady)
+}
+
+// recordNotReady records in the release the resources that were not ready
+// when waiting for them timed out, if err reports them.
+func recordNotReady(rel *release.Release, err error) {
+	var notReady *kube.NotReadyError
+	if !errors.As(err, &notReady) {
+		return
+	}
+	rel.Info.NotReady = notReadyResources(notReady.Resources)
+}
+
+func notReadyResources(resources []kube.NotReadyResource) []release.NotReadyResource {
+	if len(resources) == 0 {
+		return nil
+	}
+	out := make([]release.NotReadyResource, 0, len(resources))
+	for _, r := range resources {
+		out = append(out, release.NotReadyResource{
+			Kind:      r.Kind,
+			Name:      r.Name,
+			Namespace: r.Namespace,
+			Status:    r.Status,
+			Events:    r.Events,
+			Pods:      notReadyResources(r.Pods),
+		})
+	}
+	return out
+}
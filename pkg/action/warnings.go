@@ -0,0 +1,159 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/client-go/rest"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Reasons of warnings
+const (
+	// WarningDeprecatedChart is raised for deprecated charts and subcharts.
+	WarningDeprecatedChart = "DeprecatedChart"
+	// WarningDeprecatedValue is raised for values set by users that the
+	// schema of the chart deprecates.
+	WarningDeprecatedValue = "DeprecatedValue"
+	// WarningSkippedResource is raised for resources left alone because of
+	// their apply policy.
+	WarningSkippedResource = "SkippedResource"
+	// WarningKubernetes is raised for the warnings returned by the Kubernetes
+	// API server, such as for deprecated APIs.
+	WarningKubernetes = "Kubernetes"
+	// WarningClient is raised for issues met talking to the cluster that do
+	// not prevent the action from completing.
+	WarningClient = "Client"
+	// WarningNotification is raised when the events of a release cannot be
+	// sent to the notifier.
+	WarningNotification = "Notification"
+)
+
+// Warning is a non-fatal issue met by an action, which users should know
+// about.
+type Warning struct {
+	// Reason classifies the warning, such as WarningDeprecatedChart.
+	Reason string `json:"reason"`
+	// Message describes the issue.
+	Message string `json:"message"`
+}
+
+func (w Warning) String() string {
+	return w.Message
+}
+
+// WarningHandler receives the warnings of actions as they are raised. It may
+// be called concurrently.
+type WarningHandler interface {
+	HandleWarning(Warning)
+}
+
+// WarningHandlerFunc is a function handling warnings.
+type WarningHandlerFunc func(Warning)
+
+// HandleWarning calls f(w).
+func (f WarningHandlerFunc) HandleWarning(w Warning) {
+	f(w)
+}
+
+// WarningCollector is a WarningHandler keeping the warnings it receives, for
+// callers that report them once an action completed.
+type WarningCollector struct {
+	mu       sync.Mutex
+	warnings []Warning
+}
+
+// HandleWarning records a warning.
+func (c *WarningCollector) HandleWarning(w Warning) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.warnings = append(c.warnings, w)
+}
+
+// Warnings returns the warnings received so far, in the order they were
+// raised.
+func (c *WarningCollector) Warnings() []Warning {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Warning(nil), c.warnings...)
+}
+
+// warn raises a warning. Without a warning handler, it is logged.
+func (c *Configuration) warn(reason, format string, v ...interface{}) {
+	w := Warning{Reason: reason, Message: fmt.Sprintf(format, v...)}
+	if c.Warnings == nil {
+		c.Log("WARNING: %s", w.Message)
+		return
+	}
+	c.Warnings.HandleWarning(w)
+}
+
+// warnDeprecations raises a warning for each deprecation of a chart.
+func (c *Configuration) warnDeprecations(deprecations []ChartDeprecation) {
+	for _, d := range deprecations {
+		c.warn(WarningDeprecatedChart, "%s", d)
+	}
+}
+
+// warnResults raises a warning for each resource left alone because of its
+// apply policy, and for each warning the API server returned for a resource.
+// The latter are recorded on the release.
+func (c *Configuration) warnResults(rel *release.Release, res *kube.Result) {
+	if res == nil {
+		return
+	}
+	for _, r := range res.Outcomes(kube.OutcomeSkipped) {
+		c.warn(WarningSkippedResource, "%s %q was skipped due to its %s annotation", r.Kind, r.Name, kube.ApplyPolicyAnno)
+	}
+	for _, r := range res.Warnings() {
+		for _, msg := range r.Warnings {
+			c.warn(WarningKubernetes, "%s %q: %s", r.Kind, r.Name, msg)
+			if rel != nil {
+				rel.Info.Warnings = append(rel.Info.Warnings, release.ResourceWarning{
+					Kind:      r.Kind,
+					Name:      r.Name,
+					Namespace: r.Namespace,
+					Message:   msg,
+				})
+			}
+		}
+	}
+}
+
+// KubeWarningHandler returns a handler for the warnings returned by the
+// Kubernetes API server, such as for deprecated APIs, which raises them as
+// warnings of the configuration. It is meant for rest.SetDefaultWarningHandler
+// or the WarningHandler of the REST configuration of the Kubernetes clients.
+func (c *Configuration) KubeWarningHandler() rest.WarningHandler {
+	return kubeWarningHandler{cfg: c}
+}
+
+type kubeWarningHandler struct {
+	cfg *Configuration
+}
+
+func (h kubeWarningHandler) HandleWarningHeader(code int, _ string, message string) {
+	// Only 299 warnings are meant for users, see RFC 7234
+	if code != 299 || message == "" {
+		return
+	}
+	h.cfg.warn(WarningKubernetes, "%s", message)
+}
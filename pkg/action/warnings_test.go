@@ -0,0 +1,81 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestInstallReleaseWarnings(t *testing.T) {
+	is := assert.New(t)
+	collector := &WarningCollector{}
+	instAction := installAction(t)
+	instAction.cfg.Warnings = collector
+
+	_, err := instAction.Run(buildChart(withDeprecation(true, "", "")), map[string]interface{}{})
+	is.NoError(err)
+	is.Equal([]Warning{{Reason: WarningDeprecatedChart, Message: "chart hello-0.1.0 is deprecated"}}, collector.Warnings())
+}
+
+func TestWarnResults(t *testing.T) {
+	collector := &WarningCollector{}
+	cfg := actionConfigFixture(t)
+	cfg.Warnings = collector
+	rel := releaseStub()
+
+	cfg.warnResults(rel, &kube.Result{Resources: []kube.ResourceResult{
+		{Kind: "ConfigMap", Name: "settings", Outcome: kube.OutcomeSkipped},
+		{Kind: "Deployment", Name: "web", Outcome: kube.OutcomeCreated},
+		{Kind: "PodDisruptionBudget", Name: "web", Namespace: "spaced", Outcome: kube.OutcomePatched, Warnings: []string{
+			"policy/v1beta1 PodDisruptionBudget is deprecated in v1.21+, unavailable in v1.25+; use policy/v1 PodDisruptionBudget",
+		}},
+	}})
+	cfg.warnResults(rel, nil)
+	assert.Equal(t, []Warning{{
+		Reason:  WarningSkippedResource,
+		Message: `ConfigMap "settings" was skipped due to its helm.sh/apply-policy annotation`,
+	}, {
+		Reason:  WarningKubernetes,
+		Message: `PodDisruptionBudget "web": policy/v1beta1 PodDisruptionBudget is deprecated in v1.21+, unavailable in v1.25+; use policy/v1 PodDisruptionBudget`,
+	}}, collector.Warnings())
+	assert.Equal(t, []release.ResourceWarning{{
+		Kind:      "PodDisruptionBudget",
+		Name:      "web",
+		Namespace: "spaced",
+		Message:   "policy/v1beta1 PodDisruptionBudget is deprecated in v1.21+, unavailable in v1.25+; use policy/v1 PodDisruptionBudget",
+	}}, rel.Info.Warnings)
+}
+
+func TestKubeWarningHandler(t *testing.T) {
+	collector := &WarningCollector{}
+	cfg := actionConfigFixture(t)
+	cfg.Warnings = collector
+
+	h := cfg.KubeWarningHandler()
+	h.HandleWarningHeader(299, "", "extensions/v1beta1 Ingress is deprecated in v1.14+, unavailable in v1.22+")
+	h.HandleWarningHeader(199, "", "miscellaneous warning")
+	h.HandleWarningHeader(299, "", "")
+	assert.Equal(t, []Warning{{
+		Reason:  WarningKubernetes,
+		Message: "extensions/v1beta1 Ingress is deprecated in v1.14+, unavailable in v1.22+",
+	}}, collector.Warnings())
+}
@@ -0,0 +1,64 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package auth provides the credentials used to access chart repositories and
+registries.
+
+Credentials are supplied by auth provider plugins, so that they never need to
+be stored in repositories.yaml or in the registry configuration. A plugin
+declares the hosts it provides credentials for in its plugin.yaml:
+
+	name: vault-auth
+	authProviders:
+	  - hosts:
+	      - charts.example.com
+	      - "*.registry.example.com"
+	    command: "bin/credentials"
+
+Helm runs the command with the host as its last argument and reads the
+credentials as JSON on its standard output:
+
+	{"username": "robot", "password": "s3cr3t", "expiresAt": "2021-01-01T00:00:00Z"}
+
+Each provider caches the credentials until they expire. Credentials without
+an expiration are cached for the lifetime of the provider.
+*/
+package auth // import "helm.sh/helm/v3/pkg/auth"
+
+import (
+	"time"
+)
+
+// Credentials are the credentials of a host.
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// ExpiresAt is when the credentials expire. The zero value means never.
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+}
+
+// expired reports whether the credentials expire within the given margin.
+func (c *Credentials) expired(now time.Time, margin time.Duration) bool {
+	return !c.ExpiresAt.IsZero() && now.Add(margin).After(c.ExpiresAt)
+}
+
+// Provider provides the credentials of hosts.
+type Provider interface {
+	// Credentials returns the credentials of the host, which may include a
+	// port, or nil if the provider has none for the host.
+	Credentials(host string) (*Credentials, error)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/plugin"
+)
+
+// expiryMargin is how long before they expire cached credentials are renewed.
+const expiryMargin = 30 * time.Second
+
+type cacheKey struct {
+	plugin string
+	host   string
+}
+
+type authPlugin struct {
+	name    string
+	dir     string
+	hosts   []string
+	command string
+}
+
+// pluginProvider provides the credentials returned by auth provider plugins.
+type pluginProvider struct {
+	settings *cli.EnvSettings
+	plugins  []authPlugin
+
+	// now returns the current time, and is replaced in tests.
+	now func() time.Time
+
+	mu sync.Mutex
+	// cache holds the credentials returned by the plugins.
+	cache map[cacheKey]*Credentials
+}
+
+// NewPluginProvider returns a Provider consulting the auth provider plugins
+// installed in the plugins directory. It returns nil if no plugin provides
+// credentials, so that callers can keep using their own credentials.
+func NewPluginProvider(settings *cli.EnvSettings) (Provider, error) {
+	plugins, err := plugin.FindPlugins(settings.PluginsDirectory)
+	if err != nil {
+		return nil, err
+	}
+	p := &pluginProvider{
+		settings: settings,
+		now:      time.Now,
+		cache:    map[cacheKey]*Credentials{},
+	}
+	for _, plug := range plugins {
+		for _, ap := range plug.Metadata.AuthProviders {
+			p.plugins = append(p.plugins, authPlugin{
+				name:    plug.Metadata.Name,
+				dir:     plug.Dir,
+				hosts:   ap.Hosts,
+				command: ap.Command,
+			})
+		}
+	}
+	if len(p.plugins) == 0 {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// Credentials returns the credentials of the first plugin providing
+// credentials for the host.
+func (p *pluginProvider) Credentials(host string) (*Credentials, error) {
+	for _, ap := range p.plugins {
+		if !ap.provides(host) {
+			continue
+		}
+		key := cacheKey{plugin: ap.dir, host: host}
+
+		p.mu.Lock()
+		c, ok := p.cache[key]
+		p.mu.Unlock()
+		if ok && !c.expired(p.now(), expiryMargin) {
+			return c, nil
+		}
+
+		c, err := p.run(ap, host)
+		if err != nil {
+			return nil, errors.Wrapf(err, "auth provider plugin %q failed for host %s", ap.name, host)
+		}
+		p.mu.Lock()
+		p.cache[key] = c
+		p.mu.Unlock()
+		return c, nil
+	}
+	return nil, nil
+}
+
+func (p *pluginProvider) run(ap authPlugin, host string) (*Credentials, error) {
+	commands := strings.Split(ap.command, " ")
+	argv := append(commands[1:], host)
+	prog := exec.Command(filepath.Join(ap.dir, commands[0]), argv...)
+	plugin.SetupPluginEnv(p.settings, ap.name, ap.dir)
+	prog.Env = os.Environ()
+	buf := bytes.NewBuffer(nil)
+	prog.Stdout = buf
+	prog.Stderr = os.Stderr
+	if err := prog.Run(); err != nil {
+		return nil, err
+	}
+
+	c := &Credentials{}
+	if err := json.Unmarshal(buf.Bytes(), c); err != nil {
+		return nil, errors.Wrap(err, "unable to parse credentials")
+	}
+	return c, nil
+}
+
+// provides reports whether the plugin provides credentials for the host,
+// with or without its port.
+func (ap authPlugin) provides(host string) bool {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	for _, pattern := range ap.hosts {
+		if matchHost(pattern, host) || matchHost(pattern, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchHost(pattern, host string) bool {
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	return pattern == host
+}
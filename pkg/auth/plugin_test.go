@@ -0,0 +1,101 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+func TestPluginProvider(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("TODO: refactor this test to work on windows")
+	}
+
+	tmpdir := ensure.TempDir(t)
+	defer os.RemoveAll(tmpdir)
+	calls := filepath.Join(tmpdir, "calls")
+	os.Setenv("HELM_AUTH_TEST_CALLS", calls)
+	defer os.Unsetenv("HELM_AUTH_TEST_CALLS")
+
+	env := cli.New()
+	env.PluginsDirectory = "testdata/plugins"
+	p, err := NewPluginProvider(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pp := p.(*pluginProvider)
+	pp.now = func() time.Time { return time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	creds, err := p.Credentials("charts.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds == nil || creds.Username != "robot" || creds.Password != "token-for-charts.example.com" {
+		t.Errorf("Unexpected credentials %v", creds)
+	}
+
+	creds, err = p.Credentials("eu.registry.example.com:5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds == nil || creds.Password != "token-for-eu.registry.example.com:5000" {
+		t.Errorf("Unexpected credentials %v", creds)
+	}
+
+	if creds, err := p.Credentials("example.com"); err != nil || creds != nil {
+		t.Errorf("Expected no credentials for example.com, got %v, %v", creds, err)
+	}
+
+	// Cached until they are about to expire
+	if _, err := p.Credentials("charts.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	pp.now = func() time.Time { return time.Date(2021, 1, 1, 0, 59, 45, 0, time.UTC) }
+	if _, err := p.Credentials("charts.example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"charts.example.com", "eu.registry.example.com:5000", "charts.example.com"}
+	if got := strings.Fields(string(data)); strings.Join(got, " ") != strings.Join(expect, " ") {
+		t.Errorf("Expected plugin calls %v, got %v", expect, got)
+	}
+}
+
+func TestPluginProviderWithoutPlugins(t *testing.T) {
+	env := cli.New()
+	env.PluginsDirectory = ensure.TempDir(t)
+	p, err := NewPluginProvider(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != nil {
+		t.Errorf("Expected no provider without auth provider plugins, got %v", p)
+	}
+}
@@ -0,0 +1,8 @@
+#!/bin/sh
+# Prints credentials for the host given as the last argument, counting the
+# calls in $HELM_AUTH_TEST_CALLS.
+for host; do :; done
+if [ -n "$HELM_AUTH_TEST_CALLS" ]; then
+  echo "$host" >> "$HELM_AUTH_TEST_CALLS"
+fi
+echo "{\"username\": \"robot\", \"password\": \"token-for-$host\", \"expiresAt\": \"2021-01-01T01:00:00Z\"}"
@@ -0,0 +1,267 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package benchmark measures the cost of the render and apply pipeline.
+//
+// It renders, installs, upgrades and uninstalls a synthetic chart of a
+// configurable size, and reports the latency and memory allocations of every
+// phase. It runs against any action.Configuration, so the same harness can be
+// pointed at an in-memory fake or at a real (for instance kind) cluster.
+package benchmark
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// Phase is a measured step of the release lifecycle.
+type Phase string
+
+const (
+	// PhaseRender renders and sorts the chart's manifests, without any
+	// cluster interaction.
+	PhaseRender Phase = "render"
+	// PhaseInstall installs a release of the chart.
+	PhaseInstall Phase = "install"
+	// PhaseUpgrade upgrades the release with values changing every resource.
+	PhaseUpgrade Phase = "upgrade"
+	// PhaseUninstall uninstalls the release.
+	PhaseUninstall Phase = "uninstall"
+)
+
+// phases lists the phases in the order they run and are reported.
+var phases = []Phase{PhaseRender, PhaseInstall, PhaseUpgrade, PhaseUninstall}
+
+// Options configures a benchmark run.
+type Options struct {
+	Chart ChartOptions
+	// Iterations is the number of times every phase is measured.
+	Iterations int
+	// Namespace is the namespace the releases are installed in.
+	Namespace string
+	// ReleasePrefix prefixes the names of the releases, which are suffixed
+	// with the iteration number.
+	ReleasePrefix string
+}
+
+// Result holds the measurements of one phase.
+type Result struct {
+	Phase      Phase         `json:"phase"`
+	Iterations int           `json:"iterations"`
+	Min        time.Duration `json:"min"`
+	Mean       time.Duration `json:"mean"`
+	P50        time.Duration `json:"p50"`
+	P95        time.Duration `json:"p95"`
+	Max        time.Duration `json:"max"`
+	// BytesPerOp is the mean number of bytes allocated by one iteration.
+	BytesPerOp uint64 `json:"bytes_per_op"`
+	// AllocsPerOp is the mean number of heap allocations of one iteration.
+	AllocsPerOp uint64 `json:"allocs_per_op"`
+}
+
+// Report is the outcome of a benchmark run.
+type Report struct {
+	Chart ChartOptions `json:"chart"`
+	// Resources is the total number of resources rendered by the chart.
+	Resources int      `json:"resources"`
+	Results   []Result `json:"results"`
+}
+
+// NewFakeConfiguration returns a configuration backed by in-memory release
+// storage and a kube client that accepts every request without a cluster.
+func NewFakeConfiguration() *action.Configuration {
+	return &action.Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   &kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          func(_ string, _ ...interface{}) {},
+	}
+}
+
+// Run measures every phase o.Iterations times against cfg.
+//
+// Every iteration installs its own release and uninstalls it at the end, so
+// a run leaves no release behind, even when it fails.
+func Run(cfg *action.Configuration, o Options) (*Report, error) {
+	if o.Iterations < 1 {
+		return nil, errors.New("at least one iteration is required")
+	}
+	if o.ReleasePrefix == "" {
+		o.ReleasePrefix = "bench"
+	}
+
+	chrt := SyntheticChart(o.Chart)
+	samples := make(map[Phase]*sample, len(phases))
+	for _, p := range phases {
+		samples[p] = &sample{}
+	}
+
+	for i := 0; i < o.Iterations; i++ {
+		name := fmt.Sprintf("%s-%d", o.ReleasePrefix, i)
+		if err := iterate(cfg, chrt, name, o, samples); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &Report{
+		Chart:     o.Chart,
+		Resources: o.Chart.Templates * o.Chart.Resources,
+	}
+	for _, p := range phases {
+		report.Results = append(report.Results, samples[p].result(p))
+	}
+	return report, nil
+}
+
+// iterate measures every phase once, on a release named name.
+func iterate(cfg *action.Configuration, chrt *chart.Chart, name string, o Options, samples map[Phase]*sample) error {
+	if err := samples[PhaseRender].measure(func() error {
+		return render(chrt, name, o)
+	}); err != nil {
+		return errors.Wrapf(err, "%s of %s failed", PhaseRender, name)
+	}
+
+	install := action.NewInstall(cfg)
+	install.ReleaseName = name
+	install.Namespace = o.Namespace
+	if err := samples[PhaseInstall].measure(func() error {
+		_, err := install.Run(chrt, Values(o.Chart, 1))
+		return err
+	}); err != nil {
+		return errors.Wrapf(err, "%s of %s failed", PhaseInstall, name)
+	}
+
+	uninstalled := false
+	defer func() {
+		if !uninstalled {
+			if _, uerr := action.NewUninstall(cfg).Run(name); uerr != nil {
+				cfg.Log("failed to clean up release %s: %s", name, uerr)
+			}
+		}
+	}()
+
+	upgrade := action.NewUpgrade(cfg)
+	upgrade.Namespace = o.Namespace
+	if err := samples[PhaseUpgrade].measure(func() error {
+		_, err := upgrade.Run(name, chrt, Values(o.Chart, 2))
+		return err
+	}); err != nil {
+		return errors.Wrapf(err, "%s of %s failed", PhaseUpgrade, name)
+	}
+
+	if err := samples[PhaseUninstall].measure(func() error {
+		_, err := action.NewUninstall(cfg).Run(name)
+		return err
+	}); err != nil {
+		return errors.Wrapf(err, "%s of %s failed", PhaseUninstall, name)
+	}
+	uninstalled = true
+	return nil
+}
+
+// render runs the client side of the pipeline: the template engine and the
+// manifest sorter.
+func render(chrt *chart.Chart, name string, o Options) error {
+	options := chartutil.ReleaseOptions{
+		Name:      name,
+		Namespace: o.Namespace,
+		Revision:  1,
+		IsInstall: true,
+	}
+	vals, err := chartutil.ToRenderValues(chrt, Values(o.Chart, 1), options, chartutil.DefaultCapabilities)
+	if err != nil {
+		return err
+	}
+	files, err := engine.Render(chrt, vals)
+	if err != nil {
+		return err
+	}
+	_, _, err = releaseutil.SortManifests(files, chartutil.DefaultCapabilities.APIVersions, releaseutil.InstallOrder)
+	return err
+}
+
+// sample accumulates the measurements of one phase.
+type sample struct {
+	durations []time.Duration
+	bytes     uint64
+	allocs    uint64
+}
+
+// measure runs fn once and records its latency and allocations.
+func (s *sample) measure(fn func() error) error {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	err := fn()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		return err
+	}
+
+	s.durations = append(s.durations, elapsed)
+	s.bytes += after.TotalAlloc - before.TotalAlloc
+	s.allocs += after.Mallocs - before.Mallocs
+	return nil
+}
+
+func (s *sample) result(p Phase) Result {
+	r := Result{Phase: p, Iterations: len(s.durations)}
+	if r.Iterations == 0 {
+		return r
+	}
+
+	sorted := append([]time.Duration(nil), s.durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	n := uint64(r.Iterations)
+	r.Min = sorted[0]
+	r.Max = sorted[len(sorted)-1]
+	r.Mean = total / time.Duration(r.Iterations)
+	r.P50 = percentile(sorted, 50)
+	r.P95 = percentile(sorted, 95)
+	r.BytesPerOp = s.bytes / n
+	r.AllocsPerOp = s.allocs / n
+	return r
+}
+
+// percentile returns the nearest-rank percentile p of the sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
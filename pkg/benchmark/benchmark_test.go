@@ -0,0 +1,114 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+func TestSyntheticChart(t *testing.T) {
+	o := ChartOptions{Templates: 3, Resources: 4, ValueKeys: 2}
+	chrt := SyntheticChart(o)
+	if err := chrt.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	options := chartutil.ReleaseOptions{Name: "bench", Namespace: "default", Revision: 1, IsInstall: true}
+	vals, err := chartutil.ToRenderValues(chrt, Values(o, 7), options, chartutil.DefaultCapabilities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := engine.Render(chrt, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, manifests, err := releaseutil.SortManifests(files, chartutil.DefaultCapabilities.APIVersions, releaseutil.InstallOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(manifests) != 12 {
+		t.Fatalf("expected 12 manifests, got %d", len(manifests))
+	}
+	for _, m := range manifests {
+		if m.Head.Kind != "ConfigMap" {
+			t.Errorf("expected a ConfigMap, got %s", m.Head.Kind)
+		}
+		if !strings.Contains(m.Content, `revision: "7"`) || !strings.Contains(m.Content, "key-1:") {
+			t.Errorf("expected the values to be rendered in %s", m.Content)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	cfg := NewFakeConfiguration()
+	report, err := Run(cfg, Options{
+		Chart:      ChartOptions{Templates: 2, Resources: 2, ValueKeys: 2},
+		Iterations: 3,
+		Namespace:  "default",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Resources != 4 {
+		t.Errorf("expected 4 resources, got %d", report.Resources)
+	}
+	if len(report.Results) != len(phases) {
+		t.Fatalf("expected %d results, got %d", len(phases), len(report.Results))
+	}
+	for i, r := range report.Results {
+		if r.Phase != phases[i] {
+			t.Errorf("expected phase %s, got %s", phases[i], r.Phase)
+		}
+		if r.Iterations != 3 {
+			t.Errorf("%s: expected 3 iterations, got %d", r.Phase, r.Iterations)
+		}
+		if r.Min > r.P50 || r.P50 > r.P95 || r.P95 > r.Max {
+			t.Errorf("%s: expected ordered latencies, got %+v", r.Phase, r)
+		}
+	}
+
+	rels, err := cfg.Releases.ListDeployed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rels) != 0 {
+		t.Errorf("expected no release to be left behind, got %d", len(rels))
+	}
+}
+
+func TestRunRequiresIterations(t *testing.T) {
+	if _, err := Run(NewFakeConfiguration(), Options{Chart: DefaultChartOptions}); err == nil {
+		t.Error("expected an error without iterations")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	for p, want := range map[float64]time.Duration{0: 1, 50: 5, 95: 10, 100: 10} {
+		if got := percentile(sorted, p); got != want {
+			t.Errorf("percentile %v: expected %d, got %d", p, want, got)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// ChartOptions describes the size of a synthetic chart.
+type ChartOptions struct {
+	// Templates is the number of template files in the chart.
+	Templates int `json:"templates"`
+	// Resources is the number of resources rendered by each template.
+	Resources int `json:"resources_per_template"`
+	// ValueKeys is the number of entries in the chart's data values. Every
+	// rendered resource carries all of them.
+	ValueKeys int `json:"value_keys"`
+}
+
+// DefaultChartOptions is a chart of a size comparable to a typical
+// application chart.
+var DefaultChartOptions = ChartOptions{
+	Templates: 20,
+	Resources: 5,
+	ValueKeys: 10,
+}
+
+const helpersTemplate = `{{- define "bench.labels" -}}
+app.kubernetes.io/name: {{ .Chart.Name }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+helm.sh/chart: {{ printf "%s-%s" .Chart.Name .Chart.Version }}
+{{- end -}}
+`
+
+// resourceTemplate renders the ConfigMaps of one template file. It is
+// formatted with the template index and the number of resources.
+const resourceTemplate = `{{- range $i := until %d }}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ $.Release.Name }}-t%d-{{ $i }}
+  labels:
+    {{- include "bench.labels" $ | nindent 4 }}
+data:
+  revision: {{ $.Values.revision | quote }}
+  {{- range $key, $value := $.Values.data }}
+  {{ $key }}: {{ $value | quote }}
+  {{- end }}
+{{- end }}
+`
+
+// SyntheticChart builds an in-memory chart of the given size. Every template
+// renders ConfigMaps only, so that the chart can be applied to any cluster.
+func SyntheticChart(o ChartOptions) *chart.Chart {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "bench",
+			Version:    "0.1.0",
+			Type:       "application",
+		},
+		Values: Values(o, 0),
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(helpersTemplate)},
+		},
+	}
+	for t := 0; t < o.Templates; t++ {
+		c.Templates = append(c.Templates, &chart.File{
+			Name: fmt.Sprintf("templates/configmaps-%d.yaml", t),
+			Data: []byte(fmt.Sprintf(resourceTemplate, o.Resources, t)),
+		})
+	}
+	return c
+}
+
+// Values returns the values of a synthetic chart for the given revision.
+// Changing the revision changes every rendered resource, so an upgrade always
+// has work to do.
+func Values(o ChartOptions, revision int) map[string]interface{} {
+	data := make(map[string]interface{}, o.ValueKeys)
+	for k := 0; k < o.ValueKeys; k++ {
+		data[fmt.Sprintf("key-%d", k)] = strings.Repeat(fmt.Sprintf("%d", k%10), 32)
+	}
+	return map[string]interface{}{
+		"revision": revision,
+		"data":     data,
+	}
+}
@@ -29,7 +29,8 @@ type Dependency struct {
 	// Version is the version (range) of this chart.
 	//
 	// A lock file will always produce a single version, while a dependency
-	// may contain a semantic version range.
+	// may contain a semantic version range. Dependencies in oci:// repositories
+	// require an exact version, or the digest of the chart's manifest.
 	Version string `json:"version,omitempty"`
 	// The URL to the repository.
 	//
@@ -47,6 +48,13 @@ type Dependency struct {
 	ImportValues []interface{} `json:"import-values,omitempty"`
 	// Alias usable alias to be used for the chart
 	Alias string `json:"alias,omitempty"`
+	// ValuesFiles are files of the parent chart holding the default values of
+	// the dependency, merged under its key in the order they are listed. The
+	// values under its key in the values.yaml of the parent take precedence.
+	ValuesFiles []string `json:"values-files,omitempty"`
+	// Digest is the digest of the manifest of a dependency pulled from an
+	// oci:// repository. A lock file records it to pin the dependency.
+	Digest string `json:"digest,omitempty"`
 }
 
 // Lock is a lock file for dependencies.
@@ -59,4 +67,37 @@ type Lock struct {
 	Digest string `json:"digest"`
 	// Dependencies is the list of dependencies that this lock file has locked.
 	Dependencies []*Dependency `json:"dependencies"`
+	// Vendored is the list of dependencies that are expanded into the charts
+	// directory by 'helm dependency vendor'.
+	Vendored []*VendoredDependency `json:"vendored,omitempty"`
+	// Local is the list of dependencies fetched from local directories with
+	// file:// repositories, along with the digest of their content.
+	Local []*LocalDependency `json:"local,omitempty"`
+}
+
+// LocalDependency records the content of a dependency fetched from a local
+// directory, so that changes which do not bump its version are detected.
+type LocalDependency struct {
+	// Name is the name of the dependency.
+	Name string `json:"name"`
+	// Repository is the file:// URL of the directory of the dependency.
+	Repository string `json:"repository"`
+	// Digest is the digest of the directory of the dependency.
+	Digest string `json:"digest"`
+}
+
+// VendoredDependency records the provenance of a dependency expanded into a
+// directory of the charts directory.
+type VendoredDependency struct {
+	// Name is the name of the dependency, and of its directory in charts/.
+	Name string `json:"name"`
+	// Version is the version of the dependency.
+	Version string `json:"version"`
+	// Repository is the repository the dependency was fetched from.
+	Repository string `json:"repository"`
+	// ArchiveDigest is the digest of the chart archive that was expanded.
+	ArchiveDigest string `json:"archiveDigest"`
+	// Digest is the digest of the expanded directory, used to detect local
+	// modifications.
+	Digest string `json:"digest"`
 }
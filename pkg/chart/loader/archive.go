@@ -24,8 +24,6 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"path"
-	"regexp"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -33,8 +31,6 @@ import (
 	"helm.sh/helm/v3/pkg/chart"
 )
 
-var drivePathPattern = regexp.MustCompile(`^[a-zA-Z]:/`)
-
 // FileLoader loads a chart from a file
 type FileLoader string
 
@@ -139,30 +135,13 @@ func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
 		}
 
 		parts := strings.Split(hd.Name, delimiter)
-		n := strings.Join(parts[1:], delimiter)
-
-		// Normalize the path to the / delimiter
-		n = strings.ReplaceAll(n, delimiter, "/")
-
-		if path.IsAbs(n) {
-			return nil, errors.New("chart illegally contains absolute paths")
-		}
-
-		n = path.Clean(n)
-		if n == "." {
+		if len(parts) == 1 {
 			// In this case, the original path was relative when it should have been absolute.
 			return nil, errors.Errorf("chart illegally contains content outside the base directory: %q", hd.Name)
 		}
-		if strings.HasPrefix(n, "..") {
-			return nil, errors.New("chart illegally references parent directory")
-		}
-
-		// In some particularly arcane acts of path creativity, it is possible to intermix
-		// UNIX and Windows style paths in such a way that you produce a result of the form
-		// c:/foo even after all the built-in absolute path checks. So we explicitly check
-		// for this condition.
-		if drivePathPattern.MatchString(n) {
-			return nil, errors.New("chart contains illegally named files")
+		n, err := CleanPath(strings.Join(parts[1:], "/"))
+		if err != nil {
+			return nil, err
 		}
 
 		if parts[0] == "Chart.yaml" {
@@ -26,6 +26,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"helm.sh/helm/v3/internal/fileutil"
 	"helm.sh/helm/v3/internal/ignore"
 	"helm.sh/helm/v3/internal/sympath"
 	"helm.sh/helm/v3/pkg/chart"
@@ -41,10 +42,27 @@ func (l DirLoader) Load() (*chart.Chart, error) {
 	return LoadDir(string(l))
 }
 
+// DirOptions tunes how LoadDirWithOptions reads a chart directory.
+type DirOptions struct {
+	// ConfineSymlinks rejects the symbolic links of the chart resolving
+	// outside of the chart directory, such as a templates directory linked
+	// from another checkout. Symbolic links are followed wherever they lead
+	// otherwise.
+	ConfineSymlinks bool
+}
+
 // LoadDir loads from a directory.
 //
 // This loads charts only from directories.
 func LoadDir(dir string) (*chart.Chart, error) {
+	return LoadDirWithOptions(dir, DirOptions{})
+}
+
+// LoadDirWithOptions loads from a directory, as set by opts.
+//
+// Symbolic links are followed, but never the ones to a directory containing
+// them, which would make loading the chart loop forever.
+func LoadDirWithOptions(dir string, opts DirOptions) (*chart.Chart, error) {
 	topdir, err := filepath.Abs(dir)
 	if err != nil {
 		return nil, err
@@ -102,7 +120,7 @@ func LoadDir(dir string) (*chart.Chart, error) {
 			return fmt.Errorf("cannot load irregular file %s as it has file mode type bits set", name)
 		}
 
-		data, err := ioutil.ReadFile(name)
+		data, err := ioutil.ReadFile(fileutil.LongPath(name))
 		if err != nil {
 			return errors.Wrapf(err, "error reading %s", n)
 		}
@@ -112,7 +130,7 @@ func LoadDir(dir string) (*chart.Chart, error) {
 		files = append(files, &BufferedFile{Name: n, Data: data})
 		return nil
 	}
-	if err = sympath.Walk(topdir, walk); err != nil {
+	if err = sympath.WalkWithOptions(topdir, sympath.Options{Confine: opts.ConfineSymlinks}, walk); err != nil {
 		return c, err
 	}
 
@@ -69,10 +69,20 @@ type BufferedFile struct {
 }
 
 // LoadFiles loads from in-memory files.
+//
+// The names of the files are normalized with CleanPath.
 func LoadFiles(files []*BufferedFile) (*chart.Chart, error) {
 	c := new(chart.Chart)
 	subcharts := make(map[string][]*BufferedFile)
 
+	for _, f := range files {
+		n, err := CleanPath(f.Name)
+		if err != nil {
+			return c, err
+		}
+		f.Name = n
+	}
+
 	// do not rely on assumed ordering of files in the chart and crash
 	// if Chart.yaml was not coming early enough to initialize metadata
 	for _, f := range files {
@@ -85,6 +85,11 @@ func TestLoadDirWithSymlink(t *testing.T) {
 	verifyChart(t, c)
 	verifyDependencies(t, c)
 	verifyDependenciesLock(t, c)
+
+	_, err = LoadDirWithOptions("testdata/frobnitz_with_symlink", DirOptions{ConfineSymlinks: true})
+	if err == nil || !strings.Contains(err.Error(), "outside of") {
+		t.Errorf("expected the symbolic link outside of the chart to be rejected, got %v", err)
+	}
 }
 
 func TestBomTestData(t *testing.T) {
@@ -222,6 +227,24 @@ func TestLoadFiles_BadCases(t *testing.T) {
 				},
 			},
 			expectError: "validation: chart.metadata.apiVersion is required"},
+		{
+			name: "These files reference the parent directory",
+			bufferedFiles: []*BufferedFile{
+				{
+					Name: "templates/../../secret.yaml",
+					Data: []byte(""),
+				},
+			},
+			expectError: "chart illegally references parent directory"},
+		{
+			name: "These files have a Windows absolute path",
+			bufferedFiles: []*BufferedFile{
+				{
+					Name: "\\\\server\\share\\secret.yaml",
+					Data: []byte(""),
+				},
+			},
+			expectError: "chart illegally contains absolute paths"},
 	} {
 		_, err := LoadFiles(tt.bufferedFiles)
 		if err == nil {
@@ -307,6 +330,27 @@ icon: https://example.com/64x64.png
 	}
 }
 
+func TestLoadFilesWindowsSeparators(t *testing.T) {
+	c, err := LoadFiles([]*BufferedFile{
+		{Name: "Chart.yaml", Data: []byte("apiVersion: v2\nname: frobnitz\nversion: 1.2.3\n")},
+		{Name: "templates\\service.yaml", Data: []byte("some service")},
+		{Name: "charts\\sub\\Chart.yaml", Data: []byte("apiVersion: v2\nname: sub\nversion: 0.1.0\n")},
+		{Name: "charts\\sub\\templates\\.\\deployment.yaml", Data: []byte("some deployment")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Templates) != 1 || c.Templates[0].Name != "templates/service.yaml" {
+		t.Errorf("expected the template to be named templates/service.yaml, got %v", c.Templates)
+	}
+	if len(c.Dependencies()) != 1 {
+		t.Fatalf("expected 1 dependency, got %d", len(c.Dependencies()))
+	}
+	if sub := c.Dependencies()[0]; len(sub.Templates) != 1 || sub.Templates[0].Name != "templates/deployment.yaml" {
+		t.Errorf("expected the subchart template to be named templates/deployment.yaml, got %v", sub.Templates)
+	}
+}
+
 // Test the order of file loading. The Chart.yaml file needs to come first for
 // later comparison checks. See https://github.com/helm/helm/pull/8948
 func TestLoadFilesOrder(t *testing.T) {
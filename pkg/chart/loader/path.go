@@ -0,0 +1,60 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var drivePathPattern = regexp.MustCompile(`^[a-zA-Z]:/`)
+
+// CleanPath normalizes the name of a file of a chart to a clean path relative
+// to the chart directory, using / as separator whatever the operating system
+// the chart was written on.
+//
+// It fails if the name is absolute, or if it is outside of the chart
+// directory. Every name coming from an archive, a directory or an in-memory
+// chart goes through it, so that all of them are checked the same way.
+func CleanPath(name string) (string, error) {
+	// Charts written on Windows may use \ as separator
+	n := strings.ReplaceAll(name, "\\", "/")
+
+	if path.IsAbs(n) {
+		return "", errors.New("chart illegally contains absolute paths")
+	}
+
+	n = path.Clean(n)
+	if n == "." {
+		return "", errors.Errorf("chart illegally contains content outside the base directory: %q", name)
+	}
+	if n == ".." || strings.HasPrefix(n, "../") {
+		return "", errors.New("chart illegally references parent directory")
+	}
+
+	// In some particularly arcane acts of path creativity, it is possible to intermix
+	// UNIX and Windows style paths in such a way that you produce a result of the form
+	// c:/foo even after all the built-in absolute path checks. So we explicitly check
+	// for this condition.
+	if drivePathPattern.MatchString(n) {
+		return "", errors.New("chart contains illegally named files")
+	}
+	return n, nil
+}
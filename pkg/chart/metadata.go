@@ -15,6 +15,15 @@ limitations under the License.
 
 package chart
 
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+// EndOfSupportLayout is the layout of the endOfSupport date of a chart.
+const EndOfSupportLayout = "2006-01-02"
+
 // Maintainer describes a Chart maintainer.
 type Maintainer struct {
 	// Name is a user name or organization name
@@ -25,6 +34,19 @@ type Maintainer struct {
 	URL string `json:"url,omitempty"`
 }
 
+// Compatibility is a row of the compatibility matrix of a chart. Every field
+// is a SemVer constraint; an empty field places no restriction.
+type Compatibility struct {
+	// Version is the range of chart versions this row applies to
+	Version string `json:"version,omitempty"`
+	// AppVersion is the range of application versions supported
+	AppVersion string `json:"appVersion,omitempty"`
+	// KubeVersion is the range of Kubernetes versions supported
+	KubeVersion string `json:"kubeVersion,omitempty"`
+	// UpgradeFrom is the range of chart versions that can be upgraded from
+	UpgradeFrom string `json:"upgradeFrom,omitempty"`
+}
+
 // Metadata for a Chart file. This models the structure of a Chart.yaml file.
 type Metadata struct {
 	// The name of the chart
@@ -53,6 +75,12 @@ type Metadata struct {
 	AppVersion string `json:"appVersion,omitempty"`
 	// Whether or not this chart is deprecated
 	Deprecated bool `json:"deprecated,omitempty"`
+	// ReplacedBy is a reference to the chart replacing this deprecated chart
+	ReplacedBy string `json:"replacedBy,omitempty"`
+	// EndOfSupport is the date, formatted as YYYY-MM-DD, after which this
+	// chart is no longer supported. A chart past its end of support is
+	// considered deprecated.
+	EndOfSupport string `json:"endOfSupport,omitempty"`
 	// Annotations are additional mappings uninterpreted by Helm,
 	// made available for inspection by other applications.
 	Annotations map[string]string `json:"annotations,omitempty"`
@@ -62,6 +90,9 @@ type Metadata struct {
 	Dependencies []*Dependency `json:"dependencies,omitempty"`
 	// Specifies the chart type: application or library
 	Type string `json:"type,omitempty"`
+	// Compatibility is the matrix of app, Kubernetes and upgrade versions
+	// supported by versions of this chart.
+	Compatibility []*Compatibility `json:"compatibility,omitempty"`
 }
 
 // Validate checks the metadata for known issues, returning an error if metadata is not correct
@@ -81,6 +112,11 @@ func (md *Metadata) Validate() error {
 	if !isValidChartType(md.Type) {
 		return ValidationError("chart.metadata.type must be application or library")
 	}
+	if md.EndOfSupport != "" {
+		if _, err := time.Parse(EndOfSupportLayout, md.EndOfSupport); err != nil {
+			return ValidationErrorf("chart.metadata.endOfSupport %q must be a date formatted as YYYY-MM-DD", md.EndOfSupport)
+		}
+	}
 
 	// Aliases need to be validated here to make sure that the alias name does
 	// not contain any illegal characters.
@@ -109,5 +145,10 @@ func validateDependency(dep *Dependency) error {
 	if len(dep.Alias) > 0 && !aliasNameFormat.MatchString(dep.Alias) {
 		return ValidationErrorf("dependency %q has disallowed characters in the alias", dep.Name)
 	}
+	for _, file := range dep.ValuesFiles {
+		if clean := path.Clean(file); file == "" || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return ValidationErrorf("dependency %q has a values file outside of the chart: %q", dep.Name, file)
+		}
+	}
 	return nil
 }
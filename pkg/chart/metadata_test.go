@@ -72,6 +72,14 @@ func TestValidate(t *testing.T) {
 			},
 			ValidationError("dependency \"bad\" has disallowed characters in the alias"),
 		},
+		{
+			&Metadata{Name: "test", APIVersion: "v2", Version: "1.0", Deprecated: true, EndOfSupport: "2021-06-30"},
+			nil,
+		},
+		{
+			&Metadata{Name: "test", APIVersion: "v2", Version: "1.0", EndOfSupport: "30/06/2021"},
+			ValidationError("chart.metadata.endOfSupport \"30/06/2021\" must be a date formatted as YYYY-MM-DD"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -105,3 +113,28 @@ func TestValidateDependency(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateDependencyValuesFiles(t *testing.T) {
+	dep := &Dependency{
+		Name: "example",
+	}
+	for value, shouldFail := range map[string]bool{
+		"redis-values.yaml":       false,
+		"values/redis.yaml":       false,
+		"values/../redis.yaml":    false,
+		"..values.yaml":           false,
+		"":                        true,
+		"/etc/passwd":             true,
+		"../redis.yaml":           true,
+		"values/../../redis.yaml": true,
+		"..":                      true,
+	} {
+		dep.ValuesFiles = []string{value}
+		res := validateDependency(dep)
+		if res != nil && !shouldFail {
+			t.Errorf("Failed on case %q", value)
+		} else if res == nil && shouldFail {
+			t.Errorf("Expected failure for %q", value)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package charttest provides golden-file snapshot testing for charts.
+
+It is meant to be used from the Go test suites of chart repositories:
+
+	func TestWebDefaults(t *testing.T) {
+		charttest.AssertGoldenChart(t, "../charts/web", charttest.Options{
+			Values: map[string]interface{}{"replicaCount": 3},
+		}, "web-defaults.yaml")
+	}
+
+The chart is rendered locally, without a cluster, and the documents are
+normalized so that snapshots only change when the rendered objects do.
+Golden files are rewritten instead of compared when Options.Update is set or
+the HELM_UPDATE_GOLDEN environment variable is "true".
+*/
+package charttest // import "helm.sh/helm/v3/pkg/charttest"
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/unittest"
+)
+
+// UpdateEnvVar is the environment variable that switches golden-file
+// assertions into update mode.
+const UpdateEnvVar = "HELM_UPDATE_GOLDEN"
+
+// TestingT describes the subset of testing.T used by the assertions.
+type TestingT interface {
+	Fatalf(string, ...interface{})
+	Helper()
+}
+
+// Options describes how a chart is rendered for a snapshot.
+type Options struct {
+	// ReleaseName defaults to "RELEASE-NAME".
+	ReleaseName string
+	// Namespace defaults to "default".
+	Namespace string
+	// Values are merged over the chart's default values.
+	Values map[string]interface{}
+	// Templates restricts the snapshot to the given chart templates, e.g.
+	// "templates/deployment.yaml".
+	Templates []string
+	// KubeVersion and APIVersions describe the cluster to render for.
+	KubeVersion string
+	APIVersions []string
+	// Update rewrites the golden file instead of comparing against it.
+	Update bool
+}
+
+// Render renders the chart and returns its documents in normalized form:
+// documents are sorted by template, kind, namespace and name, keys are
+// sorted, and every document is preceded by a comment naming its template.
+func Render(ch *chart.Chart, opts Options) ([]byte, error) {
+	docs, err := unittest.Render(ch, opts.Templates, &unittest.Case{
+		Values: opts.Values,
+		Release: unittest.ReleaseOptions{
+			Name:      opts.ReleaseName,
+			Namespace: opts.Namespace,
+		},
+		Capabilities: unittest.CapabilitiesOptions{
+			KubeVersion: opts.KubeVersion,
+			APIVersions: opts.APIVersions,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return Normalize(docs)
+}
+
+// Normalize serializes rendered documents in a stable order.
+func Normalize(docs []unittest.Document) ([]byte, error) {
+	sorted := append([]unittest.Document(nil), docs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sortKey(sorted[i]), sortKey(sorted[j])
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+
+	var buf bytes.Buffer
+	for _, d := range sorted {
+		out, err := yaml.Marshal(d.Object)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to serialize a document rendered from %s", d.Template)
+		}
+		fmt.Fprintf(&buf, "---\n# Source: %s\n%s", d.Template, out)
+	}
+	return buf.Bytes(), nil
+}
+
+func sortKey(d unittest.Document) [4]string {
+	kind, _ := d.Object["kind"].(string)
+	var namespace, name string
+	if md, ok := d.Object["metadata"].(map[string]interface{}); ok {
+		namespace, _ = md["namespace"].(string)
+		name, _ = md["name"].(string)
+	}
+	return [4]string{d.Template, kind, namespace, name}
+}
+
+// AssertGolden renders the chart and compares the result with the golden
+// file. Relative golden file names are resolved against "testdata".
+func AssertGolden(t TestingT, ch *chart.Chart, opts Options, golden string) {
+	t.Helper()
+
+	actual, err := Render(ch, opts)
+	if err != nil {
+		t.Fatalf("failed to render chart %s: %s", ch.Name(), err)
+	}
+	if err := Compare(actual, golden, opts.Update); err != nil {
+		t.Fatalf("%s", err)
+	}
+}
+
+// AssertGoldenChart loads the chart at the given path and behaves like AssertGolden.
+func AssertGoldenChart(t TestingT, chartPath string, opts Options, golden string) {
+	t.Helper()
+
+	ch, err := loader.Load(chartPath)
+	if err != nil {
+		t.Fatalf("failed to load chart %s: %s", chartPath, err)
+	}
+	AssertGolden(t, ch, opts, golden)
+}
+
+// Compare checks actual against the contents of the golden file and returns
+// an error with a line diff if they differ. In update mode, the golden file
+// is written instead.
+func Compare(actual []byte, golden string, update bool) error {
+	golden = goldenPath(golden)
+	actual = bytes.ReplaceAll(actual, []byte("\r\n"), []byte("\n"))
+
+	if update || updateFromEnv() {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(golden, actual, 0644)
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read golden file %s (set %s=true to create it)", golden, UpdateEnvVar)
+	}
+	expected = bytes.ReplaceAll(expected, []byte("\r\n"), []byte("\n"))
+	if bytes.Equal(expected, actual) {
+		return nil
+	}
+	return errors.Errorf("rendered chart does not match golden file %s (set %s=true to update it):\n%s",
+		golden, UpdateEnvVar, Diff(string(expected), string(actual)))
+}
+
+func goldenPath(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join("testdata", name)
+}
+
+func updateFromEnv() bool {
+	update, _ := strconv.ParseBool(os.Getenv(UpdateEnvVar))
+	return update
+}
@@ -0,0 +1,118 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package charttest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func snapshotChart() *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: "v2", Name: "snap", Version: "0.1.0"},
+		Values:   map[string]interface{}{"port": 80},
+		Templates: []*chart.File{
+			{Name: "templates/b.yaml", Data: []byte("kind: Service\nmetadata:\n  name: z\nspec:\n  port: {{ .Values.port }}\n---\nkind: Service\nmetadata:\n  name: a\n")},
+			{Name: "templates/a.yaml", Data: []byte("metadata:\n  name: {{ .Release.Name }}\nkind: ConfigMap\n")},
+		},
+	}
+}
+
+func TestRenderIsNormalized(t *testing.T) {
+	out, err := Render(snapshotChart(), Options{ReleaseName: "snap"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `---
+# Source: templates/a.yaml
+kind: ConfigMap
+metadata:
+  name: snap
+---
+# Source: templates/b.yaml
+kind: Service
+metadata:
+  name: a
+---
+# Source: templates/b.yaml
+kind: Service
+metadata:
+  name: z
+spec:
+  port: 80
+`
+	if string(out) != expected {
+		t.Errorf("unexpected output:\n%s", Diff(expected, string(out)))
+	}
+}
+
+func TestCompareAndUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-charttest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	golden := filepath.Join(dir, "nested", "snap.yaml")
+
+	if err := Compare([]byte("a: 1\n"), golden, false); err == nil {
+		t.Fatal("expected an error for a missing golden file")
+	}
+	if err := Compare([]byte("a: 1\n"), golden, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := Compare([]byte("a: 1\n"), golden, false); err != nil {
+		t.Fatal(err)
+	}
+
+	err = Compare([]byte("a: 2\n"), golden, false)
+	if err == nil {
+		t.Fatal("expected a mismatch")
+	}
+	if !strings.Contains(err.Error(), "-a: 1\n+a: 2\n") {
+		t.Errorf("expected a diff in the error, got:\n%s", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	if d := Diff("a\nb\n", "a\nb\n"); d != "" {
+		t.Errorf("expected no diff, got %q", d)
+	}
+
+	want := "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+	got := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n"
+	expected := `--- want
++++ got
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`
+	if d := Diff(want, got); d != expected {
+		t.Errorf("unexpected diff:\n%s", d)
+	}
+}
@@ -0,0 +1,28 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package charttest
+
+import "helm.sh/helm/v3/pkg/diff"
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// Diff returns a unified diff of want and got. It returns an empty string if
+// both are equal.
+func Diff(want, got string) string {
+	return diff.Text("want", "got", want, got, diffContext)
+}
@@ -16,6 +16,9 @@ limitations under the License.
 package chartutil
 
 import (
+	"sort"
+	"sync"
+
 	"k8s.io/client-go/kubernetes/scheme"
 
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -37,6 +40,7 @@ var (
 		},
 		APIVersions: DefaultVersionSet,
 		HelmVersion: helmversion.Get(),
+		CRDs:        NewCRDs(nil),
 	}
 )
 
@@ -48,6 +52,8 @@ type Capabilities struct {
 	APIVersions VersionSet
 	// HelmVersion is the build information for this helm version
 	HelmVersion helmversion.BuildInfo
+	// CRDs are the CustomResourceDefinitions installed in the cluster
+	CRDs *CRDs
 }
 
 // KubeVersion is the Kubernetes version.
@@ -80,6 +86,122 @@ func (v VersionSet) Has(apiVersion string) bool {
 	return false
 }
 
+// CRD is a CustomResourceDefinition installed in the cluster.
+type CRD struct {
+	// Name is the name of the definition, such as
+	// 'certificates.cert-manager.io'
+	Name  string `json:"name"`
+	Group string `json:"group"`
+	Kind  string `json:"kind"`
+	// Scope is either 'Namespaced' or 'Cluster'
+	Scope    string       `json:"scope"`
+	Versions []CRDVersion `json:"versions"`
+}
+
+// CRDVersion is a version of a CustomResourceDefinition.
+type CRDVersion struct {
+	Name    string `json:"name"`
+	Served  bool   `json:"served"`
+	Storage bool   `json:"storage"`
+	// Schema is the OpenAPI v3 schema of the version. It is nil if the
+	// version has none.
+	Schema map[string]interface{} `json:"schema,omitempty"`
+}
+
+// Version returns the version of the given name, or nil if there is none.
+//
+//	(.Capabilities.CRDs.Get "certificates.cert-manager.io").Version "v1"
+func (c *CRD) Version(name string) *CRDVersion {
+	if c == nil {
+		return nil
+	}
+	for i := range c.Versions {
+		if c.Versions[i].Name == name {
+			return &c.Versions[i]
+		}
+	}
+	return nil
+}
+
+// HasVersion returns true if the version of the given name is served.
+func (c *CRD) HasVersion(name string) bool {
+	v := c.Version(name)
+	return v != nil && v.Served
+}
+
+// StorageVersion returns the name of the version the resources are stored as.
+func (c *CRD) StorageVersion() string {
+	if c == nil {
+		return ""
+	}
+	for _, v := range c.Versions {
+		if v.Storage {
+			return v.Name
+		}
+	}
+	return ""
+}
+
+// CRDs gives access to the CustomResourceDefinitions installed in the cluster.
+// They are listed the first time they are used, so that rendering charts that
+// do not use them does not query the cluster.
+type CRDs struct {
+	once sync.Once
+	list func() []CRD
+	crds map[string]*CRD
+}
+
+// NewCRDs returns the CustomResourceDefinitions listed by the given function.
+// It is called once, the first time they are used. A nil function lists none.
+func NewCRDs(list func() []CRD) *CRDs {
+	return &CRDs{list: list}
+}
+
+func (c *CRDs) load() {
+	c.once.Do(func() {
+		c.crds = map[string]*CRD{}
+		if c.list == nil {
+			return
+		}
+		crds := c.list()
+		for i := range crds {
+			c.crds[crds[i].Name] = &crds[i]
+		}
+	})
+}
+
+// Get returns the CustomResourceDefinition of the given name, or nil if it is
+// not installed.
+//
+//	.Capabilities.CRDs.Get "certificates.cert-manager.io"
+func (c *CRDs) Get(name string) *CRD {
+	if c == nil {
+		return nil
+	}
+	c.load()
+	return c.crds[name]
+}
+
+// Has returns true if the CustomResourceDefinition of the given name is
+// installed.
+func (c *CRDs) Has(name string) bool {
+	return c.Get(name) != nil
+}
+
+// List returns all the CustomResourceDefinitions, sorted by name.
+func (c *CRDs) List() []CRD {
+	if c == nil {
+		return nil
+	}
+	c.load()
+	crds := make([]CRD, 0, len(c.crds))
+	for _, crd := range c.crds {
+		crds = append(crds, *crd)
+	}
+	sort.Slice(crds, func(i, j int) bool { return crds[i].Name < crds[j].Name })
+	return crds
+}
+
 func allKnownVersions() VersionSet {
 	// We should register the built in extension APIs as well so CRDs are
 	// supported in the default version set. This has caused problems with `helm
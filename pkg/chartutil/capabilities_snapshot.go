@@ -0,0 +1,118 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// CapabilitiesSnapshot records the capabilities of a cluster, so that charts
+// can be rendered elsewhere exactly as they would be in that cluster.
+type CapabilitiesSnapshot struct {
+	// HelmVersion is the version of Helm that took the snapshot. It is only
+	// informative: charts are rendered with the version of Helm in use.
+	HelmVersion string `json:"helmVersion,omitempty"`
+	// KubeVersion is the version of Kubernetes, such as 'v1.20.4'.
+	KubeVersion string `json:"kubeVersion"`
+	// KubeMajor and KubeMinor are the major and minor versions as the
+	// cluster reports them. They are taken from KubeVersion if empty.
+	KubeMajor string `json:"kubeMajor,omitempty"`
+	KubeMinor string `json:"kubeMinor,omitempty"`
+	// APIVersions are the API versions and the resources they serve, such
+	// as 'apps/v1' and 'apps/v1/Deployment'.
+	APIVersions VersionSet `json:"apiVersions"`
+	// CRDs are the CustomResourceDefinitions installed in the cluster.
+	CRDs []CRD `json:"crds,omitempty"`
+}
+
+// NewCapabilitiesSnapshot takes a snapshot of the given capabilities. The
+// CustomResourceDefinitions are listed if they have not been yet.
+func NewCapabilitiesSnapshot(caps *Capabilities) *CapabilitiesSnapshot {
+	apiVersions := make(VersionSet, len(caps.APIVersions))
+	copy(apiVersions, caps.APIVersions)
+	sort.Strings(apiVersions)
+
+	return &CapabilitiesSnapshot{
+		HelmVersion: caps.HelmVersion.Version,
+		KubeVersion: caps.KubeVersion.Version,
+		KubeMajor:   caps.KubeVersion.Major,
+		KubeMinor:   caps.KubeVersion.Minor,
+		APIVersions: apiVersions,
+		CRDs:        caps.CRDs.List(),
+	}
+}
+
+// Capabilities returns the capabilities recorded by the snapshot, along with
+// the version of Helm in use.
+func (s *CapabilitiesSnapshot) Capabilities() *Capabilities {
+	crds := s.CRDs
+	return &Capabilities{
+		KubeVersion: KubeVersion{
+			Version: s.KubeVersion,
+			Major:   s.KubeMajor,
+			Minor:   s.KubeMinor,
+		},
+		APIVersions: s.APIVersions,
+		HelmVersion: DefaultCapabilities.HelmVersion,
+		CRDs:        NewCRDs(func() []CRD { return crds }),
+	}
+}
+
+// ReadCapabilitiesSnapshot parses a capabilities snapshot in YAML or JSON.
+func ReadCapabilitiesSnapshot(data []byte) (*CapabilitiesSnapshot, error) {
+	s := &CapabilitiesSnapshot{}
+	if err := yaml.UnmarshalStrict(data, s); err != nil {
+		return nil, errors.Wrap(err, "invalid capabilities snapshot")
+	}
+	if s.KubeVersion == "" {
+		return nil, errors.New("invalid capabilities snapshot: kubeVersion is required")
+	}
+	if s.KubeMajor == "" || s.KubeMinor == "" {
+		v, err := semver.NewVersion(s.KubeVersion)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid capabilities snapshot: kubeVersion %q", s.KubeVersion)
+		}
+		if s.KubeMajor == "" {
+			s.KubeMajor = strconv.FormatUint(v.Major(), 10)
+		}
+		if s.KubeMinor == "" {
+			s.KubeMinor = strconv.FormatUint(v.Minor(), 10)
+		}
+	}
+	return s, nil
+}
+
+// LoadCapabilitiesSnapshot reads the capabilities snapshot of the given file.
+func LoadCapabilitiesSnapshot(filename string) (*CapabilitiesSnapshot, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	s, err := ReadCapabilitiesSnapshot(data)
+	return s, errors.Wrap(err, filename)
+}
+
+// Marshal returns the snapshot in YAML.
+func (s *CapabilitiesSnapshot) Marshal() ([]byte, error) {
+	return yaml.Marshal(s)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"testing"
+)
+
+func TestCapabilitiesSnapshotRoundTrip(t *testing.T) {
+	caps := &Capabilities{
+		KubeVersion: KubeVersion{Version: "v1.20.4-gke.1", Major: "1", Minor: "20+"},
+		APIVersions: VersionSet{"v1", "apps/v1/Deployment", "apps/v1"},
+		CRDs: NewCRDs(func() []CRD {
+			return []CRD{
+				{Name: "widgets.example.com", Group: "example.com", Kind: "Widget", Scope: "Namespaced",
+					Versions: []CRDVersion{{Name: "v1", Served: true, Storage: true}}},
+				{Name: "certificates.cert-manager.io", Group: "cert-manager.io", Kind: "Certificate", Scope: "Namespaced"},
+			}
+		}),
+	}
+
+	data, err := NewCapabilitiesSnapshot(caps).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := ReadCapabilitiesSnapshot(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := snapshot.APIVersions; len(got) != 3 || got[0] != "apps/v1" || got[2] != "v1" {
+		t.Errorf("Expected sorted API versions, got %v", got)
+	}
+	if len(snapshot.CRDs) != 2 || snapshot.CRDs[0].Name != "certificates.cert-manager.io" {
+		t.Errorf("Expected CRDs sorted by name, got %v", snapshot.CRDs)
+	}
+
+	replayed := snapshot.Capabilities()
+	if replayed.KubeVersion != caps.KubeVersion {
+		t.Errorf("Expected KubeVersion %v, got %v", caps.KubeVersion, replayed.KubeVersion)
+	}
+	if !replayed.APIVersions.Has("apps/v1/Deployment") {
+		t.Error("Expected to find apps/v1/Deployment")
+	}
+	if !replayed.CRDs.Get("widgets.example.com").HasVersion("v1") {
+		t.Error("Expected widgets.example.com to serve v1")
+	}
+	if replayed.HelmVersion != DefaultCapabilities.HelmVersion {
+		t.Errorf("Expected the version of Helm in use, got %v", replayed.HelmVersion)
+	}
+}
+
+func TestReadCapabilitiesSnapshot(t *testing.T) {
+	snapshot, err := ReadCapabilitiesSnapshot([]byte("kubeVersion: v1.19.3\napiVersions: [v1]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.KubeMajor != "1" || snapshot.KubeMinor != "19" {
+		t.Errorf("Expected major and minor versions 1 and 19, got %q and %q", snapshot.KubeMajor, snapshot.KubeMinor)
+	}
+
+	for _, data := range []string{
+		"apiVersions: [v1]\n",
+		"kubeVersion: latest\n",
+		"kubeVersion: v1.19.3\nunknown: true\n",
+	} {
+		if _, err := ReadCapabilitiesSnapshot([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %q", data)
+		}
+	}
+}
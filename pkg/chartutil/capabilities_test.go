@@ -66,3 +66,45 @@ func TestDefaultCapabilitiesHelmVersion(t *testing.T) {
 		t.Errorf("Expected default HelmVersion to be v3.4, got %q", hv.Version)
 	}
 }
+
+func TestCRDs(t *testing.T) {
+	calls := 0
+	crds := NewCRDs(func() []CRD {
+		calls++
+		return []CRD{{
+			Name: "certificates.cert-manager.io",
+			Versions: []CRDVersion{
+				{Name: "v1alpha2", Served: false},
+				{Name: "v1", Served: true, Storage: true},
+			},
+		}}
+	})
+	if calls != 0 {
+		t.Error("Expected the CRDs not to be listed before they are used")
+	}
+
+	crd := crds.Get("certificates.cert-manager.io")
+	if crd == nil {
+		t.Fatal("Expected to find certificates.cert-manager.io")
+	}
+	if !crd.HasVersion("v1") || crd.HasVersion("v1alpha2") || crd.HasVersion("v2") {
+		t.Error("Expected only v1 to be served")
+	}
+	if v := crd.StorageVersion(); v != "v1" {
+		t.Errorf("Expected the storage version to be v1, got %q", v)
+	}
+	if crds.Has("issuers.cert-manager.io") {
+		t.Error("Expected issuers.cert-manager.io not to be installed")
+	}
+	if calls != 1 {
+		t.Errorf("Expected the CRDs to be listed once, got %d", calls)
+	}
+
+	var missing *CRD
+	if missing.HasVersion("v1") || missing.StorageVersion() != "" {
+		t.Error("Expected a missing CRD to have no versions")
+	}
+	if DefaultCapabilities.CRDs.Has("certificates.cert-manager.io") {
+		t.Error("Expected the default capabilities to have no CRDs")
+	}
+}
@@ -18,6 +18,7 @@ package chartutil
 
 import (
 	"log"
+	"path"
 
 	"github.com/mitchellh/copystructure"
 	"github.com/pkg/errors"
@@ -68,11 +69,18 @@ func coalesceDeps(chrt *chart.Chart, dest map[string]interface{}) (map[string]in
 		if dv, ok := dest[subchart.Name()]; ok {
 			dvmap := dv.(map[string]interface{})
 
+			// The values files of the dependency have a lower precedence
+			// than the values of the parent chart already in dvmap.
+			fileVals, err := dependencyValues(chrt, subchart.Name())
+			if err != nil {
+				return dest, err
+			}
+			CoalesceTables(dvmap, fileVals)
+
 			// Get globals out of dest and merge them into dvmap.
 			coalesceGlobals(dvmap, dest)
 
 			// Now coalesce the rest of the values.
-			var err error
 			dest[subchart.Name()], err = coalesce(subchart, dvmap)
 			if err != nil {
 				return dest, err
@@ -82,6 +90,47 @@ func coalesceDeps(chrt *chart.Chart, dest map[string]interface{}) (map[string]in
 	return dest, nil
 }
 
+// dependencyValues reads the values files the chart lists for its dependency
+// of the given name or alias, later files taking precedence.
+func dependencyValues(chrt *chart.Chart, name string) (map[string]interface{}, error) {
+	vals := make(map[string]interface{})
+	if chrt.Metadata == nil {
+		return vals, nil
+	}
+	for _, dep := range chrt.Metadata.Dependencies {
+		key := dep.Name
+		if dep.Alias != "" {
+			key = dep.Alias
+		}
+		if key != name {
+			continue
+		}
+		for _, file := range dep.ValuesFiles {
+			data, ok := chartFile(chrt, file)
+			if !ok {
+				return nil, errors.Errorf("values file %q of dependency %s not found in chart %s", file, name, chrt.Name())
+			}
+			fv, err := ReadValues(data)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot read values file %q of dependency %s", file, name)
+			}
+			vals = CoalesceTables(fv, vals)
+		}
+	}
+	return vals, nil
+}
+
+// chartFile returns the data of a file of the chart.
+func chartFile(chrt *chart.Chart, name string) ([]byte, bool) {
+	name = path.Clean(name)
+	for _, f := range chrt.Files {
+		if f.Name == name {
+			return f.Data, true
+		}
+	}
+	return nil, false
+}
+
 // coalesceGlobals copies the globals out of src and merges them into dest.
 //
 // For convenience, returns dest.
@@ -175,6 +224,26 @@ func coalesceValues(c *chart.Chart, v map[string]interface{}) {
 	}
 }
 
+// MergeTables returns a copy of dst with src merged over it, recursing into
+// the tables present in both. Unlike CoalesceTables, src is authoritative, and
+// neither map is modified.
+func MergeTables(dst, src map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(dst))
+	for k, v := range dst {
+		out[k] = v
+	}
+	for k, v := range src {
+		if v, ok := v.(map[string]interface{}); ok {
+			if dv, ok := out[k].(map[string]interface{}); ok {
+				out[k] = MergeTables(dv, v)
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
 // CoalesceTables merges a source map into a destination map.
 //
 // dest is considered authoritative.
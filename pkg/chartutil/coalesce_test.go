@@ -18,6 +18,7 @@ package chartutil
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -306,3 +307,108 @@ func TestCoalesceTables(t *testing.T) {
 		t.Errorf("Expected hole string, got %v", dst2["boat"])
 	}
 }
+
+func TestCoalesceDependencyValuesFiles(t *testing.T) {
+	is := assert.New(t)
+
+	c := withDeps(&chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "umbrella",
+			Dependencies: []*chart.Dependency{
+				{Name: "redis", ValuesFiles: []string{"values/redis.yaml", "values/redis-ha.yaml"}},
+				{Name: "redis", Alias: "cache", ValuesFiles: []string{"./values/cache.yaml"}},
+			},
+		},
+		Values: map[string]interface{}{
+			"redis": map[string]interface{}{"image": "parent"},
+		},
+		Files: []*chart.File{
+			{Name: "values/redis.yaml", Data: []byte("image: file\nreplicas: 1\npassword: file\n")},
+			{Name: "values/redis-ha.yaml", Data: []byte("replicas: 3\nsentinel: {enabled: true}\n")},
+			{Name: "values/cache.yaml", Data: []byte("replicas: 2\n")},
+		},
+	},
+		&chart.Chart{
+			Metadata: &chart.Metadata{Name: "redis"},
+			Values:   map[string]interface{}{"image": "redis", "replicas": 0, "password": "", "port": 6379},
+		},
+		&chart.Chart{
+			Metadata: &chart.Metadata{Name: "cache"},
+			Values:   map[string]interface{}{"replicas": 0},
+		},
+	)
+
+	v, err := CoalesceValues(c, map[string]interface{}{
+		"redis": map[string]interface{}{"password": "user"},
+	})
+	is.NoError(err)
+
+	redis := v["redis"].(map[string]interface{})
+	is.Equal("parent", redis["image"], "values.yaml should override the values files")
+	is.Equal("user", redis["password"], "user values should override the values files")
+	is.Equal(float64(3), redis["replicas"], "later values files should override earlier ones")
+	is.Equal(map[string]interface{}{"enabled": true}, redis["sentinel"])
+	is.Equal(6379, redis["port"], "the values of the dependency should be kept")
+
+	cache := v["cache"].(map[string]interface{})
+	is.Equal(float64(2), cache["replicas"], "values files should apply to aliases")
+
+	c.Files = nil
+	_, err = CoalesceValues(c, nil)
+	is.EqualError(err, `values file "values/redis.yaml" of dependency redis not found in chart umbrella`)
+}
+
+func TestMergeTables(t *testing.T) {
+	nestedMap := map[string]interface{}{
+		"foo": "bar",
+		"baz": map[string]string{
+			"cool": "stuff",
+		},
+	}
+	anotherNestedMap := map[string]interface{}{
+		"foo": "bar",
+		"baz": map[string]string{
+			"cool":    "things",
+			"awesome": "stuff",
+		},
+	}
+	flatMap := map[string]interface{}{
+		"foo": "bar",
+		"baz": "stuff",
+	}
+	anotherFlatMap := map[string]interface{}{
+		"testing": "fun",
+	}
+
+	testMap := MergeTables(flatMap, nestedMap)
+	equal := reflect.DeepEqual(testMap, nestedMap)
+	if !equal {
+		t.Errorf("Expected a nested map to overwrite a flat value. Expected: %v, got %v", nestedMap, testMap)
+	}
+
+	testMap = MergeTables(nestedMap, flatMap)
+	equal = reflect.DeepEqual(testMap, flatMap)
+	if !equal {
+		t.Errorf("Expected a flat value to overwrite a map. Expected: %v, got %v", flatMap, testMap)
+	}
+
+	testMap = MergeTables(nestedMap, anotherNestedMap)
+	equal = reflect.DeepEqual(testMap, anotherNestedMap)
+	if !equal {
+		t.Errorf("Expected a nested map to overwrite another nested map. Expected: %v, got %v", anotherNestedMap, testMap)
+	}
+
+	testMap = MergeTables(anotherFlatMap, anotherNestedMap)
+	expectedMap := map[string]interface{}{
+		"testing": "fun",
+		"foo":     "bar",
+		"baz": map[string]string{
+			"cool":    "things",
+			"awesome": "stuff",
+		},
+	}
+	equal = reflect.DeepEqual(testMap, expectedMap)
+	if !equal {
+		t.Errorf("Expected a map with different keys to merge properly with another map. Expected: %v, got %v", expectedMap, testMap)
+	}
+}
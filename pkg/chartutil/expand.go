@@ -26,6 +26,7 @@ import (
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
 
+	"helm.sh/helm/v3/internal/fileutil"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 )
@@ -68,11 +69,11 @@ func Expand(dir string, r io.Reader) error {
 
 		// Make sure the necessary subdirs get created.
 		basedir := filepath.Dir(outpath)
-		if err := os.MkdirAll(basedir, 0755); err != nil {
+		if err := os.MkdirAll(fileutil.LongPath(basedir), 0755); err != nil {
 			return err
 		}
 
-		if err := ioutil.WriteFile(outpath, file.Data, 0644); err != nil {
+		if err := ioutil.WriteFile(fileutil.LongPath(outpath), file.Data, 0644); err != nil {
 			return err
 		}
 	}
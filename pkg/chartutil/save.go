@@ -21,6 +21,7 @@ import (
 	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
@@ -28,7 +29,9 @@ import (
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
 
+	"helm.sh/helm/v3/internal/fileutil"
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
 )
 
 var headerBytes = []byte("+aHR0cHM6Ly95b3V0dS5iZS96OVV6MWljandyTQo=")
@@ -73,7 +76,11 @@ func SaveDir(c *chart.Chart, dest string) error {
 	// Save templates and files
 	for _, o := range [][]*chart.File{c.Templates, c.Files} {
 		for _, f := range o {
-			n := filepath.Join(outdir, f.Name)
+			name, err := loader.CleanPath(f.Name)
+			if err != nil {
+				return err
+			}
+			n := filepath.Join(outdir, filepath.FromSlash(name))
 			if err := writeFile(n, f.Data); err != nil {
 				return err
 			}
@@ -119,36 +126,51 @@ func Save(c *chart.Chart, outDir string) (string, error) {
 		return "", errors.Errorf("is not a directory: %s", dir)
 	}
 
-	f, err := os.Create(filename)
+	f, err := os.Create(fileutil.LongPath(filename))
 	if err != nil {
 		return "", err
 	}
+	err = writeArchive(f, c, time.Now())
+	f.Close()
+	if err != nil {
+		os.Remove(filename)
+	}
+	return filename, err
+}
 
+// Archive writes a chart as a gzipped tar archive, as Save does, to out.
+//
+// The entries of the archive are timestamped with modTime, or with the current
+// time if it is zero. Archiving the same chart with the same modTime gives the
+// same archive, byte for byte, which makes packaging reproducible.
+func Archive(c *chart.Chart, out io.Writer, modTime time.Time) error {
+	if err := c.Validate(); err != nil {
+		return errors.Wrap(err, "chart validation")
+	}
+	if modTime.IsZero() {
+		modTime = time.Now()
+	}
+	return writeArchive(out, c, modTime)
+}
+
+func writeArchive(out io.Writer, c *chart.Chart, modTime time.Time) error {
 	// Wrap in gzip writer
-	zipper := gzip.NewWriter(f)
+	zipper := gzip.NewWriter(out)
 	zipper.Header.Extra = headerBytes
 	zipper.Header.Comment = "Helm"
 
 	// Wrap in tar writer
 	twriter := tar.NewWriter(zipper)
-	rollback := false
-	defer func() {
-		twriter.Close()
-		zipper.Close()
-		f.Close()
-		if rollback {
-			os.Remove(filename)
-		}
-	}()
-
-	if err := writeTarContents(twriter, c, ""); err != nil {
-		rollback = true
-		return filename, err
+	if err := writeTarContents(twriter, c, "", modTime); err != nil {
+		return err
 	}
-	return filename, nil
+	if err := twriter.Close(); err != nil {
+		return err
+	}
+	return zipper.Close()
 }
 
-func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
+func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string, modTime time.Time) error {
 	base := filepath.Join(prefix, c.Name())
 
 	// Pull out the dependencies of a v1 Chart, since there's no way
@@ -165,7 +187,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	if err != nil {
 		return err
 	}
-	if err := writeToTar(out, filepath.Join(base, ChartfileName), cdata); err != nil {
+	if err := writeToTar(out, filepath.Join(base, ChartfileName), cdata, modTime); err != nil {
 		return err
 	}
 
@@ -177,7 +199,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 			if err != nil {
 				return err
 			}
-			if err := writeToTar(out, filepath.Join(base, "Chart.lock"), ldata); err != nil {
+			if err := writeToTar(out, filepath.Join(base, "Chart.lock"), ldata, modTime); err != nil {
 				return err
 			}
 		}
@@ -186,7 +208,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	// Save values.yaml
 	for _, f := range c.Raw {
 		if f.Name == ValuesfileName {
-			if err := writeToTar(out, filepath.Join(base, ValuesfileName), f.Data); err != nil {
+			if err := writeToTar(out, filepath.Join(base, ValuesfileName), f.Data, modTime); err != nil {
 				return err
 			}
 		}
@@ -197,30 +219,28 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 		if !json.Valid(c.Schema) {
 			return errors.New("Invalid JSON in " + SchemafileName)
 		}
-		if err := writeToTar(out, filepath.Join(base, SchemafileName), c.Schema); err != nil {
-			return err
-		}
-	}
-
-	// Save templates
-	for _, f := range c.Templates {
-		n := filepath.Join(base, f.Name)
-		if err := writeToTar(out, n, f.Data); err != nil {
+		if err := writeToTar(out, filepath.Join(base, SchemafileName), c.Schema, modTime); err != nil {
 			return err
 		}
 	}
 
-	// Save files
-	for _, f := range c.Files {
-		n := filepath.Join(base, f.Name)
-		if err := writeToTar(out, n, f.Data); err != nil {
-			return err
+	// Save templates and files
+	for _, o := range [][]*chart.File{c.Templates, c.Files} {
+		for _, f := range o {
+			name, err := loader.CleanPath(f.Name)
+			if err != nil {
+				return err
+			}
+			n := filepath.Join(base, filepath.FromSlash(name))
+			if err := writeToTar(out, n, f.Data, modTime); err != nil {
+				return err
+			}
 		}
 	}
 
 	// Save dependencies
 	for _, dep := range c.Dependencies() {
-		if err := writeTarContents(out, dep, filepath.Join(base, ChartsDir)); err != nil {
+		if err := writeTarContents(out, dep, filepath.Join(base, ChartsDir), modTime); err != nil {
 			return err
 		}
 	}
@@ -228,13 +248,13 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 }
 
 // writeToTar writes a single file to a tar archive.
-func writeToTar(out *tar.Writer, name string, body []byte) error {
+func writeToTar(out *tar.Writer, name string, body []byte, modTime time.Time) error {
 	// TODO: Do we need to create dummy parent directory names if none exist?
 	h := &tar.Header{
 		Name:    filepath.ToSlash(name),
 		Mode:    0644,
 		Size:    int64(len(body)),
-		ModTime: time.Now(),
+		ModTime: modTime,
 	}
 	if err := out.WriteHeader(h); err != nil {
 		return err
@@ -168,6 +168,65 @@ func TestSavePreservesTimestamps(t *testing.T) {
 	}
 }
 
+func TestArchiveReproducible(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "ahab",
+			Version:    "1.2.3",
+		},
+		Templates: []*chart.File{
+			{Name: "templates/whale.yaml", Data: []byte("moby: dick")},
+		},
+		Files: []*chart.File{
+			{Name: "scheherazade/shahryar.txt", Data: []byte("1,001 Nights")},
+		},
+	}
+	modTime := time.Unix(1600000000, 0)
+
+	var first, second bytes.Buffer
+	if err := Archive(c, &first, modTime); err != nil {
+		t.Fatalf("Failed to archive: %s", err)
+	}
+	if err := Archive(c, &second, modTime); err != nil {
+		t.Fatalf("Failed to archive: %s", err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Error("Expected archives of the same chart and time to be identical")
+	}
+
+	c2, err := loader.LoadArchive(bytes.NewReader(first.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c2.Name() != "ahab" || len(c2.Templates) != 1 || len(c2.Files) != 1 {
+		t.Errorf("Unexpected chart loaded from the archive: %+v", c2)
+	}
+
+	tr := tar.NewReader(mustGunzip(t, first.Bytes()))
+	for {
+		hd, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !hd.ModTime.Equal(modTime) {
+			t.Errorf("Expected %s to be stamped with %s, got %s", hd.Name, modTime, hd.ModTime)
+		}
+	}
+}
+
+func mustGunzip(t *testing.T, data []byte) io.Reader {
+	t.Helper()
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
 // We could refactor `load.go` to use this `retrieveAllHeadersFromTar` function
 // as well, so we are not duplicating components of the code which iterate
 // through the tar.
@@ -244,3 +303,45 @@ func TestSaveDir(t *testing.T) {
 		t.Fatal("Files data did not match")
 	}
 }
+
+func TestSaveNormalizesFileNames(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "helm-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "ahab",
+			Version:    "1.2.3",
+		},
+		Templates: []*chart.File{
+			{Name: "templates\\nested\\thing.yaml", Data: []byte("abc: {{ .Values.abc }}")},
+		},
+	}
+
+	where, err := Save(c, tmp)
+	if err != nil {
+		t.Fatalf("Failed to save: %s", err)
+	}
+	c2, err := loader.LoadFile(where)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c2.Templates) != 1 || c2.Templates[0].Name != "templates/nested/thing.yaml" {
+		t.Fatalf("Expected the template to be named templates/nested/thing.yaml, got %v", c2.Templates)
+	}
+
+	c.Templates = []*chart.File{{Name: "templates/../../../escape.yaml", Data: []byte("escape")}}
+	if _, err := Save(c, tmp); err == nil {
+		t.Fatal("Expected a template outside of the chart directory not to be saved")
+	}
+	if err := SaveDir(c, tmp); err == nil {
+		t.Fatal("Expected a template outside of the chart directory not to be written")
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "escape.yaml")); !os.IsNotExist(err) {
+		t.Fatal("Expected no file to be written outside of the chart directory")
+	}
+}
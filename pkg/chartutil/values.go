@@ -129,6 +129,43 @@ type ReleaseOptions struct {
 	Revision  int
 	IsUpgrade bool
 	IsInstall bool
+
+	// NamespaceMetadata is the metadata of the target namespace, available
+	// to templates as .Release.NamespaceMetadata.
+	NamespaceMetadata NamespaceMetadata
+
+	// Features are the features of the Helm client enabled for the render,
+	// available to templates as .Helm.Features along with the built-in ones.
+	Features Features
+}
+
+// Built-in features of the Helm client, which templates can always check.
+const (
+	// FeatureDryRun is enabled when rendering for a dry run.
+	FeatureDryRun = "dryRun"
+	// FeatureServerDryRun is enabled when the rendered resources are sent to
+	// the API server in dry-run mode.
+	FeatureServerDryRun = "serverDryRun"
+	// FeatureClientOnly is enabled when rendering without a cluster, as
+	// helm template does.
+	FeatureClientOnly = "clientOnly"
+)
+
+// Features maps the names of features of the Helm client to whether they are
+// enabled.
+type Features map[string]bool
+
+// BuiltinFeatures returns the names of the built-in features.
+func BuiltinFeatures() []string {
+	return []string{FeatureClientOnly, FeatureDryRun, FeatureServerDryRun}
+}
+
+// NamespaceMetadata holds the labels and annotations of the namespace a
+// release is rendered for. They are empty when no cluster is available or the
+// namespace does not exist yet.
+type NamespaceMetadata struct {
+	Labels      map[string]string
+	Annotations map[string]string
 }
 
 // ToRenderValues composes the struct from the data coming from the Releases, Charts and Values files
@@ -148,6 +185,11 @@ func ToRenderValues(chrt *chart.Chart, chrtVals map[string]interface{}, options
 			"IsInstall": options.IsInstall,
 			"Revision":  options.Revision,
 			"Service":   "Helm",
+
+			"NamespaceMetadata": options.NamespaceMetadata,
+		},
+		"Helm": map[string]interface{}{
+			"Features": renderFeatures(options.Features),
 		},
 	}
 
@@ -165,6 +207,19 @@ func ToRenderValues(chrt *chart.Chart, chrtVals map[string]interface{}, options
 	return top, nil
 }
 
+// renderFeatures returns the features given with the built-in ones that are
+// not, disabled, so that templates can check them even with missingkey=error.
+func renderFeatures(features Features) Features {
+	out := Features{}
+	for _, name := range BuiltinFeatures() {
+		out[name] = false
+	}
+	for name, enabled := range features {
+		out[name] = enabled
+	}
+	return out
+}
+
 // istable is a special-purpose function to see if the present thing matches the definition of a YAML table.
 func istable(v interface{}) bool {
 	_, ok := v.(map[string]interface{})
@@ -0,0 +1,294 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// ValuesSource is a set of user supplied values, named after where they come
+// from, such as a values file or the --set flag.
+type ValuesSource struct {
+	Name   string
+	Values map[string]interface{}
+}
+
+// DebugValue is a value annotated with the source that supplied it.
+type DebugValue struct {
+	// Key is the dotted path of the value
+	Key   string
+	Value interface{}
+	// Source describes where the value comes from
+	Source string
+}
+
+// ValuesDebug describes the values a chart or subchart receives after the
+// values have been coalesced.
+type ValuesDebug struct {
+	// Path is the path of the subchart from the top-level chart
+	Path string
+	// Values is the scope of the subchart, without its globals
+	Values []DebugValue
+	// Globals are the global values of the subchart
+	Globals []DebugValue
+	// Exports are the values the subchart exports to its parent through the
+	// import-values of the dependency, keyed by where they land in the parent
+	Exports []DebugValue
+}
+
+// DebugValues returns the values the subchart at the given path receives when
+// the chart is rendered with the given user supplied values.
+//
+// The path is made of the names, or aliases, of the subcharts separated by
+// '/', such as 'subchart1/subcharta'. 'charts' segments are ignored, so paths
+// of the charts directory can be used as well. An empty path designates the
+// chart itself.
+//
+// Sources are the user supplied values in increasing order of precedence.
+// They are used to annotate each value with the source that supplied it, so
+// their merge is expected to be vals.
+//
+// The chart's dependencies are processed, so the chart is modified.
+func DebugValues(ch *chart.Chart, vals map[string]interface{}, sources []ValuesSource, path string) (*ValuesDebug, error) {
+	if err := ProcessDependencies(ch, vals); err != nil {
+		return nil, err
+	}
+	final, err := CoalesceValues(ch, vals)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the chain of charts from the top-level chart to the subchart
+	chain := []*chart.Chart{ch}
+	var names []string
+	for _, name := range strings.Split(path, "/") {
+		if name == "" || name == "." || name == "charts" {
+			continue
+		}
+		sub := dependencyNamed(chain[len(chain)-1], name)
+		if sub == nil {
+			return nil, errors.Errorf("subchart %s of %s is disabled or does not exist", name, chain[len(chain)-1].Name())
+		}
+		chain = append(chain, sub)
+		names = append(names, name)
+	}
+
+	scope, ok := lookupValue(final, names)
+	if !ok {
+		return nil, errors.Errorf("no values for subchart %s", path)
+	}
+	scopeMap, _ := scope.(map[string]interface{})
+
+	layers := valuesLayers(chain, names, sources)
+	source := func(key []string, v interface{}, global bool) string {
+		for _, l := range layers {
+			if lv, ok := l.lookup(key, global); ok && reflect.DeepEqual(lv, v) {
+				return l.name
+			}
+		}
+		return "import-values"
+	}
+
+	debug := &ValuesDebug{Path: strings.Join(names, "/")}
+	leaves := map[string]interface{}{}
+	flattenValues("", scopeMap, leaves)
+	for key, v := range leaves {
+		if key == GlobalKey || strings.HasPrefix(key, GlobalKey+".") {
+			continue
+		}
+		debug.Values = append(debug.Values, DebugValue{Key: key, Value: v, Source: source(parsePath(key), v, false)})
+	}
+	if globals, ok := scopeMap[GlobalKey].(map[string]interface{}); ok {
+		leaves := map[string]interface{}{}
+		flattenValues("", globals, leaves)
+		for key, v := range leaves {
+			debug.Globals = append(debug.Globals, DebugValue{Key: key, Value: v, Source: source(parsePath(key), v, true)})
+		}
+	}
+	if len(chain) > 1 {
+		for _, imp := range importValues(chain[len(chain)-2], chain[len(chain)-1].Name()) {
+			child, ok := lookupValue(scopeMap, parsePath(imp[0]))
+			if !ok {
+				continue
+			}
+			leaves := map[string]interface{}{}
+			if m, ok := child.(map[string]interface{}); ok {
+				flattenValues("", m, leaves)
+			} else {
+				leaves[""] = child
+			}
+			for key, v := range leaves {
+				childKey := joinKey(imp[0], key)
+				debug.Exports = append(debug.Exports, DebugValue{
+					Key:    joinKey(imp[1], key),
+					Value:  v,
+					Source: fmt.Sprintf("%s (from %s)", source(parsePath(childKey), v, false), childKey),
+				})
+			}
+		}
+	}
+
+	for _, values := range [][]DebugValue{debug.Values, debug.Globals, debug.Exports} {
+		sort.Slice(values, func(i, j int) bool { return values[i].Key < values[j].Key })
+	}
+	return debug, nil
+}
+
+// String renders the values with their sources.
+func (d *ValuesDebug) String() string {
+	var b strings.Builder
+	path := d.Path
+	if path == "" {
+		path = "."
+	}
+	fmt.Fprintf(&b, "SUBCHART: %s\n", path)
+	for _, section := range []struct {
+		title  string
+		values []DebugValue
+	}{
+		{"VALUES", d.Values},
+		{"GLOBALS", d.Globals},
+		{"EXPORTS", d.Exports},
+	} {
+		fmt.Fprintf(&b, "\n%s:\n", section.title)
+		if len(section.values) == 0 {
+			b.WriteString("(none)\n")
+			continue
+		}
+		for _, v := range section.values {
+			fmt.Fprintf(&b, "%s: %s  # %s\n", v.Key, jsonValue(v.Value), v.Source)
+		}
+	}
+	return b.String()
+}
+
+// valuesLayer is a set of values that may supply the values of a subchart.
+type valuesLayer struct {
+	name   string
+	values map[string]interface{}
+	// prefix is the path of the scope of the subchart in the values
+	prefix []string
+}
+
+// valuesLayers returns the sets of values that may supply the values of the
+// last chart of the chain, in decreasing order of precedence: the user
+// supplied values, then the values.yaml files from the top-level chart down.
+// names are the names of the subcharts of the chain.
+func valuesLayers(chain []*chart.Chart, names []string, sources []ValuesSource) []valuesLayer {
+	var layers []valuesLayer
+	for i := len(sources) - 1; i >= 0; i-- {
+		layers = append(layers, valuesLayer{sources[i].Name, sources[i].Values, names})
+	}
+	for i, c := range chain {
+		layers = append(layers, valuesLayer{
+			name:   fmt.Sprintf("values.yaml of %s", strings.Join(append([]string{chain[0].Name()}, names[:i]...), "/")),
+			values: chartDefaults(c),
+			prefix: names[i:],
+		})
+	}
+	return layers
+}
+
+// lookup returns the value of the key in the scope of the subchart, or in
+// the globals of the set of values if global is set.
+func (l valuesLayer) lookup(key []string, global bool) (interface{}, bool) {
+	p := append(append([]string{}, l.prefix...), key...)
+	if global {
+		p = append([]string{GlobalKey}, key...)
+	}
+	return lookupValue(l.values, p)
+}
+
+// dependencyNamed returns the enabled subchart of a chart with the given
+// name or alias, if any.
+func dependencyNamed(c *chart.Chart, name string) *chart.Chart {
+	for _, d := range c.Dependencies() {
+		if d.Name() == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// chartDefaults returns the values of the values.yaml file of a chart, as
+// the values of the chart are modified when its dependencies are processed.
+func chartDefaults(c *chart.Chart) map[string]interface{} {
+	for _, f := range c.Raw {
+		if f.Name == ValuesfileName {
+			if vals, err := ReadValues(f.Data); err == nil {
+				return vals
+			}
+		}
+	}
+	return c.Values
+}
+
+// importValues returns the pairs of child and parent keys of the values the
+// parent imports from the named subchart.
+func importValues(parent *chart.Chart, name string) [][2]string {
+	var out [][2]string
+	for _, r := range parent.Metadata.Dependencies {
+		if r.Alias != name && (r.Alias != "" || r.Name != name) {
+			continue
+		}
+		for _, riv := range r.ImportValues {
+			switch iv := riv.(type) {
+			case map[string]string:
+				out = append(out, [2]string{iv["child"], iv["parent"]})
+			case map[string]interface{}:
+				child, _ := iv["child"].(string)
+				parent, _ := iv["parent"].(string)
+				out = append(out, [2]string{child, parent})
+			case string:
+				out = append(out, [2]string{"exports." + iv, "."})
+			}
+		}
+	}
+	return out
+}
+
+func lookupValue(values map[string]interface{}, path []string) (interface{}, bool) {
+	var v interface{} = values
+	for _, k := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[k]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func joinKey(prefix, key string) string {
+	prefix = strings.Trim(prefix, ".")
+	switch {
+	case prefix == "":
+		return key
+	case key == "":
+		return prefix
+	}
+	return prefix + "." + key
+}
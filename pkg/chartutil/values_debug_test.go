@@ -0,0 +1,101 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func chartWithValues(t *testing.T, name, values string) *chart.Chart {
+	t.Helper()
+	vals, err := ReadValues([]byte(values))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &chart.Chart{
+		Metadata: &chart.Metadata{Name: name, Version: "0.1.0"},
+		Raw:      []*chart.File{{Name: ValuesfileName, Data: []byte(values)}},
+		Values:   vals,
+	}
+}
+
+func TestDebugValues(t *testing.T) {
+	parent := chartWithValues(t, "parent", `
+global:
+  env: prod
+sub:
+  replicas: 2
+`)
+	parent.Metadata.Dependencies = []*chart.Dependency{{Name: "sub", Version: "0.1.0", ImportValues: []interface{}{"data"}}}
+	sub := chartWithValues(t, "sub", `
+replicas: 1
+image: nginx
+global:
+  env: dev
+  region: eu
+exports:
+  data:
+    port: 80
+`)
+	parent.AddDependency(sub)
+
+	sources := []ValuesSource{
+		{Name: "custom.yaml", Values: map[string]interface{}{"sub": map[string]interface{}{"image": "custom"}}},
+		{Name: "--set", Values: map[string]interface{}{"global": map[string]interface{}{"region": "us"}}},
+	}
+	vals := map[string]interface{}{
+		"sub":    map[string]interface{}{"image": "custom"},
+		"global": map[string]interface{}{"region": "us"},
+	}
+
+	debug, err := DebugValues(parent, vals, sources, "charts/sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if debug.Path != "sub" {
+		t.Errorf("expected path sub, got %s", debug.Path)
+	}
+	expectValues := []DebugValue{
+		{Key: "exports.data.port", Value: float64(80), Source: "values.yaml of parent/sub"},
+		{Key: "image", Value: "custom", Source: "custom.yaml"},
+		{Key: "replicas", Value: float64(2), Source: "values.yaml of parent"},
+	}
+	if !reflect.DeepEqual(debug.Values, expectValues) {
+		t.Errorf("expected values %v, got %v", expectValues, debug.Values)
+	}
+	expectGlobals := []DebugValue{
+		{Key: "env", Value: "prod", Source: "values.yaml of parent"},
+		{Key: "region", Value: "us", Source: "--set"},
+	}
+	if !reflect.DeepEqual(debug.Globals, expectGlobals) {
+		t.Errorf("expected globals %v, got %v", expectGlobals, debug.Globals)
+	}
+	expectExports := []DebugValue{
+		{Key: "port", Value: float64(80), Source: "values.yaml of parent/sub (from exports.data.port)"},
+	}
+	if !reflect.DeepEqual(debug.Exports, expectExports) {
+		t.Errorf("expected exports %v, got %v", expectExports, debug.Exports)
+	}
+
+	if _, err := DebugValues(parent, vals, sources, "missing"); err == nil {
+		t.Error("expected an error for a missing subchart")
+	}
+}
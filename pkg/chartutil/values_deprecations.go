@@ -0,0 +1,119 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// ValueDeprecation describes a deprecated value that is set.
+type ValueDeprecation struct {
+	// Chart is the name of the chart whose schema deprecates the value
+	Chart string `json:"chart"`
+	// Key is the dotted path of the value, from the values of the top level
+	// chart
+	Key string `json:"key"`
+	// ReplacedBy is the dotted path of the value replacing it, if any
+	ReplacedBy string `json:"replacedBy,omitempty"`
+	// Message is the deprecation message of the schema, if any
+	Message string `json:"message,omitempty"`
+}
+
+func (d ValueDeprecation) String() string {
+	msg := fmt.Sprintf("value %q of chart %s is deprecated", d.Key, d.Chart)
+	if d.ReplacedBy != "" {
+		msg += fmt.Sprintf(", use %q instead", d.ReplacedBy)
+	}
+	if d.Message != "" {
+		msg += ": " + d.Message
+	}
+	return msg
+}
+
+// DeprecatedValues returns the values that are set although the schema of the
+// chart, or of the subchart they belong to, deprecates them.
+//
+// A property of values.schema.json is deprecated if it has "deprecated": true
+// or a "deprecationMessage". Its "replacedBy" gives the dotted path of the
+// value replacing it, relative to the chart. Only the values given are
+// checked, not the defaults of the charts, so that charts can keep defaults
+// for deprecated values while users migrate.
+func DeprecatedValues(chrt *chart.Chart, values map[string]interface{}) ([]ValueDeprecation, error) {
+	var deprecations []ValueDeprecation
+	var walk func(*chart.Chart, string, map[string]interface{}) error
+	walk = func(c *chart.Chart, prefix string, vals map[string]interface{}) error {
+		if len(c.Schema) > 0 {
+			var schema map[string]interface{}
+			if err := json.Unmarshal(c.Schema, &schema); err != nil {
+				return errors.Wrapf(err, "unable to parse values schema of chart %s", c.Name())
+			}
+			props := map[string]schemaProperty{}
+			schemaProperties("", schema, props)
+
+			var found []ValueDeprecation
+			for key, p := range props {
+				if !p.Deprecated || !hasValue(vals, key) {
+					continue
+				}
+				d := ValueDeprecation{Chart: c.Name(), Key: prefix + key, Message: p.DeprecationMessage}
+				if p.ReplacedBy != "" {
+					d.ReplacedBy = prefix + p.ReplacedBy
+				}
+				found = append(found, d)
+			}
+			sort.Slice(found, func(i, j int) bool { return found[i].Key < found[j].Key })
+			deprecations = append(deprecations, found...)
+		}
+		for _, sub := range c.Dependencies() {
+			subVals, _ := vals[sub.Name()].(map[string]interface{})
+			if err := walk(sub, prefix+sub.Name()+".", subVals); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(chrt, "", values); err != nil {
+		return nil, err
+	}
+	return deprecations, nil
+}
+
+// hasValue tells whether values set the value at a dotted path. Null values
+// are not set, as they delete the defaults.
+func hasValue(values map[string]interface{}, key string) bool {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		v, ok := values[part]
+		if !ok || v == nil {
+			return false
+		}
+		if i == len(parts)-1 {
+			return true
+		}
+		if values, ok = v.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const deprecationsSchema = `{
+  "properties": {
+    "image": {
+      "properties": {
+        "name": {"type": "string", "deprecated": true, "replacedBy": "image.repository"},
+        "repository": {"type": "string"}
+      }
+    },
+    "legacy": {"deprecationMessage": "no longer used"},
+    "replicas": {"type": "integer", "deprecated": true}
+  }
+}`
+
+func TestDeprecatedValues(t *testing.T) {
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "sub"},
+		Schema:   []byte(`{"properties": {"oldPort": {"deprecated": true, "replacedBy": "port"}}}`),
+	}
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "parent"},
+		Values:   map[string]interface{}{"replicas": 1},
+		Schema:   []byte(deprecationsSchema),
+	}
+	c.AddDependency(sub)
+
+	deprecations, err := DeprecatedValues(c, map[string]interface{}{
+		"image":  map[string]interface{}{"name": "nginx", "repository": "nginx"},
+		"legacy": nil,
+		"sub":    map[string]interface{}{"oldPort": 8080},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []ValueDeprecation{
+		{Chart: "parent", Key: "image.name", ReplacedBy: "image.repository"},
+		{Chart: "sub", Key: "sub.oldPort", ReplacedBy: "sub.port"},
+	}
+	if !reflect.DeepEqual(deprecations, expect) {
+		t.Errorf("Expected %v, got %v", expect, deprecations)
+	}
+
+	deprecations, err = DeprecatedValues(c, map[string]interface{}{"legacy": "yes", "image": "nginx"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = []ValueDeprecation{{Chart: "parent", Key: "legacy", Message: "no longer used"}}
+	if !reflect.DeepEqual(deprecations, expect) {
+		t.Errorf("Expected %v, got %v", expect, deprecations)
+	}
+	if got := deprecations[0].String(); got != `value "legacy" of chart parent is deprecated: no longer used` {
+		t.Errorf("Unexpected message %q", got)
+	}
+
+	if _, err := DeprecatedValues(&chart.Chart{Metadata: c.Metadata, Schema: []byte("{")}, nil); err == nil {
+		t.Error("Expected an error for an invalid schema")
+	}
+}
+
+func TestValueDeprecationString(t *testing.T) {
+	d := ValueDeprecation{Chart: "parent", Key: "image.name", ReplacedBy: "image.repository", Message: "use the full repository"}
+	expect := `value "image.name" of chart parent is deprecated, use "image.repository" instead: use the full repository`
+	if got := d.String(); got != expect {
+		t.Errorf("Expected %q, got %q", expect, got)
+	}
+}
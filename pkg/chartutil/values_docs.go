@@ -0,0 +1,319 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// ValueDoc documents a single value of a chart.
+type ValueDoc struct {
+	// Key is the dotted path of the value
+	Key string `json:"key"`
+	// Type is the type of the value: string, int, float, bool, list or object
+	Type string `json:"type,omitempty"`
+	// Default is the default value set in values.yaml or in the schema
+	Default interface{} `json:"default"`
+	// Description is taken from the comments above the value in values.yaml,
+	// or from the schema
+	Description string `json:"description,omitempty"`
+}
+
+// ValuesDocs is the documentation of the values of a chart, sorted by key.
+type ValuesDocs []ValueDoc
+
+// DocumentValues generates the documentation of the values of a chart.
+//
+// The comments directly above a key of values.yaml describe the value. Values
+// that are not commented are described by the description of their property in
+// values.schema.json, if any, which also gives the type of null values.
+// Properties of the schema that have no default in values.yaml are documented
+// with the default of the schema.
+func DocumentValues(ch *chart.Chart) (ValuesDocs, error) {
+	props := map[string]schemaProperty{}
+	if len(ch.Schema) > 0 {
+		var schema map[string]interface{}
+		if err := json.Unmarshal(ch.Schema, &schema); err != nil {
+			return nil, errors.Wrap(err, "unable to parse values schema")
+		}
+		schemaProperties("", schema, props)
+	}
+
+	var comments map[string]string
+	for _, f := range ch.Raw {
+		if f.Name == ValuesfileName {
+			comments = valuesComments(f.Data)
+		}
+	}
+
+	defaults := map[string]interface{}{}
+	flattenValues("", ch.Values, defaults)
+
+	docs := ValuesDocs{}
+	for key, v := range defaults {
+		doc := ValueDoc{Key: key, Type: valueType(v), Default: v, Description: comments[key]}
+		if p, ok := props[key]; ok {
+			if doc.Type == "" {
+				doc.Type = p.Type
+			}
+			if doc.Description == "" {
+				doc.Description = p.Description
+			}
+		}
+		docs = append(docs, doc)
+	}
+	for key, p := range props {
+		if _, ok := defaults[key]; ok || !p.Leaf || hasLeafAncestor(key, defaults) {
+			continue
+		}
+		docs = append(docs, ValueDoc{Key: key, Type: p.Type, Default: p.Default, Description: p.Description})
+	}
+
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Key < docs[j].Key })
+	return docs, nil
+}
+
+// Markdown renders the documentation as a markdown table.
+func (d ValuesDocs) Markdown() string {
+	var b strings.Builder
+	b.WriteString("| Key | Type | Default | Description |\n")
+	b.WriteString("|-----|------|---------|-------------|\n")
+	for _, v := range d {
+		def, err := json.Marshal(v.Default)
+		if err != nil {
+			def = []byte(fmt.Sprintf("%v", v.Default))
+		}
+		fmt.Fprintf(&b, "| %s | %s | `%s` | %s |\n",
+			markdownCell(v.Key), v.Type, markdownCell(string(def)), markdownCell(v.Description))
+	}
+	return b.String()
+}
+
+// JSON renders the documentation as a JSON array.
+func (d ValuesDocs) JSON() (string, error) {
+	out, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
+}
+
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// flattenValues collects the leaves of the values by dotted path. Empty maps
+// are leaves.
+func flattenValues(prefix string, values map[string]interface{}, out map[string]interface{}) {
+	for k, v := range values {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if m, ok := v.(map[string]interface{}); ok && len(m) > 0 {
+			flattenValues(key, m, out)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+func hasLeafAncestor(key string, defaults map[string]interface{}) bool {
+	for i := strings.LastIndex(key, "."); i > 0; i = strings.LastIndex(key[:i], ".") {
+		if _, ok := defaults[key[:i]]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func valueType(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case int, int64:
+		return "int"
+	case float64:
+		if v == math.Trunc(v) {
+			return "int"
+		}
+		return "float"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return ""
+	}
+}
+
+type schemaProperty struct {
+	Type        string
+	Description string
+	Default     interface{}
+	// Leaf is set if the property has no properties of its own
+	Leaf bool
+	// Deprecated is set if the property is marked deprecated, or has a
+	// deprecation message
+	Deprecated         bool
+	DeprecationMessage string
+	// ReplacedBy is the dotted path of the property replacing a deprecated one
+	ReplacedBy string
+}
+
+// schemaTypes maps JSON schema types to the types used in the documentation.
+var schemaTypes = map[string]string{
+	"integer": "int",
+	"number":  "float",
+	"boolean": "bool",
+	"array":   "list",
+}
+
+// schemaProperties collects the properties of a JSON schema by dotted path.
+func schemaProperties(prefix string, schema map[string]interface{}, out map[string]schemaProperty) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	for k, v := range properties {
+		p, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		prop := schemaProperty{Default: p["default"]}
+		prop.Description, _ = p["description"].(string)
+		prop.Deprecated, _ = p["deprecated"].(bool)
+		prop.DeprecationMessage, _ = p["deprecationMessage"].(string)
+		prop.ReplacedBy, _ = p["replacedBy"].(string)
+		prop.Deprecated = prop.Deprecated || prop.DeprecationMessage != ""
+		switch t := p["type"].(type) {
+		case string:
+			prop.Type = schemaType(t)
+		case []interface{}:
+			var types []string
+			for _, s := range t {
+				if s, ok := s.(string); ok {
+					types = append(types, schemaType(s))
+				}
+			}
+			prop.Type = strings.Join(types, "|")
+		}
+		nested, _ := p["properties"].(map[string]interface{})
+		prop.Leaf = len(nested) == 0
+		out[key] = prop
+		schemaProperties(key, p, out)
+	}
+}
+
+func schemaType(t string) string {
+	if s, ok := schemaTypes[t]; ok {
+		return s
+	}
+	return t
+}
+
+// valuesKeyPattern matches a line of values.yaml defining a key.
+var valuesKeyPattern = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s#'"-][^:#]*?|-[^\s:#][^:#]*?):(\s|$)`)
+
+// valuesComments returns the comments directly above each key of values.yaml
+// by dotted path. A blank line ends a comment.
+func valuesComments(data []byte) map[string]string {
+	type level struct {
+		indent int
+		// key is empty for the items of a list
+		key string
+	}
+
+	comments := map[string]string{}
+	var (
+		stack []level
+		lines []string
+		// blockIndent is the indentation of the key of a block scalar being skipped
+		blockIndent = -1
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if blockIndent >= 0 {
+			if trimmed == "" || indent > blockIndent {
+				continue
+			}
+			blockIndent = -1
+		}
+
+		switch {
+		case trimmed == "" || trimmed == "---":
+			lines = nil
+			continue
+		case strings.HasPrefix(trimmed, "#"):
+			lines = append(lines, strings.TrimSpace(strings.TrimPrefix(trimmed, "#")))
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			stack = append(stack, level{indent: indent})
+			lines = nil
+			continue
+		}
+
+		m := valuesKeyPattern.FindStringSubmatch(trimmed)
+		if m == nil {
+			lines = nil
+			continue
+		}
+		stack = append(stack, level{indent: indent, key: strings.Trim(m[1], `"'`)})
+
+		keys := make([]string, 0, len(stack))
+		inList := false
+		for _, l := range stack {
+			keys = append(keys, l.key)
+			inList = inList || l.key == ""
+		}
+		// Items of lists are not documented
+		if len(lines) > 0 && !inList {
+			comments[strings.Join(keys, ".")] = strings.Join(lines, " ")
+		}
+		lines = nil
+
+		if rest := strings.TrimSpace(trimmed[len(m[0]):]); strings.HasPrefix(rest, "|") || strings.HasPrefix(rest, ">") {
+			blockIndent = indent
+		}
+	}
+	return comments
+}
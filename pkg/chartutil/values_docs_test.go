@@ -0,0 +1,130 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const docsValues = `# Number of replicas
+replicaCount: 1
+
+image:
+  # The image repository
+  repository: nginx
+  # Overrides the image tag
+  # whose default is the chart appVersion.
+  tag: ""
+
+# This comment is not attached to anything
+
+script: |
+  # not a comment
+  notAKey: true
+
+tolerations:
+  # not documented
+  - key: example
+    # not documented either
+    value: foo
+
+# Resources of the pod
+resources: {}
+`
+
+const docsSchema = `{
+  "properties": {
+    "image": {
+      "properties": {
+        "pullPolicy": {
+          "type": "string",
+          "description": "Pull policy of the image",
+          "default": "IfNotPresent"
+        },
+        "repository": {
+          "type": "string",
+          "description": "Overridden by the values.yaml comment"
+        }
+      }
+    },
+    "nodeSelector": {
+      "type": "object",
+      "description": "Node labels for pod assignment"
+    },
+    "resources": {
+      "properties": {
+        "limits": {"type": "object"}
+      }
+    },
+    "script": {
+      "type": "string",
+      "description": "Script to run"
+    }
+  }
+}`
+
+func TestValuesComments(t *testing.T) {
+	expect := map[string]string{
+		"replicaCount":     "Number of replicas",
+		"image.repository": "The image repository",
+		"image.tag":        "Overrides the image tag whose default is the chart appVersion.",
+		"resources":        "Resources of the pod",
+	}
+	if got := valuesComments([]byte(docsValues)); !reflect.DeepEqual(expect, got) {
+		t.Errorf("Expected comments %v, got %v", expect, got)
+	}
+}
+
+func TestDocumentValues(t *testing.T) {
+	values, err := ReadValues([]byte(docsValues))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "docs"},
+		Raw:      []*chart.File{{Name: ValuesfileName, Data: []byte(docsValues)}},
+		Values:   values,
+		Schema:   []byte(docsSchema),
+	}
+
+	docs, err := DocumentValues(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `| Key | Type | Default | Description |
+|-----|------|---------|-------------|
+| image.pullPolicy | string | ` + "`\"IfNotPresent\"`" + ` | Pull policy of the image |
+| image.repository | string | ` + "`\"nginx\"`" + ` | The image repository |
+| image.tag | string | ` + "`\"\"`" + ` | Overrides the image tag whose default is the chart appVersion. |
+| nodeSelector | object | ` + "`null`" + ` | Node labels for pod assignment |
+| replicaCount | int | ` + "`1`" + ` | Number of replicas |
+| resources | object | ` + "`{}`" + ` | Resources of the pod |
+| script | string | ` + "`\"# not a comment\\nnotAKey: true\\n\"`" + ` | Script to run |
+| tolerations | list | ` + "`[{\"key\":\"example\",\"value\":\"foo\"}]`" + ` |  |
+`
+	if got := docs.Markdown(); got != expect {
+		t.Errorf("Expected\n%s\nGot\n%s", expect, got)
+	}
+
+	if _, err := DocumentValues(&chart.Chart{Metadata: c.Metadata, Schema: []byte("{")}); err == nil {
+		t.Error("Expected an error for an invalid schema")
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/copystructure"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// ValuesMatrixFile is the path, in a chart, of the matrix of the values the
+// chart is tested with.
+const ValuesMatrixFile = "ci/values-matrix.yaml"
+
+// ValuesMatrix describes the values a chart supports along independent axes,
+// so that the chart can be rendered with every combination of them. For
+// instance, with
+//
+//	axes:
+//	  ingress:
+//	    enabled:
+//	      ingress:
+//	        enabled: true
+//	    disabled: {}
+//	  persistence:
+//	    emptyDir:
+//	      persistence:
+//	        enabled: false
+//	    volume:
+//	      persistence:
+//	        enabled: true
+//	exclude:
+//	- ingress: disabled
+//	  persistence: emptyDir
+//
+// the chart is rendered with three combinations of values, such as
+// 'ingress=enabled,persistence=volume'.
+type ValuesMatrix struct {
+	// Axes maps the name of each axis to its choices, each of which maps
+	// its name to the values it sets.
+	Axes map[string]map[string]map[string]interface{} `json:"axes"`
+	// Exclude lists the combinations the chart does not support, as the
+	// choices of some of the axes. A combination is excluded if it makes
+	// all the choices of one of them.
+	Exclude []map[string]string `json:"exclude,omitempty"`
+}
+
+// ValuesCombination is a combination of the choices of the axes of a values
+// matrix.
+type ValuesCombination struct {
+	// Name is the name of the combination, the choices for the axes sorted
+	// by name, such as 'ingress=enabled,persistence=volume'.
+	Name string
+	// Choices maps the name of each axis to the name of its choice.
+	Choices map[string]string
+	// Values are the values set by the choices. If several of them set
+	// the same value, that of the first axis in alphabetical order wins.
+	Values map[string]interface{}
+}
+
+// ReadValuesMatrix parses a values matrix.
+func ReadValuesMatrix(data []byte) (*ValuesMatrix, error) {
+	m := &ValuesMatrix{}
+	if err := yaml.UnmarshalStrict(data, m); err != nil {
+		return nil, errors.Wrap(err, "invalid values matrix")
+	}
+	if len(m.Axes) == 0 {
+		return nil, errors.New("invalid values matrix: no axes")
+	}
+	for axis, choices := range m.Axes {
+		if len(choices) == 0 {
+			return nil, errors.Errorf("invalid values matrix: axis %q has no choices", axis)
+		}
+	}
+	for i, exclude := range m.Exclude {
+		if len(exclude) == 0 {
+			return nil, errors.Errorf("invalid values matrix: exclude %d is empty", i)
+		}
+		for axis, choice := range exclude {
+			if _, ok := m.Axes[axis][choice]; !ok {
+				return nil, errors.Errorf("invalid values matrix: exclude %d refers to unknown choice %s=%s", i, axis, choice)
+			}
+		}
+	}
+	return m, nil
+}
+
+// LoadValuesMatrix reads the values matrix of the chart.
+func LoadValuesMatrix(chrt *chart.Chart) (*ValuesMatrix, error) {
+	for _, f := range chrt.Files {
+		if f.Name == ValuesMatrixFile {
+			return ReadValuesMatrix(f.Data)
+		}
+	}
+	return nil, errors.Errorf("chart %s has no values matrix: %s not found", chrt.Name(), ValuesMatrixFile)
+}
+
+// Combinations returns the combinations of the choices of the axes of the
+// matrix that are not excluded, sorted by name.
+func (m *ValuesMatrix) Combinations() ([]ValuesCombination, error) {
+	axes := make([]string, 0, len(m.Axes))
+	for axis := range m.Axes {
+		axes = append(axes, axis)
+	}
+	sort.Strings(axes)
+
+	combinations := []map[string]string{{}}
+	for _, axis := range axes {
+		choices := make([]string, 0, len(m.Axes[axis]))
+		for choice := range m.Axes[axis] {
+			choices = append(choices, choice)
+		}
+		sort.Strings(choices)
+
+		next := make([]map[string]string, 0, len(combinations)*len(choices))
+		for _, c := range combinations {
+			for _, choice := range choices {
+				n := make(map[string]string, len(c)+1)
+				for k, v := range c {
+					n[k] = v
+				}
+				n[axis] = choice
+				next = append(next, n)
+			}
+		}
+		combinations = next
+	}
+
+	var result []ValuesCombination
+	for _, choices := range combinations {
+		if m.excludes(choices) {
+			continue
+		}
+		names := make([]string, 0, len(axes))
+		vals := map[string]interface{}{}
+		for _, axis := range axes {
+			choice := choices[axis]
+			names = append(names, fmt.Sprintf("%s=%s", axis, choice))
+			v, err := copystructure.Copy(m.Axes[axis][choice])
+			if err != nil {
+				return nil, err
+			}
+			if v, ok := v.(map[string]interface{}); ok {
+				vals = CoalesceTables(vals, v)
+			}
+		}
+		result = append(result, ValuesCombination{
+			Name:    strings.Join(names, ","),
+			Choices: choices,
+			Values:  vals,
+		})
+	}
+	if len(result) == 0 {
+		return nil, errors.New("every combination of the values matrix is excluded")
+	}
+	return result, nil
+}
+
+// excludes returns true if the combination of the given choices is excluded.
+func (m *ValuesMatrix) excludes(choices map[string]string) bool {
+	for _, exclude := range m.Exclude {
+		matches := true
+		for axis, choice := range exclude {
+			if choices[axis] != choice {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge returns the given values with those of the combination, which take
+// precedence. Neither is modified.
+func (c *ValuesCombination) Merge(vals map[string]interface{}) (map[string]interface{}, error) {
+	base, err := copystructure.Copy(vals)
+	if err != nil {
+		return nil, err
+	}
+	own, err := copystructure.Copy(c.Values)
+	if err != nil {
+		return nil, err
+	}
+	b, _ := base.(map[string]interface{})
+	return CoalesceTables(own.(map[string]interface{}), b), nil
+}
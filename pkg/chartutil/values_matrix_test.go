@@ -0,0 +1,115 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const testValuesMatrix = `
+axes:
+  ingress:
+    enabled:
+      ingress:
+        enabled: true
+    disabled: {}
+  persistence:
+    emptyDir:
+      persistence:
+        enabled: false
+    volume:
+      persistence:
+        enabled: true
+        size: 8Gi
+exclude:
+- ingress: disabled
+  persistence: emptyDir
+`
+
+func TestValuesMatrixCombinations(t *testing.T) {
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "matrix"},
+		Files:    []*chart.File{{Name: ValuesMatrixFile, Data: []byte(testValuesMatrix)}},
+	}
+	m, err := LoadValuesMatrix(chrt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	combinations, err := m.Combinations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, c := range combinations {
+		names = append(names, c.Name)
+	}
+	expected := []string{
+		"ingress=disabled,persistence=volume",
+		"ingress=enabled,persistence=emptyDir",
+		"ingress=enabled,persistence=volume",
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Expected combinations %v, got %v", expected, names)
+	}
+
+	vals, err := combinations[2].Merge(map[string]interface{}{
+		"ingress":     map[string]interface{}{"enabled": false, "host": "example.com"},
+		"persistence": map[string]interface{}{"size": "1Gi"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedVals := map[string]interface{}{
+		"ingress":     map[string]interface{}{"enabled": true, "host": "example.com"},
+		"persistence": map[string]interface{}{"enabled": true, "size": "8Gi"},
+	}
+	if !reflect.DeepEqual(vals, expectedVals) {
+		t.Errorf("Expected values %v, got %v", expectedVals, vals)
+	}
+	if _, ok := combinations[2].Values["ingress"].(map[string]interface{})["host"]; ok {
+		t.Error("Expected the values of the combination to be left alone")
+	}
+}
+
+func TestReadValuesMatrixErrors(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"axes:\n  ingress: {}\n",
+		"axes:\n  ingress:\n    enabled: {}\nexclude:\n- ingress: disabled\n",
+		"axes:\n  ingress:\n    enabled: {}\nunknown: true\n",
+	} {
+		if _, err := ReadValuesMatrix([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %q", data)
+		}
+	}
+
+	m, err := ReadValuesMatrix([]byte("axes:\n  ingress:\n    enabled: {}\nexclude:\n- ingress: enabled\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Combinations(); err == nil {
+		t.Error("Expected an error when every combination is excluded")
+	}
+
+	if _, err := LoadValuesMatrix(&chart.Chart{Metadata: &chart.Metadata{Name: "matrix"}}); err == nil {
+		t.Error("Expected an error for a chart without a values matrix")
+	}
+}
@@ -19,6 +19,7 @@ package chartutil
 import (
 	"bytes"
 	"fmt"
+	"reflect"
 	"testing"
 	"text/template"
 
@@ -101,6 +102,10 @@ func TestToRenderValues(t *testing.T) {
 		Namespace: "default",
 		Revision:  1,
 		IsInstall: true,
+		NamespaceMetadata: NamespaceMetadata{
+			Labels: map[string]string{"istio-injection": "enabled"},
+		},
+		Features: Features{"canary": true, FeatureDryRun: true},
 	}
 
 	res, err := ToRenderValues(c, overrideValues, o, nil)
@@ -128,12 +133,20 @@ func TestToRenderValues(t *testing.T) {
 	if !relmap["IsInstall"].(bool) {
 		t.Errorf("Expected install to be true.")
 	}
+	if label := relmap["NamespaceMetadata"].(NamespaceMetadata).Labels["istio-injection"]; label != "enabled" {
+		t.Errorf("Expected namespace label 'enabled', got %q", label)
+	}
 	if !res["Capabilities"].(*Capabilities).APIVersions.Has("v1") {
 		t.Error("Expected Capabilities to have v1 as an API")
 	}
 	if res["Capabilities"].(*Capabilities).KubeVersion.Major != "1" {
 		t.Error("Expected Capabilities to have a Kube version")
 	}
+	features := res["Helm"].(map[string]interface{})["Features"].(Features)
+	expectFeatures := Features{"canary": true, FeatureDryRun: true, FeatureServerDryRun: false, FeatureClientOnly: false}
+	if !reflect.DeepEqual(features, expectFeatures) {
+		t.Errorf("Expected features %v, got %v", expectFeatures, features)
+	}
 
 	vals := res["Values"].(Values)
 	if vals["name"] != "Haroun" {
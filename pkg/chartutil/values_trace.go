@@ -0,0 +1,142 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// ValueOrigin is a value set by a source.
+type ValueOrigin struct {
+	Source string
+	Value  interface{}
+}
+
+// ValueTrace describes where a value comes from.
+type ValueTrace struct {
+	// Key is the dotted path of the value from the top-level chart
+	Key string
+	// Value is the value after the values have been coalesced
+	Value interface{}
+	// Source describes where the value comes from
+	Source string
+	// Origins are the values every source sets the key to, in decreasing
+	// order of precedence. The value of Source is one of them, the others
+	// are overridden.
+	Origins []ValueOrigin
+}
+
+// TraceValue returns where the value at the given key comes from when the
+// chart is rendered with the given user supplied values.
+//
+// The key is the dotted path of the value from the top-level chart, such as
+// 'subchart1.image.tag'. It must designate a single value rather than a
+// table of values.
+//
+// Sources are the user supplied values in increasing order of precedence, as
+// for DebugValues.
+//
+// The chart's dependencies are processed, so the chart is modified.
+func TraceValue(ch *chart.Chart, vals map[string]interface{}, sources []ValuesSource, key string) (*ValueTrace, error) {
+	if err := ProcessDependencies(ch, vals); err != nil {
+		return nil, err
+	}
+	final, err := CoalesceValues(ch, vals)
+	if err != nil {
+		return nil, err
+	}
+
+	path := parsePath(strings.Trim(key, "."))
+	value, ok := lookupValue(final, path)
+	if !ok {
+		return nil, errors.Errorf("no value for key %s", key)
+	}
+	if table, ok := value.(map[string]interface{}); ok && len(table) > 0 {
+		keys := make([]string, 0, len(table))
+		for k := range table {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return nil, errors.Errorf("key %s is a table of values, trace one of its keys: %s", key, strings.Join(keys, ", "))
+	}
+
+	// The key descends into the scopes of the subcharts it names
+	chain := []*chart.Chart{ch}
+	var names []string
+	for _, name := range path[:len(path)-1] {
+		sub := dependencyNamed(chain[len(chain)-1], name)
+		if sub == nil {
+			break
+		}
+		chain = append(chain, sub)
+		names = append(names, name)
+	}
+	rest := path[len(names):]
+	global := len(rest) > 1 && rest[0] == GlobalKey
+
+	trace := &ValueTrace{Key: joinPath(path...), Value: value}
+	for _, l := range valuesLayers(chain, names, sources) {
+		v, ok := l.lookup(rest, false)
+		if !ok && global {
+			// Globals are passed down from the parent charts
+			v, ok = l.lookup(rest[1:], true)
+		}
+		if !ok {
+			continue
+		}
+		trace.Origins = append(trace.Origins, ValueOrigin{Source: l.name, Value: v})
+		if trace.Source == "" && reflect.DeepEqual(v, value) {
+			trace.Source = l.name
+		}
+	}
+	if trace.Source == "" {
+		trace.Source = "import-values"
+	}
+	return trace, nil
+}
+
+// String renders the value with its source and the values it overrides.
+func (t *ValueTrace) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "KEY: %s\n", t.Key)
+	fmt.Fprintf(&b, "VALUE: %s\n", jsonValue(t.Value))
+	fmt.Fprintf(&b, "SOURCE: %s\n", t.Source)
+	b.WriteString("\nORIGINS:\n")
+	if len(t.Origins) == 0 {
+		b.WriteString("(none)\n")
+	}
+	for _, o := range t.Origins {
+		fmt.Fprintf(&b, "%s: %s\n", o.Source, jsonValue(o.Value))
+	}
+	return b.String()
+}
+
+func jsonValue(v interface{}) []byte {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return []byte(fmt.Sprintf("%v", v))
+	}
+	return value
+}
@@ -0,0 +1,113 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestTraceValue(t *testing.T) {
+	parent := chartWithValues(t, "parent", `
+global:
+  env: prod
+sub:
+  replicas: 2
+`)
+	parent.Metadata.Dependencies = []*chart.Dependency{{Name: "sub", Version: "0.1.0"}}
+	sub := chartWithValues(t, "sub", `
+replicas: 1
+image: nginx
+global:
+  region: eu
+`)
+	parent.AddDependency(sub)
+
+	sources := []ValuesSource{
+		{Name: "custom.yaml", Values: map[string]interface{}{"sub": map[string]interface{}{"image": "custom"}}},
+		{Name: "--set", Values: map[string]interface{}{"global": map[string]interface{}{"region": "us"}}},
+	}
+	vals := map[string]interface{}{
+		"sub":    map[string]interface{}{"image": "custom"},
+		"global": map[string]interface{}{"region": "us"},
+	}
+
+	tests := []struct {
+		key    string
+		expect *ValueTrace
+	}{{
+		key: "sub.replicas",
+		expect: &ValueTrace{Key: "sub.replicas", Value: float64(2), Source: "values.yaml of parent", Origins: []ValueOrigin{
+			{Source: "values.yaml of parent", Value: float64(2)},
+			{Source: "values.yaml of parent/sub", Value: float64(1)},
+		}},
+	}, {
+		key: "sub.image",
+		expect: &ValueTrace{Key: "sub.image", Value: "custom", Source: "custom.yaml", Origins: []ValueOrigin{
+			{Source: "custom.yaml", Value: "custom"},
+			{Source: "values.yaml of parent/sub", Value: "nginx"},
+		}},
+	}, {
+		key: "sub.global.region",
+		expect: &ValueTrace{Key: "sub.global.region", Value: "us", Source: "--set", Origins: []ValueOrigin{
+			{Source: "--set", Value: "us"},
+			{Source: "values.yaml of parent/sub", Value: "eu"},
+		}},
+	}, {
+		key: "global.env",
+		expect: &ValueTrace{Key: "global.env", Value: "prod", Source: "values.yaml of parent", Origins: []ValueOrigin{
+			{Source: "values.yaml of parent", Value: "prod"},
+		}},
+	}}
+	for _, tt := range tests {
+		trace, err := TraceValue(parent, vals, sources, tt.key)
+		if err != nil {
+			t.Errorf("%s: %s", tt.key, err)
+			continue
+		}
+		if !reflect.DeepEqual(trace, tt.expect) {
+			t.Errorf("%s: expected %v, got %v", tt.key, tt.expect, trace)
+		}
+	}
+
+	if _, err := TraceValue(parent, vals, sources, "sub"); err == nil {
+		t.Error("expected an error for a table of values")
+	}
+	if _, err := TraceValue(parent, vals, sources, "sub.missing"); err == nil {
+		t.Error("expected an error for a missing key")
+	}
+}
+
+func TestValueTraceString(t *testing.T) {
+	trace := &ValueTrace{Key: "image.tag", Value: "1.2.3", Source: "prod.yaml", Origins: []ValueOrigin{
+		{Source: "prod.yaml", Value: "1.2.3"},
+		{Source: "values.yaml of app", Value: "latest"},
+	}}
+	expect := `KEY: image.tag
+VALUE: "1.2.3"
+SOURCE: prod.yaml
+
+ORIGINS:
+prod.yaml: "1.2.3"
+values.yaml of app: "latest"
+`
+	if trace.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, trace.String())
+	}
+}
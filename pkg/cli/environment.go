@@ -68,6 +68,20 @@ type EnvSettings struct {
 	PluginsDirectory string
 	// MaxHistory is the max release history maintained.
 	MaxHistory int
+	// Profile is the name of the configuration profile to use, if any.
+	Profile string
+	// Offline disables all network access except to the Kubernetes API.
+	Offline bool
+	// Quiet hides the messages reporting progress, leaving the output of
+	// commands and warnings.
+	Quiet bool
+	// Verbose shows detailed messages along with the ones reporting progress.
+	Verbose bool
+	// MessageFormat is the format of the messages, text or json.
+	MessageFormat string
+	// Features are the features of the client enabled for the templates, each
+	// given as NAME or NAME=true|false.
+	Features []string
 }
 
 func New() *EnvSettings {
@@ -78,14 +92,18 @@ func New() *EnvSettings {
 		KubeToken:        os.Getenv("HELM_KUBETOKEN"),
 		KubeAsUser:       os.Getenv("HELM_KUBEASUSER"),
 		KubeAsGroups:     envCSV("HELM_KUBEASGROUPS"),
+		Features:         envCSV("HELM_FEATURES"),
 		KubeAPIServer:    os.Getenv("HELM_KUBEAPISERVER"),
 		KubeCaFile:       os.Getenv("HELM_KUBECAFILE"),
 		PluginsDirectory: envOr("HELM_PLUGINS", helmpath.DataPath("plugins")),
 		RegistryConfig:   envOr("HELM_REGISTRY_CONFIG", helmpath.ConfigPath("registry.json")),
 		RepositoryConfig: envOr("HELM_REPOSITORY_CONFIG", helmpath.ConfigPath("repositories.yaml")),
 		RepositoryCache:  envOr("HELM_REPOSITORY_CACHE", helmpath.CachePath("repository")),
+		Profile:          os.Getenv("HELM_PROFILE"),
+		MessageFormat:    envOr("HELM_MESSAGE_FORMAT", "text"),
 	}
 	env.Debug, _ = strconv.ParseBool(os.Getenv("HELM_DEBUG"))
+	env.Offline, _ = strconv.ParseBool(os.Getenv("HELM_OFFLINE"))
 
 	// bind to kubernetes config flags
 	env.config = &genericclioptions.ConfigFlags{
@@ -115,6 +133,11 @@ func (s *EnvSettings) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.RegistryConfig, "registry-config", s.RegistryConfig, "path to the registry config file")
 	fs.StringVar(&s.RepositoryConfig, "repository-config", s.RepositoryConfig, "path to the file containing repository names and URLs")
 	fs.StringVar(&s.RepositoryCache, "repository-cache", s.RepositoryCache, "path to the file containing cached repository indexes")
+	fs.StringVar(&s.Profile, "profile", s.Profile, "name of the configuration profile to use, from the profiles directory of the Helm configuration")
+	fs.BoolVar(&s.Offline, "offline", s.Offline, "fail instead of reaching chart repositories, registries or any other network service than the Kubernetes API")
+	fs.BoolVar(&s.Quiet, "quiet", s.Quiet, "only print the output of commands and warnings, without the messages reporting progress")
+	fs.BoolVar(&s.Verbose, "verbose", s.Verbose, "print detailed messages along with the ones reporting progress")
+	fs.StringVar(&s.MessageFormat, "message-format", s.MessageFormat, "format of the messages that are not the output of commands: text, or json to print them as JSON lines to stderr")
 }
 
 func envOr(name, def string) string {
@@ -151,12 +174,16 @@ func (s *EnvSettings) EnvVars() map[string]string {
 		"HELM_CONFIG_HOME":       helmpath.ConfigPath(""),
 		"HELM_DATA_HOME":         helmpath.DataPath(""),
 		"HELM_DEBUG":             fmt.Sprint(s.Debug),
+		"HELM_FEATURES":          strings.Join(s.Features, ","),
 		"HELM_PLUGINS":           s.PluginsDirectory,
 		"HELM_REGISTRY_CONFIG":   s.RegistryConfig,
 		"HELM_REPOSITORY_CACHE":  s.RepositoryCache,
 		"HELM_REPOSITORY_CONFIG": s.RepositoryConfig,
 		"HELM_NAMESPACE":         s.Namespace(),
 		"HELM_MAX_HISTORY":       strconv.Itoa(s.MaxHistory),
+		"HELM_PROFILE":           s.Profile,
+		"HELM_OFFLINE":           fmt.Sprint(s.Offline),
+		"HELM_MESSAGE_FORMAT":    s.MessageFormat,
 
 		// broken, these are populated from helm flags and not kubeconfig.
 		"HELM_KUBECONTEXT":   s.KubeContext,
@@ -36,6 +36,7 @@ func TestEnvSettings(t *testing.T) {
 		// expected values
 		ns, kcontext string
 		debug        bool
+		offline      bool
 		maxhistory   int
 		kAsUser      string
 		kAsGroups    []string
@@ -48,9 +49,10 @@ func TestEnvSettings(t *testing.T) {
 		},
 		{
 			name:       "with flags set",
-			args:       "--debug --namespace=myns --kube-as-user=poro --kube-as-group=admins --kube-as-group=teatime --kube-as-group=snackeaters --kube-ca-file=/tmp/ca.crt",
+			args:       "--debug --offline --namespace=myns --kube-as-user=poro --kube-as-group=admins --kube-as-group=teatime --kube-as-group=snackeaters --kube-ca-file=/tmp/ca.crt",
 			ns:         "myns",
 			debug:      true,
+			offline:    true,
 			maxhistory: defaultMaxHistory,
 			kAsUser:    "poro",
 			kAsGroups:  []string{"admins", "teatime", "snackeaters"},
@@ -58,10 +60,11 @@ func TestEnvSettings(t *testing.T) {
 		},
 		{
 			name:       "with envvars set",
-			envvars:    map[string]string{"HELM_DEBUG": "1", "HELM_NAMESPACE": "yourns", "HELM_KUBEASUSER": "pikachu", "HELM_KUBEASGROUPS": ",,,operators,snackeaters,partyanimals", "HELM_MAX_HISTORY": "5", "HELM_KUBECAFILE": "/tmp/ca.crt"},
+			envvars:    map[string]string{"HELM_DEBUG": "1", "HELM_OFFLINE": "true", "HELM_NAMESPACE": "yourns", "HELM_KUBEASUSER": "pikachu", "HELM_KUBEASGROUPS": ",,,operators,snackeaters,partyanimals", "HELM_MAX_HISTORY": "5", "HELM_KUBECAFILE": "/tmp/ca.crt"},
 			ns:         "yourns",
 			maxhistory: 5,
 			debug:      true,
+			offline:    true,
 			kAsUser:    "pikachu",
 			kAsGroups:  []string{"operators", "snackeaters", "partyanimals"},
 			kCaFile:    "/tmp/ca.crt",
@@ -96,6 +99,9 @@ func TestEnvSettings(t *testing.T) {
 			if settings.Debug != tt.debug {
 				t.Errorf("expected debug %t, got %t", tt.debug, settings.Debug)
 			}
+			if settings.Offline != tt.offline {
+				t.Errorf("expected offline %t, got %t", tt.offline, settings.Offline)
+			}
 			if settings.Namespace() != tt.ns {
 				t.Errorf("expected namespace %q, got %q", tt.ns, settings.Namespace())
 			}
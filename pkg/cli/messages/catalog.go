@@ -0,0 +1,142 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package messages
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// Catalog maps the IDs of messages to their texts, as fmt formats. The
+// formats of a translation take the same arguments as the English ones, and
+// may reorder them with explicit argument indexes.
+type Catalog map[ID]string
+
+// IDs of the messages of the Helm CLI.
+const (
+	// Warning is a warning of the CLI. Args: message.
+	Warning ID = "Warning"
+	// ActionWarning is a warning raised by an action. Args: reason, message.
+	ActionWarning ID = "ActionWarning"
+
+	ChartCreating      ID = "ChartCreating"
+	ChartPackaged      ID = "ChartPackaged"
+	PluginInstalled    ID = "PluginInstalled"
+	PluginUninstalled  ID = "PluginUninstalled"
+	PluginUpdated      ID = "PluginUpdated"
+	ReleaseInstalling  ID = "ReleaseInstalling"
+	ReleaseUpgraded    ID = "ReleaseUpgraded"
+	ReleaseUnchanged   ID = "ReleaseUnchanged"
+	ReleaseRolledBack  ID = "ReleaseRolledBack"
+	ReleaseUninstalled ID = "ReleaseUninstalled"
+	ReleasePaused      ID = "ReleasePaused"
+	ReleaseResumed     ID = "ReleaseResumed"
+	ReleaseRenamed     ID = "ReleaseRenamed"
+	ReleasesBackedUp   ID = "ReleasesBackedUp"
+	ReleasesRestored   ID = "ReleasesRestored"
+	RevisionWritten    ID = "RevisionWritten"
+	RecordsDeleted     ID = "RecordsDeleted"
+	RepoAdded          ID = "RepoAdded"
+	RepoUnchanged      ID = "RepoUnchanged"
+	RepoRemoved        ID = "RepoRemoved"
+	RepoUpdating       ID = "RepoUpdating"
+	RepoUpdated        ID = "RepoUpdated"
+	RepoUpdateFailed   ID = "RepoUpdateFailed"
+	ReposUpdated       ID = "ReposUpdated"
+	ReposUpdateFailed  ID = "ReposUpdateFailed"
+	UsingProfile       ID = "UsingProfile"
+	LoadedChart        ID = "LoadedChart"
+)
+
+// English is the catalog of the messages in English, which all messages
+// have.
+var English = Catalog{
+	Warning:            "%s",
+	ActionWarning:      "%[2]s",
+	ChartCreating:      "Creating %s",
+	ChartPackaged:      "Successfully packaged chart and saved it to: %s",
+	PluginInstalled:    "Installed plugin: %s",
+	PluginUninstalled:  "Uninstalled plugin: %s",
+	PluginUpdated:      "Updated plugin: %s",
+	ReleaseInstalling:  "Release %q does not exist. Installing it now.",
+	ReleaseUpgraded:    "Release %q has been upgraded. Happy Helming!",
+	ReleaseUnchanged:   "Release %q is unchanged. The upgrade was skipped.",
+	ReleaseRolledBack:  "Rollback was a success! Happy Helming!",
+	ReleaseUninstalled: "release %q uninstalled",
+	ReleasePaused:      "Release %q has been paused",
+	ReleaseResumed:     "Release %q has been resumed",
+	ReleaseRenamed:     "Release %q has been renamed to %q",
+	ReleasesBackedUp:   "Wrote %d release records to %s",
+	ReleasesRestored:   "Restored %d release records",
+	RevisionWritten:    "wrote revision %d of %s to %s",
+	RecordsDeleted:     "deleted %d release record(s)",
+	RepoAdded:          "%q has been added to your repositories",
+	RepoUnchanged:      "%q already exists with the same configuration, skipping",
+	RepoRemoved:        "%q has been removed from your repositories",
+	RepoUpdating:       "Hang tight while we grab the latest from your chart repositories...",
+	RepoUpdated:        "...Successfully got an update from the %q chart repository",
+	RepoUpdateFailed:   "...Unable to get an update from the %q chart repository (%s):\n\t%s",
+	ReposUpdated:       "Update Complete. ⎈Happy Helming!⎈",
+	ReposUpdateFailed:  "Update Complete. %d of %d repositories could not be updated: %s",
+	UsingProfile:       "Using the configuration profile %s",
+	LoadedChart:        "Loaded chart %s-%s from %s",
+}
+
+var (
+	catalogsMu sync.RWMutex
+	catalogs   = map[string]Catalog{"en": English}
+)
+
+// Register registers the catalog of a language, such as "fr" or "pt_BR".
+func Register(lang string, c Catalog) {
+	catalogsMu.Lock()
+	defer catalogsMu.Unlock()
+	catalogs[lang] = c
+}
+
+// Lookup returns the catalog of a language, given as a locale such as
+// "pt_BR.UTF-8". It falls back to the catalog of the language without its
+// territory, then to English.
+func Lookup(locale string) Catalog {
+	lang := locale
+	if i := strings.IndexAny(lang, ".@"); i >= 0 {
+		lang = lang[:i]
+	}
+	catalogsMu.RLock()
+	defer catalogsMu.RUnlock()
+	if c, ok := catalogs[lang]; ok {
+		return c
+	}
+	if i := strings.IndexAny(lang, "_-"); i >= 0 {
+		if c, ok := catalogs[lang[:i]]; ok {
+			return c
+		}
+	}
+	return English
+}
+
+// Language returns the locale of the messages of the user, from the first
+// of $HELM_LANG, $LC_ALL, $LC_MESSAGES and $LANG that is set.
+func Language() string {
+	for _, name := range []string{"HELM_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return "en"
+}
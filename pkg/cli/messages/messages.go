@@ -0,0 +1,206 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package messages prints the messages of the Helm CLI that are not the output
+of commands, such as progress, from a catalog of localizable messages.
+
+Each message has a stable ID. The text of a message is looked up in the
+catalog of the language of the user, falling back to English. Messages are
+printed at a level, and the verbosity of the printer tells which levels are
+shown, so that quiet commands only print their output on stdout. In the JSON
+format, messages are printed to stderr as JSON lines carrying their IDs and
+arguments, for programs wrapping Helm.
+*/
+package messages
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ID identifies a message of the catalog. IDs are stable and can be relied
+// upon by programs.
+type ID string
+
+// Level is the level of a message.
+type Level string
+
+const (
+	// LevelInfo is the level of messages reporting the progress of commands.
+	LevelInfo Level = "info"
+	// LevelVerbose is the level of messages detailing what commands do.
+	LevelVerbose Level = "verbose"
+	// LevelWarning is the level of warnings.
+	LevelWarning Level = "warning"
+)
+
+// Verbosity tells which levels of messages are printed.
+type Verbosity int
+
+const (
+	// Quiet only prints warnings.
+	Quiet Verbosity = iota - 1
+	// Normal prints warnings and info messages.
+	Normal
+	// Verbose prints all messages.
+	Verbose
+)
+
+// Format is the format of the messages.
+type Format string
+
+const (
+	// Text prints messages for humans, info messages with the output of
+	// commands and verbose messages and warnings to stderr.
+	Text Format = "text"
+	// JSON prints messages as JSON lines to stderr.
+	JSON Format = "json"
+)
+
+// Formats returns the supported formats.
+func Formats() []string {
+	return []string{string(Text), string(JSON)}
+}
+
+// ParseFormat returns the format of a name. The empty name is the text
+// format.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case Text, JSON:
+		return Format(s), nil
+	case "":
+		return Text, nil
+	}
+	return "", errors.Errorf("invalid message format %q, must be one of %s", s, strings.Join(Formats(), ", "))
+}
+
+// Message is a message as printed in the JSON format.
+type Message struct {
+	Level Level  `json:"level"`
+	ID    ID     `json:"id"`
+	Text  string `json:"message"`
+	// Args are the arguments of the message, in the order of the English
+	// catalog
+	Args []interface{} `json:"args,omitempty"`
+}
+
+// Printer prints messages.
+type Printer struct {
+	// Out receives the info messages in the text format.
+	Out io.Writer
+	// Err receives the verbose messages and warnings in the text format, and
+	// all messages in the JSON format.
+	Err       io.Writer
+	Verbosity Verbosity
+	Format    Format
+	// Catalog gives the texts of the messages. Messages it lacks are taken
+	// from English.
+	Catalog Catalog
+
+	mu sync.Mutex
+}
+
+// NewPrinter returns a printer of the messages of the given catalog, with the
+// normal verbosity and the text format.
+func NewPrinter(out, err io.Writer, catalog Catalog) *Printer {
+	return &Printer{Out: out, Err: err, Catalog: catalog, Format: Text}
+}
+
+// Info prints a message reporting progress, unless quiet.
+func (p *Printer) Info(id ID, args ...interface{}) {
+	p.Print(LevelInfo, id, args...)
+}
+
+// Verbose prints a detailed message, if verbose.
+func (p *Printer) Verbose(id ID, args ...interface{}) {
+	p.Print(LevelVerbose, id, args...)
+}
+
+// Warning prints a warning.
+func (p *Printer) Warning(id ID, args ...interface{}) {
+	p.Print(LevelWarning, id, args...)
+}
+
+// Print prints a message at a level, if the verbosity allows.
+func (p *Printer) Print(level Level, id ID, args ...interface{}) {
+	p.Fprint(p.Out, level, id, args...)
+}
+
+// Fprint prints a message at a level as Print does, with the info messages in
+// the text format written to w rather than Out.
+func (p *Printer) Fprint(w io.Writer, level Level, id ID, args ...interface{}) {
+	switch {
+	case level == LevelInfo && p.Verbosity < Normal,
+		level == LevelVerbose && p.Verbosity < Verbose:
+		return
+	}
+	text := p.Text(id, args...)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Format == JSON {
+		raw, err := json.Marshal(Message{Level: level, ID: id, Text: text, Args: jsonArgs(args)})
+		if err != nil {
+			raw, _ = json.Marshal(Message{Level: level, ID: id, Text: text})
+		}
+		fmt.Fprintf(p.Err, "%s\n", raw)
+		return
+	}
+	switch level {
+	case LevelWarning:
+		fmt.Fprintf(p.Err, "WARNING: %s\n", text)
+	case LevelVerbose:
+		fmt.Fprintln(p.Err, text)
+	default:
+		fmt.Fprintln(w, text)
+	}
+}
+
+// Text returns the text of a message, from the catalog of the printer or
+// from English.
+func (p *Printer) Text(id ID, args ...interface{}) string {
+	format, ok := p.Catalog[id]
+	if !ok {
+		format, ok = English[id]
+	}
+	if !ok {
+		return fmt.Sprintf("%s: %s", id, fmt.Sprint(args...))
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// jsonArgs returns the arguments of a message with errors and stringers as
+// their strings, which JSON would otherwise lose.
+func jsonArgs(args []interface{}) []interface{} {
+	out := make([]interface{}, len(args))
+	for i, a := range args {
+		switch a := a.(type) {
+		case error:
+			out[i] = a.Error()
+		case fmt.Stringer:
+			out[i] = a.String()
+		default:
+			out[i] = a
+		}
+	}
+	return out
+}
@@ -0,0 +1,132 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package messages
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPrinter(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbosity Verbosity
+		format    Format
+		out, err  string
+	}{{
+		name:   "normal",
+		format: Text,
+		out:    "\"stable\" has been added to your repositories\n",
+		err:    "WARNING: chart is deprecated\n",
+	}, {
+		name:      "quiet",
+		verbosity: Quiet,
+		format:    Text,
+		err:       "WARNING: chart is deprecated\n",
+	}, {
+		name:      "verbose",
+		verbosity: Verbose,
+		format:    Text,
+		out:       "\"stable\" has been added to your repositories\n",
+		err:       "Using the configuration profile staging\nWARNING: chart is deprecated\n",
+	}, {
+		name:   "json",
+		format: JSON,
+		err: `{"level":"info","id":"RepoAdded","message":"\"stable\" has been added to your repositories","args":["stable"]}
+{"level":"warning","id":"ActionWarning","message":"chart is deprecated","args":["DeprecatedChart","chart is deprecated"]}
+`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, err bytes.Buffer
+			p := NewPrinter(&out, &err, English)
+			p.Verbosity = tt.verbosity
+			p.Format = tt.format
+
+			p.Info(RepoAdded, "stable")
+			p.Verbose(UsingProfile, "staging")
+			p.Warning(ActionWarning, "DeprecatedChart", "chart is deprecated")
+
+			if out.String() != tt.out {
+				t.Errorf("Expected out %q, got %q", tt.out, out.String())
+			}
+			if err.String() != tt.err {
+				t.Errorf("Expected err %q, got %q", tt.err, err.String())
+			}
+		})
+	}
+}
+
+func TestPrinterJSONArgs(t *testing.T) {
+	var out bytes.Buffer
+	p := NewPrinter(&out, &out, English)
+	p.Format = JSON
+	p.Info(RepoUpdateFailed, "stable", "https://example.com", errors.New("not found"))
+
+	expect := `{"level":"info","id":"RepoUpdateFailed","message":"...Unable to get an update from the \"stable\" chart repository (https://example.com):\n\tnot found","args":["stable","https://example.com","not found"]}` + "\n"
+	if out.String() != expect {
+		t.Errorf("Expected %q, got %q", expect, out.String())
+	}
+}
+
+func TestText(t *testing.T) {
+	p := NewPrinter(nil, nil, Catalog{RepoAdded: "%q a été ajouté à vos dépôts"})
+	if got := p.Text(RepoAdded, "stable"); got != `"stable" a été ajouté à vos dépôts` {
+		t.Errorf("Unexpected translation %q", got)
+	}
+	if got := p.Text(RepoRemoved, "stable"); got != `"stable" has been removed from your repositories` {
+		t.Errorf("Expected a fallback to English, got %q", got)
+	}
+	if got := p.Text("Unknown", "stable"); got != "Unknown: stable" {
+		t.Errorf("Unexpected text of an unknown message %q", got)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	fr := Catalog{RepoAdded: "%q a été ajouté à vos dépôts"}
+	Register("fr", fr)
+	defer func() {
+		catalogsMu.Lock()
+		delete(catalogs, "fr")
+		catalogsMu.Unlock()
+	}()
+
+	for locale, expect := range map[string]Catalog{
+		"fr":          fr,
+		"fr_CA.UTF-8": fr,
+		"fr-BE":       fr,
+		"en_US.UTF-8": English,
+		"de_DE":       English,
+		"C":           English,
+	} {
+		if got := Lookup(locale); got[RepoAdded] != expect[RepoAdded] {
+			t.Errorf("Unexpected catalog for %s: %v", locale, got)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for s, expect := range map[string]Format{"": Text, "text": Text, "json": JSON} {
+		if got, err := ParseFormat(s); err != nil || got != expect {
+			t.Errorf("ParseFormat(%q) = %q, %v", s, got, err)
+		}
+	}
+	if _, err := ParseFormat("yaml"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mitchellh/copystructure"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/helmpath"
+)
+
+// RedactedValue replaces the values redacted by a profile.
+const RedactedValue = "REDACTED"
+
+var profileNameRegexp = regexp.MustCompile(`^[A-Za-z0-9][-A-Za-z0-9_.]*$`)
+
+// Profile bundles client-side defaults for a cluster or a namespace, such as
+// the production cluster. Profiles are stored in the profiles directory of
+// the Helm configuration, one YAML file per profile, and activated by name
+// with '--profile' or $HELM_PROFILE.
+type Profile struct {
+	// Name is the name of the profile, set from the name of its file.
+	Name string `json:"-"`
+	// KubeContext is the kubeconfig context used unless one is given.
+	KubeContext string `json:"kubeContext,omitempty"`
+	// Namespace is the namespace used unless one is given.
+	Namespace string `json:"namespace,omitempty"`
+	// Wait makes the release operations wait for the resources to be ready
+	// unless '--wait' is given.
+	Wait bool `json:"wait,omitempty"`
+	// WaitForJobs makes the release operations wait for the Jobs to complete
+	// unless '--wait-for-jobs' is given.
+	WaitForJobs bool `json:"waitForJobs,omitempty"`
+	// Timeout is the timeout of the release operations unless '--timeout' is
+	// given. Zero keeps the default of the operations.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+	// PostRenderer is the path to the executable used for post rendering
+	// unless '--post-renderer' is given.
+	PostRenderer string `json:"postRenderer,omitempty"`
+	// Redact lists the dotted paths of the values hidden when the values of
+	// releases are printed, such as 'database.password'.
+	Redact []string `json:"redact,omitempty"`
+}
+
+// ProfilePath returns the path to the file of the named profile.
+func ProfilePath(name string) string {
+	return helmpath.ConfigPath("profiles", name+".yaml")
+}
+
+// LoadProfile loads the named profile from the profiles directory.
+func LoadProfile(name string) (*Profile, error) {
+	if !profileNameRegexp.MatchString(name) {
+		return nil, errors.Errorf("invalid profile name %q", name)
+	}
+	p, err := LoadProfileFile(ProfilePath(name))
+	if err != nil {
+		return nil, err
+	}
+	p.Name = name
+	return p, nil
+}
+
+// LoadProfileFile loads a profile from a file. Its name is the name of the
+// file, without the extension.
+func LoadProfileFile(path string) (*Profile, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't load profile")
+	}
+	p := &Profile{}
+	if err := yaml.UnmarshalStrict(b, p); err != nil {
+		return nil, errors.Wrapf(err, "invalid profile %s", path)
+	}
+	p.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return p, nil
+}
+
+// ApplyProfile sets the kube context and the namespace of the settings to
+// the ones of the profile, unless they are already set by flags or
+// environment variables.
+func (s *EnvSettings) ApplyProfile(p *Profile) {
+	if s.KubeContext == "" {
+		s.KubeContext = p.KubeContext
+	}
+	if s.namespace == "" {
+		s.namespace = p.Namespace
+	}
+}
+
+// RedactValues returns a copy of the values in which the values at the paths
+// listed by the profile are replaced with RedactedValue. The values are
+// returned as is if the profile redacts nothing.
+func (p *Profile) RedactValues(vals map[string]interface{}) (map[string]interface{}, error) {
+	if len(p.Redact) == 0 || len(vals) == 0 {
+		return vals, nil
+	}
+	c, err := copystructure.Copy(vals)
+	if err != nil {
+		return nil, err
+	}
+	redacted := c.(map[string]interface{})
+	for _, path := range p.Redact {
+		redactPath(redacted, strings.Split(path, "."))
+	}
+	return redacted, nil
+}
+
+func redactPath(vals map[string]interface{}, keys []string) {
+	v, ok := vals[keys[0]]
+	if !ok {
+		return
+	}
+	if len(keys) == 1 {
+		vals[keys[0]] = RedactedValue
+		return
+	}
+	if next, ok := v.(map[string]interface{}); ok {
+		redactPath(next, keys[1:])
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"helm.sh/helm/v3/pkg/helmpath"
+)
+
+func TestLoadProfile(t *testing.T) {
+	defer resetEnv()()
+	os.Setenv(helmpath.ConfigHomeEnvVar, "testdata")
+
+	p, err := LoadProfile("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "prod" || p.KubeContext != "prod" || p.Namespace != "payments" {
+		t.Errorf("unexpected profile %+v", p)
+	}
+	if !p.Wait || p.WaitForJobs || p.Timeout.Duration != 10*time.Minute {
+		t.Errorf("unexpected wait defaults: wait=%t wait-for-jobs=%t timeout=%s", p.Wait, p.WaitForJobs, p.Timeout.Duration)
+	}
+	if p.PostRenderer != "kustomize-prod" {
+		t.Errorf("expected post-renderer kustomize-prod, got %q", p.PostRenderer)
+	}
+
+	if _, err := LoadProfile("typo"); err == nil {
+		t.Error("expected an error for a profile with an unknown field")
+	}
+	if _, err := LoadProfile("missing"); err == nil {
+		t.Error("expected an error for a missing profile")
+	}
+	if _, err := LoadProfile("../prod"); err == nil {
+		t.Error("expected an error for a profile name with a path")
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	defer resetEnv()()
+
+	p := &Profile{KubeContext: "prod", Namespace: "payments"}
+
+	s := New()
+	s.ApplyProfile(p)
+	if s.KubeContext != "prod" || s.namespace != "payments" {
+		t.Errorf("expected the profile's context and namespace, got %q and %q", s.KubeContext, s.namespace)
+	}
+
+	os.Setenv("HELM_KUBECONTEXT", "staging")
+	os.Setenv("HELM_NAMESPACE", "search")
+	s = New()
+	s.ApplyProfile(p)
+	if s.KubeContext != "staging" || s.namespace != "search" {
+		t.Errorf("expected the environment to win over the profile, got %q and %q", s.KubeContext, s.namespace)
+	}
+}
+
+func TestRedactValues(t *testing.T) {
+	p := &Profile{Redact: []string{"database.password", "token", "missing.key"}}
+	vals := map[string]interface{}{
+		"token": "s3cr3t",
+		"database": map[string]interface{}{
+			"user":     "app",
+			"password": "hunter2",
+		},
+	}
+
+	redacted, err := p.RedactValues(vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]interface{}{
+		"token": RedactedValue,
+		"database": map[string]interface{}{
+			"user":     "app",
+			"password": RedactedValue,
+		},
+	}
+	if !reflect.DeepEqual(redacted, expect) {
+		t.Errorf("expected %v, got %v", expect, redacted)
+	}
+	if vals["token"] != "s3cr3t" {
+		t.Error("expected the original values to be left alone")
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"encoding/json"
+	"net/url"
+	"path"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// parseValues parses the contents of a values file into a map. Files with a
+// .json, .toml or .cue extension are converted to the form YAML values take,
+// anything else is read as YAML.
+func parseValues(filePath string, data []byte) (map[string]interface{}, error) {
+	var err error
+	switch valuesFormat(filePath) {
+	case ".toml":
+		data, err = tomlToJSON(data)
+	case ".cue":
+		data, err = cueToJSON(filePath, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is YAML, so the converted values take the same path as YAML ones
+	// and end up with the same types for numbers, lists and maps.
+	currentMap := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &currentMap); err != nil {
+		return nil, err
+	}
+	return currentMap, nil
+}
+
+// valuesFormat returns the lower cased extension of a values file, or of the
+// path of a values URL.
+func valuesFormat(filePath string) string {
+	p := filePath
+	if u, err := url.Parse(filePath); err == nil && u.Scheme != "" {
+		p = u.Path
+	}
+	return strings.ToLower(path.Ext(p))
+}
+
+func tomlToJSON(data []byte) ([]byte, error) {
+	m := map[string]interface{}{}
+	if err := toml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+// cueToJSON evaluates a CUE file and exports it as JSON. All values must be
+// concrete, as they would have to be for `cue export`.
+func cueToJSON(filePath string, data []byte) ([]byte, error) {
+	var r cue.Runtime
+	inst, err := r.Compile(filePath, data)
+	if err != nil {
+		return nil, err
+	}
+	v := inst.Value()
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return nil, errors.Wrap(err, "values must be concrete")
+	}
+	return v.MarshalJSON()
+}
@@ -0,0 +1,73 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseValuesFormats(t *testing.T) {
+	expect := map[string]interface{}{
+		"name":     "web",
+		"replicas": float64(3),
+		"enabled":  true,
+		"image": map[string]interface{}{
+			"tag": "1.2.0",
+		},
+		"ports": []interface{}{float64(80), float64(443)},
+	}
+
+	tests := []struct {
+		path string
+		data string
+	}{
+		{"values.yaml", "name: web\nreplicas: 3\nenabled: true\nimage:\n  tag: 1.2.0\nports: [80, 443]\n"},
+		{"values.json", `{"name": "web", "replicas": 3, "enabled": true, "image": {"tag": "1.2.0"}, "ports": [80, 443]}`},
+		{"values.TOML", "name = \"web\"\nreplicas = 3\nenabled = true\nports = [80, 443]\n\n[image]\ntag = \"1.2.0\"\n"},
+		{"values.cue", "name: \"web\"\nreplicas: 1 + 2\nenabled: true\nimage: tag: \"1.2.0\"\nports: [80, 443]\n"},
+		{"https://example.com/charts/values.toml?ref=main", "name = \"web\"\nreplicas = 3\nenabled = true\nports = [80, 443]\n\n[image]\ntag = \"1.2.0\"\n"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseValues(tt.path, []byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("%s: expected %v, got %v", tt.path, expect, got)
+		}
+	}
+}
+
+func TestParseValuesErrors(t *testing.T) {
+	tests := []struct {
+		path string
+		data string
+	}{
+		{"values.json", `{"name": `},
+		{"values.toml", "name = "},
+		{"values.cue", "replicas: int\n"},
+	}
+
+	for _, tt := range tests {
+		if _, err := parseValues(tt.path, []byte(tt.data)); err == nil {
+			t.Errorf("%s: expected an error", tt.path)
+		}
+	}
+}
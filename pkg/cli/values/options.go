@@ -23,85 +23,131 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
-	"sigs.k8s.io/yaml"
 
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/strvals"
 )
 
 type Options struct {
-	ValueFiles   []string
-	StringValues []string
-	Values       []string
-	FileValues   []string
+	ValueFiles    []string
+	StringValues  []string
+	Values        []string
+	FileValues    []string
+	JSONValues    []string
+	LiteralValues []string
 }
 
 // MergeValues merges values from files specified via -f/--values and directly
-// via --set, --set-string, or --set-file, marshaling them to YAML
+// via --set, --set-string, --set-file, --set-json or --set-literal, marshaling them to YAML. Values files
+// may also be written in JSON, TOML or CUE, as told by their extension.
 func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, error) {
+	base, _, err := opts.MergeValuesWithSources(p)
+	return base, err
+}
+
+// MergeValuesWithSources merges values like MergeValues, and also returns the
+// values supplied by each values file and by each of --set-json, --set,
+// --set-string, --set-file and --set-literal, in increasing order of
+// precedence.
+func (opts *Options) MergeValuesWithSources(p getter.Providers) (map[string]interface{}, []chartutil.ValuesSource, error) {
 	base := map[string]interface{}{}
+	var sources []chartutil.ValuesSource
 
 	// User specified a values files via -f/--values
 	for _, filePath := range opts.ValueFiles {
-		currentMap := map[string]interface{}{}
-
 		bytes, err := readFile(filePath, p)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		if err := yaml.Unmarshal(bytes, &currentMap); err != nil {
-			return nil, errors.Wrapf(err, "failed to parse %s", filePath)
+		currentMap, err := parseValues(filePath, bytes)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to parse %s", filePath)
 		}
+		sources = append(sources, chartutil.ValuesSource{Name: filePath, Values: currentMap})
 		// Merge with the previous map
-		base = mergeMaps(base, currentMap)
+		base = chartutil.MergeTables(base, currentMap)
+	}
+
+	// User specified a value via --set-json. Objects are deep merged into the
+	// values set so far.
+	if len(opts.JSONValues) > 0 {
+		current, err := parseSource(opts.JSONValues, base, strvals.ParseJSON)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed parsing --set-json data")
+		}
+		sources = append(sources, chartutil.ValuesSource{Name: "--set-json", Values: current})
 	}
 
 	// User specified a value via --set
-	for _, value := range opts.Values {
-		if err := strvals.ParseInto(value, base); err != nil {
-			return nil, errors.Wrap(err, "failed parsing --set data")
+	if len(opts.Values) > 0 {
+		current, err := parseSource(opts.Values, base, strvals.ParseInto)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed parsing --set data")
 		}
+		sources = append(sources, chartutil.ValuesSource{Name: "--set", Values: current})
 	}
 
 	// User specified a value via --set-string
-	for _, value := range opts.StringValues {
-		if err := strvals.ParseIntoString(value, base); err != nil {
-			return nil, errors.Wrap(err, "failed parsing --set-string data")
+	if len(opts.StringValues) > 0 {
+		current, err := parseSource(opts.StringValues, base, strvals.ParseIntoString)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed parsing --set-string data")
 		}
+		sources = append(sources, chartutil.ValuesSource{Name: "--set-string", Values: current})
 	}
 
 	// User specified a value via --set-file
-	for _, value := range opts.FileValues {
+	if len(opts.FileValues) > 0 {
+		// Files are only read once, as they may be read from stdin
+		read := map[string]string{}
 		reader := func(rs []rune) (interface{}, error) {
+			if data, ok := read[string(rs)]; ok {
+				return data, nil
+			}
 			bytes, err := readFile(string(rs), p)
+			read[string(rs)] = string(bytes)
 			return string(bytes), err
 		}
-		if err := strvals.ParseIntoFile(value, base, reader); err != nil {
-			return nil, errors.Wrap(err, "failed parsing --set-file data")
+		current, err := parseSource(opts.FileValues, base, func(value string, dest map[string]interface{}) error {
+			return strvals.ParseIntoFile(value, dest, reader)
+		})
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed parsing --set-file data")
+		}
+		sources = append(sources, chartutil.ValuesSource{Name: "--set-file", Values: current})
+	}
+
+	// User specified a value via --set-literal
+	if len(opts.LiteralValues) > 0 {
+		current, err := parseSource(opts.LiteralValues, base, strvals.ParseLiteralInto)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed parsing --set-literal data")
 		}
+		sources = append(sources, chartutil.ValuesSource{Name: "--set-literal", Values: current})
 	}
 
-	return base, nil
+	return base, sources, nil
 }
 
-func mergeMaps(a, b map[string]interface{}) map[string]interface{} {
-	out := make(map[string]interface{}, len(a))
-	for k, v := range a {
-		out[k] = v
-	}
-	for k, v := range b {
-		if v, ok := v.(map[string]interface{}); ok {
-			if bv, ok := out[k]; ok {
-				if bv, ok := bv.(map[string]interface{}); ok {
-					out[k] = mergeMaps(bv, v)
-					continue
-				}
-			}
+// parseSource parses the values of a flag into base, and returns the values
+// set by the flag alone.
+//
+// Each value is parsed into base rather than merged into it, as an index such
+// as list[1] sets an element of the list already in base instead of replacing
+// the list.
+func parseSource(values []string, base map[string]interface{}, parse func(string, map[string]interface{}) error) (map[string]interface{}, error) {
+	current := map[string]interface{}{}
+	for _, value := range values {
+		if err := parse(value, base); err != nil {
+			return nil, err
+		}
+		if err := parse(value, current); err != nil {
+			return nil, err
 		}
-		out[k] = v
 	}
-	return out
+	return current, nil
 }
 
 // readFile load a file from stdin, the local directory, or a remote file with a url.
@@ -21,57 +21,59 @@ import (
 	"testing"
 )
 
-func TestMergeValues(t *testing.T) {
-	nestedMap := map[string]interface{}{
-		"foo": "bar",
-		"baz": map[string]string{
-			"cool": "stuff",
-		},
+func TestMergeValuesSetJSON(t *testing.T) {
+	opts := Options{
+		Values:        []string{"image.pullPolicy=Always"},
+		JSONValues:    []string{`image={"tag": "1.0"}`, `image={"repository": "nginx"},ports=[80]`},
+		LiteralValues: []string{"args[0]=--flag=a,b"},
 	}
-	anotherNestedMap := map[string]interface{}{
-		"foo": "bar",
-		"baz": map[string]string{
-			"cool":    "things",
-			"awesome": "stuff",
-		},
+	vals, sources, err := opts.MergeValuesWithSources(nil)
+	if err != nil {
+		t.Fatal(err)
 	}
-	flatMap := map[string]interface{}{
-		"foo": "bar",
-		"baz": "stuff",
+
+	expect := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.0",
+			"pullPolicy": "Always",
+		},
+		"ports": []interface{}{float64(80)},
+		"args":  []interface{}{"--flag=a,b"},
 	}
-	anotherFlatMap := map[string]interface{}{
-		"testing": "fun",
+	if !reflect.DeepEqual(vals, expect) {
+		t.Errorf("Expected %v, got %v", expect, vals)
 	}
 
-	testMap := mergeMaps(flatMap, nestedMap)
-	equal := reflect.DeepEqual(testMap, nestedMap)
-	if !equal {
-		t.Errorf("Expected a nested map to overwrite a flat value. Expected: %v, got %v", nestedMap, testMap)
+	var names []string
+	for _, s := range sources {
+		names = append(names, s.Name)
 	}
-
-	testMap = mergeMaps(nestedMap, flatMap)
-	equal = reflect.DeepEqual(testMap, flatMap)
-	if !equal {
-		t.Errorf("Expected a flat value to overwrite a map. Expected: %v, got %v", flatMap, testMap)
+	if expect := []string{"--set-json", "--set", "--set-literal"}; !reflect.DeepEqual(names, expect) {
+		t.Errorf("Expected sources %v, got %v", expect, names)
 	}
+}
 
-	testMap = mergeMaps(nestedMap, anotherNestedMap)
-	equal = reflect.DeepEqual(testMap, anotherNestedMap)
-	if !equal {
-		t.Errorf("Expected a nested map to overwrite another nested map. Expected: %v, got %v", anotherNestedMap, testMap)
+func TestMergeValuesSetIndexes(t *testing.T) {
+	opts := Options{
+		Values:       []string{"args[0]=a", "args[1]=b"},
+		StringValues: []string{"args[2]=c"},
+	}
+	vals, sources, err := opts.MergeValuesWithSources(nil)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	testMap = mergeMaps(anotherFlatMap, anotherNestedMap)
-	expectedMap := map[string]interface{}{
-		"testing": "fun",
-		"foo":     "bar",
-		"baz": map[string]string{
-			"cool":    "things",
-			"awesome": "stuff",
-		},
+	// Indexes set elements of the list set so far
+	if expect := []interface{}{"a", "b", "c"}; !reflect.DeepEqual(vals["args"], expect) {
+		t.Errorf("Expected %v, got %v", expect, vals["args"])
 	}
-	equal = reflect.DeepEqual(testMap, expectedMap)
-	if !equal {
-		t.Errorf("Expected a map with different keys to merge properly with another map. Expected: %v, got %v", expectedMap, testMap)
+	if expect := []interface{}{"a", "b"}; len(sources) != 2 || !reflect.DeepEqual(sources[0].Values["args"], expect) {
+		t.Errorf("Expected the --set source to hold %v, got %v", expect, sources)
+	}
+
+	opts = Options{Values: []string{"args[0]=a", "args[=b"}}
+	if _, _, err := opts.MergeValuesWithSources(nil); err == nil {
+		t.Error("Expected an error for invalid --set data")
 	}
 }
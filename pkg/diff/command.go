@@ -0,0 +1,93 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Command renders the changes with an external command, such as dyff or
+// 'diff -u'.
+//
+// The current and target manifests of the changed resources are written to
+// two files, as streams of YAML documents in the same order, whose paths are
+// given to the command after its arguments. Its output is copied to the
+// output of the renderer. As diff tools exit with status 1 when the files
+// differ, only the statuses above 1 are errors.
+type Command struct {
+	Name string
+	Args []string
+	// Stderr receives the error output of the command. It defaults to
+	// os.Stderr.
+	Stderr io.Writer
+}
+
+// Render implements Renderer.
+func (c *Command) Render(out io.Writer, diffs []ResourceDiff) error {
+	dir, err := ioutil.TempDir("", "helm-diff-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	var before, after bytes.Buffer
+	for _, d := range diffs {
+		writeDocument(&before, d.Before)
+		writeDocument(&after, d.After)
+	}
+	current := filepath.Join(dir, "current.yaml")
+	target := filepath.Join(dir, "target.yaml")
+	if err := ioutil.WriteFile(current, before.Bytes(), 0600); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(target, after.Bytes(), 0600); err != nil {
+		return err
+	}
+
+	cmd := exec.Command(c.Name, append(append([]string{}, c.Args...), current, target)...)
+	cmd.Stdout = out
+	cmd.Stderr = c.Stderr
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return nil
+		}
+		return errors.Wrapf(err, "diff renderer %q failed", strings.Join(append([]string{c.Name}, c.Args...), " "))
+	}
+	return nil
+}
+
+func writeDocument(b *bytes.Buffer, doc string) {
+	if doc == "" {
+		return
+	}
+	b.WriteString("---\n")
+	b.WriteString(doc)
+	if !strings.HasSuffix(doc, "\n") {
+		b.WriteString("\n")
+	}
+}
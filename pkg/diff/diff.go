@@ -0,0 +1,145 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package diff renders the changes of the resources of a release.
+
+The changes are computed by the actions previewing an operation, such as an
+upgrade or a rollback, as a list of ResourceDiff. A Renderer writes them out in
+a given format:
+
+	r, err := diff.NewRenderer("side-by-side")
+	if err != nil {
+		return err
+	}
+	return r.Render(os.Stdout, diffs)
+
+Besides the built-in renderers, external tools such as dyff can be used with a
+spec of the form 'external:<command>', and other renderers can be registered by
+name with Register.
+*/
+package diff // import "helm.sh/helm/v3/pkg/diff"
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mattn/go-shellwords"
+	"github.com/pkg/errors"
+)
+
+// Changes of the resources of a release.
+const (
+	// Create is the change of a resource that is only in the target manifest.
+	Create = "create"
+	// Update is the change of a resource that differs between the manifests.
+	Update = "update"
+	// Delete is the change of a resource that is only in the current
+	// manifest.
+	Delete = "delete"
+)
+
+// ExternalPrefix starts the renderer specs running an external command.
+const ExternalPrefix = "external:"
+
+// ResourceDiff is the change of a resource between the current manifest of a
+// release and its target manifest.
+type ResourceDiff struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Change is one of Create, Update or Delete
+	Change string `json:"change"`
+	// Before and After are the YAML documents of the resource in the current
+	// and target manifests. Before is empty for the resources to create, and
+	// After for those to delete.
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// ID identifies the resource, as in 'default/Deployment/web'.
+func (d ResourceDiff) ID() string {
+	return d.Namespace + "/" + d.Kind + "/" + d.Name
+}
+
+// Renderer writes the changes of the resources of a release.
+type Renderer interface {
+	Render(out io.Writer, diffs []ResourceDiff) error
+}
+
+// RendererFunc is a function that implements Renderer.
+type RendererFunc func(out io.Writer, diffs []ResourceDiff) error
+
+// Render calls f(out, diffs).
+func (f RendererFunc) Render(out io.Writer, diffs []ResourceDiff) error {
+	return f(out, diffs)
+}
+
+var renderers = map[string]func() Renderer{
+	"unified":      func() Renderer { return &Unified{Context: 3} },
+	"side-by-side": func() Renderer { return &SideBySide{Width: 160} },
+	"json-patch":   func() Renderer { return &JSONPatch{} },
+}
+
+// Register makes a renderer available by name to NewRenderer, replacing any
+// renderer registered with the same name. It is meant to be called from init
+// functions, as it is not safe for concurrent use.
+func Register(name string, factory func() Renderer) {
+	renderers[name] = factory
+}
+
+// Names returns the names of the registered renderers, sorted.
+func Names() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewRenderer returns the renderer for the given spec: the name of a
+// registered renderer, or 'external:' followed by a command line, such as
+// 'external:dyff between --omit-header'. An empty spec gives the unified
+// renderer.
+func NewRenderer(spec string) (Renderer, error) {
+	if spec == "" {
+		spec = "unified"
+	}
+	if strings.HasPrefix(spec, ExternalPrefix) {
+		args, err := shellwords.Parse(strings.TrimPrefix(spec, ExternalPrefix))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid diff renderer %q", spec)
+		}
+		if len(args) == 0 {
+			return nil, errors.Errorf("invalid diff renderer %q: no command given", spec)
+		}
+		return &Command{Name: args[0], Args: args[1:]}, nil
+	}
+	factory, ok := renderers[spec]
+	if !ok {
+		return nil, errors.Errorf("unknown diff renderer %q: use one of %s, or %s<command>",
+			spec, strings.Join(Names(), ", "), ExternalPrefix)
+	}
+	return factory(), nil
+}
+
+// header returns the line introducing the change of a resource.
+func header(d ResourceDiff) string {
+	return fmt.Sprintf("%s (%s)", d.ID(), d.Change)
+}
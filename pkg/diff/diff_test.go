@@ -0,0 +1,291 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var testDiffs = []ResourceDiff{
+	{
+		Kind:      "ConfigMap",
+		Name:      "settings",
+		Namespace: "default",
+		Change:    Update,
+		Before:    "data:\n  a: \"1\"\n  b: \"2\"\n  c: \"3\"\nkind: ConfigMap\n",
+		After:     "data:\n  a: \"1\"\n  b: \"20\"\n  c: \"3\"\nkind: ConfigMap\n",
+	},
+	{
+		Kind:      "Service",
+		Name:      "web",
+		Namespace: "default",
+		Change:    Create,
+		After:     "kind: Service\n",
+	},
+}
+
+func TestUnified(t *testing.T) {
+	var out bytes.Buffer
+	if err := (&Unified{Context: 1}).Render(&out, testDiffs); err != nil {
+		t.Fatal(err)
+	}
+	expected := `--- a/default/ConfigMap/settings
++++ b/default/ConfigMap/settings
+@@ -2,3 +2,3 @@
+   a: "1"
+-  b: "2"
++  b: "20"
+   c: "3"
+--- /dev/null
++++ b/default/Service/web
+@@ -0,0 +1 @@
++kind: Service
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestText(t *testing.T) {
+	if d := Text("a", "b", "same\n", "same\n", 3); d != "" {
+		t.Errorf("expected no diff for equal texts, got %q", d)
+	}
+	expected := "--- a\n+++ b\n@@ -1,2 +1,2 @@\n keep\n-old\n+new\n"
+	if d := Text("a", "b", "keep\nold\n", "keep\nnew\n", 3); d != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, d)
+	}
+}
+
+func TestHunks(t *testing.T) {
+	a := SplitLines("1\n2\n3\n4\n5\n6\n7\n8\n9\n")
+	b := SplitLines("1\nX\n3\n4\n5\n6\n7\nY\n9\n")
+
+	// Changes further apart than twice the context get their own hunk
+	hs := hunks(Lines(a, b), 2)
+	if len(hs) != 2 || hs[0].fromRange() != "1,4" || hs[1].fromRange() != "6,4" {
+		t.Errorf("expected two hunks, got %+v", hs)
+	}
+	hs = hunks(Lines(a, b), 3)
+	if len(hs) != 1 || hs[0].fromRange() != "1,9" || hs[0].toRange() != "1,9" {
+		t.Errorf("expected a single hunk, got %+v", hs)
+	}
+}
+
+func TestLines(t *testing.T) {
+	ops := Lines(SplitLines("a\nb\nc\nd\n"), SplitLines("a\nx\nc\nd\ny\n"))
+	expected := []Op{{Equal, "a"}, {Removed, "b"}, {Added, "x"}, {Equal, "c"}, {Equal, "d"}, {Added, "y"}}
+	if !reflect.DeepEqual(ops, expected) {
+		t.Errorf("expected %v, got %v", expected, ops)
+	}
+}
+
+func TestLinesLarge(t *testing.T) {
+	// Change every seventh line and append one, so that the texts share
+	// neither a long prefix nor a long suffix
+	const n = 5000
+	var a, b []string
+	for i := 0; i < n; i++ {
+		line := "line " + strconv.Itoa(i)
+		a = append(a, line)
+		if i%7 == 0 {
+			line += " changed"
+		}
+		b = append(b, line)
+	}
+	b = append(b, "appended")
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	ops := Lines(a, b)
+	runtime.ReadMemStats(&after)
+
+	// A table of the common subsequences of all prefixes would take 200MB
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 16<<20 {
+		t.Errorf("expected the diff to take less than 16MB, took %d bytes", alloc)
+	}
+
+	var from, to []string
+	equal := 0
+	for _, op := range ops {
+		if op.Kind != Added {
+			from = append(from, op.Line)
+		}
+		if op.Kind != Removed {
+			to = append(to, op.Line)
+		}
+		if op.Kind == Equal {
+			equal++
+		}
+	}
+	if !reflect.DeepEqual(from, a) || !reflect.DeepEqual(to, b) {
+		t.Fatal("expected the edit script to turn the first text into the second")
+	}
+	if expected := n - n/7 - 1; equal != expected {
+		t.Errorf("expected %d unchanged lines, got %d", expected, equal)
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	var out bytes.Buffer
+	if err := (&SideBySide{Width: 33}).Render(&out, testDiffs[:1]); err != nil {
+		t.Fatal(err)
+	}
+	expected := `default/ConfigMap/settings (update)
+data:             data:
+  a: "1"            a: "1"
+  b: "2"        |   b: "20"
+  c: "3"            c: "3"
+kind: ConfigMap   kind: ConfigMap
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	var out bytes.Buffer
+	if err := (JSONPatch{}).Render(&out, testDiffs); err != nil {
+		t.Fatal(err)
+	}
+	expected := `[
+  {
+    "kind": "ConfigMap",
+    "name": "settings",
+    "namespace": "default",
+    "change": "update",
+    "patch": [
+      {
+        "op": "replace",
+        "path": "/data/b",
+        "value": "20"
+      }
+    ]
+  },
+  {
+    "kind": "Service",
+    "name": "web",
+    "namespace": "default",
+    "change": "create",
+    "patch": [
+      {
+        "op": "add",
+        "path": "",
+        "value": {
+          "kind": "Service"
+        }
+      }
+    ]
+  }
+]
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestCreatePatch(t *testing.T) {
+	a := map[string]interface{}{
+		"gone":  "x",
+		"a/b":   1.0,
+		"list":  []interface{}{"a", "b"},
+		"other": []interface{}{"a"},
+	}
+	b := map[string]interface{}{
+		"new":   nil,
+		"a/b":   2.0,
+		"list":  []interface{}{"a", "c"},
+		"other": []interface{}{"a", "b"},
+	}
+	expected := []PatchOperation{
+		{Op: "remove", Path: "/gone"},
+		{Op: "replace", Path: "/a~1b", Value: 2.0},
+		{Op: "replace", Path: "/list/1", Value: "c"},
+		{Op: "add", Path: "/new", Value: nil},
+		{Op: "replace", Path: "/other", Value: []interface{}{"a", "b"}},
+	}
+	if ops := CreatePatch(a, b); !reflect.DeepEqual(ops, expected) {
+		t.Errorf("expected %v, got %v", expected, ops)
+	}
+}
+
+func TestNewRenderer(t *testing.T) {
+	for spec, expected := range map[string]Renderer{
+		"":                                    &Unified{Context: 3},
+		"unified":                             &Unified{Context: 3},
+		"side-by-side":                        &SideBySide{Width: 160},
+		"json-patch":                          &JSONPatch{},
+		"external:dyff between --omit-header": &Command{Name: "dyff", Args: []string{"between", "--omit-header"}},
+	} {
+		r, err := NewRenderer(spec)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(r, expected) {
+			t.Errorf("%q: expected %#v, got %#v", spec, expected, r)
+		}
+	}
+
+	for _, spec := range []string{"nope", "external:", "external:'dyff"} {
+		if _, err := NewRenderer(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+
+	Register("count", func() Renderer {
+		return RendererFunc(func(out io.Writer, diffs []ResourceDiff) error {
+			_, err := io.WriteString(out, strings.Repeat(".", len(diffs)))
+			return err
+		})
+	})
+	defer delete(renderers, "count")
+	r, err := NewRenderer("count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := r.Render(&out, testDiffs); err != nil || out.String() != ".." {
+		t.Errorf("expected the registered renderer to be used, got %q, %v", out.String(), err)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cat is not available on Windows")
+	}
+	var out bytes.Buffer
+	if err := (&Command{Name: "cat"}).Render(&out, testDiffs); err != nil {
+		t.Fatal(err)
+	}
+	expected := "---\n" + testDiffs[0].Before + "---\n" + testDiffs[0].After + "---\n" + testDiffs[1].After
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	if err := (&Command{Name: "sh", Args: []string{"-c", "exit 1"}}).Render(&out, testDiffs); err != nil {
+		t.Errorf("expected exit status 1 to report differences, got %s", err)
+	}
+	if err := (&Command{Name: "sh", Args: []string{"-c", "exit 2"}, Stderr: &out}).Render(&out, testDiffs); err == nil {
+		t.Error("expected exit status 2 to fail")
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import "strings"
+
+// Kinds of the operations of a line diff.
+const (
+	Equal   byte = ' '
+	Removed byte = '-'
+	Added   byte = '+'
+)
+
+// Op is an operation of a line diff: a line kept, removed or added.
+type Op struct {
+	Kind byte
+	Line string
+}
+
+// Lines computes an edit script from a to b using the longest common
+// subsequence of lines. The subsequence is found with Hirschberg's algorithm,
+// which needs space linear in the number of lines, after skipping the lines
+// both texts start and end with.
+func Lines(a, b []string) []Op {
+	ids := make(map[string]int)
+	d := &lineDiff{a: a, b: b, x: lineIDs(ids, a), y: lineIDs(ids, b)}
+	d.diff(0, len(a), 0, len(b))
+	return d.ops
+}
+
+// lineIDs numbers the distinct lines, so that lines are compared as integers.
+func lineIDs(ids map[string]int, lines []string) []int {
+	xs := make([]int, len(lines))
+	for i, line := range lines {
+		id, ok := ids[line]
+		if !ok {
+			id = len(ids)
+			ids[line] = id
+		}
+		xs[i] = id
+	}
+	return xs
+}
+
+// lineDiff collects the edit script from the lines a to b, numbered as x and
+// y.
+type lineDiff struct {
+	a, b []string
+	x, y []int
+	ops  []Op
+}
+
+// diff appends the edit script from a[alo:ahi] to b[blo:bhi]. Lines removed
+// from a come before the lines added in their place.
+func (d *lineDiff) diff(alo, ahi, blo, bhi int) {
+	for alo < ahi && blo < bhi && d.x[alo] == d.y[blo] {
+		d.add(Equal, d.a, alo, alo+1)
+		alo++
+		blo++
+	}
+	suffix := 0
+	for alo < ahi-suffix && blo < bhi-suffix && d.x[ahi-suffix-1] == d.y[bhi-suffix-1] {
+		suffix++
+	}
+	ahi -= suffix
+	bhi -= suffix
+
+	switch {
+	case alo == ahi || blo == bhi:
+		d.add(Removed, d.a, alo, ahi)
+		d.add(Added, d.b, blo, bhi)
+	case ahi-alo == 1:
+		j := blo
+		for j < bhi && d.y[j] != d.x[alo] {
+			j++
+		}
+		if j == bhi {
+			d.add(Removed, d.a, alo, ahi)
+			d.add(Added, d.b, blo, bhi)
+			break
+		}
+		d.add(Added, d.b, blo, j)
+		d.add(Equal, d.a, alo, ahi)
+		d.add(Added, d.b, j+1, bhi)
+	default:
+		// Split b where the longest common subsequences of both halves of a
+		// add up to the longest one, favoring the earliest split.
+		mid := (alo + ahi) / 2
+		head := d.headLengths(alo, mid, blo, bhi)
+		tail := d.tailLengths(mid, ahi, blo, bhi)
+		split := 0
+		for k := range head {
+			if head[k]+tail[k] > head[split]+tail[split] {
+				split = k
+			}
+		}
+		d.diff(alo, mid, blo, blo+split)
+		d.diff(mid, ahi, blo+split, bhi)
+	}
+
+	d.add(Equal, d.a, ahi, ahi+suffix)
+}
+
+// add appends an operation of the given kind for each of lines[i:j].
+func (d *lineDiff) add(kind byte, lines []string, i, j int) {
+	for ; i < j; i++ {
+		d.ops = append(d.ops, Op{kind, lines[i]})
+	}
+}
+
+// headLengths returns the lengths of the longest common subsequences of
+// a[alo:ahi] and every prefix of b[blo:bhi], indexed by the prefix length.
+func (d *lineDiff) headLengths(alo, ahi, blo, bhi int) []int {
+	prev, cur := make([]int, bhi-blo+1), make([]int, bhi-blo+1)
+	for i := alo; i < ahi; i++ {
+		for j := blo; j < bhi; j++ {
+			k := j - blo + 1
+			switch {
+			case d.x[i] == d.y[j]:
+				cur[k] = prev[k-1] + 1
+			case prev[k] >= cur[k-1]:
+				cur[k] = prev[k]
+			default:
+				cur[k] = cur[k-1]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// tailLengths returns the lengths of the longest common subsequences of
+// a[alo:ahi] and every suffix of b[blo:bhi], indexed by the start of the
+// suffix relative to blo.
+func (d *lineDiff) tailLengths(alo, ahi, blo, bhi int) []int {
+	prev, cur := make([]int, bhi-blo+1), make([]int, bhi-blo+1)
+	for i := ahi - 1; i >= alo; i-- {
+		for j := bhi - 1; j >= blo; j-- {
+			k := j - blo
+			switch {
+			case d.x[i] == d.y[j]:
+				cur[k] = prev[k+1] + 1
+			case prev[k] >= cur[k+1]:
+				cur[k] = prev[k]
+			default:
+				cur[k] = cur[k+1]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// SplitLines splits a text into lines, without a trailing empty line.
+func SplitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,321 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// Unified renders the changes as unified diffs, one per resource.
+type Unified struct {
+	// Context is the number of unchanged lines shown around each change
+	Context int
+}
+
+// Render implements Renderer.
+func (u *Unified) Render(out io.Writer, diffs []ResourceDiff) error {
+	for _, d := range diffs {
+		from, to := "a/"+d.ID(), "b/"+d.ID()
+		if d.Before == "" {
+			from = "/dev/null"
+		}
+		if d.After == "" {
+			to = "/dev/null"
+		}
+		if _, err := io.WriteString(out, unified(from, to, d.Before, d.After, u.Context)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Text returns a unified diff of the texts a and b, named from and to, with
+// up to context unchanged lines around each change. It returns an empty
+// string if both are equal.
+func Text(from, to, a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+	return unified(from, to, a, b, context)
+}
+
+func unified(from, to, a, b string, context int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", from, to)
+	ops := Lines(SplitLines(a), SplitLines(b))
+	for _, h := range hunks(ops, context) {
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", h.fromRange(), h.toRange())
+		for _, op := range ops[h.start:h.end] {
+			fmt.Fprintf(&sb, "%c%s\n", op.Kind, op.Line)
+		}
+	}
+	return sb.String()
+}
+
+// hunk is a range of the operations of a line diff, with the position of its
+// first line in both texts.
+type hunk struct {
+	start, end       int
+	fromLine, toLine int
+	fromLen, toLen   int
+}
+
+func (h hunk) fromRange() string {
+	return unifiedRange(h.fromLine, h.fromLen)
+}
+
+func (h hunk) toRange() string {
+	return unifiedRange(h.toLine, h.toLen)
+}
+
+// unifiedRange formats a range of lines as in the hunk headers of unified
+// diffs, where an empty range starts at the line before it.
+func unifiedRange(line, n int) string {
+	if n == 0 {
+		line--
+	}
+	if n == 1 {
+		return strconv.Itoa(line)
+	}
+	return fmt.Sprintf("%d,%d", line, n)
+}
+
+// hunks groups the changes of a line diff with up to context unchanged lines
+// around them. Changes closer than twice the context share a hunk.
+func hunks(ops []Op, context int) []hunk {
+	shown := make([]bool, len(ops))
+	for i, op := range ops {
+		if op.Kind != Equal {
+			for j := i - context; j <= i+context; j++ {
+				if j >= 0 && j < len(ops) {
+					shown[j] = true
+				}
+			}
+		}
+	}
+
+	var hs []hunk
+	fromLine, toLine := 1, 1
+	for i, op := range ops {
+		if shown[i] {
+			if i == 0 || !shown[i-1] {
+				hs = append(hs, hunk{start: i, fromLine: fromLine, toLine: toLine})
+			}
+			h := &hs[len(hs)-1]
+			h.end = i + 1
+			if op.Kind != Added {
+				h.fromLen++
+			}
+			if op.Kind != Removed {
+				h.toLen++
+			}
+		}
+		if op.Kind != Added {
+			fromLine++
+		}
+		if op.Kind != Removed {
+			toLine++
+		}
+	}
+	return hs
+}
+
+// SideBySide renders the changes as two columns, the current manifest of each
+// resource on the left and its target manifest on the right.
+type SideBySide struct {
+	// Width is the width of the output, in characters
+	Width int
+}
+
+// Render implements Renderer.
+func (s *SideBySide) Render(out io.Writer, diffs []ResourceDiff) error {
+	col := (s.Width - 3) / 2
+	if col < 10 {
+		col = 10
+	}
+	for _, d := range diffs {
+		if _, err := fmt.Fprintf(out, "%s\n", header(d)); err != nil {
+			return err
+		}
+		ops := Lines(SplitLines(d.Before), SplitLines(d.After))
+		for i := 0; i < len(ops); {
+			if ops[i].Kind == Equal {
+				if err := sideBySideRow(out, col, ops[i].Line, ' ', ops[i].Line); err != nil {
+					return err
+				}
+				i++
+				continue
+			}
+
+			// Pair the removed lines of a change with the added ones
+			var removed, added []string
+			for ; i < len(ops) && ops[i].Kind == Removed; i++ {
+				removed = append(removed, ops[i].Line)
+			}
+			for ; i < len(ops) && ops[i].Kind == Added; i++ {
+				added = append(added, ops[i].Line)
+			}
+			for j := 0; j < len(removed) || j < len(added); j++ {
+				var err error
+				switch {
+				case j >= len(added):
+					err = sideBySideRow(out, col, removed[j], '<', "")
+				case j >= len(removed):
+					err = sideBySideRow(out, col, "", '>', added[j])
+				default:
+					err = sideBySideRow(out, col, removed[j], '|', added[j])
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func sideBySideRow(out io.Writer, col int, left string, mark byte, right string) error {
+	row := fmt.Sprintf("%-*s %c %s", col, truncate(left, col), mark, truncate(right, col))
+	_, err := fmt.Fprintln(out, strings.TrimRight(row, " "))
+	return err
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+// JSONPatch renders the changes as a JSON list of the resources, each with
+// the RFC 6902 JSON patch turning its current manifest into its target one.
+type JSONPatch struct{}
+
+// PatchOperation is an operation of an RFC 6902 JSON patch.
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON leaves out the value of the remove operations only, as a null
+// value is a value for the others.
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	if o.Op == "remove" {
+		return json.Marshal(map[string]string{"op": o.Op, "path": o.Path})
+	}
+	type operation PatchOperation
+	return json.Marshal(operation(o))
+}
+
+type resourcePatch struct {
+	Kind      string           `json:"kind"`
+	Name      string           `json:"name"`
+	Namespace string           `json:"namespace,omitempty"`
+	Change    string           `json:"change"`
+	Patch     []PatchOperation `json:"patch"`
+}
+
+// Render implements Renderer.
+func (JSONPatch) Render(out io.Writer, diffs []ResourceDiff) error {
+	patches := make([]resourcePatch, 0, len(diffs))
+	for _, d := range diffs {
+		var before, after interface{}
+		if err := yaml.Unmarshal([]byte(d.Before), &before); err != nil {
+			return errors.Wrapf(err, "unable to parse the current manifest of %s", d.ID())
+		}
+		if err := yaml.Unmarshal([]byte(d.After), &after); err != nil {
+			return errors.Wrapf(err, "unable to parse the target manifest of %s", d.ID())
+		}
+		p := resourcePatch{Kind: d.Kind, Name: d.Name, Namespace: d.Namespace, Change: d.Change}
+		switch {
+		case before == nil:
+			p.Patch = []PatchOperation{{Op: "add", Path: "", Value: after}}
+		case after == nil:
+			p.Patch = []PatchOperation{{Op: "remove", Path: ""}}
+		default:
+			p.Patch = CreatePatch(before, after)
+		}
+		patches = append(patches, p)
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(patches)
+}
+
+// CreatePatch returns the operations of the RFC 6902 JSON patch turning the
+// decoded JSON document a into b. Lists are patched item by item if their
+// length did not change, and replaced otherwise.
+func CreatePatch(a, b interface{}) []PatchOperation {
+	ops := []PatchOperation{}
+	return createPatch(ops, "", a, b)
+}
+
+func createPatch(ops []PatchOperation, path string, a, b interface{}) []PatchOperation {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		if b, ok := b.(map[string]interface{}); ok {
+			for _, k := range sortedKeys(a) {
+				if _, ok := b[k]; !ok {
+					ops = append(ops, PatchOperation{Op: "remove", Path: pointer(path, k)})
+				}
+			}
+			for _, k := range sortedKeys(b) {
+				if av, ok := a[k]; ok {
+					ops = createPatch(ops, pointer(path, k), av, b[k])
+				} else {
+					ops = append(ops, PatchOperation{Op: "add", Path: pointer(path, k), Value: b[k]})
+				}
+			}
+			return ops
+		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok && len(a) == len(b) {
+			for i := range a {
+				ops = createPatch(ops, pointer(path, strconv.Itoa(i)), a[i], b[i])
+			}
+			return ops
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		ops = append(ops, PatchOperation{Op: "replace", Path: path, Value: b})
+	}
+	return ops
+}
+
+// pointer appends a reference token to a JSON pointer.
+func pointer(path, token string) string {
+	return path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,180 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/internal/resolver"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// LocalChanges returns the names of the dependencies fetched from local
+// directories whose content changed since the lock file was written.
+//
+// Dependencies the lock file has no digest for, such as those of lock files
+// written by older versions of Helm, are reported as changed.
+func (m *Manager) LocalChanges() ([]string, error) {
+	c, err := m.loadChartDir()
+	if err != nil {
+		return nil, err
+	}
+	if c.Lock == nil {
+		return nil, nil
+	}
+	return localChanges(m.ChartPath, c.Lock)
+}
+
+func localChanges(chartpath string, lock *chart.Lock) ([]string, error) {
+	recorded := map[string]string{}
+	for _, l := range lock.Local {
+		recorded[l.Name] = l.Digest
+	}
+	digests, err := localDigests(chartpath, lock.Dependencies)
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for _, l := range digests {
+		if recorded[l.Name] != l.Digest {
+			changed = append(changed, l.Name)
+		}
+	}
+	return changed, nil
+}
+
+// recordLocalDigests updates the digests of the local dependencies in the
+// lock file of the chart, if they changed.
+func (m *Manager) recordLocalDigests() error {
+	c, err := m.loadChartDir()
+	if err != nil {
+		return err
+	}
+	if c.Lock == nil {
+		return nil
+	}
+	digests, err := localDigests(m.ChartPath, c.Lock.Dependencies)
+	if err != nil {
+		return err
+	}
+	if sameLocalDigests(c.Lock.Local, digests) {
+		return nil
+	}
+	c.Lock.Local = digests
+	return writeLock(m.ChartPath, c.Lock, c.Metadata.APIVersion == chart.APIVersionV1)
+}
+
+// localDigests computes the digests of the directories of the dependencies
+// with file:// repositories.
+func localDigests(chartpath string, deps []*chart.Dependency) ([]*chart.LocalDependency, error) {
+	var digests []*chart.LocalDependency
+	for _, dep := range deps {
+		if !strings.HasPrefix(dep.Repository, "file://") {
+			continue
+		}
+		origPath, err := resolver.GetLocalPath(dep.Repository, chartpath)
+		if err != nil {
+			return nil, err
+		}
+		if origPath, err = filepath.EvalSymlinks(origPath); err != nil {
+			return nil, err
+		}
+		digest, err := hashDir(origPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compute the digest of dependency %s", dep.Name)
+		}
+		digests = append(digests, &chart.LocalDependency{
+			Name:       dep.Name,
+			Repository: dep.Repository,
+			Digest:     digest,
+		})
+	}
+	return digests, nil
+}
+
+func sameLocalDigests(a, b []*chart.LocalDependency) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if *a[i] != *b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// symlinkFromLocalDir links a dep chart from local directory into charts/,
+// instead of archiving it, so that changes to the dependency are picked up
+// without rebuilding.
+func symlinkFromLocalDir(chartpath, name, repo, version string) (string, error) {
+	destPath := filepath.Join(chartpath, "charts")
+
+	if !strings.HasPrefix(repo, "file://") {
+		return "", errors.Errorf("wrong format: chart %s repository %s", name, repo)
+	}
+
+	origPath, err := resolver.GetLocalPath(repo, chartpath)
+	if err != nil {
+		return "", err
+	}
+	if origPath, err = filepath.Abs(origPath); err != nil {
+		return "", err
+	}
+
+	ch, err := loader.LoadDir(origPath)
+	if err != nil {
+		return "", err
+	}
+
+	constraint, err := semver.NewConstraint(version)
+	if err != nil {
+		return "", errors.Wrapf(err, "dependency %s has an invalid version/constraint format", name)
+	}
+
+	v, err := semver.NewVersion(ch.Metadata.Version)
+	if err != nil {
+		return "", err
+	}
+
+	if !constraint.Check(v) {
+		return "", errors.Errorf("can't get a valid version for dependency %s", name)
+	}
+
+	link := filepath.Join(destPath, ch.Name())
+	if err := os.RemoveAll(link); err != nil {
+		return "", err
+	}
+	return ch.Metadata.Version, os.Symlink(origPath, link)
+}
+
+// safeDeleteLink deletes the link of the given dependency in the given
+// directory, if there is one.
+func safeDeleteLink(name, dir string) error {
+	link := filepath.Join(dir, name)
+	fi, err := os.Lstat(link)
+	if err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		return nil
+	}
+	return os.Remove(link)
+}
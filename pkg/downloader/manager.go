@@ -33,6 +33,7 @@ import (
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
 
+	"helm.sh/helm/v3/internal/experimental/registry"
 	"helm.sh/helm/v3/internal/resolver"
 	"helm.sh/helm/v3/internal/third_party/dep/fs"
 	"helm.sh/helm/v3/internal/urlutil"
@@ -73,6 +74,11 @@ type Manager struct {
 	Getters          []getter.Provider
 	RepositoryConfig string
 	RepositoryCache  string
+	// SymlinkLocal links dependencies with file:// repositories into the
+	// charts directory instead of archiving them, for local development.
+	SymlinkLocal bool
+	// RegistryClient pulls the dependencies with oci:// repositories.
+	RegistryClient *registry.Client
 }
 
 // Build rebuilds a local charts directory from a lockfile.
@@ -138,8 +144,28 @@ func (m *Manager) Build() error {
 		}
 	}
 
+	changed, err := localChanges(m.ChartPath, lock)
+	if err != nil {
+		return err
+	}
+	// Lock files written by older versions of Helm record no digests
+	if len(lock.Local) > 0 {
+		for _, name := range changed {
+			fmt.Fprintf(m.Out, "Local dependency %s changed since the lock file was written, rebuilding it\n", name)
+		}
+	}
+
 	// Now we need to fetch every package here into charts/
-	return m.downloadAll(lock.Dependencies)
+	if err := m.downloadAll(lock.Dependencies); err != nil {
+		return err
+	}
+
+	// Record the content of the local dependencies, so that the next build
+	// can tell whether they changed
+	if len(changed) > 0 {
+		return m.recordLocalDigests()
+	}
+	return nil
 }
 
 // Update updates a local charts directory.
@@ -199,16 +225,19 @@ func (m *Manager) Update() error {
 		return err
 	}
 
-	// downloadAll might overwrite dependency version, recalculate lock digest
+	// downloadAll might overwrite dependency version and digest, recalculate lock digest
 	newDigest, err := resolver.HashReq(req, lock.Dependencies)
 	if err != nil {
 		return err
 	}
 	lock.Digest = newDigest
+	if lock.Local, err = localDigests(m.ChartPath, lock.Dependencies); err != nil {
+		return err
+	}
 
 	// If the lock file hasn't changed, don't write a new one.
 	oldLock := c.Lock
-	if oldLock != nil && oldLock.Digest == lock.Digest {
+	if oldLock != nil && oldLock.Digest == lock.Digest && sameLocalDigests(oldLock.Local, lock.Local) {
 		return nil
 	}
 
@@ -296,7 +325,11 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 			if m.Debug {
 				fmt.Fprintf(m.Out, "Archiving %s from repo %s\n", dep.Name, dep.Repository)
 			}
-			ver, err := tarFromLocalDir(m.ChartPath, dep.Name, dep.Repository, dep.Version)
+			archive := tarFromLocalDir
+			if m.SymlinkLocal {
+				archive = symlinkFromLocalDir
+			}
+			ver, err := archive(m.ChartPath, dep.Name, dep.Repository, dep.Version)
 			if err != nil {
 				saveError = err
 				break
@@ -305,6 +338,15 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 			continue
 		}
 
+		if registry.IsOCI(dep.Repository) {
+			fmt.Fprintf(m.Out, "Downloading %s from repo %s\n", dep.Name, dep.Repository)
+			if err := m.downloadOCI(dep, destPath); err != nil {
+				saveError = err
+				break
+			}
+			continue
+		}
+
 		// Any failure to resolve/download a chart should fail:
 		// https://github.com/helm/helm/issues/1439
 		churl, username, password, err := m.findChartURL(dep.Name, dep.Version, dep.Repository, repos)
@@ -348,6 +390,9 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 				if err := m.safeDeleteDep(dep.Name, tmpPath); err != nil {
 					return err
 				}
+				if err := safeDeleteLink(dep.Name, tmpPath); err != nil {
+					return err
+				}
 			}
 		}
 		if err := move(tmpPath, destPath); err != nil {
@@ -375,6 +420,40 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 	return nil
 }
 
+// downloadOCI pulls a dependency from a registry, by digest if it is locked
+// to one, and records the version and the digest that were pulled.
+func (m *Manager) downloadOCI(dep *chart.Dependency, dest string) error {
+	dl := ChartDownloader{
+		Out:            m.Out,
+		Verify:         m.Verify,
+		Keyring:        m.Keyring,
+		RegistryClient: m.RegistryClient,
+	}
+	ref := ociChartRef(dep)
+	version := dep.Version
+	if dep.Digest != "" {
+		version = dep.Digest
+	}
+	filename, digest, err := dl.DownloadOCITo(ref, version, dest)
+	if err != nil {
+		return errors.Wrapf(err, "could not download %s", ref)
+	}
+	ch, err := loader.Load(filename)
+	if err != nil {
+		return err
+	}
+	dep.Version = ch.Metadata.Version
+	dep.Digest = digest
+	return nil
+}
+
+// ociChartRef returns the reference of a dependency with an oci://
+// repository: the chart is stored under the repository, named after the
+// dependency.
+func ociChartRef(dep *chart.Dependency) string {
+	return strings.TrimSuffix(dep.Repository, "/") + "/" + dep.Name
+}
+
 // safeDeleteDep deletes any versions of the given dependency in the given directory.
 //
 // It does this by first matching the file name to an expected pattern, then loading
@@ -421,8 +500,8 @@ func (m *Manager) hasAllRepos(deps []*chart.Dependency) error {
 	missing := []string{}
 Loop:
 	for _, dd := range deps {
-		// If repo is from local path, continue
-		if strings.HasPrefix(dd.Repository, "file://") {
+		// If repo is from local path or a registry, continue
+		if strings.HasPrefix(dd.Repository, "file://") || registry.IsOCI(dd.Repository) {
 			continue
 		}
 
@@ -454,8 +533,8 @@ func (m *Manager) ensureMissingRepos(repoNames map[string]string, deps []*chart.
 	for _, dd := range deps {
 
 		// If the chart is in the local charts directory no repository needs
-		// to be specified.
-		if dd.Repository == "" {
+		// to be specified. Registries have no index to cache.
+		if dd.Repository == "" || registry.IsOCI(dd.Repository) {
 			continue
 		}
 
@@ -523,7 +602,8 @@ func (m *Manager) resolveRepoNames(deps []*chart.Dependency) (map[string]string,
 	missing := []string{}
 	for _, dd := range deps {
 		// Don't map the repository, we don't need to download chart from charts directory
-		if dd.Repository == "" {
+		// or from registries, which have no index
+		if dd.Repository == "" || registry.IsOCI(dd.Repository) {
 			continue
 		}
 		// if dep chart is from local path, verify the path is valid
@@ -17,8 +17,11 @@ package downloader
 
 import (
 	"bytes"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"helm.sh/helm/v3/pkg/chart"
@@ -489,3 +492,135 @@ func TestKey(t *testing.T) {
 		}
 	}
 }
+
+func TestHashDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-hashdir-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "templates"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "templates", "a.yaml"), []byte("a: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := hashDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := hashDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Errorf("expected the digest to be stable, got %s and %s", first, second)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "templates", "a.yaml"), []byte("a: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if modified, err := hashDir(dir); err != nil {
+		t.Fatal(err)
+	} else if modified == first {
+		t.Error("expected the digest to change when a file is modified")
+	}
+}
+
+func TestBuild_LocalDependencyChanges(t *testing.T) {
+	// Set up a fake repo
+	srv, err := repotest.NewTempServerWithCleanup(t, "testdata/*.tgz*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+	if err := srv.LinkIndices(); err != nil {
+		t.Fatal(err)
+	}
+	dir := func(p ...string) string {
+		return filepath.Join(append([]string{srv.Root()}, p...)...)
+	}
+
+	d := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "dep-chart",
+			Version:    "0.1.0",
+			APIVersion: "v1",
+		},
+	}
+	if err := chartutil.SaveDir(d, dir()); err != nil {
+		t.Fatal(err)
+	}
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "with-dependency",
+			Version:    "0.1.0",
+			APIVersion: "v2",
+			Dependencies: []*chart.Dependency{{
+				Name:       d.Metadata.Name,
+				Version:    ">=0.1.0",
+				Repository: "file://../dep-chart",
+			}},
+		},
+	}
+	if err := chartutil.SaveDir(c, dir()); err != nil {
+		t.Fatal(err)
+	}
+
+	b := bytes.NewBuffer(nil)
+	m := &Manager{
+		ChartPath:        dir(c.Metadata.Name),
+		Out:              b,
+		SkipUpdate:       true,
+		RepositoryConfig: dir("repositories.yaml"),
+		RepositoryCache:  dir(),
+	}
+	if err := m.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := m.LocalChanges(); err != nil {
+		t.Fatal(err)
+	} else if len(changed) != 0 {
+		t.Fatalf("expected no local changes after an update, got %v", changed)
+	}
+
+	// Change the dependency without bumping its version
+	if err := ioutil.WriteFile(dir("dep-chart", "values.yaml"), []byte("changed: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := m.LocalChanges(); err != nil {
+		t.Fatal(err)
+	} else if len(changed) != 1 || changed[0] != "dep-chart" {
+		t.Fatalf("expected dep-chart to be changed, got %v", changed)
+	}
+
+	if err := m.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "Local dependency dep-chart changed") {
+		t.Errorf("expected the change to be reported, got %q", b.String())
+	}
+	if changed, err := m.LocalChanges(); err != nil {
+		t.Fatal(err)
+	} else if len(changed) != 0 {
+		t.Errorf("expected the build to record the change, got %v", changed)
+	}
+
+	// Link the dependency instead of archiving it
+	m.SymlinkLocal = true
+	if err := m.Build(); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Lstat(dir(c.Metadata.Name, "charts", "dep-chart"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode()&os.ModeSymlink == 0 {
+		t.Error("expected the dependency to be linked")
+	}
+	if _, err := os.Stat(dir(c.Metadata.Name, "charts", "dep-chart-0.1.0.tgz")); !os.IsNotExist(err) {
+		t.Error("expected the archive of the dependency to be removed")
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/internal/experimental/registry"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// DependencyVersions are the locked version of a dependency of a chart and
+// the versions available in its repository.
+type DependencyVersions struct {
+	Name       string `json:"name"`
+	Repository string `json:"repository"`
+	// Constraint is the version constraint of Chart.yaml
+	Constraint string `json:"constraint"`
+	// Locked is the version of Chart.lock
+	Locked string `json:"locked"`
+	// Wanted is the newest version satisfying the constraint
+	Wanted string `json:"wanted"`
+	// Latest is the newest version, which may not satisfy the constraint.
+	// Pre-releases are left out unless there is nothing else.
+	Latest string `json:"latest"`
+}
+
+// Outdated returns true if a newer version satisfying the constraint, or a
+// newer version that does not, is available.
+func (d DependencyVersions) Outdated() bool {
+	return d.Wanted != d.Locked || d.Latest != d.Locked
+}
+
+// Outdated compares the versions of the lock file against the newest versions
+// of the repositories of the dependencies. Dependencies living in the charts
+// directory or in local directories are left out.
+//
+// The repository indexes are refreshed first, unless SkipUpdate is set.
+func (m *Manager) Outdated() ([]DependencyVersions, error) {
+	c, err := m.loadChartDir()
+	if err != nil {
+		return nil, err
+	}
+	req := c.Metadata.Dependencies
+	if len(req) == 0 {
+		return nil, nil
+	}
+	if c.Lock == nil {
+		return nil, errors.New("the chart has no lock file. Run 'helm dependency update' to create it")
+	}
+
+	repoNames, err := m.resolveRepoNames(req)
+	if err != nil {
+		return nil, err
+	}
+	if !m.SkipUpdate {
+		if err := m.UpdateRepositories(); err != nil {
+			return nil, err
+		}
+	}
+
+	var versions []DependencyVersions
+	for i, d := range req {
+		// Registries cannot be searched for newer versions
+		if d.Repository == "" || strings.HasPrefix(d.Repository, "file://") || registry.IsOCI(d.Repository) {
+			continue
+		}
+		repoName := repoNames[d.Name]
+		if repoName == "" {
+			return nil, errors.Errorf("no repository definition for %s. Please add it via 'helm repo add'", d.Repository)
+		}
+		constraint, err := semver.NewConstraint(d.Version)
+		if err != nil {
+			return nil, errors.Wrapf(err, "dependency %q has an invalid version/constraint format", d.Name)
+		}
+		index, err := repo.LoadIndexFile(filepath.Join(m.RepositoryCache, helmpath.CacheIndexFile(repoName)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "no cached repository for %s found. (try 'helm repo update')", repoName)
+		}
+
+		v := DependencyVersions{
+			Name:       d.Name,
+			Repository: d.Repository,
+			Constraint: d.Version,
+		}
+		if locked := lockedDependency(c.Lock, req, i); locked != nil {
+			v.Locked = locked.Version
+		}
+		v.Wanted, v.Latest = newestVersions(index.Entries[d.Name], constraint)
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// lockedDependency returns the entry of the lock file for the i-th
+// dependency. The lock file lists the dependencies in the order of
+// Chart.yaml, unless it is out of date.
+func lockedDependency(lock *chart.Lock, req []*chart.Dependency, i int) *chart.Dependency {
+	if len(lock.Dependencies) == len(req) && lock.Dependencies[i].Name == req[i].Name {
+		return lock.Dependencies[i]
+	}
+	for _, l := range lock.Dependencies {
+		if l.Name == req[i].Name {
+			return l
+		}
+	}
+	return nil
+}
+
+// newestVersions returns the newest of the versions satisfying the
+// constraint, and the newest of all the versions, preferring releases over
+// pre-releases. The versions are sorted newest first.
+func newestVersions(versions repo.ChartVersions, constraint *semver.Constraints) (wanted, latest string) {
+	var latestPrerelease string
+	for _, cv := range versions {
+		v, err := semver.NewVersion(cv.Version)
+		if err != nil || len(cv.URLs) == 0 {
+			// Not a legit entry.
+			continue
+		}
+		if wanted == "" && constraint.Check(v) {
+			wanted = v.Original()
+		}
+		if v.Prerelease() != "" {
+			if latestPrerelease == "" {
+				latestPrerelease = v.Original()
+			}
+		} else if latest == "" {
+			latest = v.Original()
+		}
+	}
+	if latest == "" {
+		latest = latestPrerelease
+	}
+	return wanted, latest
+}
@@ -0,0 +1,83 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestOutdated(t *testing.T) {
+	dir := ensure.TempDir(t)
+	defer os.RemoveAll(dir)
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "with-dependencies",
+			Version:    "0.1.0",
+			APIVersion: "v2",
+			Dependencies: []*chart.Dependency{
+				{Name: "alpine", Version: "^0.1.0", Repository: "http://example.com/charts"},
+				{Name: "mariadb", Version: ">=0.1.0", Repository: "@kubernetes-charts"},
+				{Name: "local", Version: "0.1.0"},
+			},
+		},
+	}
+	if err := chartutil.SaveDir(c, dir); err != nil {
+		t.Fatal(err)
+	}
+	chartPath := filepath.Join(dir, c.Metadata.Name)
+	lock := &chart.Lock{Dependencies: []*chart.Dependency{
+		{Name: "alpine", Version: "0.1.0", Repository: "http://example.com/charts"},
+		{Name: "mariadb", Version: "0.1.0", Repository: "http://example.com/charts"},
+		{Name: "local", Version: "0.1.0"},
+	}}
+	if err := writeLock(chartPath, lock, false); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{
+		Out:              new(bytes.Buffer),
+		ChartPath:        chartPath,
+		SkipUpdate:       true,
+		RepositoryConfig: "testdata/repositories.yaml",
+		RepositoryCache:  "testdata/repository",
+	}
+	versions, err := m.Outdated()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []DependencyVersions{
+		{Name: "alpine", Repository: "http://example.com/charts", Constraint: "^0.1.0", Locked: "0.1.0", Wanted: "0.1.0", Latest: "0.2.0"},
+		{Name: "mariadb", Repository: "http://example.com/charts", Constraint: ">=0.1.0", Locked: "0.1.0", Wanted: "0.3.0", Latest: "0.3.0"},
+	}
+	if !reflect.DeepEqual(versions, expect) {
+		t.Errorf("expected %+v, got %+v", expect, versions)
+	}
+	for _, v := range versions {
+		if !v.Outdated() {
+			t.Errorf("expected %s to be outdated", v.Name)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/provenance"
+)
+
+// Vendor builds the charts directory from the lock file, then expands each
+// dependency fetched from a repository into a directory of the charts
+// directory.
+//
+// The provenance of the vendored dependencies, and a digest of their
+// directories, are recorded in the lock file so that local modifications can
+// be detected by VerifyVendored.
+func (m *Manager) Vendor() error {
+	if err := m.Build(); err != nil {
+		return err
+	}
+
+	c, err := m.loadChartDir()
+	if err != nil {
+		return err
+	}
+	lock := c.Lock
+	if lock == nil {
+		// The chart has no dependencies
+		return nil
+	}
+
+	chartsDir := filepath.Join(m.ChartPath, "charts")
+	vendored := []*chart.VendoredDependency{}
+	seen := map[string]string{}
+	for _, dep := range lock.Dependencies {
+		// Dependencies without a repository already live in the charts directory
+		if dep.Repository == "" {
+			continue
+		}
+		if version, ok := seen[dep.Name]; ok {
+			if version != dep.Version {
+				return errors.Errorf("cannot vendor %s: versions %s and %s are both required", dep.Name, version, dep.Version)
+			}
+			continue
+		}
+		seen[dep.Name] = dep.Version
+
+		archive, err := findArchive(chartsDir, dep.Name, dep.Version)
+		if err != nil {
+			return err
+		}
+		archiveDigest, err := provenance.DigestFile(archive)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(m.Out, "Vendoring %s %s into %s\n", dep.Name, dep.Version, filepath.Join(chartsDir, dep.Name))
+		dest := filepath.Join(chartsDir, dep.Name)
+		if err := os.RemoveAll(dest); err != nil {
+			return errors.Wrapf(err, "failed to remove %s", dest)
+		}
+		if err := chartutil.ExpandFile(chartsDir, archive); err != nil {
+			return errors.Wrapf(err, "failed to expand %s", archive)
+		}
+		if err := os.Remove(archive); err != nil {
+			return err
+		}
+
+		digest, err := hashDir(dest)
+		if err != nil {
+			return err
+		}
+		vendored = append(vendored, &chart.VendoredDependency{
+			Name:          dep.Name,
+			Version:       dep.Version,
+			Repository:    dep.Repository,
+			ArchiveDigest: "sha256:" + archiveDigest,
+			Digest:        digest,
+		})
+	}
+
+	lock.Vendored = vendored
+	return writeLock(m.ChartPath, lock, c.Metadata.APIVersion == chart.APIVersionV1)
+}
+
+// VerifyVendored checks that the vendored dependencies recorded in the lock
+// file have not been modified since they were vendored.
+func (m *Manager) VerifyVendored() error {
+	c, err := m.loadChartDir()
+	if err != nil {
+		return err
+	}
+	if c.Lock == nil || len(c.Lock.Vendored) == 0 {
+		return errors.New("no vendored dependencies found in the lock file")
+	}
+
+	var modified []string
+	for _, v := range c.Lock.Vendored {
+		dir := filepath.Join(m.ChartPath, "charts", v.Name)
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			fmt.Fprintf(m.Out, "%s: missing\n", v.Name)
+			modified = append(modified, v.Name)
+			continue
+		}
+		digest, err := hashDir(dir)
+		if err != nil {
+			return err
+		}
+		if digest != v.Digest {
+			fmt.Fprintf(m.Out, "%s: modified\n", v.Name)
+			modified = append(modified, v.Name)
+			continue
+		}
+		fmt.Fprintf(m.Out, "%s: ok\n", v.Name)
+	}
+
+	if len(modified) > 0 {
+		return errors.Errorf("vendored dependencies do not match the lock file: %s", strings.Join(modified, ", "))
+	}
+	return nil
+}
+
+// findArchive returns the archive of the given version of a chart in the
+// charts directory.
+func findArchive(dir, name, version string) (string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, name+"-*.tgz"))
+	if err != nil {
+		return "", err
+	}
+	for _, fname := range files {
+		ch, err := loader.LoadFile(fname)
+		if err != nil {
+			continue
+		}
+		if ch.Name() == name && ch.Metadata.Version == version {
+			return fname, nil
+		}
+	}
+	return "", errors.Errorf("could not find the archive of %s %s in %s", name, version, dir)
+}
+
+// hashDir calculates a digest of the paths and contents of the files in a
+// directory.
+func hashDir(dir string) (string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.Mode().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, path := range files {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+		f, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte{0})
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultChecksumHash is the hash function of checksums when none is named.
+const DefaultChecksumHash = "sha256"
+
+var (
+	checksumHashesMu sync.RWMutex
+	checksumHashes   = map[string]func() hash.Hash{
+		"sha1":   sha1.New,
+		"sha256": sha256.New,
+		"sha512": sha512.New,
+	}
+)
+
+// RegisterChecksumHash makes a hash function available to the checksum
+// template functions and to the checksum/config annotations under name,
+// replacing any hash function registered under the same name.
+func RegisterChecksumHash(name string, newHash func() hash.Hash) {
+	checksumHashesMu.Lock()
+	defer checksumHashesMu.Unlock()
+	checksumHashes[name] = newHash
+}
+
+// ChecksumHashes returns the names of the registered hash functions, sorted.
+func ChecksumHashes() []string {
+	checksumHashesMu.RLock()
+	defer checksumHashesMu.RUnlock()
+	names := make([]string, 0, len(checksumHashes))
+	for name := range checksumHashes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Checksum returns the hex encoded digest of the concatenated parts with the
+// hash function registered under algorithm. The checksum of a single part is
+// the one computed by the sha256sum template function and by sha256sum(1).
+func Checksum(algorithm string, parts ...string) (string, error) {
+	checksumHashesMu.RLock()
+	newHash, ok := checksumHashes[algorithm]
+	checksumHashesMu.RUnlock()
+	if !ok {
+		return "", errors.Errorf("unknown checksum hash %q, expected one of %v", algorithm, ChecksumHashes())
+	}
+
+	h := newHash()
+	for _, p := range parts {
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// checksum returns the hex encoded digest of s with the hash function
+// registered under algorithm.
+//
+// This is designed to be called from a template.
+func checksum(algorithm, s string) (string, error) {
+	return Checksum(algorithm, s)
+}
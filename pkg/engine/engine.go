@@ -124,6 +124,26 @@ func (e Engine) initFunMap(t *template.Template, referenceTpls map[string]render
 		return buf.String(), err
 	}
 
+	// Add the 'templateChecksum' function here, so that it renders the
+	// templates with 'include' and its recursion limit.
+	funcMap["templateChecksum"] = func(vals chartutil.Values, names ...string) (string, error) {
+		basePath, err := vals.PathValue("Template.BasePath")
+		if err != nil {
+			return "", errors.Wrap(err, "cannot retrieve Template.BasePath from values inside templateChecksum function")
+		}
+
+		include := funcMap["include"].(func(string, interface{}) (string, error))
+		rendered := make([]string, 0, len(names))
+		for _, name := range names {
+			out, err := include(path.Join(basePath.(string), name), vals)
+			if err != nil {
+				return "", errors.Wrapf(err, "error during templateChecksum function execution for %q", name)
+			}
+			rendered = append(rendered, out)
+		}
+		return Checksum(DefaultChecksumHash, rendered...)
+	}
+
 	// Add the 'tpl' function here
 	funcMap["tpl"] = func(tpl string, vals chartutil.Values) (string, error) {
 		basePath, err := vals.PathValue("Template.BasePath")
@@ -340,6 +360,7 @@ func recAllTpls(c *chart.Chart, templates map[string]renderable, vals chartutil.
 		"Files":        newFiles(c.Files),
 		"Release":      vals["Release"],
 		"Capabilities": vals["Capabilities"],
+		"Helm":         vals["Helm"],
 		"Values":       make(chartutil.Values),
 	}
 
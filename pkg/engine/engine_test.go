@@ -17,6 +17,7 @@ limitations under the License.
 package engine
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"strings"
 	"sync"
@@ -625,6 +626,61 @@ func TestAlterFuncMap_tpl(t *testing.T) {
 	}
 }
 
+func TestAlterFuncMap_templateChecksum(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "checksums"},
+		Templates: []*chart.File{
+			{Name: "templates/configmap.yaml", Data: []byte(`data: {{ .Values.value }}`)},
+			{Name: "templates/secret.yaml", Data: []byte(`key: other`)},
+			{Name: "templates/one", Data: []byte(`{{ templateChecksum . "configmap.yaml" }}`)},
+			{Name: "templates/both", Data: []byte(`{{ templateChecksum . "configmap.yaml" "secret.yaml" }}`)},
+			{Name: "templates/missing", Data: []byte(`{{ templateChecksum . "missing.yaml" }}`)},
+		},
+	}
+	v := chartutil.Values{
+		"Values": chartutil.Values{"value": "myvalue"},
+		"Chart":  c.Metadata,
+	}
+
+	_, err := Render(c, v)
+	if err == nil || !strings.Contains(err.Error(), "missing.yaml") {
+		t.Fatalf("expected an error about the missing template, got %v", err)
+	}
+
+	c.Templates = c.Templates[:4]
+	out, err := Render(c, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sha256sum of "data: myvalue", then of "data: myvaluekey: other"
+	if got, expect := out["checksums/templates/one"], "04dad759e35c7e4c09d028f5010b07745955a459f221b0f7f50d4c4edb7be6e9"; got != expect {
+		t.Errorf("Expected %q, got %q", expect, got)
+	}
+	if got, expect := out["checksums/templates/both"], "1b94f35e4bb00a2cf8d8a706b57ce155d6b4aa113d13292e572dcea777f2c664"; got != expect {
+		t.Errorf("Expected %q, got %q", expect, got)
+	}
+}
+
+func TestChecksumUnknownHash(t *testing.T) {
+	if _, err := Checksum("crc", "abc"); err == nil {
+		t.Error("expected an error for an unknown hash function")
+	}
+
+	RegisterChecksumHash("sha256-copy", sha256.New)
+	defer func() {
+		checksumHashesMu.Lock()
+		delete(checksumHashes, "sha256-copy")
+		checksumHashesMu.Unlock()
+	}()
+	got, err := Checksum("sha256-copy", "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"; got != expect {
+		t.Errorf("Expected %q, got %q", expect, got)
+	}
+}
+
 func TestAlterFuncMap_tplfunc(t *testing.T) {
 	c := &chart.Chart{
 		Metadata: &chart.Metadata{Name: "TplFunction"},
@@ -17,11 +17,19 @@ limitations under the License.
 package engine
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"encoding/base64"
 	"path"
+	"sort"
 	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gobwas/glob"
+	"github.com/pkg/errors"
 
 	"helm.sh/helm/v3/pkg/chart"
 )
@@ -64,14 +72,16 @@ func (f files) Get(name string) string {
 }
 
 // Glob takes a glob pattern and returns another files object only containing
-// matched  files.
+// matched  files, leaving out those matching any of the exclude patterns.
 //
 // This is designed to be called from a template.
 //
 // {{ range $name, $content := .Files.Glob("foo/**") }}
 // {{ $name }}: |
 // {{ .Files.Get($name) | indent 4 }}{{ end }}
-func (f files) Glob(pattern string) files {
+//
+// {{ (.Files.Glob "config/**" "**/*.bak" "**/.*").AsConfig | indent 2 }}
+func (f files) Glob(pattern string, excludes ...string) files {
 	g, err := glob.Compile(pattern, '/')
 	if err != nil {
 		g, _ = glob.Compile("**")
@@ -84,9 +94,214 @@ func (f files) Glob(pattern string) files {
 		}
 	}
 
+	return nf.Exclude(excludes...)
+}
+
+// Exclude returns another files object without the files matching any of the
+// glob patterns. Invalid patterns match nothing.
+//
+// This is designed to be called from a template.
+//
+// {{ (.Files.Glob "config/**").Exclude "**/*.bak" }}
+func (f files) Exclude(patterns ...string) files {
+	var globs []glob.Glob
+	for _, pattern := range patterns {
+		if g, err := glob.Compile(pattern, '/'); err == nil {
+			globs = append(globs, g)
+		}
+	}
+
+	nf := newFiles(nil)
+	for name, contents := range f {
+		if !matchesAny(globs, name) {
+			nf[name] = contents
+		}
+	}
 	return nf
 }
 
+func matchesAny(globs []glob.Glob, name string) bool {
+	for _, g := range globs {
+		if g.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Size returns the size in bytes of a named file, or 0 if it is missing.
+func (f files) Size(name string) int {
+	return len(f[name])
+}
+
+// MaxSize returns the files object unchanged, or fails rendering if any file
+// is larger than limit bytes. It guards against bundling files that do not
+// fit in a ConfigMap or a Secret.
+//
+// This is designed to be called from a template.
+//
+// {{ ((.Files.Glob "config/**").MaxSize 65536).AsConfig | indent 2 }}
+func (f files) MaxSize(limit int) (files, error) {
+	for _, name := range f.names() {
+		if size := len(f[name]); size > limit {
+			return nil, errors.Errorf("file %q is %d bytes, larger than the limit of %d bytes", name, size, limit)
+		}
+	}
+	return f, nil
+}
+
+// IsBinary tells whether a named file holds binary data rather than text,
+// that is whether its first 8000 bytes contain a NUL byte or are not valid
+// UTF-8, as git does.
+func (f files) IsBinary(name string) bool {
+	return isBinary(f[name])
+}
+
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+		// Don't mistake a multi-byte character cut in half for binary data.
+		for i := 0; i < utf8.UTFMax && len(data) > 0 && !utf8.Valid(data); i++ {
+			data = data[:len(data)-1]
+		}
+	}
+	return bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data)
+}
+
+// Text returns another files object with only the text files.
+//
+// {{ (.Files.Glob "config/**").Text.AsConfig | indent 2 }}
+func (f files) Text() files {
+	return f.filter(func(data []byte) bool { return !isBinary(data) })
+}
+
+// Binary returns another files object with only the binary files.
+//
+// {{ (.Files.Glob "config/**").Binary.AsSecrets | indent 2 }}
+func (f files) Binary() files {
+	return f.filter(isBinary)
+}
+
+func (f files) filter(keep func([]byte) bool) files {
+	nf := newFiles(nil)
+	for name, contents := range f {
+		if keep(contents) {
+			nf[name] = contents
+		}
+	}
+	return nf
+}
+
+// names returns the names of the files, sorted.
+func (f files) names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render renders a named file as a template with the data, usually the
+// current context, and returns the result.
+//
+// The file has the template functions of the chart, but not its named
+// templates: 'include' is not available, use 'tpl (.Files.Get $name) .' for
+// files that need it.
+//
+// This is designed to be called from a template.
+//
+// {{ .Files.Render "config/app.conf" . | indent 4 }}
+func (f files) Render(name string, data interface{}) (string, error) {
+	contents, ok := f[name]
+	if !ok {
+		return "", errors.Errorf("cannot render %q: no such file", name)
+	}
+
+	t := template.New(name).Option("missingkey=zero").Funcs(fileFuncMap())
+	if _, err := t.Parse(string(contents)); err != nil {
+		return "", cleanupParseError(name, err)
+	}
+	var buf strings.Builder
+	if err := t.Execute(&buf, data); err != nil {
+		return "", cleanupExecError(name, err)
+	}
+	// As with the templates of charts, drop the "<no value>" Go emits for
+	// missing values.
+	return strings.ReplaceAll(buf.String(), "<no value>", ""), nil
+}
+
+// RenderAll renders every text file as a template with the data, as Render
+// does, returning another files object with the results. Binary files are
+// kept as they are.
+//
+// {{ ((.Files.Glob "config/**").RenderAll .).AsConfig | indent 2 }}
+func (f files) RenderAll(data interface{}) (files, error) {
+	nf := newFiles(nil)
+	for _, name := range f.names() {
+		if isBinary(f[name]) {
+			nf[name] = f[name]
+			continue
+		}
+		out, err := f.Render(name, data)
+		if err != nil {
+			return nil, err
+		}
+		nf[name] = []byte(out)
+	}
+	return nf, nil
+}
+
+// fileFuncMap returns the template functions of the files rendered by Render,
+// where the late-bound functions of the Engine fail.
+func fileFuncMap() template.FuncMap {
+	f := funcMap()
+	for _, name := range []string{"include", "tpl", "templateChecksum"} {
+		name := name
+		f[name] = func(...interface{}) (string, error) {
+			return "", errors.Errorf("%q is not available in files rendered with .Files.Render", name)
+		}
+	}
+	return f
+}
+
+// TarGz returns the base64-encoded gzipped tar archive of the files, suitable
+// for including in the 'binaryData' section of a ConfigMap or the 'data'
+// section of a Secret. The archive only depends on the names and contents of
+// the files, so that it only changes when they do.
+//
+// This is designed to be called from a template.
+//
+//   binaryData:
+//     config.tar.gz: {{ ((.Files.Glob "config/**").RenderAll .).TarGz }}
+func (f files) TarGz() (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for _, name := range f.names() {
+		hdr := &tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(f[name])),
+			Typeflag: tar.TypeReg,
+			ModTime:  time.Unix(0, 0),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return "", err
+		}
+		if _, err := tw.Write(f[name]); err != nil {
+			return "", err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
 // AsConfig turns a Files group and flattens it to a YAML map suitable for
 // including in the 'data' section of a Kubernetes ConfigMap definition.
 // Duplicate keys will be overwritten, so be aware that your file names
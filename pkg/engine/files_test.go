@@ -16,6 +16,12 @@ limitations under the License.
 package engine
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -96,3 +102,116 @@ func TestLines(t *testing.T) {
 
 	as.Equal("bar", out[0])
 }
+
+func TestFileGlobExclude(t *testing.T) {
+	as := assert.New(t)
+
+	f := getTestFiles()
+
+	matched := f.Glob("**", "ship/**", "**/name.txt")
+	as.Len(matched, 2)
+	as.Contains(matched, "story/author.txt")
+	as.Contains(matched, "multiline/test.txt")
+
+	matched = f.Glob("story/**").Exclude("**/author.txt", "[")
+	as.Len(matched, 1)
+	as.Contains(matched, "story/name.txt")
+}
+
+func TestMaxSize(t *testing.T) {
+	as := assert.New(t)
+
+	f := getTestFiles()
+	as.Equal(11, f.Size("ship/captain.txt"))
+	as.Equal(0, f.Size("missing.txt"))
+
+	out, err := f.Glob("story/**").MaxSize(17)
+	as.NoError(err)
+	as.Len(out, 2)
+
+	_, err = f.Glob("story/**").MaxSize(16)
+	as.EqualError(err, `file "story/name.txt" is 17 bytes, larger than the limit of 16 bytes`)
+}
+
+func TestIsBinary(t *testing.T) {
+	as := assert.New(t)
+
+	f := getTestFiles()
+	f["bin/nul.dat"] = []byte("abc\x00def")
+	f["bin/latin1.txt"] = []byte("caf\xe9")
+	// A multi-byte character cut at the 8000th byte is still text.
+	f["long/text.txt"] = []byte(strings.Repeat("a", 7999) + "é")
+
+	as.False(f.IsBinary("ship/captain.txt"))
+	as.True(f.IsBinary("bin/nul.dat"))
+	as.True(f.IsBinary("bin/latin1.txt"))
+	as.False(f.IsBinary("long/text.txt"))
+
+	as.Len(f.Binary(), 2)
+	as.Len(f.Text(), len(cases)+1)
+}
+
+func TestFileRender(t *testing.T) {
+	as := assert.New(t)
+
+	f := getTestFiles()
+	f["config/app.conf"] = []byte(`name = {{ .Values.name | upper }}{{ .Values.missing }}`)
+	f["config/include.conf"] = []byte(`{{ include "x" . }}`)
+	f["config/broken.conf"] = []byte(`{{ .Values.name `)
+	vals := map[string]interface{}{"Values": map[string]interface{}{"name": "helm"}}
+
+	out, err := f.Render("config/app.conf", vals)
+	as.NoError(err)
+	as.Equal("name = HELM", out)
+
+	_, err = f.Render("config/missing.conf", vals)
+	as.EqualError(err, `cannot render "config/missing.conf": no such file`)
+
+	_, err = f.Render("config/include.conf", vals)
+	as.Error(err)
+	as.Contains(err.Error(), `"include" is not available`)
+
+	_, err = f.Render("config/broken.conf", vals)
+	as.Error(err)
+	as.Contains(err.Error(), "parse error")
+
+	f["config/logo.png"] = []byte("\x89PNG\x00{{")
+	rendered, err := f.Glob("config/{app.conf,logo.png}").RenderAll(vals)
+	as.NoError(err)
+	as.Equal("name = HELM", rendered.Get("config/app.conf"))
+	as.Equal("\x89PNG\x00{{", rendered.Get("config/logo.png"))
+
+	_, err = f.Glob("config/**").RenderAll(vals)
+	as.Error(err)
+}
+
+func TestTarGz(t *testing.T) {
+	as := assert.New(t)
+
+	f := getTestFiles()
+	out, err := f.Glob("ship/**").TarGz()
+	as.NoError(err)
+
+	again, err := f.Glob("ship/**").TarGz()
+	as.NoError(err)
+	as.Equal(out, again, "archives of the same files should be identical")
+
+	data, err := base64.StdEncoding.DecodeString(out)
+	as.NoError(err)
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	as.NoError(err)
+	tr := tar.NewReader(zr)
+
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		contents, err := ioutil.ReadAll(tr)
+		as.NoError(err)
+		as.Equal(f.Get(hdr.Name), string(contents))
+		names = append(names, hdr.Name)
+	}
+	as.Equal([]string{"ship/captain.txt", "ship/stowaway.txt"}, names)
+}
@@ -36,6 +36,7 @@ import (
 // Known late-bound functions:
 //
 //	- "include"
+//	- "templateChecksum"
 //	- "tpl"
 //
 // These are late-bound in Engine.Render().  The
@@ -55,6 +56,14 @@ func funcMap() template.FuncMap {
 		"toJson":        toJSON,
 		"fromJson":      fromJSON,
 		"fromJsonArray": fromJSONArray,
+		"toYamlPretty":  toYAMLPretty,
+		"toYamlDocs":    toYAMLDocs,
+		"fromYamlDocs":  fromYAMLDocs,
+		"mergeYaml":     mergeYAML,
+		"dns1123":       dns1123,
+		"k8sName":       k8sName,
+		"truncLabel":    truncLabel,
+		"checksum":      checksum,
 
 		// This is a placeholder for the "include" function, which is
 		// late-bound to a template. By declaring it here, we preserve the
@@ -67,6 +76,9 @@ func funcMap() template.FuncMap {
 		"lookup": func(string, string, string, string) (map[string]interface{}, error) {
 			return map[string]interface{}{}, nil
 		},
+		// Provide a placeholder for the "templateChecksum" function, which
+		// renders templates with "include".
+		"templateChecksum": func(interface{}, ...string) (string, error) { return "", nil },
 	}
 
 	for k, v := range extra {
@@ -76,6 +88,15 @@ func funcMap() template.FuncMap {
 	return f
 }
 
+// FuncMap returns a mapping of all of the functions available to the
+// templates of charts, with placeholders for the late-bound ones.
+//
+// It is meant for parsing templates outside of an Engine, such as to analyze
+// them, rather than for rendering them.
+func FuncMap() template.FuncMap {
+	return funcMap()
+}
+
 // toYAML takes an interface, marshals it to yaml, and returns a string. It will
 // always return a string, even on marshal error (empty string).
 //
@@ -0,0 +1,214 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// toYAMLPretty is like toYAML, but indents the items of sequences under their
+// key, as most hand written YAML does.
+//
+// This is designed to be called from a template.
+func toYAMLPretty(v interface{}) string {
+	return indentSequences(toYAML(v))
+}
+
+// blockScalarPattern matches a line opening a block scalar.
+var blockScalarPattern = regexp.MustCompile(`(?:^|[:-] )[|>][-+0-9]*$`)
+
+// indentSequences indents the items of the sequences of a YAML document
+// under their key.
+func indentSequences(s string) string {
+	lines := strings.Split(s, "\n")
+	var (
+		// shifts are the columns of the keys whose sequences are indented
+		shifts []int
+		// scalarIndent is the indentation of the line opening a block scalar
+		// being copied
+		scalarIndent = -1
+	)
+	for i, line := range lines {
+		content := strings.TrimLeft(line, " ")
+		indent := len(line) - len(content)
+
+		if scalarIndent >= 0 {
+			if content == "" || indent > scalarIndent {
+				lines[i] = shiftLine(line, len(shifts))
+				continue
+			}
+			scalarIndent = -1
+		}
+
+		for len(shifts) > 0 {
+			c := shifts[len(shifts)-1]
+			if indent > c || (indent == c && isSequenceItem(content)) {
+				break
+			}
+			shifts = shifts[:len(shifts)-1]
+		}
+		lines[i] = shiftLine(line, len(shifts))
+
+		if blockScalarPattern.MatchString(content) {
+			scalarIndent = indent
+			continue
+		}
+
+		// Sequences are emitted at the column of their key
+		column, rest := indent, content
+		for strings.HasPrefix(rest, "- ") {
+			column, rest = column+2, rest[2:]
+		}
+		if strings.HasSuffix(rest, ":") && i+1 < len(lines) {
+			next := strings.TrimLeft(lines[i+1], " ")
+			if len(lines[i+1])-len(next) == column && isSequenceItem(next) {
+				shifts = append(shifts, column)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isSequenceItem(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+func shiftLine(line string, shifts int) string {
+	if line == "" {
+		return line
+	}
+	return strings.Repeat("  ", shifts) + line
+}
+
+// toYAMLDocs marshals each item of a list to a YAML document, and returns
+// the documents separated by '---'. Every document starts with a separator,
+// so the output can be safely concatenated with other documents.
+//
+// This is designed to be called from a template.
+func toYAMLDocs(v interface{}) string {
+	var items []interface{}
+	switch v := v.(type) {
+	case []interface{}:
+		items = v
+	case []map[string]interface{}:
+		for _, m := range v {
+			items = append(items, m)
+		}
+	default:
+		items = []interface{}{v}
+	}
+
+	var b strings.Builder
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		b.WriteString("---\n")
+		b.WriteString(toYAML(item))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// fromYAMLDocs converts a stream of YAML documents separated by '---' into a
+// []interface{}. Empty documents are skipped.
+//
+// Because its intended use is within templates it tolerates errors. It will
+// insert the returned error message string in place of the documents that
+// cannot be parsed.
+func fromYAMLDocs(str string) []interface{} {
+	manifests := releaseutil.SplitManifests(str)
+	keys := make([]string, 0, len(manifests))
+	for k := range manifests {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	docs := []interface{}{}
+	for _, k := range keys {
+		var doc interface{}
+		if err := yaml.Unmarshal([]byte(manifests[k]), &doc); err != nil {
+			docs = append(docs, err.Error())
+			continue
+		}
+		if doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// mergeYAML deep merges YAML mappings, given as strings or maps, with the
+// later ones taking precedence. The result is indented like the first
+// mapping, so that it can be emitted in place of it, such as when merging
+// the output of 'include' with user supplied values.
+//
+// This is designed to be called from a template.
+func mergeYAML(docs ...interface{}) (string, error) {
+	merged := map[string]interface{}{}
+	indent := ""
+	for i, d := range docs {
+		var m map[string]interface{}
+		switch d := d.(type) {
+		case string:
+			if i == 0 {
+				indent = leadingIndent(d)
+			}
+			if err := yaml.Unmarshal([]byte(d), &m); err != nil {
+				return "", errors.Wrapf(err, "mergeYaml: argument %d is not a YAML mapping", i+1)
+			}
+		case map[string]interface{}:
+			m = d
+		case chartutil.Values:
+			m = d
+		case nil:
+		default:
+			return "", errors.Errorf("mergeYaml: argument %d is a %T, not a YAML mapping", i+1, d)
+		}
+		merged = chartutil.MergeTables(merged, m)
+	}
+
+	out := toYAML(merged)
+	if indent == "" {
+		return out, nil
+	}
+	lines := strings.Split(out, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// leadingIndent returns the indentation of the first non-blank line.
+func leadingIndent(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) != "" {
+			return line[:len(line)-len(strings.TrimLeft(line, " "))]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// nameHashLength is the length of the hash that replaces the end of names
+// too long for Kubernetes.
+const nameHashLength = 8
+
+// dns1123 normalizes a string to a DNS-1123 label, as required for the names
+// of Services, Namespaces and most other resources: lower case alphanumeric
+// characters or '-', starting and ending with an alphanumeric character, and
+// at most 63 characters long. Other characters are replaced with '-'.
+//
+// This is designed to be called from a template.
+func dns1123(s string) (string, error) {
+	name := truncateWithHash(normalizeLabel(s), validation.DNS1123LabelMaxLength, "-")
+	if name == "" {
+		return "", errors.Errorf("dns1123: %q has no character allowed in a Kubernetes name", s)
+	}
+	return name, nil
+}
+
+// k8sName normalizes a string to a DNS-1123 subdomain, as required for the
+// names of ConfigMaps, Secrets, Deployments and other resources: dot separated
+// DNS-1123 labels, at most 253 characters long.
+//
+// This is designed to be called from a template.
+func k8sName(s string) (string, error) {
+	var labels []string
+	for _, l := range strings.Split(s, ".") {
+		if l = normalizeLabel(l); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	name := truncateWithHash(strings.Join(labels, "."), validation.DNS1123SubdomainMaxLength, "-.")
+	if name == "" {
+		return "", errors.Errorf("k8sName: %q has no character allowed in a Kubernetes name", s)
+	}
+	return name, nil
+}
+
+// truncLabel shortens a string to at most n characters, for label values and
+// other fields of limited length, without the trailing '-', '_' or '.' that
+// Kubernetes rejects. Unlike `trunc n | trimSuffix "-"`, strings that only
+// differ past the limit stay distinct.
+//
+// This is designed to be called from a template.
+func truncLabel(n int, s string) string {
+	if len(s) <= n {
+		return s
+	}
+	return truncateWithHash(s, n, "-_.")
+}
+
+// normalizeLabel lower cases s, replaces the characters not allowed in a
+// DNS-1123 label with '-' and trims the dashes it starts or ends with.
+func normalizeLabel(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, s)
+	return strings.Trim(s, "-")
+}
+
+// truncateWithHash shortens s to at most max characters. The end of s is
+// replaced with a hash of all of s, so that the result is deterministic and
+// distinct strings sharing a long prefix do not end up with the same name.
+// The characters of cutset are trimmed from the kept prefix.
+func truncateWithHash(s string, max int, cutset string) string {
+	if len(s) <= max {
+		return s
+	}
+	if max <= 0 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(s))
+	hash := hex.EncodeToString(sum[:])[:nameHashLength]
+	if max <= nameHashLength+1 {
+		return hash[:max]
+	}
+	prefix := strings.TrimRight(s[:max-nameHashLength-1], cutset)
+	if prefix == "" {
+		return hash
+	}
+	return prefix + "-" + hash
+}
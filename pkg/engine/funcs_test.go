@@ -94,6 +94,42 @@ func TestFuncs(t *testing.T) {
 		tpl:    `{{ fromYamlArray . }}`,
 		expect: `[error unmarshaling JSON: while decoding JSON: json: cannot unmarshal object into Go value of type []interface {}]`,
 		vars:   `hello: world`,
+	}, {
+		tpl:    `{{ toYamlPretty . }}`,
+		expect: "list:\n  - a\n  - b: c\n    d:\n      - e\nname: helm",
+		vars:   map[string]interface{}{"name": "helm", "list": []interface{}{"a", map[string]interface{}{"b": "c", "d": []interface{}{"e"}}}},
+	}, {
+		tpl:    `{{ toYamlDocs . }}`,
+		expect: "---\nkind: A\n---\nkind: B\n",
+		vars:   []interface{}{map[string]interface{}{"kind": "A"}, nil, map[string]interface{}{"kind": "B"}},
+	}, {
+		tpl:    `{{ fromYamlDocs . }}`,
+		expect: "[map[kind:A] map[kind:B]]",
+		vars:   "---\nkind: A\n---\n---\nkind: B\n",
+	}, {
+		tpl:    `{{ mergeYaml .base .extra }}`,
+		expect: "    a: 1\n    b:\n      c: 3\n      d: 4",
+		vars:   map[string]interface{}{"base": "    a: 1\n    b:\n      c: 2\n", "extra": map[string]interface{}{"b": map[string]interface{}{"c": 3, "d": 4}}},
+	}, {
+		tpl:    `{{ dns1123 . }}`,
+		expect: "my-release-web",
+		vars:   "My_Release.Web",
+	}, {
+		tpl:    `{{ k8sName . }}`,
+		expect: "config.my-release",
+		vars:   ".Config..My_Release-",
+	}, {
+		tpl:    `{{ truncLabel 10 . }}`,
+		expect: "web-1.2.0",
+		vars:   "web-1.2.0",
+	}, {
+		tpl:    `{{ checksum "sha1" . }}`,
+		expect: "a9993e364706816aba3e25717850c26c9cd0d89d",
+		vars:   "abc",
+	}, {
+		tpl:    `{{ checksum "sha256" . }}`,
+		expect: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		vars:   "abc",
 	}, {
 		// This should never result in a network lookup. Regression for #7955
 		tpl:    `{{ lookup "v1" "Namespace" "" "unlikelynamespace99999999" }}`,
@@ -109,6 +145,40 @@ func TestFuncs(t *testing.T) {
 	}
 }
 
+func TestNameFuncs(t *testing.T) {
+	long := strings.Repeat("release-", 10)
+
+	name, err := dns1123(long + "a")
+	assert.NoError(t, err)
+	assert.Len(t, name, 63)
+	assert.True(t, strings.HasPrefix(name, "release-release-"))
+	other, err := dns1123(long + "b")
+	assert.NoError(t, err)
+	assert.NotEqual(t, name, other, "names only differing past the limit must stay distinct")
+	again, _ := dns1123(long + "a")
+	assert.Equal(t, name, again, "the hash must be deterministic")
+
+	// The kept prefix does not end with a dash before the hash
+	name, err = dns1123(strings.Repeat("a", 53) + "-" + strings.Repeat("b", 20))
+	assert.NoError(t, err)
+	assert.Regexp(t, `^a{53}-[0-9a-f]{8}$`, name)
+
+	_, err = dns1123("_.-")
+	assert.Error(t, err)
+	_, err = k8sName("...")
+	assert.Error(t, err)
+
+	name, err = k8sName(strings.Repeat("example.", 40))
+	assert.NoError(t, err)
+	assert.Len(t, name, 253)
+	assert.Regexp(t, `^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`, name)
+
+	assert.Equal(t, "short", truncLabel(63, "short"))
+	assert.Regexp(t, `^chart-1.2.3_build-[0-9a-f]{8}$`, truncLabel(27, "chart-1.2.3_build.20210701120000"))
+	assert.Len(t, truncLabel(5, "a-very-long-value"), 5)
+	assert.Equal(t, "", truncLabel(0, "value"))
+}
+
 // This test to check a function provided by sprig is due to a change in a
 // dependency of sprig. mergo in v0.3.9 changed the way it merges and only does
 // public fields (i.e. those starting with a capital letter). This test, from
@@ -22,6 +22,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"helm.sh/helm/v3/pkg/auth"
 	"helm.sh/helm/v3/pkg/cli"
 )
 
@@ -38,6 +39,8 @@ type options struct {
 	password              string
 	userAgent             string
 	timeout               time.Duration
+	transportOptions      *TransportOptions
+	credentialsProvider   auth.Provider
 }
 
 // Option allows specifying various settings configurable by the user for overriding the defaults
@@ -90,6 +93,21 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithTransportOptions tunes the HTTP transport used for requests
+func WithTransportOptions(transportOptions TransportOptions) Option {
+	return func(opts *options) {
+		opts.transportOptions = &transportOptions
+	}
+}
+
+// WithCredentialsProvider sets the provider consulted for credentials when no
+// basic auth credentials are given
+func WithCredentialsProvider(provider auth.Provider) Option {
+	return func(opts *options) {
+		opts.credentialsProvider = provider
+	}
+}
+
 // Getter is an interface to support GET to the specified URL.
 type Getter interface {
 	// Get file content by url string
@@ -141,10 +159,19 @@ var httpProvider = Provider{
 
 // All finds all of the registered getters as a list of Provider instances.
 // Currently, the built-in getters and the discovered plugins with downloader
-// notations are collected.
+// notations are collected. In offline mode, the getters refuse every request.
 func All(settings *cli.EnvSettings) Providers {
-	result := Providers{httpProvider}
+	http := httpProvider
+	if authProvider, err := auth.NewPluginProvider(settings); err == nil && authProvider != nil {
+		http.New = func(options ...Option) (Getter, error) {
+			return NewHTTPGetter(append([]Option{WithCredentialsProvider(authProvider)}, options...)...)
+		}
+	}
+	result := Providers{http}
 	pluginDownloaders, _ := collectPlugins(settings)
 	result = append(result, pluginDownloaders...)
+	if settings.Offline {
+		return Offline(result)
+	}
 	return result
 }
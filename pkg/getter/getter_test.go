@@ -18,6 +18,8 @@ package getter
 import (
 	"testing"
 
+	"github.com/pkg/errors"
+
 	"helm.sh/helm/v3/pkg/cli"
 )
 
@@ -78,3 +80,25 @@ func TestByScheme(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestAllOffline(t *testing.T) {
+	env := cli.New()
+	env.PluginsDirectory = pluginDir
+	env.Offline = true
+
+	all := All(env)
+	if len(all) != 3 {
+		t.Errorf("expected 3 providers (default plus two plugins), got %d", len(all))
+	}
+
+	for _, scheme := range []string{"https", "test2"} {
+		g, err := all.ByScheme(scheme)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = g.Get(scheme + "://example.com/index.yaml")
+		if errors.Cause(err) != ErrOffline {
+			t.Errorf("expected %s getter to fail with ErrOffline, got %v", scheme, err)
+		}
+	}
+}
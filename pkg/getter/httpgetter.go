@@ -17,14 +17,11 @@ package getter
 
 import (
 	"bytes"
-	"crypto/tls"
 	"io"
 	"net/http"
 
 	"github.com/pkg/errors"
 
-	"helm.sh/helm/v3/internal/tlsutil"
-	"helm.sh/helm/v3/internal/urlutil"
 	"helm.sh/helm/v3/internal/version"
 )
 
@@ -58,6 +55,14 @@ func (g *HTTPGetter) get(href string) (*bytes.Buffer, error) {
 
 	if g.opts.username != "" && g.opts.password != "" {
 		req.SetBasicAuth(g.opts.username, g.opts.password)
+	} else if g.opts.credentialsProvider != nil {
+		creds, err := g.opts.credentialsProvider.Credentials(req.URL.Host)
+		if err != nil {
+			return buf, err
+		}
+		if creds != nil {
+			req.SetBasicAuth(creds.Username, creds.Password)
+		}
 	}
 
 	client, err := g.httpClient()
@@ -90,34 +95,9 @@ func NewHTTPGetter(options ...Option) (Getter, error) {
 }
 
 func (g *HTTPGetter) httpClient() (*http.Client, error) {
-	transport := &http.Transport{
-		DisableCompression: true,
-		Proxy:              http.ProxyFromEnvironment,
-	}
-	if (g.opts.certFile != "" && g.opts.keyFile != "") || g.opts.caFile != "" {
-		tlsConf, err := tlsutil.NewClientTLS(g.opts.certFile, g.opts.keyFile, g.opts.caFile)
-		if err != nil {
-			return nil, errors.Wrap(err, "can't create TLS config for client")
-		}
-		tlsConf.BuildNameToCertificate()
-
-		sni, err := urlutil.ExtractHostname(g.opts.url)
-		if err != nil {
-			return nil, err
-		}
-		tlsConf.ServerName = sni
-
-		transport.TLSClientConfig = tlsConf
-	}
-
-	if g.opts.insecureSkipVerifyTLS {
-		if transport.TLSClientConfig == nil {
-			transport.TLSClientConfig = &tls.Config{
-				InsecureSkipVerify: true,
-			}
-		} else {
-			transport.TLSClientConfig.InsecureSkipVerify = true
-		}
+	transport, err := transport(g.opts)
+	if err != nil {
+		return nil, err
 	}
 
 	client := &http.Client{
@@ -18,6 +18,7 @@ package getter
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -30,6 +31,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"helm.sh/helm/v3/internal/test/ensure"
 	"helm.sh/helm/v3/internal/tlsutil"
 	"helm.sh/helm/v3/internal/version"
 	"helm.sh/helm/v3/pkg/cli"
@@ -345,3 +347,92 @@ func verifyInsecureSkipVerify(t *testing.T, g HTTPGetter, caseName string, expec
 	}
 	return transport
 }
+
+func TestHTTPTransportPool(t *testing.T) {
+	transportOf := func(options ...Option) *http.Transport {
+		g, err := NewHTTPGetter(options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, err := g.(*HTTPGetter).httpClient()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client.Transport.(*http.Transport)
+	}
+
+	shared := transportOf(WithURL("https://example.com"), WithTimeout(time.Second))
+	if transportOf(WithURL("https://example.com/charts/index.yaml")) != shared {
+		t.Error("Expected getters with the same settings to share a transport")
+	}
+	if transportOf(WithURL("https://example.com"), WithInsecureSkipVerifyTLS(true)) == shared {
+		t.Error("Expected getters with different TLS settings not to share a transport")
+	}
+
+	opts := DefaultTransportOptions()
+	opts.DisableHTTP2 = true
+	opts.MaxConnsPerHost = 4
+	transport := transportOf(WithURL("https://example.com"), WithTransportOptions(opts))
+	if transport == shared {
+		t.Error("Expected getters with different transport options not to share a transport")
+	}
+	if transport.ForceAttemptHTTP2 || transport.TLSNextProto == nil {
+		t.Error("Expected HTTP/2 to be disabled")
+	}
+	if transport.MaxConnsPerHost != 4 {
+		t.Errorf("Expected MaxConnsPerHost to be 4, got %d", transport.MaxConnsPerHost)
+	}
+	if !shared.ForceAttemptHTTP2 {
+		t.Error("Expected HTTP/2 to be enabled by default")
+	}
+
+	// Disabling the TCP keep-alive probes does not disable connection reuse
+	opts = DefaultTransportOptions()
+	opts.KeepAlive = -1
+	if transport := transportOf(WithURL("https://example.com"), WithTransportOptions(opts)); transport.DisableKeepAlives {
+		t.Error("Expected HTTP keep-alives to be enabled")
+	}
+	opts.DisableHTTPKeepAlives = true
+	if transport := transportOf(WithURL("https://example.com"), WithTransportOptions(opts)); !transport.DisableKeepAlives {
+		t.Error("Expected HTTP keep-alives to be disabled")
+	}
+}
+
+func TestHTTPTransportPoolRotatedCertificates(t *testing.T) {
+	dir := ensure.TempDir(t)
+	copyFile := func(src, dst string) {
+		data, err := ioutil.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(dst, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ca, pub, priv := filepath.Join(dir, "ca.crt"), filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	copyFile("testdata/client.crt", pub)
+	copyFile("testdata/client.key", priv)
+	copyFile("testdata/ca.crt", ca)
+
+	transportOf := func() *http.Transport {
+		g, err := NewHTTPGetter(WithURL("https://example.com"), WithTLSClientConfig(pub, priv, ca))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, err := g.(*HTTPGetter).httpClient()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client.Transport.(*http.Transport)
+	}
+
+	first := transportOf()
+	if transportOf() != first {
+		t.Error("Expected getters with the same certificates to share a transport")
+	}
+
+	copyFile("../../testdata/rootca.crt", ca)
+	if transportOf() == first {
+		t.Error("Expected a new transport once the CA file changed")
+	}
+}
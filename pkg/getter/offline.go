@@ -0,0 +1,51 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package getter
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+)
+
+// ErrOffline is returned by the getters of Offline providers, and by any
+// operation that would need to reach a chart repository or a registry while
+// network access is disabled.
+var ErrOffline = errors.New("network access is disabled in offline mode")
+
+// Offline returns providers that handle the same schemes as p, but whose
+// getters fail with ErrOffline instead of fetching anything.
+func Offline(p Providers) Providers {
+	result := make(Providers, 0, len(p))
+	for _, pp := range p {
+		result = append(result, Provider{
+			Schemes: pp.Schemes,
+			New: func(options ...Option) (Getter, error) {
+				return offlineGetter{}, nil
+			},
+		})
+	}
+	return result
+}
+
+// offlineGetter refuses every request.
+type offlineGetter struct{}
+
+// Get implements Getter.
+func (offlineGetter) Get(href string, options ...Option) (*bytes.Buffer, error) {
+	return nil, errors.Wrapf(ErrOffline, "cannot fetch %s", href)
+}
@@ -0,0 +1,197 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package getter
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/internal/tlsutil"
+	"helm.sh/helm/v3/internal/urlutil"
+)
+
+// TransportOptions tunes the HTTP transports of the HTTP getter.
+//
+// Transports are pooled: getters with the same TLS settings and transport
+// options share a transport, and with it its idle connections to each host.
+// A transport is replaced once the content of its certificate, key or CA file
+// changes, so that rotated certificates are used.
+type TransportOptions struct {
+	// DialTimeout is the maximum amount of time a dial waits for a connection
+	DialTimeout time.Duration
+	// KeepAlive is the interval between TCP keep-alive probes of active
+	// connections. A negative value disables the probes
+	KeepAlive time.Duration
+	// DisableHTTPKeepAlives closes each connection after a single request
+	// instead of reusing it for later requests
+	DisableHTTPKeepAlives bool
+	// TLSHandshakeTimeout is the maximum amount of time waiting for a TLS handshake
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout is the maximum amount of time waiting for the
+	// headers of a response. Zero means no timeout
+	ResponseHeaderTimeout time.Duration
+	// IdleConnTimeout is the maximum amount of time an idle connection stays open
+	IdleConnTimeout time.Duration
+	// MaxIdleConnsPerHost is the maximum number of idle connections kept per host
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits the number of connections per host. Zero means no limit
+	MaxConnsPerHost int
+	// DisableHTTP2 restricts the transport to HTTP/1.1
+	DisableHTTP2 bool
+}
+
+// DefaultTransportOptions returns the transport options used unless others
+// are given with WithTransportOptions.
+func DefaultTransportOptions() TransportOptions {
+	return TransportOptions{
+		DialTimeout:         30 * time.Second,
+		KeepAlive:           30 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+		IdleConnTimeout:     90 * time.Second,
+		MaxIdleConnsPerHost: 10,
+	}
+}
+
+// transportKey identifies the transports that can be shared.
+type transportKey struct {
+	certFile              string
+	keyFile               string
+	caFile                string
+	insecureSkipVerifyTLS bool
+	serverName            string
+	options               TransportOptions
+}
+
+// pooledTransport is a pooled transport, along with the digest of the TLS
+// files it was created from.
+type pooledTransport struct {
+	transport *http.Transport
+	files     [sha256.Size]byte
+}
+
+var transports = struct {
+	sync.Mutex
+	pool map[transportKey]pooledTransport
+}{pool: map[transportKey]pooledTransport{}}
+
+// transport returns the pooled transport for the options, creating it if needed.
+func transport(opts options) (*http.Transport, error) {
+	key := transportKey{
+		certFile:              opts.certFile,
+		keyFile:               opts.keyFile,
+		caFile:                opts.caFile,
+		insecureSkipVerifyTLS: opts.insecureSkipVerifyTLS,
+		options:               DefaultTransportOptions(),
+	}
+	if opts.transportOptions != nil {
+		key.options = *opts.transportOptions
+	}
+	if (opts.certFile != "" && opts.keyFile != "") || opts.caFile != "" {
+		sni, err := urlutil.ExtractHostname(opts.url)
+		if err != nil {
+			return nil, err
+		}
+		key.serverName = sni
+	}
+
+	files := filesDigest(key.certFile, key.keyFile, key.caFile)
+
+	transports.Lock()
+	defer transports.Unlock()
+	pooled, ok := transports.pool[key]
+	if ok && pooled.files == files {
+		return pooled.transport, nil
+	}
+	t, err := newTransport(key)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		pooled.transport.CloseIdleConnections()
+	}
+	transports.pool[key] = pooledTransport{transport: t, files: files}
+	return t, nil
+}
+
+// filesDigest returns a digest of the content of the files. Files that cannot
+// be read are left out, and reported when the transport is created.
+func filesDigest(names ...string) [sha256.Size]byte {
+	h := sha256.New()
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if data, err := ioutil.ReadFile(name); err == nil {
+			h.Write(data)
+		}
+		h.Write([]byte{0})
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+func newTransport(key transportKey) (*http.Transport, error) {
+	o := key.options
+	t := &http.Transport{
+		DisableCompression: true,
+		Proxy:              http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   o.DialTimeout,
+			KeepAlive: o.KeepAlive,
+		}).DialContext,
+		TLSHandshakeTimeout:   o.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: o.ResponseHeaderTimeout,
+		IdleConnTimeout:       o.IdleConnTimeout,
+		MaxIdleConnsPerHost:   o.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       o.MaxConnsPerHost,
+		DisableKeepAlives:     o.DisableHTTPKeepAlives,
+		// A custom TLS configuration disables HTTP/2 unless asked for
+		ForceAttemptHTTP2: !o.DisableHTTP2,
+	}
+	if o.DisableHTTP2 {
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	if (key.certFile != "" && key.keyFile != "") || key.caFile != "" {
+		tlsConf, err := tlsutil.NewClientTLS(key.certFile, key.keyFile, key.caFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't create TLS config for client")
+		}
+		tlsConf.BuildNameToCertificate()
+		tlsConf.ServerName = key.serverName
+
+		t.TLSClientConfig = tlsConf
+	}
+
+	if key.insecureSkipVerifyTLS {
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{
+				InsecureSkipVerify: true,
+			}
+		} else {
+			t.TLSClientConfig.InsecureSkipVerify = true
+		}
+	}
+	return t, nil
+}
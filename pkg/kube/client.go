@@ -39,7 +39,9 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/resource"
@@ -61,8 +63,18 @@ type Client struct {
 	Log     func(string, ...interface{})
 	// Namespace allows to bypass the kubeconfig file for the choice of the namespace
 	Namespace string
+	// Clock is used to poll resources while waiting for them. If it is nil,
+	// the wall clock is used.
+	Clock clock.Clock
 
 	kubeClient *kubernetes.Clientset
+
+	// mutators change the objects sent to the API, in order
+	mutators []Mutator
+
+	// cache is the cache of the GETs of the operation in progress, if any
+	cacheMu sync.Mutex
+	cache   *getCache
 }
 
 var addToScheme sync.Once
@@ -120,10 +132,39 @@ func (c *Client) IsReachable() error {
 // Create creates Kubernetes resources specified in the resource list.
 func (c *Client) Create(resources ResourceList) (*Result, error) {
 	c.Log("creating %d resource(s)", len(resources))
-	if err := perform(resources, createResource); err != nil {
+	res := &Result{}
+	for _, info := range resources {
+		if policy := applyPolicy(info); policy == SkipPolicy || policy == PatchOnlyPolicy {
+			c.Log("Skipping creation of %q due to annotation [%s=%s]", info.Name, ApplyPolicyAnno, policy)
+			res.record(info, OutcomeSkipped, 0, time.Now())
+			continue
+		}
+		res.Created = append(res.Created, info)
+	}
+	if len(res.Created) == 0 && len(resources) > 0 {
+		return res, nil
+	}
+
+	mtx := sync.Mutex{}
+	err := perform(res.Created, func(info *resource.Info) error {
+		start := time.Now()
+		capture := captureWarnings(info)
+		err := createResource(c, info)
+		c.written(info)
+		warnings := capture.done()
+		if err != nil {
+			return err
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		res.record(info, OutcomeCreated, 0, start)
+		res.addWarnings(len(res.Resources)-1, warnings)
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	return &Result{Created: resources}, nil
+	return res, nil
 }
 
 // Wait up to the given timeout for the specified resources to be ready
@@ -136,6 +177,7 @@ func (c *Client) Wait(resources ResourceList, timeout time.Duration) error {
 		c:       cs,
 		log:     c.Log,
 		timeout: timeout,
+		clock:   c.clock(),
 	}
 	return w.waitForResources(resources, false)
 }
@@ -150,6 +192,7 @@ func (c *Client) WaitWithJobs(resources ResourceList, timeout time.Duration) err
 		c:       cs,
 		log:     c.Log,
 		timeout: timeout,
+		clock:   c.clock(),
 	}
 	return w.waitForResources(resources, true)
 }
@@ -187,6 +230,42 @@ func (c *Client) Build(reader io.Reader, validate bool) (ResourceList, error) {
 	return result, scrubValidationError(err)
 }
 
+// Get fetches the live objects of the resources. Resources that do not exist
+// are left out.
+func (c *Client) Get(resources ResourceList) (ResourceList, error) {
+	var live ResourceList
+	for _, info := range resources {
+		obj, err := c.getObject(info)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get %s %q", info.Mapping.GroupVersionKind.Kind, info.Name)
+		}
+		found := *info
+		found.Object = obj
+		live = append(live, &found)
+	}
+	return live, nil
+}
+
+// List lists the objects of the given resource types, such as
+// 'deployments.apps', that match the label selector in the namespace.
+func (c *Client) List(namespace, selector string, types ...string) (ResourceList, error) {
+	if len(types) == 0 {
+		return nil, nil
+	}
+	return c.Factory.NewBuilder().
+		Unstructured().
+		ContinueOnError().
+		NamespaceParam(namespace).
+		DefaultNamespace().
+		ResourceTypes(types...).
+		LabelSelectorParam(selector).
+		Flatten().
+		Do().Infos()
+}
+
 // Update takes the current list of objects and target list of objects and
 // creates resources that don't already exist, updates resources that have been
 // modified in the target configuration, and deletes resources from the current
@@ -195,6 +274,15 @@ func (c *Client) Build(reader io.Reader, validate bool) (ResourceList, error) {
 // resource updates, creations, and deletions that were attempted. These can be
 // used for cleanup or other logging purposes.
 func (c *Client) Update(original, target ResourceList, force bool) (*Result, error) {
+	return c.UpdateRecreate(original, target, force, 0)
+}
+
+// UpdateRecreate updates the resources as Update does. When force is set, the
+// resources that cannot be replaced, such as for changes of immutable fields,
+// are deleted and created again once their deletion completed, waiting up to
+// deletionTimeout for it. A zero deletionTimeout disables the recreation, the
+// failure to replace being returned as an error.
+func (c *Client) UpdateRecreate(original, target ResourceList, force bool, deletionTimeout time.Duration) (*Result, error) {
 	updateErrors := []string{}
 	res := &Result{}
 
@@ -204,34 +292,83 @@ func (c *Client) Update(original, target ResourceList, force bool) (*Result, err
 			return err
 		}
 
-		helper := resource.NewHelper(info.Client, info.Mapping)
-		if _, err := helper.Get(info.Namespace, info.Name); err != nil {
+		start := time.Now()
+		recorded := len(res.Resources)
+		capture := captureWarnings(info)
+		defer func() { res.addWarnings(recorded, capture.done()) }()
+
+		kind := info.Mapping.GroupVersionKind.Kind
+		policy := applyPolicy(info)
+		if policy == SkipPolicy {
+			c.Log("Skipping %s %q due to annotation [%s=%s]", kind, info.Name, ApplyPolicyAnno, policy)
+			res.record(info, OutcomeSkipped, 0, start)
+			return nil
+		}
+
+		// Resources using metadata.generateName get a new name each time
+		// they are created, so there is nothing to patch
+		if GeneratesName(info) {
+			if policy == PatchOnlyPolicy {
+				c.Log("Skipping creation of %s %q due to annotation [%s=%s]", kind, info.Name, ApplyPolicyAnno, policy)
+				res.record(info, OutcomeSkipped, 0, start)
+				return nil
+			}
+			res.Created = append(res.Created, info)
+			if err := createResource(c, info); err != nil {
+				return errors.Wrap(err, "failed to create resource")
+			}
+			res.record(info, OutcomeCreated, 0, start)
+			c.Log("Created a new %s called %q in %s\n", kind, info.Name, info.Namespace)
+			return nil
+		}
+
+		if _, err := c.getObject(info); err != nil {
 			if !apierrors.IsNotFound(err) {
 				return errors.Wrap(err, "could not get information about the resource")
 			}
 
+			if policy == PatchOnlyPolicy {
+				c.Log("Skipping creation of %s %q due to annotation [%s=%s]", kind, info.Name, ApplyPolicyAnno, policy)
+				res.record(info, OutcomeSkipped, 0, start)
+				return nil
+			}
+
 			// Append the created resource to the results, even if something fails
 			res.Created = append(res.Created, info)
 
 			// Since the resource does not exist, create it.
-			if err := createResource(info); err != nil {
+			err := createResource(c, info)
+			c.written(info)
+			if err != nil {
 				return errors.Wrap(err, "failed to create resource")
 			}
+			res.record(info, OutcomeCreated, 0, start)
 
-			kind := info.Mapping.GroupVersionKind.Kind
 			c.Log("Created a new %s called %q in %s\n", kind, info.Name, info.Namespace)
 			return nil
 		}
 
+		if policy == CreateOnlyPolicy {
+			c.Log("Skipping patch of %s %q due to annotation [%s=%s]", kind, info.Name, ApplyPolicyAnno, policy)
+			// Helm needs the latest info from the API, as for unchanged resources
+			if err := c.refresh(info); err != nil {
+				return errors.Wrap(err, "failed to refresh resource information")
+			}
+			res.record(info, OutcomeSkipped, 0, start)
+			return nil
+		}
+
 		originalInfo := original.Get(info)
 		if originalInfo == nil {
-			kind := info.Mapping.GroupVersionKind.Kind
 			return errors.Errorf("no %s with the name %q found", kind, info.Name)
 		}
 
-		if err := updateResource(c, info, originalInfo.Object, force); err != nil {
+		outcome, patchSize, err := updateResource(c, info, originalInfo.Object, force, deletionTimeout)
+		if err != nil {
 			c.Log("error updating the resource %q:\n\t %v", info.Name, err)
 			updateErrors = append(updateErrors, err.Error())
+		} else {
+			res.record(info, outcome, patchSize, start)
 		}
 		// Because we check for errors later, append the info regardless
 		res.Updated = append(res.Updated, info)
@@ -247,9 +384,14 @@ func (c *Client) Update(original, target ResourceList, force bool) (*Result, err
 	}
 
 	for _, info := range original.Difference(target) {
+		if GeneratesName(info) {
+			c.Log("Skipping delete of %s with generateName %q: its name is unknown", info.Mapping.GroupVersionKind.Kind, generateName(info))
+			continue
+		}
 		c.Log("Deleting %q in %s...", info.Name, info.Namespace)
+		start := time.Now()
 
-		if err := info.Get(); err != nil {
+		if err := c.refresh(info); err != nil {
 			c.Log("Unable to get obj %q, err: %s", info.Name, err)
 			continue
 		}
@@ -261,11 +403,14 @@ func (c *Client) Update(original, target ResourceList, force bool) (*Result, err
 			c.Log("Skipping delete of %q due to annotation [%s=%s]", info.Name, ResourcePolicyAnno, KeepPolicy)
 			continue
 		}
-		if err := deleteResource(info); err != nil {
+		err = deleteResource(info)
+		c.written(info)
+		if err != nil {
 			c.Log("Failed to delete %q, err: %s", info.ObjectName(), err)
 			continue
 		}
 		res.Deleted = append(res.Deleted, info)
+		res.record(info, OutcomeDeleted, 0, start)
 	}
 	return res, nil
 }
@@ -273,22 +418,38 @@ func (c *Client) Update(original, target ResourceList, force bool) (*Result, err
 // Delete deletes Kubernetes resources specified in the resources list. It will
 // attempt to delete all resources even if one or more fail and collect any
 // errors. All successfully deleted items will be returned in the `Deleted`
-// ResourceList that is part of the result.
+// ResourceList that is part of the result. The result, which is returned
+// along with the errors, also records the resources that were not found and
+// those that failed to be deleted.
 func (c *Client) Delete(resources ResourceList) (*Result, []error) {
 	var errs []error
 	res := &Result{}
 	mtx := sync.Mutex{}
 	err := perform(resources, func(info *resource.Info) error {
-		c.Log("Starting delete for %q %s", info.Name, info.Mapping.GroupVersionKind.Kind)
-		if err := c.skipIfNotFound(deleteResource(info)); err != nil {
+		start := time.Now()
+		if GeneratesName(info) {
+			c.Log("Skipping delete of %s with generateName %q: its name is unknown", info.Mapping.GroupVersionKind.Kind, generateName(info))
 			mtx.Lock()
 			defer mtx.Unlock()
+			res.record(info, OutcomeNotFound, 0, start)
+			return nil
+		}
+		c.Log("Starting delete for %q %s", info.Name, info.Mapping.GroupVersionKind.Kind)
+		err := deleteResource(info)
+		c.written(info)
+		mtx.Lock()
+		defer mtx.Unlock()
+		switch {
+		case err == nil:
+			res.Deleted = append(res.Deleted, info)
+			res.record(info, OutcomeDeleted, 0, start)
+		case apierrors.IsNotFound(err):
+			c.Log("%v", err)
+			res.record(info, OutcomeNotFound, 0, start)
+		default:
 			// Collect the error and continue on
 			errs = append(errs, err)
-		} else {
-			mtx.Lock()
-			defer mtx.Unlock()
-			res.Deleted = append(res.Deleted, info)
+			res.recordFailure(info, err, start)
 		}
 		return nil
 	})
@@ -300,18 +461,31 @@ func (c *Client) Delete(resources ResourceList) (*Result, []error) {
 		}
 		errs = append(errs, err)
 	}
-	if errs != nil {
-		return nil, errs
+	return res, errs
+}
+
+// GeneratesName returns true if the resource has no name but a
+// metadata.generateName, the API server assigning its name when it is
+// created.
+func GeneratesName(info *resource.Info) bool {
+	return info.Name == "" && generateName(info) != ""
+}
+
+func generateName(info *resource.Info) string {
+	name, err := metadataAccessor.GenerateName(info.Object)
+	if err != nil {
+		return ""
 	}
-	return res, nil
+	return name
 }
 
-func (c *Client) skipIfNotFound(err error) error {
-	if apierrors.IsNotFound(err) {
-		c.Log("%v", err)
-		return nil
+// applyPolicy returns the apply policy a resource is annotated with, if any.
+func applyPolicy(info *resource.Info) string {
+	annotations, err := metadataAccessor.Annotations(info.Object)
+	if err != nil {
+		return ""
 	}
-	return err
+	return strings.TrimSpace(annotations[ApplyPolicyAnno])
 }
 
 func (c *Client) watchTimeout(t time.Duration) func(*resource.Info) error {
@@ -334,10 +508,34 @@ func (c *Client) watchTimeout(t time.Duration) func(*resource.Info) error {
 //   ascertained by watching the status.phase field in a pod's output.
 //
 // Handling for other kinds will be added as necessary.
+//
+// If the timeout expires, a *NotReadyError describing every resource that
+// had not completed is returned.
 func (c *Client) WatchUntilReady(resources ResourceList, timeout time.Duration) error {
 	// For jobs, there's also the option to do poll c.Jobs(namespace).Get():
 	// https://github.com/adamreese/kubernetes/blob/master/test/e2e/job.go#L291-L300
-	return perform(resources, c.watchTimeout(timeout))
+	var (
+		mtx      sync.Mutex
+		notReady []*NotReadyError
+	)
+	watchFn := c.watchTimeout(timeout)
+	err := perform(resources, func(info *resource.Info) error {
+		err := watchFn(info)
+		if nr, ok := err.(*NotReadyError); ok {
+			mtx.Lock()
+			defer mtx.Unlock()
+			notReady = append(notReady, nr)
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(notReady) > 0 {
+		return mergeNotReady(timeout, notReady)
+	}
+	return nil
 }
 
 func perform(infos ResourceList, fn func(*resource.Info) error) error {
@@ -374,8 +572,12 @@ func batchPerform(infos ResourceList, fn func(*resource.Info) error, errs chan<-
 	}
 }
 
-func createResource(info *resource.Info) error {
-	obj, err := resource.NewHelper(info.Client, info.Mapping).Create(info.Namespace, true, info.Object)
+func createResource(c *Client, info *resource.Info) error {
+	obj, err := c.mutated(info, info.Object)
+	if err != nil {
+		return err
+	}
+	obj, err = resource.NewHelper(info.Client, info.Mapping).Create(info.Namespace, true, obj)
 	if err != nil {
 		return err
 	}
@@ -389,19 +591,72 @@ func deleteResource(info *resource.Info) error {
 	return err
 }
 
-func createPatch(target *resource.Info, current runtime.Object) ([]byte, types.PatchType, error) {
+// recreate deletes the resource, waits for its deletion to complete, so that
+// its finalizers ran and its name is free again, then creates it.
+func (c *Client) recreate(info *resource.Info, timeout time.Duration) error {
+	defer c.written(info)
+	if err := deleteResource(info); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete %q to recreate it", info.Name)
+	}
+	if err := c.WaitForDelete(ResourceList{info}, timeout); err != nil {
+		return err
+	}
+	// Replace set the resource version of the object, which must be empty to
+	// create it
+	if err := metadataAccessor.SetResourceVersion(info.Object, ""); err != nil {
+		return err
+	}
+	if err := createResource(c, info); err != nil {
+		return errors.Wrapf(err, "failed to recreate %q", info.Name)
+	}
+	c.Log("Recreated %s %q in %s", info.Mapping.GroupVersionKind.Kind, info.Name, info.Namespace)
+	return nil
+}
+
+// WaitForDelete waits up to the timeout until the resources are deleted,
+// their finalizers having completed. The resources using generateName that
+// were never named are skipped, as they cannot be looked up.
+func (c *Client) WaitForDelete(resources ResourceList, timeout time.Duration) error {
+	for _, info := range resources {
+		if GeneratesName(info) {
+			continue
+		}
+		helper := resource.NewHelper(info.Client, info.Mapping)
+		kind := info.Mapping.GroupVersionKind.Kind
+		c.Log("Waiting for the deletion of %s %q with timeout of %v", kind, info.Name, timeout)
+		err := poll(c.clock(), pollInterval, timeout, true, func() (bool, error) {
+			_, err := helper.Get(info.Namespace, info.Name)
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		})
+		if err == wait.ErrWaitTimeout {
+			return errors.Errorf("timed out waiting for the deletion of %s %q in %s after %v", kind, info.Name, info.Namespace, timeout)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "unable to wait for the deletion of %s %q", kind, info.Name)
+		}
+	}
+	return nil
+}
+
+func createPatch(c *Client, target *resource.Info, current runtime.Object) ([]byte, types.PatchType, error) {
 	oldData, err := json.Marshal(current)
 	if err != nil {
 		return nil, types.StrategicMergePatchType, errors.Wrap(err, "serializing current configuration")
 	}
-	newData, err := json.Marshal(target.Object)
+	targetObj, err := c.mutated(target, target.Object)
+	if err != nil {
+		return nil, types.StrategicMergePatchType, err
+	}
+	newData, err := json.Marshal(targetObj)
 	if err != nil {
 		return nil, types.StrategicMergePatchType, errors.Wrap(err, "serializing target configuration")
 	}
 
 	// Fetch the current object for the three way merge
-	helper := resource.NewHelper(target.Client, target.Mapping)
-	currentObj, err := helper.Get(target.Namespace, target.Name)
+	currentObj, err := c.getObject(target)
 	if err != nil && !apierrors.IsNotFound(err) {
 		return nil, types.StrategicMergePatchType, errors.Wrapf(err, "unable to get data for current object %s/%s", target.Namespace, target.Name)
 	}
@@ -439,45 +694,69 @@ func createPatch(target *resource.Info, current runtime.Object) ([]byte, types.P
 	return patch, types.StrategicMergePatchType, err
 }
 
-func updateResource(c *Client, target *resource.Info, currentObj runtime.Object, force bool) error {
+// updateResource updates a resource to its target and returns what was done
+// to it, along with the size of the patch sent, if any. Forced updates that
+// are rejected as invalid recreate the resource if deletionTimeout is set.
+func updateResource(c *Client, target *resource.Info, currentObj runtime.Object, force bool, deletionTimeout time.Duration) (Outcome, int, error) {
 	var (
-		obj    runtime.Object
-		helper = resource.NewHelper(target.Client, target.Mapping)
-		kind   = target.Mapping.GroupVersionKind.Kind
+		obj       runtime.Object
+		helper    = resource.NewHelper(target.Client, target.Mapping)
+		kind      = target.Mapping.GroupVersionKind.Kind
+		outcome   = OutcomeReplaced
+		patchSize int
 	)
 
 	// if --force is applied, attempt to replace the existing resource with the new object.
 	if force {
-		var err error
-		obj, err = helper.Replace(target.Namespace, target.Name, true, target.Object)
+		replacement, err := c.mutated(target, target.Object)
 		if err != nil {
-			return errors.Wrap(err, "failed to replace object")
+			return "", 0, err
+		}
+		obj, err = helper.Replace(target.Namespace, target.Name, true, replacement)
+		c.written(target)
+		if apierrors.IsInvalid(err) && deletionTimeout > 0 {
+			c.Log("Unable to replace %s %q, recreating it: %v", kind, target.Name, err)
+			if err := c.recreate(target, deletionTimeout); err != nil {
+				return "", 0, err
+			}
+			return OutcomeRecreated, 0, nil
+		}
+		if err != nil {
+			return "", 0, errors.Wrap(err, "failed to replace object")
 		}
 		c.Log("Replaced %q with kind %s for kind %s", target.Name, currentObj.GetObjectKind().GroupVersionKind().Kind, kind)
 	} else {
-		patch, patchType, err := createPatch(target, currentObj)
+		// The original object is mutated as the target is, so that the
+		// mutations are not taken for changes
+		original, err := c.mutated(target, currentObj)
+		if err != nil {
+			return "", 0, err
+		}
+		patch, patchType, err := createPatch(c, target, original)
 		if err != nil {
-			return errors.Wrap(err, "failed to create patch")
+			return "", 0, errors.Wrap(err, "failed to create patch")
 		}
 
 		if patch == nil || string(patch) == "{}" {
 			c.Log("Looks like there are no changes for %s %q", target.Mapping.GroupVersionKind.Kind, target.Name)
 			// This needs to happen to make sure that Helm has the latest info from the API
 			// Otherwise there will be no labels and other functions that use labels will panic
-			if err := target.Get(); err != nil {
-				return errors.Wrap(err, "failed to refresh resource information")
+			if err := c.refresh(target); err != nil {
+				return "", 0, errors.Wrap(err, "failed to refresh resource information")
 			}
-			return nil
+			return OutcomeUnchanged, 0, nil
 		}
 		// send patch to server
 		obj, err = helper.Patch(target.Namespace, target.Name, patchType, patch, nil)
+		c.written(target)
 		if err != nil {
-			return errors.Wrapf(err, "cannot patch %q with kind %s", target.Name, kind)
+			return "", 0, errors.Wrapf(err, "cannot patch %q with kind %s", target.Name, kind)
 		}
+		outcome, patchSize = OutcomePatched, len(patch)
 	}
 
 	target.Refresh(obj, true)
-	return nil
+	return outcome, patchSize, nil
 }
 
 func (c *Client) watchUntilReady(timeout time.Duration, info *resource.Info) error {
@@ -506,10 +785,13 @@ func (c *Client) watchUntilReady(timeout time.Duration, info *resource.Info) err
 
 	ctx, cancel := watchtools.ContextWithOptionalTimeout(context.Background(), timeout)
 	defer cancel()
+	// last is the last observed state of the resource, reported on timeout
+	var last runtime.Object
 	_, err = watchtools.UntilWithSync(ctx, lw, &unstructured.Unstructured{}, nil, func(e watch.Event) (bool, error) {
 		// Make sure the incoming object is versioned as we use unstructured
 		// objects when we build manifests
 		obj := convertWithMapper(e.Object, info.Mapping)
+		last = obj
 		switch e.Type {
 		case watch.Added, watch.Modified:
 			// For things like a secret or a config map, this is the best indicator
@@ -535,6 +817,9 @@ func (c *Client) watchUntilReady(timeout time.Duration, info *resource.Info) err
 			return false, nil
 		}
 	})
+	if err == wait.ErrWaitTimeout {
+		return c.notReady(info, last, timeout)
+	}
 	return err
 }
 
@@ -21,8 +21,10 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -182,6 +184,31 @@ func TestUpdate(t *testing.T) {
 		t.Errorf("expected 1 resource deleted, got %d", len(result.Deleted))
 	}
 
+	expectedOutcomes := []struct {
+		name    string
+		outcome Outcome
+	}{
+		{"starfish", OutcomePatched},
+		{"otter", OutcomeUnchanged},
+		{"dolphin", OutcomeCreated},
+		{"squid", OutcomeDeleted},
+	}
+	if len(result.Resources) != len(expectedOutcomes) {
+		t.Fatalf("expected %d resource outcomes, got %d", len(expectedOutcomes), len(result.Resources))
+	}
+	for i, e := range expectedOutcomes {
+		r := result.Resources[i]
+		if r.Kind != "Pod" || r.Name != e.name || r.Outcome != e.outcome {
+			t.Errorf("expected Pod %s to be %s, got %s %s %s", e.name, e.outcome, r.Kind, r.Name, r.Outcome)
+		}
+		if (r.PatchSize > 0) != (e.outcome == OutcomePatched) {
+			t.Errorf("unexpected patch size %d for %s", r.PatchSize, e.name)
+		}
+	}
+	if !result.Changed() {
+		t.Error("expected the result to report changes")
+	}
+
 	// TODO: Find a way to test methods that use Client Set
 	// Test with a wait
 	// if err := c.Update("test", objBody(codec, &listB), objBody(codec, &listC), false, 300, true); err != nil {
@@ -214,6 +241,206 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestUpdateApplyPolicy(t *testing.T) {
+	listA := newPodList("starfish", "otter")
+	listB := newPodList("starfish", "otter", "dolphin")
+	listB.Items[0].Spec.Containers[0].Ports = []v1.ContainerPort{{Name: "https", ContainerPort: 443}}
+	listB.Items[0].Annotations = map[string]string{ApplyPolicyAnno: CreateOnlyPolicy}
+	listB.Items[1].Annotations = map[string]string{ApplyPolicyAnno: SkipPolicy}
+	listB.Items[2].Annotations = map[string]string{ApplyPolicyAnno: PatchOnlyPolicy}
+
+	var actions []string
+
+	c := newTestClient(t)
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			p, m := req.URL.Path, req.Method
+			actions = append(actions, p+":"+m)
+			t.Logf("got request %s %s", p, m)
+			switch {
+			case p == "/namespaces/default/pods/starfish" && m == "GET":
+				return newResponse(200, &listA.Items[0])
+			case p == "/namespaces/default/pods/dolphin" && m == "GET":
+				return newResponse(404, notFoundBody())
+			default:
+				t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+				return nil, nil
+			}
+		}),
+	}
+	first, err := c.Build(objBody(&listA), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.Build(objBody(&listB), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := c.Update(first, second, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Created) != 0 || len(result.Updated) != 0 || len(result.Deleted) != 0 {
+		t.Errorf("expected no changes, got %d created, %d updated and %d deleted", len(result.Created), len(result.Updated), len(result.Deleted))
+	}
+	skipped := result.Outcomes(OutcomeSkipped)
+	if len(skipped) != 3 {
+		t.Errorf("expected 3 resources skipped, got %d", len(skipped))
+	}
+	if result.Changed() {
+		t.Error("expected the result to report no changes")
+	}
+
+	expectedActions := []string{
+		"/namespaces/default/pods/starfish:GET",
+		"/namespaces/default/pods/starfish:GET",
+		"/namespaces/default/pods/dolphin:GET",
+	}
+	if len(expectedActions) != len(actions) {
+		t.Fatalf("unexpected number of requests, expected %d, got %d", len(expectedActions), len(actions))
+	}
+	for k, v := range expectedActions {
+		if actions[k] != v {
+			t.Errorf("expected %s request got %s", v, actions[k])
+		}
+	}
+}
+
+func TestCreateWarnings(t *testing.T) {
+	list := newPodList("starfish", "otter")
+
+	c := newTestClient(t)
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			p, m := req.URL.Path, req.Method
+			if p != "/namespaces/default/pods" || m != "POST" {
+				t.Fatalf("unexpected request: %s %s", m, p)
+			}
+			data, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				t.Fatalf("could not dump request: %s", err)
+			}
+			req.Body.Close()
+			if !strings.Contains(string(data), `"name":"starfish"`) {
+				return newResponse(201, &list.Items[1])
+			}
+			res, err := newResponse(201, &list.Items[0])
+			// The warning is returned twice, but recorded once
+			res.Header.Add("Warning", `299 - "v1 Pod starfish is deprecated"`)
+			res.Header.Add("Warning", `299 - "v1 Pod starfish is deprecated"`)
+			res.Header.Add("Warning", `199 - "miscellaneous warning"`)
+			return res, err
+		}),
+	}
+	resources, err := c.Build(objBody(&list), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := c.Create(resources)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	warned := result.Warnings()
+	if len(warned) != 1 || warned[0].Name != "starfish" {
+		t.Fatalf("expected warnings for starfish only, got %+v", warned)
+	}
+	if expect := []string{"v1 Pod starfish is deprecated"}; !reflect.DeepEqual(expect, warned[0].Warnings) {
+		t.Errorf("expected warnings %v, got %v", expect, warned[0].Warnings)
+	}
+}
+
+func TestUpdateRecreate(t *testing.T) {
+	listA := newPodList("starfish")
+	listB := newPodList("starfish")
+	listB.Items[0].Spec.Containers[0].Image = "abc/app:v5"
+
+	var actions []string
+	deleted := false
+
+	c := newTestClient(t)
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			p, m := req.URL.Path, req.Method
+			actions = append(actions, p+":"+m)
+			t.Logf("got request %s %s", p, m)
+			switch {
+			case p == "/namespaces/default/pods/starfish" && m == "GET":
+				if deleted {
+					return newResponse(404, notFoundBody())
+				}
+				return newResponse(200, &listA.Items[0])
+			case p == "/namespaces/default/pods/starfish" && m == "PUT":
+				return newResponse(422, &metav1.Status{
+					Code:    http.StatusUnprocessableEntity,
+					Status:  metav1.StatusFailure,
+					Reason:  metav1.StatusReasonInvalid,
+					Message: "Pod \"starfish\" is invalid: spec: Forbidden: pod updates may not change fields other than `spec.containers[*].image`",
+				})
+			case p == "/namespaces/default/pods/starfish" && m == "DELETE":
+				deleted = true
+				return newResponse(200, &listA.Items[0])
+			case p == "/namespaces/default/pods" && m == "POST":
+				return newResponse(200, &listB.Items[0])
+			default:
+				t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+				return nil, nil
+			}
+		}),
+	}
+	first, err := c.Build(objBody(&listA), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.Build(objBody(&listB), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a deletion timeout, the failure to replace is returned
+	if _, err := c.Update(first, second, true); err == nil {
+		t.Fatal("expected the replacement to fail")
+	}
+
+	actions = nil
+	second, err = c.Build(objBody(&listB), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := c.UpdateRecreate(first, second, true, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Resources) != 1 || result.Resources[0].Outcome != OutcomeRecreated {
+		t.Fatalf("expected the Pod to be recreated, got %+v", result.Resources)
+	}
+	if !result.Changed() {
+		t.Error("expected the result to report changes")
+	}
+
+	expectedActions := []string{
+		"/namespaces/default/pods/starfish:GET",
+		"/namespaces/default/pods/starfish:GET",
+		"/namespaces/default/pods/starfish:PUT",
+		"/namespaces/default/pods/starfish:DELETE",
+		"/namespaces/default/pods/starfish:GET",
+		"/namespaces/default/pods:POST",
+	}
+	if len(expectedActions) != len(actions) {
+		t.Fatalf("unexpected number of requests, expected %d, got %d: %v", len(expectedActions), len(actions), actions)
+	}
+	for k, v := range expectedActions {
+		if actions[k] != v {
+			t.Errorf("expected %s request got %s", v, actions[k])
+		}
+	}
+}
+
 func TestBuild(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -0,0 +1,71 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// pollInterval is the interval at which resources are polled while waiting
+// for them.
+const pollInterval = 2 * time.Second
+
+// clock returns the clock of the client, or the wall clock if it has none.
+func (c *Client) clock() clock.Clock {
+	if c.Clock != nil {
+		return c.Clock
+	}
+	return clock.RealClock{}
+}
+
+// poll calls condition every interval, until it returns true or an error, or
+// until the timeout expires, in which case wait.ErrWaitTimeout is returned.
+// If immediate is set, condition is called before waiting for the first
+// interval too.
+//
+// It behaves like wait.Poll and wait.PollImmediate, but waits on the given
+// clock, so that tests can step through the intervals.
+func poll(clk clock.Clock, interval, timeout time.Duration, immediate bool, condition wait.ConditionFunc) error {
+	if immediate {
+		if done, err := condition(); err != nil || done {
+			return err
+		}
+	}
+
+	var timeoutC <-chan time.Time
+	if timeout > 0 {
+		timer := clk.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutC = timer.C()
+	}
+	ticker := clk.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			if done, err := condition(); err != nil || done {
+				return err
+			}
+		case <-timeoutC:
+			return wait.ErrWaitTimeout
+		}
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// stepWhenWaiting steps the clock once the poll has started waiting on it.
+func stepWhenWaiting(clk *clock.FakeClock, d time.Duration) {
+	for !clk.HasWaiters() {
+		runtime.Gosched()
+	}
+	clk.Step(d)
+}
+
+func TestPoll(t *testing.T) {
+	start := time.Unix(0, 0)
+	clk := clock.NewFakeClock(start)
+
+	calls := make(chan int)
+	done := make(chan error, 1)
+	go func() {
+		n := 0
+		done <- poll(clk, pollInterval, time.Minute, false, func() (bool, error) {
+			n++
+			calls <- n
+			return n == 3, nil
+		})
+	}()
+
+	for i := 1; i <= 3; i++ {
+		stepWhenWaiting(clk, pollInterval)
+		if n := <-calls; n != i {
+			t.Fatalf("expected call %d, got %d", i, n)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("expected the poll to succeed, got %v", err)
+	}
+	if elapsed := clk.Since(start); elapsed != 3*pollInterval {
+		t.Errorf("expected the poll to take %v, took %v", 3*pollInterval, elapsed)
+	}
+}
+
+func TestPollImmediate(t *testing.T) {
+	clk := clock.NewFakeClock(time.Unix(0, 0))
+
+	calls := 0
+	err := poll(clk, pollInterval, time.Minute, true, func() (bool, error) {
+		calls++
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("expected the poll to succeed, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+	if clk.HasWaiters() {
+		t.Error("expected the poll not to wait")
+	}
+}
+
+func TestPollTimeout(t *testing.T) {
+	clk := clock.NewFakeClock(time.Unix(0, 0))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- poll(clk, pollInterval, time.Minute, false, func() (bool, error) {
+			return false, nil
+		})
+	}()
+
+	stepWhenWaiting(clk, time.Minute)
+	if err := <-done; err != wait.ErrWaitTimeout {
+		t.Errorf("expected %v, got %v", wait.ErrWaitTimeout, err)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+var (
+	// policyDenial matches the messages of the requests denied by a
+	// ValidatingAdmissionPolicy, naming the policy and its binding.
+	policyDenial = regexp.MustCompile(`ValidatingAdmissionPolicy '([^']+)'(?: with binding '([^']+)')? denied request`)
+	// webhookDenial matches the messages of the requests denied by an
+	// admission webhook, naming the webhook.
+	webhookDenial = regexp.MustCompile(`admission webhook "([^"]+)" denied the request`)
+)
+
+// AdmissionResult is the outcome of the server-side dry run of a resource:
+// whether the API server, with its admission policies and webhooks, would
+// accept it.
+type AdmissionResult struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Hook is true if the resource is a hook. It is set by the caller.
+	Hook bool `json:"hook,omitempty"`
+	// Allowed is false if the request for the resource was rejected
+	Allowed bool `json:"allowed"`
+	// Policy and Binding are the ValidatingAdmissionPolicy, and its binding,
+	// that denied the request, if any
+	Policy  string `json:"policy,omitempty"`
+	Binding string `json:"binding,omitempty"`
+	// Webhook is the admission webhook that denied the request, if any
+	Webhook string `json:"webhook,omitempty"`
+	// Message is the reason the request was rejected
+	Message string `json:"message,omitempty"`
+}
+
+// DeniedBy returns the policy or webhook that denied the request for the
+// resource, if known.
+func (r AdmissionResult) DeniedBy() string {
+	switch {
+	case r.Policy != "":
+		return "ValidatingAdmissionPolicy/" + r.Policy
+	case r.Webhook != "":
+		return "webhook/" + r.Webhook
+	}
+	return ""
+}
+
+// DryRun sends the target resources to the API server in dry-run mode, as
+// Update would send them, so that they go through validation and admission,
+// including ValidatingAdmissionPolicies and admission webhooks, without being
+// persisted. The resources that do not exist are created, and the others are
+// patched from their original resources, or from their live objects if they
+// are not in original.
+//
+// The outcome for each resource is returned in order. Requests rejected by
+// the API server are reported there rather than as errors, which are returned
+// for the other failures, such as being unable to reach it.
+func (c *Client) DryRun(original, target ResourceList) ([]AdmissionResult, error) {
+	results := make([]AdmissionResult, 0, len(target))
+	for _, info := range target {
+		result := AdmissionResult{
+			Kind:      info.Mapping.GroupVersionKind.Kind,
+			Name:      info.Name,
+			Namespace: info.Namespace,
+			Allowed:   true,
+		}
+		if result.Name == "" {
+			result.Name = generateName(info)
+		}
+		err := dryRunResource(c, info, original.Get(info))
+		if err != nil {
+			if _, ok := err.(apierrors.APIStatus); !ok {
+				return results, errors.Wrapf(err, "dry run of %s %q failed", result.Kind, result.Name)
+			}
+			result.Allowed = false
+			result.Message = err.Error()
+			if m := policyDenial.FindStringSubmatch(result.Message); m != nil {
+				result.Policy, result.Binding = m[1], m[2]
+			} else if m := webhookDenial.FindStringSubmatch(result.Message); m != nil {
+				result.Webhook = m[1]
+			}
+			c.Log("dry run of %s %q rejected: %s", result.Kind, result.Name, result.Message)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// dryRunResource creates or patches the resource in dry-run mode. The
+// returned errors are those of the API server, unwrapped.
+func dryRunResource(c *Client, target *resource.Info, original *resource.Info) error {
+	helper := resource.NewHelper(target.Client, target.Mapping).DryRun(true)
+	obj, err := c.mutated(target, target.Object.DeepCopyObject())
+	if err != nil {
+		return err
+	}
+	if GeneratesName(target) {
+		_, err := helper.Create(target.Namespace, true, obj)
+		return err
+	}
+
+	live, err := c.getObject(target)
+	if apierrors.IsNotFound(err) {
+		_, err = helper.Create(target.Namespace, true, obj)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	var current runtime.Object = live
+	if original != nil {
+		// The original object is mutated as the target is, the live one
+		// already was
+		if current, err = c.mutated(target, original.Object); err != nil {
+			return err
+		}
+	}
+	patch, patchType, err := createPatch(c, target, current)
+	if err != nil {
+		return errors.Wrap(err, "failed to create patch")
+	}
+	_, err = helper.Patch(target.Namespace, target.Name, patchType, patch, &metav1.PatchOptions{})
+	return err
+}
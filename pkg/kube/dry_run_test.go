@@ -0,0 +1,113 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"net/http"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestDryRun(t *testing.T) {
+	listA := newPodList("starfish")
+	listB := newPodList("starfish", "otter", "squid")
+	listB.Items[0].Spec.Containers[0].Image = "abc/app:v5"
+
+	var actions []string
+	c := newTestClient(t)
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			p, m := req.URL.Path, req.Method
+			actions = append(actions, p+":"+m)
+			if m != "GET" && req.URL.Query().Get("dryRun") != "All" {
+				t.Errorf("expected a dry run request, got %s %s", m, req.URL)
+			}
+			switch {
+			case p == "/namespaces/default/pods/starfish" && m == "GET":
+				return newResponse(200, &listA.Items[0])
+			case p == "/namespaces/default/pods/starfish" && m == "PATCH":
+				return newResponse(422, &metav1.Status{
+					Code:    http.StatusUnprocessableEntity,
+					Status:  metav1.StatusFailure,
+					Reason:  metav1.StatusReasonInvalid,
+					Message: "pods \"starfish\" is forbidden: ValidatingAdmissionPolicy 'signed-images' with binding 'signed-images-binding' denied request: image must be signed",
+				})
+			case (p == "/namespaces/default/pods/otter" || p == "/namespaces/default/pods/squid") && m == "GET":
+				return newResponse(404, notFoundBody())
+			case p == "/namespaces/default/pods" && m == "POST":
+				if len(actions) < 5 {
+					return newResponse(403, &metav1.Status{
+						Code:    http.StatusForbidden,
+						Status:  metav1.StatusFailure,
+						Reason:  metav1.StatusReasonForbidden,
+						Message: "admission webhook \"policy.example.com\" denied the request: no latest tags",
+					})
+				}
+				return newResponse(201, &listB.Items[2])
+			default:
+				t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+				return nil, nil
+			}
+		}),
+	}
+	original, err := c.Build(objBody(&listA), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := c.Build(objBody(&listB), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := c.DryRun(original, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %+v", results)
+	}
+	if r := results[0]; r.Allowed || r.Policy != "signed-images" || r.Binding != "signed-images-binding" || r.DeniedBy() != "ValidatingAdmissionPolicy/signed-images" {
+		t.Errorf("expected starfish to be denied by the policy, got %+v", r)
+	}
+	if r := results[1]; r.Allowed || r.Webhook != "policy.example.com" || r.DeniedBy() != "webhook/policy.example.com" {
+		t.Errorf("expected otter to be denied by the webhook, got %+v", r)
+	}
+	if r := results[2]; !r.Allowed || r.Name != "squid" || r.Namespace != "default" || r.Kind != "Pod" {
+		t.Errorf("expected squid to be admitted, got %+v", r)
+	}
+
+	expectedActions := []string{
+		"/namespaces/default/pods/starfish:GET",
+		"/namespaces/default/pods/starfish:PATCH",
+		"/namespaces/default/pods/otter:GET",
+		"/namespaces/default/pods:POST",
+		"/namespaces/default/pods/squid:GET",
+		"/namespaces/default/pods:POST",
+	}
+	if len(expectedActions) != len(actions) {
+		t.Fatalf("unexpected number of requests, expected %d, got %d: %v", len(expectedActions), len(actions), actions)
+	}
+	for k, v := range expectedActions {
+		if actions[k] != v {
+			t.Errorf("expected %s request got %s", v, actions[k])
+		}
+	}
+}
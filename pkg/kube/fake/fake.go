@@ -34,6 +34,8 @@ type FailingKubeClient struct {
 	PrintingKubeClient
 	CreateError                      error
 	WaitError                        error
+	WaitForConditionError            error
+	WaitForEndpointsError            error
 	DeleteError                      error
 	WatchUntilReadyError             error
 	UpdateError                      error
@@ -66,6 +68,22 @@ func (f *FailingKubeClient) WaitWithJobs(resources kube.ResourceList, d time.Dur
 	return f.PrintingKubeClient.Wait(resources, d)
 }
 
+// WaitForCondition returns the configured error if set or prints
+func (f *FailingKubeClient) WaitForCondition(resources kube.ResourceList, d time.Duration, conditions ...kube.WaitCondition) error {
+	if f.WaitForConditionError != nil {
+		return f.WaitForConditionError
+	}
+	return f.PrintingKubeClient.WaitForCondition(resources, d, conditions...)
+}
+
+// WaitForEndpoints returns the configured error if set or prints
+func (f *FailingKubeClient) WaitForEndpoints(resources kube.ResourceList, d time.Duration, minReady int) error {
+	if f.WaitForEndpointsError != nil {
+		return f.WaitForEndpointsError
+	}
+	return f.PrintingKubeClient.WaitForEndpoints(resources, d, minReady)
+}
+
 // Delete returns the configured error if set or prints
 func (f *FailingKubeClient) Delete(resources kube.ResourceList) (*kube.Result, []error) {
 	if f.DeleteError != nil {
@@ -44,7 +44,7 @@ func (p *PrintingKubeClient) Create(resources kube.ResourceList) (*kube.Result,
 	if err != nil {
 		return nil, err
 	}
-	return &kube.Result{Created: resources}, nil
+	return &kube.Result{Created: resources, Resources: resourceResults(resources, kube.OutcomeCreated)}, nil
 }
 
 func (p *PrintingKubeClient) Wait(resources kube.ResourceList, _ time.Duration) error {
@@ -57,6 +57,30 @@ func (p *PrintingKubeClient) WaitWithJobs(resources kube.ResourceList, _ time.Du
 	return err
 }
 
+// WaitForCondition implements KubeClient WaitForCondition.
+func (p *PrintingKubeClient) WaitForCondition(resources kube.ResourceList, _ time.Duration, _ ...kube.WaitCondition) error {
+	_, err := io.Copy(p.Out, bufferize(resources))
+	return err
+}
+
+// WaitForEndpoints implements KubeClient WaitForEndpoints.
+func (p *PrintingKubeClient) WaitForEndpoints(resources kube.ResourceList, _ time.Duration, _ int) error {
+	_, err := io.Copy(p.Out, bufferize(resources))
+	return err
+}
+
+// Get implements KubeClient Get.
+//
+// All the resources exist, as they are given.
+func (p *PrintingKubeClient) Get(resources kube.ResourceList) (kube.ResourceList, error) {
+	return resources, nil
+}
+
+// List implements KubeClient List.
+func (p *PrintingKubeClient) List(_, _ string, _ ...string) (kube.ResourceList, error) {
+	return []*resource.Info{}, nil
+}
+
 // Delete implements KubeClient delete.
 //
 // It only prints out the content to be deleted.
@@ -65,7 +89,7 @@ func (p *PrintingKubeClient) Delete(resources kube.ResourceList) (*kube.Result,
 	if err != nil {
 		return nil, []error{err}
 	}
-	return &kube.Result{Deleted: resources}, nil
+	return &kube.Result{Deleted: resources, Resources: resourceResults(resources, kube.OutcomeDeleted)}, nil
 }
 
 // WatchUntilReady implements KubeClient WatchUntilReady.
@@ -83,7 +107,7 @@ func (p *PrintingKubeClient) Update(_, modified kube.ResourceList, _ bool) (*kub
 	// TODO: This doesn't completely mock out have some that get created,
 	// updated, and deleted. I don't think these are used in any unit tests, but
 	// we may want to refactor a way to handle future tests
-	return &kube.Result{Updated: modified}, nil
+	return &kube.Result{Updated: modified, Resources: resourceResults(modified, kube.OutcomePatched)}, nil
 }
 
 // Build implements KubeClient Build.
@@ -96,6 +120,19 @@ func (p *PrintingKubeClient) WaitAndGetCompletedPodPhase(_ string, _ time.Durati
 	return v1.PodSucceeded, nil
 }
 
+// resourceResults reports the same outcome for every resource.
+func resourceResults(resources kube.ResourceList, outcome kube.Outcome) []kube.ResourceResult {
+	var results []kube.ResourceResult
+	for _, info := range resources {
+		res := kube.ResourceResult{Name: info.Name, Namespace: info.Namespace, Outcome: outcome}
+		if info.Mapping != nil {
+			res.Kind = info.Mapping.GroupVersionKind.Kind
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
 func bufferize(resources kube.ResourceList) io.Reader {
 	var builder strings.Builder
 	for _, info := range resources {
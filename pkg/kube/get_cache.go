@@ -0,0 +1,180 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// getCacheTTL is how long the object fetched by a GET is reused during an
+// operation. It is shorter than pollInterval, so that waits polling resources
+// see each change.
+const getCacheTTL = time.Second
+
+// getKey identifies an object fetched by a GET.
+type getKey struct {
+	resource  schema.GroupVersionResource
+	namespace string
+	name      string
+}
+
+func newGetKey(info *resource.Info) getKey {
+	return getKey{resource: info.Mapping.Resource, namespace: info.Namespace, name: info.Name}
+}
+
+// getEntry is the result of a GET, which is pending until done is closed.
+type getEntry struct {
+	done    chan struct{}
+	obj     runtime.Object
+	err     error
+	fetched time.Time
+}
+
+// getCache deduplicates the GETs of the same object during an operation.
+// Concurrent GETs of an object wait for the one in flight, and an object is
+// fetched at most once per getCacheTTL. Writes to an object drop it from the
+// cache, so that it is fetched again afterwards.
+type getCache struct {
+	clock clock.Clock
+
+	mu      sync.Mutex
+	entries map[getKey]*getEntry
+}
+
+func newGetCache(clk clock.Clock) *getCache {
+	return &getCache{clock: clk, entries: map[getKey]*getEntry{}}
+}
+
+// get returns a copy of the object of the given key, calling fetch unless it
+// was fetched recently or is being fetched. Only the objects and the errors
+// for objects that do not exist are cached.
+func (g *getCache) get(key getKey, fetch func() (runtime.Object, error)) (runtime.Object, error) {
+	g.mu.Lock()
+	e, ok := g.entries[key]
+	if ok {
+		select {
+		case <-e.done:
+			if g.clock.Since(e.fetched) >= getCacheTTL {
+				ok = false
+			}
+		default:
+		}
+	}
+	if !ok {
+		e = &getEntry{done: make(chan struct{})}
+		g.entries[key] = e
+		g.mu.Unlock()
+
+		e.obj, e.err = fetch()
+		e.fetched = g.clock.Now()
+		if e.err != nil && !apierrors.IsNotFound(e.err) {
+			g.forget(key, e)
+		}
+		close(e.done)
+	} else {
+		g.mu.Unlock()
+		<-e.done
+	}
+
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.obj.DeepCopyObject(), nil
+}
+
+// invalidate drops the object of the given key, as it was written to.
+func (g *getCache) invalidate(key getKey) {
+	g.forget(key, nil)
+}
+
+// forget drops the entry of the given key, only if it is e unless e is nil.
+func (g *getCache) forget(key getKey, e *getEntry) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if e == nil || g.entries[key] == e {
+		delete(g.entries, key)
+	}
+}
+
+// StartOperation starts caching the GETs of the objects of resources, so that
+// the hooks, updates and checks of an operation such as an install fetch
+// each object at most once per second, however many times they need it. The
+// returned function ends the operation and drops the cache. Operations
+// started while one is in progress join it.
+func (c *Client) StartOperation() func() {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	if c.cache != nil {
+		return func() {}
+	}
+	cache := newGetCache(c.clock())
+	c.cache = cache
+	return func() {
+		c.cacheMu.Lock()
+		defer c.cacheMu.Unlock()
+		if c.cache == cache {
+			c.cache = nil
+		}
+	}
+}
+
+// operationCache returns the cache of the operation in progress, if any.
+func (c *Client) operationCache() *getCache {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	return c.cache
+}
+
+// getObject fetches the live object of the resource, through the cache of
+// the operation in progress if there is one.
+func (c *Client) getObject(info *resource.Info) (runtime.Object, error) {
+	fetch := func() (runtime.Object, error) {
+		return resource.NewHelper(info.Client, info.Mapping).Get(info.Namespace, info.Name)
+	}
+	cache := c.operationCache()
+	if cache == nil {
+		return fetch()
+	}
+	return cache.get(newGetKey(info), fetch)
+}
+
+// refresh replaces the object of the resource with the live one, as
+// info.Get does, through the cache of the operation in progress.
+func (c *Client) refresh(info *resource.Info) error {
+	obj, err := c.getObject(info)
+	if err != nil {
+		return err
+	}
+	info.Object = obj
+	info.ResourceVersion, _ = metadataAccessor.ResourceVersion(obj)
+	return nil
+}
+
+// written drops the object of the resource from the cache of the operation
+// in progress, as it was created, updated or deleted.
+func (c *Client) written(info *resource.Info) {
+	if cache := c.operationCache(); cache != nil {
+		cache.invalidate(newGetKey(info))
+	}
+}
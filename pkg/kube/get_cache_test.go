@@ -0,0 +1,118 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestOperationCache(t *testing.T) {
+	listA := newPodList("starfish", "otter")
+	listB := newPodList("starfish", "otter")
+	listB.Items[0].Spec.Containers[0].Ports = []v1.ContainerPort{{Name: "https", ContainerPort: 443}}
+
+	requests := map[string]int{}
+	c := newTestClient(t)
+	clk := clock.NewFakeClock(time.Unix(0, 0))
+	c.Clock = clk
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			p, m := req.URL.Path, req.Method
+			requests[p+":"+m]++
+			switch {
+			case p == "/namespaces/default/pods/starfish" && m == "GET":
+				return newResponse(200, &listA.Items[0])
+			case p == "/namespaces/default/pods/starfish" && m == "PATCH":
+				return newResponse(200, &listB.Items[0])
+			case p == "/namespaces/default/pods/otter" && m == "GET":
+				return newResponse(200, &listA.Items[1])
+			default:
+				t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+				return nil, nil
+			}
+		}),
+	}
+	first, err := c.Build(objBody(&listA), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.Build(objBody(&listB), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := func(step string, expected map[string]int) {
+		t.Helper()
+		for k, v := range expected {
+			if requests[k] != v {
+				t.Errorf("%s: expected %d %s requests, got %d", step, v, k, requests[k])
+			}
+		}
+	}
+
+	end := c.StartOperation()
+	if _, err := c.Update(first, second, false); err != nil {
+		t.Fatal(err)
+	}
+	expect("update", map[string]int{
+		"/namespaces/default/pods/starfish:GET":   1,
+		"/namespaces/default/pods/starfish:PATCH": 1,
+		"/namespaces/default/pods/otter:GET":      1,
+	})
+
+	// The patched pod is fetched again, the unchanged one is not
+	live, err := c.Get(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(live) != 2 {
+		t.Fatalf("expected 2 live resources, got %d", len(live))
+	}
+	expect("get", map[string]int{
+		"/namespaces/default/pods/starfish:GET": 2,
+		"/namespaces/default/pods/otter:GET":    1,
+	})
+
+	clk.Step(getCacheTTL)
+	if _, err := c.Get(second); err != nil {
+		t.Fatal(err)
+	}
+	expect("get after the TTL", map[string]int{
+		"/namespaces/default/pods/starfish:GET": 3,
+		"/namespaces/default/pods/otter:GET":    2,
+	})
+
+	end()
+	if _, err := c.Get(second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(second); err != nil {
+		t.Fatal(err)
+	}
+	expect("get after the operation", map[string]int{
+		"/namespaces/default/pods/starfish:GET": 5,
+		"/namespaces/default/pods/otter:GET":    4,
+	})
+}
@@ -65,4 +65,91 @@ type Interface interface {
 	IsReachable() error
 }
 
+// InterfaceConditionWait is introduced to avoid breaking backwards compatibility for Interface implementers.
+//
+// TODO Helm 4: Remove InterfaceConditionWait and integrate its method(s) into the Interface.
+type InterfaceConditionWait interface {
+	// WaitForCondition waits until the resources meet the given conditions.
+	WaitForCondition(resources ResourceList, timeout time.Duration, conditions ...WaitCondition) error
+}
+
+// InterfaceEndpointsWait is introduced to avoid breaking backwards compatibility for Interface implementers.
+//
+// TODO Helm 4: Remove InterfaceEndpointsWait and integrate its method(s) into the Interface.
+type InterfaceEndpointsWait interface {
+	// WaitForEndpoints waits until the Services of the resources have at least minReady ready endpoints.
+	WaitForEndpoints(resources ResourceList, timeout time.Duration, minReady int) error
+}
+
+// InterfaceResources is introduced to avoid breaking backwards compatibility for Interface implementers.
+//
+// TODO Helm 4: Remove InterfaceResources and integrate its method(s) into the Interface.
+type InterfaceResources interface {
+	// Get fetches the live objects of the resources. Resources that do not
+	// exist are left out.
+	Get(resources ResourceList) (ResourceList, error)
+
+	// List lists the objects of the given resource types, such as
+	// 'deployments.apps', that match the label selector in the namespace.
+	List(namespace, selector string, types ...string) (ResourceList, error)
+}
+
+// InterfaceRecreate is introduced to avoid breaking backwards compatibility for Interface implementers.
+//
+// TODO Helm 4: Remove InterfaceRecreate and integrate its method(s) into the Interface.
+type InterfaceRecreate interface {
+	// UpdateRecreate updates the resources as Update does. With force, the
+	// resources that cannot be replaced, such as for changes of immutable
+	// fields, are deleted then created again once their deletion completed,
+	// waiting up to deletionTimeout. A zero timeout disables recreation.
+	UpdateRecreate(original, target ResourceList, force bool, deletionTimeout time.Duration) (*Result, error)
+
+	// WaitForDelete waits up to the timeout until the resources are deleted,
+	// their finalizers having completed.
+	WaitForDelete(resources ResourceList, timeout time.Duration) error
+}
+
+// InterfaceDryRun is introduced to avoid breaking backwards compatibility for Interface implementers.
+//
+// TODO Helm 4: Remove InterfaceDryRun and integrate its method(s) into the Interface.
+type InterfaceDryRun interface {
+	// DryRun sends the target resources to the API server in dry-run mode, as
+	// Update would, and reports whether each of them would be admitted.
+	DryRun(original, target ResourceList) ([]AdmissionResult, error)
+}
+
+// InterfaceResourceQuotas is introduced to avoid breaking backwards compatibility for Interface implementers.
+//
+// TODO Helm 4: Remove InterfaceResourceQuotas and integrate its method(s) into the Interface.
+type InterfaceResourceQuotas interface {
+	// ResourceQuotas lists the ResourceQuotas of the namespace.
+	ResourceQuotas(namespace string) ([]v1.ResourceQuota, error)
+}
+
+// InterfaceNamespaces is introduced to avoid breaking backwards compatibility for Interface implementers.
+//
+// TODO Helm 4: Remove InterfaceNamespaces and integrate its method(s) into the Interface.
+type InterfaceNamespaces interface {
+	// GetNamespace fetches the namespace with the given name.
+	GetNamespace(name string) (*v1.Namespace, error)
+}
+
+// InterfaceOperations is introduced to avoid breaking backwards compatibility for Interface implementers.
+//
+// TODO Helm 4: Remove InterfaceOperations and integrate its method(s) into the Interface.
+type InterfaceOperations interface {
+	// StartOperation starts caching the GETs of the objects of resources, so
+	// that the GETs of the same object during an operation are deduplicated.
+	// The returned function ends the operation.
+	StartOperation() func()
+}
+
 var _ Interface = (*Client)(nil)
+var _ InterfaceConditionWait = (*Client)(nil)
+var _ InterfaceEndpointsWait = (*Client)(nil)
+var _ InterfaceResources = (*Client)(nil)
+var _ InterfaceRecreate = (*Client)(nil)
+var _ InterfaceDryRun = (*Client)(nil)
+var _ InterfaceResourceQuotas = (*Client)(nil)
+var _ InterfaceNamespaces = (*Client)(nil)
+var _ InterfaceOperations = (*Client)(nil)
@@ -0,0 +1,205 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubetest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+)
+
+// adminToken authenticates the clients of a control plane started by Start
+// as a member of system:masters.
+const adminToken = "kubetest-admin-token"
+
+// controlPlane is an etcd and a kube-apiserver running as local processes, the
+// same binaries envtest uses. There is no controller manager nor scheduler, so
+// workloads are stored but never run.
+type controlPlane struct {
+	dir    string
+	host   string
+	caData []byte
+	etcd   *process
+	api    *process
+}
+
+// process is a binary started by the control plane, keeping its output for
+// the errors reported when it fails.
+type process struct {
+	name string
+	cmd  *exec.Cmd
+	out  bytes.Buffer
+	done chan struct{}
+}
+
+func (p *process) start() error {
+	p.cmd.Stdout = &p.out
+	p.cmd.Stderr = &p.out
+	if err := p.cmd.Start(); err != nil {
+		return errors.Wrapf(err, "failed to start %s", p.name)
+	}
+	p.done = make(chan struct{})
+	go func() {
+		p.cmd.Wait()
+		close(p.done)
+	}()
+	return nil
+}
+
+// exited tells whether the process is no longer running.
+func (p *process) exited() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *process) stop() {
+	if p == nil || p.done == nil {
+		return
+	}
+	p.cmd.Process.Kill()
+	<-p.done
+}
+
+// findBinary returns the path of the named binary in the assets directory.
+func findBinary(assets, name string) (string, error) {
+	path := filepath.Join(assets, name)
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot find %s in %s", name, AssetsEnvVar)
+	}
+	if fi.IsDir() || fi.Mode()&0111 == 0 {
+		return "", errors.Errorf("%s is not an executable", path)
+	}
+	return path, nil
+}
+
+// freePort returns a port of the loopback interface nothing listens on.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// startControlPlane starts etcd and the API server found in the assets
+// directory, keeping their data, certificates and keys in dir.
+func startControlPlane(assets, dir string) (*controlPlane, error) {
+	etcdPath, err := findBinary(assets, "etcd")
+	if err != nil {
+		return nil, err
+	}
+	apiPath, err := findBinary(assets, "kube-apiserver")
+	if err != nil {
+		return nil, err
+	}
+
+	var ports [3]int
+	for i := range ports {
+		if ports[i], err = freePort(); err != nil {
+			return nil, errors.Wrap(err, "failed to find a free port")
+		}
+	}
+	etcdURL := fmt.Sprintf("http://127.0.0.1:%d", ports[0])
+
+	certData, keyData, err := cert.GenerateSelfSignedCertKey("127.0.0.1", []net.IP{net.ParseIP("127.0.0.1")}, []string{"localhost"})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate the serving certificate")
+	}
+	saKey, err := keyutil.MakeEllipticPrivateKeyPEM()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate the service account key")
+	}
+	files := map[string][]byte{
+		"apiserver.crt": certData,
+		"apiserver.key": keyData,
+		"sa.key":        saKey,
+		"tokens.csv":    []byte(adminToken + ",kubetest-admin,kubetest-admin,system:masters\n"),
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			return nil, err
+		}
+	}
+
+	cp := &controlPlane{
+		dir:    dir,
+		host:   "https://127.0.0.1:" + strconv.Itoa(ports[2]),
+		caData: certData,
+	}
+	cp.etcd = &process{name: "etcd", cmd: exec.Command(etcdPath,
+		"--data-dir="+filepath.Join(dir, "etcd"),
+		"--listen-client-urls="+etcdURL,
+		"--advertise-client-urls="+etcdURL,
+		fmt.Sprintf("--listen-peer-urls=http://127.0.0.1:%d", ports[1]),
+	)}
+	cp.api = &process{name: "kube-apiserver", cmd: exec.Command(apiPath,
+		"--etcd-servers="+etcdURL,
+		"--bind-address=127.0.0.1",
+		"--secure-port="+strconv.Itoa(ports[2]),
+		"--cert-dir="+dir,
+		"--tls-cert-file="+filepath.Join(dir, "apiserver.crt"),
+		"--tls-private-key-file="+filepath.Join(dir, "apiserver.key"),
+		"--token-auth-file="+filepath.Join(dir, "tokens.csv"),
+		"--authorization-mode=RBAC",
+		"--service-account-issuer=https://kubernetes.default.svc",
+		"--service-account-key-file="+filepath.Join(dir, "sa.key"),
+		"--service-account-signing-key-file="+filepath.Join(dir, "sa.key"),
+		"--service-cluster-ip-range=10.0.0.0/24",
+		"--disable-admission-plugins=ServiceAccount",
+		"--allow-privileged=true",
+	)}
+
+	if err := cp.etcd.start(); err != nil {
+		return nil, err
+	}
+	if err := cp.api.start(); err != nil {
+		cp.stop()
+		return nil, err
+	}
+	return cp, nil
+}
+
+// exited returns an error carrying the output of a process of the control
+// plane that is no longer running.
+func (cp *controlPlane) exited() error {
+	for _, p := range []*process{cp.etcd, cp.api} {
+		if p.exited() {
+			return errors.Errorf("%s exited: %s", p.name, p.out.String())
+		}
+	}
+	return nil
+}
+
+func (cp *controlPlane) stop() {
+	cp.api.stop()
+	cp.etcd.stop()
+}
@@ -0,0 +1,108 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubetest
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// RequireRelease fails the test unless the last revision of the named release
+// has the status, returning the release.
+func (e *Environment) RequireRelease(t *testing.T, cfg *action.Configuration, name string, status release.Status) *release.Release {
+	t.Helper()
+	rel, err := cfg.Releases.Last(name)
+	if err != nil {
+		t.Fatalf("release %q: %s", name, err)
+	}
+	if rel.Info.Status != status {
+		t.Fatalf("release %q: expected status %q, got %q: %s", name, status, rel.Info.Status, rel.Info.Description)
+	}
+	return rel
+}
+
+// RequireReleaseResources fails the test unless every resource of the
+// manifest of the release exists.
+func (e *Environment) RequireReleaseResources(t *testing.T, cfg *action.Configuration, rel *release.Release) {
+	t.Helper()
+	resources, err := cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		t.Fatalf("release %q: %s", rel.Name, err)
+	}
+	for _, info := range resources {
+		if err := info.Get(); err != nil {
+			t.Errorf("release %q: %s", rel.Name, err)
+		}
+	}
+}
+
+// RequireResource fails the test unless the resource exists, returning it.
+// The namespace is ignored for the kinds that are not namespaced.
+func (e *Environment) RequireResource(t *testing.T, apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	t.Helper()
+	obj, err := e.getResource(apiVersion, kind, namespace, name)
+	if err != nil {
+		t.Fatalf("%s %q: %s", kind, name, err)
+	}
+	return obj
+}
+
+// RequireNoResource fails the test if the resource exists.
+func (e *Environment) RequireNoResource(t *testing.T, apiVersion, kind, namespace, name string) {
+	t.Helper()
+	_, err := e.getResource(apiVersion, kind, namespace, name)
+	if err == nil {
+		t.Fatalf("%s %q: expected not to exist", kind, name)
+	}
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("%s %q: %s", kind, name, err)
+	}
+}
+
+func (e *Environment) getResource(apiVersion, kind, namespace, name string) (*unstructured.Unstructured, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := e.Getter(namespace).ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := mapper.RESTMapping(gv.WithKind(kind).GroupKind(), gv.Version)
+	if err != nil {
+		return nil, err
+	}
+	client, err := dynamic.NewForConfig(e.Config)
+	if err != nil {
+		return nil, err
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		return client.Resource(mapping.Resource).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	}
+	return client.Resource(mapping.Resource).Get(context.Background(), name, metav1.GetOptions{})
+}
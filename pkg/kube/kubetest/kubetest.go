@@ -0,0 +1,252 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package kubetest runs integration tests against a real Kubernetes API server.
+
+An Environment either starts an etcd and a kube-apiserver from the directory
+named by $KUBEBUILDER_ASSETS, the binaries envtest uses, or attaches to the
+existing cluster of the kubeconfig named by $HELM_KUBETEST_KUBECONFIG, such as
+a kind cluster. Tests using Setup are skipped when neither is set:
+
+	func TestInstall(t *testing.T) {
+		env := kubetest.Setup(t)
+		ns := env.Namespace(t)
+		cfg := env.ActionConfig(t, ns)
+		...
+		env.RequireRelease(t, cfg, "myrelease", release.StatusDeployed)
+		env.RequireResource(t, "v1", "ConfigMap", ns, "myrelease-config")
+	}
+
+A started API server runs no controllers, so workloads such as Deployments are
+stored but never become ready; attach to a cluster to test waiting on them.
+*/
+package kubetest // import "helm.sh/helm/v3/pkg/kube/kubetest"
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+const (
+	// AssetsEnvVar names the directory holding the etcd and kube-apiserver
+	// binaries to start, as with envtest.
+	AssetsEnvVar = "KUBEBUILDER_ASSETS"
+	// KubeConfigEnvVar names the kubeconfig of an existing cluster to attach
+	// to instead of starting an API server.
+	KubeConfigEnvVar = "HELM_KUBETEST_KUBECONFIG"
+)
+
+// ErrNoEnvironment is returned by Start when neither an API server to start
+// nor a cluster to attach to is configured.
+var ErrNoEnvironment = errors.Errorf("neither $%s nor $%s is set", AssetsEnvVar, KubeConfigEnvVar)
+
+// readyTimeout bounds how long Start waits for a started API server.
+var readyTimeout = time.Minute
+
+// Environment is a Kubernetes API server to run tests against.
+type Environment struct {
+	// Config is the configuration of the clients of the API server.
+	Config *rest.Config
+	// KubeConfig is the path of a kubeconfig file for the API server.
+	KubeConfig string
+
+	dir   string
+	plane *controlPlane
+}
+
+// Start starts or attaches to the environment configured by $KUBEBUILDER_ASSETS
+// or $HELM_KUBETEST_KUBECONFIG, attaching first. Call Stop once done.
+func Start() (*Environment, error) {
+	if path := os.Getenv(KubeConfigEnvVar); path != "" {
+		return Attach(path)
+	}
+	assets := os.Getenv(AssetsEnvVar)
+	if assets == "" {
+		return nil, ErrNoEnvironment
+	}
+	return StartAPIServer(assets)
+}
+
+// Attach returns an environment for the current context of the kubeconfig at
+// path. Stopping it leaves the cluster running.
+func Attach(path string) (*Environment, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load %s", path)
+	}
+	return &Environment{Config: config, KubeConfig: path}, nil
+}
+
+// StartAPIServer starts an etcd and a kube-apiserver from the binaries in the
+// assets directory and waits until the API server is ready.
+func StartAPIServer(assets string) (*Environment, error) {
+	dir, err := ioutil.TempDir("", "helm-kubetest-")
+	if err != nil {
+		return nil, err
+	}
+	cp, err := startControlPlane(assets, dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	env := &Environment{
+		Config: &rest.Config{
+			Host:            cp.host,
+			BearerToken:     adminToken,
+			TLSClientConfig: rest.TLSClientConfig{CAData: cp.caData},
+		},
+		KubeConfig: filepath.Join(dir, "kubeconfig"),
+		dir:        dir,
+		plane:      cp,
+	}
+	if err := env.writeKubeConfig(); err != nil {
+		env.Stop()
+		return nil, err
+	}
+	if err := env.waitReady(); err != nil {
+		env.Stop()
+		return nil, err
+	}
+	return env, nil
+}
+
+// Setup starts an environment for the test, stopping it once the test is
+// done. The test is skipped when no environment is configured or when tests
+// run with -short.
+func Setup(t *testing.T) *Environment {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	env, err := Start()
+	if errors.Is(err, ErrNoEnvironment) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := env.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	return env
+}
+
+// Stop stops a started API server and removes its data. Attached clusters are
+// left untouched.
+func (e *Environment) Stop() error {
+	if e.plane == nil {
+		return nil
+	}
+	e.plane.stop()
+	e.plane = nil
+	return os.RemoveAll(e.dir)
+}
+
+func (e *Environment) writeKubeConfig() error {
+	config := clientcmdapi.NewConfig()
+	config.Clusters["kubetest"] = &clientcmdapi.Cluster{
+		Server:                   e.Config.Host,
+		CertificateAuthorityData: e.Config.CAData,
+	}
+	config.AuthInfos["kubetest"] = &clientcmdapi.AuthInfo{Token: e.Config.BearerToken}
+	config.Contexts["kubetest"] = &clientcmdapi.Context{Cluster: "kubetest", AuthInfo: "kubetest"}
+	config.CurrentContext = "kubetest"
+	return clientcmd.WriteToFile(*config, e.KubeConfig)
+}
+
+// waitReady polls the readiness of a started API server, failing early with
+// the output of etcd or the API server if either exits.
+func (e *Environment) waitReady() error {
+	client, err := e.Clientset()
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	err = wait.PollImmediate(100*time.Millisecond, readyTimeout, func() (bool, error) {
+		if err := e.plane.exited(); err != nil {
+			return false, err
+		}
+		_, lastErr = client.Discovery().RESTClient().Get().AbsPath("/readyz").DoRaw(context.Background())
+		return lastErr == nil, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return errors.Wrapf(lastErr, "API server not ready after %s", readyTimeout)
+	}
+	return err
+}
+
+// Clientset returns a client of the API server.
+func (e *Environment) Clientset() (*kubernetes.Clientset, error) {
+	return kubernetes.NewForConfig(e.Config)
+}
+
+// Getter returns the client getter Helm uses to reach the API server, in the
+// namespace.
+func (e *Environment) Getter(namespace string) genericclioptions.RESTClientGetter {
+	flags := genericclioptions.NewConfigFlags(true)
+	flags.KubeConfig = &e.KubeConfig
+	flags.Namespace = &namespace
+	return flags
+}
+
+// ActionConfig returns an action configuration storing releases as secrets
+// of the namespace, logging to the test.
+func (e *Environment) ActionConfig(t *testing.T, namespace string) *action.Configuration {
+	t.Helper()
+	cfg := new(action.Configuration)
+	if err := cfg.Init(e.Getter(namespace), namespace, "secret", t.Logf); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+// Namespace creates a namespace of a random name for the test, deleting it
+// once the test is done.
+func (e *Environment) Namespace(t *testing.T) string {
+	t.Helper()
+	client, err := e.Clientset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kubetest-" + rand.String(8)}}
+	if _, err := client.CoreV1().Namespaces().Create(context.Background(), ns, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		client.CoreV1().Namespaces().Delete(context.Background(), ns.Name, metav1.DeleteOptions{})
+	})
+	return ns.Name
+}
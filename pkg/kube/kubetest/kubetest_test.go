@@ -0,0 +1,152 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubetest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// unsetEnv unsets the variables for the test, restoring them once it is done.
+func unsetEnv(t *testing.T, names ...string) {
+	for _, name := range names {
+		name := name
+		if value, ok := os.LookupEnv(name); ok {
+			os.Unsetenv(name)
+			t.Cleanup(func() { os.Setenv(name, value) })
+		}
+	}
+}
+
+func TestStartNoEnvironment(t *testing.T) {
+	unsetEnv(t, AssetsEnvVar, KubeConfigEnvVar)
+
+	if _, err := Start(); !errors.Is(err, ErrNoEnvironment) {
+		t.Fatalf("expected ErrNoEnvironment, got %v", err)
+	}
+}
+
+func TestAttach(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-kubetest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters["kind"] = &clientcmdapi.Cluster{Server: "https://127.0.0.1:6443"}
+	config.AuthInfos["kind"] = &clientcmdapi.AuthInfo{Token: "secret"}
+	config.Contexts["kind"] = &clientcmdapi.Context{Cluster: "kind", AuthInfo: "kind"}
+	config.CurrentContext = "kind"
+	path := filepath.Join(dir, "kubeconfig")
+	if err := clientcmd.WriteToFile(*config, path); err != nil {
+		t.Fatal(err)
+	}
+
+	unsetEnv(t, AssetsEnvVar, KubeConfigEnvVar)
+	os.Setenv(KubeConfigEnvVar, path)
+	defer os.Unsetenv(KubeConfigEnvVar)
+
+	env, err := Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.Config.Host != "https://127.0.0.1:6443" || env.Config.BearerToken != "secret" {
+		t.Errorf("unexpected config %+v", env.Config)
+	}
+	if env.KubeConfig != path {
+		t.Errorf("expected kubeconfig %q, got %q", path, env.KubeConfig)
+	}
+	if err := env.Stop(); err != nil {
+		t.Errorf("stopping an attached environment: %s", err)
+	}
+
+	if _, err := Attach(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected an error attaching to a missing kubeconfig")
+	}
+}
+
+func TestFindBinary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-kubetest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "etcd"), nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "kube-apiserver"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if path, err := findBinary(dir, "etcd"); err != nil || path != filepath.Join(dir, "etcd") {
+		t.Errorf("expected to find etcd, got %q, %v", path, err)
+	}
+	if _, err := findBinary(dir, "kube-apiserver"); err == nil {
+		t.Error("expected an error for a binary that is not executable")
+	}
+	if _, err := StartAPIServer(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected an error for missing binaries")
+	}
+}
+
+func TestInstallAndUninstall(t *testing.T) {
+	env := Setup(t)
+	ns := env.Namespace(t)
+	cfg := env.ActionConfig(t, ns)
+
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "kubetest", Version: "0.1.0"},
+		Templates: []*chart.File{{
+			Name: "templates/configmap.yaml",
+			Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Release.Name }}-config\ndata:\n  greeting: {{ .Values.greeting }}\n"),
+		}},
+	}
+
+	install := action.NewInstall(cfg)
+	install.ReleaseName = "kubetest"
+	install.Namespace = ns
+	install.Wait = true
+	install.Timeout = time.Minute
+	if _, err := install.Run(chrt, map[string]interface{}{"greeting": "hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rel := env.RequireRelease(t, cfg, "kubetest", release.StatusDeployed)
+	env.RequireReleaseResources(t, cfg, rel)
+	cm := env.RequireResource(t, "v1", "ConfigMap", ns, "kubetest-config")
+	if got := cm.Object["data"].(map[string]interface{})["greeting"]; got != "hello" {
+		t.Errorf("expected greeting %q, got %v", "hello", got)
+	}
+
+	if _, err := action.NewUninstall(cfg).Run("kubetest"); err != nil {
+		t.Fatal(err)
+	}
+	env.RequireNoResource(t, "v1", "ConfigMap", ns, "kubetest-config")
+}
@@ -0,0 +1,119 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// Mutator changes the objects the client creates or updates just before they
+// are sent to the API, to apply cluster policies to every release without
+// post-renderers, such as injecting labels or tolerations, or rewriting the
+// registries of images.
+//
+// The manifests of releases are left as rendered: mutators are applied again
+// to every object sent, so they must give the same result each time.
+type Mutator struct {
+	// Name identifies the mutator in errors.
+	Name string
+	// Kinds restricts the mutator to the objects of the given kinds. A kind
+	// without a version matches every version. No kinds match every object.
+	Kinds []schema.GroupVersionKind
+	// Namespaces restricts the mutator to the objects of the given
+	// namespaces, cluster-scoped objects being in the namespace "". No
+	// namespaces match every object.
+	Namespaces []string
+	// Mutate changes the object.
+	Mutate func(obj *unstructured.Unstructured) error
+}
+
+// matches returns whether the mutator applies to the objects of the given
+// kind and namespace.
+func (m *Mutator) matches(gvk schema.GroupVersionKind, namespace string) bool {
+	if len(m.Kinds) > 0 {
+		found := false
+		for _, k := range m.Kinds {
+			if k.Group == gvk.Group && k.Kind == gvk.Kind && (k.Version == "" || k.Version == gvk.Version) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(m.Namespaces) > 0 {
+		for _, ns := range m.Namespaces {
+			if ns == namespace {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// AddMutator registers a mutator, applied after the ones registered before
+// it. Mutators must be registered before the client is used.
+func (c *Client) AddMutator(m Mutator) {
+	c.mutators = append(c.mutators, m)
+}
+
+// mutated returns the object of a resource as it is to be sent to the API: a
+// copy changed by the mutators matching the resource, or the object itself if
+// none does.
+func (c *Client) mutated(info *resource.Info, obj runtime.Object) (runtime.Object, error) {
+	var gvk schema.GroupVersionKind
+	if info.Mapping != nil {
+		gvk = info.Mapping.GroupVersionKind
+	}
+	copied := false
+	for i := range c.mutators {
+		m := &c.mutators[i]
+		if !m.matches(gvk, info.Namespace) {
+			continue
+		}
+		if !copied {
+			obj, copied = obj.DeepCopyObject(), true
+		}
+		if err := mutate(m, obj); err != nil {
+			return nil, errors.Wrapf(err, "mutator %s failed on %s %q", m.Name, gvk.Kind, info.Name)
+		}
+	}
+	return obj, nil
+}
+
+// mutate applies a mutator to an object, converting typed objects to
+// unstructured ones and back.
+func mutate(m *Mutator, obj runtime.Object) error {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return m.Mutate(u)
+	}
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	u := &unstructured.Unstructured{Object: data}
+	if err := m.Mutate(u); err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj)
+}
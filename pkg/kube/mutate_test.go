@@ -0,0 +1,132 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestMutatorMatches(t *testing.T) {
+	pod := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	deployment := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+
+	tests := []struct {
+		name      string
+		mutator   Mutator
+		gvk       schema.GroupVersionKind
+		namespace string
+		want      bool
+	}{
+		{"no conditions", Mutator{}, pod, "default", true},
+		{"kind", Mutator{Kinds: []schema.GroupVersionKind{pod}}, pod, "default", true},
+		{"other kind", Mutator{Kinds: []schema.GroupVersionKind{pod}}, deployment, "default", false},
+		{"kind of any version", Mutator{Kinds: []schema.GroupVersionKind{{Group: "apps", Kind: "Deployment"}}}, deployment, "default", true},
+		{"kind of another version", Mutator{Kinds: []schema.GroupVersionKind{{Group: "apps", Version: "v1beta2", Kind: "Deployment"}}}, deployment, "default", false},
+		{"namespace", Mutator{Namespaces: []string{"default"}}, pod, "default", true},
+		{"other namespace", Mutator{Namespaces: []string{"default"}}, pod, "kube-system", false},
+		{"cluster-scoped", Mutator{Namespaces: []string{""}}, schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, "", true},
+	}
+	for _, tt := range tests {
+		if got := tt.mutator.matches(tt.gvk, tt.namespace); got != tt.want {
+			t.Errorf("%s: expected %t, got %t", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestCreateWithMutators(t *testing.T) {
+	list := newPodList("starfish")
+
+	var body string
+	c := newTestClient(t)
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			data, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				t.Fatalf("could not dump request: %s", err)
+			}
+			req.Body.Close()
+			body = string(data)
+			return newResponse(201, &list.Items[0])
+		}),
+	}
+	c.AddMutator(Mutator{
+		Name:  "team-label",
+		Kinds: []schema.GroupVersionKind{{Kind: "Pod"}},
+		Mutate: func(obj *unstructured.Unstructured) error {
+			obj.SetLabels(map[string]string{"team": "a"})
+			return nil
+		},
+	})
+	c.AddMutator(Mutator{
+		Name:       "mirror",
+		Namespaces: []string{"default"},
+		Mutate: func(obj *unstructured.Unstructured) error {
+			// Runs after the label was set
+			containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "containers")
+			for _, c := range containers {
+				container := c.(map[string]interface{})
+				container["image"] = "mirror.example.com/" + obj.GetLabels()["team"] + "/" + container["image"].(string)
+			}
+			return unstructured.SetNestedSlice(obj.Object, containers, "spec", "containers")
+		},
+	})
+	c.AddMutator(Mutator{
+		Name:  "services",
+		Kinds: []schema.GroupVersionKind{{Kind: "Service"}},
+		Mutate: func(obj *unstructured.Unstructured) error {
+			return errors.New("not a service")
+		},
+	})
+
+	resources, err := c.Build(objBody(&list), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Create(resources); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"labels":{"team":"a"}`, `"image":"mirror.example.com/a/abc/app:v4"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %s in the object created, got %s", want, body)
+		}
+	}
+
+	c.AddMutator(Mutator{
+		Name: "broken",
+		Mutate: func(obj *unstructured.Unstructured) error {
+			return errors.New("boom")
+		},
+	})
+	resources, err = c.Build(objBody(&list), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Create(resources)
+	if err == nil || !strings.Contains(err.Error(), `mutator broken failed on Pod "starfish": boom`) {
+		t.Errorf("expected the error of the mutator, got %v", err)
+	}
+}
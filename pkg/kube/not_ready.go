@@ -0,0 +1,317 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxNotReadyEvents is the number of recent events reported per resource.
+const maxNotReadyEvents = 5
+
+// maxNotReadyPods is the number of pods that are not ready reported per
+// workload.
+const maxNotReadyPods = 3
+
+// NotReadyResource describes a resource that had not become ready when a
+// watch timed out.
+type NotReadyResource struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Status is a summary of the last observed status of the resource.
+	Status string
+	// Events are the most recent events recorded for the resource.
+	Events []string
+	// Pods are the pods of a workload that are not ready, with the reasons
+	// their containers are waiting, such as ImagePullBackOff.
+	Pods []NotReadyResource
+}
+
+// NotReadyError is returned when resources do not become ready before the
+// timeout expires. It reports the resources that were still pending, with
+// their last observed status.
+type NotReadyError struct {
+	Timeout   time.Duration
+	Resources []NotReadyResource
+}
+
+func (e *NotReadyError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "timed out after %v waiting for %d resources to complete", e.Timeout, len(e.Resources))
+	for _, r := range e.Resources {
+		fmt.Fprintf(&b, "\n  %s %s/%s: %s", r.Kind, r.Namespace, r.Name, r.Status)
+		for _, ev := range r.Events {
+			fmt.Fprintf(&b, "\n    %s", ev)
+		}
+		for _, p := range r.Pods {
+			fmt.Fprintf(&b, "\n    Pod %s: %s", p.Name, p.Status)
+			for _, ev := range p.Events {
+				fmt.Fprintf(&b, "\n      %s", ev)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Unwrap returns wait.ErrWaitTimeout so that callers checking for a timeout
+// keep working.
+func (e *NotReadyError) Unwrap() error {
+	return wait.ErrWaitTimeout
+}
+
+// Cause implements the causer interface of github.com/pkg/errors.
+func (e *NotReadyError) Cause() error {
+	return wait.ErrWaitTimeout
+}
+
+// mergeNotReady combines several NotReadyErrors into one, sorting the resources.
+func mergeNotReady(timeout time.Duration, errs []*NotReadyError) *NotReadyError {
+	merged := &NotReadyError{Timeout: timeout}
+	for _, e := range errs {
+		merged.Resources = append(merged.Resources, e.Resources...)
+	}
+	sort.SliceStable(merged.Resources, func(i, j int) bool {
+		a, b := merged.Resources[i], merged.Resources[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return merged
+}
+
+// notReady builds the report for a resource that did not become ready. last
+// is the last observed version of the object and may be nil.
+func (c *Client) notReady(info *resource.Info, last runtime.Object, timeout time.Duration) *NotReadyError {
+	r := NotReadyResource{
+		Kind:      info.Mapping.GroupVersionKind.Kind,
+		Namespace: info.Namespace,
+		Name:      info.Name,
+		Status:    describeStatus(last),
+	}
+	events, err := c.recentEvents(r.Kind, r.Namespace, r.Name)
+	if err != nil {
+		c.Log("unable to list events for %s %s: %v", r.Kind, r.Name, err)
+	}
+	r.Events = events
+	return &NotReadyError{Timeout: timeout, Resources: []NotReadyResource{r}}
+}
+
+// describeStatus summarizes the status of a Job, a Pod, a workload or a
+// PersistentVolumeClaim.
+func describeStatus(obj runtime.Object) string {
+	switch o := obj.(type) {
+	case *batch.Job:
+		return fmt.Sprintf("active: %d, failed: %d, succeeded: %d", o.Status.Active, o.Status.Failed, o.Status.Succeeded)
+	case *appsv1.Deployment:
+		return fmt.Sprintf("ready replicas: %d/%d, updated replicas: %d", o.Status.ReadyReplicas, replicas(o.Spec.Replicas), o.Status.UpdatedReplicas)
+	case *appsv1.StatefulSet:
+		return fmt.Sprintf("ready replicas: %d/%d, updated replicas: %d", o.Status.ReadyReplicas, replicas(o.Spec.Replicas), o.Status.UpdatedReplicas)
+	case *appsv1.DaemonSet:
+		return fmt.Sprintf("ready pods: %d/%d, updated pods: %d", o.Status.NumberReady, o.Status.DesiredNumberScheduled, o.Status.UpdatedNumberScheduled)
+	case *appsv1.ReplicaSet:
+		return fmt.Sprintf("ready replicas: %d/%d", o.Status.ReadyReplicas, replicas(o.Spec.Replicas))
+	case *v1.PersistentVolumeClaim:
+		return fmt.Sprintf("phase: %s", o.Status.Phase)
+	case *v1.Pod:
+		status := fmt.Sprintf("phase: %s", o.Status.Phase)
+		var reasons []string
+		statuses := append([]v1.ContainerStatus{}, o.Status.InitContainerStatuses...)
+		for _, cs := range append(statuses, o.Status.ContainerStatuses...) {
+			if w := cs.State.Waiting; w != nil && w.Reason != "" {
+				reasons = append(reasons, fmt.Sprintf("container %s waiting: %s", cs.Name, w.Reason))
+			}
+			if t := cs.State.Terminated; t != nil && t.ExitCode != 0 {
+				reasons = append(reasons, fmt.Sprintf("container %s exited with code %d", cs.Name, t.ExitCode))
+			}
+		}
+		if len(reasons) > 0 {
+			status += ", " + strings.Join(reasons, ", ")
+		}
+		return status
+	case nil:
+		return "not observed"
+	default:
+		return "not ready"
+	}
+}
+
+// replicas returns the number of desired replicas of a workload, which
+// defaults to one.
+func replicas(r *int32) int32 {
+	if r == nil {
+		return 1
+	}
+	return *r
+}
+
+// recentEvents returns the most recent events involving the resource, oldest
+// first.
+func (c *Client) recentEvents(kind, namespace, name string) ([]string, error) {
+	client, err := c.getKubeClient()
+	if err != nil {
+		return nil, err
+	}
+	return listRecentEvents(client, kind, namespace, name)
+}
+
+func listRecentEvents(client kubernetes.Interface, kind, namespace, name string) ([]string, error) {
+	list, err := client.CoreV1().Events(namespace).List(context.Background(), metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", kind, name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return formatEvents(list.Items), nil
+}
+
+func formatEvents(items []v1.Event) []string {
+	sort.SliceStable(items, func(i, j int) bool {
+		return eventTime(items[i]).Before(eventTime(items[j]))
+	})
+	if len(items) > maxNotReadyEvents {
+		items = items[len(items)-maxNotReadyEvents:]
+	}
+	events := make([]string, 0, len(items))
+	for _, e := range items {
+		events = append(events, fmt.Sprintf("%s %s: %s", e.Type, e.Reason, strings.TrimSpace(e.Message)))
+	}
+	return events
+}
+
+func eventTime(e v1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	}
+	if !e.EventTime.IsZero() {
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
+
+// notReady diagnoses the resources that are still not ready once a wait timed
+// out: their last status, their recent events and, for workloads, the pods
+// that are not ready. It returns nil if every resource is ready by then.
+func (w *waiter) notReady(created ResourceList, waitForJobsEnabled bool) *NotReadyError {
+	nr := &NotReadyError{Timeout: w.timeout}
+	for _, v := range created {
+		if ok, err := w.resourceReady(v, waitForJobsEnabled); ok && err == nil {
+			continue
+		}
+
+		r := NotReadyResource{
+			Kind:      v.Mapping.GroupVersionKind.Kind,
+			Namespace: v.Namespace,
+			Name:      v.Name,
+			Status:    "not ready",
+		}
+		live, err := w.liveObject(v)
+		switch {
+		case err != nil:
+			r.Status = err.Error()
+		case live != nil:
+			r.Status = describeStatus(live)
+			r.Pods = w.notReadyPods(v.Namespace, v.Name, live)
+		}
+		if r.Events, err = listRecentEvents(w.c, r.Kind, r.Namespace, r.Name); err != nil {
+			w.log("unable to list events for %s %s: %v", r.Kind, r.Name, err)
+		}
+		nr.Resources = append(nr.Resources, r)
+	}
+	if len(nr.Resources) == 0 {
+		return nil
+	}
+	return nr
+}
+
+// liveObject gets the current state of the resources whose status can be
+// described. It returns nil for the others.
+func (w *waiter) liveObject(v *resource.Info) (runtime.Object, error) {
+	ctx := context.Background()
+	switch AsVersioned(v).(type) {
+	case *v1.Pod:
+		return w.c.CoreV1().Pods(v.Namespace).Get(ctx, v.Name, metav1.GetOptions{})
+	case *batch.Job:
+		return w.c.BatchV1().Jobs(v.Namespace).Get(ctx, v.Name, metav1.GetOptions{})
+	case *v1.PersistentVolumeClaim:
+		return w.c.CoreV1().PersistentVolumeClaims(v.Namespace).Get(ctx, v.Name, metav1.GetOptions{})
+	case *appsv1.Deployment, *appsv1beta1.Deployment, *appsv1beta2.Deployment, *extensionsv1beta1.Deployment:
+		return w.c.AppsV1().Deployments(v.Namespace).Get(ctx, v.Name, metav1.GetOptions{})
+	case *appsv1.StatefulSet, *appsv1beta1.StatefulSet, *appsv1beta2.StatefulSet:
+		return w.c.AppsV1().StatefulSets(v.Namespace).Get(ctx, v.Name, metav1.GetOptions{})
+	case *appsv1.DaemonSet, *appsv1beta2.DaemonSet, *extensionsv1beta1.DaemonSet:
+		return w.c.AppsV1().DaemonSets(v.Namespace).Get(ctx, v.Name, metav1.GetOptions{})
+	case *appsv1.ReplicaSet, *appsv1beta2.ReplicaSet, *extensionsv1beta1.ReplicaSet:
+		return w.c.AppsV1().ReplicaSets(v.Namespace).Get(ctx, v.Name, metav1.GetOptions{})
+	}
+	return nil, nil
+}
+
+// notReadyPods describes the pods of a workload that are neither ready nor
+// completed, with their recent events.
+func (w *waiter) notReadyPods(namespace, name string, obj runtime.Object) []NotReadyResource {
+	switch obj.(type) {
+	case *v1.Pod, *v1.PersistentVolumeClaim:
+		return nil
+	}
+	pods, err := w.podsforObject(namespace, obj)
+	if err != nil {
+		w.log("unable to list the pods of %s: %v", name, err)
+		return nil
+	}
+
+	var notReady []NotReadyResource
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == v1.PodSucceeded || w.isPodReady(pod) {
+			continue
+		}
+		r := NotReadyResource{
+			Kind:      "Pod",
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Status:    describeStatus(pod),
+		}
+		if r.Events, err = listRecentEvents(w.c, r.Kind, r.Namespace, r.Name); err != nil {
+			w.log("unable to list events for Pod %s: %v", r.Name, err)
+		}
+		if notReady = append(notReady, r); len(notReady) == maxNotReadyPods {
+			break
+		}
+	}
+	return notReady
+}
@@ -0,0 +1,117 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestNotReadyError(t *testing.T) {
+	err := mergeNotReady(time.Minute, []*NotReadyError{
+		{Resources: []NotReadyResource{{Kind: "Pod", Namespace: "default", Name: "migrate", Status: "phase: Pending"}}},
+		{Resources: []NotReadyResource{{
+			Kind: "Job", Namespace: "default", Name: "seed", Status: "active: 1, failed: 0, succeeded: 0",
+			Events: []string{"Normal SuccessfulCreate: Created pod: seed-abcde"},
+		}}},
+	})
+
+	expect := `timed out after 1m0s waiting for 2 resources to complete
+  Job default/seed: active: 1, failed: 0, succeeded: 0
+    Normal SuccessfulCreate: Created pod: seed-abcde
+  Pod default/migrate: phase: Pending`
+	if err.Error() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, err.Error())
+	}
+	if !errors.Is(err, wait.ErrWaitTimeout) {
+		t.Error("expected the error to unwrap to wait.ErrWaitTimeout")
+	}
+}
+
+func TestNotReadyErrorWithPods(t *testing.T) {
+	err := &NotReadyError{Timeout: time.Minute, Resources: []NotReadyResource{{
+		Kind: "Deployment", Namespace: "default", Name: "web", Status: "ready replicas: 0/1, updated replicas: 1",
+		Pods: []NotReadyResource{{
+			Kind: "Pod", Namespace: "default", Name: "web-abcde", Status: "phase: Pending, container main waiting: ImagePullBackOff",
+			Events: []string{"Warning Failed: Error: ImagePullBackOff"},
+		}},
+	}}}
+
+	expect := `timed out after 1m0s waiting for 1 resources to complete
+  Deployment default/web: ready replicas: 0/1, updated replicas: 1
+    Pod web-abcde: phase: Pending, container main waiting: ImagePullBackOff
+      Warning Failed: Error: ImagePullBackOff`
+	if err.Error() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, err.Error())
+	}
+}
+
+func TestDescribeStatus(t *testing.T) {
+	pod := &v1.Pod{Status: v1.PodStatus{
+		Phase: v1.PodPending,
+		ContainerStatuses: []v1.ContainerStatus{
+			{Name: "main", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+		},
+	}}
+	job := &batch.Job{Status: batch.JobStatus{Active: 1, Failed: 2}}
+	deployment := newDeployment("foo", 3, 1, 1)
+	deployment.Status.ReadyReplicas = 1
+	deployment.Status.UpdatedReplicas = 2
+
+	for _, tt := range []struct {
+		obj    runtime.Object
+		expect string
+	}{
+		{pod, "phase: Pending, container main waiting: ImagePullBackOff"},
+		{job, "active: 1, failed: 2, succeeded: 0"},
+		{deployment, "ready replicas: 1/3, updated replicas: 2"},
+		{newPersistentVolumeClaim("foo", v1.ClaimPending), "phase: Pending"},
+		{nil, "not observed"},
+	} {
+		got := describeStatus(tt.obj)
+		if got != tt.expect {
+			t.Errorf("expected %q, got %q", tt.expect, got)
+		}
+	}
+}
+
+func TestFormatEvents(t *testing.T) {
+	var events []v1.Event
+	for i := 0; i < 7; i++ {
+		events = append(events, v1.Event{
+			Type:          "Warning",
+			Reason:        "BackOff",
+			Message:       string(rune('a' + i)),
+			LastTimestamp: metav1.NewTime(time.Unix(int64(100-i), 0)),
+		})
+	}
+	got := formatEvents(events)
+	if len(got) != maxNotReadyEvents {
+		t.Fatalf("expected %d events, got %d", maxNotReadyEvents, len(got))
+	}
+	// The most recent event has the lowest index and is reported last.
+	if got[len(got)-1] != "Warning BackOff: a" || got[0] != "Warning BackOff: e" {
+		t.Errorf("unexpected events: %v", got)
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"context"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// QuotaCheck compares what the resources of a release request of a resource,
+// such as requests.cpu, with what a ResourceQuota of their namespace allows.
+type QuotaCheck struct {
+	Quota     string              `json:"quota"`
+	Resource  corev1.ResourceName `json:"resource"`
+	Hard      resource.Quantity   `json:"hard"`
+	Used      resource.Quantity   `json:"used"`
+	Requested resource.Quantity   `json:"requested"`
+}
+
+// Exceeded returns true if the requested quantity does not fit in what is left
+// of the quota.
+func (q QuotaCheck) Exceeded() bool {
+	total := q.Used.DeepCopy()
+	total.Add(q.Requested)
+	return total.Cmp(q.Hard) > 0
+}
+
+// quotaAliases are the quota resources that are accounted as others.
+var quotaAliases = map[corev1.ResourceName]corev1.ResourceName{
+	corev1.ResourceCPU:     corev1.ResourceRequestsCPU,
+	corev1.ResourceMemory:  corev1.ResourceRequestsMemory,
+	corev1.ResourceStorage: corev1.ResourceRequestsStorage,
+}
+
+// ResourceQuotas lists the ResourceQuotas of the namespace.
+func (c *Client) ResourceQuotas(namespace string) ([]corev1.ResourceQuota, error) {
+	client, err := c.getKubeClient()
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.CoreV1().ResourceQuotas(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// CheckQuotas compares the requested resources with each of the quotas that
+// limit them. Scoped quotas, which only apply to some pods, are left out.
+func CheckQuotas(quotas []corev1.ResourceQuota, requested corev1.ResourceList) []QuotaCheck {
+	var checks []QuotaCheck
+	for _, quota := range quotas {
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		for _, name := range sortedResourceNames(quota.Status.Hard) {
+			key := name
+			if alias, ok := quotaAliases[name]; ok {
+				key = alias
+			}
+			req, ok := requested[key]
+			if !ok {
+				continue
+			}
+			checks = append(checks, QuotaCheck{
+				Quota:     quota.Name,
+				Resource:  name,
+				Hard:      quota.Status.Hard[name],
+				Used:      quota.Status.Used[name],
+				Requested: req,
+			})
+		}
+	}
+	return checks
+}
+
+// RequestedResources sums the compute resources requested and limited by the
+// pods of the workloads, and the storage requested by the persistent volume
+// claims, of the resources, as ResourceQuotas account them. The pods of
+// DaemonSets and CronJobs are counted once.
+func RequestedResources(resources ResourceList) corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for _, info := range resources {
+		var (
+			spec     *corev1.PodSpec
+			replicas int32 = 1
+			claims   []corev1.PersistentVolumeClaim
+		)
+		switch obj := AsVersioned(info).(type) {
+		case *corev1.Pod:
+			spec = &obj.Spec
+		case *corev1.ReplicationController:
+			spec, replicas = &obj.Spec.Template.Spec, replicasOf(obj.Spec.Replicas)
+		case *appsv1.Deployment:
+			spec, replicas = &obj.Spec.Template.Spec, replicasOf(obj.Spec.Replicas)
+		case *appsv1.ReplicaSet:
+			spec, replicas = &obj.Spec.Template.Spec, replicasOf(obj.Spec.Replicas)
+		case *appsv1.StatefulSet:
+			spec, replicas = &obj.Spec.Template.Spec, replicasOf(obj.Spec.Replicas)
+			claims = obj.Spec.VolumeClaimTemplates
+		case *appsv1.DaemonSet:
+			spec = &obj.Spec.Template.Spec
+		case *batchv1.Job:
+			spec, replicas = &obj.Spec.Template.Spec, replicasOf(obj.Spec.Parallelism)
+		case *batchv1beta1.CronJob:
+			spec = &obj.Spec.JobTemplate.Spec.Template.Spec
+		case *corev1.PersistentVolumeClaim:
+			addResources(total, claimResources(obj), 1)
+		}
+		if spec != nil {
+			addResources(total, podResources(spec), replicas)
+		}
+		for i := range claims {
+			addResources(total, claimResources(&claims[i]), replicas)
+		}
+	}
+	return total
+}
+
+func sortedResourceNames(list corev1.ResourceList) []corev1.ResourceName {
+	names := make([]corev1.ResourceName, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+func replicasOf(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// podResources returns the resources of a pod: those of its containers, or
+// of its largest init container if it is larger, as the scheduler accounts
+// them.
+func podResources(spec *corev1.PodSpec) corev1.ResourceList {
+	total := corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1")}
+	for _, c := range spec.Containers {
+		addResources(total, containerResources(c), 1)
+	}
+	for _, c := range spec.InitContainers {
+		for name, q := range containerResources(c) {
+			if current, ok := total[name]; !ok || q.Cmp(current) > 0 {
+				total[name] = q
+			}
+		}
+	}
+	return total
+}
+
+// containerResources returns the CPU and memory requests and limits of a
+// container. Unset requests default to the limits.
+func containerResources(c corev1.Container) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if q, ok := c.Resources.Limits[name]; ok {
+			list["limits."+name] = q
+			list["requests."+name] = q
+		}
+		if q, ok := c.Resources.Requests[name]; ok {
+			list["requests."+name] = q
+		}
+	}
+	return list
+}
+
+func claimResources(claim *corev1.PersistentVolumeClaim) corev1.ResourceList {
+	list := corev1.ResourceList{corev1.ResourcePersistentVolumeClaims: resource.MustParse("1")}
+	if q, ok := claim.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		list[corev1.ResourceRequestsStorage] = q
+	}
+	return list
+}
+
+func addResources(total, list corev1.ResourceList, times int32) {
+	for name, q := range list {
+		sum := total[name]
+		for i := int32(0); i < times; i++ {
+			sum.Add(q)
+		}
+		total[name] = sum
+	}
+}
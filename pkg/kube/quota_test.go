@@ -0,0 +1,173 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const quotaManifest = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      initContainers:
+      - name: migrate
+        image: migrate
+        resources:
+          requests:
+            memory: 1Gi
+      containers:
+      - name: web
+        image: web
+        resources:
+          requests:
+            cpu: 100m
+            memory: 128Mi
+          limits:
+            cpu: 500m
+      - name: proxy
+        image: proxy
+        resources:
+          limits:
+            cpu: 100m
+            memory: 64Mi
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  replicas: 2
+  serviceName: db
+  selector:
+    matchLabels:
+      app: db
+  template:
+    metadata:
+      labels:
+        app: db
+    spec:
+      containers:
+      - name: db
+        image: db
+  volumeClaimTemplates:
+  - metadata:
+      name: data
+    spec:
+      accessModes: ["ReadWriteOnce"]
+      resources:
+        requests:
+          storage: 10Gi
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: shared
+spec:
+  accessModes: ["ReadWriteMany"]
+  resources:
+    requests:
+      storage: 5Gi
+`
+
+func TestRequestedResources(t *testing.T) {
+	c := newTestClient(t)
+	resources, err := c.Build(strings.NewReader(quotaManifest), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requested := RequestedResources(resources)
+	expected := map[corev1.ResourceName]string{
+		// 3 web pods and 2 db pods
+		corev1.ResourcePods: "5",
+		// The init container requests more memory than the containers
+		corev1.ResourceRequestsCPU:    "600m",
+		corev1.ResourceRequestsMemory: "3Gi",
+		corev1.ResourceLimitsCPU:      "1800m",
+		corev1.ResourceLimitsMemory:   "192Mi",
+		// 2 volume claims of the db and the shared claim
+		corev1.ResourcePersistentVolumeClaims: "3",
+		corev1.ResourceRequestsStorage:        "25Gi",
+	}
+	if len(requested) != len(expected) {
+		t.Errorf("expected %d resources, got %v", len(expected), requested)
+	}
+	for name, want := range expected {
+		got := requested[name]
+		if got.Cmp(resource.MustParse(want)) != 0 {
+			t.Errorf("expected %s to be %s, got %s", name, want, got.String())
+		}
+	}
+}
+
+func TestCheckQuotas(t *testing.T) {
+	requested := corev1.ResourceList{
+		corev1.ResourceRequestsCPU:    resource.MustParse("600m"),
+		corev1.ResourceRequestsMemory: resource.MustParse("1Gi"),
+	}
+	quotas := []corev1.ResourceQuota{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{
+					corev1.ResourceCPU:                    resource.MustParse("1"),
+					corev1.ResourceRequestsMemory:         resource.MustParse("4Gi"),
+					corev1.ResourcePersistentVolumeClaims: resource.MustParse("2"),
+				},
+				Used: corev1.ResourceList{
+					corev1.ResourceCPU:            resource.MustParse("500m"),
+					corev1.ResourceRequestsMemory: resource.MustParse("1Gi"),
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "best-effort"},
+			Spec:       corev1.ResourceQuotaSpec{Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort}},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("0")},
+			},
+		},
+	}
+
+	checks := CheckQuotas(quotas, requested)
+	if len(checks) != 2 {
+		t.Fatalf("expected 2 checks, got %+v", checks)
+	}
+	if c := checks[0]; c.Quota != "compute" || c.Resource != corev1.ResourceCPU || !c.Exceeded() {
+		t.Errorf("expected the cpu of the compute quota to be exceeded, got %+v", c)
+	}
+	if c := checks[1]; c.Resource != corev1.ResourceRequestsMemory || c.Exceeded() {
+		t.Errorf("expected the memory of the compute quota to fit, got %+v", c)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ready // import "helm.sh/helm/v3/pkg/kube/ready"
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CustomCheck reports whether a custom resource is ready, given its current
+// state. It is used for resources Kubernetes itself knows nothing about.
+type CustomCheck func(obj *unstructured.Unstructured) (bool, error)
+
+var (
+	customChecksMu sync.RWMutex
+	// customChecks are the checks for custom resources widely used in
+	// charts. A check only comes into play when a resource of its kind is
+	// checked, which requires the CRD to be installed.
+	customChecks = map[schema.GroupKind]CustomCheck{
+		// External Secrets Operator
+		{Group: "external-secrets.io", Kind: "ExternalSecret"}: ConditionCheck("Ready"),
+		// Sealed Secrets. Controllers before v0.13 do not report a status,
+		// the secret is then taken as ready once created.
+		{Group: "bitnami.com", Kind: "SealedSecret"}: optionalConditionCheck("Synced"),
+		// cert-manager
+		{Group: "cert-manager.io", Kind: "Certificate"}:   ConditionCheck("Ready"),
+		{Group: "cert-manager.io", Kind: "Issuer"}:        ConditionCheck("Ready"),
+		{Group: "cert-manager.io", Kind: "ClusterIssuer"}: ConditionCheck("Ready"),
+		// Strimzi Kafka operator
+		{Group: "kafka.strimzi.io", Kind: "Kafka"}:        ConditionCheck("Ready"),
+		{Group: "kafka.strimzi.io", Kind: "KafkaTopic"}:   ConditionCheck("Ready"),
+		{Group: "kafka.strimzi.io", Kind: "KafkaUser"}:    ConditionCheck("Ready"),
+		{Group: "kafka.strimzi.io", Kind: "KafkaConnect"}: ConditionCheck("Ready"),
+		// NATS JetStream controller
+		{Group: "jetstream.nats.io", Kind: "Stream"}:   ConditionCheck("Ready"),
+		{Group: "jetstream.nats.io", Kind: "Consumer"}: ConditionCheck("Ready"),
+	}
+)
+
+// RegisterCheck sets the check for custom resources of the given group and
+// kind, replacing any existing one. A nil check removes it, so that the
+// readiness of resources of the kind is no longer known.
+func RegisterCheck(gk schema.GroupKind, check CustomCheck) {
+	customChecksMu.Lock()
+	defer customChecksMu.Unlock()
+	if check == nil {
+		delete(customChecks, gk)
+		return
+	}
+	customChecks[gk] = check
+}
+
+// LookupCheck returns the check registered for custom resources of the given
+// group and kind.
+func LookupCheck(gk schema.GroupKind) (CustomCheck, bool) {
+	customChecksMu.RLock()
+	defer customChecksMu.RUnlock()
+	check, ok := customChecks[gk]
+	return check, ok
+}
+
+// ConditionCheck returns a check that is met when the status condition of the
+// given type is True, and the status reflects the latest generation of the
+// resource.
+func ConditionCheck(conditionType string) CustomCheck {
+	return func(obj *unstructured.Unstructured) (bool, error) {
+		status, found := conditionStatus(obj, conditionType)
+		return found && status, nil
+	}
+}
+
+// optionalConditionCheck is like ConditionCheck, but resources that report no
+// status at all are ready.
+func optionalConditionCheck(conditionType string) CustomCheck {
+	return func(obj *unstructured.Unstructured) (bool, error) {
+		if _, found, _ := unstructured.NestedMap(obj.Object, "status"); !found {
+			return true, nil
+		}
+		status, found := conditionStatus(obj, conditionType)
+		return found && status, nil
+	}
+}
+
+// conditionStatus returns whether the condition of the given type is True,
+// and whether it was found up to date.
+func conditionStatus(obj *unstructured.Unstructured, conditionType string) (bool, bool) {
+	generation := obj.GetGeneration()
+	if observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration"); found && observed < generation {
+		return false, false
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if t, _ := cond["type"].(string); t != conditionType {
+			continue
+		}
+		if observed, found, _ := unstructured.NestedInt64(cond, "observedGeneration"); found && observed < generation {
+			return false, false
+		}
+		s, _ := cond["status"].(string)
+		return strings.EqualFold(s, "True"), true
+	}
+	return false, false
+}
+
+// customResourceReady runs the check registered for the kind of a custom
+// resource.
+func customResourceReady(obj *unstructured.Unstructured) (Status, string, error) {
+	gvk := obj.GroupVersionKind()
+	check, found := LookupCheck(gvk.GroupKind())
+	if !found {
+		return Unknown, "", nil
+	}
+	ready, err := check(obj)
+	if err != nil {
+		return Unknown, "", err
+	}
+	if !ready {
+		return NotReady, fmt.Sprintf("%s is not ready: %s/%s", gvk.Kind, obj.GetNamespace(), obj.GetName()), nil
+	}
+	return Ready, "", nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ready
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newCustomResource(generation int64, status map[string]interface{}) *unstructured.Unstructured {
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":       "example",
+			"generation": generation,
+		},
+	}
+	if status != nil {
+		obj["status"] = status
+	}
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func readyCondition(status string) map[string]interface{} {
+	return map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": status},
+		},
+	}
+}
+
+func TestConditionCheck(t *testing.T) {
+	stale := readyCondition("True")
+	stale["observedGeneration"] = int64(1)
+
+	tests := []struct {
+		name string
+		obj  *unstructured.Unstructured
+		want bool
+	}{
+		{"ready", newCustomResource(1, readyCondition("True")), true},
+		{"not ready", newCustomResource(1, readyCondition("False")), false},
+		{"no status", newCustomResource(1, nil), false},
+		{"stale status", newCustomResource(2, stale), false},
+		{"stale condition", newCustomResource(2, map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True", "observedGeneration": int64(1)},
+			},
+		}), false},
+	}
+	check := ConditionCheck("Ready")
+	for _, tt := range tests {
+		got, err := check(tt.obj)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %t, got %t", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestSealedSecretReadiness(t *testing.T) {
+	check, ok := LookupCheck(schema.GroupKind{Group: "bitnami.com", Kind: "SealedSecret"})
+	if !ok {
+		t.Fatal("expected a readiness check for SealedSecrets")
+	}
+
+	synced := map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Synced", "status": "False"},
+		},
+	}
+	if ready, _ := check(newCustomResource(1, synced)); ready {
+		t.Error("expected a SealedSecret that failed to sync not to be ready")
+	}
+	if ready, _ := check(newCustomResource(1, nil)); !ready {
+		t.Error("expected a SealedSecret without a status to be ready")
+	}
+}
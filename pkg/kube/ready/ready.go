@@ -0,0 +1,437 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package ready checks whether Kubernetes resources are ready.
+
+It holds the health logic used by --wait, so that other tools, such as
+controllers or commands reporting on the status of a release, reach the
+same conclusions as Helm does:
+
+	checker := ready.NewChecker(clientset)
+	status, message, err := checker.CheckResource(ctx, deployment)
+
+The object given is taken as the current state of the resource. The objects
+readiness depends on, such as the ReplicaSets of a Deployment or the Pods of
+a ReplicaSet, are read with the client.
+*/
+package ready // import "helm.sh/helm/v3/pkg/kube/ready"
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	deploymentutil "helm.sh/helm/v3/internal/third_party/k8s.io/kubernetes/deployment/util"
+)
+
+// Status is the readiness of a resource.
+type Status string
+
+const (
+	// Ready means the resource is ready to be used.
+	Ready Status = "Ready"
+	// NotReady means the resource is not ready yet, and may become ready.
+	NotReady Status = "NotReady"
+	// Failed means the resource will not become ready without a change, such
+	// as a Job that exceeded its backoff limit.
+	Failed Status = "Failed"
+	// Unknown means no readiness check is known for the kind of the resource.
+	// Such resources are ready as soon as they exist.
+	Unknown Status = "Unknown"
+)
+
+// Checker checks the readiness of resources.
+type Checker struct {
+	client kubernetes.Interface
+}
+
+// NewChecker returns a Checker reading the objects readiness depends on with
+// the given client.
+func NewChecker(client kubernetes.Interface) *Checker {
+	return &Checker{client: client}
+}
+
+// CheckResource returns the readiness of a resource, given its current state,
+// with a message explaining why it is not ready.
+//
+// The object may be typed or unstructured. Workloads of older API versions,
+// such as apps/v1beta2 Deployments, are checked as their apps/v1 equivalent.
+// Custom resources are checked with the check registered for their kind, see
+// RegisterCheck.
+func (c *Checker) CheckResource(ctx context.Context, obj runtime.Object) (Status, string, error) {
+	obj, err := currentVersion(obj)
+	if err != nil {
+		return Unknown, "", err
+	}
+
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		return podStatus(o), podMessage(o), nil
+	case *batchv1.Job:
+		status, message := jobReady(o)
+		return status, message, nil
+	case *appsv1.Deployment:
+		// A paused deployment will never be ready
+		if o.Spec.Paused {
+			return Ready, "", nil
+		}
+		// Find the ReplicaSet associated with the deployment
+		rs, err := deploymentutil.GetNewReplicaSet(o, c.client.AppsV1())
+		if err != nil {
+			return Unknown, "", err
+		}
+		if rs == nil {
+			return NotReady, fmt.Sprintf("Deployment has no new ReplicaSet yet: %s/%s", o.Namespace, o.Name), nil
+		}
+		status, message := deploymentReady(rs, o)
+		return status, message, nil
+	case *corev1.PersistentVolumeClaim:
+		status, message := volumeReady(o)
+		return status, message, nil
+	case *corev1.Service:
+		status, message := serviceReady(o)
+		return status, message, nil
+	case *appsv1.DaemonSet:
+		status, message := daemonSetReady(o)
+		return status, message, nil
+	case *apiextv1.CustomResourceDefinition:
+		status, message := crdReady(o)
+		return status, message, nil
+	case *appsv1.StatefulSet:
+		status, message := statefulSetReady(o)
+		return status, message, nil
+	case *corev1.ReplicationController, *appsv1.ReplicaSet:
+		return c.podsReady(ctx, o)
+	case *unstructured.Unstructured:
+		return customResourceReady(o)
+	}
+	return Unknown, "", nil
+}
+
+// podsReady checks that every pod selected by a workload is ready.
+func (c *Checker) podsReady(ctx context.Context, obj runtime.Object) (Status, string, error) {
+	selector, err := SelectorsForObject(obj)
+	if err != nil {
+		return Unknown, "", err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return Unknown, "", err
+	}
+	list, err := c.client.CoreV1().Pods(accessor.GetNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return Unknown, "", err
+	}
+	for i := range list.Items {
+		if pod := &list.Items[i]; podStatus(pod) != Ready {
+			return NotReady, podMessage(pod), nil
+		}
+	}
+	return Ready, "", nil
+}
+
+// kinds are the kinds of Kubernetes resources readiness is checked for, with
+// the type of the version they are checked as.
+var kinds = map[schema.GroupKind]func() runtime.Object{
+	{Kind: "Pod"}:                   func() runtime.Object { return &corev1.Pod{} },
+	{Kind: "PersistentVolumeClaim"}: func() runtime.Object { return &corev1.PersistentVolumeClaim{} },
+	{Kind: "Service"}:               func() runtime.Object { return &corev1.Service{} },
+	{Kind: "ReplicationController"}: func() runtime.Object { return &corev1.ReplicationController{} },
+	{Group: "batch", Kind: "Job"}:   func() runtime.Object { return &batchv1.Job{} },
+
+	{Group: "apps", Kind: "Deployment"}:       func() runtime.Object { return &appsv1.Deployment{} },
+	{Group: "extensions", Kind: "Deployment"}: func() runtime.Object { return &appsv1.Deployment{} },
+	{Group: "apps", Kind: "DaemonSet"}:        func() runtime.Object { return &appsv1.DaemonSet{} },
+	{Group: "extensions", Kind: "DaemonSet"}:  func() runtime.Object { return &appsv1.DaemonSet{} },
+	{Group: "apps", Kind: "ReplicaSet"}:       func() runtime.Object { return &appsv1.ReplicaSet{} },
+	{Group: "extensions", Kind: "ReplicaSet"}: func() runtime.Object { return &appsv1.ReplicaSet{} },
+	{Group: "apps", Kind: "StatefulSet"}:      func() runtime.Object { return &appsv1.StatefulSet{} },
+
+	// The status of CustomResourceDefinitions is the same in v1beta1 and v1
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}: func() runtime.Object {
+		return &apiextv1.CustomResourceDefinition{}
+	},
+}
+
+// kindScheme knows the types of the Kubernetes resources, so that the kind of
+// typed objects without type metadata can be found.
+var kindScheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(scheme.AddToScheme(kindScheme))
+	utilruntime.Must(apiextv1beta1.AddToScheme(kindScheme))
+	utilruntime.Must(apiextv1.AddToScheme(kindScheme))
+}
+
+// currentVersion converts an object of a known kind to the type it is checked
+// as. Other objects are returned unchanged, typed custom resources being made
+// unstructured.
+func currentVersion(obj runtime.Object) (runtime.Object, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if _, ok := obj.(*unstructured.Unstructured); !ok {
+		if gvks, _, err := kindScheme.ObjectKinds(obj); err == nil {
+			gvk = gvks[0]
+		}
+	}
+	gk := gvk.GroupKind()
+
+	newObj, known := kinds[gk]
+	if !known {
+		if _, ok := obj.(*unstructured.Unstructured); ok || gk.Empty() {
+			return obj, nil
+		}
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		out := &unstructured.Unstructured{Object: u}
+		out.SetGroupVersionKind(gvk)
+		return out, nil
+	}
+
+	out := newObj()
+	if reflect.TypeOf(out) == reflect.TypeOf(obj) {
+		return obj, nil
+	}
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u, out); err != nil {
+		return nil, errors.Wrapf(err, "unable to convert %s", gk)
+	}
+	return out, nil
+}
+
+func podStatus(pod *corev1.Pod) Status {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue {
+			return Ready
+		}
+	}
+	return NotReady
+}
+
+func podMessage(pod *corev1.Pod) string {
+	if podStatus(pod) == Ready {
+		return ""
+	}
+	return fmt.Sprintf("Pod is not ready: %s/%s", pod.GetNamespace(), pod.GetName())
+}
+
+func jobReady(job *batchv1.Job) (Status, string) {
+	if job.Status.Failed >= *job.Spec.BackoffLimit {
+		return Failed, fmt.Sprintf("Job is failed: %s/%s", job.GetNamespace(), job.GetName())
+	}
+	if job.Status.Succeeded < *job.Spec.Completions {
+		return NotReady, fmt.Sprintf("Job is not completed: %s/%s", job.GetNamespace(), job.GetName())
+	}
+	return Ready, ""
+}
+
+func serviceReady(s *corev1.Service) (Status, string) {
+	// ExternalName Services are external to cluster so helm shouldn't be checking to see if they're 'ready' (i.e. have an IP Set)
+	if s.Spec.Type == corev1.ServiceTypeExternalName {
+		return Ready, ""
+	}
+
+	// Ensure that the service cluster IP is not empty
+	if s.Spec.ClusterIP == "" {
+		return NotReady, fmt.Sprintf("Service does not have cluster IP address: %s/%s", s.GetNamespace(), s.GetName())
+	}
+
+	// This checks if the service has a LoadBalancer and that balancer has an Ingress defined
+	if s.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		// do not wait when at least 1 external IP is set
+		if len(s.Spec.ExternalIPs) > 0 {
+			return Ready, fmt.Sprintf("Service %s/%s has external IP addresses (%v), marking as ready", s.GetNamespace(), s.GetName(), s.Spec.ExternalIPs)
+		}
+
+		if s.Status.LoadBalancer.Ingress == nil {
+			return NotReady, fmt.Sprintf("Service does not have load balancer ingress IP address: %s/%s", s.GetNamespace(), s.GetName())
+		}
+	}
+
+	return Ready, ""
+}
+
+func volumeReady(v *corev1.PersistentVolumeClaim) (Status, string) {
+	if v.Status.Phase != corev1.ClaimBound {
+		return NotReady, fmt.Sprintf("PersistentVolumeClaim is not bound: %s/%s", v.GetNamespace(), v.GetName())
+	}
+	return Ready, ""
+}
+
+func deploymentReady(rs *appsv1.ReplicaSet, dep *appsv1.Deployment) (Status, string) {
+	expectedReady := *dep.Spec.Replicas - deploymentutil.MaxUnavailable(*dep)
+	if !(rs.Status.ReadyReplicas >= expectedReady) {
+		return NotReady, fmt.Sprintf("Deployment is not ready: %s/%s. %d out of %d expected pods are ready", dep.Namespace, dep.Name, rs.Status.ReadyReplicas, expectedReady)
+	}
+	return Ready, ""
+}
+
+func daemonSetReady(ds *appsv1.DaemonSet) (Status, string) {
+	// If the update strategy is not a rolling update, there will be nothing to wait for
+	if ds.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
+		return Ready, ""
+	}
+
+	// Make sure all the updated pods have been scheduled
+	if ds.Status.UpdatedNumberScheduled != ds.Status.DesiredNumberScheduled {
+		return NotReady, fmt.Sprintf("DaemonSet is not ready: %s/%s. %d out of %d expected pods have been scheduled", ds.Namespace, ds.Name, ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled)
+	}
+	maxUnavailable, err := intstr.GetValueFromIntOrPercent(ds.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable, int(ds.Status.DesiredNumberScheduled), true)
+	if err != nil {
+		// If for some reason the value is invalid, set max unavailable to the
+		// number of desired replicas. This is the same behavior as the
+		// `MaxUnavailable` function in deploymentutil
+		maxUnavailable = int(ds.Status.DesiredNumberScheduled)
+	}
+
+	expectedReady := int(ds.Status.DesiredNumberScheduled) - maxUnavailable
+	if !(int(ds.Status.NumberReady) >= expectedReady) {
+		return NotReady, fmt.Sprintf("DaemonSet is not ready: %s/%s. %d out of %d expected pods are ready", ds.Namespace, ds.Name, ds.Status.NumberReady, expectedReady)
+	}
+	return Ready, ""
+}
+
+func crdReady(crd *apiextv1.CustomResourceDefinition) (Status, string) {
+	for _, cond := range crd.Status.Conditions {
+		switch cond.Type {
+		case apiextv1.Established:
+			if cond.Status == apiextv1.ConditionTrue {
+				return Ready, ""
+			}
+		case apiextv1.NamesAccepted:
+			if cond.Status == apiextv1.ConditionFalse {
+				// This indicates a naming conflict, but it's probably not the
+				// job of this function to fail because of that. Instead,
+				// we treat it as a success, since the process should be able to
+				// continue.
+				return Ready, ""
+			}
+		}
+	}
+	return NotReady, fmt.Sprintf("CustomResourceDefinition is not established: %s", crd.Name)
+}
+
+func statefulSetReady(sts *appsv1.StatefulSet) (Status, string) {
+	// If the update strategy is not a rolling update, there will be nothing to wait for
+	if sts.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return Ready, ""
+	}
+
+	// Dereference all the pointers because StatefulSets like them
+	var partition int
+	// 1 is the default for replicas if not set
+	var replicas = 1
+	// For some reason, even if the update strategy is a rolling update, the
+	// actual rollingUpdate field can be nil. If it is, we can safely assume
+	// there is no partition value
+	if sts.Spec.UpdateStrategy.RollingUpdate != nil && sts.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
+		partition = int(*sts.Spec.UpdateStrategy.RollingUpdate.Partition)
+	}
+	if sts.Spec.Replicas != nil {
+		replicas = int(*sts.Spec.Replicas)
+	}
+
+	// Because an update strategy can use partitioning, we need to calculate the
+	// number of updated replicas we should have. For example, if the replicas
+	// is set to 3 and the partition is 2, we'd expect only one pod to be
+	// updated
+	expectedReplicas := replicas - partition
+
+	// Make sure all the updated pods have been scheduled
+	if int(sts.Status.UpdatedReplicas) != expectedReplicas {
+		return NotReady, fmt.Sprintf("StatefulSet is not ready: %s/%s. %d out of %d expected pods have been scheduled", sts.Namespace, sts.Name, sts.Status.UpdatedReplicas, expectedReplicas)
+	}
+
+	if int(sts.Status.ReadyReplicas) != replicas {
+		return NotReady, fmt.Sprintf("StatefulSet is not ready: %s/%s. %d out of %d expected pods are ready", sts.Namespace, sts.Name, sts.Status.ReadyReplicas, replicas)
+	}
+	return Ready, ""
+}
+
+// SelectorsForObject returns the pod label selector for a given object
+//
+// Modified version of https://github.com/kubernetes/kubernetes/blob/v1.14.1/pkg/kubectl/polymorphichelpers/helpers.go#L84
+func SelectorsForObject(object runtime.Object) (selector labels.Selector, err error) {
+	switch t := object.(type) {
+	case *extensionsv1beta1.ReplicaSet:
+		selector, err = metav1.LabelSelectorAsSelector(t.Spec.Selector)
+	case *appsv1.ReplicaSet:
+		selector, err = metav1.LabelSelectorAsSelector(t.Spec.Selector)
+	case *appsv1beta2.ReplicaSet:
+		selector, err = metav1.LabelSelectorAsSelector(t.Spec.Selector)
+	case *corev1.ReplicationController:
+		selector = labels.SelectorFromSet(t.Spec.Selector)
+	case *appsv1.StatefulSet:
+		selector, err = metav1.LabelSelectorAsSelector(t.Spec.Selector)
+	case *appsv1beta1.StatefulSet:
+		selector, err = metav1.LabelSelectorAsSelector(t.Spec.Selector)
+	case *appsv1beta2.StatefulSet:
+		selector, err = metav1.LabelSelectorAsSelector(t.Spec.Selector)
+	case *extensionsv1beta1.DaemonSet:
+		selector, err = metav1.LabelSelectorAsSelector(t.Spec.Selector)
+	case *appsv1.DaemonSet:
+		selector, err = metav1.LabelSelectorAsSelector(t.Spec.Selector)
+	case *appsv1beta2.DaemonSet:
+		selector, err = metav1.LabelSelectorAsSelector(t.Spec.Selector)
+	case *extensionsv1beta1.Deployment:
+		selector, err = metav1.LabelSelectorAsSelector(t.Spec.Selector)
+	case *appsv1.Deployment:
+		selector, err = metav1.LabelSelectorAsSelector(t.Spec.Selector)
+	case *appsv1beta1.Deployment:
+		selector, err = metav1.LabelSelectorAsSelector(t.Spec.Selector)
+	case *appsv1beta2.Deployment:
+		selector, err = metav1.LabelSelectorAsSelector(t.Spec.Selector)
+	case *batchv1.Job:
+		selector, err = metav1.LabelSelectorAsSelector(t.Spec.Selector)
+	case *corev1.Service:
+		if t.Spec.Selector == nil || len(t.Spec.Selector) == 0 {
+			return nil, fmt.Errorf("invalid service '%s': Service is defined without a selector", t.Name)
+		}
+		selector = labels.SelectorFromSet(t.Spec.Selector)
+
+	default:
+		return nil, fmt.Errorf("selector for %T not implemented", object)
+	}
+
+	return selector, errors.Wrap(err, "invalid label selector")
+}
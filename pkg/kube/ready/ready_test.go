@@ -0,0 +1,573 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ready
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const defaultNamespace = metav1.NamespaceDefault
+
+func TestCheckResource(t *testing.T) {
+	toUnstructured := func(obj runtime.Object, apiVersion, kind string) *unstructured.Unstructured {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := &unstructured.Unstructured{Object: u}
+		out.SetAPIVersion(apiVersion)
+		out.SetKind(kind)
+		return out
+	}
+	betaStatefulSet := &appsv1beta2.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: defaultNamespace},
+		Spec: appsv1beta2.StatefulSetSpec{
+			Replicas:       intToInt32(2),
+			UpdateStrategy: appsv1beta2.StatefulSetUpdateStrategy{Type: appsv1beta2.RollingUpdateStatefulSetStrategyType},
+		},
+		Status: appsv1beta2.StatefulSetStatus{UpdatedReplicas: 2, ReadyReplicas: 1},
+	}
+	externalSecret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "external-secrets.io/v1beta1",
+		"kind":       "ExternalSecret",
+		"metadata":   map[string]interface{}{"name": "foo", "namespace": defaultNamespace},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			},
+		},
+	}}
+
+	tests := []struct {
+		name        string
+		obj         runtime.Object
+		wantStatus  Status
+		wantMessage string
+	}{
+		{
+			name:        "typed object",
+			obj:         newPersistentVolumeClaim("foo", corev1.ClaimPending),
+			wantStatus:  NotReady,
+			wantMessage: "PersistentVolumeClaim is not bound: default/foo",
+		},
+		{
+			name:        "unstructured object",
+			obj:         toUnstructured(newJob("foo", 1, 1, 0, 1), "batch/v1", "Job"),
+			wantStatus:  Failed,
+			wantMessage: "Job is failed: default/foo",
+		},
+		{
+			name:        "older API version",
+			obj:         betaStatefulSet,
+			wantStatus:  NotReady,
+			wantMessage: "StatefulSet is not ready: default/foo. 1 out of 2 expected pods are ready",
+		},
+		{
+			name:       "custom resource with a registered check",
+			obj:        externalSecret,
+			wantStatus: Ready,
+		},
+		{
+			name:       "kind without a check",
+			obj:        &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: defaultNamespace}},
+			wantStatus: Unknown,
+		},
+	}
+	c := NewChecker(fake.NewSimpleClientset())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, message, err := c.CheckResource(context.TODO(), tt.obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s", tt.wantStatus, status)
+			}
+			if message != tt.wantMessage {
+				t.Errorf("expected message %q, got %q", tt.wantMessage, message)
+			}
+		})
+	}
+}
+
+func Test_deploymentReady(t *testing.T) {
+	type args struct {
+		rs  *appsv1.ReplicaSet
+		dep *appsv1.Deployment
+	}
+	tests := []struct {
+		name string
+		args args
+		want Status
+	}{
+		{
+			name: "deployment is ready",
+			args: args{
+				rs:  newReplicaSet("foo", 1, 1),
+				dep: newDeployment("foo", 1, 1, 0),
+			},
+			want: Ready,
+		},
+		{
+			name: "deployment is not ready",
+			args: args{
+				rs:  newReplicaSet("foo", 0, 0),
+				dep: newDeployment("foo", 1, 1, 0),
+			},
+			want: NotReady,
+		},
+		{
+			name: "deployment is ready when maxUnavailable is set",
+			args: args{
+				rs:  newReplicaSet("foo", 2, 1),
+				dep: newDeployment("foo", 2, 1, 1),
+			},
+			want: Ready,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := deploymentReady(tt.args.rs, tt.args.dep); got != tt.want {
+				t.Errorf("deploymentReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_daemonSetReady(t *testing.T) {
+	type args struct {
+		ds *appsv1.DaemonSet
+	}
+	tests := []struct {
+		name string
+		args args
+		want Status
+	}{
+		{
+			name: "daemonset is ready",
+			args: args{
+				ds: newDaemonSet("foo", 0, 1, 1, 1),
+			},
+			want: Ready,
+		},
+		{
+			name: "daemonset is not ready",
+			args: args{
+				ds: newDaemonSet("foo", 0, 0, 1, 1),
+			},
+			want: NotReady,
+		},
+		{
+			name: "daemonset pods have not been scheduled successfully",
+			args: args{
+				ds: newDaemonSet("foo", 0, 0, 1, 0),
+			},
+			want: NotReady,
+		},
+		{
+			name: "daemonset is ready when maxUnavailable is set",
+			args: args{
+				ds: newDaemonSet("foo", 1, 1, 2, 2),
+			},
+			want: Ready,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := daemonSetReady(tt.args.ds); got != tt.want {
+				t.Errorf("daemonSetReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_statefulSetReady(t *testing.T) {
+	type args struct {
+		sts *appsv1.StatefulSet
+	}
+	tests := []struct {
+		name string
+		args args
+		want Status
+	}{
+		{
+			name: "statefulset is ready",
+			args: args{
+				sts: newStatefulSet("foo", 1, 0, 1, 1),
+			},
+			want: Ready,
+		},
+		{
+			name: "statefulset is not ready",
+			args: args{
+				sts: newStatefulSet("foo", 1, 0, 0, 1),
+			},
+			want: NotReady,
+		},
+		{
+			name: "statefulset is ready when partition is specified",
+			args: args{
+				sts: newStatefulSet("foo", 2, 1, 2, 1),
+			},
+			want: Ready,
+		},
+		{
+			name: "statefulset is not ready when partition is set",
+			args: args{
+				sts: newStatefulSet("foo", 1, 1, 1, 1),
+			},
+			want: NotReady,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := statefulSetReady(tt.args.sts); got != tt.want {
+				t.Errorf("statefulSetReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Checker_podsReady(t *testing.T) {
+	type args struct {
+		obj runtime.Object
+	}
+	tests := []struct {
+		name      string
+		args      args
+		existPods []corev1.Pod
+		want      Status
+		wantErr   bool
+	}{
+		{
+			name: "pods ready for a replicaset",
+			args: args{
+				obj: newReplicaSet("foo", 1, 1),
+			},
+			existPods: []corev1.Pod{
+				*newPodWithCondition("foo", corev1.ConditionTrue),
+			},
+			want:    Ready,
+			wantErr: false,
+		},
+		{
+			name: "pods not ready for a replicaset",
+			args: args{
+				obj: newReplicaSet("foo", 1, 1),
+			},
+			existPods: []corev1.Pod{
+				*newPodWithCondition("foo", corev1.ConditionFalse),
+			},
+			want:    NotReady,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker(fake.NewSimpleClientset())
+			for _, pod := range tt.existPods {
+				if _, err := c.client.CoreV1().Pods(defaultNamespace).Create(context.TODO(), &pod, metav1.CreateOptions{}); err != nil {
+					t.Errorf("Failed to create Pod error: %v", err)
+					return
+				}
+			}
+			got, _, err := c.podsReady(context.TODO(), tt.args.obj)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("podsReady() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("podsReady() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_jobReady(t *testing.T) {
+	type args struct {
+		job *batchv1.Job
+	}
+	tests := []struct {
+		name string
+		args args
+		want Status
+	}{
+		{
+			name: "job is completed",
+			args: args{job: newJob("foo", 1, 1, 1, 0)},
+			want: Ready,
+		},
+		{
+			name: "job is incomplete",
+			args: args{job: newJob("foo", 1, 1, 0, 0)},
+			want: NotReady,
+		},
+		{
+			name: "job is failed",
+			args: args{job: newJob("foo", 1, 1, 0, 1)},
+			want: Failed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := jobReady(tt.args.job); got != tt.want {
+				t.Errorf("jobReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_volumeReady(t *testing.T) {
+	type args struct {
+		v *corev1.PersistentVolumeClaim
+	}
+	tests := []struct {
+		name string
+		args args
+		want Status
+	}{
+		{
+			name: "pvc is bound",
+			args: args{
+				v: newPersistentVolumeClaim("foo", corev1.ClaimBound),
+			},
+			want: Ready,
+		},
+		{
+			name: "pvc is not ready",
+			args: args{
+				v: newPersistentVolumeClaim("foo", corev1.ClaimPending),
+			},
+			want: NotReady,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := volumeReady(tt.args.v); got != tt.want {
+				t.Errorf("volumeReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newDaemonSet(name string, maxUnavailable, numberReady, desiredNumberScheduled, updatedNumberScheduled int) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: defaultNamespace,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+				Type: appsv1.RollingUpdateDaemonSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+					MaxUnavailable: func() *intstr.IntOrString { i := intstr.FromInt(maxUnavailable); return &i }(),
+				},
+			},
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": name}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name,
+					Labels: map[string]string{"name": name},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Image: "nginx",
+						},
+					},
+				},
+			},
+		},
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: int32(desiredNumberScheduled),
+			NumberReady:            int32(numberReady),
+			UpdatedNumberScheduled: int32(updatedNumberScheduled),
+		},
+	}
+}
+
+func newStatefulSet(name string, replicas, partition, readyReplicas, updatedReplicas int) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: defaultNamespace,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{
+					Partition: intToInt32(partition),
+				},
+			},
+			Replicas: intToInt32(replicas),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": name}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name,
+					Labels: map[string]string{"name": name},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Image: "nginx",
+						},
+					},
+				},
+			},
+		},
+		Status: appsv1.StatefulSetStatus{
+			UpdatedReplicas: int32(updatedReplicas),
+			ReadyReplicas:   int32(readyReplicas),
+		},
+	}
+}
+
+func newDeployment(name string, replicas, maxSurge, maxUnavailable int) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: defaultNamespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{
+					MaxUnavailable: func() *intstr.IntOrString { i := intstr.FromInt(maxUnavailable); return &i }(),
+					MaxSurge:       func() *intstr.IntOrString { i := intstr.FromInt(maxSurge); return &i }(),
+				},
+			},
+			Replicas: intToInt32(replicas),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": name}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name,
+					Labels: map[string]string{"name": name},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Image: "nginx",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newReplicaSet(name string, replicas int, readyReplicas int) *appsv1.ReplicaSet {
+	d := newDeployment(name, replicas, 0, 0)
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       defaultNamespace,
+			Labels:          d.Spec.Selector.MatchLabels,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(d, d.GroupVersionKind())},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Selector: d.Spec.Selector,
+			Replicas: intToInt32(replicas),
+			Template: d.Spec.Template,
+		},
+		Status: appsv1.ReplicaSetStatus{
+			ReadyReplicas: int32(readyReplicas),
+		},
+	}
+}
+
+func newPodWithCondition(name string, podReadyCondition corev1.ConditionStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: defaultNamespace,
+			Labels:    map[string]string{"name": name},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Image: "nginx",
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{
+					Type:   corev1.PodReady,
+					Status: podReadyCondition,
+				},
+			},
+		},
+	}
+}
+
+func newPersistentVolumeClaim(name string, phase corev1.PersistentVolumeClaimPhase) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: defaultNamespace,
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Phase: phase,
+		},
+	}
+}
+
+func newJob(name string, backoffLimit, completions, succeeded, failed int) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: defaultNamespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: intToInt32(backoffLimit),
+			Completions:  intToInt32(completions),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name,
+					Labels: map[string]string{"name": name},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Image: "nginx",
+						},
+					},
+				},
+			},
+		},
+		Status: batchv1.JobStatus{
+			Succeeded: int32(succeeded),
+			Failed:    int32(failed),
+		},
+	}
+}
+
+func intToInt32(i int) *int32 {
+	i32 := int32(i)
+	return &i32
+}
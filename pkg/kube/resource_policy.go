@@ -24,3 +24,23 @@ const ResourcePolicyAnno = "helm.sh/resource-policy"
 // This resource policy type allows resources to skip being deleted
 //   during an uninstallRelease action.
 const KeepPolicy = "keep"
+
+// ApplyPolicyAnno is the annotation name for the apply policy of a resource
+const ApplyPolicyAnno = "helm.sh/apply-policy"
+
+// CreateOnlyPolicy is the apply policy type for create-only
+//
+// This apply policy type allows resources to be created if they do not
+//   exist, but never to be patched afterwards.
+const CreateOnlyPolicy = "create-only"
+
+// PatchOnlyPolicy is the apply policy type for patch-only
+//
+// This apply policy type allows existing resources to be patched, but never
+//   to be created.
+const PatchOnlyPolicy = "patch-only"
+
+// SkipPolicy is the apply policy type for skip
+//
+// This apply policy type allows resources to be neither created nor patched.
+const SkipPolicy = "skip"
@@ -16,6 +16,56 @@ limitations under the License.
 
 package kube
 
+import (
+	"time"
+
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// Outcome is what an API call did to a resource.
+type Outcome string
+
+// Outcomes of the API calls on a resource.
+const (
+	// OutcomeCreated means the resource did not exist and was created.
+	OutcomeCreated Outcome = "created"
+	// OutcomePatched means the resource was changed with a patch.
+	OutcomePatched Outcome = "patched"
+	// OutcomeUnchanged means the resource already matched its target.
+	OutcomeUnchanged Outcome = "unchanged"
+	// OutcomeReplaced means the resource was replaced, as updates are forced.
+	OutcomeReplaced Outcome = "replaced"
+	// OutcomeRecreated means the resource was deleted and created again, as
+	// it could not be replaced.
+	OutcomeRecreated Outcome = "recreated"
+	// OutcomeDeleted means the resource was deleted.
+	OutcomeDeleted Outcome = "deleted"
+	// OutcomeSkipped means the resource was left alone because of its apply
+	// policy.
+	OutcomeSkipped Outcome = "skipped"
+	// OutcomeNotFound means the resource to delete did not exist.
+	OutcomeNotFound Outcome = "not-found"
+	// OutcomeFailed means the API call on the resource failed.
+	OutcomeFailed Outcome = "failed"
+)
+
+// ResourceResult is the outcome of an API call on a single resource.
+type ResourceResult struct {
+	Kind      string  `json:"kind"`
+	Name      string  `json:"name"`
+	Namespace string  `json:"namespace,omitempty"`
+	Outcome   Outcome `json:"outcome"`
+	// PatchSize is the size in bytes of the patch sent for patched resources
+	PatchSize int `json:"patchSize,omitempty"`
+	// Duration is how long the API calls on the resource took
+	Duration time.Duration `json:"duration"`
+	// Error is the error of the API call for failed resources
+	Error string `json:"error,omitempty"`
+	// Warnings are the warnings the API server returned for the calls on the
+	// resource, such as for the use of a deprecated API
+	Warnings []string `json:"warnings,omitempty"`
+}
+
 // Result contains the information of created, updated, and deleted resources
 // for various kube API calls along with helper methods for using those
 // resources
@@ -23,6 +73,79 @@ type Result struct {
 	Created ResourceList
 	Updated ResourceList
 	Deleted ResourceList
+
+	// Resources is the outcome of the call on each resource, in the order the
+	// resources were processed
+	Resources []ResourceResult
+}
+
+// Changed returns true if any resource was created, patched, replaced or
+// deleted.
+func (r *Result) Changed() bool {
+	for _, res := range r.Resources {
+		switch res.Outcome {
+		case OutcomeUnchanged, OutcomeSkipped, OutcomeNotFound, OutcomeFailed:
+		default:
+			return true
+		}
+	}
+	return false
 }
 
-// If needed, we can add methods to the Result type for things like diffing
+// Outcomes returns the resources with the given outcome.
+func (r *Result) Outcomes(outcome Outcome) []ResourceResult {
+	var out []ResourceResult
+	for _, res := range r.Resources {
+		if res.Outcome == outcome {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+// record adds the outcome of a call on a resource that started at the given
+// time.
+func (r *Result) record(info *resource.Info, outcome Outcome, patchSize int, start time.Time) {
+	res := ResourceResult{
+		Name:      info.Name,
+		Namespace: info.Namespace,
+		Outcome:   outcome,
+		PatchSize: patchSize,
+		Duration:  time.Since(start),
+	}
+	if info.Mapping != nil {
+		res.Kind = info.Mapping.GroupVersionKind.Kind
+	} else if info.Object != nil {
+		res.Kind = info.Object.GetObjectKind().GroupVersionKind().Kind
+	}
+	r.Resources = append(r.Resources, res)
+}
+
+// Warnings returns the resources the API server returned warnings for.
+func (r *Result) Warnings() []ResourceResult {
+	var out []ResourceResult
+	for _, res := range r.Resources {
+		if len(res.Warnings) > 0 {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+// addWarnings attaches warnings to the resources recorded since the given
+// index of Resources.
+func (r *Result) addWarnings(from int, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	for i := from; i < len(r.Resources); i++ {
+		r.Resources[i].Warnings = append(r.Resources[i].Warnings, warnings...)
+	}
+}
+
+// recordFailure adds the failure of a call on a resource that started at the
+// given time.
+func (r *Result) recordFailure(info *resource.Info, err error, start time.Time) {
+	r.record(info, OutcomeFailed, 0, start)
+	r.Resources[len(r.Resources)-1].Error = err.Error()
+}
@@ -0,0 +1,237 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// WaitCondition describes a condition that resources of a release must meet,
+// in the spirit of 'kubectl wait --for'.
+//
+// It is written as
+//
+//	[KIND[/NAME]:]condition=TYPE[=STATUS]
+//	[KIND[/NAME]:]jsonpath=EXPRESSION=VALUE
+//
+// for example "condition=Ready", "Deployment/web:condition=Available" or
+// "Pod:jsonpath={.status.phase}=Running".
+type WaitCondition struct {
+	// Kind restricts the condition to resources of the given kind. It is
+	// matched case-insensitively.
+	Kind string
+	// Name restricts the condition to the resource with the given name.
+	Name string
+
+	// ConditionType is the type of the status condition to wait for.
+	ConditionType string
+	// ConditionStatus is the expected status of the condition. It defaults
+	// to "True".
+	ConditionStatus string
+
+	// JSONPath is evaluated against the live object and compared with Value.
+	JSONPath string
+	// Value is the expected result of JSONPath.
+	Value string
+}
+
+var waitConditionTarget = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9.]*(/[a-z0-9]([-a-z0-9.]*[a-z0-9])?)?:`)
+
+// ParseWaitCondition parses a condition in the form described by WaitCondition.
+func ParseWaitCondition(s string) (WaitCondition, error) {
+	var w WaitCondition
+
+	spec := s
+	if target := waitConditionTarget.FindString(spec); target != "" {
+		spec = spec[len(target):]
+		target = strings.TrimSuffix(target, ":")
+		parts := strings.SplitN(target, "/", 2)
+		w.Kind = parts[0]
+		if len(parts) == 2 {
+			w.Name = parts[1]
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(spec, "condition="):
+		parts := strings.SplitN(strings.TrimPrefix(spec, "condition="), "=", 2)
+		w.ConditionType = parts[0]
+		w.ConditionStatus = "True"
+		if len(parts) == 2 {
+			w.ConditionStatus = parts[1]
+		}
+		if w.ConditionType == "" || w.ConditionStatus == "" {
+			return w, errors.Errorf("invalid wait condition %q: expected condition=TYPE[=STATUS]", s)
+		}
+	case strings.HasPrefix(spec, "jsonpath="):
+		expr := strings.TrimPrefix(spec, "jsonpath=")
+		i := strings.LastIndex(expr, "=")
+		if i <= 0 || i == len(expr)-1 {
+			return w, errors.Errorf("invalid wait condition %q: expected jsonpath=EXPRESSION=VALUE", s)
+		}
+		w.JSONPath, w.Value = expr[:i], expr[i+1:]
+		if err := jsonpath.New("wait").Parse(w.JSONPath); err != nil {
+			return w, errors.Wrapf(err, "invalid wait condition %q", s)
+		}
+	default:
+		return w, errors.Errorf("invalid wait condition %q: must start with condition= or jsonpath=", s)
+	}
+	return w, nil
+}
+
+// String returns the condition in the form accepted by ParseWaitCondition.
+func (w WaitCondition) String() string {
+	var b strings.Builder
+	if w.Kind != "" {
+		b.WriteString(w.Kind)
+		if w.Name != "" {
+			b.WriteString("/" + w.Name)
+		}
+		b.WriteString(":")
+	}
+	if w.JSONPath != "" {
+		fmt.Fprintf(&b, "jsonpath=%s=%s", w.JSONPath, w.Value)
+	} else {
+		fmt.Fprintf(&b, "condition=%s=%s", w.ConditionType, w.ConditionStatus)
+	}
+	return b.String()
+}
+
+// targeted reports whether the condition names the resources it applies to.
+func (w WaitCondition) targeted() bool {
+	return w.Kind != ""
+}
+
+// Matches reports whether the condition applies to the resource.
+func (w WaitCondition) Matches(info *resource.Info) bool {
+	if w.Kind != "" {
+		if info.Mapping == nil || !strings.EqualFold(info.Mapping.GroupVersionKind.Kind, w.Kind) {
+			return false
+		}
+	}
+	return w.Name == "" || w.Name == info.Name
+}
+
+// IsMet evaluates the condition against a live object in unstructured form.
+func (w WaitCondition) IsMet(obj map[string]interface{}) (bool, error) {
+	met, _, err := w.evaluate(obj)
+	return met, err
+}
+
+// evaluate evaluates the condition against a live object in unstructured
+// form, and also reports whether the object has the condition type or the
+// field the condition is about.
+func (w WaitCondition) evaluate(obj map[string]interface{}) (met, reported bool, err error) {
+	if w.JSONPath != "" {
+		j := jsonpath.New("wait")
+		if err := j.Parse(w.JSONPath); err != nil {
+			return false, false, err
+		}
+		results, err := j.FindResults(obj)
+		if err != nil {
+			// The field may not have been populated yet.
+			return false, false, nil
+		}
+		if len(results) != 1 || len(results[0]) != 1 {
+			return false, true, errors.Errorf("jsonpath %s must resolve to exactly one value", w.JSONPath)
+		}
+		return fmt.Sprint(results[0][0].Interface()) == w.Value, true, nil
+	}
+
+	status, _ := obj["status"].(map[string]interface{})
+	conditions, _ := status["conditions"].([]interface{})
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if t, _ := cond["type"].(string); strings.EqualFold(t, w.ConditionType) {
+			s, _ := cond["status"].(string)
+			return strings.EqualFold(s, w.ConditionStatus), true, nil
+		}
+	}
+	return false, false, nil
+}
+
+// WaitForCondition waits until every resource matched by a condition meets
+// it, or the timeout expires.
+//
+// Conditions without a kind only apply to the resources that report the
+// condition type, or the field of the jsonpath: waiting for condition=Ready
+// waits for the Pods of a release, but not for its Services or Deployments,
+// which have no such condition. Such a condition is still pending until at
+// least one resource reports it. Conditions with a kind apply to all the
+// resources they match.
+func (c *Client) WaitForCondition(resources ResourceList, timeout time.Duration, conditions ...WaitCondition) error {
+	if len(conditions) == 0 {
+		return nil
+	}
+	c.Log("waiting up to %v for %d conditions on %d resources", timeout, len(conditions), len(resources))
+
+	var pending []string
+	err := poll(c.clock(), pollInterval, timeout, true, func() (bool, error) {
+		pending = pending[:0]
+		reported := make([]bool, len(conditions))
+		for _, info := range resources {
+			obj, err := resource.NewHelper(info.Client, info.Mapping).Get(info.Namespace, info.Name)
+			if err != nil {
+				return false, errors.Wrapf(err, "unable to get %s %q", info.Mapping.GroupVersionKind.Kind, info.Name)
+			}
+			u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+			if err != nil {
+				return false, err
+			}
+			for i, w := range conditions {
+				if !w.Matches(info) {
+					continue
+				}
+				met, ok, err := w.evaluate(u)
+				if err != nil {
+					return false, err
+				}
+				if !ok && !w.targeted() {
+					continue
+				}
+				reported[i] = true
+				if !met {
+					pending = append(pending, fmt.Sprintf("%s/%s (%s)", info.Mapping.GroupVersionKind.Kind, info.Name, w))
+				}
+			}
+		}
+		for i, w := range conditions {
+			if !reported[i] && !w.targeted() {
+				pending = append(pending, fmt.Sprintf("any resource (%s)", w))
+			}
+		}
+		return len(pending) == 0, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		sort.Strings(pending)
+		return errors.Errorf("timed out waiting for conditions: %s", strings.Join(pending, ", "))
+	}
+	return err
+}
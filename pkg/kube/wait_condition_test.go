@@ -0,0 +1,236 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestParseWaitCondition(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    WaitCondition
+		wantErr bool
+	}{
+		{in: "condition=Ready", want: WaitCondition{ConditionType: "Ready", ConditionStatus: "True"}},
+		{in: "condition=Ready=False", want: WaitCondition{ConditionType: "Ready", ConditionStatus: "False"}},
+		{in: "Deployment/web:condition=Available", want: WaitCondition{Kind: "Deployment", Name: "web", ConditionType: "Available", ConditionStatus: "True"}},
+		{in: "jsonpath={.status.phase}=Running", want: WaitCondition{JSONPath: "{.status.phase}", Value: "Running"}},
+		{in: "Pod:jsonpath={.status.phase}=Running", want: WaitCondition{Kind: "Pod", JSONPath: "{.status.phase}", Value: "Running"}},
+		{in: "condition=", wantErr: true},
+		{in: "jsonpath={.status.phase}", wantErr: true},
+		{in: "jsonpath={.status.phase=Running", wantErr: true},
+		{in: "delete", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseWaitCondition(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: expected error %t, got %v", tt.in, tt.wantErr, err)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: expected %+v, got %+v", tt.in, tt.want, got)
+		}
+		if roundTrip, err := ParseWaitCondition(got.String()); err != nil || roundTrip != got {
+			t.Errorf("%q: %q does not round-trip: %+v, %v", tt.in, got.String(), roundTrip, err)
+		}
+	}
+}
+
+func TestWaitConditionIsMet(t *testing.T) {
+	obj := map[string]interface{}{
+		"status": map[string]interface{}{
+			"phase": "Running",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+				map[string]interface{}{"type": "Initialized", "status": "False"},
+			},
+		},
+	}
+
+	tests := []struct {
+		cond string
+		want bool
+	}{
+		{"condition=Ready", true},
+		{"condition=ready", true},
+		{"condition=Initialized", false},
+		{"condition=Initialized=False", true},
+		{"condition=Missing", false},
+		{"jsonpath={.status.phase}=Running", true},
+		{"jsonpath={.status.phase}=Pending", false},
+		{"jsonpath={.status.podIP}=10.0.0.1", false},
+	}
+	for _, tt := range tests {
+		w, err := ParseWaitCondition(tt.cond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := w.IsMet(obj)
+		if err != nil {
+			t.Errorf("%s: %v", tt.cond, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %t, got %t", tt.cond, tt.want, got)
+		}
+	}
+}
+
+func TestWaitConditionMatches(t *testing.T) {
+	info := &resource.Info{
+		Name: "web",
+		Mapping: &meta.RESTMapping{
+			GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		},
+	}
+
+	for cond, want := range map[string]bool{
+		"condition=Available":                true,
+		"deployment:condition=Available":     true,
+		"Deployment/web:condition=Available": true,
+		"Deployment/api:condition=Available": false,
+		"Pod:condition=Ready":                false,
+	} {
+		w, err := ParseWaitCondition(cond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := w.Matches(info); got != want {
+			t.Errorf("%s: expected %t, got %t", cond, want, got)
+		}
+	}
+}
+
+const waitConditionManifest = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: web
+    image: nginx
+`
+
+func TestWaitForConditionMixedResources(t *testing.T) {
+	podReady := v1.ConditionFalse
+
+	c := newTestClient(t)
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch p := req.URL.Path; {
+			case strings.HasSuffix(p, "/services/web"):
+				return newResponse(200, &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+			case strings.HasSuffix(p, "/deployments/web"):
+				return newResponse(200, &appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{
+						{Type: appsv1.DeploymentAvailable, Status: v1.ConditionTrue},
+						{Type: appsv1.DeploymentProgressing, Status: v1.ConditionTrue},
+					}},
+				})
+			case strings.HasSuffix(p, "/pods/web"):
+				pod := newPodWithStatus("web", v1.PodStatus{Conditions: []v1.PodCondition{
+					{Type: v1.PodReady, Status: podReady},
+				}}, "")
+				return newResponse(200, &pod)
+			}
+			t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+			return nil, nil
+		}),
+	}
+	resources, err := c.Build(strings.NewReader(waitConditionManifest), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cond := func(s string) WaitCondition {
+		w, err := ParseWaitCondition(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return w
+	}
+
+	// Only the Pod reports the Ready condition, so it is the only one waited for
+	err = c.WaitForCondition(resources, 10*time.Millisecond, cond("condition=Ready"))
+	if err == nil || !strings.Contains(err.Error(), "Pod/web (condition=Ready=True)") {
+		t.Fatalf("expected the Pod to be pending, got %v", err)
+	}
+	if strings.Contains(err.Error(), "Service") || strings.Contains(err.Error(), "Deployment") {
+		t.Errorf("expected the Service and the Deployment to be skipped, got %v", err)
+	}
+
+	podReady = v1.ConditionTrue
+	if err := c.WaitForCondition(resources, 10*time.Millisecond, cond("condition=Ready"), cond("condition=Available")); err != nil {
+		t.Errorf("expected the conditions to be met, got %v", err)
+	}
+
+	// A condition that no resource reports is never met
+	err = c.WaitForCondition(resources, 10*time.Millisecond, cond("condition=Complete"))
+	if err == nil || !strings.Contains(err.Error(), "any resource (condition=Complete=True)") {
+		t.Errorf("expected the condition to be pending, got %v", err)
+	}
+
+	// A condition with a kind applies to every resource of the kind
+	err = c.WaitForCondition(resources, 10*time.Millisecond, cond("Service:condition=Ready"))
+	if err == nil || !strings.Contains(err.Error(), "Service/web (Service:condition=Ready=True)") {
+		t.Errorf("expected the Service to be pending, got %v", err)
+	}
+}
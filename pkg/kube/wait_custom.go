@@ -0,0 +1,51 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v3/pkg/kube/ready"
+)
+
+// ReadinessCheck reports whether a custom resource is ready, given its live
+// state. It is used by --wait for resources Kubernetes itself knows nothing
+// about.
+type ReadinessCheck = ready.CustomCheck
+
+// RegisterReadinessCheck sets the check --wait uses for custom resources of
+// the given group and kind, replacing any existing one. A nil check removes
+// it, so that resources of the kind are no longer waited for.
+func RegisterReadinessCheck(gk schema.GroupKind, check ReadinessCheck) {
+	ready.RegisterCheck(gk, check)
+}
+
+// readinessCheckFor returns the check registered for the kind of a resource.
+func readinessCheckFor(info *resource.Info) (ReadinessCheck, bool) {
+	if info.Mapping == nil {
+		return nil, false
+	}
+	return ready.LookupCheck(info.Mapping.GroupVersionKind.GroupKind())
+}
+
+// ConditionReadinessCheck returns a check that is met when the status
+// condition of the given type is True, and the status reflects the latest
+// generation of the resource.
+func ConditionReadinessCheck(conditionType string) ReadinessCheck {
+	return ready.ConditionCheck(conditionType)
+}
@@ -0,0 +1,42 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+func TestRegisterReadinessCheck(t *testing.T) {
+	gk := schema.GroupKind{Group: "example.com", Kind: "Widget"}
+	info := &resource.Info{Mapping: &meta.RESTMapping{GroupVersionKind: gk.WithVersion("v1")}}
+
+	if _, ok := readinessCheckFor(info); ok {
+		t.Fatal("expected no readiness check for an unknown kind")
+	}
+	RegisterReadinessCheck(gk, ConditionReadinessCheck("Available"))
+	if _, ok := readinessCheckFor(info); !ok {
+		t.Error("expected the registered readiness check")
+	}
+	RegisterReadinessCheck(gk, nil)
+	if _, ok := readinessCheckFor(info); ok {
+		t.Error("expected the readiness check to be removed")
+	}
+}
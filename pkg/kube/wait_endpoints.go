@@ -0,0 +1,146 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1beta1 "k8s.io/api/discovery/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// WaitForEndpoints waits until each Service of the resources that selects
+// Pods has at least minReady ready endpoints. The endpoints are read from the
+// EndpointSlices of the Service, or from its Endpoints if the cluster does not
+// serve EndpointSlices.
+//
+// ExternalName Services and Services without a selector are not waited for.
+func (c *Client) WaitForEndpoints(resources ResourceList, timeout time.Duration, minReady int) error {
+	if minReady <= 0 {
+		return nil
+	}
+	cs, err := c.getKubeClient()
+	if err != nil {
+		return err
+	}
+	w := waiter{
+		c:       cs,
+		log:     c.Log,
+		timeout: timeout,
+		clock:   c.clock(),
+	}
+	c.Log("waiting up to %v for Services to have %d ready endpoints", timeout, minReady)
+
+	var pending []string
+	err = poll(w.clock, pollInterval, timeout, true, func() (bool, error) {
+		pending = pending[:0]
+		for _, v := range resources {
+			if _, ok := AsVersioned(v).(*corev1.Service); !ok {
+				continue
+			}
+			svc, err := cs.CoreV1().Services(v.Namespace).Get(context.Background(), v.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			ready, err := w.serviceEndpointsReady(svc, minReady)
+			if err != nil {
+				return false, err
+			}
+			if !ready {
+				pending = append(pending, fmt.Sprintf("%s/%s", svc.Namespace, svc.Name))
+			}
+		}
+		return len(pending) == 0, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return errors.Errorf("timed out waiting for %d ready endpoints of Services: %s", minReady, strings.Join(pending, ", "))
+	}
+	return err
+}
+
+// serviceEndpointsReady returns true if the Service has at least minReady
+// ready endpoints, or does not need to be waited for.
+func (w *waiter) serviceEndpointsReady(svc *corev1.Service, minReady int) (bool, error) {
+	if svc.Spec.Type == corev1.ServiceTypeExternalName || len(svc.Spec.Selector) == 0 {
+		return true, nil
+	}
+
+	ready, err := w.readyEndpointSlices(svc)
+	if apierrors.IsNotFound(err) {
+		// The cluster does not serve EndpointSlices
+		ready, err = w.readyEndpoints(svc)
+	}
+	if err != nil {
+		return false, err
+	}
+	if ready < minReady {
+		w.log("Service is not ready: %s/%s. %d out of %d expected endpoints are ready", svc.Namespace, svc.Name, ready, minReady)
+		return false, nil
+	}
+	return true, nil
+}
+
+// readyEndpointSlices counts the ready endpoints of the EndpointSlices of a
+// Service. Dual-stack Services have a slice per address type, so the largest
+// count of an address type is returned.
+func (w *waiter) readyEndpointSlices(svc *corev1.Service) (int, error) {
+	slices, err := w.c.DiscoveryV1beta1().EndpointSlices(svc.Namespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: discoveryv1beta1.LabelServiceName + "=" + svc.Name,
+	})
+	if err != nil {
+		return 0, err
+	}
+	counts := map[discoveryv1beta1.AddressType]int{}
+	for _, slice := range slices.Items {
+		for _, ep := range slice.Endpoints {
+			// A nil ready condition is an unknown state, to be interpreted as ready
+			if ep.Conditions.Ready == nil || *ep.Conditions.Ready {
+				counts[slice.AddressType]++
+			}
+		}
+	}
+	var ready int
+	for _, n := range counts {
+		if n > ready {
+			ready = n
+		}
+	}
+	return ready, nil
+}
+
+// readyEndpoints counts the ready addresses of the Endpoints of a Service.
+func (w *waiter) readyEndpoints(svc *corev1.Service) (int, error) {
+	ep, err := w.c.CoreV1().Endpoints(svc.Namespace).Get(context.Background(), svc.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var ready int
+	for _, subset := range ep.Subsets {
+		ready += len(subset.Addresses)
+	}
+	return ready, nil
+}
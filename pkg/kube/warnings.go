@@ -0,0 +1,70 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v3/pkg/kube"
+
+import (
+	"sync"
+
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest"
+)
+
+// warningCapture keeps the warnings the API server returns for the calls on a
+// resource, such as for the use of a deprecated API.
+type warningCapture struct {
+	info   *resource.Info
+	client resource.RESTClient
+
+	mu       sync.Mutex
+	warnings []string
+}
+
+// captureWarnings makes the calls on the resource keep the warnings the API
+// server returns, until done is called.
+func captureWarnings(info *resource.Info) *warningCapture {
+	w := &warningCapture{info: info, client: info.Client}
+	if info.Client != nil {
+		info.Client = resource.NewClientWithOptions(info.Client, func(r *rest.Request) {
+			r.WarningHandler(w)
+		})
+	}
+	return w
+}
+
+// HandleWarningHeader keeps the warnings meant for users, each once.
+func (w *warningCapture) HandleWarningHeader(code int, _ string, text string) {
+	// Only 299 warnings are meant for users, see RFC 7234
+	if code != 299 || text == "" {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, seen := range w.warnings {
+		if seen == text {
+			return
+		}
+	}
+	w.warnings = append(w.warnings, text)
+}
+
+// done restores the client of the resource and returns the warnings captured.
+func (w *warningCapture) done() []string {
+	w.info.Client = w.client
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.warnings
+}
@@ -19,19 +19,27 @@ package lint // import "helm.sh/helm/v3/pkg/lint"
 import (
 	"path/filepath"
 
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/lint/rules"
 	"helm.sh/helm/v3/pkg/lint/support"
 )
 
 // All runs all of the available linters on the given base directory.
 func All(basedir string, values map[string]interface{}, namespace string, strict bool) support.Linter {
+	return AllWithCapabilities(basedir, values, namespace, strict, nil)
+}
+
+// AllWithCapabilities runs all of the available linters on the given base
+// directory, rendering the templates with the given capabilities. The default
+// capabilities are used if nil.
+func AllWithCapabilities(basedir string, values map[string]interface{}, namespace string, strict bool, caps *chartutil.Capabilities) support.Linter {
 	// Using abs path to get directory context
 	chartDir, _ := filepath.Abs(basedir)
 
 	linter := support.Linter{ChartDir: chartDir}
 	rules.Chartfile(&linter)
 	rules.ValuesWithOverrides(&linter, values)
-	rules.Templates(&linter, values, namespace, strict)
+	rules.TemplatesWithCapabilities(&linter, values, namespace, strict, caps)
 	rules.Dependencies(&linter)
 	return linter
 }
@@ -65,6 +65,8 @@ func Chartfile(linter *support.Linter) {
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartIconURL(chartFile))
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartType(chartFile))
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartDependencies(chartFile))
+	linter.RunLinterRule(support.WarningSev, chartFileName, validateChartDeprecation(chartFile))
+	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartCompatibility(chartFile))
 }
 
 func validateChartVersionType(data map[string]interface{}) error {
@@ -196,6 +198,35 @@ func validateChartType(cf *chart.Metadata) error {
 	return nil
 }
 
+func validateChartDeprecation(cf *chart.Metadata) error {
+	if cf.ReplacedBy != "" && !cf.Deprecated {
+		return errors.New("replacedBy is set but the chart is not deprecated")
+	}
+	return nil
+}
+
+func validateChartCompatibility(cf *chart.Metadata) error {
+	for i, c := range cf.Compatibility {
+		if c == nil {
+			return errors.Errorf("compatibility entry %d is empty", i)
+		}
+		for _, f := range []struct{ name, constraint string }{
+			{"version", c.Version},
+			{"appVersion", c.AppVersion},
+			{"kubeVersion", c.KubeVersion},
+			{"upgradeFrom", c.UpgradeFrom},
+		} {
+			if f.constraint == "" {
+				continue
+			}
+			if _, err := semver.NewConstraint(f.constraint); err != nil {
+				return errors.Errorf("compatibility entry %d: %s '%s' is not a valid SemVer constraint", i, f.name, f.constraint)
+			}
+		}
+	}
+	return nil
+}
+
 // loadChartFileForTypeCheck loads the Chart.yaml
 // in a generic form of a map[string]interface{}, so that the type
 // of the values can be checked
@@ -184,6 +184,34 @@ func TestValidateChartIconURL(t *testing.T) {
 	}
 }
 
+func TestValidateChartDeprecation(t *testing.T) {
+	md := &chart.Metadata{ReplacedBy: "example/newchart"}
+	if err := validateChartDeprecation(md); err == nil {
+		t.Errorf("validateChartDeprecation to return a linter error, got no error")
+	}
+
+	md.Deprecated = true
+	if err := validateChartDeprecation(md); err != nil {
+		t.Errorf("validateChartDeprecation to return no error, got %s", err.Error())
+	}
+}
+
+func TestValidateChartCompatibility(t *testing.T) {
+	md := &chart.Metadata{Compatibility: []*chart.Compatibility{
+		{Version: "^1.0.0", AppVersion: ">=2.1", KubeVersion: ">=1.18.0-0", UpgradeFrom: ">=0.9.0"},
+	}}
+	if err := validateChartCompatibility(md); err != nil {
+		t.Errorf("validateChartCompatibility to return no error, got %s", err.Error())
+	}
+
+	md.Compatibility = append(md.Compatibility, &chart.Compatibility{KubeVersion: "not a range"})
+	if err := validateChartCompatibility(md); err == nil {
+		t.Errorf("validateChartCompatibility to return a linter error, got no error")
+	} else if !strings.Contains(err.Error(), "compatibility entry 1: kubeVersion") {
+		t.Errorf("unexpected error %q", err)
+	}
+}
+
 func TestChartfile(t *testing.T) {
 	t.Run("Chart.yaml basic validity issues", func(t *testing.T) {
 		linter := support.Linter{ChartDir: badChartDir}
@@ -18,12 +18,14 @@ package rules // import "helm.sh/helm/v3/pkg/lint/rules"
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/pkg/errors"
 
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/lint/support"
 )
 
@@ -38,6 +40,7 @@ func Dependencies(linter *support.Linter) {
 
 	linter.RunLinterRule(support.ErrorSev, linter.ChartDir, validateDependencyInMetadata(c))
 	linter.RunLinterRule(support.WarningSev, linter.ChartDir, validateDependencyInChartsDir(c))
+	linter.RunLinterRule(support.ErrorSev, linter.ChartDir, validateDependencyValuesFiles(c))
 }
 
 func validateChartFormat(chartError error) error {
@@ -80,3 +83,22 @@ func validateDependencyInMetadata(c *chart.Chart) (err error) {
 	}
 	return err
 }
+
+func validateDependencyValuesFiles(c *chart.Chart) error {
+	files := map[string][]byte{}
+	for _, f := range c.Files {
+		files[f.Name] = f.Data
+	}
+	for _, dep := range c.Metadata.Dependencies {
+		for _, name := range dep.ValuesFiles {
+			data, ok := files[path.Clean(name)]
+			if !ok {
+				return errors.Errorf("values file %q of dependency %s does not exist", name, dep.Name)
+			}
+			if _, err := chartutil.ReadValues(data); err != nil {
+				return errors.Wrapf(err, "values file %q of dependency %s is not valid YAML", name, dep.Name)
+			}
+		}
+	}
+	return nil
+}
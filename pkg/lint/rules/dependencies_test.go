@@ -97,3 +97,30 @@ func TestDependencies(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateDependencyValuesFiles(t *testing.T) {
+	c := chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "umbrella",
+			Version:    "0.1.0",
+			APIVersion: "v2",
+			Dependencies: []*chart.Dependency{
+				{Name: "redis", ValuesFiles: []string{"values/redis.yaml"}},
+			},
+		},
+	}
+
+	if err := validateDependencyValuesFiles(&c); err == nil {
+		t.Error("chart should have been flagged for a missing values file")
+	}
+
+	c.Files = []*chart.File{{Name: "values/redis.yaml", Data: []byte("replicas: [")}}
+	if err := validateDependencyValuesFiles(&c); err == nil {
+		t.Error("chart should have been flagged for an invalid values file")
+	}
+
+	c.Files[0].Data = []byte("replicas: 3\n")
+	if err := validateDependencyValuesFiles(&c); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,313 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/lint/support"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// hookEvents are the events hooks can run on, with the name Helm 2 tests used.
+var hookEvents = map[string]release.HookEvent{
+	release.HookPreInstall.String():   release.HookPreInstall,
+	release.HookPostInstall.String():  release.HookPostInstall,
+	release.HookPreDelete.String():    release.HookPreDelete,
+	release.HookPostDelete.String():   release.HookPostDelete,
+	release.HookPreUpgrade.String():   release.HookPreUpgrade,
+	release.HookPostUpgrade.String():  release.HookPostUpgrade,
+	release.HookPreRollback.String():  release.HookPreRollback,
+	release.HookPostRollback.String(): release.HookPostRollback,
+	release.HookTest.String():         release.HookTest,
+	release.HookMigration.String():    release.HookMigration,
+	"test-success":                    release.HookTest,
+}
+
+var hookDeletePolicies = map[string]bool{
+	release.HookSucceeded.String():          true,
+	release.HookFailed.String():             true,
+	release.HookBeforeHookCreation.String(): true,
+}
+
+// hookObject stubs the fields of a rendered object its hook annotations and
+// service account are read from.
+type hookObject struct {
+	Kind     string
+	Metadata struct {
+		Name        string
+		Annotations map[string]string
+	}
+	Spec struct {
+		ServiceAccountName string `json:"serviceAccountName"`
+		Template           struct {
+			Spec struct {
+				ServiceAccountName string `json:"serviceAccountName"`
+			}
+		}
+	}
+}
+
+func (o *hookObject) serviceAccountName() string {
+	switch o.Kind {
+	case "Pod":
+		return o.Spec.ServiceAccountName
+	case "Job":
+		return o.Spec.Template.Spec.ServiceAccountName
+	}
+	return ""
+}
+
+// serviceAccount is a ServiceAccount rendered by the chart, and the hook
+// events and weight it is created on if it is a hook.
+type serviceAccount struct {
+	events []release.HookEvent
+	weight int
+}
+
+// validateHooks lints the hook annotations of the rendered templates: the
+// hook events, weights, delete policies, timeouts and time to live, and the
+// service accounts the Pods and Jobs of the hooks run as, which must exist
+// when they run.
+//
+// These mistakes are otherwise only found at install time, when Helm skips
+// the hook or ignores the annotation, or the hook fails.
+func validateHooks(linter *support.Linter, chrt *chart.Chart, rendered map[string]string) {
+	objects := map[string][]*hookObject{}
+	accounts := map[string][]serviceAccount{}
+
+	names := make([]string, 0, len(rendered))
+	for name := range rendered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if ext := filepath.Ext(name); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		docs := releaseutil.SplitManifests(rendered[name])
+		keys := make([]string, 0, len(docs))
+		for k := range docs {
+			keys = append(keys, k)
+		}
+		sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+		for _, k := range keys {
+			var o hookObject
+			if err := yaml.Unmarshal([]byte(docs[k]), &o); err != nil {
+				// The templates rule reports the documents that do not parse
+				continue
+			}
+			objects[name] = append(objects[name], &o)
+			if o.Kind == "ServiceAccount" {
+				sa := serviceAccount{}
+				if hooks, ok := o.Metadata.Annotations[release.HookAnnotation]; ok {
+					sa.events, _ = parseHookEvents(hooks)
+					sa.weight, _ = strconv.Atoi(o.Metadata.Annotations[release.HookWeightAnnotation])
+				}
+				accounts[o.Metadata.Name] = append(accounts[o.Metadata.Name], sa)
+			}
+		}
+	}
+
+	// Only the hooks of the chart itself are reported, those of its
+	// dependencies being linted with them
+	for _, template := range chrt.Templates {
+		for _, o := range objects[path.Join(chrt.Name(), template.Name)] {
+			hooks, ok := o.Metadata.Annotations[release.HookAnnotation]
+			if !ok {
+				linter.RunLinterRule(support.WarningSev, template.Name, validateNoHookAnnotations(o))
+				continue
+			}
+			events, err := parseHookEvents(hooks)
+			linter.RunLinterRule(support.ErrorSev, template.Name, err)
+			linter.RunLinterRule(support.ErrorSev, template.Name, validateHookWeight(o))
+			linter.RunLinterRule(support.ErrorSev, template.Name, validateHookDeletePolicies(o))
+			linter.RunLinterRule(support.ErrorSev, template.Name, validateHookDurations(o))
+			linter.RunLinterRule(support.ErrorSev, template.Name, validateMigrationHook(o, events))
+			linter.RunLinterRule(support.WarningSev, template.Name, validateHookServiceAccount(o, events, accounts))
+		}
+	}
+}
+
+// parseHookEvents returns the events of a hook annotation. The crd-install
+// hooks of Helm 2 are left out, as validateNoCRDHooks reports them.
+func parseHookEvents(value string) ([]release.HookEvent, error) {
+	var events []release.HookEvent
+	var unknown []string
+	for _, e := range strings.Split(value, ",") {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if event, ok := hookEvents[e]; ok {
+			events = append(events, event)
+		} else if e != "crd-install" {
+			unknown = append(unknown, e)
+		}
+	}
+	if len(unknown) > 0 {
+		return events, errors.Errorf("unknown hook events %q in %s: the hook would be skipped", unknown, release.HookAnnotation)
+	}
+	return events, nil
+}
+
+// validateNoHookAnnotations reports the hook annotations of objects that are
+// not hooks, which are ignored.
+func validateNoHookAnnotations(o *hookObject) error {
+	var ignored []string
+	for _, a := range []string{
+		release.HookWeightAnnotation,
+		release.HookDeleteAnnotation,
+		release.HookTimeoutAnnotation,
+		release.HookTTLAnnotation,
+		release.HookSuccessConditionAnnotation,
+	} {
+		if _, ok := o.Metadata.Annotations[a]; ok {
+			ignored = append(ignored, a)
+		}
+	}
+	if len(ignored) > 0 {
+		return errors.Errorf("%s %q has %s but no %s annotation: they are ignored", o.Kind, o.Metadata.Name, strings.Join(ignored, ", "), release.HookAnnotation)
+	}
+	return nil
+}
+
+func validateHookWeight(o *hookObject) error {
+	w, ok := o.Metadata.Annotations[release.HookWeightAnnotation]
+	if !ok {
+		return nil
+	}
+	if _, err := strconv.Atoi(strings.TrimSpace(w)); err != nil {
+		return errors.Errorf("invalid %s %q on %s %q: it must be an integer, and is otherwise taken as 0", release.HookWeightAnnotation, w, o.Kind, o.Metadata.Name)
+	}
+	return nil
+}
+
+func validateHookDeletePolicies(o *hookObject) error {
+	value, ok := o.Metadata.Annotations[release.HookDeleteAnnotation]
+	if !ok {
+		return nil
+	}
+	policies := map[string]bool{}
+	for _, p := range strings.Split(value, ",") {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if !hookDeletePolicies[p] {
+			return errors.Errorf("unknown %s %q on %s %q", release.HookDeleteAnnotation, p, o.Kind, o.Metadata.Name)
+		}
+		if policies[p] {
+			return errors.Errorf("%s %q is repeated on %s %q", release.HookDeleteAnnotation, p, o.Kind, o.Metadata.Name)
+		}
+		policies[p] = true
+	}
+	if _, ok := o.Metadata.Annotations[release.HookTTLAnnotation]; ok && policies[release.HookSucceeded.String()] {
+		return errors.Errorf("%s %q conflicts with %s %q: successful hooks are deleted at once", release.HookTTLAnnotation, o.Metadata.Annotations[release.HookTTLAnnotation], release.HookDeleteAnnotation, release.HookSucceeded)
+	}
+	return nil
+}
+
+func validateHookDurations(o *hookObject) error {
+	for _, a := range []string{release.HookTimeoutAnnotation, release.HookTTLAnnotation} {
+		value, ok := o.Metadata.Annotations[a]
+		if !ok {
+			continue
+		}
+		if !validHookSeconds(value, a == release.HookTimeoutAnnotation) {
+			return errors.Errorf("invalid %s %q on %s %q: it must be a duration, such as \"5m\", or a number of seconds, and is otherwise ignored", a, value, o.Kind, o.Metadata.Name)
+		}
+	}
+	return nil
+}
+
+// validateMigrationHook checks that migration hooks are Jobs, as Helm refuses
+// to run them otherwise.
+func validateMigrationHook(o *hookObject, events []release.HookEvent) error {
+	for _, e := range events {
+		if e == release.HookMigration && o.Kind != "Job" {
+			return errors.Errorf("%s %q is a %s hook: migrations must be Jobs", o.Kind, o.Metadata.Name, release.HookMigration)
+		}
+	}
+	return nil
+}
+
+// validHookSeconds returns true if the value is a non-negative number of
+// seconds or duration, as Helm parses them, or a positive one.
+func validHookSeconds(value string, positive bool) bool {
+	var secs int64
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		secs = n
+	} else if d, err := time.ParseDuration(value); err == nil {
+		secs = int64(d.Round(time.Second) / time.Second)
+	} else {
+		return false
+	}
+	return secs > 0 || (secs == 0 && !positive)
+}
+
+// validateHookServiceAccount checks that the service account a Pod or Job
+// hook runs as exists when it runs: either the default service account, or
+// one the chart creates before the hook. Resources that are not hooks do not
+// exist yet on pre-install and migration, nor anymore on post-delete, and hook service
+// accounts must run on the same events with a lower or equal weight.
+//
+// Service accounts created outside of the chart cannot be told apart from
+// missing ones, so this is only a warning.
+func validateHookServiceAccount(o *hookObject, events []release.HookEvent, accounts map[string][]serviceAccount) error {
+	name := o.serviceAccountName()
+	if name == "" || name == "default" {
+		return nil
+	}
+	candidates, ok := accounts[name]
+	if !ok {
+		return errors.Errorf("%s %q runs as service account %q, which the chart does not create", o.Kind, o.Metadata.Name, name)
+	}
+
+	weight, _ := strconv.Atoi(strings.TrimSpace(o.Metadata.Annotations[release.HookWeightAnnotation]))
+	var missing []string
+	for _, e := range events {
+		if !serviceAccountExists(candidates, e, weight) {
+			missing = append(missing, e.String())
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("%s %q runs as service account %q, which does not exist yet or anymore on %s", o.Kind, o.Metadata.Name, name, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func serviceAccountExists(candidates []serviceAccount, event release.HookEvent, weight int) bool {
+	for _, sa := range candidates {
+		if sa.events == nil {
+			if event != release.HookPreInstall && event != release.HookMigration && event != release.HookPostDelete {
+				return true
+			}
+			continue
+		}
+		for _, e := range sa.events {
+			if e == event && sa.weight <= weight {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/lint/support"
+)
+
+const hooksTemplate = `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: migrator
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: hook-runner
+  annotations:
+    helm.sh/hook: pre-install,pre-upgrade
+    helm.sh/hook-weight: "-5"
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    helm.sh/hook: pre-install,post-upgrade
+spec:
+  template:
+    spec:
+      serviceAccountName: migrator
+      restartPolicy: Never
+      containers:
+      - name: migrate
+        image: migrate
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: setup
+  annotations:
+    helm.sh/hook: pre-install,pre-upgrade
+    helm.sh/hook-weight: "0"
+spec:
+  template:
+    spec:
+      serviceAccountName: hook-runner
+      restartPolicy: Never
+      containers:
+      - name: setup
+        image: setup
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: smoke
+  annotations:
+    helm.sh/hook: test,post-instal
+    helm.sh/hook-weight: first
+    helm.sh/hook-delete-policy: hook-succeeded,hook-suceeded
+    helm.sh/hook-timeout: soon
+spec:
+  serviceAccountName: tester
+  containers:
+  - name: smoke
+    image: smoke
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cleanup
+  annotations:
+    helm.sh/hook: post-delete
+    helm.sh/hook-delete-policy: hook-succeeded
+    helm.sh/hook-ttl: 1h
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: schema
+  annotations:
+    helm.sh/hook: migration
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: plain
+  annotations:
+    helm.sh/hook-weight: "1"
+`
+
+func TestValidateHooks(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "hooks",
+			Version:    "0.1.0",
+			Icon:       "satisfy-the-linting-gods.gif",
+		},
+		Templates: []*chart.File{
+			{Name: "templates/hooks.yaml", Data: []byte(hooksTemplate)},
+		},
+	}
+	tmpdir := ensure.TempDir(t)
+	defer os.RemoveAll(tmpdir)
+
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, values, namespace, strict)
+
+	expected := []string{
+		`"migrate" runs as service account "migrator", which does not exist yet or anymore on pre-install`,
+		`unknown hook events ["post-instal"]`,
+		`invalid helm.sh/hook-weight "first"`,
+		`unknown helm.sh/hook-delete-policy "hook-suceeded"`,
+		`invalid helm.sh/hook-timeout "soon"`,
+		`"smoke" runs as service account "tester", which the chart does not create`,
+		`helm.sh/hook-ttl "1h" conflicts with helm.sh/hook-delete-policy "hook-succeeded"`,
+		`ConfigMap "schema" is a migration hook: migrations must be Jobs`,
+		`ConfigMap "plain" has helm.sh/hook-weight but no helm.sh/hook annotation`,
+	}
+	if len(linter.Messages) != len(expected) {
+		for i, msg := range linter.Messages {
+			t.Logf("Message %d: %s", i, msg)
+		}
+		t.Fatalf("Expected %d lint messages, got %d", len(expected), len(linter.Messages))
+	}
+	for i, want := range expected {
+		if !strings.Contains(linter.Messages[i].Err.Error(), want) {
+			t.Errorf("Expected message %d to contain %q, got %q", i, want, linter.Messages[i].Err)
+		}
+	}
+}
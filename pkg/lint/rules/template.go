@@ -34,6 +34,7 @@ import (
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/engine"
 	"helm.sh/helm/v3/pkg/lint/support"
+	"helm.sh/helm/v3/pkg/release"
 )
 
 var (
@@ -41,8 +42,18 @@ var (
 	releaseTimeSearch = regexp.MustCompile(`\.Release\.Time`)
 )
 
+// structuredNotesFileName is the name of the template holding the structured
+// notes of a chart.
+const structuredNotesFileName = "NOTES.yaml"
+
 // Templates lints the templates in the Linter.
 func Templates(linter *support.Linter, values map[string]interface{}, namespace string, strict bool) {
+	TemplatesWithCapabilities(linter, values, namespace, strict, nil)
+}
+
+// TemplatesWithCapabilities lints the templates in the Linter, rendering them
+// with the given capabilities. The default capabilities are used if nil.
+func TemplatesWithCapabilities(linter *support.Linter, values map[string]interface{}, namespace string, strict bool, caps *chartutil.Capabilities) {
 	fpath := "templates/"
 	templatesPath := filepath.Join(linter.ChartDir, fpath)
 
@@ -71,7 +82,7 @@ func Templates(linter *support.Linter, values map[string]interface{}, namespace
 	if err != nil {
 		return
 	}
-	valuesToRender, err := chartutil.ToRenderValues(chart, cvals, options, nil)
+	valuesToRender, err := chartutil.ToRenderValues(chart, cvals, options, caps)
 	if err != nil {
 		linter.RunLinterRule(support.ErrorSev, fpath, err)
 		return
@@ -103,6 +114,12 @@ func Templates(linter *support.Linter, values map[string]interface{}, namespace
 		linter.RunLinterRule(support.WarningSev, fpath, validateNoCRDHooks(data))
 		linter.RunLinterRule(support.ErrorSev, fpath, validateNoReleaseTime(data))
 
+		// NOTES.yaml holds the structured notes of the chart rather than a resource
+		if fileName == path.Join("templates", structuredNotesFileName) {
+			linter.RunLinterRule(support.ErrorSev, fpath, validateStructuredNotes(renderedContentMap[path.Join(chart.Name(), fileName)]))
+			continue
+		}
+
 		// We only apply the following lint rules to yaml files
 		if filepath.Ext(fileName) != ".yaml" || filepath.Ext(fileName) == ".yml" {
 			continue
@@ -144,6 +161,8 @@ func Templates(linter *support.Linter, values map[string]interface{}, namespace
 			}
 		}
 	}
+
+	validateHooks(linter, chart, renderedContentMap)
 }
 
 // validateTopIndentLevel checks that the content does not start with an indent level > 0.
@@ -195,6 +214,14 @@ func validateAllowedExtension(fileName string) error {
 	return errors.Errorf("file extension '%s' not valid. Valid extensions are .yaml, .yml, .tpl, or .txt", ext)
 }
 
+func validateStructuredNotes(content string) error {
+	if strings.TrimSpace(content) == "" {
+		return nil
+	}
+	_, err := release.ParseStructuredNotes([]byte(content))
+	return err
+}
+
 func validateYamlContent(err error) error {
 	return errors.Wrap(err, "unable to parse YAML")
 }
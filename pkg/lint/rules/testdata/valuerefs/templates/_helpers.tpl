@@ -0,0 +1,3 @@
+{{- define "valuerefs.image" -}}
+{{ .Values.image.repository }}:{{ .Values.image.tag }}
+{{- end -}}
@@ -0,0 +1,367 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/lint/support"
+)
+
+// ValueReferences cross-references the values of a chart with the templates
+// using them.
+//
+// The values defined in values.yaml that no template references are reported
+// as dead configuration, and the '.Values' paths the templates reference that
+// neither values.yaml nor values.schema.json define are reported as likely
+// typos. The global values and those of the chart's dependencies are left
+// alone, as other charts use them.
+func ValueReferences(linter *support.Linter) {
+	chrt, err := loader.Load(linter.ChartDir)
+	if err != nil {
+		// The other rules report the charts that do not load
+		return
+	}
+	if chrt.Metadata.Type == "library" {
+		// The values of library charts are used by the templates of others
+		return
+	}
+
+	refs, err := templateValueReferences(chrt.Templates)
+	if err != nil {
+		// The templates rule reports the templates that do not parse
+		return
+	}
+
+	var schema map[string]interface{}
+	if len(chrt.Schema) > 0 {
+		if err := json.Unmarshal(chrt.Schema, &schema); err != nil {
+			linter.RunLinterRule(support.ErrorSev, "values.schema.json", errors.Wrap(err, "unable to parse JSON schema"))
+			return
+		}
+	}
+
+	skipped := map[string]bool{"global": true}
+	for _, dep := range chrt.Metadata.Dependencies {
+		skipped[dep.Name] = true
+		if dep.Alias != "" {
+			skipped[dep.Alias] = true
+		}
+	}
+	for _, dep := range chrt.Dependencies() {
+		skipped[dep.Name()] = true
+	}
+
+	for _, ref := range refs {
+		if len(ref.path) == 0 || skipped[ref.path[0]] {
+			continue
+		}
+		if !valuesDefine(chrt.Values, ref.path) && !schemaDefines(schema, ref.path) {
+			linter.RunLinterRule(support.WarningSev, ref.file, validateValueDefined(ref.path))
+		}
+	}
+
+	for _, leaf := range valueLeaves(chrt.Values, nil) {
+		if skipped[leaf[0]] {
+			continue
+		}
+		linter.RunLinterRule(support.InfoSev, "values.yaml", validateValueReferenced(leaf, refs))
+	}
+}
+
+func validateValueDefined(path []string) error {
+	return errors.Errorf("value .Values.%s is referenced but defined neither in values.yaml nor in values.schema.json", strings.Join(path, "."))
+}
+
+func validateValueReferenced(leaf []string, refs []valueReference) error {
+	for _, ref := range refs {
+		if hasPathPrefix(leaf, ref.path) || hasPathPrefix(ref.path, leaf) {
+			return nil
+		}
+	}
+	return errors.Errorf("value %s is defined but never referenced by the templates", strings.Join(leaf, "."))
+}
+
+// valueReference is a '.Values' path referenced by a template file.
+type valueReference struct {
+	file string
+	path []string
+}
+
+// templateValueReferences lists the '.Values' paths the templates reference,
+// once per file, in the order of the templates.
+func templateValueReferences(templates []*chart.File) ([]valueReference, error) {
+	t := template.New("").Funcs(engine.FuncMap())
+	for _, f := range templates {
+		if _, err := t.New(f.Name).Parse(string(f.Data)); err != nil {
+			return nil, err
+		}
+	}
+
+	// The templates defined in a file are walked along with it
+	byFile := map[string][]*template.Template{}
+	for _, tt := range t.Templates() {
+		if tt.Tree != nil {
+			byFile[tt.Tree.ParseName] = append(byFile[tt.Tree.ParseName], tt)
+		}
+	}
+
+	var refs []valueReference
+	for _, f := range templates {
+		trees := byFile[f.Name]
+		sort.Slice(trees, func(i, j int) bool { return trees[i].Name() < trees[j].Name() })
+
+		w := &valueWalker{seen: map[string]bool{}}
+		for _, tt := range trees {
+			// Named templates are assumed to be given the root context, as
+			// they mostly are through 'include "name" .'
+			w.walk(tt.Tree.Root, dotScope{root: true})
+		}
+		for _, path := range w.paths {
+			refs = append(refs, valueReference{file: f.Name, path: path})
+		}
+	}
+	return refs, nil
+}
+
+// dotScope is what the dot of a template refers to.
+type dotScope struct {
+	// root is true when the dot is the root context, holding the values
+	root bool
+	// values is the path of the values the dot is set to, if known
+	values []string
+}
+
+func (d dotScope) field(ident []string) []string {
+	switch {
+	case d.root && ident[0] == "Values":
+		return append([]string{}, ident[1:]...)
+	case d.values != nil:
+		return append(append([]string{}, d.values...), ident...)
+	}
+	return nil
+}
+
+// valueWalker collects the '.Values' paths referenced by template trees.
+type valueWalker struct {
+	paths [][]string
+	seen  map[string]bool
+}
+
+func (w *valueWalker) record(path []string) {
+	key := strings.Join(path, ".")
+	if !w.seen[key] {
+		w.seen[key] = true
+		w.paths = append(w.paths, path)
+	}
+}
+
+func (w *valueWalker) walk(node parse.Node, dot dotScope) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			w.walk(child, dot)
+		}
+	case *parse.ActionNode:
+		w.pipe(n.Pipe, dot)
+	case *parse.IfNode:
+		w.pipe(n.Pipe, dot)
+		w.walk(n.List, dot)
+		w.walk(n.ElseList, dot)
+	case *parse.WithNode:
+		// Within 'with .Values.foo', the dot is the value it tested
+		w.walk(n.List, dotScope{values: w.pipe(n.Pipe, dot)})
+		w.walk(n.ElseList, dot)
+	case *parse.RangeNode:
+		// The elements ranged over are not followed
+		w.pipe(n.Pipe, dot)
+		w.walk(n.List, dotScope{})
+		w.walk(n.ElseList, dot)
+	case *parse.TemplateNode:
+		w.pipe(n.Pipe, dot)
+	}
+}
+
+// pipe records the values referenced by a pipeline, and returns the path of
+// the values it evaluates to, if it is a single reference to them.
+func (w *valueWalker) pipe(pipe *parse.PipeNode, dot dotScope) []string {
+	if pipe == nil {
+		return nil
+	}
+	var path []string
+	for _, cmd := range pipe.Cmds {
+		path = w.command(cmd, dot)
+	}
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return nil
+	}
+	return path
+}
+
+func (w *valueWalker) command(cmd *parse.CommandNode, dot dotScope) []string {
+	args := cmd.Args
+	// 'index .Values "foo" "bar"' references .Values.foo.bar
+	if id, ok := args[0].(*parse.IdentifierNode); ok && id.Ident == "index" && len(args) > 1 {
+		if path := w.value(args[1], dot); path != nil {
+			i := 2
+			for ; i < len(args); i++ {
+				s, ok := args[i].(*parse.StringNode)
+				if !ok {
+					break
+				}
+				path = append(path, s.Text)
+			}
+			w.record(path)
+			args = args[i:]
+		}
+	}
+
+	var path []string
+	for _, arg := range args {
+		path = w.arg(arg, dot)
+	}
+	return path
+}
+
+// arg records the values referenced by an argument, and returns their path.
+func (w *valueWalker) arg(node parse.Node, dot dotScope) []string {
+	if n, ok := node.(*parse.PipeNode); ok {
+		return w.pipe(n, dot)
+	}
+	if path := w.value(node, dot); path != nil {
+		w.record(path)
+		return path
+	}
+	// The values used to compute '(...).field' are referenced on their own
+	if n, ok := node.(*parse.ChainNode); ok {
+		w.arg(n.Node, dot)
+	}
+	return nil
+}
+
+// value returns the path of the values an argument refers to, if any.
+func (w *valueWalker) value(node parse.Node, dot dotScope) []string {
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		return dot.field(n.Ident)
+	case *parse.VariableNode:
+		// $.Values.foo, or any variable set to the root context
+		if len(n.Ident) > 1 && n.Ident[1] == "Values" {
+			return append([]string{}, n.Ident[2:]...)
+		}
+	case *parse.DotNode:
+		if dot.values != nil {
+			return append([]string{}, dot.values...)
+		}
+	case *parse.ChainNode:
+		if path := w.value(n.Node, dot); path != nil {
+			return append(path, n.Field...)
+		}
+	case *parse.PipeNode:
+		if len(n.Decl) == 0 && len(n.Cmds) == 1 && len(n.Cmds[0].Args) == 1 {
+			return w.value(n.Cmds[0].Args[0], dot)
+		}
+	}
+	return nil
+}
+
+// valuesDefine returns true if the values define the given path. Below empty
+// maps and values that are not maps, any path is taken as defined, as they
+// are set by users.
+func valuesDefine(values map[string]interface{}, path []string) bool {
+	var cur interface{} = values
+	for i, key := range path {
+		m, ok := cur.(map[string]interface{})
+		if !ok || (len(m) == 0 && i > 0) {
+			return true
+		}
+		if cur, ok = m[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// schemaDefines returns true if the JSON schema has properties for the given
+// path, or allows additional properties where it has none.
+func schemaDefines(schema map[string]interface{}, path []string) bool {
+	if schema == nil {
+		return false
+	}
+	cur := schema
+	for _, key := range path {
+		props, ok := cur["properties"].(map[string]interface{})
+		if !ok {
+			return true
+		}
+		if prop, ok := props[key].(map[string]interface{}); ok {
+			cur = prop
+			continue
+		}
+		if _, ok := cur["patternProperties"]; ok {
+			return true
+		}
+		additional, ok := cur["additionalProperties"]
+		return ok && additional != false
+	}
+	return true
+}
+
+// valueLeaves lists the paths of the values that are not non-empty maps, in
+// order.
+func valueLeaves(values map[string]interface{}, prefix []string) [][]string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var leaves [][]string
+	for _, k := range keys {
+		path := append(append([]string{}, prefix...), k)
+		if m, ok := values[k].(map[string]interface{}); ok && len(m) > 0 {
+			leaves = append(leaves, valueLeaves(m, path)...)
+			continue
+		}
+		leaves = append(leaves, path)
+	}
+	return leaves
+}
+
+func hasPathPrefix(path, prefix []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
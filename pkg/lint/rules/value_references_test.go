@@ -0,0 +1,81 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/lint/support"
+)
+
+func TestValueReferences(t *testing.T) {
+	linter := support.Linter{ChartDir: "./testdata/valuerefs"}
+	ValueReferences(&linter)
+
+	expected := []string{
+		"[WARNING] templates/deployment.yaml: value .Values.resources.limits.memory is referenced but defined neither in values.yaml nor in values.schema.json",
+		"[WARNING] templates/deployment.yaml: value .Values.debug.enabled is referenced but defined neither in values.yaml nor in values.schema.json",
+		"[WARNING] templates/deployment.yaml: value .Values.replicaCount is referenced but defined neither in values.yaml nor in values.schema.json",
+		"[INFO] values.yaml: value legacyPort is defined but never referenced by the templates",
+	}
+	if len(linter.Messages) != len(expected) {
+		t.Fatalf("Expected %d messages, got %d: %v", len(expected), len(linter.Messages), linter.Messages)
+	}
+	for i, msg := range linter.Messages {
+		if msg.Error() != expected[i] {
+			t.Errorf("Expected message %q, got %q", expected[i], msg.Error())
+		}
+	}
+	if linter.HighestSeverity != support.WarningSev {
+		t.Errorf("Expected the highest severity to be a warning, got %d", linter.HighestSeverity)
+	}
+}
+
+func TestTemplateValueReferences(t *testing.T) {
+	templates := []*chart.File{
+		{Name: "templates/a.yaml", Data: []byte(`{{ toYaml .Values.a }}{{ with .Values.b }}{{ .c }}{{ end }}{{ $root := . }}{{ $root.Values.d }}`)},
+		{Name: "templates/b.yaml", Data: []byte(`{{ range .Values.e }}{{ .f }}{{ end }}{{ (.Values.g).h }}{{ index .Values "i" "j" }}`)},
+	}
+	refs, err := templateValueReferences(templates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, ref := range refs {
+		got = append(got, ref.file+":"+strings.Join(ref.path, "."))
+	}
+	expected := []string{
+		"templates/a.yaml:a",
+		"templates/a.yaml:b",
+		"templates/a.yaml:b.c",
+		"templates/a.yaml:d",
+		"templates/b.yaml:e",
+		"templates/b.yaml:g.h",
+		"templates/b.yaml:i.j",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected references %v, got %v", expected, got)
+	}
+
+	// A reference to the whole values uses all of them
+	if err := validateValueReferenced([]string{"anything"}, []valueReference{{path: []string{}}}); err != nil {
+		t.Errorf("Expected a reference to .Values to use all the values, got %s", err)
+	}
+}
@@ -0,0 +1,290 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package notify posts the events of releases to webhooks.
+
+The webhooks are read from a YAML file, such as:
+
+	webhooks:
+	- name: audit
+	  url: https://audit.example.com/helm
+	  secretEnv: AUDIT_WEBHOOK_SECRET
+	- name: payments-team
+	  url: https://hooks.slack.com/services/T000/B000/XXXX
+	  format: slack
+	  releases: ["payments/*"]
+	  events: ["install.failed", "upgrade.failed", "drift.detected"]
+
+Each event is posted as JSON, signed with HMAC-SHA256 when the webhook has a
+secret. The signature is sent in the X-Helm-Signature-256 header, as
+"sha256=" followed by the hexadecimal digest of the body.
+*/
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// Formats of the bodies posted to webhooks
+const (
+	// FormatJSON posts the event as is.
+	FormatJSON = "json"
+	// FormatSlack posts a message for Slack incoming webhooks and the
+	// services compatible with them.
+	FormatSlack = "slack"
+)
+
+// Headers of the requests posted to webhooks
+const (
+	// SignatureHeader holds the signature of the body.
+	SignatureHeader = "X-Helm-Signature-256"
+	// EventHeader holds the type of the event.
+	EventHeader = "X-Helm-Event"
+)
+
+// DefaultTimeout is the time a webhook is given to answer.
+const DefaultTimeout = 10 * time.Second
+
+// SlackTemplate is the template of the bodies in the slack format.
+const SlackTemplate = `{"text": {{ printf "*%s* %s/%s revision %d (%s %s): %s" .Type .Namespace .Release .Revision .Chart .ChartVersion .Message | json }}}`
+
+// Config lists the webhooks to post the events of releases to.
+type Config struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// Webhook is an endpoint the events of releases are posted to.
+type Webhook struct {
+	// Name identifies the webhook in errors. It defaults to the URL.
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url"`
+	// Secret is the key the bodies are signed with. They are not signed if
+	// it is empty.
+	Secret string `json:"secret,omitempty"`
+	// SecretEnv is the environment variable holding the secret, so that it
+	// is not written in the configuration.
+	SecretEnv string `json:"secretEnv,omitempty"`
+	// Format is the format of the bodies, FormatJSON if it is empty.
+	Format string `json:"format,omitempty"`
+	// Template is the template of the bodies, which takes precedence over
+	// the format. It is executed with the action.ReleaseEvent, and has a
+	// 'json' function quoting its argument as JSON.
+	Template string `json:"template,omitempty"`
+	// Events are the types of the events to post, such as
+	// 'install.failed'. Every event is posted if there are none.
+	Events []string `json:"events,omitempty"`
+	// Releases are the releases whose events are posted, as patterns
+	// matching NAMESPACE/NAME, or NAME in any namespace. Every release is
+	// matched if there are none.
+	Releases []string `json:"releases,omitempty"`
+	// Timeout is the time the webhook is given to answer, DefaultTimeout if
+	// it is zero.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// LoadConfig reads the webhooks from the file at path. A missing file
+// configures no webhook.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't load the notifiers configuration")
+	}
+	c := &Config{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, errors.Wrapf(err, "invalid notifiers configuration %s", path)
+	}
+	return c, nil
+}
+
+// Notifier posts the events of releases to the webhooks of a configuration.
+// It implements action.Notifier.
+type Notifier struct {
+	webhooks []webhook
+	// Client sends the requests. It defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+type webhook struct {
+	Webhook
+	secret string
+	tpl    *template.Template
+}
+
+// New creates a notifier for the webhooks of c.
+func New(c *Config) (*Notifier, error) {
+	n := &Notifier{}
+	for _, w := range c.Webhooks {
+		if w.URL == "" {
+			return nil, errors.Errorf("webhook %q has no URL", w.Name)
+		}
+		if w.Name == "" {
+			w.Name = w.URL
+		}
+		text := w.Template
+		if text == "" {
+			switch w.Format {
+			case "", FormatJSON:
+			case FormatSlack:
+				text = SlackTemplate
+			default:
+				return nil, errors.Errorf("webhook %q has an unknown format %q", w.Name, w.Format)
+			}
+		}
+		for _, p := range w.Releases {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, errors.Wrapf(err, "webhook %q has an invalid release pattern %q", w.Name, p)
+			}
+		}
+		hook := webhook{Webhook: w, secret: w.Secret}
+		if w.SecretEnv != "" {
+			hook.secret = os.Getenv(w.SecretEnv)
+		}
+		if text != "" {
+			tpl, err := template.New(w.Name).Funcs(template.FuncMap{"json": toJSON}).Parse(text)
+			if err != nil {
+				return nil, errors.Wrapf(err, "webhook %q has an invalid template", w.Name)
+			}
+			hook.tpl = tpl
+		}
+		n.webhooks = append(n.webhooks, hook)
+	}
+	return n, nil
+}
+
+// Notify posts e to the webhooks matching it. All of them are tried, and
+// their errors are returned together.
+func (n *Notifier) Notify(e action.ReleaseEvent) error {
+	var errs []string
+	for _, w := range n.webhooks {
+		if !w.matches(e) {
+			continue
+		}
+		if err := n.post(w, e); err != nil {
+			errs = append(errs, fmt.Sprintf("webhook %s: %s", w.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (n *Notifier) post(w webhook, e action.ReleaseEvent) error {
+	body, err := w.body(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, e.Type)
+	if w.secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.secret, body))
+	}
+
+	client := http.Client{Timeout: DefaultTimeout}
+	if n.Client != nil {
+		client = *n.Client
+	}
+	if w.Timeout.Duration > 0 {
+		client.Timeout = w.Timeout.Duration
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature of a body with the secret, as sent in the
+// SignatureHeader header.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// matches tells whether the event is one the webhook is set to receive.
+func (w webhook) matches(e action.ReleaseEvent) bool {
+	if len(w.Events) > 0 && !contains(w.Events, e.Type) {
+		return false
+	}
+	if len(w.Releases) == 0 {
+		return true
+	}
+	for _, p := range w.Releases {
+		if ok, _ := path.Match(p, e.Namespace+"/"+e.Release); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, e.Release); ok && !strings.Contains(p, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (w webhook) body(e action.ReleaseEvent) ([]byte, error) {
+	if w.tpl == nil {
+		return json.Marshal(e)
+	}
+	var b bytes.Buffer
+	if err := w.tpl.Execute(&b, e); err != nil {
+		return nil, errors.Wrap(err, "unable to render the body")
+	}
+	return b.Bytes(), nil
+}
+
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
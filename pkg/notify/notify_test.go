@@ -0,0 +1,157 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+type request struct {
+	path      string
+	event     string
+	signature string
+	body      []byte
+}
+
+func newServer(t *testing.T, requests *[]request) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		*requests = append(*requests, request{
+			path:      r.URL.Path,
+			event:     r.Header.Get(EventHeader),
+			signature: r.Header.Get(SignatureHeader),
+			body:      body,
+		})
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+}
+
+func TestNotify(t *testing.T) {
+	var requests []request
+	srv := newServer(t, &requests)
+	defer srv.Close()
+
+	n, err := New(&Config{Webhooks: []Webhook{
+		{Name: "audit", URL: srv.URL + "/audit", Secret: "s3cr3t"},
+		{Name: "slack", URL: srv.URL + "/slack", Format: FormatSlack, Releases: []string{"payments/*"}, Events: []string{action.EventInstallFailed}},
+		{Name: "other", URL: srv.URL + "/other", Releases: []string{"web"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := action.ReleaseEvent{
+		Type:         action.EventInstallFailed,
+		Release:      "billing",
+		Namespace:    "payments",
+		Revision:     1,
+		Chart:        "billing",
+		ChartVersion: "0.1.0",
+		Message:      "timed out",
+	}
+	if err := n.Notify(e); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+
+	audit := requests[0]
+	if audit.path != "/audit" || audit.event != action.EventInstallFailed {
+		t.Errorf("unexpected request %s for event %s", audit.path, audit.event)
+	}
+	if audit.signature != Sign("s3cr3t", audit.body) {
+		t.Errorf("unexpected signature %q", audit.signature)
+	}
+	var got action.ReleaseEvent
+	if err := json.Unmarshal(audit.body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Release != "billing" || got.Message != "timed out" {
+		t.Errorf("unexpected event %+v", got)
+	}
+
+	slack := requests[1]
+	if slack.signature != "" {
+		t.Errorf("expected no signature without a secret, got %q", slack.signature)
+	}
+	expect := `{"text": "*install.failed* payments/billing revision 1 (billing 0.1.0): timed out"}`
+	if string(slack.body) != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, slack.body)
+	}
+}
+
+func TestNotifyErrors(t *testing.T) {
+	var requests []request
+	srv := newServer(t, &requests)
+	defer srv.Close()
+
+	n, err := New(&Config{Webhooks: []Webhook{
+		{Name: "broken", URL: srv.URL + "/broken"},
+		{Name: "audit", URL: srv.URL + "/audit"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = n.Notify(action.ReleaseEvent{Type: action.EventUninstallSucceeded, Release: "web"})
+	if err == nil || err.Error() != "webhook broken: unexpected status 500 Internal Server Error" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if len(requests) != 2 {
+		t.Errorf("expected every webhook to be tried, got %d requests", len(requests))
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, w := range []Webhook{
+		{Name: "no-url"},
+		{URL: "https://example.com", Format: "xml"},
+		{URL: "https://example.com", Template: "{{ .Type"},
+		{URL: "https://example.com", Releases: []string{"["}},
+	} {
+		if _, err := New(&Config{Webhooks: []Webhook{w}}); err == nil {
+			t.Errorf("expected an error for %+v", w)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	c, err := LoadConfig(filepath.Join("testdata", "notifiers.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Webhooks) != 2 || c.Webhooks[1].Format != FormatSlack || c.Webhooks[1].Timeout.Duration.String() != "5s" {
+		t.Errorf("unexpected configuration %+v", c)
+	}
+
+	c, err = LoadConfig(filepath.Join("testdata", "missing.yaml"))
+	if err != nil || len(c.Webhooks) != 0 {
+		t.Errorf("expected a missing file to configure no webhook, got %+v, %v", c, err)
+	}
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/plugin"
 )
 
@@ -34,6 +35,29 @@ var ErrMissingMetadata = errors.New("plugin metadata (plugin.yaml) missing")
 // Debug enables verbose output.
 var Debug bool
 
+// Option sets how plugins are installed and updated.
+type Option func(*options)
+
+type options struct {
+	offline bool
+}
+
+// OptOffline returns an option that refuses to install or update plugins
+// from remote sources when offline is true.
+func OptOffline(offline bool) Option {
+	return func(o *options) {
+		o.offline = offline
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
 // Installer provides an interface for installing helm client plugins.
 type Installer interface {
 	// Install adds a plugin.
@@ -56,18 +80,23 @@ func Install(i Installer) error {
 }
 
 // Update updates a plugin.
-func Update(i Installer) error {
+func Update(i Installer, opts ...Option) error {
 	if _, pathErr := os.Stat(i.Path()); os.IsNotExist(pathErr) {
 		return errors.New("plugin does not exist")
 	}
+	if _, ok := i.(*LocalInstaller); newOptions(opts).offline && !ok {
+		return errors.Wrapf(getter.ErrOffline, "cannot update plugin %s from its remote source", filepath.Base(i.Path()))
+	}
 	return i.Update()
 }
 
 // NewForSource determines the correct Installer for the given source.
-func NewForSource(source, version string) (Installer, error) {
+func NewForSource(source, version string, opts ...Option) (Installer, error) {
 	// Check if source is a local directory
 	if isLocalReference(source) {
 		return NewLocalInstaller(source)
+	} else if newOptions(opts).offline {
+		return nil, errors.Wrapf(getter.ErrOffline, "cannot install plugin from %s", source)
 	} else if isRemoteHTTPArchive(source) {
 		return NewHTTPInstaller(source)
 	}
@@ -15,7 +15,13 @@ limitations under the License.
 
 package installer
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/getter"
+)
 
 func TestIsRemoteHTTPArchive(t *testing.T) {
 	srv := mockArchiveServer()
@@ -38,3 +44,12 @@ func TestIsRemoteHTTPArchive(t *testing.T) {
 		t.Error("Expected media type match to fail")
 	}
 }
+
+func TestNewForSourceOffline(t *testing.T) {
+	if _, err := NewForSource("https://github.com/adamreese/helm-env", "", OptOffline(true)); errors.Cause(err) != getter.ErrOffline {
+		t.Errorf("expected a remote source to fail with ErrOffline, got %v", err)
+	}
+	if _, err := NewForSource("../testdata/plugdir/good/echo", "", OptOffline(true)); err != nil {
+		t.Errorf("expected a local source to be installable offline, got %v", err)
+	}
+}
@@ -42,6 +42,28 @@ type Downloaders struct {
 	Command string `json:"command"`
 }
 
+// AuthProviders represents the plugins capability to provide credentials
+// for chart repository and registry hosts
+type AuthProviders struct {
+	// Hosts are the hosts the plugin provides credentials for. A leading
+	// "*." matches any subdomain.
+	Hosts []string `json:"hosts"`
+	// Command is the executable path with which the plugin retrieves the
+	// credentials of one of the Hosts
+	Command string `json:"command"`
+}
+
+// SecretBackends represents the plugins capability to resolve the secret
+// references of values
+type SecretBackends struct {
+	// Schemes are the backends of the references the plugin resolves, such
+	// as 'vault' for 'ref+vault://' references.
+	Schemes []string `json:"schemes"`
+	// Command is the executable path with which the plugin resolves a
+	// reference to its secret
+	Command string `json:"command"`
+}
+
 // PlatformCommand represents a command for a particular operating system and architecture
 type PlatformCommand struct {
 	OperatingSystem string `json:"os"`
@@ -97,6 +119,14 @@ type Metadata struct {
 	// for special protocols.
 	Downloaders []Downloaders `json:"downloaders"`
 
+	// AuthProviders field is used if the plugin supplies credentials for
+	// chart repositories and registries.
+	AuthProviders []AuthProviders `json:"authProviders"`
+
+	// SecretBackends field is used if the plugin resolves secret references
+	// of values.
+	SecretBackends []SecretBackends `json:"secretBackends"`
+
 	// UseTunnelDeprecated indicates that this command needs a tunnel.
 	// Setting this will cause a number of side effects, such as the
 	// automatic setting of HELM_HOST.
@@ -277,6 +277,70 @@ func (s *Signatory) Verify(chartpath, sigpath string) (*Verification, error) {
 	return ver, nil
 }
 
+// ClearSignData signs arbitrary data, such as a repository index, with the
+// given key. The clear signature records the SHA-256 sum of the data under the
+// given name, as that of a chart archive is recorded in its provenance file.
+func (s *Signatory) ClearSignData(name string, data []byte) (string, error) {
+	if s.Entity == nil {
+		return "", errors.New("private key not found")
+	} else if s.Entity.PrivateKey == nil {
+		return "", errors.New("provided key is not a private key. Try providing a keyring with secret keys")
+	}
+
+	sum, err := Digest(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	msg, err := yaml.Marshal(&SumCollection{Files: map[string]string{name: "sha256:" + sum}})
+	if err != nil {
+		return "", err
+	}
+
+	out := bytes.NewBuffer(nil)
+	w, err := clearsign.Encode(out, s.Entity.PrivateKey, &defaultPGPConfig)
+	if err != nil {
+		return "", err
+	}
+	_, err = w.Write(msg)
+	w.Close()
+	return out.String(), err
+}
+
+// VerifyData checks a clear signature, as created by ClearSignData, and
+// verifies that it is legit for the data with the given name.
+func (s *Signatory) VerifyData(name string, data, sig []byte) (*Verification, error) {
+	ver := &Verification{}
+	block, _ := clearsign.Decode(sig)
+	if block == nil {
+		return ver, errors.New("failed to decode signature: signature block not found")
+	}
+
+	by, err := s.verifySignature(block)
+	if err != nil {
+		return ver, err
+	}
+	ver.SignedBy = by
+
+	sum, err := Digest(bytes.NewReader(data))
+	if err != nil {
+		return ver, err
+	}
+	sums := &SumCollection{}
+	if err := yaml.Unmarshal(block.Plaintext, sums); err != nil {
+		return ver, err
+	}
+
+	sum = "sha256:" + sum
+	if sha, ok := sums.Files[name]; !ok {
+		return ver, errors.Errorf("signature does not contain a SHA for a file named %q", name)
+	} else if sha != sum {
+		return ver, errors.Errorf("sha256 sum does not match for %s: %q != %q", name, sha, sum)
+	}
+	ver.FileHash = sum
+	ver.FileName = name
+	return ver, nil
+}
+
 func (s *Signatory) decodeSignature(filename string) (*clearsign.Block, error) {
 	data, err := ioutil.ReadFile(filename)
 	if err != nil {
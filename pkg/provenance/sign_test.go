@@ -299,6 +299,34 @@ func TestVerify(t *testing.T) {
 	}
 }
 
+func TestClearSignData(t *testing.T) {
+	signer, err := NewFromFiles(testKeyfile, testPubfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("apiVersion: v1\nentries: {}\n")
+	sig, err := signer.ClearSignData("index.yaml", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ver, err := signer.VerifyData("index.yaml", data, []byte(sig)); err != nil {
+		t.Errorf("Failed to pass verify. Err: %s", err)
+	} else if ver.SignedBy == nil {
+		t.Error("No SignedBy field")
+	} else if ver.FileName != "index.yaml" {
+		t.Errorf("FileName is unexpectedly %q", ver.FileName)
+	}
+
+	if _, err := signer.VerifyData("index.yaml", []byte("apiVersion: v1\nentries: {tampered: []}\n"), []byte(sig)); err == nil {
+		t.Error("Expected tampered data to fail")
+	}
+	if _, err := signer.VerifyData("other.yaml", data, []byte(sig)); err == nil {
+		t.Error("Expected data with another name to fail")
+	}
+}
+
 // readSumFile reads a file containing a sum generated by the UNIX shasum tool.
 func readSumFile(sumfile string) (string, error) {
 	data, err := ioutil.ReadFile(sumfile)
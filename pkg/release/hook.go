@@ -34,6 +34,9 @@ const (
 	HookPreRollback  HookEvent = "pre-rollback"
 	HookPostRollback HookEvent = "post-rollback"
 	HookTest         HookEvent = "test"
+	// HookMigration hooks are Jobs run on install and upgrade after the
+	// pre-install and pre-upgrade hooks, once per version of the chart.
+	HookMigration HookEvent = "migration"
 )
 
 func (x HookEvent) String() string { return string(x) }
@@ -59,6 +62,23 @@ const HookWeightAnnotation = "helm.sh/hook-weight"
 // HookDeleteAnnotation is the label name for the delete policy for a hook
 const HookDeleteAnnotation = "helm.sh/hook-delete-policy"
 
+// HookTimeoutAnnotation is the label name for the timeout of a hook, either
+// as a duration ("5m") or in seconds ("300")
+const HookTimeoutAnnotation = "helm.sh/hook-timeout"
+
+// HookTTLAnnotation is the label name for the time a successful hook resource
+// is kept before it is garbage collected, either as a duration or in seconds
+const HookTTLAnnotation = "helm.sh/hook-ttl"
+
+// HookSuccessConditionAnnotation is the label name for the condition the
+// resource of a hook must meet for the hook to succeed: the name of a status
+// condition, such as "Ready", or a condition in the form of --wait-for, such
+// as "jsonpath={.status.phase}=Completed". CEL expressions are not supported.
+const HookSuccessConditionAnnotation = "helm.sh/hook-success-condition"
+
+// MaxHookExecutions is the number of executions kept in the history of a hook.
+const MaxHookExecutions = 10
+
 // Hook defines a hook object.
 type Hook struct {
 	Name string `json:"name,omitempty"`
@@ -72,10 +92,24 @@ type Hook struct {
 	Events []HookEvent `json:"events,omitempty"`
 	// LastRun indicates the date/time this was last run.
 	LastRun HookExecution `json:"last_run,omitempty"`
+	// Executions are the attempts to run the hook, oldest first, carried
+	// over across the revisions of the release. The latest attempt of this
+	// revision is also recorded as LastRun.
+	Executions []HookExecution `json:"executions,omitempty"`
 	// Weight indicates the sort order for execution among similar Hook type
 	Weight int `json:"weight,omitempty"`
 	// DeletePolicies are the policies that indicate when to delete the hook
 	DeletePolicies []HookDeletePolicy `json:"delete_policies,omitempty"`
+	// TimeoutSeconds is the time allowed for the hook's weight group to
+	// complete. Zero means the timeout of the operation applies.
+	TimeoutSeconds int64 `json:"timeout_seconds,omitempty"`
+	// TTLSeconds is the time a successful hook resource is kept before it is
+	// garbage collected. Nil means it is kept until a delete policy applies.
+	TTLSeconds *int64 `json:"ttl_seconds,omitempty"`
+	// SuccessCondition is the condition the hook resource must meet for the
+	// hook to succeed. If empty, Jobs and Pods must complete, and other
+	// resources only need to be created.
+	SuccessCondition string `json:"success_condition,omitempty"`
 }
 
 // A HookExecution records the result for the last execution of a hook for a given release.
@@ -86,6 +120,71 @@ type HookExecution struct {
 	CompletedAt time.Time `json:"completed_at,omitempty"`
 	// Phase indicates whether the hook completed successfully
 	Phase HookPhase `json:"phase"`
+	// ResourceState indicates what became of the hook resource
+	ResourceState HookResourceState `json:"resource_state,omitempty"`
+	// DeletedBy is the delete policy that removed the hook resource, if any
+	DeletedBy HookDeletePolicy `json:"deleted_by,omitempty"`
+	// ExpiresAt indicates the date/time after which a kept hook resource is
+	// garbage collected
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	// Revision is the revision of the release the hook was run for
+	Revision int `json:"revision,omitempty"`
+	// Event is the event the hook was run on
+	Event HookEvent `json:"event,omitempty"`
+	// ChartVersion is the version of the chart the hook was run for
+	ChartVersion string `json:"chart_version,omitempty"`
+	// Message explains the outcome of the execution, such as why it failed
+	Message string `json:"message,omitempty"`
+	// Resource is the resource created by the hook
+	Resource *HookResource `json:"resource,omitempty"`
+}
+
+// HookResource references the resource created by a hook.
+type HookResource struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// HasEvent tells whether the hook runs on the event.
+func (h *Hook) HasEvent(event HookEvent) bool {
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordLastRun records LastRun in the executions of the hook, replacing the
+// latest one if it is the same attempt. Hooks that did not run are left
+// alone.
+func (h *Hook) RecordLastRun() {
+	if h.LastRun.StartedAt.IsZero() {
+		return
+	}
+	if n := len(h.Executions); n > 0 && h.Executions[n-1].StartedAt.Equal(h.LastRun.StartedAt) {
+		h.Executions[n-1] = h.LastRun
+		return
+	}
+	h.Executions = append(h.Executions, h.LastRun)
+	if n := len(h.Executions); n > MaxHookExecutions {
+		h.Executions = h.Executions[n-MaxHookExecutions:]
+	}
+}
+
+// CarryHookExecutions copies the executions of the hooks of a previous
+// revision to the hooks of a new one, matching them by kind, name and path.
+func CarryHookExecutions(previous, hooks []*Hook) {
+	byKey := map[string]*Hook{}
+	for _, h := range previous {
+		byKey[h.Kind+"/"+h.Name+"/"+h.Path] = h
+	}
+	for _, h := range hooks {
+		if p, ok := byKey[h.Kind+"/"+h.Name+"/"+h.Path]; ok && p != h {
+			h.Executions = append([]HookExecution(nil), p.Executions...)
+		}
+	}
 }
 
 // A HookPhase indicates the state of a hook execution
@@ -104,3 +203,19 @@ const (
 
 // Strng converts a hook phase to a printable string
 func (x HookPhase) String() string { return string(x) }
+
+// A HookResourceState indicates what became of the resource created by a hook
+type HookResourceState string
+
+const (
+	// HookResourceCreated indicates that the hook resource was created
+	HookResourceCreated HookResourceState = "Created"
+	// HookResourceDeleted indicates that the hook resource was deleted by a delete policy
+	HookResourceDeleted HookResourceState = "Deleted"
+	// HookResourceKept indicates that the hook resource was left in the cluster
+	HookResourceKept HookResourceState = "Kept"
+	// HookResourceExpired indicates that the hook resource was deleted after its TTL expired
+	HookResourceExpired HookResourceState = "Expired"
+)
+
+func (x HookResourceState) String() string { return string(x) }
@@ -33,4 +33,63 @@ type Info struct {
 	Status Status `json:"status,omitempty"`
 	// Contains the rendered templates/NOTES.txt if available
 	Notes string `json:"notes,omitempty"`
+	// NotesDiff is the unified diff of the notes from those of the revision
+	// that was upgraded, if they changed
+	NotesDiff string `json:"notes_diff,omitempty"`
+	// Contains the rendered templates/NOTES.yaml if available
+	StructuredNotes *StructuredNotes `json:"structured_notes,omitempty"`
+	// Pause is set while the release is paused
+	Pause *Pause `json:"pause,omitempty"`
+	// Warnings are the warnings the Kubernetes API server returned for the
+	// resources of the release when it was deployed
+	Warnings []ResourceWarning `json:"warnings,omitempty"`
+	// NotReady diagnoses the resources that were not ready when waiting for
+	// them timed out, if the release failed so
+	NotReady []NotReadyResource `json:"not_ready,omitempty"`
+	// Attempts are the failed attempts of the operation that were retried
+	// before this revision, if it was retried
+	Attempts []Attempt `json:"attempts,omitempty"`
+}
+
+// Attempt records a failed attempt of an operation on a release that was
+// retried.
+type Attempt struct {
+	// Revision is the revision the attempt created, if it got that far
+	Revision int `json:"revision,omitempty"`
+	// StartedAt is when the attempt started
+	StartedAt time.Time `json:"started_at"`
+	// Error is the error the attempt failed with
+	Error string `json:"error"`
+}
+
+// NotReadyResource describes a resource that was not ready when waiting for
+// the resources of a release timed out.
+type NotReadyResource struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Status summarizes the last observed status of the resource
+	Status string `json:"status"`
+	// Events are the most recent events recorded for the resource
+	Events []string `json:"events,omitempty"`
+	// Pods are the pods of a workload that were not ready
+	Pods []NotReadyResource `json:"pods,omitempty"`
+}
+
+// ResourceWarning is a warning the Kubernetes API server returned for a
+// resource, such as for the use of a deprecated API.
+type ResourceWarning struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Message   string `json:"message"`
+}
+
+// Pause records that a release is paused. Paused releases are not installed
+// over, upgraded or rolled back unless the pause is explicitly overridden.
+type Pause struct {
+	// Reason explains why the release was paused
+	Reason string `json:"reason,omitempty"`
+	// PausedAt is when the release was paused
+	PausedAt time.Time `json:"paused_at,omitempty"`
 }
@@ -0,0 +1,74 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// StructuredNotes holds the rendered templates/NOTES.yaml of a chart. It is
+// the machine-readable counterpart of NOTES.txt.
+type StructuredNotes struct {
+	// Endpoints are the addresses at which the release can be reached
+	Endpoints []NotesEndpoint `json:"endpoints,omitempty"`
+	// Credentials reference the secrets holding the credentials of the release
+	Credentials []NotesCredential `json:"credentials,omitempty"`
+	// NextSteps are the instructions to follow after the release is deployed
+	NextSteps []string `json:"next_steps,omitempty"`
+}
+
+// NotesEndpoint describes an address at which a release can be reached.
+type NotesEndpoint struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// NotesCredential references a key of a secret holding a credential. Notes
+// never hold the credential itself.
+type NotesCredential struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	SecretName  string `json:"secret_name"`
+	SecretKey   string `json:"secret_key,omitempty"`
+}
+
+// ParseStructuredNotes parses a rendered NOTES.yaml.
+func ParseStructuredNotes(data []byte) (*StructuredNotes, error) {
+	notes := &StructuredNotes{}
+	if err := yaml.UnmarshalStrict(data, notes); err != nil {
+		return nil, errors.Wrap(err, "unable to parse structured notes")
+	}
+	for _, e := range notes.Endpoints {
+		if e.Name == "" || e.URL == "" {
+			return nil, errors.New("structured notes: endpoints must have a name and a url")
+		}
+	}
+	for _, c := range notes.Credentials {
+		if c.Name == "" || c.SecretName == "" {
+			return nil, errors.New("structured notes: credentials must have a name and a secret_name")
+		}
+	}
+	return notes, nil
+}
+
+// Merge appends the entries of other to the notes.
+func (n *StructuredNotes) Merge(other *StructuredNotes) {
+	n.Endpoints = append(n.Endpoints, other.Endpoints...)
+	n.Credentials = append(n.Credentials, other.Credentials...)
+	n.NextSteps = append(n.NextSteps, other.NextSteps...)
+}
@@ -15,7 +15,11 @@ limitations under the License.
 
 package release
 
-import "helm.sh/helm/v3/pkg/chart"
+import (
+	"time"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
 
 // Release describes a deployment of a chart, together with the chart
 // and the variables used to deploy that chart.
@@ -29,10 +33,36 @@ type Release struct {
 	// Config is the set of extra Values added to the chart.
 	// These values override the default values inside of the chart.
 	Config map[string]interface{} `json:"config,omitempty"`
+	// ValuesDelta replaces Config in the stored records whose values are
+	// encoded as changes from the values of another revision. The storage
+	// restores Config from it when the release is read.
+	ValuesDelta *ValuesDelta `json:"values_delta,omitempty"`
+	// InstallConfig is the set of extra Values that were only applied when
+	// the release was installed. They are kept apart from Config so that
+	// upgrades reusing the release's values do not send them again.
+	InstallConfig map[string]interface{} `json:"install_config,omitempty"`
+	// ValuesRefs are the values overlays pulled from registries for this
+	// release, pinned to the digests that were pulled. They are merged
+	// beneath Config.
+	ValuesRefs []ValuesReference `json:"values_refs,omitempty"`
 	// Manifest is the string representation of the rendered template.
 	Manifest string `json:"manifest,omitempty"`
 	// Hooks are all of the hooks declared for this release.
 	Hooks []*Hook `json:"hooks,omitempty"`
+	// GeneratedNames are the names the API server assigned to the resources
+	// of the manifest that use metadata.generateName.
+	GeneratedNames []GeneratedName `json:"generated_names,omitempty"`
+	// Source records the chart reference and version constraint the chart
+	// was requested with, and what they resolved to. Charts installed from
+	// local paths have none.
+	Source *ChartSource `json:"source,omitempty"`
+	// Checksums are the digests of the content of this revision, used to
+	// verify its integrity. Revisions stored by older versions of Helm have
+	// none.
+	Checksums *Checksums `json:"checksums,omitempty"`
+	// Defaults are the operation flags recorded with the release, which the
+	// upgrades and rollbacks of the release inherit unless they set them.
+	Defaults *Defaults `json:"defaults,omitempty"`
 	// Version is an int which represents the revision of the release.
 	Version int `json:"version,omitempty"`
 	// Namespace is the kubernetes namespace of the release.
@@ -42,8 +72,98 @@ type Release struct {
 	Labels map[string]string `json:"-"`
 }
 
+// GeneratedName is the name assigned to a resource created with
+// metadata.generateName.
+type GeneratedName struct {
+	Kind         string `json:"kind"`
+	Namespace    string `json:"namespace,omitempty"`
+	GenerateName string `json:"generate_name"`
+	Name         string `json:"name"`
+}
+
+// ValuesDelta encodes the values of a stored revision as the changes from the
+// values of another revision of the release.
+type ValuesDelta struct {
+	// Base is the revision whose values the changes apply to
+	Base int `json:"base"`
+	// Depth is the number of deltas applied to restore the values, from the
+	// last revision storing them in full
+	Depth int `json:"depth"`
+	// Changes are applied in order to the values of the base revision
+	Changes []ValuesChange `json:"changes,omitempty"`
+}
+
+// ValuesChange sets or removes the value at a path of the values.
+type ValuesChange struct {
+	Path   []string    `json:"path"`
+	Value  interface{} `json:"value"`
+	Remove bool        `json:"remove,omitempty"`
+}
+
+// Checksums are the digests of the content of a release revision.
+type Checksums struct {
+	// Manifest is the digest of the rendered manifest and hooks
+	Manifest string `json:"manifest"`
+	// Values is the digest of the coalesced values
+	Values string `json:"values"`
+}
+
+// Defaults are the operation flags recorded with a release.
+type Defaults struct {
+	// Wait is whether to wait for the resources to be ready
+	Wait bool `json:"wait,omitempty"`
+	// WaitForJobs is whether to wait for the Jobs to complete when waiting
+	WaitForJobs bool `json:"wait_for_jobs,omitempty"`
+	// Timeout is the time to wait for any individual Kubernetes operation.
+	// It is left to the operation if zero.
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// Atomic is whether to roll back upgrades that fail
+	Atomic bool `json:"atomic,omitempty"`
+}
+
+// ChartSource records where the chart of a release revision was downloaded
+// from, so that the revision can be reproduced.
+type ChartSource struct {
+	// Ref is the chart reference the chart was requested with, such as
+	// 'example/mariadb' or a URL
+	Ref string `json:"ref"`
+	// Constraint is the version or version constraint requested with
+	// --version, such as '>=1.2.0 <2.0.0'. It is empty for the latest version.
+	Constraint string `json:"constraint,omitempty"`
+	// Version is the concrete version the reference resolved to
+	Version string `json:"version"`
+	// Digest is the SHA256 digest of the downloaded chart archive
+	Digest string `json:"digest"`
+	// ManifestDigest is the digest of the manifest of charts pulled from
+	// registries. Ref@ManifestDigest pulls the same chart, whatever the tag
+	// now refers to.
+	ManifestDigest string `json:"manifest_digest,omitempty"`
+}
+
+// ValuesReference records a values overlay pulled from a registry.
+type ValuesReference struct {
+	// Ref is the reference the overlay was requested with, such as
+	// oci://registry/overlays/prod:1.2.0
+	Ref string `json:"ref"`
+	// Digest is the digest of the overlay's manifest
+	Digest string `json:"digest"`
+}
+
+// Pinned returns the reference pinned to the recorded digest.
+func (r ValuesReference) Pinned() string {
+	if r.Digest == "" {
+		return r.Ref
+	}
+	return r.Ref + "@" + r.Digest
+}
+
 // SetStatus is a helper for setting the status on a release.
 func (r *Release) SetStatus(status Status, msg string) {
 	r.Info.Status = status
 	r.Info.Description = msg
 }
+
+// IsPaused reports whether the release is paused.
+func (r *Release) IsPaused() bool {
+	return r.Info != nil && r.Info.Pause != nil
+}
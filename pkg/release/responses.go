@@ -15,10 +15,67 @@ limitations under the License.
 
 package release
 
+import (
+	"helm.sh/helm/v3/pkg/time"
+)
+
 // UninstallReleaseResponse represents a successful response to an uninstall request.
 type UninstallReleaseResponse struct {
 	// Release is the release that was marked deleted.
 	Release *Release `json:"release,omitempty"`
 	// Info is an uninstall message
 	Info string `json:"info,omitempty"`
+	// Report describes what the uninstall did to the resources of the
+	// release.
+	Report *UninstallReport `json:"report,omitempty"`
+}
+
+// DeletionOutcome is what an uninstall did to a resource.
+type DeletionOutcome string
+
+// Outcomes of the deletion of a resource.
+const (
+	// DeletionDeleted means the resource was deleted.
+	DeletionDeleted DeletionOutcome = "deleted"
+	// DeletionKept means the resource was kept because of its resource
+	// policy.
+	DeletionKept DeletionOutcome = "kept-by-policy"
+	// DeletionNotFound means the resource no longer existed.
+	DeletionNotFound DeletionOutcome = "not-found"
+	// DeletionFailed means the resource could not be deleted.
+	DeletionFailed DeletionOutcome = "failed"
+)
+
+// UninstallReport describes what an uninstall did to the resources of a
+// release.
+type UninstallReport struct {
+	// Started is when the uninstall started.
+	Started time.Time `json:"started"`
+	// Finished is when the uninstall finished.
+	Finished time.Time `json:"finished"`
+	// Resumed is set if the uninstall resumed a partially failed one.
+	Resumed bool `json:"resumed,omitempty"`
+	// Resources is the outcome of the deletion of each resource.
+	Resources []DeletedResource `json:"resources"`
+}
+
+// DeletedResource is the outcome of the deletion of a resource.
+type DeletedResource struct {
+	Kind      string          `json:"kind"`
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace,omitempty"`
+	Outcome   DeletionOutcome `json:"outcome"`
+	// Error is why the resource could not be deleted
+	Error string `json:"error,omitempty"`
+}
+
+// Failed returns the resources that could not be deleted.
+func (r *UninstallReport) Failed() []DeletedResource {
+	var failed []DeletedResource
+	for _, res := range r.Resources {
+		if res.Outcome == DeletionFailed {
+			failed = append(failed, res)
+		}
+	}
+	return failed
 }
@@ -109,10 +109,7 @@ var UninstallOrder KindSortOrder = []string{
 //
 // Results are sorted by 'ordering', keeping order of items with equal kind/priority
 func sortManifestsByKind(manifests []Manifest, ordering KindSortOrder) []Manifest {
-	sort.SliceStable(manifests, func(i, j int) bool {
-		return lessByKind(manifests[i], manifests[j], manifests[i].Head.Kind, manifests[j].Head.Kind, ordering)
-	})
-
+	KindLess(ordering).Sort(manifests)
 	return manifests
 }
 
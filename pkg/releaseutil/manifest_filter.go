@@ -0,0 +1,206 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// sourcePrefix starts the comment naming the template a document of a
+// rendered manifest comes from.
+const sourcePrefix = "# Source: "
+
+// ParseManifests splits a stream of YAML documents, such as the manifest of a
+// release, and parses the head of each document, in order.
+//
+// The name of each manifest is the template named by its '# Source:' comment,
+// if it has one. Documents holding nothing but comments are skipped.
+func ParseManifests(stream string) ([]Manifest, error) {
+	docs := SplitManifests(stream)
+	keys := make([]string, 0, len(docs))
+	for k := range docs {
+		keys = append(keys, k)
+	}
+	sort.Sort(BySplitManifestsOrder(keys))
+
+	var manifests []Manifest
+	for _, k := range keys {
+		doc := docs[k]
+		if err := validateDocument(doc); err != nil {
+			return manifests, errors.Wrapf(err, "YAML parse error on %s", manifestSource(doc))
+		}
+		var head SimpleHead
+		if err := yaml.Unmarshal([]byte(doc), &head); err != nil {
+			return manifests, errors.Wrapf(err, "YAML parse error on %s", manifestSource(doc))
+		}
+		if head == (SimpleHead{}) {
+			continue
+		}
+		manifests = append(manifests, Manifest{Name: manifestSource(doc), Content: doc, Head: &head})
+	}
+	return manifests, nil
+}
+
+// manifestSource returns the template named by the '# Source:' comment of a
+// document, or an empty string.
+func manifestSource(doc string) string {
+	line := strings.SplitN(doc, "\n", 2)[0]
+	if !strings.HasPrefix(line, sourcePrefix) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(line, sourcePrefix))
+}
+
+// ManifestFilterFunc returns true if the manifest satisfies the predicate of
+// the underlying filter func.
+type ManifestFilterFunc func(Manifest) bool
+
+// Check applies the ManifestFilterFunc to the manifest. Manifests without a
+// head never satisfy it.
+func (fn ManifestFilterFunc) Check(m Manifest) bool {
+	if m.Head == nil {
+		return false
+	}
+	return fn(m)
+}
+
+// Filter applies the filter to the list of provided manifests, returning
+// those that satisfy it, in order.
+func (fn ManifestFilterFunc) Filter(manifests []Manifest) (rets []Manifest) {
+	for _, m := range manifests {
+		if fn.Check(m) {
+			rets = append(rets, m)
+		}
+	}
+	return
+}
+
+// AnyManifest returns a ManifestFilterFunc that filters a list of manifests
+// determined by the predicate 'f0 || f1 || ... || fn'.
+func AnyManifest(filters ...ManifestFilterFunc) ManifestFilterFunc {
+	return func(m Manifest) bool {
+		for _, filter := range filters {
+			if filter(m) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AllManifests returns a ManifestFilterFunc that filters a list of manifests
+// determined by the predicate 'f0 && f1 && ... && fn'.
+func AllManifests(filters ...ManifestFilterFunc) ManifestFilterFunc {
+	return func(m Manifest) bool {
+		for _, filter := range filters {
+			if !filter(m) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// NotManifest returns a ManifestFilterFunc negating the given filter.
+func NotManifest(filter ManifestFilterFunc) ManifestFilterFunc {
+	return func(m Manifest) bool {
+		return !filter(m)
+	}
+}
+
+// KindFilter filters a set of manifests by kind.
+func KindFilter(kinds ...string) ManifestFilterFunc {
+	return func(m Manifest) bool {
+		for _, kind := range kinds {
+			if m.Head.Kind == kind {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AnnotationFilter filters a set of manifests by the value of an
+// annotation. An empty value matches any manifest with the annotation.
+func AnnotationFilter(key, value string) ManifestFilterFunc {
+	return func(m Manifest) bool {
+		if m.Head.Metadata == nil {
+			return false
+		}
+		v, ok := m.Head.Metadata.Annotations[key]
+		return ok && (value == "" || v == value)
+	}
+}
+
+// HookFilter filters a set of manifests to the hooks, the manifests with the
+// 'helm.sh/hook' annotation. Use NotManifest(HookFilter()) for the others.
+func HookFilter() ManifestFilterFunc {
+	return AnnotationFilter(release.HookAnnotation, "")
+}
+
+// ManifestLessFunc returns true if the manifest a sorts before the
+// manifest b.
+type ManifestLessFunc func(a, b Manifest) bool
+
+// Sort sorts the manifests in place, keeping the order of equal manifests.
+func (less ManifestLessFunc) Sort(manifests []Manifest) {
+	sort.SliceStable(manifests, func(i, j int) bool {
+		return less(manifests[i], manifests[j])
+	})
+}
+
+// ThenBy returns a ManifestLessFunc comparing manifests with each of the
+// given funcs in turn, until one of them tells the manifests apart.
+func ThenBy(funcs ...ManifestLessFunc) ManifestLessFunc {
+	return func(a, b Manifest) bool {
+		for _, less := range funcs {
+			switch {
+			case less(a, b):
+				return true
+			case less(b, a):
+				return false
+			}
+		}
+		return false
+	}
+}
+
+// KindLess returns a ManifestLessFunc sorting manifests by kind in the given
+// order, such as InstallOrder. Unknown kinds sort last, alphabetically.
+func KindLess(ordering KindSortOrder) ManifestLessFunc {
+	return func(a, b Manifest) bool {
+		return lessByKind(a, b, a.Head.Kind, b.Head.Kind, ordering)
+	}
+}
+
+// NameLess sorts manifests by the name of their resource.
+func NameLess(a, b Manifest) bool {
+	return resourceName(a) < resourceName(b)
+}
+
+func resourceName(m Manifest) string {
+	if m.Head == nil || m.Head.Metadata == nil {
+		return ""
+	}
+	return m.Head.Metadata.Name
+}
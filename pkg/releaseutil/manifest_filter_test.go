@@ -0,0 +1,123 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+const releaseManifest = `---
+# Source: mychart/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+# Source: mychart/templates/empty.yaml
+---
+# Source: mychart/templates/job.yaml
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    helm.sh/hook: pre-upgrade
+---
+# Source: mychart/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b-config
+  annotations:
+    example.com/tier: backend
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a-config
+`
+
+func manifestNames(manifests []Manifest) []string {
+	var names []string
+	for _, m := range manifests {
+		names = append(names, m.Head.Kind+"/"+resourceName(m))
+	}
+	return names
+}
+
+func TestParseManifests(t *testing.T) {
+	manifests, err := ParseManifests(releaseManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Service/web", "Job/migrate", "ConfigMap/b-config", "ConfigMap/a-config"}
+	if got := manifestNames(manifests); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if manifests[0].Name != "mychart/templates/service.yaml" || manifests[3].Name != "" {
+		t.Errorf("expected the names of the manifests to be their source, got %q and %q", manifests[0].Name, manifests[3].Name)
+	}
+
+	if _, err := ParseManifests("kind: Service\nkind: Pod"); err == nil {
+		t.Error("expected an error for an invalid document")
+	}
+}
+
+func TestManifestFilters(t *testing.T) {
+	manifests, err := ParseManifests(releaseManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		filter   ManifestFilterFunc
+		expected []string
+	}{
+		{"hooks", HookFilter(), []string{"Job/migrate"}},
+		{"not hooks", NotManifest(HookFilter()), []string{"Service/web", "ConfigMap/b-config", "ConfigMap/a-config"}},
+		{"kind", KindFilter("ConfigMap", "Service"), []string{"Service/web", "ConfigMap/b-config", "ConfigMap/a-config"}},
+		{"annotation", AnnotationFilter("example.com/tier", "backend"), []string{"ConfigMap/b-config"}},
+		{"any", AnyManifest(KindFilter("Service"), HookFilter()), []string{"Service/web", "Job/migrate"}},
+		{"all", AllManifests(KindFilter("ConfigMap"), NotManifest(AnnotationFilter("example.com/tier", ""))), []string{"ConfigMap/a-config"}},
+	}
+	for _, tt := range tests {
+		if got := manifestNames(tt.filter.Filter(manifests)); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestManifestLessFuncs(t *testing.T) {
+	manifests, err := ParseManifests(releaseManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ThenBy(KindLess(InstallOrder), NameLess).Sort(manifests)
+	expected := []string{"ConfigMap/a-config", "ConfigMap/b-config", "Service/web", "Job/migrate"}
+	if got := manifestNames(manifests); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	ManifestLessFunc(NameLess).Sort(manifests)
+	expected = []string{"ConfigMap/a-config", "ConfigMap/b-config", "Job/migrate", "Service/web"}
+	if got := manifestNames(manifests); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
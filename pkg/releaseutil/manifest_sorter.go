@@ -17,11 +17,13 @@ limitations under the License.
 package releaseutil
 
 import (
+	"bytes"
 	"log"
 	"path"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
@@ -62,6 +64,7 @@ var events = map[string]release.HookEvent{
 	release.HookPreRollback.String():  release.HookPreRollback,
 	release.HookPostRollback.String(): release.HookPostRollback,
 	release.HookTest.String():         release.HookTest,
+	release.HookMigration.String():    release.HookMigration,
 	// Support test-success for backward compatibility with Helm 2 tests
 	"test-success": release.HookTest,
 }
@@ -141,6 +144,10 @@ func (file *manifestFile) sort(result *result) error {
 	for _, entryKey := range sortedEntryKeys {
 		m := file.entries[entryKey]
 
+		if err := validateDocument(m); err != nil {
+			return errors.Wrapf(err, "YAML parse error on %s", file.path)
+		}
+
 		var entry SimpleHead
 		if err := yaml.Unmarshal([]byte(m), &entry); err != nil {
 			return errors.Wrapf(err, "YAML parse error on %s", file.path)
@@ -175,7 +182,10 @@ func (file *manifestFile) sort(result *result) error {
 			Events:         []release.HookEvent{},
 			Weight:         hw,
 			DeletePolicies: []release.HookDeletePolicy{},
+			TimeoutSeconds: calculateHookTimeout(entry),
+			TTLSeconds:     calculateHookTTL(entry),
 		}
+		h.SuccessCondition = strings.TrimSpace(entry.Metadata.Annotations[release.HookSuccessConditionAnnotation])
 
 		isUnknownHook := false
 		for _, hookType := range strings.Split(hookTypes, ",") {
@@ -203,6 +213,21 @@ func (file *manifestFile) sort(result *result) error {
 	return nil
 }
 
+// validateDocument checks that a document of a rendered manifest is a single
+// well-formed object. Documents joined by a missing or misplaced '---'
+// separator usually define the same keys twice, which is reported.
+func validateDocument(doc string) error {
+	data, err := yaml.YAMLToJSONStrict([]byte(doc))
+	if err != nil {
+		return errors.Wrap(err, "invalid document, check for a missing '---' separator")
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] == '{' || string(data) == "null" {
+		return nil
+	}
+	return errors.New("the document is not an object, check for a stray '---' separator")
+}
+
 // hasAnyAnnotation returns true if the given entry has any annotations at all.
 func hasAnyAnnotation(entry SimpleHead) bool {
 	return entry.Metadata != nil &&
@@ -222,6 +247,50 @@ func calculateHookWeight(entry SimpleHead) int {
 	return hw
 }
 
+// calculateHookTimeout finds the timeout in the hook timeout annotation.
+//
+// If no valid timeout is found, the assigned timeout is 0
+func calculateHookTimeout(entry SimpleHead) int64 {
+	hts, ok := entry.Metadata.Annotations[release.HookTimeoutAnnotation]
+	if !ok {
+		return 0
+	}
+	secs, ok := parseHookSeconds(hts)
+	if !ok || secs == 0 {
+		log.Printf("info: ignoring invalid hook timeout %q on %s", hts, entry.Metadata.Name)
+		return 0
+	}
+	return secs
+}
+
+// calculateHookTTL finds the time to live in the hook TTL annotation.
+//
+// If no valid TTL is found, nil is returned
+func calculateHookTTL(entry SimpleHead) *int64 {
+	ttl, ok := entry.Metadata.Annotations[release.HookTTLAnnotation]
+	if !ok {
+		return nil
+	}
+	secs, ok := parseHookSeconds(ttl)
+	if !ok {
+		log.Printf("info: ignoring invalid hook TTL %q on %s", ttl, entry.Metadata.Name)
+		return nil
+	}
+	return &secs
+}
+
+// parseHookSeconds parses a non-negative number of seconds, given either as
+// a duration, such as "90s" or "5m", or as a plain number.
+func parseHookSeconds(value string) (int64, bool) {
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return secs, secs >= 0
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return int64(d.Round(time.Second) / time.Second), true
+	}
+	return 0, false
+}
+
 // operateAnnotationValues finds the given annotation and runs the operate function with the value of that annotation
 func operateAnnotationValues(entry SimpleHead, annotation string, operate func(p string)) {
 	if dps, ok := entry.Metadata.Annotations[annotation]; ok {
@@ -18,6 +18,7 @@ package releaseutil
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"sigs.k8s.io/yaml"
@@ -226,3 +227,112 @@ metadata:
 		}
 	}
 }
+
+func TestSortManifestsInvalidDocuments(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		expect   string
+	}{
+		{
+			name: "missing separator",
+			manifest: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: second
+`,
+			expect: "missing '---' separator",
+		},
+		{
+			name: "stray separator",
+			manifest: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+---
+- name: second
+`,
+			expect: "not an object",
+		},
+	}
+
+	for _, tt := range tests {
+		_, _, err := SortManifests(map[string]string{"templates/cm.yaml": tt.manifest}, chartutil.VersionSet{"v1"}, InstallOrder)
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.expect) || !strings.Contains(err.Error(), "templates/cm.yaml") {
+			t.Errorf("%s: unexpected error %q", tt.name, err)
+		}
+	}
+}
+
+func TestSortManifestsHookSuccessCondition(t *testing.T) {
+	manifest := `apiVersion: example.com/v1
+kind: Backup
+metadata:
+  name: backup
+  annotations:
+    helm.sh/hook: pre-upgrade
+    helm.sh/hook-success-condition: " Completed "
+`
+	hooks, _, err := SortManifests(map[string]string{"templates/backup.yaml": manifest}, chartutil.VersionSet{"v1"}, InstallOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 1 || hooks[0].SuccessCondition != "Completed" {
+		t.Errorf("expected a hook with the success condition Completed, got %v", hooks)
+	}
+}
+
+func TestCalculateHookTimeout(t *testing.T) {
+	for value, expect := range map[string]int64{
+		"300":     300,
+		"90s":     90,
+		"5m":      300,
+		"1m500ms": 61,
+		"0":       0,
+		"-10":     0,
+		"soon":    0,
+	} {
+		var sh SimpleHead
+		manifest := "kind: Job\nmetadata:\n  name: hook\n  annotations:\n    helm.sh/hook-timeout: \"" + value + "\"\n"
+		if err := yaml.Unmarshal([]byte(manifest), &sh); err != nil {
+			t.Fatal(err)
+		}
+		if got := calculateHookTimeout(sh); got != expect {
+			t.Errorf("hook timeout %q: expected %d, got %d", value, expect, got)
+		}
+	}
+}
+
+func TestCalculateHookTTL(t *testing.T) {
+	for value, expect := range map[string]int64{
+		"0":   0,
+		"600": 600,
+		"1h":  3600,
+	} {
+		var sh SimpleHead
+		manifest := "kind: Job\nmetadata:\n  name: hook\n  annotations:\n    helm.sh/hook-ttl: \"" + value + "\"\n"
+		if err := yaml.Unmarshal([]byte(manifest), &sh); err != nil {
+			t.Fatal(err)
+		}
+		got := calculateHookTTL(sh)
+		if got == nil || *got != expect {
+			t.Errorf("hook TTL %q: expected %d, got %v", value, expect, got)
+		}
+	}
+
+	var sh SimpleHead
+	if err := yaml.Unmarshal([]byte("kind: Job\nmetadata:\n  name: hook\n  annotations:\n    helm.sh/hook-ttl: later\n"), &sh); err != nil {
+		t.Fatal(err)
+	}
+	if got := calculateHookTTL(sh); got != nil {
+		t.Errorf("expected an invalid TTL to be ignored, got %d", *got)
+	}
+}
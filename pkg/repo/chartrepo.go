@@ -113,35 +113,43 @@ func (r *ChartRepository) Load() error {
 
 // DownloadIndexFile fetches the index from a repository.
 func (r *ChartRepository) DownloadIndexFile() (string, error) {
-	parsedURL, err := url.Parse(r.Config.URL)
+	index, err := r.fetch("index.yaml")
 	if err != nil {
 		return "", err
 	}
-	parsedURL.RawPath = path.Join(parsedURL.RawPath, "index.yaml")
-	parsedURL.Path = path.Join(parsedURL.Path, "index.yaml")
 
-	indexURL := parsedURL.String()
-	// TODO add user-agent
-	resp, err := r.Client.Get(indexURL,
-		getter.WithURL(r.Config.URL),
-		getter.WithInsecureSkipVerifyTLS(r.Config.InsecureSkipTLSverify),
-		getter.WithTLSClientConfig(r.Config.CertFile, r.Config.KeyFile, r.Config.CAFile),
-		getter.WithBasicAuth(r.Config.Username, r.Config.Password),
-	)
+	indexFile, err := loadIndex(index)
 	if err != nil {
 		return "", err
 	}
+	return r.cacheIndex(index, indexFile)
+}
 
-	index, err := ioutil.ReadAll(resp)
+// fetch gets a file relative to the URL of the repository.
+func (r *ChartRepository) fetch(name string) ([]byte, error) {
+	parsedURL, err := url.Parse(r.Config.URL)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	parsedURL.RawPath = path.Join(parsedURL.RawPath, name)
+	parsedURL.Path = path.Join(parsedURL.Path, name)
 
-	indexFile, err := loadIndex(index)
+	// TODO add user-agent
+	resp, err := r.Client.Get(parsedURL.String(),
+		getter.WithURL(r.Config.URL),
+		getter.WithInsecureSkipVerifyTLS(r.Config.InsecureSkipTLSverify),
+		getter.WithTLSClientConfig(r.Config.CertFile, r.Config.KeyFile, r.Config.CAFile),
+		getter.WithBasicAuth(r.Config.Username, r.Config.Password),
+	)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	return ioutil.ReadAll(resp)
+}
 
+// cacheIndex writes the index, and the list of its charts, to the cache
+// directory, and returns the path of the cached index.
+func (r *ChartRepository) cacheIndex(index []byte, indexFile *IndexFile) (string, error) {
 	// Create the chart list file in the cache directory
 	var charts strings.Builder
 	for name := range indexFile.Entries {
@@ -79,6 +79,17 @@ func (r *File) update(e *Entry) {
 	r.Add(e)
 }
 
+// UpdateIfSameURL replaces the entry with the same name as the given one if
+// it has the same URL, or adds it if there is none. It returns false, leaving
+// the file unchanged, if the entry with the same name has another URL.
+func (r *File) UpdateIfSameURL(e *Entry) bool {
+	if existing := r.Get(e.Name); existing != nil && existing.URL != e.URL {
+		return false
+	}
+	r.update(e)
+	return true
+}
+
 // Has returns true if the given name is already a repository name.
 func (r *File) Has(name string) bool {
 	entry := r.Get(name)
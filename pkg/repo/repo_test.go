@@ -185,6 +185,29 @@ func TestUpdateRepository(t *testing.T) {
 	}
 }
 
+func TestUpdateIfSameURL(t *testing.T) {
+	f := NewFile()
+	f.Add(&Entry{Name: "stable", URL: "https://example.com/stable/charts"})
+
+	if !f.UpdateIfSameURL(&Entry{Name: "stable", URL: "https://example.com/stable/charts", Username: "admin"}) {
+		t.Error("expected the repository with the same URL to be replaced")
+	}
+	if f.Get("stable").Username != "admin" {
+		t.Errorf("expected the repository to be updated, got %+v", f.Get("stable"))
+	}
+
+	if f.UpdateIfSameURL(&Entry{Name: "stable", URL: "https://example.com/other"}) {
+		t.Error("expected the repository with another URL to be kept")
+	}
+	if f.Get("stable").URL != "https://example.com/stable/charts" {
+		t.Errorf("expected the repository to be unchanged, got %+v", f.Get("stable"))
+	}
+
+	if !f.UpdateIfSameURL(&Entry{Name: "incubator", URL: "https://example.com/incubator"}) || len(f.Repositories) != 2 {
+		t.Errorf("expected a new repository to be added, got %+v", f.Repositories)
+	}
+}
+
 func TestWriteFile(t *testing.T) {
 	sampleRepository := NewFile()
 	sampleRepository.Add(
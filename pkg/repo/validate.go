@@ -0,0 +1,131 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/provenance"
+)
+
+// ValidationLevel is how strictly a chart repository is checked before it is
+// added.
+type ValidationLevel int
+
+const (
+	// ValidateNone does not check the repository.
+	ValidateNone ValidationLevel = iota
+	// ValidateReachable checks that the index of the repository can be
+	// fetched.
+	ValidateReachable
+	// ValidateIndex also checks that the index is valid. This is the default.
+	ValidateIndex
+	// ValidateSigned also checks that the index is signed by a trusted key, in
+	// an index.yaml.prov file next to it.
+	ValidateSigned
+)
+
+var validationLevels = []string{"none", "reachable", "index", "signed"}
+
+// ValidationLevels returns the names of the validation levels, in order of
+// strictness.
+func ValidationLevels() []string {
+	return append([]string(nil), validationLevels...)
+}
+
+// ParseValidationLevel returns the validation level with the given name.
+func ParseValidationLevel(s string) (ValidationLevel, error) {
+	for i, name := range validationLevels {
+		if s == name {
+			return ValidationLevel(i), nil
+		}
+	}
+	return ValidateNone, errors.Errorf("invalid validation level %q, must be one of %v", s, validationLevels)
+}
+
+func (l ValidationLevel) String() string {
+	if l < 0 || int(l) >= len(validationLevels) {
+		return "unknown"
+	}
+	return validationLevels[l]
+}
+
+// ValidateOptions configures the validation of a chart repository.
+type ValidateOptions struct {
+	Level ValidationLevel
+	// Keyring is the keyring of the keys trusted to sign the index, for
+	// ValidateSigned
+	Keyring string
+	// Retries is how many more times the index is fetched if it cannot be
+	// reached
+	Retries int
+	// RetryDelay is how long to wait before each retry. It is doubled after
+	// each of them.
+	RetryDelay time.Duration
+}
+
+// Validate checks the repository at the given level. The index is written to
+// the cache directory if it is found to be valid.
+func (r *ChartRepository) Validate(opts ValidateOptions) error {
+	if opts.Level == ValidateNone {
+		return nil
+	}
+
+	index, err := r.fetchWithRetries("index.yaml", opts)
+	if err != nil {
+		return errors.Wrap(err, "index cannot be reached")
+	}
+	if opts.Level == ValidateReachable {
+		return nil
+	}
+
+	indexFile, err := loadIndex(index)
+	if err != nil {
+		return errors.Wrap(err, "index is not valid")
+	}
+
+	if opts.Level >= ValidateSigned {
+		sig, err := r.fetchWithRetries("index.yaml.prov", opts)
+		if err != nil {
+			return errors.Wrap(err, "index signature cannot be reached")
+		}
+		signer, err := provenance.NewFromKeyring(opts.Keyring, "")
+		if err != nil {
+			return errors.Wrap(err, "failed to load keyring")
+		}
+		if _, err := signer.VerifyData("index.yaml", index, sig); err != nil {
+			return errors.Wrap(err, "index signature is not valid")
+		}
+	}
+
+	_, err = r.cacheIndex(index, indexFile)
+	return err
+}
+
+func (r *ChartRepository) fetchWithRetries(name string, opts ValidateOptions) ([]byte, error) {
+	delay := opts.RetryDelay
+	for i := 0; ; i++ {
+		data, err := r.fetch(name)
+		if err == nil || i >= opts.Retries {
+			return data, err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
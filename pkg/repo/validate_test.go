@@ -0,0 +1,141 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/provenance"
+)
+
+const (
+	testSigningKey = "../provenance/testdata/helm-test-key.secret"
+	testKeyring    = "../provenance/testdata/helm-test-key.pub"
+)
+
+func TestParseValidationLevel(t *testing.T) {
+	for _, name := range ValidationLevels() {
+		l, err := ParseValidationLevel(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.String() != name {
+			t.Errorf("expected %q, got %q", name, l)
+		}
+	}
+	if _, err := ParseValidationLevel("paranoid"); err == nil {
+		t.Error("expected an error for an unknown validation level")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	index, err := ioutil.ReadFile("testdata/local-index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := provenance.NewFromFiles(testSigningKey, testKeyring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signer.ClearSignData("index.yaml", index)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var failures, requests int
+	files := map[string][]byte{"/index.yaml": index}
+	srv, err := startLocalServerForTests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		data, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	r, err := NewChartRepository(&Entry{Name: "test", URL: srv.URL}, getter.All(&cli.EnvSettings{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.CachePath = ensure.TempDir(t)
+	defer os.RemoveAll(r.CachePath)
+
+	// Nothing is fetched without validation
+	if err := r.Validate(ValidateOptions{Level: ValidateNone}); err != nil || requests != 0 {
+		t.Errorf("expected no request, got %d: %v", requests, err)
+	}
+
+	// The index is fetched again until it is reached
+	failures = 2
+	opts := ValidateOptions{Level: ValidateIndex, Retries: 1}
+	if err := r.Validate(opts); err == nil {
+		t.Error("expected the index to be unreachable after a retry")
+	}
+	opts.Retries = 2
+	requests, failures = 0, 2
+	if err := r.Validate(opts); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+	if _, err := os.Stat(filepath.Join(r.CachePath, helmpath.CacheIndexFile("test"))); err != nil {
+		t.Errorf("expected the index to be cached: %v", err)
+	}
+
+	// An invalid index is only accepted if it is reachable
+	files["/index.yaml"] = []byte("not: [an index")
+	if err := r.Validate(ValidateOptions{Level: ValidateReachable}); err != nil {
+		t.Error(err)
+	}
+	if err := r.Validate(ValidateOptions{Level: ValidateIndex}); err == nil {
+		t.Error("expected the index to be invalid")
+	}
+
+	// A signed index must have a valid signature
+	files["/index.yaml"] = index
+	opts = ValidateOptions{Level: ValidateSigned, Keyring: testKeyring}
+	if err := r.Validate(opts); err == nil {
+		t.Error("expected an error for an index without signature")
+	}
+	files["/index.yaml.prov"] = []byte(sig)
+	if err := r.Validate(opts); err != nil {
+		t.Error(err)
+	}
+	files["/index.yaml"] = append(index, "\n# tampered\n"...)
+	if err := r.Validate(opts); err == nil {
+		t.Error("expected an error for a tampered index")
+	}
+}
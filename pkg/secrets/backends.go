@@ -0,0 +1,85 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+// EnvBackend resolves 'ref+env://NAME' references to the value of the
+// environment variable NAME.
+type EnvBackend struct{}
+
+// Resolve returns the value of the environment variable.
+func (EnvBackend) Resolve(ref *Reference) (interface{}, error) {
+	if ref.Key != "" {
+		return nil, errors.Errorf("the env backend does not support keys")
+	}
+	v, ok := os.LookupEnv(ref.Path)
+	if !ok {
+		return nil, errors.Errorf("environment variable %s is not set", ref.Path)
+	}
+	return v, nil
+}
+
+// FileBackend resolves 'ref+file://path#key' references to the value at the
+// dotted key of the YAML file at path, or to the content of the file if there
+// is no key.
+type FileBackend struct{}
+
+// Resolve reads the secret from the file.
+func (FileBackend) Resolve(ref *Reference) (interface{}, error) {
+	data, err := ioutil.ReadFile(ref.Path)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Key == "" {
+		return string(data), nil
+	}
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse %s", ref.Path)
+	}
+	for _, k := range strings.Split(ref.Key, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("key %s not found in %s", ref.Key, ref.Path)
+		}
+		if v, ok = m[k]; !ok {
+			return nil, errors.Errorf("key %s not found in %s", ref.Key, ref.Path)
+		}
+	}
+	return v, nil
+}
+
+// All returns the built-in backends and the backends of the secret backend
+// plugins installed in the plugins directory.
+func All(settings *cli.EnvSettings) Providers {
+	result := Providers{
+		{Schemes: []string{"env"}, Backend: EnvBackend{}},
+		{Schemes: []string{"file"}, Backend: FileBackend{}},
+	}
+	plugins, _ := collectPlugins(settings)
+	return append(result, plugins...)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/plugin"
+)
+
+// collectPlugins scans for secret backend plugins.
+func collectPlugins(settings *cli.EnvSettings) (Providers, error) {
+	plugins, err := plugin.FindPlugins(settings.PluginsDirectory)
+	if err != nil {
+		return nil, err
+	}
+	var result Providers
+	for _, plug := range plugins {
+		for _, sb := range plug.Metadata.SecretBackends {
+			result = append(result, Provider{
+				Schemes: sb.Schemes,
+				Backend: &pluginBackend{
+					settings: settings,
+					name:     plug.Metadata.Name,
+					dir:      plug.Dir,
+					command:  sb.Command,
+				},
+			})
+		}
+	}
+	return result, nil
+}
+
+// pluginBackend resolves references by running the command of a secret
+// backend plugin.
+type pluginBackend struct {
+	settings *cli.EnvSettings
+	name     string
+	dir      string
+	command  string
+}
+
+// Resolve runs the plugin command with the reference as its last argument.
+func (p *pluginBackend) Resolve(ref *Reference) (interface{}, error) {
+	commands := strings.Split(p.command, " ")
+	argv := append(commands[1:], ref.String())
+	prog := exec.Command(filepath.Join(p.dir, commands[0]), argv...)
+	plugin.SetupPluginEnv(p.settings, p.name, p.dir)
+	prog.Env = os.Environ()
+	buf := bytes.NewBuffer(nil)
+	prog.Stdout = buf
+	prog.Stderr = os.Stderr
+	if err := prog.Run(); err != nil {
+		return nil, errors.Wrapf(err, "secret backend plugin %q failed", p.name)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
@@ -0,0 +1,249 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package secrets resolves the secret references found in values.
+
+A secret reference is a string value of the form
+
+	ref+<backend>://<path>[#<key>]
+
+such as 'ref+vault://secret/data/db#password'. References are replaced by the
+secrets they point to when the chart is rendered, so that secrets never need
+to be written to values files, nor stored in the release's values.
+
+The env and file backends are built in:
+
+	ref+env://DB_PASSWORD
+	ref+file://secrets.yaml#db.password
+
+Other backends are supplied by plugins, which declare the backends they
+implement in their plugin.yaml:
+
+	name: vault-secrets
+	secretBackends:
+	  - schemes:
+	      - vault
+	    command: "bin/resolve"
+
+Helm runs the command with the reference as its last argument and reads the
+secret on its standard output, without its trailing newline.
+*/
+package secrets // import "helm.sh/helm/v3/pkg/secrets"
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// RefPrefix is the prefix of secret references.
+const RefPrefix = "ref+"
+
+// Reference points to a secret held by a backend.
+type Reference struct {
+	// Backend is the scheme of the reference, such as 'vault'
+	Backend string
+	// Path is the location of the secret in the backend
+	Path string
+	// Key selects a field of the secret. It is optional.
+	Key string
+}
+
+// IsReference returns true if the value is a secret reference.
+func IsReference(v interface{}) bool {
+	s, ok := v.(string)
+	return ok && strings.HasPrefix(s, RefPrefix)
+}
+
+// ParseReference parses a secret reference.
+func ParseReference(s string) (*Reference, error) {
+	if !strings.HasPrefix(s, RefPrefix) {
+		return nil, errors.Errorf("secret reference %q does not start with %q", s, RefPrefix)
+	}
+	parts := strings.SplitN(strings.TrimPrefix(s, RefPrefix), "://", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, errors.Errorf("invalid secret reference %q: must be of the form %s<backend>://<path>[#<key>]", s, RefPrefix)
+	}
+	ref := &Reference{Backend: parts[0], Path: parts[1]}
+	if i := strings.LastIndex(ref.Path, "#"); i >= 0 {
+		ref.Path, ref.Key = ref.Path[:i], ref.Path[i+1:]
+	}
+	return ref, nil
+}
+
+// String returns the reference as it is written in values.
+func (r *Reference) String() string {
+	s := RefPrefix + r.Backend + "://" + r.Path
+	if r.Key != "" {
+		s += "#" + r.Key
+	}
+	return s
+}
+
+// Backend resolves the references to the secrets it holds.
+type Backend interface {
+	// Resolve returns the secret the reference points to.
+	Resolve(ref *Reference) (interface{}, error)
+}
+
+// Provider represents a backend and the schemes it supports.
+type Provider struct {
+	Schemes []string
+	Backend Backend
+}
+
+// Provides returns true if the given scheme is supported by this Provider.
+func (p Provider) Provides(scheme string) bool {
+	for _, s := range p.Schemes {
+		if s == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+// Providers is a collection of Provider objects.
+type Providers []Provider
+
+// ByScheme returns the backend that handles the given scheme.
+//
+// If no provider handles this scheme, this will return an error.
+func (p Providers) ByScheme(scheme string) (Backend, error) {
+	for _, pp := range p {
+		if pp.Provides(scheme) {
+			return pp.Backend, nil
+		}
+	}
+	return nil, errors.Errorf("no secret backend for scheme %q", scheme)
+}
+
+// Resolver replaces the secret references of values by their secrets.
+//
+// Secrets are cached for the lifetime of the resolver, so that each reference
+// is resolved once. Every resolution is recorded in the audit log, without
+// the secret.
+type Resolver struct {
+	providers Providers
+	// Log receives the audit records of the resolutions. It may be nil.
+	Log func(format string, v ...interface{})
+
+	mu    sync.Mutex
+	cache map[string]interface{}
+}
+
+// NewResolver creates a resolver resolving references with the backends of
+// the given providers.
+func NewResolver(providers Providers) *Resolver {
+	return &Resolver{
+		providers: providers,
+		cache:     map[string]interface{}{},
+	}
+}
+
+// Resolve returns the secret a reference points to. The key is the path of
+// the value holding the reference, used in the audit log.
+func (r *Resolver) Resolve(key, s string) (interface{}, error) {
+	r.mu.Lock()
+	secret, ok := r.cache[s]
+	r.mu.Unlock()
+	if ok {
+		r.audit("resolved secret reference %s for value %s (cached)", s, key)
+		return secret, nil
+	}
+
+	ref, err := ParseReference(s)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := r.providers.ByScheme(ref.Backend)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to resolve secret reference %s for value %s", s, key)
+	}
+	secret, err = backend.Resolve(ref)
+	if err != nil {
+		r.audit("failed to resolve secret reference %s for value %s: %s", s, key, err)
+		return nil, errors.Wrapf(err, "unable to resolve secret reference %s for value %s", s, key)
+	}
+	r.audit("resolved secret reference %s for value %s", s, key)
+
+	r.mu.Lock()
+	r.cache[s] = secret
+	r.mu.Unlock()
+	return secret, nil
+}
+
+// ResolveValues returns a copy of the values with every secret reference
+// replaced by its secret. The values are left unchanged.
+func (r *Resolver) ResolveValues(vals map[string]interface{}) (map[string]interface{}, error) {
+	out, err := r.resolve("", vals)
+	if err != nil {
+		return nil, err
+	}
+	return out.(map[string]interface{}), nil
+}
+
+func (r *Resolver) resolve(key string, v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		// Walk the keys in order so that the audit log is deterministic
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make(map[string]interface{}, len(v))
+		for _, k := range keys {
+			child, err := r.resolve(joinKey(key, k), v[k])
+			if err != nil {
+				return nil, err
+			}
+			out[k] = child
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			child, err := r.resolve(fmt.Sprintf("%s[%d]", key, i), item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = child
+		}
+		return out, nil
+	case string:
+		if IsReference(v) {
+			return r.Resolve(key, v)
+		}
+	}
+	return v, nil
+}
+
+func (r *Resolver) audit(format string, v ...interface{}) {
+	if r.Log != nil {
+		r.Log(format, v...)
+	}
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,158 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		ref    string
+		expect *Reference
+		err    bool
+	}{
+		{ref: "ref+vault://secret/data/db#password", expect: &Reference{Backend: "vault", Path: "secret/data/db", Key: "password"}},
+		{ref: "ref+awssm://prod/db", expect: &Reference{Backend: "awssm", Path: "prod/db"}},
+		{ref: "ref+file:///etc/secrets.yaml#db.password", expect: &Reference{Backend: "file", Path: "/etc/secrets.yaml", Key: "db.password"}},
+		{ref: "vault://secret", err: true},
+		{ref: "ref+vault", err: true},
+		{ref: "ref+://secret", err: true},
+		{ref: "ref+vault://", err: true},
+	}
+	for _, tt := range tests {
+		ref, err := ParseReference(tt.ref)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.ref)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.ref, err)
+			continue
+		}
+		if !reflect.DeepEqual(ref, tt.expect) {
+			t.Errorf("%s: expected %+v, got %+v", tt.ref, tt.expect, ref)
+		}
+		if ref.String() != tt.ref {
+			t.Errorf("%s: round trip gave %s", tt.ref, ref.String())
+		}
+	}
+}
+
+func TestResolveValues(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("TODO: refactor this test to work on windows")
+	}
+	tmpdir := ensure.TempDir(t)
+	defer os.RemoveAll(tmpdir)
+
+	calls := filepath.Join(tmpdir, "calls")
+	os.Setenv("HELM_SECRETS_TEST_CALLS", calls)
+	defer os.Unsetenv("HELM_SECRETS_TEST_CALLS")
+	os.Setenv("HELM_SECRETS_TEST_TOKEN", "t0ken")
+	defer os.Unsetenv("HELM_SECRETS_TEST_TOKEN")
+
+	env := cli.New()
+	env.PluginsDirectory = "testdata/plugins"
+	r := NewResolver(All(env))
+	var audit []string
+	r.Log = func(format string, v ...interface{}) {
+		audit = append(audit, fmt.Sprintf(format, v...))
+	}
+
+	vals := map[string]interface{}{
+		"token": "ref+env://HELM_SECRETS_TEST_TOKEN",
+		"db": map[string]interface{}{
+			"password": "ref+file://testdata/secrets.yaml#db.password",
+			"port":     "ref+file://testdata/secrets.yaml#db.port",
+			"user":     "admin",
+		},
+		"apiKeys": []interface{}{"ref+vault://secret/api#key", "ref+vault://secret/api#key"},
+	}
+	resolved, err := r.ResolveValues(vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := map[string]interface{}{
+		"token": "t0ken",
+		"db": map[string]interface{}{
+			"password": "hunter2",
+			"port":     float64(5432),
+			"user":     "admin",
+		},
+		"apiKeys": []interface{}{"secret-for-ref+vault://secret/api#key", "secret-for-ref+vault://secret/api#key"},
+	}
+	if !reflect.DeepEqual(resolved, expect) {
+		t.Errorf("Expected %v, got %v", expect, resolved)
+	}
+	if vals["token"] != "ref+env://HELM_SECRETS_TEST_TOKEN" {
+		t.Error("Expected the values to be left unchanged")
+	}
+
+	// The plugin is run once, the second reference being cached
+	data, err := ioutil.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Fields(string(data)); len(got) != 1 || got[0] != "ref+vault://secret/api#key" {
+		t.Errorf("Expected a single plugin call, got %v", got)
+	}
+
+	expectAudit := []string{
+		"resolved secret reference ref+vault://secret/api#key for value apiKeys[0]",
+		"resolved secret reference ref+vault://secret/api#key for value apiKeys[1] (cached)",
+		"resolved secret reference ref+file://testdata/secrets.yaml#db.password for value db.password",
+		"resolved secret reference ref+file://testdata/secrets.yaml#db.port for value db.port",
+		"resolved secret reference ref+env://HELM_SECRETS_TEST_TOKEN for value token",
+	}
+	if !reflect.DeepEqual(audit, expectAudit) {
+		t.Errorf("Expected audit log\n%s\ngot\n%s", strings.Join(expectAudit, "\n"), strings.Join(audit, "\n"))
+	}
+	for _, line := range audit {
+		if strings.Contains(line, "hunter2") || strings.Contains(line, "t0ken") {
+			t.Errorf("Secret leaked to the audit log: %s", line)
+		}
+	}
+}
+
+func TestResolveValuesErrors(t *testing.T) {
+	r := NewResolver(Providers{{Schemes: []string{"env"}, Backend: EnvBackend{}}})
+	for _, tt := range []struct {
+		ref, err string
+	}{
+		{"ref+awssm://prod/db", `unable to resolve secret reference ref+awssm://prod/db for value secret: no secret backend for scheme "awssm"`},
+		{"ref+env://HELM_SECRETS_TEST_UNSET", "unable to resolve secret reference ref+env://HELM_SECRETS_TEST_UNSET for value secret: environment variable HELM_SECRETS_TEST_UNSET is not set"},
+	} {
+		_, err := r.ResolveValues(map[string]interface{}{"secret": tt.ref})
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("Expected error %q, got %v", tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+#!/bin/sh
+# Prints a secret for the reference given as the last argument, counting the
+# calls in $HELM_SECRETS_TEST_CALLS.
+for ref; do :; done
+if [ -n "$HELM_SECRETS_TEST_CALLS" ]; then
+  echo "$ref" >> "$HELM_SECRETS_TEST_CALLS"
+fi
+echo "secret-for-$ref"
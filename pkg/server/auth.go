@@ -0,0 +1,94 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server // import "helm.sh/helm/v3/pkg/server"
+
+import (
+	"crypto/subtle"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// User is the Kubernetes user a caller is impersonating.
+type User struct {
+	Name   string   `json:"user"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// Token is a bearer token, with the user it authenticates.
+type Token struct {
+	Token string `json:"token"`
+	User
+}
+
+// Tokens are the bearer tokens accepted by a server.
+type Tokens []Token
+
+// ReadTokens reads the tokens accepted by a server, listed as YAML:
+//
+//	tokens:
+//	  - token: 3f2b8c...
+//	    user: ci
+//	    groups: [platform]
+func ReadTokens(data []byte) (Tokens, error) {
+	var file struct {
+		Tokens Tokens `json:"tokens"`
+	}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, errors.Wrap(err, "invalid tokens")
+	}
+	tokens := file.Tokens
+	for i, t := range tokens {
+		if t.Token == "" || t.Name == "" {
+			return nil, errors.Errorf("invalid tokens: entry %d needs a token and a user", i+1)
+		}
+	}
+	return tokens, nil
+}
+
+// LoadTokens reads the tokens accepted by a server from a file.
+func LoadTokens(filename string) (Tokens, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := ReadTokens(data)
+	return tokens, errors.Wrapf(err, "unable to load %s", filename)
+}
+
+// Authenticate returns the user authenticated by the bearer token of a
+// request.
+func (t Tokens) Authenticate(r *http.Request) (*User, bool) {
+	const prefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, prefix) {
+		return nil, false
+	}
+	token := []byte(strings.TrimPrefix(auth, prefix))
+
+	var user *User
+	for i := range t {
+		// Compare every token in constant time, not to reveal which one matched
+		if subtle.ConstantTimeCompare(token, []byte(t[i].Token)) == 1 && user == nil {
+			user = &t[i].User
+		}
+	}
+	return user, user != nil
+}
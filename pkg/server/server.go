@@ -0,0 +1,495 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package server serves the operations of Helm on releases over HTTP.
+
+It lets platforms drive Helm remotely, with JSON requests authenticated by
+bearer tokens:
+
+	GET    /v1/namespaces/{namespace}/releases                  list the releases
+	POST   /v1/namespaces/{namespace}/releases                  install a release
+	GET    /v1/namespaces/{namespace}/releases/{name}           get the status of a release
+	PUT    /v1/namespaces/{namespace}/releases/{name}           upgrade a release
+	POST   /v1/namespaces/{namespace}/releases/{name}/rollback  roll back a release
+
+Each token authenticates a user, which the operations the token is used for
+impersonate on the Kubernetes API. The permissions of a caller are thus those
+RBAC grants to its user, the server itself only needing the permission to
+impersonate the users.
+
+Charts are referenced as repo/chart, from the repositories configured for the
+server, or as oci:// references. Charts of the chart root of the server, if
+any, are referenced by their path relative to it. Any other local path or URL
+is refused, so that callers can neither read the files of the server nor make
+it fetch from arbitrary hosts.
+
+Errors are returned as {"error": "..."} with the HTTP status fitting them.
+*/
+package server // import "helm.sh/helm/v3/pkg/server"
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"helm.sh/helm/v3/internal/experimental/registry"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// defaultTimeout is the timeout of the operations of requests that set none,
+// the default of the commands.
+const defaultTimeout = 300 * time.Second
+
+// maxRequestSize is the maximum size of the body of a request.
+const maxRequestSize = 10 << 20
+
+// Server serves the operations of Helm on releases over HTTP.
+type Server struct {
+	// Settings locate the charts and the Kubernetes API. The namespace and
+	// the impersonated user are set per request.
+	Settings *cli.EnvSettings
+	// Tokens authenticate the callers.
+	Tokens Tokens
+	// ChartRoot is the directory of the local charts requests may reference,
+	// by their path relative to it. Requests may not reference local charts
+	// if it is empty.
+	ChartRoot string
+	// NewConfiguration returns the configuration of the actions of a request,
+	// given the client getter impersonating its user and its namespace.
+	NewConfiguration func(getter genericclioptions.RESTClientGetter, namespace string) (*action.Configuration, error)
+	// Log logs the requests served.
+	Log func(string, ...interface{})
+}
+
+// Handler returns the HTTP handler of the server.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/v1/namespaces/", s.serveReleases)
+	return mux
+}
+
+// serveReleases authenticates a request on releases and routes it to its
+// operation.
+func (s *Server) serveReleases(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.Tokens.Authenticate(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="helm"`)
+		writeError(w, http.StatusUnauthorized, errors.New("a valid bearer token is required"))
+		return
+	}
+
+	// namespaces/{namespace}/releases[/{name}[/rollback]]
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/"), "/"), "/")
+	if len(parts) < 3 || len(parts) > 5 || parts[1] == "" || parts[2] != "releases" || (len(parts) == 5 && parts[4] != "rollback") {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	namespace := parts[1]
+	var name string
+	if len(parts) > 3 {
+		name = parts[3]
+	}
+	s.log("%s %s from %s", r.Method, r.URL.Path, user.Name)
+
+	var op func(*action.Configuration) (int, interface{}, error)
+	switch {
+	case len(parts) == 3 && r.Method == http.MethodGet:
+		op = func(cfg *action.Configuration) (int, interface{}, error) {
+			return s.list(cfg, r)
+		}
+	case len(parts) == 3 && r.Method == http.MethodPost:
+		op = func(cfg *action.Configuration) (int, interface{}, error) {
+			return s.install(cfg, namespace, r)
+		}
+	case len(parts) == 4 && r.Method == http.MethodGet:
+		op = func(cfg *action.Configuration) (int, interface{}, error) {
+			rel, err := action.NewStatus(cfg).Run(name)
+			return http.StatusOK, rel, err
+		}
+	case len(parts) == 4 && r.Method == http.MethodPut:
+		op = func(cfg *action.Configuration) (int, interface{}, error) {
+			return s.upgrade(cfg, namespace, name, r)
+		}
+	case len(parts) == 5 && r.Method == http.MethodPost:
+		op = func(cfg *action.Configuration) (int, interface{}, error) {
+			return s.rollback(cfg, name, r)
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	cfg, err := s.NewConfiguration(s.restClientGetter(namespace, user), namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	code, v, err := op(cfg)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	writeJSON(w, code, v)
+}
+
+// restClientGetter returns a client getter for the namespace of a request,
+// impersonating its user.
+func (s *Server) restClientGetter(namespace string, user *User) genericclioptions.RESTClientGetter {
+	impersonate, groups := user.Name, append([]string{}, user.Groups...)
+	settings := *s.Settings
+	return &genericclioptions.ConfigFlags{
+		Namespace:        &namespace,
+		Context:          &settings.KubeContext,
+		BearerToken:      &settings.KubeToken,
+		APIServer:        &settings.KubeAPIServer,
+		CAFile:           &settings.KubeCaFile,
+		KubeConfig:       &settings.KubeConfig,
+		Impersonate:      &impersonate,
+		ImpersonateGroup: &groups,
+	}
+}
+
+func (s *Server) list(cfg *action.Configuration, r *http.Request) (int, interface{}, error) {
+	client := action.NewList(cfg)
+	client.All = r.URL.Query().Get("all") == "true"
+	client.Filter = r.URL.Query().Get("filter")
+	client.SetStateMask()
+	releases, err := client.Run()
+	if err != nil {
+		return 0, nil, err
+	}
+	summaries := make([]ReleaseSummary, 0, len(releases))
+	for _, rel := range releases {
+		summaries = append(summaries, summarize(rel))
+	}
+	return http.StatusOK, summaries, nil
+}
+
+func (s *Server) install(cfg *action.Configuration, namespace string, r *http.Request) (int, interface{}, error) {
+	var req InstallRequest
+	if err := decode(r, &req); err != nil {
+		return 0, nil, err
+	}
+	if req.Name == "" || req.Chart == "" {
+		return 0, nil, badRequest(errors.New("the name of the release and the chart are required"))
+	}
+	timeout, err := parseTimeout(req.Timeout)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	client := action.NewInstall(cfg)
+	client.Namespace = namespace
+	client.ReleaseName = req.Name
+	client.Version = req.Version
+	client.CreateNamespace = req.CreateNamespace
+	client.Wait = req.Wait
+	client.Atomic = req.Atomic
+	client.Timeout = timeout
+	chrt, err := s.loadChart(&client.ChartPathOptions, req.Chart)
+	if err != nil {
+		return 0, nil, err
+	}
+	rel, err := client.Run(chrt, req.Values)
+	return http.StatusCreated, rel, err
+}
+
+func (s *Server) upgrade(cfg *action.Configuration, namespace, name string, r *http.Request) (int, interface{}, error) {
+	var req UpgradeRequest
+	if err := decode(r, &req); err != nil {
+		return 0, nil, err
+	}
+	if req.Chart == "" {
+		return 0, nil, badRequest(errors.New("the chart is required"))
+	}
+	timeout, err := parseTimeout(req.Timeout)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	client := action.NewUpgrade(cfg)
+	client.Namespace = namespace
+	client.Version = req.Version
+	client.ReuseValues = req.ReuseValues
+	client.ResetValues = req.ResetValues
+	client.Wait = req.Wait
+	client.Atomic = req.Atomic
+	client.Timeout = timeout
+	chrt, err := s.loadChart(&client.ChartPathOptions, req.Chart)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if req.Install {
+		// Install the release if it has no history, as 'helm upgrade --install' does
+		if _, err := cfg.Releases.History(name); errors.Is(err, driver.ErrReleaseNotFound) {
+			install := action.NewInstall(cfg)
+			install.Namespace = namespace
+			install.ReleaseName = name
+			install.Wait = req.Wait
+			install.Atomic = req.Atomic
+			install.Timeout = timeout
+			rel, err := install.Run(chrt, req.Values)
+			return http.StatusCreated, rel, err
+		}
+	}
+	rel, err := client.Run(name, chrt, req.Values)
+	return http.StatusOK, rel, err
+}
+
+func (s *Server) rollback(cfg *action.Configuration, name string, r *http.Request) (int, interface{}, error) {
+	var req RollbackRequest
+	if err := decode(r, &req); err != nil {
+		return 0, nil, err
+	}
+	timeout, err := parseTimeout(req.Timeout)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	client := action.NewRollback(cfg)
+	client.Version = req.Revision
+	client.Wait = req.Wait
+	client.Timeout = timeout
+	if err := client.Run(name); err != nil {
+		return 0, nil, err
+	}
+	rel, err := action.NewStatus(cfg).Run(name)
+	return http.StatusOK, rel, err
+}
+
+// loadChart locates a chart as the install command does, and loads it. Only
+// the references allowed by chartReference are located.
+func (s *Server) loadChart(opts *action.ChartPathOptions, ref string) (*chart.Chart, error) {
+	ref, err := s.chartReference(ref)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	path, err := opts.LocateChart(ref, s.Settings)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	chrt, err := loader.Load(path)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	if req := chrt.Metadata.Dependencies; req != nil {
+		if err := action.CheckDependencies(chrt, req); err != nil {
+			return nil, badRequest(err)
+		}
+	}
+	return chrt, nil
+}
+
+// chartReference checks that a chart reference of a request is an oci://
+// reference, the path of a chart in the chart root, or repo/chart, and
+// returns the reference to locate the chart with.
+func (s *Server) chartReference(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if registry.IsOCI(ref) {
+		return ref, nil
+	}
+	if strings.Contains(ref, "://") {
+		return "", errors.New("charts may not be referenced by URL")
+	}
+	if filepath.IsAbs(ref) || strings.HasPrefix(ref, ".") {
+		return "", errors.New("charts may not be referenced by local path")
+	}
+
+	if s.ChartRoot != "" {
+		path, err := chartRootPath(s.ChartRoot, ref)
+		if err != nil {
+			return "", err
+		}
+		if path != "" {
+			return path, nil
+		}
+	}
+
+	// The chart is located as a local path if one exists
+	if _, err := os.Stat(ref); err == nil {
+		return "", errors.New("charts may not be referenced by local path")
+	}
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", errors.New("charts must be referenced as repo/chart or oci://")
+	}
+	return ref, nil
+}
+
+// chartRootPath returns the path of the chart ref relative to root, or ""
+// if there is none. It fails if the path resolves out of root, such as
+// through a symbolic link.
+func chartRootPath(root, ref string) (string, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", err
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(root, filepath.FromSlash(ref)))
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(root, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New("charts may not be referenced out of the chart root")
+	}
+	return path, nil
+}
+
+func (s *Server) log(format string, v ...interface{}) {
+	if s.Log != nil {
+		s.Log(format, v...)
+	}
+}
+
+// InstallRequest is the body of a request installing a release.
+type InstallRequest struct {
+	// Name is the name of the release.
+	Name string `json:"name"`
+	// Chart is the chart reference: repo/chart, an oci:// reference, or
+	// the path of a chart relative to the chart root of the server.
+	Chart string `json:"chart"`
+	// Version is the constraint on the version of the chart.
+	Version         string                 `json:"version,omitempty"`
+	Values          map[string]interface{} `json:"values,omitempty"`
+	CreateNamespace bool                   `json:"createNamespace,omitempty"`
+	Wait            bool                   `json:"wait,omitempty"`
+	Atomic          bool                   `json:"atomic,omitempty"`
+	// Timeout is a duration such as 5m, which defaults to 5 minutes.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// UpgradeRequest is the body of a request upgrading a release.
+type UpgradeRequest struct {
+	Chart       string                 `json:"chart"`
+	Version     string                 `json:"version,omitempty"`
+	Values      map[string]interface{} `json:"values,omitempty"`
+	ReuseValues bool                   `json:"reuseValues,omitempty"`
+	ResetValues bool                   `json:"resetValues,omitempty"`
+	// Install installs the release if it does not exist yet.
+	Install bool   `json:"install,omitempty"`
+	Wait    bool   `json:"wait,omitempty"`
+	Atomic  bool   `json:"atomic,omitempty"`
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// RollbackRequest is the body of a request rolling back a release.
+type RollbackRequest struct {
+	// Revision is the revision to roll back to. Zero is the previous one.
+	Revision int    `json:"revision,omitempty"`
+	Wait     bool   `json:"wait,omitempty"`
+	Timeout  string `json:"timeout,omitempty"`
+}
+
+// ReleaseSummary describes a release in a list.
+type ReleaseSummary struct {
+	Name       string         `json:"name"`
+	Namespace  string         `json:"namespace"`
+	Revision   int            `json:"revision"`
+	Updated    time.Time      `json:"updated"`
+	Status     release.Status `json:"status"`
+	Chart      string         `json:"chart"`
+	AppVersion string         `json:"appVersion"`
+}
+
+func summarize(rel *release.Release) ReleaseSummary {
+	s := ReleaseSummary{
+		Name:      rel.Name,
+		Namespace: rel.Namespace,
+		Revision:  rel.Version,
+	}
+	if rel.Info != nil {
+		s.Updated = rel.Info.LastDeployed.Time
+		s.Status = rel.Info.Status
+	}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		s.Chart = rel.Chart.Metadata.Name + "-" + rel.Chart.Metadata.Version
+		s.AppVersion = rel.Chart.Metadata.AppVersion
+	}
+	return s
+}
+
+// requestError is an error of the request itself.
+type requestError struct {
+	error
+}
+
+func badRequest(err error) error {
+	return requestError{err}
+}
+
+// errorStatus returns the HTTP status of an error.
+func errorStatus(err error) int {
+	var reqErr requestError
+	switch {
+	case errors.As(err, &reqErr):
+		return http.StatusBadRequest
+	case errors.Is(err, driver.ErrReleaseNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func decode(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxRequestSize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest(err)
+	}
+	return nil
+}
+
+func parseTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return defaultTimeout, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, badRequest(err)
+	}
+	return d, nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
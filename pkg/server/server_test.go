@@ -0,0 +1,253 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, *genericclioptions.ConfigFlags) {
+	t.Helper()
+	tokens, err := LoadTokens("testdata/tokens.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &action.Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   &kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          func(format string, v ...interface{}) {},
+	}
+	flags := &genericclioptions.ConfigFlags{}
+	s := &Server{
+		Settings:  cli.New(),
+		Tokens:    tokens,
+		ChartRoot: "testdata",
+		NewConfiguration: func(getter genericclioptions.RESTClientGetter, namespace string) (*action.Configuration, error) {
+			*flags = *getter.(*genericclioptions.ConfigFlags)
+			return cfg, nil
+		},
+	}
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts, flags
+}
+
+func do(t *testing.T, ts *httptest.Server, method, path, token string, body interface{}, out interface{}) int {
+	t.Helper()
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestServerAuthentication(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	for _, token := range []string{"", "wrong-token"} {
+		var body map[string]string
+		if code := do(t, ts, http.MethodGet, "/v1/namespaces/default/releases", token, nil, &body); code != http.StatusUnauthorized {
+			t.Errorf("token %q: expected status %d, got %d", token, http.StatusUnauthorized, code)
+		}
+		if body["error"] == "" {
+			t.Errorf("token %q: expected an error", token)
+		}
+	}
+	if code := do(t, ts, http.MethodGet, "/healthz", "", nil, nil); code != http.StatusOK {
+		t.Errorf("expected the health check not to need a token, got status %d", code)
+	}
+}
+
+func TestServerImpersonation(t *testing.T) {
+	ts, flags := newTestServer(t)
+
+	if code := do(t, ts, http.MethodGet, "/v1/namespaces/team-a/releases", "ci-token", nil, nil); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	if *flags.Impersonate != "ci" {
+		t.Errorf("expected to impersonate ci, got %q", *flags.Impersonate)
+	}
+	if groups := *flags.ImpersonateGroup; len(groups) != 1 || groups[0] != "platform" {
+		t.Errorf("expected to impersonate the platform group, got %v", groups)
+	}
+	if *flags.Namespace != "team-a" {
+		t.Errorf("expected the namespace team-a, got %q", *flags.Namespace)
+	}
+}
+
+func TestServerReleaseLifecycle(t *testing.T) {
+	ts, _ := newTestServer(t)
+	const releases = "/v1/namespaces/default/releases"
+
+	var installed release.Release
+	code := do(t, ts, http.MethodPost, releases, "dev-token", InstallRequest{Name: "web", Chart: "chart"}, &installed)
+	if code != http.StatusCreated {
+		t.Fatalf("install: expected status %d, got %d", http.StatusCreated, code)
+	}
+	if installed.Version != 1 || installed.Info.Status != release.StatusDeployed {
+		t.Errorf("install: expected revision 1 deployed, got revision %d %s", installed.Version, installed.Info.Status)
+	}
+
+	var upgraded release.Release
+	code = do(t, ts, http.MethodPut, releases+"/web", "dev-token", UpgradeRequest{
+		Chart:  "chart",
+		Values: map[string]interface{}{"greeting": "hi"},
+	}, &upgraded)
+	if code != http.StatusOK {
+		t.Fatalf("upgrade: expected status %d, got %d", http.StatusOK, code)
+	}
+	if upgraded.Version != 2 || upgraded.Config["greeting"] != "hi" {
+		t.Errorf("upgrade: expected revision 2 with the new values, got revision %d with %v", upgraded.Version, upgraded.Config)
+	}
+
+	var rolledBack release.Release
+	code = do(t, ts, http.MethodPost, releases+"/web/rollback", "dev-token", RollbackRequest{Revision: 1}, &rolledBack)
+	if code != http.StatusOK {
+		t.Fatalf("rollback: expected status %d, got %d", http.StatusOK, code)
+	}
+	if rolledBack.Version != 3 || len(rolledBack.Config) != 0 {
+		t.Errorf("rollback: expected revision 3 with the values of revision 1, got revision %d with %v", rolledBack.Version, rolledBack.Config)
+	}
+
+	var list []ReleaseSummary
+	if code := do(t, ts, http.MethodGet, releases, "dev-token", nil, &list); code != http.StatusOK {
+		t.Fatalf("list: expected status %d, got %d", http.StatusOK, code)
+	}
+	if len(list) != 1 || list[0].Name != "web" || list[0].Revision != 3 || list[0].Chart != "chart-0.1.0" {
+		t.Errorf("list: unexpected releases %+v", list)
+	}
+
+	if code := do(t, ts, http.MethodGet, releases+"/web", "dev-token", nil, nil); code != http.StatusOK {
+		t.Errorf("status: expected status %d, got %d", http.StatusOK, code)
+	}
+	if code := do(t, ts, http.MethodGet, releases+"/missing", "dev-token", nil, nil); code != http.StatusNotFound {
+		t.Errorf("status of a missing release: expected status %d, got %d", http.StatusNotFound, code)
+	}
+}
+
+func TestServerBadRequests(t *testing.T) {
+	ts, _ := newTestServer(t)
+	const releases = "/v1/namespaces/default/releases"
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		want   int
+	}{
+		{"missing chart", http.MethodPost, releases, InstallRequest{Name: "web"}, http.StatusBadRequest},
+		{"unknown field", http.MethodPost, releases, map[string]string{"name": "web", "chart": "chart", "colour": "blue"}, http.StatusBadRequest},
+		{"invalid timeout", http.MethodPost, releases, InstallRequest{Name: "web", Chart: "chart", Timeout: "soon"}, http.StatusBadRequest},
+		{"missing chart of the chart root", http.MethodPost, releases, InstallRequest{Name: "web", Chart: "missing"}, http.StatusBadRequest},
+		{"repo URL", http.MethodPost, releases, map[string]string{"name": "web", "chart": "chart", "repo": "https://example.com/charts"}, http.StatusBadRequest},
+		{"unknown path", http.MethodGet, "/v1/namespaces/default/secrets", nil, http.StatusNotFound},
+		{"unsupported method", http.MethodDelete, releases + "/web", nil, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		if code := do(t, ts, tt.method, tt.path, "dev-token", tt.body, nil); code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, code)
+		}
+	}
+}
+
+func TestServerChartReference(t *testing.T) {
+	s := &Server{ChartRoot: "testdata"}
+	for _, ref := range []string{"stable/nginx", "oci://registry.example.com/charts/nginx"} {
+		if got, err := s.chartReference(ref); err != nil || got != ref {
+			t.Errorf("%s: expected the reference to be allowed, got %q, %v", ref, got, err)
+		}
+	}
+	want, err := filepath.Abs(filepath.Join("testdata", "chart"))
+	if err == nil {
+		want, err = filepath.EvalSymlinks(want)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.chartReference("chart"); err != nil || got != want {
+		t.Errorf("expected the chart of the chart root %q, got %q, %v", want, got, err)
+	}
+
+	refused := []string{
+		"/etc/passwd",
+		"./testdata/chart",
+		"../server/testdata/chart",
+		"chart/../../server_test.go",
+		"testdata/chart",
+		"https://example.com/charts/nginx-1.0.0.tgz",
+		"file:///etc/passwd",
+		"nginx",
+		"stable/nginx/extra",
+	}
+	for _, ref := range refused {
+		if _, err := s.chartReference(ref); err == nil {
+			t.Errorf("%s: expected the reference to be refused", ref)
+		}
+	}
+
+	s.ChartRoot = ""
+	if _, err := s.chartReference("chart"); err == nil {
+		t.Error("expected local charts to be refused without a chart root")
+	}
+}
+
+func TestReadTokens(t *testing.T) {
+	if _, err := ReadTokens([]byte("tokens:\n  - token: abc\n")); err == nil {
+		t.Error("expected an error for a token without a user")
+	}
+	if _, err := ReadTokens([]byte("users: []\n")); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
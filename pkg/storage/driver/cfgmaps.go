@@ -59,6 +59,11 @@ func (cfgmaps *ConfigMaps) Name() string {
 	return ConfigMapsDriverName
 }
 
+// MaxRecordSize returns the size limit of the records.
+func (cfgmaps *ConfigMaps) MaxRecordSize() int {
+	return MaxRecordSize
+}
+
 // Get fetches the release named by key. The corresponding release is returned
 // or error if not found.
 func (cfgmaps *ConfigMaps) Get(key string) (*rspb.Release, error) {
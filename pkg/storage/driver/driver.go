@@ -103,3 +103,29 @@ type Driver interface {
 	Queryor
 	Name() string
 }
+
+// MaxRecordSize is the size limit of the release records stored in Secrets
+// and ConfigMaps, the maximum size of their data.
+const MaxRecordSize = 1 << 20
+
+// SizeLimiter is implemented by the drivers whose records have a maximum
+// size.
+type SizeLimiter interface {
+	MaxRecordSize() int
+}
+
+// Pinger is implemented by the drivers connecting to a remote store, to check
+// that it can be reached.
+type Pinger interface {
+	Ping() error
+}
+
+// EncodedSize returns the size of the release once encoded as the drivers
+// store it.
+func EncodedSize(rls *rspb.Release) (int, error) {
+	data, err := encodeRelease(rls)
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
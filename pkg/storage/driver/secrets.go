@@ -59,6 +59,11 @@ func (secrets *Secrets) Name() string {
 	return SecretsDriverName
 }
 
+// MaxRecordSize returns the size limit of the records.
+func (secrets *Secrets) MaxRecordSize() int {
+	return MaxRecordSize
+}
+
 // Get fetches the release named by key. The corresponding release is returned
 // or error if not found.
 func (secrets *Secrets) Get(key string) (*rspb.Release, error) {
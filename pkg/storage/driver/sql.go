@@ -82,6 +82,11 @@ func (s *SQL) Name() string {
 	return SQLDriverName
 }
 
+// Ping checks that the database can be reached.
+func (s *SQL) Ping() error {
+	return s.db.Ping()
+}
+
 func (s *SQL) ensureDBSetup() error {
 	// Populate the database with the relations we need if they don't exist yet
 	migrations := &migrate.MemoryMigrationSource{
@@ -43,6 +43,13 @@ type Storage struct {
 	// ignored (meaning no limits are imposed).
 	MaxHistory int
 
+	// DeltaValues stores the large values of a revision as the changes from
+	// those of the previous revision, when the changes are smaller. The
+	// values are restored when releases are read, whatever the setting.
+	// Versions of Helm that predate it read such values as empty, so it is
+	// opt-in.
+	DeltaValues bool
+
 	Log func(string, ...interface{})
 }
 
@@ -51,7 +58,11 @@ type Storage struct {
 // release identified by the key, version pair does not exist.
 func (s *Storage) Get(name string, version int) (*rspb.Release, error) {
 	s.Log("getting release %q", makeKey(name, version))
-	return s.Driver.Get(makeKey(name, version))
+	rls, err := s.Driver.Get(makeKey(name, version))
+	if err != nil {
+		return nil, err
+	}
+	return s.restoreValues(rls, nil)
 }
 
 // Create creates a new storage entry holding the release. An
@@ -63,7 +74,7 @@ func (s *Storage) Create(rls *rspb.Release) error {
 		// Want to make space for one more release.
 		s.removeLeastRecent(rls.Name, s.MaxHistory-1)
 	}
-	return s.Driver.Create(makeKey(rls.Name, rls.Version), rls)
+	return s.Driver.Create(makeKey(rls.Name, rls.Version), s.encodeValues(rls))
 }
 
 // Update updates the release in storage. An error is returned if the
@@ -71,7 +82,7 @@ func (s *Storage) Create(rls *rspb.Release) error {
 // does not exist.
 func (s *Storage) Update(rls *rspb.Release) error {
 	s.Log("updating release %q", makeKey(rls.Name, rls.Version))
-	return s.Driver.Update(makeKey(rls.Name, rls.Version), rls)
+	return s.Driver.Update(makeKey(rls.Name, rls.Version), s.encodeValues(rls))
 }
 
 // Delete deletes the release from storage. An error is returned if
@@ -79,21 +90,51 @@ func (s *Storage) Update(rls *rspb.Release) error {
 // does not exist.
 func (s *Storage) Delete(name string, version int) (*rspb.Release, error) {
 	s.Log("deleting release %q", makeKey(name, version))
-	return s.Driver.Delete(makeKey(name, version))
+	rls, err := s.Get(name, version)
+	if err != nil {
+		return nil, err
+	}
+	// The next revision may store its values as changes from this one
+	if err := s.rebaseValues(name, version); err != nil {
+		return nil, err
+	}
+	if _, err := s.Driver.Delete(makeKey(name, version)); err != nil {
+		return nil, err
+	}
+	return rls, nil
+}
+
+// List returns the releases satisfying the filter, with their values
+// restored. The filter is given the releases as they are stored.
+func (s *Storage) List(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
+	ls, err := s.Driver.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.restoreAllValues(ls)
+}
+
+// Query returns the releases matching the labels, with their values restored.
+func (s *Storage) Query(labels map[string]string) ([]*rspb.Release, error) {
+	ls, err := s.Driver.Query(labels)
+	if err != nil {
+		return nil, err
+	}
+	return s.restoreAllValues(ls)
 }
 
 // ListReleases returns all releases from storage. An error is returned if the
 // storage backend fails to retrieve the releases.
 func (s *Storage) ListReleases() ([]*rspb.Release, error) {
 	s.Log("listing all releases in storage")
-	return s.Driver.List(func(_ *rspb.Release) bool { return true })
+	return s.List(func(_ *rspb.Release) bool { return true })
 }
 
 // ListUninstalled returns all releases with Status == UNINSTALLED. An error is returned
 // if the storage backend fails to retrieve the releases.
 func (s *Storage) ListUninstalled() ([]*rspb.Release, error) {
 	s.Log("listing uninstalled releases in storage")
-	return s.Driver.List(func(rls *rspb.Release) bool {
+	return s.List(func(rls *rspb.Release) bool {
 		return relutil.StatusFilter(rspb.StatusUninstalled).Check(rls)
 	})
 }
@@ -102,7 +143,7 @@ func (s *Storage) ListUninstalled() ([]*rspb.Release, error) {
 // if the storage backend fails to retrieve the releases.
 func (s *Storage) ListDeployed() ([]*rspb.Release, error) {
 	s.Log("listing all deployed releases in storage")
-	return s.Driver.List(func(rls *rspb.Release) bool {
+	return s.List(func(rls *rspb.Release) bool {
 		return relutil.StatusFilter(rspb.StatusDeployed).Check(rls)
 	})
 }
@@ -131,7 +172,7 @@ func (s *Storage) Deployed(name string) (*rspb.Release, error) {
 func (s *Storage) DeployedAll(name string) ([]*rspb.Release, error) {
 	s.Log("getting deployed releases from %q history", name)
 
-	ls, err := s.Driver.Query(map[string]string{
+	ls, err := s.Query(map[string]string{
 		"name":   name,
 		"owner":  "helm",
 		"status": "deployed",
@@ -150,7 +191,7 @@ func (s *Storage) DeployedAll(name string) ([]*rspb.Release, error) {
 func (s *Storage) History(name string) ([]*rspb.Release, error) {
 	s.Log("getting release history for %q", name)
 
-	return s.Driver.Query(map[string]string{"name": name, "owner": "helm"})
+	return s.Query(map[string]string{"name": name, "owner": "helm"})
 }
 
 // removeLeastRecent removes items from history until the lengh number of releases
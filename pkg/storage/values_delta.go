@@ -0,0 +1,223 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "helm.sh/helm/v3/pkg/storage"
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	rspb "helm.sh/helm/v3/pkg/release"
+)
+
+// deltaValuesMinSize is the size of the encoded values, in bytes, below which
+// values are always stored in full.
+const deltaValuesMinSize = 4096
+
+// maxValuesDeltaDepth is the largest number of deltas applied to restore the
+// values of a revision. The values of the next revision are stored in full.
+const maxValuesDeltaDepth = 8
+
+// encodeValues returns the release to store for rls: rls itself, or a copy
+// whose values are encoded as the changes from those of the previous
+// revision, when DeltaValues is set and the changes are smaller.
+func (s *Storage) encodeValues(rls *rspb.Release) *rspb.Release {
+	if !s.DeltaValues || rls.Version <= 1 || rls.ValuesDelta != nil {
+		return rls
+	}
+	full, err := json.Marshal(rls.Config)
+	if err != nil || len(full) < deltaValuesMinSize {
+		return rls
+	}
+
+	prev, err := s.Driver.Get(makeKey(rls.Name, rls.Version-1))
+	if err != nil {
+		return rls
+	}
+	depth := 1
+	if prev.ValuesDelta != nil {
+		depth = prev.ValuesDelta.Depth + 1
+	}
+	if depth > maxValuesDeltaDepth {
+		return rls
+	}
+	if prev, err = s.restoreValues(prev, nil); err != nil {
+		s.Log("storing the values of %q in full: %s", makeKey(rls.Name, rls.Version), err)
+		return rls
+	}
+
+	// Compare the values as they are decoded, so that numbers match
+	var vals map[string]interface{}
+	if err := json.Unmarshal(full, &vals); err != nil {
+		return rls
+	}
+	changes := diffValues(prev.Config, vals, nil)
+	delta, err := json.Marshal(changes)
+	if err != nil || len(delta) >= len(full) {
+		return rls
+	}
+
+	stored := *rls
+	stored.Config = nil
+	stored.ValuesDelta = &rspb.ValuesDelta{Base: rls.Version - 1, Depth: depth, Changes: changes}
+	return &stored
+}
+
+// restoreValues returns rls, or a copy of it with the values restored if they
+// are stored as changes from those of another revision. The releases already
+// read are looked up in known, by namespace and key, before the driver.
+func (s *Storage) restoreValues(rls *rspb.Release, known map[string]*rspb.Release) (*rspb.Release, error) {
+	if rls.ValuesDelta == nil {
+		return rls, nil
+	}
+	key := makeKey(rls.Name, rls.ValuesDelta.Base)
+	base, ok := known[knownKey(rls.Namespace, key)]
+	if !ok {
+		var err error
+		if base, err = s.Driver.Get(key); err != nil {
+			return nil, errors.Wrapf(err, "unable to restore the values of %q", makeKey(rls.Name, rls.Version))
+		}
+	}
+	base, err := s.restoreValues(base, known)
+	if err != nil {
+		return nil, err
+	}
+	vals, err := applyValuesChanges(base.Config, rls.ValuesDelta.Changes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to restore the values of %q", makeKey(rls.Name, rls.Version))
+	}
+
+	restored := *rls
+	restored.Config = vals
+	restored.ValuesDelta = nil
+	if known != nil {
+		known[knownKey(restored.Namespace, makeKey(restored.Name, restored.Version))] = &restored
+	}
+	return &restored, nil
+}
+
+// restoreAllValues restores the values of the given releases, reusing them as
+// the bases of one another.
+func (s *Storage) restoreAllValues(rels []*rspb.Release) ([]*rspb.Release, error) {
+	known := make(map[string]*rspb.Release, len(rels))
+	for _, rls := range rels {
+		known[knownKey(rls.Namespace, makeKey(rls.Name, rls.Version))] = rls
+	}
+	restored := make([]*rspb.Release, len(rels))
+	for i, rls := range rels {
+		r, err := s.restoreValues(rls, known)
+		if err != nil {
+			return nil, err
+		}
+		restored[i] = r
+	}
+	return restored, nil
+}
+
+// rebaseValues stores in full the values of the revision following the given
+// one if they are stored as changes from it, before it is deleted.
+func (s *Storage) rebaseValues(name string, version int) error {
+	key := makeKey(name, version+1)
+	next, err := s.Driver.Get(key)
+	if err != nil || next.ValuesDelta == nil || next.ValuesDelta.Base != version {
+		return nil
+	}
+	restored, err := s.restoreValues(next, nil)
+	if err != nil {
+		return err
+	}
+	return s.Driver.Update(key, restored)
+}
+
+func knownKey(namespace, key string) string {
+	return namespace + "/" + key
+}
+
+// diffValues returns the changes turning the values base into target.
+func diffValues(base, target map[string]interface{}, path []string) []rspb.ValuesChange {
+	var changes []rspb.ValuesChange
+	for _, k := range sortedKeys(base) {
+		if _, ok := target[k]; !ok {
+			changes = append(changes, rspb.ValuesChange{Path: appendPath(path, k), Remove: true})
+		}
+	}
+	for _, k := range sortedKeys(target) {
+		bv, ok := base[k]
+		tv := target[k]
+		bm, bIsMap := bv.(map[string]interface{})
+		tm, tIsMap := tv.(map[string]interface{})
+		switch {
+		case ok && bIsMap && tIsMap:
+			changes = append(changes, diffValues(bm, tm, appendPath(path, k))...)
+		case !ok || !reflect.DeepEqual(bv, tv):
+			changes = append(changes, rspb.ValuesChange{Path: appendPath(path, k), Value: tv})
+		}
+	}
+	return changes
+}
+
+// applyValuesChanges returns a copy of the values base with the changes
+// applied.
+func applyValuesChanges(base map[string]interface{}, changes []rspb.ValuesChange) (map[string]interface{}, error) {
+	vals := map[string]interface{}{}
+	if base != nil {
+		// Copy the values, as the base release may be cached by the driver
+		data, err := json.Marshal(base)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &vals); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range changes {
+		if len(c.Path) == 0 {
+			return nil, errors.New("invalid values change without a path")
+		}
+		m := vals
+		for i, k := range c.Path[:len(c.Path)-1] {
+			next, ok := m[k].(map[string]interface{})
+			if !ok {
+				return nil, errors.Errorf("invalid values change: %s is not a map", strings.Join(c.Path[:i+1], "."))
+			}
+			m = next
+		}
+		last := c.Path[len(c.Path)-1]
+		if c.Remove {
+			delete(m, last)
+		} else {
+			m[last] = c.Value
+		}
+	}
+	return vals, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func appendPath(path []string, key string) []string {
+	return append(append([]string{}, path...), key)
+}
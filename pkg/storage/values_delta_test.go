@@ -0,0 +1,121 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "helm.sh/helm/v3/pkg/storage"
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	rspb "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func TestDiffValues(t *testing.T) {
+	base := map[string]interface{}{
+		"tls":      map[string]interface{}{"cert": "CERT", "key": "KEY"},
+		"replicas": 1.0,
+		"legacy":   true,
+	}
+	target := map[string]interface{}{
+		"tls":      map[string]interface{}{"cert": "CERT", "key": "NEWKEY"},
+		"replicas": 1.0,
+		"image":    map[string]interface{}{"tag": nil},
+	}
+
+	changes := diffValues(base, target, nil)
+	expected := []rspb.ValuesChange{
+		{Path: []string{"legacy"}, Remove: true},
+		{Path: []string{"image"}, Value: map[string]interface{}{"tag": nil}},
+		{Path: []string{"tls", "key"}, Value: "NEWKEY"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("Expected changes %v, got %v", expected, changes)
+	}
+
+	vals, err := applyValuesChanges(base, changes)
+	assertErrNil(t.Fatal, err, "applyValuesChanges")
+	if !reflect.DeepEqual(vals, target) {
+		t.Errorf("Expected values %v, got %v", target, vals)
+	}
+	if base["tls"].(map[string]interface{})["key"] != "KEY" {
+		t.Error("Expected the base values to be left alone")
+	}
+}
+
+func TestStorageDeltaValues(t *testing.T) {
+	storage := Init(driver.NewMemory())
+	storage.DeltaValues = true
+
+	cert := strings.Repeat("A", 2*deltaValuesMinSize)
+	for v := 1; v <= 3; v++ {
+		rls := ReleaseTestData{Name: "angry-beaver", Version: v, Status: rspb.StatusSuperseded}.ToRelease()
+		rls.Config = map[string]interface{}{"tls": map[string]interface{}{"cert": cert}, "replicas": float64(v)}
+		assertErrNil(t.Fatal, storage.Create(rls), "StoreRelease")
+	}
+
+	// Only the first revision stores the values in full
+	raw, err := storage.Driver.Get(makeKey("angry-beaver", 3))
+	assertErrNil(t.Fatal, err, "GetStoredRelease")
+	if raw.Config != nil || raw.ValuesDelta == nil || raw.ValuesDelta.Base != 2 || raw.ValuesDelta.Depth != 2 {
+		t.Fatalf("Expected revision 3 to store its values as changes from revision 2, got %+v", raw.ValuesDelta)
+	}
+
+	rls, err := storage.Get("angry-beaver", 3)
+	assertErrNil(t.Fatal, err, "QueryRelease")
+	if rls.ValuesDelta != nil || rls.Config["replicas"] != 3.0 || rls.Config["tls"].(map[string]interface{})["cert"] != cert {
+		t.Errorf("Expected the values of revision 3 to be restored, got %v", rls.Config)
+	}
+
+	h, err := storage.History("angry-beaver")
+	assertErrNil(t.Fatal, err, "History")
+	for _, rls := range h {
+		if rls.Config["replicas"] != float64(rls.Version) {
+			t.Errorf("Expected the values of revision %d to be restored, got %v", rls.Version, rls.Config)
+		}
+	}
+
+	// Deleting a revision stores the values of the next one in full
+	_, err = storage.Delete("angry-beaver", 1)
+	assertErrNil(t.Fatal, err, "DeleteRelease")
+	raw, err = storage.Driver.Get(makeKey("angry-beaver", 2))
+	assertErrNil(t.Fatal, err, "GetStoredRelease")
+	if raw.ValuesDelta != nil || raw.Config["replicas"] != 2.0 {
+		t.Errorf("Expected revision 2 to store its values in full, got %v", raw.Config)
+	}
+	rls, err = storage.Get("angry-beaver", 3)
+	assertErrNil(t.Fatal, err, "QueryRelease")
+	if rls.Config["replicas"] != 3.0 {
+		t.Errorf("Expected the values of revision 3 to be restored, got %v", rls.Config)
+	}
+}
+
+func TestStorageDeltaValuesSmall(t *testing.T) {
+	storage := Init(driver.NewMemory())
+	storage.DeltaValues = true
+
+	for v := 1; v <= 2; v++ {
+		rls := ReleaseTestData{Name: "angry-beaver", Version: v}.ToRelease()
+		rls.Config = map[string]interface{}{"replicas": v}
+		assertErrNil(t.Fatal, storage.Create(rls), "StoreRelease")
+	}
+	raw, err := storage.Driver.Get(makeKey("angry-beaver", 2))
+	assertErrNil(t.Fatal, err, "GetStoredRelease")
+	if raw.ValuesDelta != nil {
+		t.Error("Expected small values to be stored in full")
+	}
+}
@@ -17,10 +17,13 @@ package strvals
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
@@ -29,6 +32,24 @@ import (
 // ErrNotList indicates that a non-list was treated as a list.
 var ErrNotList = errors.New("not a list")
 
+// ParseError is returned for a line the parser fails on. It tells where in the
+// line parsing stopped.
+type ParseError struct {
+	// Line is the line being parsed.
+	Line string
+	// Pos is the offset, in characters, of the line at which parsing stopped.
+	Pos int
+	// Err is the reason parsing failed.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s (at position %d of %q)", e.Err, e.Pos, e.Line)
+}
+
+// Unwrap returns the reason parsing failed.
+func (e *ParseError) Unwrap() error { return e.Err }
+
 // ToYAML takes a string of arguments and converts to a YAML document.
 func ToYAML(s string) (string, error) {
 	m, err := Parse(s)
@@ -46,7 +67,7 @@ func Parse(s string) (map[string]interface{}, error) {
 	vals := map[string]interface{}{}
 	scanner := bytes.NewBufferString(s)
 	t := newParser(scanner, vals, false)
-	err := t.parse()
+	err := t.run(s)
 	return vals, err
 }
 
@@ -57,7 +78,7 @@ func ParseString(s string) (map[string]interface{}, error) {
 	vals := map[string]interface{}{}
 	scanner := bytes.NewBufferString(s)
 	t := newParser(scanner, vals, true)
-	err := t.parse()
+	err := t.run(s)
 	return vals, err
 }
 
@@ -68,7 +89,7 @@ func ParseString(s string) (map[string]interface{}, error) {
 func ParseInto(s string, dest map[string]interface{}) error {
 	scanner := bytes.NewBufferString(s)
 	t := newParser(scanner, dest, false)
-	return t.parse()
+	return t.run(s)
 }
 
 // ParseFile parses a set line, but its final value is loaded from the file at the path specified by the original value.
@@ -81,7 +102,7 @@ func ParseFile(s string, reader RunesValueReader) (map[string]interface{}, error
 	vals := map[string]interface{}{}
 	scanner := bytes.NewBufferString(s)
 	t := newFileParser(scanner, vals, reader)
-	err := t.parse()
+	err := t.run(s)
 	return vals, err
 }
 
@@ -91,7 +112,7 @@ func ParseFile(s string, reader RunesValueReader) (map[string]interface{}, error
 func ParseIntoString(s string, dest map[string]interface{}) error {
 	scanner := bytes.NewBufferString(s)
 	t := newParser(scanner, dest, true)
-	return t.parse()
+	return t.run(s)
 }
 
 // ParseIntoFile parses a filevals line and merges the result into dest.
@@ -100,7 +121,41 @@ func ParseIntoString(s string, dest map[string]interface{}) error {
 func ParseIntoFile(s string, dest map[string]interface{}, reader RunesValueReader) error {
 	scanner := bytes.NewBufferString(s)
 	t := newFileParser(scanner, dest, reader)
-	return t.parse()
+	return t.run(s)
+}
+
+// ParseJSON parses a line of keys set to JSON values and deep merges the
+// result into dest.
+//
+// A line is of the form name1={"key": "value"},name2.sub=[1, 2]
+//
+// When a JSON object is set on a key that already holds a map, the object is
+// merged into the map rather than replacing it. Any other value replaces the
+// one set on the key.
+func ParseJSON(s string, dest map[string]interface{}) error {
+	scanner := bytes.NewBufferString(s)
+	t := newJSONParser(scanner, dest)
+	return t.run(s)
+}
+
+// ParseLiteral parses a line setting a single key to a literal string value.
+//
+// A line is of the form name.sub[0]=value, where everything following the first
+// = is the value, commas, braces and backslashes included.
+func ParseLiteral(s string) (map[string]interface{}, error) {
+	vals := map[string]interface{}{}
+	scanner := bytes.NewBufferString(s)
+	t := newLiteralParser(scanner, vals)
+	err := t.run(s)
+	return vals, err
+}
+
+// ParseLiteralInto parses a literal line like ParseLiteral and merges the
+// result into dest.
+func ParseLiteralInto(s string, dest map[string]interface{}) error {
+	scanner := bytes.NewBufferString(s)
+	t := newLiteralParser(scanner, dest)
+	return t.run(s)
 }
 
 // RunesValueReader is a function that takes the given value (a slice of runes)
@@ -116,6 +171,11 @@ type parser struct {
 	sc     *bytes.Buffer
 	data   map[string]interface{}
 	reader RunesValueReader
+
+	// isJSONVal is set when values are JSON documents.
+	isJSONVal bool
+	// isLiteral is set when the value is the rest of the line, taken as is.
+	isLiteral bool
 }
 
 func newParser(sc *bytes.Buffer, data map[string]interface{}, stringBool bool) *parser {
@@ -129,6 +189,28 @@ func newFileParser(sc *bytes.Buffer, data map[string]interface{}, reader RunesVa
 	return &parser{sc: sc, data: data, reader: reader}
 }
 
+func newJSONParser(sc *bytes.Buffer, data map[string]interface{}) *parser {
+	return &parser{sc: sc, data: data, isJSONVal: true}
+}
+
+func newLiteralParser(sc *bytes.Buffer, data map[string]interface{}) *parser {
+	return &parser{sc: sc, data: data, isLiteral: true}
+}
+
+// run parses the line s, returning a ParseError on failure.
+func (t *parser) run(s string) error {
+	err := t.parse()
+	if err == nil {
+		return nil
+	}
+	// The rest of the line is what the parser has not read yet.
+	read := len(s) - t.sc.Len()
+	if read < 0 {
+		read = 0
+	}
+	return &ParseError{Line: s, Pos: utf8.RuneCountInString(s[:read]), Err: err}
+}
+
 func (t *parser) parse() error {
 	for {
 		err := t.key(t.data)
@@ -157,6 +239,10 @@ func (t *parser) key(data map[string]interface{}) (reterr error) {
 		}
 	}()
 	stop := runeSet([]rune{'=', '[', ',', '.'})
+	if t.isLiteral {
+		// Literal lines set a single key, commas are part of the value.
+		stop = runeSet([]rune{'=', '[', '.'})
+	}
 	for {
 		switch k, last, err := runesUntil(t.sc, stop); {
 		case err != nil:
@@ -183,6 +269,16 @@ func (t *parser) key(data map[string]interface{}) (reterr error) {
 			list, err = t.listItem(list, i)
 			set(data, kk, list)
 			return err
+		case last == '=' && t.isLiteral:
+			set(data, string(k), t.literalVal())
+			return nil
+		case last == '=' && t.isJSONVal:
+			v, e := t.jsonVal()
+			if e != nil {
+				return e
+			}
+			set(data, string(k), mergeJSONVal(data[string(k)], v))
+			return nil
 		case last == '=':
 			//End of key. Consume =, Get value.
 			// FIXME: Get value list first
@@ -279,6 +375,18 @@ func (t *parser) listItem(list []interface{}, i int) ([]interface{}, error) {
 		return list, errors.Errorf("unexpected data at end of array index: %q", k)
 	case err != nil:
 		return list, err
+	case last == '=' && t.isLiteral:
+		return setIndex(list, i, t.literalVal())
+	case last == '=' && t.isJSONVal:
+		v, e := t.jsonVal()
+		if e != nil {
+			return list, e
+		}
+		var existing interface{}
+		if len(list) > i {
+			existing = list[i]
+		}
+		return setIndex(list, i, mergeJSONVal(existing, v))
 	case last == '=':
 		vl, e := t.valList()
 		switch e {
@@ -349,6 +457,61 @@ func (t *parser) val() ([]rune, error) {
 	return v, err
 }
 
+// literalVal consumes the rest of the line as a string value.
+func (t *parser) literalVal() string {
+	return string(t.sc.Next(t.sc.Len()))
+}
+
+// jsonVal decodes the JSON value that follows a key, and consumes the comma
+// separating it from the next key if there is one.
+func (t *parser) jsonVal() (interface{}, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(t.sc.Bytes()))
+	if err := dec.Decode(&v); err != nil {
+		// Point at the offending character of the value.
+		if serr, ok := err.(*json.SyntaxError); ok && serr.Offset > 0 {
+			t.sc.Next(int(serr.Offset) - 1)
+		} else if err == io.ErrUnexpectedEOF {
+			t.sc.Next(t.sc.Len())
+		}
+		return nil, errors.Wrap(err, "invalid JSON value")
+	}
+	t.sc.Next(int(dec.InputOffset()))
+
+	for {
+		r, _, e := t.sc.ReadRune()
+		switch {
+		case e != nil:
+			// End of the line
+			return v, nil
+		case r == ',':
+			return v, nil
+		case unicode.IsSpace(r):
+			continue
+		default:
+			t.sc.UnreadRune()
+			return nil, errors.Errorf("unexpected %q after JSON value", r)
+		}
+	}
+}
+
+// mergeJSONVal deep merges a JSON object into the map already set where it
+// goes. Any other value replaces the existing one.
+func mergeJSONVal(dst, src interface{}) interface{} {
+	srcMap, ok := src.(map[string]interface{})
+	if !ok {
+		return src
+	}
+	dstMap, ok := dst.(map[string]interface{})
+	if !ok {
+		return src
+	}
+	for k, v := range srcMap {
+		dstMap[k] = mergeJSONVal(dstMap[k], v)
+	}
+	return dstMap
+}
+
 func (t *parser) valList() ([]interface{}, error) {
 	r, _, e := t.sc.ReadRune()
 	if e != nil {
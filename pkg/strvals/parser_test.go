@@ -633,6 +633,131 @@ func TestParseIntoFile(t *testing.T) {
 	}
 }
 
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		input  string
+		got    map[string]interface{}
+		expect map[string]interface{}
+	}{
+		{
+			input: `outer={"inner1": "value1", "deep": {"b": 2}},outer.inner3=[1, "two"]`,
+			got: map[string]interface{}{
+				"outer": map[string]interface{}{
+					"inner1": "overwrite",
+					"inner2": "value2",
+					"deep":   map[string]interface{}{"a": 1},
+				},
+			},
+			expect: map[string]interface{}{
+				"outer": map[string]interface{}{
+					"inner1": "value1",
+					"inner2": "value2",
+					"inner3": []interface{}{1, "two"},
+					"deep":   map[string]interface{}{"a": 1, "b": 2},
+				},
+			},
+		},
+		{
+			input: `list[1]={"name": "b"} ,list[0]=null`,
+			got: map[string]interface{}{
+				"list": []interface{}{
+					map[string]interface{}{"name": "a"},
+					map[string]interface{}{"name": "x", "port": 80},
+				},
+			},
+			expect: map[string]interface{}{
+				"list": []interface{}{
+					nil,
+					map[string]interface{}{"name": "b", "port": 80},
+				},
+			},
+		},
+		{
+			input:  `replaced={"a": 1}`,
+			got:    map[string]interface{}{"replaced": "scalar"},
+			expect: map[string]interface{}{"replaced": map[string]interface{}{"a": 1}},
+		},
+	}
+	for _, tt := range tests {
+		if err := ParseJSON(tt.input, tt.got); err != nil {
+			t.Fatalf("%s: %s", tt.input, err)
+		}
+
+		y1, err := yaml.Marshal(tt.expect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y2, err := yaml.Marshal(tt.got)
+		if err != nil {
+			t.Fatalf("Error serializing parsed value: %s", err)
+		}
+
+		if string(y1) != string(y2) {
+			t.Errorf("%s: Expected:\n%s\nGot:\n%s", tt.input, y1, y2)
+		}
+	}
+}
+
+func TestParseLiteral(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect map[string]interface{}
+	}{
+		{`name=a,b={c}\d`, map[string]interface{}{"name": `a,b={c}\d`}},
+		{"outer.inner=1", map[string]interface{}{"outer": map[string]interface{}{"inner": "1"}}},
+		{"list[1]=true", map[string]interface{}{"list": []interface{}{nil, "true"}}},
+		{"list[0].name=x.y", map[string]interface{}{"list": []interface{}{map[string]interface{}{"name": "x.y"}}}},
+		{"empty=", map[string]interface{}{"empty": ""}},
+	}
+	for _, tt := range tests {
+		got, err := ParseLiteral(tt.input)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.input, err)
+		}
+
+		y1, err := yaml.Marshal(tt.expect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y2, err := yaml.Marshal(got)
+		if err != nil {
+			t.Fatalf("Error serializing parsed value: %s", err)
+		}
+
+		if string(y1) != string(y2) {
+			t.Errorf("%s: Expected:\n%s\nGot:\n%s", tt.input, y1, y2)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		input string
+		parse func(string) error
+		pos   int
+	}{
+		{"name1=value1,name2", func(s string) error { _, err := Parse(s); return err }, 18},
+		{"list[a]=value", func(s string) error { _, err := Parse(s); return err }, 7},
+		{`a={"b": 1},c={"d" 2}`, func(s string) error { return ParseJSON(s, map[string]interface{}{}) }, 18},
+		{`a={"b": 1} x`, func(s string) error { return ParseJSON(s, map[string]interface{}{}) }, 11},
+		{"ключ", func(s string) error { return ParseLiteralInto(s, map[string]interface{}{}) }, 4},
+	}
+	for _, tt := range tests {
+		err := tt.parse(tt.input)
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("%s: expected a parse error, got %v", tt.input, err)
+			continue
+		}
+		if perr.Line != tt.input {
+			t.Errorf("%s: expected the line in the error, got %q", tt.input, perr.Line)
+		}
+		if perr.Pos != tt.pos {
+			t.Errorf("%s: expected position %d, got %d (%s)", tt.input, tt.pos, perr.Pos, perr)
+		}
+	}
+}
+
 func TestToYAML(t *testing.T) {
 	// The TestParse does the hard part. We just verify that YAML formatting is
 	// happening.
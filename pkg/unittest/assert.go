@@ -0,0 +1,271 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+// Assertion is a check against the rendered documents of a test case.
+//
+// Exactly one of Equal, MatchRegex, Exists, IsKind, HasDocuments or
+// FailedTemplate should be set. Not inverts the outcome.
+type Assertion struct {
+	// Template restricts the assertion to documents rendered from the given
+	// chart template.
+	Template string `json:"template,omitempty"`
+	// DocumentIndex restricts the assertion to a single document, counted
+	// from zero across the selected templates.
+	DocumentIndex *int `json:"documentIndex,omitempty"`
+	// Not inverts the assertion.
+	Not bool `json:"not,omitempty"`
+
+	// Path is the JSONPath expression the value assertions apply to.
+	Path string `json:"path,omitempty"`
+	// Equal asserts that the value at Path equals the given value.
+	Equal interface{} `json:"equal,omitempty"`
+	// MatchRegex asserts that the value at Path is a string matching the
+	// regular expression.
+	MatchRegex string `json:"matchRegex,omitempty"`
+	// Exists asserts whether Path resolves to a value.
+	Exists *bool `json:"exists,omitempty"`
+
+	// IsKind asserts the kind of the document.
+	IsKind string `json:"isKind,omitempty"`
+	// HasDocuments asserts the number of selected documents.
+	HasDocuments *int `json:"hasDocuments,omitempty"`
+	// FailedTemplate asserts that rendering fails with an error containing
+	// the given message.
+	FailedTemplate string `json:"failedTemplate,omitempty"`
+}
+
+func (a *Assertion) validate() error {
+	if a == nil {
+		return errors.New("assertion is empty")
+	}
+	n := 0
+	for _, set := range []bool{
+		a.Equal != nil, a.MatchRegex != "", a.Exists != nil,
+		a.IsKind != "", a.HasDocuments != nil, a.FailedTemplate != "",
+	} {
+		if set {
+			n++
+		}
+	}
+	if n != 1 {
+		return errors.New("exactly one of equal, matchRegex, exists, isKind, hasDocuments or failedTemplate must be set")
+	}
+	if (a.Equal != nil || a.MatchRegex != "" || a.Exists != nil) && a.Path == "" {
+		return errors.New("path is required")
+	}
+	if a.MatchRegex != "" {
+		if _, err := regexp.Compile(a.MatchRegex); err != nil {
+			return errors.Wrap(err, "invalid matchRegex")
+		}
+	}
+	return nil
+}
+
+// Document is a single rendered Kubernetes object.
+type Document struct {
+	// Template is the chart template the document was rendered from, e.g.
+	// "templates/deployment.yaml".
+	Template string
+	// Object is the decoded document.
+	Object map[string]interface{}
+}
+
+// Evaluate checks the assertion against the rendered documents, or the
+// rendering error if rendering failed. It returns nil if the assertion holds.
+func (a *Assertion) Evaluate(docs []Document, renderErr error) error {
+	if a.FailedTemplate != "" {
+		failed := renderErr != nil && strings.Contains(renderErr.Error(), a.FailedTemplate)
+		return a.result(failed, func() string {
+			if renderErr == nil {
+				return fmt.Sprintf("expected rendering to fail with %q, but it succeeded", a.FailedTemplate)
+			}
+			return fmt.Sprintf("expected rendering to fail with %q, got: %s", a.FailedTemplate, renderErr)
+		}, "expected rendering not to fail with %q", a.FailedTemplate)
+	}
+	if renderErr != nil {
+		return errors.Wrap(renderErr, "rendering failed")
+	}
+
+	selected := a.selectDocuments(docs)
+	if a.HasDocuments != nil {
+		return a.result(len(selected) == *a.HasDocuments, func() string {
+			return fmt.Sprintf("expected %d documents, got %d", *a.HasDocuments, len(selected))
+		}, "expected a document count other than %d", *a.HasDocuments)
+	}
+	if len(selected) == 0 {
+		return errors.New("no documents were rendered for the assertion")
+	}
+
+	for i, d := range selected {
+		if err := a.evaluateDocument(d); err != nil {
+			return errors.Wrapf(err, "%s: document %d", d.Template, i)
+		}
+	}
+	return nil
+}
+
+func (a *Assertion) selectDocuments(docs []Document) []Document {
+	var selected []Document
+	for _, d := range docs {
+		if a.Template != "" && d.Template != a.Template {
+			continue
+		}
+		selected = append(selected, d)
+	}
+	if a.DocumentIndex != nil {
+		if *a.DocumentIndex < 0 || *a.DocumentIndex >= len(selected) {
+			return nil
+		}
+		return selected[*a.DocumentIndex : *a.DocumentIndex+1]
+	}
+	return selected
+}
+
+func (a *Assertion) evaluateDocument(d Document) error {
+	if a.IsKind != "" {
+		kind, _ := d.Object["kind"].(string)
+		return a.result(kind == a.IsKind, func() string {
+			return fmt.Sprintf("expected kind %q, got %q", a.IsKind, kind)
+		}, "expected kind other than %q", a.IsKind)
+	}
+
+	value, found, err := Lookup(d.Object, a.Path)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case a.Exists != nil:
+		return a.result(found == *a.Exists, func() string {
+			if *a.Exists {
+				return fmt.Sprintf("expected %s to exist", a.Path)
+			}
+			return fmt.Sprintf("expected %s not to exist, got %s", a.Path, format(value))
+		}, "expected %s existence to differ from %t", a.Path, *a.Exists)
+	case a.MatchRegex != "":
+		s, ok := value.(string)
+		matched := ok && found && regexp.MustCompile(a.MatchRegex).MatchString(s)
+		return a.result(matched, func() string {
+			return fmt.Sprintf("expected %s to match %q, got %s", a.Path, a.MatchRegex, format(value))
+		}, "expected %s not to match %q", a.Path, a.MatchRegex)
+	default:
+		expected := normalize(a.Equal)
+		equal := found && reflect.DeepEqual(expected, normalize(value))
+		return a.result(equal, func() string {
+			if !found {
+				return fmt.Sprintf("expected %s to equal %s, but it does not exist", a.Path, format(expected))
+			}
+			return fmt.Sprintf("expected %s to equal %s, got %s", a.Path, format(expected), format(value))
+		}, "expected %s not to equal %s", a.Path, format(expected))
+	}
+}
+
+// result turns an outcome into an error, honoring Not.
+func (a *Assertion) result(ok bool, msg func() string, notFormat string, notArgs ...interface{}) error {
+	if a.Not {
+		if ok {
+			return errors.Errorf(notFormat, notArgs...)
+		}
+		return nil
+	}
+	if !ok {
+		return errors.New(msg())
+	}
+	return nil
+}
+
+// Lookup resolves a JSONPath expression against a decoded document. Several
+// matches are returned as a list. The boolean result reports whether the path
+// matched anything.
+func Lookup(obj map[string]interface{}, path string) (interface{}, bool, error) {
+	j := jsonpath.New("assert")
+	if err := j.Parse(jsonPathExpression(path)); err != nil {
+		return nil, false, errors.Wrapf(err, "invalid path %q", path)
+	}
+	results, err := j.FindResults(obj)
+	if err != nil {
+		// Missing keys are reported as errors by the JSONPath evaluator.
+		return nil, false, nil
+	}
+
+	var values []interface{}
+	for _, r := range results {
+		for _, v := range r {
+			values = append(values, v.Interface())
+		}
+	}
+	switch len(values) {
+	case 0:
+		return nil, false, nil
+	case 1:
+		return values[0], true, nil
+	default:
+		return values, true, nil
+	}
+}
+
+// jsonPathExpression accepts the short forms "spec.replicas" and
+// ".spec.replicas" in addition to full JSONPath templates.
+func jsonPathExpression(path string) string {
+	if strings.HasPrefix(path, "{") {
+		return path
+	}
+	if !strings.HasPrefix(path, ".") && !strings.HasPrefix(path, "$") {
+		path = "." + path
+	}
+	return "{" + path + "}"
+}
+
+// normalize round-trips a value through YAML so that expected values from a
+// suite file and values from rendered documents compare equal regardless of
+// their Go types.
+func normalize(v interface{}) interface{} {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := yaml.Unmarshal(b, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+func format(v interface{}) string {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	b = bytes.TrimSpace(b)
+	if bytes.ContainsRune(b, '\n') {
+		return "\n" + string(b)
+	}
+	return string(b)
+}
@@ -0,0 +1,286 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// DefaultReleaseName is the release name used when a test case does not set one.
+const DefaultReleaseName = "RELEASE-NAME"
+
+// CaseResult is the outcome of a single test case.
+type CaseResult struct {
+	Name     string
+	Failures []error
+}
+
+// Passed reports whether every assertion of the case held.
+func (r *CaseResult) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// SuiteResult is the outcome of a suite.
+type SuiteResult struct {
+	Name  string
+	File  string
+	Cases []*CaseResult
+}
+
+// Passed reports whether every case of the suite passed.
+func (r *SuiteResult) Passed() bool {
+	for _, c := range r.Cases {
+		if !c.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Report is the outcome of running the unit tests of a chart.
+type Report struct {
+	Chart  string
+	Suites []*SuiteResult
+}
+
+// Passed reports whether every suite passed.
+func (r *Report) Passed() bool {
+	for _, s := range r.Suites {
+		if !s.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Counts returns the number of passed and failed test cases.
+func (r *Report) Counts() (passed, failed int) {
+	for _, s := range r.Suites {
+		for _, c := range s.Cases {
+			if c.Passed() {
+				passed++
+			} else {
+				failed++
+			}
+		}
+	}
+	return passed, failed
+}
+
+// RunChart loads and runs every unit test suite stored in the chart.
+func RunChart(ch *chart.Chart) (*Report, error) {
+	suites, err := LoadSuites(ch)
+	if err != nil {
+		return nil, err
+	}
+	return Run(ch, suites...), nil
+}
+
+// Run runs the given suites against the chart.
+func Run(ch *chart.Chart, suites ...*Suite) *Report {
+	report := &Report{Chart: ch.Name()}
+	for _, s := range suites {
+		sr := &SuiteResult{Name: s.Name, File: s.File}
+		for _, c := range s.Tests {
+			sr.Cases = append(sr.Cases, RunCase(ch, s, c))
+		}
+		report.Suites = append(report.Suites, sr)
+	}
+	return report
+}
+
+// RunCase renders the chart for a single test case and evaluates its assertions.
+func RunCase(ch *chart.Chart, s *Suite, c *Case) *CaseResult {
+	result := &CaseResult{Name: c.Name}
+
+	docs, renderErr := Render(ch, s.Templates, c)
+	for i, a := range c.Asserts {
+		if err := a.Evaluate(docs, renderErr); err != nil {
+			result.Failures = append(result.Failures, errors.Wrapf(err, "assertion %d", i))
+		}
+	}
+	return result
+}
+
+// Render renders the chart for the given test case and returns the documents
+// produced by the given templates. All templates are returned when the list
+// of templates is empty.
+func Render(ch *chart.Chart, templates []string, c *Case) ([]Document, error) {
+	vals, err := caseValues(ch, c)
+	if err != nil {
+		return nil, err
+	}
+
+	options := chartutil.ReleaseOptions{
+		Name:      c.Release.Name,
+		Namespace: c.Release.Namespace,
+		Revision:  c.Release.Revision,
+		IsUpgrade: c.Release.IsUpgrade,
+		IsInstall: !c.Release.IsUpgrade,
+	}
+	if options.Name == "" {
+		options.Name = DefaultReleaseName
+	}
+	if options.Namespace == "" {
+		options.Namespace = "default"
+	}
+	if options.Revision == 0 {
+		options.Revision = 1
+	}
+
+	caps, err := caseCapabilities(c)
+	if err != nil {
+		return nil, err
+	}
+
+	renderVals, err := chartutil.ToRenderValues(ch, vals, options, caps)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := engine.Render(ch, renderVals)
+	if err != nil {
+		return nil, err
+	}
+
+	return Documents(ch, rendered, templates)
+}
+
+// Documents decodes the output of the rendering engine into documents,
+// ordered by template name and then by position in the template. Only the
+// given chart templates are returned, or all of them if the list is empty.
+func Documents(ch *chart.Chart, rendered map[string]string, templates []string) ([]Document, error) {
+	want := make(map[string]bool, len(templates))
+	for _, t := range templates {
+		want[t] = true
+	}
+
+	prefix := ch.Name() + "/"
+	var names []string
+	for name := range rendered {
+		tpl := strings.TrimPrefix(name, prefix)
+		if strings.HasSuffix(tpl, "NOTES.txt") || strings.HasSuffix(tpl, "NOTES.yaml") || strings.HasPrefix(path.Base(tpl), "_") {
+			continue
+		}
+		if len(want) > 0 && !want[tpl] {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var docs []Document
+	for _, name := range names {
+		manifests := releaseutil.SplitManifests(rendered[name])
+		keys := make([]string, 0, len(manifests))
+		for k := range manifests {
+			keys = append(keys, k)
+		}
+		sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+		tpl := strings.TrimPrefix(name, prefix)
+		for _, k := range keys {
+			obj := map[string]interface{}{}
+			if err := yaml.Unmarshal([]byte(manifests[k]), &obj); err != nil {
+				return nil, errors.Wrapf(err, "failed to decode a document rendered from %s", tpl)
+			}
+			if len(obj) == 0 {
+				continue
+			}
+			docs = append(docs, Document{Template: tpl, Object: obj})
+		}
+	}
+	return docs, nil
+}
+
+func caseValues(ch *chart.Chart, c *Case) (map[string]interface{}, error) {
+	vals := map[string]interface{}{}
+	for _, name := range c.ValuesFiles {
+		var data []byte
+		for _, f := range ch.Files {
+			if f.Name == name {
+				data = f.Data
+				break
+			}
+		}
+		if data == nil {
+			return nil, errors.Errorf("values file %s not found in chart", name)
+		}
+		fileVals, err := chartutil.ReadValues(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", name)
+		}
+		vals = chartutil.MergeTables(vals, fileVals)
+	}
+	vals = chartutil.MergeTables(vals, c.Values)
+
+	keys := make([]string, 0, len(c.Set))
+	for k := range c.Set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		setPath(vals, strings.Split(k, "."), c.Set[k])
+	}
+	return vals, nil
+}
+
+func caseCapabilities(c *Case) (*chartutil.Capabilities, error) {
+	caps := *chartutil.DefaultCapabilities
+	if c.Capabilities.KubeVersion != "" {
+		v, err := semver.NewVersion(c.Capabilities.KubeVersion)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid kubeVersion %q", c.Capabilities.KubeVersion)
+		}
+		caps.KubeVersion = chartutil.KubeVersion{
+			Version: "v" + v.String(),
+			Major:   strconv.FormatUint(v.Major(), 10),
+			Minor:   strconv.FormatUint(v.Minor(), 10),
+		}
+	}
+	if len(c.Capabilities.APIVersions) > 0 {
+		caps.APIVersions = append(chartutil.VersionSet{}, caps.APIVersions...)
+		caps.APIVersions = append(caps.APIVersions, c.Capabilities.APIVersions...)
+	}
+	return &caps, nil
+}
+
+func setPath(vals map[string]interface{}, keys []string, value interface{}) {
+	for _, k := range keys[:len(keys)-1] {
+		next, ok := vals[k].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+		} else {
+			// Copy the table so that the suite's own values are not modified.
+			next = chartutil.MergeTables(next, nil)
+		}
+		vals[k] = next
+		vals = next
+	}
+	vals[keys[len(keys)-1]] = value
+}
@@ -0,0 +1,152 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package unittest runs chart unit tests without a Kubernetes cluster.
+
+Unit tests live in the chart's top-level tests/ directory, in files whose
+names end in "_test.yaml". Each file describes a suite:
+
+	suite: deployment
+	templates:
+	  - templates/deployment.yaml
+	tests:
+	  - it: sets the replica count
+	    set:
+	      replicaCount: 3
+	    asserts:
+	      - isKind: Deployment
+	      - path: "{.spec.replicas}"
+	        equal: 3
+
+Every test renders the chart locally with the given values and evaluates its
+assertions against the rendered documents. Paths are JSONPath expressions as
+understood by kubectl; the surrounding braces and leading dot may be omitted.
+*/
+package unittest // import "helm.sh/helm/v3/pkg/unittest"
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// TestsDir is the chart directory holding unit test suites.
+const TestsDir = "tests/"
+
+// SuiteFileSuffix is the file name suffix identifying a unit test suite.
+const SuiteFileSuffix = "_test.yaml"
+
+// Suite is a set of unit tests sharing the templates under test.
+type Suite struct {
+	// Name identifies the suite in reports. It defaults to the file name.
+	Name string `json:"suite,omitempty"`
+	// Templates restricts the documents under test to the given chart
+	// templates, e.g. "templates/deployment.yaml". All templates are
+	// considered when it is empty.
+	Templates []string `json:"templates,omitempty"`
+	// Tests are the individual test cases.
+	Tests []*Case `json:"tests"`
+
+	// File is the chart file the suite was loaded from.
+	File string `json:"-"`
+}
+
+// Case is a single unit test.
+type Case struct {
+	// Name describes the behavior under test.
+	Name string `json:"it"`
+	// Values are merged over the chart's default values.
+	Values map[string]interface{} `json:"values,omitempty"`
+	// ValuesFiles are chart files merged over the default values, in order,
+	// before Values and Set are applied.
+	ValuesFiles []string `json:"valuesFiles,omitempty"`
+	// Set assigns individual values by dotted path, e.g. "image.tag".
+	Set map[string]interface{} `json:"set,omitempty"`
+	// Release configures the release the chart is rendered for.
+	Release ReleaseOptions `json:"release,omitempty"`
+	// Capabilities configures the cluster capabilities visible to templates.
+	Capabilities CapabilitiesOptions `json:"capabilities,omitempty"`
+	// Asserts are evaluated against the rendered documents.
+	Asserts []*Assertion `json:"asserts"`
+}
+
+// ReleaseOptions describes the release a test case is rendered for.
+type ReleaseOptions struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Revision  int    `json:"revision,omitempty"`
+	IsUpgrade bool   `json:"upgrade,omitempty"`
+}
+
+// CapabilitiesOptions describes the cluster a test case is rendered for.
+type CapabilitiesOptions struct {
+	KubeVersion string   `json:"kubeVersion,omitempty"`
+	APIVersions []string `json:"apiVersions,omitempty"`
+}
+
+// IsSuiteFile reports whether the chart file name identifies a unit test suite.
+func IsSuiteFile(name string) bool {
+	return strings.HasPrefix(name, TestsDir) && strings.HasSuffix(name, SuiteFileSuffix)
+}
+
+// LoadSuites reads every unit test suite stored in the chart, sorted by file name.
+func LoadSuites(ch *chart.Chart) ([]*Suite, error) {
+	var suites []*Suite
+	for _, f := range ch.Files {
+		if !IsSuiteFile(f.Name) {
+			continue
+		}
+		s, err := ParseSuite(f.Data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load test suite %s", f.Name)
+		}
+		s.File = f.Name
+		if s.Name == "" {
+			s.Name = strings.TrimSuffix(path.Base(f.Name), SuiteFileSuffix)
+		}
+		suites = append(suites, s)
+	}
+	sort.SliceStable(suites, func(i, j int) bool { return suites[i].File < suites[j].File })
+	return suites, nil
+}
+
+// ParseSuite decodes a unit test suite from YAML.
+func ParseSuite(data []byte) (*Suite, error) {
+	s := &Suite{}
+	if err := yaml.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	for i, c := range s.Tests {
+		if c == nil {
+			return nil, errors.Errorf("test %d is empty", i)
+		}
+		if c.Name == "" {
+			return nil, errors.Errorf("test %d has no description ('it')", i)
+		}
+		for j, a := range c.Asserts {
+			if err := a.validate(); err != nil {
+				return nil, errors.Wrapf(err, "test %q: assertion %d", c.Name, j)
+			}
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const deploymentTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}-web
+  labels:
+    tier: {{ .Values.tier }}
+spec:
+  replicas: {{ .Values.replicaCount }}
+{{- if .Values.service.enabled }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}-web
+{{- end }}
+{{- if .Values.fail }}
+{{ fail "refusing to render" }}
+{{- end }}
+`
+
+const suite = `suite: web
+templates:
+  - templates/web.yaml
+tests:
+  - it: renders defaults
+    asserts:
+      - hasDocuments: 1
+      - isKind: Deployment
+      - path: spec.replicas
+        equal: 1
+      - path: "{.metadata.name}"
+        equal: RELEASE-NAME-web
+  - it: honors values
+    release:
+      name: prod
+    valuesFiles:
+      - tests/values/prod.yaml
+    set:
+      replicaCount: 5
+    asserts:
+      - hasDocuments: 2
+      - path: spec.replicas
+        equal: 5
+        documentIndex: 0
+      - path: metadata.labels.tier
+        matchRegex: ^front
+        documentIndex: 0
+      - path: spec
+        exists: false
+        documentIndex: 1
+      - isKind: Service
+        documentIndex: 1
+  - it: fails on purpose
+    set:
+      fail: true
+    asserts:
+      - failedTemplate: refusing to render
+  - it: is wrong
+    asserts:
+      - path: spec.replicas
+        equal: 2
+      - isKind: Deployment
+        not: true
+`
+
+func unitTestChart() *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: "v2", Name: "web", Version: "0.1.0"},
+		Values: map[string]interface{}{
+			"replicaCount": 1,
+			"tier":         "backend",
+			"fail":         false,
+			"service":      map[string]interface{}{"enabled": false},
+		},
+		Templates: []*chart.File{
+			{Name: "templates/web.yaml", Data: []byte(deploymentTemplate)},
+		},
+		Files: []*chart.File{
+			{Name: "tests/web_test.yaml", Data: []byte(suite)},
+			{Name: "tests/values/prod.yaml", Data: []byte("tier: frontend\nservice:\n  enabled: true\n")},
+			{Name: "README.md", Data: []byte("not a suite")},
+		},
+	}
+}
+
+func TestLoadSuites(t *testing.T) {
+	suites, err := LoadSuites(unitTestChart())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suites) != 1 {
+		t.Fatalf("expected 1 suite, got %d", len(suites))
+	}
+	if suites[0].Name != "web" || suites[0].File != "tests/web_test.yaml" {
+		t.Errorf("unexpected suite %q from %q", suites[0].Name, suites[0].File)
+	}
+	if len(suites[0].Tests) != 4 {
+		t.Errorf("expected 4 tests, got %d", len(suites[0].Tests))
+	}
+}
+
+func TestRunChart(t *testing.T) {
+	report, err := RunChart(unitTestChart())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := report.Suites[0].Cases
+	for _, c := range results[:3] {
+		if !c.Passed() {
+			t.Errorf("expected %q to pass, got %v", c.Name, c.Failures)
+		}
+	}
+	if results[3].Passed() || len(results[3].Failures) != 2 {
+		t.Errorf("expected %q to fail twice, got %v", results[3].Name, results[3].Failures)
+	}
+	if !strings.Contains(results[3].Failures[0].Error(), "expected spec.replicas to equal 2, got 1") {
+		t.Errorf("unexpected failure message: %s", results[3].Failures[0])
+	}
+
+	passed, failed := report.Counts()
+	if passed != 3 || failed != 1 || report.Passed() {
+		t.Errorf("expected 3 passed and 1 failed, got %d and %d", passed, failed)
+	}
+}
+
+func TestParseSuiteErrors(t *testing.T) {
+	for name, spec := range map[string]string{
+		"missing description": "tests:\n- asserts:\n  - isKind: Pod\n",
+		"two checks":          "tests:\n- it: x\n  asserts:\n  - isKind: Pod\n    hasDocuments: 1\n",
+		"missing path":        "tests:\n- it: x\n  asserts:\n  - equal: 1\n",
+		"bad regex":           "tests:\n- it: x\n  asserts:\n  - path: a\n    matchRegex: '['\n",
+	} {
+		if _, err := ParseSuite([]byte(spec)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	obj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "a"},
+				map[string]interface{}{"name": "b"},
+			},
+		},
+	}
+
+	v, found, err := Lookup(obj, "spec.containers[*].name")
+	if err != nil || !found {
+		t.Fatalf("expected a match, got %v (found: %t)", err, found)
+	}
+	if names, ok := v.([]interface{}); !ok || len(names) != 2 {
+		t.Errorf("expected two names, got %v", v)
+	}
+
+	if _, found, _ := Lookup(obj, "{.spec.missing}"); found {
+		t.Error("expected no match for a missing key")
+	}
+}